@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"math/rand"
 	"runtime"
 	"testing"
 
@@ -629,6 +630,57 @@ func testEvaluatorRotate(testctx *testContext, t *testing.T) {
 		}
 	})
 
+	t.Run(testString("Evaluator/Automorphism", testctx.params), func(t *testing.T) {
+
+		values, _, ciphertext := newTestVectorsRingQ(testctx, testctx.encryptorPk, t)
+
+		// Column rotation by 4 followed by a row rotation
+		galEl := (testctx.params.GaloisElementForColumnRotationBy(4) * testctx.params.GaloisElementForRowRotation()) & (testctx.params.RingQ().NthRoot - 1)
+
+		rotkey := testctx.kgen.GenRotationKeys([]uint64{galEl}, testctx.sk)
+		evaluator := evaluator.WithKey(rlwe.EvaluationKey{Rlk: testctx.rlk, Rtks: rotkey})
+
+		receiver := evaluator.AutomorphismNew(ciphertext, galEl)
+
+		valuesWant := utils.RotateUint64Slots(values.Coeffs[0], 4)
+		valuesWant = append(valuesWant[testctx.params.N()>>1:], valuesWant[:testctx.params.N()>>1]...)
+
+		verifyTestVectors(testctx, testctx.decryptor, &ring.Poly{Coeffs: [][]uint64{valuesWant}}, receiver, t)
+	})
+
+	t.Run(testString("Evaluator/Permute", testctx.params), func(t *testing.T) {
+
+		values, _, ciphertext := newTestVectorsRingQ(testctx, testctx.encryptorPk, t)
+
+		// Swaps the rows and randomly swaps the pairs of adjacent slots, which gives a network of depth
+		// at most two that fits in the noise budget of the test parameters
+		N := testctx.params.N()
+		permutation := make([]int, N)
+		for i := 0; i < N; i += 2 {
+			j := (i + N>>1) % N
+			if rand.Intn(2) == 0 {
+				permutation[i], permutation[i+1] = j, j+1
+			} else {
+				permutation[i], permutation[i+1] = j+1, j
+			}
+		}
+
+		network := testctx.params.NewPermutationNetwork(permutation)
+		require.LessOrEqual(t, network.Depth(), 2)
+
+		rotkey := testctx.kgen.GenRotationKeys(network.GaloisElements(), testctx.sk)
+		evaluator := evaluator.WithKey(rlwe.EvaluationKey{Rlk: testctx.rlk, Rtks: rotkey})
+
+		evaluator.Permute(ciphertext, GenPermutation(testctx.params, testctx.encoder, network), ciphertext)
+
+		valuesWant := make([]uint64, N)
+		for i := range valuesWant {
+			valuesWant[i] = values.Coeffs[0][permutation[i]]
+		}
+
+		verifyTestVectors(testctx, testctx.decryptor, &ring.Poly{Coeffs: [][]uint64{valuesWant}}, ciphertext, t)
+	})
+
 	rotkey = testctx.kgen.GenRotationKeysForInnerSum(testctx.sk)
 	evaluator = evaluator.WithKey(rlwe.EvaluationKey{Rlk: testctx.rlk, Rtks: rotkey})
 
@@ -649,6 +701,32 @@ func testEvaluatorRotate(testctx *testContext, t *testing.T) {
 		}
 		verifyTestVectors(testctx, testctx.decryptor, values, ciphertext, t)
 	})
+
+	t.Run(testString("Evaluator/Rotate/InnerSum/Arena", testctx.params), func(t *testing.T) {
+		values, _, ciphertext := newTestVectorsRingQ(testctx, testctx.encryptorPk, t)
+
+		ctOut := NewCiphertext(testctx.params, 1)
+
+		pool := rlwe.NewPool(testctx.params.Parameters)
+		evaluator.WithPool(pool).InnerSum(ciphertext, ctOut)
+		require.Equal(t, 0, pool.Q.InUse())
+
+		sizeQ, sizeP := pool.MaxInUse()
+		evalArena := evaluator.WithPool(rlwe.NewArena(testctx.params.Parameters, sizeQ, sizeP))
+		require.Equal(t, 0.0, testing.AllocsPerRun(2, func() { evalArena.InnerSum(ciphertext, ctOut) }))
+
+		var sum uint64
+		for _, c := range values.Coeffs[0] {
+			sum += c
+		}
+
+		sum %= testctx.params.T()
+
+		for i := range values.Coeffs[0] {
+			values.Coeffs[0][i] = sum
+		}
+		verifyTestVectors(testctx, testctx.decryptor, values, ctOut, t)
+	})
 }
 
 func testMarshaller(testctx *testContext, t *testing.T) {
@@ -700,6 +778,27 @@ func testMarshaller(testctx *testContext, t *testing.T) {
 		assert.Equal(t, 6.6, paramsWithCustomSecrets.Sigma())
 		assert.Equal(t, 192, paramsWithCustomSecrets.HammingWeight())
 
+		// checks that the limb size, the sampler type and the distributions are preserved
+		paramsWithDistributions, err := NewParametersFromLiteral(ParametersLiteral{
+			LogN:                testctx.params.LogN(),
+			LogQ:                []int{30, 30},
+			LogP:                []int{31},
+			T:                   65537,
+			LimbSize:            32,
+			GaussianSamplerType: ring.GaussianCDT,
+			Xs:                  &ring.Distribution{Type: ring.TernaryProbability, P: 0.5},
+			Xe:                  &ring.Distribution{Type: ring.DiscreteGaussian, Sigma: 6.4, Bound: 38.4},
+		})
+		require.NoError(t, err)
+		data, err = json.Marshal(paramsWithDistributions)
+		require.NoError(t, err)
+		var paramsWithDistributionsRec Parameters
+		require.NoError(t, json.Unmarshal(data, &paramsWithDistributionsRec))
+		assert.True(t, paramsWithDistributions.Equals(paramsWithDistributionsRec))
+		assert.Equal(t, 32, paramsWithDistributionsRec.LimbSize())
+		assert.Equal(t, ring.GaussianCDT, paramsWithDistributionsRec.GaussianSamplerType())
+		assert.Equal(t, ring.Distribution{Type: ring.TernaryProbability, P: 0.5}, paramsWithDistributionsRec.Xs())
+		assert.Equal(t, ring.Distribution{Type: ring.DiscreteGaussian, Sigma: 6.4, Bound: 38.4}, paramsWithDistributionsRec.Xe())
 	})
 
 	t.Run(testString("Marshaller/Ciphertext", testctx.params), func(t *testing.T) {
@@ -3,6 +3,7 @@ package bfv
 import (
 	"github.com/tuneinsight/lattigo/v3/ring"
 	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
 )
 
 // Encryptor an encryption interface for the BFV scheme.
@@ -13,6 +14,7 @@ type Encryptor interface {
 	EncryptFromCRPNew(plaintext *Plaintext, crp *ring.Poly) *Ciphertext
 	ShallowCopy() Encryptor
 	WithKey(key interface{}) Encryptor
+	WithPRNG(prng utils.PRNG) Encryptor
 }
 
 type encryptor struct {
@@ -70,3 +72,9 @@ func (enc *encryptor) ShallowCopy() Encryptor {
 func (enc *encryptor) WithKey(key interface{}) Encryptor {
 	return &encryptor{enc.Encryptor.WithKey(key), enc.params}
 }
+
+// WithPRNG creates a shallow copy of this encryptor whose samplers draw their randomness from prng,
+// making the encryptions reproducible from the seed of prng (see rlwe.Encryptor.WithPRNG).
+func (enc *encryptor) WithPRNG(prng utils.PRNG) Encryptor {
+	return &encryptor{enc.Encryptor.WithPRNG(prng), enc.params}
+}
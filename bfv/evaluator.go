@@ -41,9 +41,14 @@ type Evaluator interface {
 	RotateColumns(ct0 *Ciphertext, k int, ctOut *Ciphertext)
 	RotateRows(ct0 *Ciphertext, ctOut *Ciphertext)
 	RotateRowsNew(ct0 *Ciphertext) (ctOut *Ciphertext)
+	Automorphism(ct0 *Ciphertext, galEl uint64, ctOut *Ciphertext)
+	AutomorphismNew(ct0 *Ciphertext, galEl uint64) (ctOut *Ciphertext)
+	Permute(ct0 *Ciphertext, permutation Permutation, ctOut *Ciphertext)
+	PermuteNew(ct0 *Ciphertext, permutation Permutation) (ctOut *Ciphertext)
 	InnerSum(ct0 *Ciphertext, ctOut *Ciphertext)
 	ShallowCopy() Evaluator
 	WithKey(rlwe.EvaluationKey) Evaluator
+	WithPool(*rlwe.Pool) Evaluator
 }
 
 // evaluator is a struct that holds the necessary elements to perform the homomorphic operations between ciphertexts and/or plaintexts.
@@ -57,6 +62,8 @@ type evaluator struct {
 	rtks *rlwe.RotationKeySet
 
 	basisExtenderQ1toQ2 *ring.BasisExtender
+
+	pool *rlwe.Pool
 }
 
 type evaluatorBase struct {
@@ -90,6 +97,7 @@ type evaluatorBuffers struct {
 	poolQ    [][]*ring.Poly
 	poolQmul [][]*ring.Poly
 	tmpPt    *Plaintext
+	tmpCt    *Ciphertext // Ciphertext whose polynomials are drawn from the rlwe.Pool of the evaluator
 }
 
 func newEvaluatorBuffer(eval *evaluatorBase) *evaluatorBuffers {
@@ -106,6 +114,7 @@ func newEvaluatorBuffer(eval *evaluatorBase) *evaluatorBuffers {
 	}
 
 	evb.tmpPt = NewPlaintext(eval.params)
+	evb.tmpCt = &Ciphertext{&rlwe.Ciphertext{Value: make([]*ring.Poly, 2)}}
 
 	return evb
 }
@@ -643,6 +652,34 @@ func (eval *evaluator) RotateRowsNew(ct0 *Ciphertext) (ctOut *Ciphertext) {
 	return
 }
 
+// Automorphism applies the automorphism X -> X^galEl on ct0 and returns the result in ctOut.
+// RotateColumns and RotateRows are special cases of Automorphism, for the galois elements
+// params.GaloisElementForColumnRotationBy(k) and params.GaloisElementForRowRotation().
+func (eval *evaluator) Automorphism(ct0 *Ciphertext, galEl uint64, ctOut *Ciphertext) {
+
+	if ct0.Degree() != 1 || ctOut.Degree() != 1 {
+		panic("cannot Automorphism: input and/or output must be of degree 1")
+	}
+
+	if galEl&(eval.ringQ.NthRoot-1) == 1 {
+		ctOut.Copy(ct0.El())
+		return
+	}
+
+	if key, inSet := eval.rtks.GetRotationKey(galEl); inSet {
+		eval.permute(ct0, galEl, key, ctOut)
+	} else {
+		panic(fmt.Errorf("evaluator has no rotation key for galois element %d", galEl))
+	}
+}
+
+// AutomorphismNew applies Automorphism and returns the result in a new Ciphertext.
+func (eval *evaluator) AutomorphismNew(ct0 *Ciphertext, galEl uint64) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(eval.params, 1)
+	eval.Automorphism(ct0, galEl, ctOut)
+	return
+}
+
 // InnerSum computes the inner sum of ct0 and returns the result in ctOut. It requires a rotation key storing all the left powers of two rotations.
 // The resulting vector will be of the form [sum, sum, .., sum, sum].
 func (eval *evaluator) InnerSum(ct0 *Ciphertext, ctOut *Ciphertext) {
@@ -651,7 +688,15 @@ func (eval *evaluator) InnerSum(ct0 *Ciphertext, ctOut *Ciphertext) {
 		panic("cannot InnerSum: input and output must be of degree 1")
 	}
 
-	cTmp := NewCiphertext(eval.params, 1)
+	var cTmp *Ciphertext
+	if eval.pool != nil {
+		cTmp = eval.tmpCt
+		for i := range cTmp.Value {
+			cTmp.Value[i] = eval.pool.Q.GetPoly()
+		}
+	} else {
+		cTmp = NewCiphertext(eval.params, 1)
+	}
 
 	ctOut.Copy(ct0.El())
 
@@ -662,6 +707,10 @@ func (eval *evaluator) InnerSum(ct0 *Ciphertext, ctOut *Ciphertext) {
 
 	eval.RotateRows(ctOut, cTmp)
 	eval.Add(ctOut, cTmp, ctOut)
+
+	if eval.pool != nil {
+		eval.pool.Q.Release(cTmp.Value...)
+	}
 }
 
 // ShallowCopy creates a shallow copy of this evaluator in which the read-only data-structures are
@@ -687,6 +736,22 @@ func (eval *evaluator) WithKey(evaluationKey rlwe.EvaluationKey) Evaluator {
 		basisExtenderQ1toQ2: eval.basisExtenderQ1toQ2,
 		rlk:                 evaluationKey.Rlk,
 		rtks:                evaluationKey.Rtks,
+		pool:                eval.pool,
+	}
+}
+
+// WithPool creates a shallow copy of this evaluator which draws its temporary polynomials from the pool
+// and in which the read-only data-structures and the temporary buffers are shared with the receiver.
+// The receiver and the returned Evaluators cannot be used concurrently.
+func (eval *evaluator) WithPool(pool *rlwe.Pool) Evaluator {
+	return &evaluator{
+		evaluatorBase:       eval.evaluatorBase,
+		KeySwitcher:         eval.KeySwitcher,
+		evaluatorBuffers:    eval.evaluatorBuffers,
+		basisExtenderQ1toQ2: eval.basisExtenderQ1toQ2,
+		rlk:                 eval.rlk,
+		rtks:                eval.rtks,
+		pool:                pool,
 	}
 }
 
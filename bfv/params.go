@@ -108,6 +108,9 @@ var DefaultPostQuantumParams = []ParametersLiteral{PN12QP101pq, PN13QP202pq, PN1
 // Optionally, users may specify the error variance (Sigma) and secrets' density (H). If left
 // unset, standard default values for these field are substituted at parameter creation (see
 // NewParametersFromLiteral).
+//
+// The size of the RNS limbs (LimbSize), the type of the discrete Gaussian samplers (GaussianSamplerType) and
+// the distributions of the secrets, errors and ephemeral secrets (Xs, Xe and Xu) are those of rlwe.ParametersLiteral.
 type ParametersLiteral struct {
 	LogN  int // Log Ring degree (power of 2)
 	Q     []uint64
@@ -117,6 +120,31 @@ type ParametersLiteral struct {
 	H     int
 	Sigma float64 // Gaussian sampling standard deviation
 	T     uint64  // Plaintext modulus
+
+	LimbSize            int                      `json:",omitempty"`
+	GaussianSamplerType ring.GaussianSamplerType `json:",omitempty"`
+
+	Xs *ring.Distribution `json:",omitempty"`
+	Xe *ring.Distribution `json:",omitempty"`
+	Xu *ring.Distribution `json:",omitempty"`
+}
+
+// RLWEParametersLiteral returns the rlwe.ParametersLiteral of the receiver.
+func (p ParametersLiteral) RLWEParametersLiteral() rlwe.ParametersLiteral {
+	return rlwe.ParametersLiteral{
+		LogN:                p.LogN,
+		Q:                   p.Q,
+		P:                   p.P,
+		LogQ:                p.LogQ,
+		LogP:                p.LogP,
+		H:                   p.H,
+		Sigma:               p.Sigma,
+		LimbSize:            p.LimbSize,
+		GaussianSamplerType: p.GaussianSamplerType,
+		Xs:                  p.Xs,
+		Xe:                  p.Xe,
+		Xu:                  p.Xu,
+	}
 }
 
 // Parameters represents a parameter set for the BFV cryptosystem. Its fields are private and
@@ -156,7 +184,7 @@ func NewParameters(rlweParams rlwe.Parameters, t uint64) (p Parameters, err erro
 //
 // See `rlwe.NewParametersFromLiteral` for default values of the optional fields.
 func NewParametersFromLiteral(pl ParametersLiteral) (Parameters, error) {
-	rlweParams, err := rlwe.NewParametersFromLiteral(rlwe.ParametersLiteral{LogN: pl.LogN, Q: pl.Q, P: pl.P, LogQ: pl.LogQ, LogP: pl.LogP, H: pl.H, Sigma: pl.Sigma})
+	rlweParams, err := rlwe.NewParametersFromLiteral(pl.RLWEParametersLiteral())
 	if err != nil {
 		return Parameters{}, err
 	}
@@ -237,9 +265,32 @@ func (p Parameters) MarshalBinarySize() int {
 	return p.Parameters.MarshalBinarySize() + 8
 }
 
+// ParametersLiteral returns the ParametersLiteral of the receiver, from which NewParametersFromLiteral
+// instantiates the same parameters.
+func (p Parameters) ParametersLiteral() ParametersLiteral {
+	var limbSize int
+	if p.LimbSize() == 32 {
+		limbSize = 32
+	}
+	xs, xe, xu := p.Xs(), p.Xe(), p.Xu()
+	return ParametersLiteral{
+		LogN:                p.LogN(),
+		Q:                   p.Q(),
+		P:                   p.P(),
+		H:                   p.HammingWeight(),
+		Sigma:               p.Sigma(),
+		T:                   p.T(),
+		LimbSize:            limbSize,
+		GaussianSamplerType: p.GaussianSamplerType(),
+		Xs:                  &xs,
+		Xe:                  &xe,
+		Xu:                  &xu,
+	}
+}
+
 // MarshalJSON returns a JSON representation of this parameter set. See `Marshal` from the `encoding/json` package.
 func (p Parameters) MarshalJSON() ([]byte, error) {
-	return json.Marshal(ParametersLiteral{LogN: p.LogN(), Q: p.Q(), P: p.P(), H: p.HammingWeight(), Sigma: p.Sigma(), T: p.T()})
+	return json.Marshal(p.ParametersLiteral())
 }
 
 // UnmarshalJSON reads a JSON representation of a parameter set into the receiver Parameter. See `Unmarshal` from the `encoding/json` package.
@@ -0,0 +1,111 @@
+package bfv
+
+import (
+	"fmt"
+
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+// Permutation is a type for arbitrary permutations of the slots of ciphertexts.
+// It stores the masks of the layers of an rlwe.PermutationNetwork as plaintexts
+// indexed by galois element, and can be evaluated on a ciphertext by using the
+// evaluator.Permute method.
+// The evaluation performs one plaintext-ciphertext multiplication per layer and requires
+// the rotation keys for the galois elements returned by the GaloisElements method of the network.
+type Permutation struct {
+	Layers []map[uint64]*PlaintextMul
+}
+
+// GenPermutation encodes the masks of the layers of the rlwe.PermutationNetwork into a new Permutation.
+// The network must have been generated for all the params.N() slots.
+func GenPermutation(params Parameters, encoder Encoder, network *rlwe.PermutationNetwork) Permutation {
+
+	if network.Slots != params.N() {
+		panic(fmt.Sprintf("cannot GenPermutation: network.Slots=%d must be equal to params.N()=%d", network.Slots, params.N()))
+	}
+
+	permutation := Permutation{Layers: make([]map[uint64]*PlaintextMul, network.Depth())}
+
+	values := make([]uint64, params.N())
+
+	for i, layer := range network.Layers {
+
+		permutation.Layers[i] = make(map[uint64]*PlaintextMul)
+
+		for galEl, mask := range layer {
+
+			for j := range mask {
+				values[j] = 0
+				if mask[j] {
+					values[j] = 1
+				}
+			}
+
+			pt := NewPlaintextMul(params)
+			encoder.EncodeUintMul(values, pt)
+			permutation.Layers[i][galEl] = pt
+		}
+	}
+
+	return permutation
+}
+
+// PermuteNew applies the Permutation on ct0 and returns the result on a new Ciphertext.
+func (eval *evaluator) PermuteNew(ct0 *Ciphertext, permutation Permutation) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(eval.params, 1)
+	eval.Permute(ct0, permutation, ctOut)
+	return
+}
+
+// Permute applies the Permutation on ct0 and returns the result on ctOut.
+// Each layer is evaluated as the sum of the automorphisms of its galois elements multiplied by their masks.
+func (eval *evaluator) Permute(ct0 *Ciphertext, permutation Permutation, ctOut *Ciphertext) {
+
+	if ct0.Degree() != 1 || ctOut.Degree() != 1 {
+		panic("cannot Permute: input and output must be of degree 1")
+	}
+
+	if len(permutation.Layers) == 0 {
+		ctOut.Copy(ct0.El())
+		return
+	}
+
+	var cTmp, cAcc *Ciphertext
+	if eval.pool != nil {
+		cTmp = eval.tmpCt
+		for i := range cTmp.Value {
+			cTmp.Value[i] = eval.pool.Q.GetPoly()
+		}
+		cAcc = &Ciphertext{&rlwe.Ciphertext{Value: []*ring.Poly{eval.pool.Q.GetPoly(), eval.pool.Q.GetPoly()}}}
+	} else {
+		cTmp = NewCiphertext(eval.params, 1)
+		cAcc = NewCiphertext(eval.params, 1)
+	}
+
+	ct := ct0
+	for _, layer := range permutation.Layers {
+
+		first := true
+		for galEl, pt := range layer {
+
+			eval.Automorphism(ct, galEl, cTmp)
+
+			if first {
+				eval.Mul(cTmp, pt, cAcc)
+				first = false
+			} else {
+				eval.Mul(cTmp, pt, cTmp)
+				eval.Add(cAcc, cTmp, cAcc)
+			}
+		}
+
+		ctOut.Copy(cAcc.El())
+		ct = ctOut
+	}
+
+	if eval.pool != nil {
+		eval.pool.Q.Release(cTmp.Value...)
+		eval.pool.Q.Release(cAcc.Value...)
+	}
+}
@@ -0,0 +1,51 @@
+package simulation
+
+import (
+	"math"
+
+	"github.com/tuneinsight/lattigo/v3/bfv"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+// decryptor is a bfv.Decryptor that copies the slot values of the ciphertexts on the plaintexts.
+type decryptor struct {
+	*simulator
+}
+
+// NewDecryptor creates a new bfv.Decryptor for the simulation. The secret key is ignored and can be nil.
+func NewDecryptor(params bfv.Parameters, sk *rlwe.SecretKey) bfv.Decryptor {
+	return &decryptor{simulator: newSimulator(params, false)}
+}
+
+// Decrypt decrypts the ciphertext and writes the result on ptOut. If six standard deviations of the
+// error of the ciphertext exceed Q/(2T), the decryption is considered as failed and random values are
+// written on ptOut instead.
+func (dec *decryptor) Decrypt(ct *bfv.Ciphertext, ptOut *bfv.Plaintext) {
+
+	values := dec.getValues(ct.Value[0])
+
+	if v := dec.getVariance(ct.Value[0]).total(); v > 0 && math.Log2(6*math.Sqrt(v)) >= dec.decryptionBound() {
+		for i := range values {
+			values[i] = dec.prng.Uint64() % dec.params.T()
+		}
+	}
+
+	dec.set(ptOut.Value, values, variance{})
+}
+
+// DecryptNew decrypts the ciphertext and returns the result on a new plaintext.
+func (dec *decryptor) DecryptNew(ct *bfv.Ciphertext) (ptOut *bfv.Plaintext) {
+	ptOut = &bfv.Plaintext{Plaintext: &rlwe.Plaintext{Value: dec.newPoly()}}
+	dec.Decrypt(ct, ptOut)
+	return
+}
+
+// ShallowCopy creates a shallow copy of the decryptor that can be used concurrently with the receiver.
+func (dec *decryptor) ShallowCopy() bfv.Decryptor {
+	return &decryptor{simulator: dec.shallowCopy()}
+}
+
+// WithKey creates a shallow copy of the decryptor with a new key.
+func (dec *decryptor) WithKey(sk *rlwe.SecretKey) bfv.Decryptor {
+	return dec.ShallowCopy()
+}
@@ -0,0 +1,157 @@
+package simulation
+
+import (
+	"fmt"
+
+	"github.com/tuneinsight/lattigo/v3/bfv"
+	"github.com/tuneinsight/lattigo/v3/ring"
+)
+
+// encoder is a bfv.Encoder that stores the slot values on the plaintexts.
+type encoder struct {
+	*simulator
+}
+
+// NewEncoder creates a new bfv.Encoder for the simulation.
+func NewEncoder(params bfv.Parameters) bfv.Encoder {
+	return &encoder{simulator: newSimulator(params, false)}
+}
+
+func (ecd *encoder) encodeUint(coeffs []uint64, pol *ring.Poly) {
+
+	if len(coeffs) > ecd.params.N() {
+		panic("invalid input to encode: number of coefficients must be smaller or equal to the ring degree")
+	}
+
+	values := make([]uint64, ecd.params.N())
+	for i := range coeffs {
+		values[i] = coeffs[i] % ecd.params.T()
+	}
+
+	ecd.set(pol, values, variance{})
+}
+
+func (ecd *encoder) encodeInt(coeffs []int64, pol *ring.Poly) {
+
+	if len(coeffs) > ecd.params.N() {
+		panic("invalid input to encode: number of coefficients must be smaller or equal to the ring degree")
+	}
+
+	t := int64(ecd.params.T())
+
+	values := make([]uint64, ecd.params.N())
+	for i := range coeffs {
+		values[i] = uint64(((coeffs[i] % t) + t) % t)
+	}
+
+	ecd.set(pol, values, variance{})
+}
+
+// EncodeUint encodes an uint64 slice of size at most N on a plaintext.
+func (ecd *encoder) EncodeUint(coeffs []uint64, pt *bfv.Plaintext) {
+	ecd.encodeUint(coeffs, pt.Value)
+}
+
+// EncodeUintRingT encodes an uint64 slice of size at most N on a PlaintextRingT.
+func (ecd *encoder) EncodeUintRingT(coeffs []uint64, pt *bfv.PlaintextRingT) {
+	ecd.encodeUint(coeffs, pt.Value)
+}
+
+// EncodeUintMul encodes an uint64 slice of size at most N on a PlaintextMul.
+func (ecd *encoder) EncodeUintMul(coeffs []uint64, pt *bfv.PlaintextMul) {
+	ecd.encodeUint(coeffs, pt.Value)
+}
+
+// EncodeInt encodes an int64 slice of size at most N on a plaintext.
+func (ecd *encoder) EncodeInt(coeffs []int64, pt *bfv.Plaintext) {
+	ecd.encodeInt(coeffs, pt.Value)
+}
+
+// EncodeIntRingT encodes an int64 slice of size at most N on a PlaintextRingT.
+func (ecd *encoder) EncodeIntRingT(coeffs []int64, pt *bfv.PlaintextRingT) {
+	ecd.encodeInt(coeffs, pt.Value)
+}
+
+// EncodeIntMul encodes an int64 slice of size at most N on a PlaintextMul.
+func (ecd *encoder) EncodeIntMul(coeffs []int64, pt *bfv.PlaintextMul) {
+	ecd.encodeInt(coeffs, pt.Value)
+}
+
+// ScaleUp transforms a PlaintextRingT into a Plaintext.
+func (ecd *encoder) ScaleUp(ptRt *bfv.PlaintextRingT, pt *bfv.Plaintext) {
+	ecd.set(pt.Value, ecd.getValues(ptRt.Value), variance{})
+}
+
+// ScaleDown transforms a Plaintext into a PlaintextRingT.
+func (ecd *encoder) ScaleDown(pt *bfv.Plaintext, ptRt *bfv.PlaintextRingT) {
+	ecd.set(ptRt.Value, ecd.getValues(pt.Value), variance{})
+}
+
+// RingTToMul transforms a PlaintextRingT into a PlaintextMul.
+func (ecd *encoder) RingTToMul(ptRt *bfv.PlaintextRingT, ptMul *bfv.PlaintextMul) {
+	ecd.set(ptMul.Value, ecd.getValues(ptRt.Value), variance{})
+}
+
+// MulToRingT transforms a PlaintextMul into a PlaintextRingT.
+func (ecd *encoder) MulToRingT(pt *bfv.PlaintextMul, ptRt *bfv.PlaintextRingT) {
+	ecd.set(ptRt.Value, ecd.getValues(pt.Value), variance{})
+}
+
+func (ecd *encoder) getPlaintextValues(p interface{}) []uint64 {
+	switch pt := p.(type) {
+	case *bfv.Plaintext:
+		return ecd.getValues(pt.Value)
+	case *bfv.PlaintextMul:
+		return ecd.getValues(pt.Value)
+	case *bfv.PlaintextRingT:
+		return ecd.getValues(pt.Value)
+	default:
+		panic(fmt.Errorf("unsupported plaintext type (%T)", pt))
+	}
+}
+
+// DecodeRingT decodes any plaintext type into a PlaintextRingT. It panics if p is not PlaintextRingT, Plaintext or PlaintextMul.
+func (ecd *encoder) DecodeRingT(p interface{}, ptRt *bfv.PlaintextRingT) {
+	ecd.set(ptRt.Value, ecd.getPlaintextValues(p), variance{})
+}
+
+// DecodeUint decodes any plaintext type and writes the coefficients in coeffs. It panics if p is not PlaintextRingT, Plaintext or PlaintextMul.
+func (ecd *encoder) DecodeUint(p interface{}, coeffs []uint64) {
+	copy(coeffs, ecd.getPlaintextValues(p))
+}
+
+// DecodeUintNew decodes any plaintext type and returns the coefficients in a new []uint64.
+// It panics if p is not PlaintextRingT, Plaintext or PlaintextMul.
+func (ecd *encoder) DecodeUintNew(p interface{}) (coeffs []uint64) {
+	coeffs = make([]uint64, ecd.params.N())
+	ecd.DecodeUint(p, coeffs)
+	return
+}
+
+// DecodeInt decodes any plaintext type and writes the coefficients, centered modulo T, in coeffs.
+// It panics if p is not PlaintextRingT, Plaintext or PlaintextMul.
+func (ecd *encoder) DecodeInt(p interface{}, coeffs []int64) {
+
+	modulus := int64(ecd.params.T())
+	modulusHalf := modulus >> 1
+
+	for i, value := range ecd.getPlaintextValues(p) {
+		coeffs[i] = int64(value)
+		if coeffs[i] >= modulusHalf {
+			coeffs[i] -= modulus
+		}
+	}
+}
+
+// DecodeIntNew decodes any plaintext type and returns the coefficients, centered modulo T, in a new []int64.
+// It panics if p is not PlaintextRingT, Plaintext or PlaintextMul.
+func (ecd *encoder) DecodeIntNew(p interface{}) (coeffs []int64) {
+	coeffs = make([]int64, ecd.params.N())
+	ecd.DecodeInt(p, coeffs)
+	return
+}
+
+// ShallowCopy creates a shallow copy of the encoder that can be used concurrently with the receiver.
+func (ecd *encoder) ShallowCopy() bfv.Encoder {
+	return &encoder{simulator: ecd.shallowCopy()}
+}
@@ -0,0 +1,85 @@
+package simulation
+
+import (
+	"github.com/tuneinsight/lattigo/v3/bfv"
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// encryptor is a bfv.Encryptor that copies the slot values of the plaintexts on the ciphertexts.
+type encryptor struct {
+	*simulator
+	key interface{}
+}
+
+// NewEncryptor creates a new bfv.Encryptor for the simulation. The key argument can be *rlwe.PublicKey,
+// *rlwe.SecretKey or nil, and only its type is used: it determines the variance of the encryption error
+// if noise is true. The keys can be allocated with rlwe.NewSecretKey and rlwe.NewPublicKey.
+func NewEncryptor(params bfv.Parameters, key interface{}, noise bool) bfv.Encryptor {
+	enc := &encryptor{simulator: newSimulator(params, noise)}
+	enc.setKey(key)
+	return enc
+}
+
+func (enc *encryptor) setKey(key interface{}) {
+	switch key.(type) {
+	case *rlwe.PublicKey, *rlwe.SecretKey, nil:
+		enc.key = key
+	default:
+		panic("key must be either *rlwe.PublicKey, *rlwe.SecretKey or nil")
+	}
+}
+
+// Encrypt encrypts the input plaintext and writes the result on ctOut.
+func (enc *encryptor) Encrypt(plaintext *bfv.Plaintext, ctOut *bfv.Ciphertext) {
+	switch enc.key.(type) {
+	case *rlwe.PublicKey:
+		enc.setOutput(ctOut, 1, enc.getValues(plaintext.Value), enc.freshNoiseVariance(true))
+	case *rlwe.SecretKey:
+		enc.setOutput(ctOut, 1, enc.getValues(plaintext.Value), enc.freshNoiseVariance(false))
+	default:
+		panic("cannot encrypt: Encryptor has no key")
+	}
+}
+
+// EncryptNew encrypts the input plaintext and returns the result on a new ciphertext.
+func (enc *encryptor) EncryptNew(plaintext *bfv.Plaintext) *bfv.Ciphertext {
+	ct := enc.newCiphertext(1)
+	enc.Encrypt(plaintext, ct)
+	return ct
+}
+
+// EncryptFromCRP encrypts the input plaintext and writes the result on ctOut. The crp is ignored.
+func (enc *encryptor) EncryptFromCRP(plaintext *bfv.Plaintext, crp *ring.Poly, ctOut *bfv.Ciphertext) {
+
+	if _, isSk := enc.key.(*rlwe.SecretKey); !isSk {
+		panic("cannot EncryptFromCRP: Encryptor has no secret key")
+	}
+
+	enc.setOutput(ctOut, 1, enc.getValues(plaintext.Value), enc.freshNoiseVariance(false))
+}
+
+// EncryptFromCRPNew encrypts the input plaintext and returns the result on a new ciphertext. The crp is ignored.
+func (enc *encryptor) EncryptFromCRPNew(plaintext *bfv.Plaintext, crp *ring.Poly) *bfv.Ciphertext {
+	ct := enc.newCiphertext(1)
+	enc.EncryptFromCRP(plaintext, crp, ct)
+	return ct
+}
+
+// ShallowCopy creates a shallow copy of the encryptor that can be used concurrently with the receiver.
+func (enc *encryptor) ShallowCopy() bfv.Encryptor {
+	return &encryptor{simulator: enc.shallowCopy(), key: enc.key}
+}
+
+// WithPRNG creates a shallow copy of the encryptor whose simulated encryption errors are drawn from a source seeded from prng.
+func (enc *encryptor) WithPRNG(prng utils.PRNG) bfv.Encryptor {
+	return &encryptor{simulator: newSimulatorWithPRNG(enc.params, enc.noise, prng), key: enc.key}
+}
+
+// WithKey creates a shallow copy of the encryptor with a new key.
+func (enc *encryptor) WithKey(key interface{}) bfv.Encryptor {
+	encCopy := &encryptor{simulator: enc.shallowCopy()}
+	encCopy.setKey(key)
+	return encCopy
+}
@@ -0,0 +1,478 @@
+package simulation
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/tuneinsight/lattigo/v3/bfv"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// evaluator is a bfv.Evaluator operating on the slot values stored on the plaintexts and ciphertexts.
+// It follows the same rules as the bfv.Evaluator for the types and the degrees of the operands, and
+// panics if the relinearization or rotation keys required by an operation are not available.
+type evaluator struct {
+	*simulator
+	rlk  *rlwe.RelinearizationKey
+	rtks *rlwe.RotationKeySet
+}
+
+// NewEvaluator creates a new bfv.Evaluator for the simulation. The evaluation key is only used to check the
+// availability of the relinearization and rotation keys, which can be placeholders created with NewEvaluationKey.
+// If noise is true, the variance of the error of the ciphertexts is tracked through the operations.
+func NewEvaluator(params bfv.Parameters, evaluationKey rlwe.EvaluationKey, noise bool) bfv.Evaluator {
+	return &evaluator{simulator: newSimulator(params, noise), rlk: evaluationKey.Rlk, rtks: evaluationKey.Rtks}
+}
+
+// NewEvaluationKey creates a new rlwe.EvaluationKey storing empty placeholder keys for the relinearization of
+// ciphertexts up to degree maxDegree+1 and for the given column rotations (and the row rotation if
+// includeRowRotation is true), to be given to NewEvaluator.
+func NewEvaluationKey(params bfv.Parameters, maxDegree int, rotations []int, includeRowRotation bool) rlwe.EvaluationKey {
+
+	rlk := &rlwe.RelinearizationKey{Keys: make([]*rlwe.SwitchingKey, maxDegree)}
+	for i := range rlk.Keys {
+		rlk.Keys[i] = &rlwe.SwitchingKey{}
+	}
+
+	rtks := &rlwe.RotationKeySet{Keys: make(map[uint64]*rlwe.SwitchingKey)}
+
+	for _, k := range rotations {
+		rtks.Keys[params.GaloisElementForColumnRotationBy(k)] = &rlwe.SwitchingKey{}
+	}
+
+	if includeRowRotation {
+		rtks.Keys[params.GaloisElementForRowRotation()] = &rlwe.SwitchingKey{}
+	}
+
+	return rlwe.EvaluationKey{Rlk: rlk, Rtks: rtks}
+}
+
+// getOperand returns the slot values and the variance of the error of the operand. The error of a Plaintext
+// is the rounding error of its scaling by Q/T.
+func (eval *evaluator) getOperand(op bfv.Operand) (values []uint64, v variance) {
+	switch o := op.(type) {
+	case *bfv.Ciphertext, *bfv.PlaintextRingT:
+		return eval.getValues(o.El().Value[0]), eval.getVariance(o.El().Value[0])
+	case *bfv.Plaintext:
+		if eval.noise {
+			v.independent = 1.0 / 12
+		}
+		return eval.getValues(o.Value), v
+	default:
+		panic(fmt.Errorf("invalid operand type for operation: %T", o))
+	}
+}
+
+// checkBinary checks the operands as done by the bfv.Evaluator.
+func checkBinary(op0, op1, opOut bfv.Operand, opOutMinDegree int) {
+
+	if op0 == nil || op1 == nil || opOut == nil {
+		panic("operands cannot be nil")
+	}
+
+	if op0.Degree()+op1.Degree() == 0 {
+		panic("operands cannot be both plaintexts")
+	}
+
+	if opOut.Degree() < opOutMinDegree {
+		panic("receiver operand degree is too small")
+	}
+}
+
+// checkUnary checks the operands as done by the bfv.Evaluator.
+func checkUnary(op0, opOut bfv.Operand, opOutMinDegree int) {
+
+	if op0 == nil || opOut == nil {
+		panic("operand cannot be nil")
+	}
+
+	if op0.Degree() == 0 {
+		panic("operand cannot be plaintext")
+	}
+
+	if opOut.Degree() < opOutMinDegree {
+		panic("receiver operand degree is too small")
+	}
+}
+
+// mulMod returns a*b mod t.
+func mulMod(a, b, t uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	return bits.Rem64(hi, lo, t)
+}
+
+// centeredSquare returns the square of the centered representative of x modulo t.
+func centeredSquare(x, t uint64) float64 {
+	c := float64(x)
+	if x >= t>>1 {
+		c = float64(x) - float64(t)
+	}
+	return c * c
+}
+
+func (eval *evaluator) binary(op0, op1 bfv.Operand, ctOut *bfv.Ciphertext, f func(a, b uint64) uint64) {
+
+	degree := utils.MaxInt(op0.Degree(), op1.Degree())
+
+	checkBinary(op0, op1, ctOut, degree)
+
+	v0, var0 := eval.getOperand(op0)
+	v1, var1 := eval.getOperand(op1)
+
+	for i := range v0 {
+		v0[i] = f(v0[i], v1[i])
+	}
+
+	eval.setOutput(ctOut, utils.MaxInt(degree, ctOut.Degree()), v0, var0.add(var1))
+}
+
+func (eval *evaluator) unary(op bfv.Operand, ctOut *bfv.Ciphertext, f func(a uint64) uint64, scale float64) {
+
+	checkUnary(op, ctOut, op.Degree())
+
+	values, v := eval.getValues(op.El().Value[0]), eval.getVariance(op.El().Value[0])
+
+	for i := range values {
+		values[i] = f(values[i])
+	}
+
+	eval.setOutput(ctOut, ctOut.Degree(), values, v.mul(scale))
+}
+
+// Add adds op0 to op1 and returns the result in ctOut.
+func (eval *evaluator) Add(op0, op1 bfv.Operand, ctOut *bfv.Ciphertext) {
+	t := eval.params.T()
+	eval.binary(op0, op1, ctOut, func(a, b uint64) uint64 { return (a + b) % t })
+}
+
+// AddNew adds op0 to op1 and creates a new element ctOut to store the result.
+func (eval *evaluator) AddNew(op0, op1 bfv.Operand) (ctOut *bfv.Ciphertext) {
+	ctOut = eval.newCiphertext(utils.MaxInt(op0.Degree(), op1.Degree()))
+	eval.Add(op0, op1, ctOut)
+	return
+}
+
+// AddNoMod adds op0 to op1 and returns the result in ctOut. As the simulation stores the values
+// modulo T, it is equivalent to Add.
+func (eval *evaluator) AddNoMod(op0, op1 bfv.Operand, ctOut *bfv.Ciphertext) {
+	eval.Add(op0, op1, ctOut)
+}
+
+// AddNoModNew adds op0 to op1 and creates a new element ctOut to store the result.
+func (eval *evaluator) AddNoModNew(op0, op1 bfv.Operand) (ctOut *bfv.Ciphertext) {
+	return eval.AddNew(op0, op1)
+}
+
+// Sub subtracts op1 from op0 and returns the result in ctOut.
+func (eval *evaluator) Sub(op0, op1 bfv.Operand, ctOut *bfv.Ciphertext) {
+	t := eval.params.T()
+	eval.binary(op0, op1, ctOut, func(a, b uint64) uint64 { return (a + t - b) % t })
+}
+
+// SubNew subtracts op1 from op0 and creates a new element ctOut to store the result.
+func (eval *evaluator) SubNew(op0, op1 bfv.Operand) (ctOut *bfv.Ciphertext) {
+	ctOut = eval.newCiphertext(utils.MaxInt(op0.Degree(), op1.Degree()))
+	eval.Sub(op0, op1, ctOut)
+	return
+}
+
+// SubNoMod subtracts op1 from op0 and returns the result in ctOut. As the simulation stores the values
+// modulo T, it is equivalent to Sub.
+func (eval *evaluator) SubNoMod(op0, op1 bfv.Operand, ctOut *bfv.Ciphertext) {
+	eval.Sub(op0, op1, ctOut)
+}
+
+// SubNoModNew subtracts op1 from op0 and creates a new element ctOut to store the result.
+func (eval *evaluator) SubNoModNew(op0, op1 bfv.Operand) (ctOut *bfv.Ciphertext) {
+	return eval.SubNew(op0, op1)
+}
+
+// Neg negates op and returns the result in ctOut.
+func (eval *evaluator) Neg(op bfv.Operand, ctOut *bfv.Ciphertext) {
+	t := eval.params.T()
+	eval.unary(op, ctOut, func(a uint64) uint64 { return (t - a) % t }, 1)
+}
+
+// NegNew negates op and creates a new element to store the result.
+func (eval *evaluator) NegNew(op bfv.Operand) (ctOut *bfv.Ciphertext) {
+	ctOut = eval.newCiphertext(op.Degree())
+	eval.Neg(op, ctOut)
+	return
+}
+
+// Reduce copies op on ctOut, as the simulation stores the values modulo T.
+func (eval *evaluator) Reduce(op bfv.Operand, ctOut *bfv.Ciphertext) {
+	eval.unary(op, ctOut, func(a uint64) uint64 { return a }, 1)
+}
+
+// ReduceNew copies op on a new element ctOut.
+func (eval *evaluator) ReduceNew(op bfv.Operand) (ctOut *bfv.Ciphertext) {
+	ctOut = eval.newCiphertext(op.Degree())
+	eval.Reduce(op, ctOut)
+	return
+}
+
+// MulScalar multiplies op by a uint64 scalar and returns the result in ctOut.
+func (eval *evaluator) MulScalar(op bfv.Operand, scalar uint64, ctOut *bfv.Ciphertext) {
+	t := eval.params.T()
+	scalar %= t
+	eval.unary(op, ctOut, func(a uint64) uint64 { return mulMod(a, scalar, t) }, centeredSquare(scalar, t))
+}
+
+// MulScalarNew multiplies op by a uint64 scalar and creates a new element ctOut to store the result.
+func (eval *evaluator) MulScalarNew(op bfv.Operand, scalar uint64) (ctOut *bfv.Ciphertext) {
+	ctOut = eval.newCiphertext(op.Degree())
+	eval.MulScalar(op, scalar, ctOut)
+	return
+}
+
+// Mul multiplies op0 by op1 and returns the result in ctOut.
+func (eval *evaluator) Mul(op0 *bfv.Ciphertext, op1 bfv.Operand, ctOut *bfv.Ciphertext) {
+
+	degree := op0.Degree() + op1.Degree()
+
+	checkBinary(op0, op1, ctOut, degree)
+
+	v0, var0 := eval.getOperand(op0)
+
+	var v1 []uint64
+	var v variance
+
+	switch op1 := op1.(type) {
+	case *bfv.PlaintextMul, *bfv.PlaintextRingT:
+		v1, v = eval.getValues(op1.El().Value[0]), eval.mulPlaintextNoiseVariance(var0)
+	case *bfv.Plaintext, *bfv.Ciphertext:
+		var var1 variance
+		v1, var1 = eval.getOperand(op1)
+		v = eval.mulNoiseVariance(var0, op0.Degree(), var1, op1.Degree())
+	default:
+		panic(fmt.Errorf("invalid operand type for Mul: %T", op1))
+	}
+
+	t := eval.params.T()
+	for i := range v0 {
+		v0[i] = mulMod(v0[i], v1[i], t)
+	}
+
+	eval.setOutput(ctOut, ctOut.Degree(), v0, v)
+}
+
+// MulNew multiplies op0 by op1 and creates a new element ctOut to store the result.
+func (eval *evaluator) MulNew(op0 *bfv.Ciphertext, op1 bfv.Operand) (ctOut *bfv.Ciphertext) {
+	ctOut = eval.newCiphertext(op0.Degree() + op1.Degree())
+	eval.Mul(op0, op1, ctOut)
+	return
+}
+
+// Relinearize relinearizes the ciphertext ct0 of degree > 1 until it is of degree 1, and returns the result in ctOut.
+// It requires relinearization keys for all the degrees of ct0.
+func (eval *evaluator) Relinearize(ct0 *bfv.Ciphertext, ctOut *bfv.Ciphertext) {
+
+	if eval.rlk == nil {
+		panic("evaluator has no relinearization key")
+	}
+
+	if ct0.Degree()-1 > len(eval.rlk.Keys) {
+		panic("input ciphertext degree is too large to allow relinearization with the evluator's relinearization key")
+	}
+
+	v := eval.getVariance(ct0.Value[0])
+	for deg := ct0.Degree(); deg > 1; deg-- {
+		v = v.add(eval.keySwitchNoiseVariance())
+	}
+
+	eval.setOutput(ctOut, utils.MinInt(ct0.Degree(), 1), eval.getValues(ct0.Value[0]), v)
+}
+
+// RelinearizeNew relinearizes the ciphertext ct0 of degree > 1 until it is of degree 1, and creates a new ciphertext to store the result.
+func (eval *evaluator) RelinearizeNew(ct0 *bfv.Ciphertext) (ctOut *bfv.Ciphertext) {
+	ctOut = eval.newCiphertext(1)
+	eval.Relinearize(ct0, ctOut)
+	return
+}
+
+// SwitchKeys re-encrypts ct0 under a different key and returns the result in ctOut.
+func (eval *evaluator) SwitchKeys(ct0 *bfv.Ciphertext, switchKey *rlwe.SwitchingKey, ctOut *bfv.Ciphertext) {
+
+	if ct0.Degree() != 1 || ctOut.Degree() != 1 {
+		panic("cannot SwitchKeys: input and output must be of degree 1 to allow key switching")
+	}
+
+	eval.setOutput(ctOut, 1, eval.getValues(ct0.Value[0]), eval.getVariance(ct0.Value[0]).add(eval.keySwitchNoiseVariance()))
+}
+
+// SwitchKeysNew re-encrypts ct0 under a different key and creates a new ciphertext to store the result.
+func (eval *evaluator) SwitchKeysNew(ct0 *bfv.Ciphertext, switchkey *rlwe.SwitchingKey) (ctOut *bfv.Ciphertext) {
+	ctOut = eval.newCiphertext(1)
+	eval.SwitchKeys(ct0, switchkey, ctOut)
+	return
+}
+
+func (eval *evaluator) hasRotationKey(galEl uint64) bool {
+	if eval.rtks == nil {
+		return false
+	}
+	_, inSet := eval.rtks.GetRotationKey(galEl)
+	return inSet
+}
+
+// RotateColumns rotates the columns of ct0 by k positions to the left and returns the result in ctOut.
+func (eval *evaluator) RotateColumns(ct0 *bfv.Ciphertext, k int, ctOut *bfv.Ciphertext) {
+
+	if ct0.Degree() != 1 || ctOut.Degree() != 1 {
+		panic("cannot RotateColumns: input and or output must be of degree 1")
+	}
+
+	values, v := eval.getValues(ct0.Value[0]), eval.getVariance(ct0.Value[0])
+
+	if k != 0 {
+
+		if !eval.hasRotationKey(eval.params.GaloisElementForColumnRotationBy(k)) {
+			panic(fmt.Errorf("evaluator has no rotation key for rotation by %d", k))
+		}
+
+		values = rotateColumns(values, k)
+		v = v.add(eval.keySwitchNoiseVariance())
+	}
+
+	eval.setOutput(ctOut, 1, values, v)
+}
+
+// RotateColumnsNew applies RotateColumns and returns the result in a new Ciphertext.
+func (eval *evaluator) RotateColumnsNew(ct0 *bfv.Ciphertext, k int) (ctOut *bfv.Ciphertext) {
+	ctOut = eval.newCiphertext(1)
+	eval.RotateColumns(ct0, k, ctOut)
+	return
+}
+
+// RotateRows rotates the rows of ct0 and returns the result in ctOut.
+func (eval *evaluator) RotateRows(ct0 *bfv.Ciphertext, ctOut *bfv.Ciphertext) {
+
+	if ct0.Degree() != 1 || ctOut.Degree() != 1 {
+		panic("cannot RotateRows: input and/or output must be of degree 1")
+	}
+
+	if !eval.hasRotationKey(eval.params.GaloisElementForRowRotation()) {
+		panic("evaluator has no rotation key for row rotation")
+	}
+
+	eval.setOutput(ctOut, 1, rotateRows(eval.getValues(ct0.Value[0])), eval.getVariance(ct0.Value[0]).add(eval.keySwitchNoiseVariance()))
+}
+
+// RotateRowsNew rotates the rows of ct0 and returns the result a new Ciphertext.
+func (eval *evaluator) RotateRowsNew(ct0 *bfv.Ciphertext) (ctOut *bfv.Ciphertext) {
+	ctOut = eval.newCiphertext(1)
+	eval.RotateRows(ct0, ctOut)
+	return
+}
+
+// Automorphism applies the automorphism X -> X^galEl on ct0 and returns the result in ctOut.
+func (eval *evaluator) Automorphism(ct0 *bfv.Ciphertext, galEl uint64, ctOut *bfv.Ciphertext) {
+
+	if ct0.Degree() != 1 || ctOut.Degree() != 1 {
+		panic("cannot Automorphism: input and/or output must be of degree 1")
+	}
+
+	values, v := eval.getValues(ct0.Value[0]), eval.getVariance(ct0.Value[0])
+
+	if galEl&(eval.params.RingQ().NthRoot-1) != 1 {
+
+		if !eval.hasRotationKey(galEl) {
+			panic(fmt.Errorf("evaluator has no rotation key for galois element %d", galEl))
+		}
+
+		k, rowRotation := eval.params.RotationForGaloisElement(galEl)
+
+		values = rotateColumns(values, k)
+
+		if rowRotation {
+			values = rotateRows(values)
+		}
+
+		v = v.add(eval.keySwitchNoiseVariance())
+	}
+
+	eval.setOutput(ctOut, 1, values, v)
+}
+
+// AutomorphismNew applies Automorphism and returns the result in a new Ciphertext.
+func (eval *evaluator) AutomorphismNew(ct0 *bfv.Ciphertext, galEl uint64) (ctOut *bfv.Ciphertext) {
+	ctOut = eval.newCiphertext(1)
+	eval.Automorphism(ct0, galEl, ctOut)
+	return
+}
+
+// Permute applies the Permutation on ct0 and returns the result in ctOut.
+func (eval *evaluator) Permute(ct0 *bfv.Ciphertext, permutation bfv.Permutation, ctOut *bfv.Ciphertext) {
+
+	if ct0.Degree() != 1 || ctOut.Degree() != 1 {
+		panic("cannot Permute: input and output must be of degree 1")
+	}
+
+	eval.setOutput(ctOut, 1, eval.getValues(ct0.Value[0]), eval.getVariance(ct0.Value[0]))
+
+	cTmp, cAcc := eval.newCiphertext(1), eval.newCiphertext(1)
+
+	for _, layer := range permutation.Layers {
+
+		first := true
+		for galEl, pt := range layer {
+
+			eval.Automorphism(ctOut, galEl, cTmp)
+
+			if first {
+				eval.Mul(cTmp, pt, cAcc)
+				first = false
+			} else {
+				eval.Mul(cTmp, pt, cTmp)
+				eval.Add(cAcc, cTmp, cAcc)
+			}
+		}
+
+		eval.setOutput(ctOut, 1, eval.getValues(cAcc.Value[0]), eval.getVariance(cAcc.Value[0]))
+	}
+}
+
+// PermuteNew applies Permute and returns the result in a new Ciphertext.
+func (eval *evaluator) PermuteNew(ct0 *bfv.Ciphertext, permutation bfv.Permutation) (ctOut *bfv.Ciphertext) {
+	ctOut = eval.newCiphertext(1)
+	eval.Permute(ct0, permutation, ctOut)
+	return
+}
+
+// InnerSum computes the inner sum of ct0 and returns the result in ctOut. It requires a rotation key storing all the left powers of two rotations.
+// The resulting vector will be of the form [sum, sum, .., sum, sum].
+func (eval *evaluator) InnerSum(ct0 *bfv.Ciphertext, ctOut *bfv.Ciphertext) {
+
+	if ct0.Degree() != 1 || ctOut.Degree() != 1 {
+		panic("cannot InnerSum: input and output must be of degree 1")
+	}
+
+	cTmp := eval.newCiphertext(1)
+
+	eval.setOutput(ctOut, 1, eval.getValues(ct0.Value[0]), eval.getVariance(ct0.Value[0]))
+
+	for i := 1; i < eval.params.N()>>1; i <<= 1 {
+		eval.RotateColumns(ctOut, i, cTmp)
+		eval.Add(cTmp, ctOut, ctOut)
+	}
+
+	eval.RotateRows(ctOut, cTmp)
+	eval.Add(ctOut, cTmp, ctOut)
+}
+
+// ShallowCopy creates a shallow copy of the evaluator that can be used concurrently with the receiver.
+func (eval *evaluator) ShallowCopy() bfv.Evaluator {
+	return &evaluator{simulator: eval.shallowCopy(), rlk: eval.rlk, rtks: eval.rtks}
+}
+
+// WithKey creates a shallow copy of the evaluator with a new evaluation key.
+func (eval *evaluator) WithKey(evaluationKey rlwe.EvaluationKey) bfv.Evaluator {
+	return &evaluator{simulator: eval.shallowCopy(), rlk: evaluationKey.Rlk, rtks: evaluationKey.Rtks}
+}
+
+// WithPool returns the receiver, as the simulation has no temporary polynomials.
+func (eval *evaluator) WithPool(pool *rlwe.Pool) bfv.Evaluator {
+	return eval
+}
@@ -0,0 +1,271 @@
+// Package simulation implements a simulation backend for the BFV scheme. It provides implementations of the
+// bfv.Encoder, bfv.Encryptor, bfv.Decryptor and bfv.Evaluator interfaces that operate on cleartext slot
+// vectors instead of ciphertexts, while enforcing the same degree and evaluation-key constraints as the real
+// implementations. It is intended to test applications built on top of the bfv package without the cost of the
+// encryption. Optionally, the variance of the error of the ciphertexts can be tracked: decrypting a ciphertext
+// whose error exceeds the decryption bound then returns random values, as the real scheme would.
+//
+// The objects of the simulation are regular bfv.Plaintext, bfv.PlaintextRingT, bfv.PlaintextMul and
+// bfv.Ciphertext, whose first polynomial stores the slot values modulo T followed by the variance of the error.
+// They are allocated in a compact form and are only meaningful for the simulation backend: they cannot be mixed
+// with objects of the bfv package.
+package simulation
+
+import (
+	"encoding/binary"
+	"math"
+	"math/big"
+	"math/rand"
+
+	"github.com/tuneinsight/lattigo/v3/bfv"
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// simulator stores the parameters and the random source shared by all the simulation objects.
+type simulator struct {
+	params bfv.Parameters
+	noise  bool
+	fork   *utils.PRNGFork // derives the sources of the shallow copies, nil if the simulator is randomly seeded
+	prng   *rand.Rand
+}
+
+func newSimulator(params bfv.Parameters, noise bool) *simulator {
+	return newSimulatorWithPRNG(params, noise, nil)
+}
+
+// newSimulatorWithPRNG creates a new simulator whose random source is seeded from source, or randomly if source is nil.
+func newSimulatorWithPRNG(params bfv.Parameters, noise bool, source utils.PRNG) *simulator {
+	fork := utils.NewPRNGFork(source)
+	seed := utils.RandUint64()
+	if source != nil {
+		buf := make([]byte, 8)
+		source.Clock(buf)
+		seed = binary.LittleEndian.Uint64(buf)
+	}
+	return &simulator{params: params, noise: noise, fork: fork, prng: rand.New(rand.NewSource(int64(seed)))}
+}
+
+func (s *simulator) shallowCopy() *simulator {
+	source, err := s.fork.Derive()
+	if err != nil {
+		panic(err)
+	}
+	return newSimulatorWithPRNG(s.params, s.noise, source)
+}
+
+// variance is the variance of the error of a ciphertext, split into a component independent of the
+// secret and a component correlated with the secret. The latter appears with the ciphertext-ciphertext
+// multiplication and grows faster under subsequent multiplications.
+type variance struct {
+	independent float64
+	correlated  float64
+}
+
+func (v variance) add(other variance) variance {
+	return variance{v.independent + other.independent, v.correlated + other.correlated}
+}
+
+func (v variance) mul(c float64) variance {
+	return variance{v.independent * c, v.correlated * c}
+}
+
+func (v variance) total() float64 {
+	return v.independent + v.correlated
+}
+
+// hammingWeight returns the Hamming weight of the secret.
+func (s *simulator) hammingWeight() float64 {
+	if h := s.params.HammingWeight(); h > 0 {
+		return float64(h)
+	}
+	return 2 * float64(s.params.N()) / 3
+}
+
+// freshNoiseVariance returns the variance of the error of a fresh encryption. With a public key, the
+// encryption is done modulo QP and the error is dominated by the division by P.
+func (s *simulator) freshNoiseVariance(publicKey bool) variance {
+
+	if !s.noise {
+		return variance{}
+	}
+
+	sigma2 := s.params.Sigma() * s.params.Sigma()
+
+	if publicKey {
+		if s.params.PCount() > 0 {
+			return variance{independent: (1 + s.hammingWeight()) / 12}
+		}
+		return variance{independent: sigma2 * (2*s.hammingWeight() + 1)}
+	}
+
+	return variance{independent: sigma2}
+}
+
+// keySwitchNoiseVariance returns the variance of the error added by a key-switching: the error of the
+// switching key multiplied by the decomposition of the ciphertext, divided by P, plus the rounding error.
+func (s *simulator) keySwitchNoiseVariance() variance {
+
+	if !s.noise {
+		return variance{}
+	}
+
+	N, h := float64(s.params.N()), s.hammingWeight()
+	sigma2 := s.params.Sigma() * s.params.Sigma()
+
+	P := 1.0
+	for _, pj := range s.params.P() {
+		P *= float64(pj)
+	}
+
+	alpha := utils.MaxInt(s.params.PCount(), 1)
+
+	// The decomposition is done over products Q_j of alpha moduli of Q, with coefficients uniform in [0, Q_j).
+	var decomposition float64
+	for i := 0; i < s.params.QCount(); i += alpha {
+		Qj := 1.0
+		for _, qi := range s.params.Q()[i:utils.MinInt(i+alpha, s.params.QCount())] {
+			Qj *= float64(qi)
+		}
+		decomposition += (Qj / P) * (Qj / P)
+	}
+
+	return variance{independent: N*sigma2*decomposition/3 + (1+h)/12}
+}
+
+// plaintextSquare returns the mean of the square of the coefficients of a plaintext uniformly distributed in [0, T).
+func (s *simulator) plaintextSquare() float64 {
+	t := float64(s.params.T())
+	return t * t / 3
+}
+
+// mulPlaintextNoiseVariance returns the variance of the error of a ciphertext of error variance v multiplied by
+// a plaintext that is not scaled by Q/T.
+func (s *simulator) mulPlaintextNoiseVariance(v variance) variance {
+	return v.mul(float64(s.params.N()) * s.plaintextSquare())
+}
+
+// mulNoiseVariance returns the variance of the error of the tensoring of two operands of error variances v0 and
+// v1, which is dominated by the terms m0*e1 + m1*e0 + T*(k0*e1 + k1*e0), where k = (c0 + c1*s - Q/T*m - e)/Q
+// is correlated with the secret and is zero for plaintexts. The error of a ciphertext must be given with its
+// degree, a degree of zero indicating a plaintext.
+func (s *simulator) mulNoiseVariance(v0 variance, degree0 int, v1 variance, degree1 int) (v variance) {
+
+	if !s.noise {
+		return variance{}
+	}
+
+	N, h := float64(s.params.N()), s.hammingWeight()
+	t := float64(s.params.T())
+
+	// m0*e1 + m1*e0 and rounding of the rescaling by T/Q
+	v.independent = N*s.plaintextSquare()*(v0.total()+v1.total()) + (1+h+h*h)/12
+
+	// T*k0*e1 + T*k1*e0
+	kSquare := N * t * t * (h + 1) / 3
+	if degree0 > 0 {
+		v.correlated += kSquare * (v1.independent + h*v1.correlated)
+	}
+	if degree1 > 0 {
+		v.correlated += kSquare * (v0.independent + h*v0.correlated)
+	}
+
+	return
+}
+
+// decryptionBound returns log2(Q/(2T)), the bound on the error below which a ciphertext decrypts correctly.
+func (s *simulator) decryptionBound() float64 {
+	Q, _ := new(big.Float).SetInt(s.params.QBigInt()).Float64()
+	return math.Log2(Q) - math.Log2(float64(s.params.T())) - 1
+}
+
+// newPoly returns a compact polynomial that can only store slot values and an error variance.
+func (s *simulator) newPoly() *ring.Poly {
+	return &ring.Poly{Coeffs: [][]uint64{make([]uint64, s.params.N()+2)}}
+}
+
+// NewPlaintext allocates a new compact bfv.Plaintext for the simulation.
+func NewPlaintext(params bfv.Parameters) *bfv.Plaintext {
+	return &bfv.Plaintext{Plaintext: &rlwe.Plaintext{Value: newSimulator(params, false).newPoly()}}
+}
+
+// NewPlaintextRingT allocates a new compact bfv.PlaintextRingT for the simulation.
+func NewPlaintextRingT(params bfv.Parameters) *bfv.PlaintextRingT {
+	return &bfv.PlaintextRingT{Plaintext: &rlwe.Plaintext{Value: newSimulator(params, false).newPoly()}}
+}
+
+// NewPlaintextMul allocates a new compact bfv.PlaintextMul for the simulation.
+func NewPlaintextMul(params bfv.Parameters) *bfv.PlaintextMul {
+	return &bfv.PlaintextMul{Plaintext: &rlwe.Plaintext{Value: newSimulator(params, false).newPoly()}}
+}
+
+// NewCiphertext allocates a new compact bfv.Ciphertext for the simulation.
+func NewCiphertext(params bfv.Parameters, degree int) *bfv.Ciphertext {
+	return newSimulator(params, false).newCiphertext(degree)
+}
+
+func (s *simulator) newCiphertext(degree int) *bfv.Ciphertext {
+	ct := &bfv.Ciphertext{Ciphertext: &rlwe.Ciphertext{Value: make([]*ring.Poly, degree+1)}}
+	for i := range ct.Value {
+		ct.Value[i] = s.newPoly()
+	}
+	return ct
+}
+
+// getValues returns a copy of the slot values stored on the polynomial.
+func (s *simulator) getValues(pol *ring.Poly) (values []uint64) {
+	values = make([]uint64, s.params.N())
+	copy(values, pol.Coeffs[0])
+	return
+}
+
+// getVariance returns the variance of the error stored on the polynomial.
+func (s *simulator) getVariance(pol *ring.Poly) variance {
+	if len(pol.Coeffs[0]) < s.params.N()+2 {
+		return variance{}
+	}
+	return variance{math.Float64frombits(pol.Coeffs[0][s.params.N()]), math.Float64frombits(pol.Coeffs[0][s.params.N()+1])}
+}
+
+// set stores the slot values and the variance of the error on the polynomial.
+func (s *simulator) set(pol *ring.Poly, values []uint64, v variance) {
+	if len(pol.Coeffs[0]) < s.params.N()+2 {
+		pol.Coeffs[0] = make([]uint64, s.params.N()+2)
+	}
+	copy(pol.Coeffs[0], values)
+	pol.Coeffs[0][s.params.N()] = math.Float64bits(v.independent)
+	pol.Coeffs[0][s.params.N()+1] = math.Float64bits(v.correlated)
+}
+
+// setOutput sets the degree, the slot values and the variance of the error of the ciphertext.
+func (s *simulator) setOutput(ct *bfv.Ciphertext, degree int, values []uint64, v variance) {
+	if ct.Degree() > degree {
+		ct.Value = ct.Value[:degree+1]
+	}
+	for ct.Degree() < degree {
+		ct.Value = append(ct.Value, s.newPoly())
+	}
+	s.set(ct.Value[0], values, v)
+}
+
+// rotateColumns returns the values with each of their two rows rotated by k positions to the left.
+func rotateColumns(values []uint64, k int) (res []uint64) {
+	res = make([]uint64, len(values))
+	n := len(values) >> 1
+	k = ((k % n) + n) % n
+	for i := 0; i < n; i++ {
+		res[i] = values[(i+k)%n]
+		res[i+n] = values[n+(i+k)%n]
+	}
+	return
+}
+
+// rotateRows returns the values with their two rows swapped.
+func rotateRows(values []uint64) (res []uint64) {
+	n := len(values) >> 1
+	res = make([]uint64, len(values))
+	copy(res, values[n:])
+	copy(res[n:], values[:n])
+	return
+}
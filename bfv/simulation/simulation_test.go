@@ -0,0 +1,144 @@
+package simulation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tuneinsight/lattigo/v3/bfv"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+type testContext struct {
+	params    bfv.Parameters
+	encoder   bfv.Encoder
+	encryptor bfv.Encryptor
+	decryptor bfv.Decryptor
+	eval      bfv.Evaluator
+	newPt     func() *bfv.Plaintext
+	newPtMul  func() *bfv.PlaintextMul
+}
+
+var rotations = []int{1, 2, 4, 8, 16, 32, 64, 128, 256, 512, 1024, 2047}
+
+func newTestContext(params bfv.Parameters, simulated, noise bool) *testContext {
+
+	if simulated {
+		sk := rlwe.NewSecretKey(params.Parameters)
+		return &testContext{
+			params:    params,
+			encoder:   NewEncoder(params),
+			encryptor: NewEncryptor(params, sk, noise),
+			decryptor: NewDecryptor(params, sk),
+			eval:      NewEvaluator(params, NewEvaluationKey(params, 1, rotations, true), noise),
+			newPt:     func() *bfv.Plaintext { return NewPlaintext(params) },
+			newPtMul:  func() *bfv.PlaintextMul { return NewPlaintextMul(params) },
+		}
+	}
+
+	kgen := bfv.NewKeyGenerator(params)
+	sk := kgen.GenSecretKey()
+	rlk := kgen.GenRelinearizationKey(sk, 1)
+	rtks := kgen.GenRotationKeysForRotations(rotations, true, sk)
+
+	return &testContext{
+		params:    params,
+		encoder:   bfv.NewEncoder(params),
+		encryptor: bfv.NewEncryptor(params, sk),
+		decryptor: bfv.NewDecryptor(params, sk),
+		eval:      bfv.NewEvaluator(params, rlwe.EvaluationKey{Rlk: rlk, Rtks: rtks}),
+		newPt:     func() *bfv.Plaintext { return bfv.NewPlaintext(params) },
+		newPtMul:  func() *bfv.PlaintextMul { return bfv.NewPlaintextMul(params) },
+	}
+}
+
+// evaluate computes a circuit using all the operations of the evaluator and returns the decrypted result.
+func (tc *testContext) evaluate(coeffs []uint64) []uint64 {
+
+	eval := tc.eval
+
+	pt := tc.newPt()
+	tc.encoder.EncodeUint(coeffs, pt)
+	ct := tc.encryptor.EncryptNew(pt)
+
+	ptMul := tc.newPtMul()
+	tc.encoder.EncodeUintMul(coeffs, ptMul)
+
+	tmp := eval.MulNew(ct, ct)
+	eval.Relinearize(tmp, tmp)
+	eval.Sub(tmp, pt, tmp)
+	eval.MulScalar(tmp, 3, tmp)
+	eval.Add(tmp, eval.MulNew(ct, ptMul), tmp)
+	eval.RotateColumns(tmp, 1, tmp)
+	eval.RotateRows(tmp, tmp)
+	eval.Neg(tmp, tmp)
+
+	sum := eval.NegNew(ct)
+	eval.InnerSum(sum, sum)
+	eval.Add(tmp, sum, tmp)
+
+	return tc.encoder.DecodeUintNew(tc.decryptor.DecryptNew(tmp))
+}
+
+func TestSimulation(t *testing.T) {
+
+	params, err := bfv.NewParametersFromLiteral(bfv.PN12QP109)
+	require.NoError(t, err)
+
+	coeffs := make([]uint64, params.N())
+	for i := range coeffs {
+		coeffs[i] = utils.RandUint64() % params.T()
+	}
+
+	t.Run("Correctness", func(t *testing.T) {
+		require.Equal(t, newTestContext(params, false, false).evaluate(coeffs), newTestContext(params, true, false).evaluate(coeffs))
+		require.Equal(t, newTestContext(params, false, false).evaluate(coeffs), newTestContext(params, true, true).evaluate(coeffs))
+	})
+
+	t.Run("Noise", func(t *testing.T) {
+
+		// As with the bfv package, the noise budget of PN12QP109 does not allow two successive multiplications.
+		for _, noise := range []bool{false, true} {
+
+			tc := newTestContext(params, true, noise)
+
+			pt := NewPlaintext(params)
+			tc.encoder.EncodeUint(coeffs, pt)
+			ct := tc.encryptor.EncryptNew(pt)
+
+			for i := 0; i < 2; i++ {
+				ct = tc.eval.RelinearizeNew(tc.eval.MulNew(ct, ct))
+			}
+
+			want := make([]uint64, len(coeffs))
+			for i := range want {
+				want[i] = mulMod(coeffs[i], coeffs[i], params.T())
+				want[i] = mulMod(want[i], want[i], params.T())
+			}
+
+			if noise {
+				require.NotEqual(t, want, tc.encoder.DecodeUintNew(tc.decryptor.DecryptNew(ct)))
+			} else {
+				require.Equal(t, want, tc.encoder.DecodeUintNew(tc.decryptor.DecryptNew(ct)))
+			}
+		}
+	})
+
+	t.Run("Constraints", func(t *testing.T) {
+
+		tc := newTestContext(params, true, false)
+		eval := NewEvaluator(params, rlwe.EvaluationKey{}, false)
+
+		pt := NewPlaintext(params)
+		tc.encoder.EncodeUint(coeffs, pt)
+		ct := tc.encryptor.EncryptNew(pt)
+
+		require.Panics(t, func() { eval.RelinearizeNew(eval.MulNew(ct, ct)) })
+		require.Panics(t, func() { eval.RotateColumnsNew(ct, 1) })
+		require.Panics(t, func() { eval.RotateRowsNew(ct) })
+		require.Panics(t, func() { tc.eval.RotateColumnsNew(ct, 3) })
+		require.Panics(t, func() { tc.eval.RotateColumnsNew(tc.eval.MulNew(ct, ct), 1) })
+		require.Panics(t, func() { tc.eval.AddNew(ct, NewPlaintextMul(params)) })
+		require.Panics(t, func() { tc.eval.NegNew(pt) })
+	})
+}
@@ -0,0 +1,166 @@
+package circuit
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/tuneinsight/lattigo/v3/bfv"
+)
+
+// BFVEvaluator is an Evaluator operating on *bfv.Ciphertext. Constants and vectors must have
+// integer real parts and zero imaginary parts, and are reduced modulo the plaintext modulus.
+type BFVEvaluator struct {
+	params  bfv.Parameters
+	eval    bfv.Evaluator
+	encoder bfv.Encoder
+}
+
+// NewBFVEvaluator creates a new BFVEvaluator from the given bfv.Evaluator and bfv.Encoder.
+func NewBFVEvaluator(params bfv.Parameters, eval bfv.Evaluator, encoder bfv.Encoder) *BFVEvaluator {
+	return &BFVEvaluator{params: params, eval: eval, encoder: encoder}
+}
+
+func getBFVCiphertext(op Operand) (*bfv.Ciphertext, error) {
+	ct, ok := op.(*bfv.Ciphertext)
+	if !ok {
+		return nil, fmt.Errorf("invalid operand type: %T, expected *bfv.Ciphertext", op)
+	}
+	return ct, nil
+}
+
+func getBFVCiphertexts(op0, op1 Operand) (ct0, ct1 *bfv.Ciphertext, err error) {
+	if ct0, err = getBFVCiphertext(op0); err != nil {
+		return
+	}
+	ct1, err = getBFVCiphertext(op1)
+	return
+}
+
+// toUint64 returns the real part of c reduced modulo T, or an error if c is not a real integer.
+func (eval *BFVEvaluator) toUint64(c complex128) (uint64, error) {
+
+	if imag(c) != 0 || real(c) != math.Round(real(c)) {
+		return 0, fmt.Errorf("invalid constant %v: must be a real integer", c)
+	}
+
+	t := float64(eval.params.T())
+	r := math.Mod(real(c), t)
+	if r < 0 {
+		r += t
+	}
+
+	return uint64(r), nil
+}
+
+func (eval *BFVEvaluator) toUint64Vector(vector []complex128) (coeffs []uint64, err error) {
+
+	if len(vector) > eval.params.N() {
+		return nil, fmt.Errorf("invalid vector length: %d > %d slots", len(vector), eval.params.N())
+	}
+
+	coeffs = make([]uint64, eval.params.N())
+	for i := range vector {
+		if coeffs[i], err = eval.toUint64(vector[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return
+}
+
+// Add returns op0 + op1.
+func (eval *BFVEvaluator) Add(op0, op1 Operand) (Operand, error) {
+	ct0, ct1, err := getBFVCiphertexts(op0, op1)
+	if err != nil {
+		return nil, err
+	}
+	return eval.eval.AddNew(ct0, ct1), nil
+}
+
+// Sub returns op0 - op1.
+func (eval *BFVEvaluator) Sub(op0, op1 Operand) (Operand, error) {
+	ct0, ct1, err := getBFVCiphertexts(op0, op1)
+	if err != nil {
+		return nil, err
+	}
+	return eval.eval.SubNew(ct0, ct1), nil
+}
+
+// Mul returns op0 * op1, relinearized.
+func (eval *BFVEvaluator) Mul(op0, op1 Operand) (Operand, error) {
+	ct0, ct1, err := getBFVCiphertexts(op0, op1)
+	if err != nil {
+		return nil, err
+	}
+	ctOut := eval.eval.MulNew(ct0, ct1)
+	eval.eval.Relinearize(ctOut, ctOut)
+	return ctOut, nil
+}
+
+// AddConst returns op + constant.
+func (eval *BFVEvaluator) AddConst(op Operand, constant complex128) (Operand, error) {
+
+	ct, err := getBFVCiphertext(op)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := eval.toUint64(constant)
+	if err != nil {
+		return nil, err
+	}
+
+	coeffs := make([]uint64, eval.params.N())
+	for i := range coeffs {
+		coeffs[i] = c
+	}
+
+	pt := bfv.NewPlaintext(eval.params)
+	eval.encoder.EncodeUint(coeffs, pt)
+
+	return eval.eval.AddNew(ct, pt), nil
+}
+
+// MulConst returns op * constant.
+func (eval *BFVEvaluator) MulConst(op Operand, constant complex128) (Operand, error) {
+
+	ct, err := getBFVCiphertext(op)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := eval.toUint64(constant)
+	if err != nil {
+		return nil, err
+	}
+
+	return eval.eval.MulScalarNew(ct, c), nil
+}
+
+// MulVector returns the slot-wise product between op and vector.
+func (eval *BFVEvaluator) MulVector(op Operand, vector []complex128) (Operand, error) {
+
+	ct, err := getBFVCiphertext(op)
+	if err != nil {
+		return nil, err
+	}
+
+	coeffs, err := eval.toUint64Vector(vector)
+	if err != nil {
+		return nil, err
+	}
+
+	pt := bfv.NewPlaintextMul(eval.params)
+	eval.encoder.EncodeUintMul(coeffs, pt)
+
+	return eval.eval.MulNew(ct, pt), nil
+}
+
+// Rotate returns op with both rows rotated by k positions to the left.
+func (eval *BFVEvaluator) Rotate(op Operand, k int) (Operand, error) {
+	ct, err := getBFVCiphertext(op)
+	if err != nil {
+		return nil, err
+	}
+	return eval.eval.RotateColumnsNew(ct, k), nil
+}
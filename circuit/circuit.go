@@ -0,0 +1,413 @@
+// Package circuit implements a computation graph on top of the ckks and bfv schemes. A Circuit is described
+// as a graph of abstract operations, from which the required evaluation keys and levels are derived, and which
+// can be executed on encrypted data or on cleartext data through the Evaluator interface.
+package circuit
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+)
+
+type opType int
+
+const (
+	opInput = opType(iota)
+	opAdd
+	opSub
+	opMul
+	opAddConst
+	opMulConst
+	opMulVector
+	opRotate
+)
+
+var opNames = map[opType]string{
+	opInput:     "Input",
+	opAdd:       "Add",
+	opSub:       "Sub",
+	opMul:       "Mul",
+	opAddConst:  "AddConst",
+	opMulConst:  "MulConst",
+	opMulVector: "MulVector",
+	opRotate:    "Rotate",
+}
+
+func (op opType) String() string {
+	return opNames[op]
+}
+
+// Node is a node of a Circuit, representing the result of an operation.
+type Node struct {
+	id       int
+	op       opType
+	inputs   []*Node
+	name     string
+	k        int
+	constant complex128
+	vector   []complex128
+	depth    int
+}
+
+// Depth returns the multiplicative depth of the Node, i.e. the maximum number of
+// non-integer multiplications on a path from an input to the Node.
+func (n *Node) Depth() int {
+	return n.depth
+}
+
+// Circuit is a computation graph of abstract operations. Nodes are created by the methods of
+// the Circuit and are always added after their inputs, hence the nodes of a Circuit are
+// topologically ordered.
+type Circuit struct {
+	nodes       []*Node
+	inputs      map[string]*Node
+	outputs     map[string]*Node
+	outputNames []string
+}
+
+// NewCircuit creates a new empty Circuit.
+func NewCircuit() *Circuit {
+	return &Circuit{inputs: make(map[string]*Node), outputs: make(map[string]*Node)}
+}
+
+func (c *Circuit) newNode(op opType, inputs ...*Node) (n *Node) {
+
+	for _, in := range inputs {
+		if in == nil || in.id >= len(c.nodes) || c.nodes[in.id] != in {
+			panic(fmt.Errorf("cannot add %s: input node does not belong to this circuit", op))
+		}
+	}
+
+	n = &Node{id: len(c.nodes), op: op, inputs: inputs}
+
+	for _, in := range inputs {
+		if in.depth > n.depth {
+			n.depth = in.depth
+		}
+	}
+
+	c.nodes = append(c.nodes, n)
+
+	return
+}
+
+// Input adds a new named input to the Circuit.
+func (c *Circuit) Input(name string) (n *Node) {
+
+	if _, exists := c.inputs[name]; exists {
+		panic(fmt.Errorf("cannot add Input: input %s already exists", name))
+	}
+
+	n = c.newNode(opInput)
+	n.name = name
+	c.inputs[name] = n
+	return
+}
+
+// Output sets the input node as a named output of the Circuit.
+func (c *Circuit) Output(name string, n *Node) {
+
+	if _, exists := c.outputs[name]; exists {
+		panic(fmt.Errorf("cannot add Output: output %s already exists", name))
+	}
+
+	if n == nil || n.id >= len(c.nodes) || c.nodes[n.id] != n {
+		panic("cannot add Output: node does not belong to this circuit")
+	}
+
+	c.outputs[name] = n
+	c.outputNames = append(c.outputNames, name)
+}
+
+// Add adds a node computing n0 + n1.
+func (c *Circuit) Add(n0, n1 *Node) *Node {
+	return c.newNode(opAdd, n0, n1)
+}
+
+// Sub adds a node computing n0 - n1.
+func (c *Circuit) Sub(n0, n1 *Node) *Node {
+	return c.newNode(opSub, n0, n1)
+}
+
+// Mul adds a node computing n0 * n1.
+func (c *Circuit) Mul(n0, n1 *Node) (n *Node) {
+	n = c.newNode(opMul, n0, n1)
+	n.depth++
+	return
+}
+
+// AddConst adds a node computing n0 + constant.
+func (c *Circuit) AddConst(n0 *Node, constant complex128) (n *Node) {
+	n = c.newNode(opAddConst, n0)
+	n.constant = constant
+	return
+}
+
+// MulConst adds a node computing n0 * constant.
+func (c *Circuit) MulConst(n0 *Node, constant complex128) (n *Node) {
+	n = c.newNode(opMulConst, n0)
+	n.constant = constant
+	if !isGaussianInteger(constant) {
+		n.depth++
+	}
+	return
+}
+
+// MulVector adds a node computing the slot-wise product between n0 and a plaintext vector.
+func (c *Circuit) MulVector(n0 *Node, vector []complex128) (n *Node) {
+	n = c.newNode(opMulVector, n0)
+	n.vector = make([]complex128, len(vector))
+	copy(n.vector, vector)
+	n.depth++
+	return
+}
+
+// Rotate adds a node computing the rotation of n0 by k positions to the left.
+func (c *Circuit) Rotate(n0 *Node, k int) (n *Node) {
+	if k == 0 {
+		return n0
+	}
+	n = c.newNode(opRotate, n0)
+	n.k = k
+	return
+}
+
+// Poly adds the nodes computing the polynomial sum_i coeffs[i] * n0^i, using a power basis
+// of depth ceil(log2(deg)) followed by a multiplication by the coefficients.
+func (c *Circuit) Poly(n0 *Node, coeffs []complex128) (n *Node) {
+
+	if len(coeffs) == 0 {
+		panic("cannot add Poly: coeffs cannot be empty")
+	}
+
+	powers := map[int]*Node{1: n0}
+
+	for i := 2; i < len(coeffs); i++ {
+		if coeffs[i] != 0 {
+			c.power(i, powers)
+		}
+	}
+
+	for i := 1; i < len(coeffs); i++ {
+		if coeffs[i] != 0 {
+			if n == nil {
+				n = c.MulConst(powers[i], coeffs[i])
+			} else {
+				n = c.Add(n, c.MulConst(powers[i], coeffs[i]))
+			}
+		}
+	}
+
+	if n == nil {
+		n = c.MulConst(n0, 0)
+	}
+
+	if coeffs[0] != 0 {
+		n = c.AddConst(n, coeffs[0])
+	}
+
+	return
+}
+
+// power recursively adds the nodes computing x^i = x^{2^j} * x^{i-2^j} with 2^j the largest
+// power of two smaller than i.
+func (c *Circuit) power(i int, powers map[int]*Node) *Node {
+
+	if n, ok := powers[i]; ok {
+		return n
+	}
+
+	j := 1 << (bits.Len64(uint64(i-1)) - 1)
+
+	powers[i] = c.Mul(c.power(j, powers), c.power(i-j, powers))
+
+	return powers[i]
+}
+
+// LinearTransform adds the nodes computing the linear transform sum_k diags[k] * Rotate(n0, k),
+// where diags[k] is the k-th diagonal of the matrix.
+func (c *Circuit) LinearTransform(n0 *Node, diags map[int][]complex128) (n *Node) {
+
+	if len(diags) == 0 {
+		panic("cannot add LinearTransform: diags cannot be empty")
+	}
+
+	rotations := make([]int, 0, len(diags))
+	for k := range diags {
+		rotations = append(rotations, k)
+	}
+	sort.Ints(rotations)
+
+	for _, k := range rotations {
+		if n == nil {
+			n = c.MulVector(c.Rotate(n0, k), diags[k])
+		} else {
+			n = c.Add(n, c.MulVector(c.Rotate(n0, k), diags[k]))
+		}
+	}
+
+	return
+}
+
+// InputNames returns the names of the inputs of the Circuit, in sorted order.
+func (c *Circuit) InputNames() (names []string) {
+	names = make([]string, 0, len(c.inputs))
+	for name := range c.inputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+// OutputNames returns the names of the outputs of the Circuit, in the order in which they were added.
+func (c *Circuit) OutputNames() (names []string) {
+	names = make([]string, len(c.outputNames))
+	copy(names, c.outputNames)
+	return
+}
+
+// activeNodes returns the nodes on which at least one output depends, in topological order.
+func (c *Circuit) activeNodes() (nodes []*Node) {
+
+	active := make([]bool, len(c.nodes))
+	for _, n := range c.outputs {
+		active[n.id] = true
+	}
+
+	for i := len(c.nodes) - 1; i >= 0; i-- {
+		if active[i] {
+			for _, in := range c.nodes[i].inputs {
+				active[in.id] = true
+			}
+		}
+	}
+
+	for i, n := range c.nodes {
+		if active[i] {
+			nodes = append(nodes, n)
+		}
+	}
+
+	return
+}
+
+// Depth returns the multiplicative depth of the Circuit, i.e. the maximum depth of its outputs.
+func (c *Circuit) Depth() (depth int) {
+	for _, n := range c.outputs {
+		if n.depth > depth {
+			depth = n.depth
+		}
+	}
+	return
+}
+
+// Rotations returns the sorted list of rotations performed by the Circuit, which can be given to
+// rlwe.KeyGenerator.GenRotationKeysForRotations to generate the required rotation keys.
+func (c *Circuit) Rotations() (rotations []int) {
+
+	set := make(map[int]bool)
+	for _, n := range c.activeNodes() {
+		if n.op == opRotate {
+			set[n.k] = true
+		}
+	}
+
+	rotations = make([]int, 0, len(set))
+	for k := range set {
+		rotations = append(rotations, k)
+	}
+	sort.Ints(rotations)
+
+	return
+}
+
+// RelinearizationKeyRequired returns true if the Circuit performs at least one
+// ciphertext-ciphertext multiplication.
+func (c *Circuit) RelinearizationKeyRequired() bool {
+	for _, n := range c.activeNodes() {
+		if n.op == opMul {
+			return true
+		}
+	}
+	return false
+}
+
+// Execute evaluates the Circuit on the given inputs using the provided Evaluator, and returns the map of
+// its outputs. The inputs must contain an Operand for each input of the Circuit on which an output depends.
+// Nodes on which no output depends are not evaluated.
+// The panics raised by the underlying scheme evaluators, for example on a missing relinearization or
+// rotation key or on an operand whose level is too low, are returned as errors.
+// The inputs are not modified: the Evaluators that modify their operands in place, such as the
+// CKKSEvaluator which rescales them, are given copies of the inputs.
+func (c *Circuit) Execute(eval Evaluator, inputs map[string]Operand) (outputs map[string]Operand, err error) {
+
+	values := make(map[*Node]Operand)
+
+	for _, n := range c.activeNodes() {
+
+		var value Operand
+
+		if n.op == opInput {
+			var ok bool
+			if value, ok = inputs[n.name]; !ok || value == nil {
+				return nil, fmt.Errorf("cannot Execute: missing input %s", n.name)
+			}
+			if copier, ok := eval.(inputCopier); ok {
+				if value, err = copier.copyInput(value); err != nil {
+					return nil, fmt.Errorf("cannot Execute: input %s: %w", n.name, err)
+				}
+			}
+		} else if value, err = evaluateNode(eval, n, values); err != nil {
+			return nil, fmt.Errorf("cannot Execute: %s: %w", n.op, err)
+		}
+
+		values[n] = value
+	}
+
+	outputs = make(map[string]Operand, len(c.outputs))
+	for name, n := range c.outputs {
+		outputs[name] = values[n]
+	}
+
+	return
+}
+
+// inputCopier is implemented by the Evaluators that modify their operands in place,
+// on which Execute evaluates the Circuit on copies of the inputs.
+type inputCopier interface {
+	copyInput(op Operand) (Operand, error)
+}
+
+// evaluateNode evaluates the operation of the node n on the values of its inputs
+// and converts a panic of the Evaluator into an error.
+func evaluateNode(eval Evaluator, n *Node, values map[*Node]Operand) (value Operand, err error) {
+
+	defer func() {
+		if r := recover(); r != nil {
+			value, err = nil, fmt.Errorf("evaluator panic: %v", r)
+		}
+	}()
+
+	switch n.op {
+	case opAdd:
+		return eval.Add(values[n.inputs[0]], values[n.inputs[1]])
+	case opSub:
+		return eval.Sub(values[n.inputs[0]], values[n.inputs[1]])
+	case opMul:
+		return eval.Mul(values[n.inputs[0]], values[n.inputs[1]])
+	case opAddConst:
+		return eval.AddConst(values[n.inputs[0]], n.constant)
+	case opMulConst:
+		return eval.MulConst(values[n.inputs[0]], n.constant)
+	case opMulVector:
+		return eval.MulVector(values[n.inputs[0]], n.vector)
+	case opRotate:
+		return eval.Rotate(values[n.inputs[0]], n.k)
+	}
+
+	return nil, fmt.Errorf("unknown operation")
+}
+
+func isGaussianInteger(c complex128) bool {
+	return real(c) == float64(int64(real(c))) && imag(c) == float64(int64(imag(c)))
+}
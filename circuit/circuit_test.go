@@ -0,0 +1,207 @@
+package circuit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tuneinsight/lattigo/v3/bfv"
+	"github.com/tuneinsight/lattigo/v3/ckks"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+var testCKKSParams = ckks.ParametersLiteral{
+	LogN:         10,
+	LogQ:         []int{55, 40, 40, 40, 40, 40, 40},
+	LogP:         []int{60},
+	DefaultScale: 1 << 40,
+	Sigma:        rlwe.DefaultSigma,
+}
+
+// newTestCircuit returns a circuit computing LinearTransform(Poly(x * y + Rotate(x, 1))).
+func newTestCircuit(slots int) *Circuit {
+
+	c := NewCircuit()
+
+	x := c.Input("x")
+	y := c.Input("y")
+
+	z := c.Add(c.Mul(x, y), c.Rotate(x, 1))
+
+	p := c.Poly(z, []complex128{1, 2, 0, -3})
+
+	diags := map[int][]complex128{0: make([]complex128, slots), -2: make([]complex128, slots), 3: make([]complex128, slots)}
+	for i := 0; i < slots; i++ {
+		diags[0][i] = 1
+		diags[-2][i] = 2
+		diags[3][i] = complex(float64(i%3), 0)
+	}
+
+	c.Output("out", c.LinearTransform(p, diags))
+	c.Output("z", z)
+
+	// Unused node
+	c.Rotate(y, 5)
+
+	return c
+}
+
+func TestCircuit(t *testing.T) {
+
+	t.Run("Analysis", func(t *testing.T) {
+		c := newTestCircuit(8)
+		require.Equal(t, []int{-2, 1, 3}, c.Rotations())
+		require.Equal(t, 4, c.Depth())
+		require.True(t, c.RelinearizationKeyRequired())
+		require.Equal(t, []string{"x", "y"}, c.InputNames())
+		require.Equal(t, []string{"out", "z"}, c.OutputNames())
+
+		_, err := c.Execute(NewSimulationEvaluator(8, 8, 0), map[string]Operand{"x": make([]complex128, 8)})
+		require.Error(t, err)
+	})
+
+	t.Run("Simulation", func(t *testing.T) {
+		c := NewCircuit()
+		x := c.Input("x")
+		c.Output("out", c.Poly(c.Rotate(x, -1), []complex128{1, 0, 1}))
+
+		outputs, err := c.Execute(NewSimulationEvaluator(4, 2, 5), map[string]Operand{"x": []complex128{1, 2, 3, 4}})
+		require.NoError(t, err)
+		require.Equal(t, []complex128{0, 2, 2, 0}, outputs["out"])
+
+		// The arithmetic modulo t is exact beyond the precision of float64 products
+		t40 := uint64(1<<40) + 15
+		c = NewCircuit()
+		x = c.Input("x")
+		c.Output("out", c.Sub(c.Mul(x, x), c.Add(x, x)))
+
+		outputs, err = c.Execute(NewSimulationEvaluator(2, 2, t40), map[string]Operand{"x": []complex128{complex(float64(t40-1), 0), -2}})
+		require.NoError(t, err)
+		require.Equal(t, []complex128{3, 8}, outputs["out"])
+	})
+
+	t.Run("CKKS", func(t *testing.T) {
+
+		params, err := ckks.NewParametersFromLiteral(testCKKSParams)
+		require.NoError(t, err)
+
+		c := newTestCircuit(params.Slots())
+
+		level, err := c.LevelCKKS(params)
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, level, c.Depth())
+
+		kgen := ckks.NewKeyGenerator(params)
+		sk := kgen.GenSecretKey()
+		rlk := kgen.GenRelinearizationKey(sk, 2)
+		rtks := kgen.GenRotationKeysForRotations(c.Rotations(), false, sk)
+
+		encoder := ckks.NewEncoder(params)
+		encryptor := ckks.NewEncryptor(params, sk)
+		decryptor := ckks.NewDecryptor(params, sk)
+		eval := NewCKKSEvaluator(params, ckks.NewEvaluator(params, rlwe.EvaluationKey{Rlk: rlk, Rtks: rtks}), encoder, nil)
+
+		values := map[string]Operand{}
+		inputs := map[string]Operand{}
+		for _, name := range c.InputNames() {
+			v := make([]complex128, params.Slots())
+			for i := range v {
+				v[i] = complex(utils.RandFloat64(-0.5, 0.5), utils.RandFloat64(-0.5, 0.5))
+			}
+			values[name] = v
+			inputs[name] = encryptor.EncryptNew(encoder.EncodeNew(v, level, params.DefaultScale(), params.LogSlots()))
+		}
+
+		want, err := c.Execute(NewSimulationEvaluatorCKKS(params), values)
+		require.NoError(t, err)
+
+		have, err := c.Execute(eval, inputs)
+		require.NoError(t, err)
+
+		for _, name := range c.OutputNames() {
+			ct := have[name].(*ckks.Ciphertext)
+			precStats := ckks.GetPrecisionStats(params, encoder, decryptor, want[name], ct, params.LogSlots(), 0)
+			require.GreaterOrEqual(t, precStats.MeanPrecision.L2, 15.0)
+		}
+
+		// The inputs are not modified, even when they are rescaled by the evaluation
+		require.Less(t, level, params.MaxLevel())
+		inputsCopy := map[string]*ckks.Ciphertext{}
+		for name := range inputs {
+			pt := encoder.EncodeNew(values[name].([]complex128), level+1, params.DefaultScale()*params.QiFloat64(level+1), params.LogSlots())
+			inputs[name] = encryptor.EncryptNew(pt)
+			inputsCopy[name] = inputs[name].(*ckks.Ciphertext).CopyNew()
+		}
+		_, err = c.Execute(eval, inputs)
+		require.NoError(t, err)
+		for name, ct := range inputsCopy {
+			require.Equal(t, ct, inputs[name])
+		}
+
+		if level > 0 {
+			for name := range inputs {
+				inputs[name] = encryptor.EncryptNew(encoder.EncodeNew(values[name].([]complex128), level-1, params.DefaultScale(), params.LogSlots()))
+			}
+			_, err = c.Execute(eval, inputs)
+			require.Error(t, err)
+		}
+
+		// Missing rotation keys are reported as an error
+		evalNoRtks := NewCKKSEvaluator(params, ckks.NewEvaluator(params, rlwe.EvaluationKey{Rlk: rlk}), encoder, nil)
+		for name := range inputs {
+			inputs[name] = encryptor.EncryptNew(encoder.EncodeNew(values[name].([]complex128), level, params.DefaultScale(), params.LogSlots()))
+		}
+		_, err = c.Execute(evalNoRtks, inputs)
+		require.Error(t, err)
+	})
+
+	t.Run("BFV", func(t *testing.T) {
+
+		params, err := bfv.NewParametersFromLiteral(bfv.PN13QP218)
+		require.NoError(t, err)
+
+		c := newTestCircuit(params.N())
+
+		kgen := bfv.NewKeyGenerator(params)
+		sk := kgen.GenSecretKey()
+		rlk := kgen.GenRelinearizationKey(sk, 1)
+		rtks := kgen.GenRotationKeysForRotations(c.Rotations(), false, sk)
+
+		encoder := bfv.NewEncoder(params)
+		encryptor := bfv.NewEncryptor(params, sk)
+		decryptor := bfv.NewDecryptor(params, sk)
+		eval := NewBFVEvaluator(params, bfv.NewEvaluator(params, rlwe.EvaluationKey{Rlk: rlk, Rtks: rtks}), encoder)
+
+		values := map[string]Operand{}
+		inputs := map[string]Operand{}
+		for _, name := range c.InputNames() {
+			v := make([]complex128, params.N())
+			coeffs := make([]uint64, params.N())
+			for i := range v {
+				coeffs[i] = utils.RandUint64() % params.T()
+				v[i] = complex(float64(coeffs[i]), 0)
+			}
+			pt := bfv.NewPlaintext(params)
+			encoder.EncodeUint(coeffs, pt)
+			values[name] = v
+			inputs[name] = encryptor.EncryptNew(pt)
+		}
+
+		want, err := c.Execute(NewSimulationEvaluatorBFV(params), values)
+		require.NoError(t, err)
+
+		have, err := c.Execute(eval, inputs)
+		require.NoError(t, err)
+
+		for _, name := range c.OutputNames() {
+			coeffs := encoder.DecodeUintNew(decryptor.DecryptNew(have[name].(*bfv.Ciphertext)))
+			for i := range coeffs {
+				require.Equal(t, real(want[name].([]complex128)[i]), float64(coeffs[i]))
+			}
+		}
+
+		// Missing relinearization key is reported as an error
+		_, err = c.Execute(NewBFVEvaluator(params, bfv.NewEvaluator(params, rlwe.EvaluationKey{Rtks: rtks}), encoder), inputs)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,302 @@
+package circuit
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/tuneinsight/lattigo/v3/ckks"
+)
+
+// CKKSEvaluator is an Evaluator operating on *ckks.Ciphertext. The level and scale of the
+// operands are managed by a ckks.ManagedEvaluator, which bootstraps them if a ckks.Bootstrapper
+// is provided and if they do not have enough levels for the next operation.
+type CKKSEvaluator struct {
+	params  ckks.Parameters
+	eval    *ckks.ManagedEvaluator
+	encoder ckks.Encoder
+}
+
+// NewCKKSEvaluator creates a new CKKSEvaluator from the given ckks.Evaluator and ckks.Encoder.
+// The ckks.Bootstrapper can be nil.
+func NewCKKSEvaluator(params ckks.Parameters, eval ckks.Evaluator, encoder ckks.Encoder, btp ckks.Bootstrapper) *CKKSEvaluator {
+	return &CKKSEvaluator{params: params, eval: ckks.NewManagedEvaluator(params, eval, btp), encoder: encoder}
+}
+
+// copyInput returns a copy of the input op, since the ckks.ManagedEvaluator rescales its operands in place.
+func (eval *CKKSEvaluator) copyInput(op Operand) (Operand, error) {
+	ct, err := getCKKSCiphertext(op)
+	if err != nil {
+		return nil, err
+	}
+	return ct.CopyNew(), nil
+}
+
+func getCKKSCiphertext(op Operand) (*ckks.Ciphertext, error) {
+	ct, ok := op.(*ckks.Ciphertext)
+	if !ok {
+		return nil, fmt.Errorf("invalid operand type: %T, expected *ckks.Ciphertext", op)
+	}
+	return ct, nil
+}
+
+func getCKKSCiphertexts(op0, op1 Operand) (ct0, ct1 *ckks.Ciphertext, err error) {
+	if ct0, err = getCKKSCiphertext(op0); err != nil {
+		return
+	}
+	ct1, err = getCKKSCiphertext(op1)
+	return
+}
+
+// Add returns op0 + op1.
+func (eval *CKKSEvaluator) Add(op0, op1 Operand) (Operand, error) {
+	ct0, ct1, err := getCKKSCiphertexts(op0, op1)
+	if err != nil {
+		return nil, err
+	}
+	return eval.eval.AddNew(ct0, ct1), nil
+}
+
+// Sub returns op0 - op1.
+func (eval *CKKSEvaluator) Sub(op0, op1 Operand) (Operand, error) {
+	ct0, ct1, err := getCKKSCiphertexts(op0, op1)
+	if err != nil {
+		return nil, err
+	}
+	return eval.eval.SubNew(ct0, ct1), nil
+}
+
+// Mul returns op0 * op1, relinearized.
+func (eval *CKKSEvaluator) Mul(op0, op1 Operand) (Operand, error) {
+	ct0, ct1, err := getCKKSCiphertexts(op0, op1)
+	if err != nil {
+		return nil, err
+	}
+	return eval.eval.MulRelinNew(ct0, ct1), nil
+}
+
+// AddConst returns op + constant.
+func (eval *CKKSEvaluator) AddConst(op Operand, constant complex128) (Operand, error) {
+	ct, err := getCKKSCiphertext(op)
+	if err != nil {
+		return nil, err
+	}
+	return eval.eval.AddConstNew(ct, constant), nil
+}
+
+// MulConst returns op * constant.
+func (eval *CKKSEvaluator) MulConst(op Operand, constant complex128) (Operand, error) {
+	ct, err := getCKKSCiphertext(op)
+	if err != nil {
+		return nil, err
+	}
+	return eval.eval.MultByConstNew(ct, constant), nil
+}
+
+// MulVector returns the slot-wise product between op and vector. The vector is encoded at the level
+// of op and with a scale equal to the modulus at this level, so that the rescaling restores the scale of op.
+func (eval *CKKSEvaluator) MulVector(op Operand, vector []complex128) (Operand, error) {
+
+	ct, err := getCKKSCiphertext(op)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(vector) > eval.params.Slots() {
+		return nil, fmt.Errorf("invalid vector length: %d > %d slots", len(vector), eval.params.Slots())
+	}
+
+	values := make([]complex128, eval.params.Slots())
+	copy(values, vector)
+
+	eval.eval.Prepare(ct, 1)
+
+	pt := eval.encoder.EncodeNew(values, ct.Level(), eval.params.QiFloat64(ct.Level()), eval.params.LogSlots())
+
+	return eval.eval.MulNew(ct, pt), nil
+}
+
+// Rotate returns op rotated by k positions to the left.
+func (eval *CKKSEvaluator) Rotate(op Operand, k int) (Operand, error) {
+	ct, err := getCKKSCiphertext(op)
+	if err != nil {
+		return nil, err
+	}
+	return eval.eval.RotateNew(ct, k), nil
+}
+
+// LevelCKKS returns the minimum level at which the inputs of the Circuit, encrypted with scale params.DefaultScale(),
+// must be given to a CKKSEvaluator for the Circuit to be executed without bootstrapping. The levels and scales of
+// all the intermediate nodes are derived by emulating the management done by the ckks.ManagedEvaluator.
+// Returns an error if the Circuit cannot be executed without bootstrapping.
+func (c *Circuit) LevelCKKS(params ckks.Parameters) (level int, err error) {
+
+	inputs := make(map[string]Operand, len(c.inputs))
+
+	for level = 0; level <= params.MaxLevel(); level++ {
+
+		for name := range c.inputs {
+			inputs[name] = &ckksLevelAndScale{level: level, scale: params.DefaultScale()}
+		}
+
+		if _, err = c.Execute(&ckksLevelEvaluator{params: params}, inputs); err == nil {
+			return level, nil
+		}
+	}
+
+	return -1, fmt.Errorf("circuit cannot be executed without bootstrapping: %w", err)
+}
+
+// ckksLevelAndScale is the Operand of the ckksLevelEvaluator.
+type ckksLevelAndScale struct {
+	level int
+	scale float64
+}
+
+// ckksLevelEvaluator is an Evaluator that only tracks the level and scale of its operands, following
+// the same rules as the ckks.ManagedEvaluator without bootstrapper.
+type ckksLevelEvaluator struct {
+	params ckks.Parameters
+}
+
+func (eval *ckksLevelEvaluator) prepare(op Operand, depth int) (ls *ckksLevelAndScale, err error) {
+
+	ls = op.(*ckksLevelAndScale)
+
+	if ls.level > 0 {
+		if err = eval.rescale(ls, eval.params.DefaultScale()); err != nil {
+			return nil, err
+		}
+	}
+
+	if ls.level < depth {
+		return nil, fmt.Errorf("level %d < %d", ls.level, depth)
+	}
+
+	return
+}
+
+func (eval *ckksLevelEvaluator) rescale(ls *ckksLevelAndScale, minScale float64) error {
+	for ls.level >= 0 && ls.scale/eval.params.QiFloat64(ls.level) >= minScale/2 {
+		ls.scale /= eval.params.QiFloat64(ls.level)
+		ls.level--
+	}
+	if ls.level < 0 {
+		return fmt.Errorf("cannot rescale below level 0")
+	}
+	return nil
+}
+
+func (eval *ckksLevelEvaluator) binary(op0, op1 Operand, depth int) (ls0, ls1 *ckksLevelAndScale, err error) {
+	if ls0, err = eval.prepare(op0, depth); err != nil {
+		return
+	}
+	ls1, err = eval.prepare(op1, depth)
+	return
+}
+
+func (eval *ckksLevelEvaluator) matchScales(ls0, ls1 *ckksLevelAndScale) (err error) {
+
+	compatible := func() bool {
+		ratio := math.Max(ls0.scale, ls1.scale) / math.Min(ls0.scale, ls1.scale)
+		return ratio == math.Round(ratio)
+	}
+
+	if ls0 == ls1 || compatible() {
+		return
+	}
+
+	if ls0.level < ls1.level {
+		ls0, ls1 = ls1, ls0
+	}
+
+	if _, err = eval.prepare(ls0, 1); err != nil {
+		return
+	}
+
+	if compatible() {
+		return
+	}
+
+	if ls0.level > ls1.level+1 {
+		ls0.level = ls1.level + 1
+	}
+
+	ls0.scale *= eval.params.QiFloat64(ls0.level)
+	if err = eval.rescale(ls0, ls1.scale); err != nil {
+		return
+	}
+	ls0.scale = ls1.scale
+
+	return
+}
+
+func (eval *ckksLevelEvaluator) add(op0, op1 Operand) (Operand, error) {
+	ls0, ls1, err := eval.binary(op0, op1, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err = eval.matchScales(ls0, ls1); err != nil {
+		return nil, err
+	}
+	level := ls0.level
+	if ls1.level < level {
+		level = ls1.level
+	}
+	return &ckksLevelAndScale{level: level, scale: math.Max(ls0.scale, ls1.scale)}, nil
+}
+
+func (eval *ckksLevelEvaluator) Add(op0, op1 Operand) (Operand, error) {
+	return eval.add(op0, op1)
+}
+
+func (eval *ckksLevelEvaluator) Sub(op0, op1 Operand) (Operand, error) {
+	return eval.add(op0, op1)
+}
+
+func (eval *ckksLevelEvaluator) Mul(op0, op1 Operand) (Operand, error) {
+	ls0, ls1, err := eval.binary(op0, op1, 1)
+	if err != nil {
+		return nil, err
+	}
+	level := ls0.level
+	if ls1.level < level {
+		level = ls1.level
+	}
+	return &ckksLevelAndScale{level: level, scale: ls0.scale * ls1.scale}, nil
+}
+
+func (eval *ckksLevelEvaluator) AddConst(op Operand, constant complex128) (Operand, error) {
+	ls, err := eval.prepare(op, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &ckksLevelAndScale{level: ls.level, scale: ls.scale}, nil
+}
+
+func (eval *ckksLevelEvaluator) MulConst(op Operand, constant complex128) (Operand, error) {
+	ls, err := eval.prepare(op, 1)
+	if err != nil {
+		return nil, err
+	}
+	scale := ls.scale
+	if !isGaussianInteger(constant) {
+		scale *= eval.params.QiFloat64(ls.level)
+	}
+	return &ckksLevelAndScale{level: ls.level, scale: scale}, nil
+}
+
+func (eval *ckksLevelEvaluator) MulVector(op Operand, vector []complex128) (Operand, error) {
+	ls, err := eval.prepare(op, 1)
+	if err != nil {
+		return nil, err
+	}
+	return &ckksLevelAndScale{level: ls.level, scale: ls.scale * eval.params.QiFloat64(ls.level)}, nil
+}
+
+func (eval *ckksLevelEvaluator) Rotate(op Operand, k int) (Operand, error) {
+	ls, err := eval.prepare(op, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &ckksLevelAndScale{level: ls.level, scale: ls.scale}, nil
+}
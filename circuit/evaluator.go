@@ -0,0 +1,201 @@
+package circuit
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/tuneinsight/lattigo/v3/bfv"
+	"github.com/tuneinsight/lattigo/v3/ckks"
+)
+
+// Operand is a value on which an Evaluator operates, for example a *ckks.Ciphertext,
+// a *bfv.Ciphertext or a []complex128.
+type Operand interface{}
+
+// Evaluator is an interface for the backends on which a Circuit can be executed.
+// Each method returns a new Operand storing the result of the operation.
+type Evaluator interface {
+	Add(op0, op1 Operand) (Operand, error)
+	Sub(op0, op1 Operand) (Operand, error)
+	Mul(op0, op1 Operand) (Operand, error)
+	AddConst(op Operand, constant complex128) (Operand, error)
+	MulConst(op Operand, constant complex128) (Operand, error)
+	MulVector(op Operand, vector []complex128) (Operand, error)
+	Rotate(op Operand, k int) (Operand, error)
+}
+
+// SimulationEvaluator is an Evaluator operating on cleartext vectors of type []complex128.
+// It can be used to test a Circuit without encryption.
+type SimulationEvaluator struct {
+	slots   int
+	rowSize int
+	t       uint64
+}
+
+// NewSimulationEvaluator creates a new SimulationEvaluator on vectors of the given number of slots,
+// arranged in rows of rowSize slots that are rotated independently. If t is non-zero, the arithmetic
+// is done exactly modulo t on the real part of the slots, which must be integers. The results are
+// returned as float64, hence t must be at most 2^53.
+func NewSimulationEvaluator(slots, rowSize int, t uint64) *SimulationEvaluator {
+
+	if rowSize <= 0 || slots%rowSize != 0 {
+		panic(fmt.Errorf("cannot NewSimulationEvaluator: rowSize must divide slots"))
+	}
+
+	if t > 1<<53 {
+		panic(fmt.Errorf("cannot NewSimulationEvaluator: t must be at most 2^53"))
+	}
+
+	return &SimulationEvaluator{slots: slots, rowSize: rowSize, t: t}
+}
+
+// NewSimulationEvaluatorCKKS creates a new SimulationEvaluator matching the slots of the ckks scheme.
+func NewSimulationEvaluatorCKKS(params ckks.Parameters) *SimulationEvaluator {
+	return NewSimulationEvaluator(params.Slots(), params.Slots(), 0)
+}
+
+// NewSimulationEvaluatorBFV creates a new SimulationEvaluator matching the slots of the bfv scheme,
+// which are arranged in two rows of N/2 slots.
+func NewSimulationEvaluatorBFV(params bfv.Parameters) *SimulationEvaluator {
+	return NewSimulationEvaluator(params.N(), params.N()>>1, params.T())
+}
+
+func (eval *SimulationEvaluator) getVector(op Operand) ([]complex128, error) {
+	v, ok := op.([]complex128)
+	if !ok {
+		return nil, fmt.Errorf("invalid operand type: %T, expected []complex128", op)
+	}
+	if len(v) != eval.slots {
+		return nil, fmt.Errorf("invalid operand length: %d, expected %d", len(v), eval.slots)
+	}
+	return v, nil
+}
+
+// toUint returns the real part of c, which must be an integer, reduced modulo t.
+func (eval *SimulationEvaluator) toUint(c complex128) uint64 {
+	r := math.Round(real(c))
+	if r < 0 {
+		v := uint64(-r) % eval.t
+		if v == 0 {
+			return 0
+		}
+		return eval.t - v
+	}
+	return uint64(r) % eval.t
+}
+
+func (eval *SimulationEvaluator) binary(op0, op1 Operand, f func(a, b complex128) complex128, fMod func(a, b, t uint64) uint64) (Operand, error) {
+
+	v0, err := eval.getVector(op0)
+	if err != nil {
+		return nil, err
+	}
+
+	v1, err := eval.getVector(op1)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]complex128, eval.slots)
+	for i := range res {
+		if eval.t == 0 {
+			res[i] = f(v0[i], v1[i])
+		} else {
+			res[i] = complex(float64(fMod(eval.toUint(v0[i]), eval.toUint(v1[i]), eval.t)), 0)
+		}
+	}
+
+	return res, nil
+}
+
+// addMod returns a + b mod t for a, b < t.
+func addMod(a, b, t uint64) uint64 {
+	sum, carry := bits.Add64(a, b, 0)
+	return bits.Rem64(carry, sum, t)
+}
+
+// subMod returns a - b mod t for a, b < t.
+func subMod(a, b, t uint64) uint64 {
+	if a >= b {
+		return a - b
+	}
+	return t - (b - a)
+}
+
+// mulMod returns a * b mod t.
+func mulMod(a, b, t uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	return bits.Rem64(hi, lo, t)
+}
+
+func complexAdd(a, b complex128) complex128 { return a + b }
+func complexSub(a, b complex128) complex128 { return a - b }
+func complexMul(a, b complex128) complex128 { return a * b }
+
+// Add returns op0 + op1.
+func (eval *SimulationEvaluator) Add(op0, op1 Operand) (Operand, error) {
+	return eval.binary(op0, op1, complexAdd, addMod)
+}
+
+// Sub returns op0 - op1.
+func (eval *SimulationEvaluator) Sub(op0, op1 Operand) (Operand, error) {
+	return eval.binary(op0, op1, complexSub, subMod)
+}
+
+// Mul returns op0 * op1.
+func (eval *SimulationEvaluator) Mul(op0, op1 Operand) (Operand, error) {
+	return eval.binary(op0, op1, complexMul, mulMod)
+}
+
+// AddConst returns op + constant.
+func (eval *SimulationEvaluator) AddConst(op Operand, constant complex128) (Operand, error) {
+	return eval.binary(op, eval.constVector(constant), complexAdd, addMod)
+}
+
+// MulConst returns op * constant.
+func (eval *SimulationEvaluator) MulConst(op Operand, constant complex128) (Operand, error) {
+	return eval.binary(op, eval.constVector(constant), complexMul, mulMod)
+}
+
+// MulVector returns the slot-wise product between op and vector, padded with zeros.
+func (eval *SimulationEvaluator) MulVector(op Operand, vector []complex128) (Operand, error) {
+
+	if len(vector) > eval.slots {
+		return nil, fmt.Errorf("invalid vector length: %d > %d slots", len(vector), eval.slots)
+	}
+
+	padded := make([]complex128, eval.slots)
+	copy(padded, vector)
+
+	return eval.binary(op, padded, complexMul, mulMod)
+}
+
+// Rotate returns op with each row rotated by k positions to the left.
+func (eval *SimulationEvaluator) Rotate(op Operand, k int) (Operand, error) {
+
+	v, err := eval.getVector(op)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]complex128, eval.slots)
+
+	k = ((k % eval.rowSize) + eval.rowSize) % eval.rowSize
+
+	for row := 0; row < eval.slots; row += eval.rowSize {
+		for i := 0; i < eval.rowSize; i++ {
+			res[row+i] = v[row+(i+k)%eval.rowSize]
+		}
+	}
+
+	return res, nil
+}
+
+func (eval *SimulationEvaluator) constVector(constant complex128) []complex128 {
+	v := make([]complex128, eval.slots)
+	for i := range v {
+		v[i] = constant
+	}
+	return v
+}
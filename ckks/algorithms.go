@@ -83,12 +83,14 @@ func (eval *evaluator) Power(op *Ciphertext, degree int, opOut *Ciphertext) {
 // [-1.5 - 1.5i, 1.5 + 1.5i] or the result will be wrong. Each iteration increases the precision.
 func (eval *evaluator) InverseNew(op *Ciphertext, steps int) (opOut *Ciphertext) {
 
-	cbar := eval.NegNew(op)
+	cbar := eval.getCiphertext(op.Level())
+	tmp := eval.getCiphertext(op.Level())
+
+	eval.Neg(op, cbar)
 
 	eval.AddConst(cbar, 1, cbar)
 
-	tmp := eval.AddConstNew(cbar, 1)
-	opOut = tmp.CopyNew()
+	opOut = eval.AddConstNew(cbar, 1)
 
 	for i := 1; i < steps; i++ {
 
@@ -98,16 +100,20 @@ func (eval *evaluator) InverseNew(op *Ciphertext, steps int) (opOut *Ciphertext)
 			panic(err)
 		}
 
-		tmp = eval.AddConstNew(cbar, 1)
+		eval.DropLevel(tmp, tmp.Level()-cbar.Level())
+		tmp.Copy(cbar)
+		eval.AddConst(tmp, 1, tmp)
 
-		eval.MulRelin(tmp, opOut, tmp)
+		eval.DropLevel(opOut, opOut.Level()-tmp.Level())
+		eval.MulRelin(tmp, opOut, opOut)
 
-		if err := eval.Rescale(tmp, op.Scale, tmp); err != nil {
+		if err := eval.Rescale(opOut, op.Scale, opOut); err != nil {
 			panic(err)
 		}
-
-		opOut = tmp.CopyNew()
 	}
 
+	eval.releaseCiphertext(cbar)
+	eval.releaseCiphertext(tmp)
+
 	return opOut
 }
@@ -11,38 +11,75 @@ import (
 // If the input ciphertext level is zero, the input scale must be an exact power of two smaller or equal to round(Q0/2^{10}).
 // If the input ciphertext is at level one or more, the input scale does not need to be an exact power of two as one level
 // can be used to do a scale matching.
+// If Parameters.Iterations is greater than one, the residual error of the bootstrapping is itself bootstrapped
+// Iterations-1 times (meta-bootstrapping) and subtracted from the output, which is then returned with its scale
+// multiplied by 2^{(Iterations-1) * IterationsLogScale}.
 func (btp *Bootstrapper) Bootstrapp(ctIn *ckks.Ciphertext) (ctOut *ckks.Ciphertext) {
 
-	ctOut = ctIn.CopyNew()
+	ctDiff := ctIn.CopyNew()
 
 	// Drops the level to 1
-	for ctOut.Level() > 1 {
-		btp.DropLevel(ctOut, 1)
+	for ctDiff.Level() > 1 {
+		btp.DropLevel(ctDiff, 1)
 	}
 
 	// Brings the ciphertext scale to Q0/MessageRatio
-	if ctOut.Level() == 1 {
+	if ctDiff.Level() == 1 {
 
 		// If one level is available, then uses it to match the scale
-		btp.SetScale(ctOut, btp.q0OverMessageRatio)
+		btp.SetScale(ctDiff, btp.q0OverMessageRatio)
 
 		// Then drops to level 0
-		for ctOut.Level() != 0 {
-			btp.DropLevel(ctOut, 1)
+		for ctDiff.Level() != 0 {
+			btp.DropLevel(ctDiff, 1)
 		}
 
 	} else {
 
 		// Does an integer constant mult by round((Q0/Delta_m)/ctscle)
-		if btp.q0OverMessageRatio < ctOut.Scale {
+		if btp.q0OverMessageRatio < ctDiff.Scale {
 			panic("ciphetext scale > q/||m||)")
 		}
 
-		btp.ScaleUp(ctOut, math.Round(btp.q0OverMessageRatio/ctOut.Scale), ctOut)
+		btp.ScaleUp(ctDiff, math.Round(btp.q0OverMessageRatio/ctDiff.Scale), ctDiff)
 	}
 
+	// M + e
+	ctOut = btp.bootstrap(ctDiff.CopyNew())
+
+	for i := 1; i < btp.Iterations; i++ {
+
+		// Brings M + e back to level 0 and to the scale of M, using the last level to match the scales
+		tmp := btp.DropLevelNew(ctOut, ctOut.Level()-1)
+		btp.SetScale(tmp, ctDiff.Scale)
+		btp.DropLevel(tmp, tmp.Level())
+
+		// e <- (M + e) - M
+		btp.Sub(tmp, ctDiff, tmp)
+
+		// 2^{i*k} * e
+		errScale := math.Exp2(float64(i * btp.IterationsLogScale))
+		btp.ScaleUp(tmp, errScale, tmp)
+		tmp.Scale = ctDiff.Scale
+
+		// 2^{i*k} * e + e'
+		tmp = btp.bootstrap(tmp)
+
+		// e + 2^{-i*k} * e'
+		tmp.Scale *= errScale
+
+		// (M + e) - (e + 2^{-i*k} * e') = M - 2^{-i*k} * e'
+		btp.Sub(ctOut, tmp, ctOut)
+	}
+
+	return
+}
+
+// bootstrap re-encrypts a ciphertext at level 0 and scale Q0/MessageRatio.
+func (btp *Bootstrapper) bootstrap(ctIn *ckks.Ciphertext) (ctOut *ckks.Ciphertext) {
+
 	// Step 1 : Extend the basis from q to Q
-	ctOut = btp.modUpFromQ0(ctOut)
+	ctOut = btp.modUpFromQ0(ctIn)
 
 	// Brings the ciphertext scale to EvalMod-ScalingFactor/(Q0/scale) if Q0 < EvalMod-ScalingFactor.
 	// Does it after modUp to avoid plaintext overflow as the scaling used during EvalMod can be larger than Q0.
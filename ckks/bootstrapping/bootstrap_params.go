@@ -1,6 +1,8 @@
 package bootstrapping
 
 import (
+	"fmt"
+
 	"github.com/tuneinsight/lattigo/v3/ckks"
 	"github.com/tuneinsight/lattigo/v3/ckks/advanced"
 	"github.com/tuneinsight/lattigo/v3/rlwe"
@@ -12,6 +14,8 @@ type Parameters struct {
 	SlotsToCoeffsParameters advanced.EncodingMatrixLiteral
 	EvalModParameters       advanced.EvalModLiteral
 	CoeffsToSlotsParameters advanced.EncodingMatrixLiteral
+	Iterations              int // Number of bootstrapping iterations (meta-bootstrapping), a value of 0 is treated as 1
+	IterationsLogScale      int // Log2 of the factor by which the residual error is scaled before each additional iteration
 }
 
 // MarshalBinary encode the target Parameters on a slice of bytes.
@@ -40,9 +44,24 @@ func (p *Parameters) MarshalBinary() (data []byte, err error) {
 	data = append(data, uint8(len(tmp)))
 	data = append(data, tmp...)
 
+	if err = p.checkIterations(); err != nil {
+		return nil, err
+	}
+
+	data = append(data, uint8(p.Iterations), uint8(p.IterationsLogScale))
+
 	return
 }
 
+// checkIterations returns an error if Iterations or IterationsLogScale is not in [0, 255],
+// the range of values of their binary encoding.
+func (p *Parameters) checkIterations() error {
+	if p.Iterations < 0 || p.Iterations > 0xFF || p.IterationsLogScale < 0 || p.IterationsLogScale > 0xFF {
+		return fmt.Errorf("Iterations=%d and IterationsLogScale=%d must be in [0, 255]", p.Iterations, p.IterationsLogScale)
+	}
+	return nil
+}
+
 // UnmarshalBinary decodes a slice of bytes on the target Parameters.
 func (p *Parameters) UnmarshalBinary(data []byte) (err error) {
 
@@ -69,6 +88,21 @@ func (p *Parameters) UnmarshalBinary(data []byte) (err error) {
 		return err
 	}
 
+	pt += dLen
+	pt++
+
+	// Encodings produced before the iterations parameters were added end here,
+	// in which case they keep their default (a single bootstrapping).
+	switch len(data[pt:]) {
+	case 0:
+		p.Iterations, p.IterationsLogScale = 0, 0
+	case 2:
+		p.Iterations = int(data[pt])
+		p.IterationsLogScale = int(data[pt+1])
+	default:
+		return fmt.Errorf("invalid data length: malformed iterations parameters")
+	}
+
 	return
 }
 
@@ -484,3 +518,87 @@ var DefaultParameters = []Parameters{
 		},
 	},
 }
+
+// HighPrecisionCKKSParameters are parameters for the iterated bootstrapping (meta-bootstrapping).
+// To be used in conjonction with HighPrecisionParameters.
+// The input ciphertext should have a scale close to Q0/MessageRatio (2^52) to benefit from the additional precision.
+var HighPrecisionCKKSParameters = ckks.ParametersLiteral{
+	LogN:         16,
+	LogSlots:     15,
+	DefaultScale: 1 << 30,
+	H:            192,
+	Sigma:        rlwe.DefaultSigma,
+	Q: []uint64{
+		0x10000000006e0001, // 60 Q0
+		0xffffffffffc0001,  // 60
+		0x80000000080001,   // 55
+		0xfffffffff840001,  // 60
+		0x1000000000860001, // 60
+		0xfffffffff6a0001,  // 60
+		0x1000000000980001, // 60
+		0xfffffffff5a0001,  // 60
+		0x1000000000b00001, // 60 StC  (30)
+		0x1000000000ce0001, // 60 StC  (30+30)
+		0x80000000440001,   // 55 Sine (double angle)
+		0x7fffffffba0001,   // 55 Sine (double angle)
+		0x80000000500001,   // 55 Sine
+		0x7fffffffaa0001,   // 55 Sine
+		0x800000005e0001,   // 55 Sine
+		0x7fffffff7e0001,   // 55 Sine
+		0x7fffffff380001,   // 55 Sine
+		0x80000000ca0001,   // 55 Sine
+		0x200000000e0001,   // 53 CtS
+		0x20000000140001,   // 53 CtS
+		0x20000000280001,   // 53 CtS
+		0x1fffffffd80001,   // 53 CtS
+	},
+	P: []uint64{
+		0x1fffffffffe00001, // Pi 61
+		0x1fffffffffc80001, // Pi 61
+		0x1fffffffffb40001, // Pi 61
+		0x1fffffffff500001, // Pi 61
+		0x1fffffffff420001, // Pi 61
+	},
+}
+
+// HighPrecisionParameters are bootstrapping parameters for the iterated bootstrapping (meta-bootstrapping).
+// A single bootstrapping achieves about 20 bits of precision. Each additional iteration bootstraps the residual
+// error scaled by 2^18, for about 40 bits of precision after three iterations, at the cost of three bootstrappings.
+// 1553
+var HighPrecisionParameters = Parameters{
+	SlotsToCoeffsParameters: advanced.EncodingMatrixLiteral{
+		LinearTransformType: advanced.SlotsToCoeffs,
+		LevelStart:          9,
+		BSGSRatio:           2.0,
+		BitReversed:         false,
+		ScalingFactor: [][]float64{
+			{1073741824.0},
+			{1073741824.0062866, 1073741824.0062866},
+		},
+	},
+	EvalModParameters: advanced.EvalModLiteral{
+		Q:             0x10000000006e0001,
+		LevelStart:    17,
+		SineType:      advanced.Cos1,
+		MessageRatio:  256.0,
+		K:             25,
+		SineDeg:       63,
+		DoubleAngle:   2,
+		ArcSineDeg:    0,
+		ScalingFactor: 1 << 55,
+	},
+	CoeffsToSlotsParameters: advanced.EncodingMatrixLiteral{
+		LinearTransformType: advanced.CoeffsToSlots,
+		LevelStart:          21,
+		BSGSRatio:           2.0,
+		BitReversed:         false,
+		ScalingFactor: [][]float64{
+			{0x200000000e0001},
+			{0x20000000140001},
+			{0x20000000280001},
+			{0x1fffffffd80001},
+		},
+	},
+	Iterations:         3,
+	IterationsLogScale: 18,
+}
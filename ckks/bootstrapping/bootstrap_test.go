@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/tuneinsight/lattigo/v3/ckks"
 	"github.com/tuneinsight/lattigo/v3/rlwe"
 	"github.com/tuneinsight/lattigo/v3/utils"
@@ -38,6 +39,39 @@ func TestBootstrapParametersMarshalling(t *testing.T) {
 		assert.Nil(t, err)
 	}
 	assert.Equal(t, bootstrapParams, *bootstrapParamsNew)
+
+	bootstrapParams = HighPrecisionParameters
+	data, err = bootstrapParams.MarshalBinary()
+	assert.Nil(t, err)
+
+	bootstrapParamsNew = new(Parameters)
+	if err := bootstrapParamsNew.UnmarshalBinary(data); err != nil {
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, bootstrapParams, *bootstrapParamsNew)
+
+	// Encodings without the trailing iterations parameters decode with their default values
+	bootstrapParamsNew = new(Parameters)
+	assert.Nil(t, bootstrapParamsNew.UnmarshalBinary(data[:len(data)-2]))
+	assert.Equal(t, 0, bootstrapParamsNew.Iterations)
+	assert.Equal(t, 0, bootstrapParamsNew.IterationsLogScale)
+	assert.Equal(t, bootstrapParams.EvalModParameters, bootstrapParamsNew.EvalModParameters)
+
+	// Iterations parameters that do not fit on a byte are rejected instead of being truncated
+	ckksParams := DefaultCKKSParameters[0]
+	ckksParams.LogN, ckksParams.LogSlots = 13, 12
+	params, err := ckks.NewParametersFromLiteral(ckksParams)
+	assert.Nil(t, err)
+
+	for _, iterations := range [][2]int{{256, 20}, {2, 256}, {-1, 20}, {2, -1}} {
+		bootstrapParams = DefaultParameters[0]
+		bootstrapParams.Iterations, bootstrapParams.IterationsLogScale = iterations[0], iterations[1]
+		_, err = bootstrapParams.MarshalBinary()
+		assert.NotNil(t, err)
+		_, err = NewBootstrapper(params, bootstrapParams, rlwe.EvaluationKey{})
+		assert.NotNil(t, err)
+		assert.Contains(t, fmt.Sprint(err), "must be in [0, 255]")
+	}
 }
 
 func TestBootstrap(t *testing.T) {
@@ -88,6 +122,67 @@ func TestBootstrap(t *testing.T) {
 	}
 }
 
+func TestBootstrapIterations(t *testing.T) {
+
+	if runtime.GOARCH == "wasm" {
+		t.Skip("skipping bootstrapping tests for GOARCH=wasm")
+	}
+
+	if !*testBootstrapping {
+		t.Skip("skipping bootstrapping tests (add -test-bootstrapping to run the bootstrapping tests)")
+	}
+
+	ckksParams := HighPrecisionCKKSParameters
+	btpParams := HighPrecisionParameters
+
+	// Insecure params for fast testing only
+	if !*flagLongTest {
+		ckksParams.LogN = 13
+		ckksParams.LogSlots = 12
+	}
+
+	params, err := ckks.NewParametersFromLiteral(ckksParams)
+	if err != nil {
+		panic(err)
+	}
+
+	t.Run(ParamsToString(params, "Bootstrapping/Iterations/"), func(t *testing.T) {
+
+		kgen := ckks.NewKeyGenerator(params)
+		sk := kgen.GenSecretKey()
+		rlk := kgen.GenRelinearizationKey(sk, 2)
+		encoder := ckks.NewEncoder(params)
+		encryptor := ckks.NewEncryptor(params, sk)
+		decryptor := ckks.NewDecryptor(params, sk)
+
+		rotations := btpParams.RotationsForBootstrapping(params.LogN(), params.LogSlots())
+		rotkeys := kgen.GenRotationKeysForRotations(rotations, true, sk)
+
+		btp, err := NewBootstrapper(params, btpParams, rlwe.EvaluationKey{Rlk: rlk, Rtks: rotkeys})
+		if err != nil {
+			panic(err)
+		}
+
+		values := make([]complex128, params.Slots())
+		for i := range values {
+			values[i] = utils.RandComplex128(-1, 1)
+		}
+
+		plaintext := ckks.NewPlaintext(params, 0, btp.q0OverMessageRatio)
+		encoder.Encode(values, plaintext, params.LogSlots())
+
+		ciphertext := btp.Bootstrapp(encryptor.EncryptNew(plaintext))
+
+		precStats := ckks.GetPrecisionStats(params, encoder, decryptor, values, ciphertext, params.LogSlots(), 0)
+
+		if *printPrecisionStats {
+			t.Log(precStats.String())
+		}
+
+		require.GreaterOrEqual(t, precStats.MeanPrecision.L2, 35.0)
+	})
+}
+
 func testbootstrap(params ckks.Parameters, btpParams Parameters, t *testing.T) {
 
 	t.Run(ParamsToString(params, "Bootstrapping/FullCircuit/"), func(t *testing.T) {
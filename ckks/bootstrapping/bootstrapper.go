@@ -49,9 +49,17 @@ func NewBootstrapper(params ckks.Parameters, btpParams Parameters, btpKey rlwe.E
 		return nil, fmt.Errorf("starting level and depth of SineEvalParameters inconsistent starting level of CoeffsToSlotsParameters")
 	}
 
+	if err = btpParams.checkIterations(); err != nil {
+		return nil, err
+	}
+
 	btp = new(Bootstrapper)
 	btp.bootstrapperBase = newBootstrapperBase(params, btpParams, btpKey)
 
+	if btp.Iterations > 1 && btp.IterationsLogScale == 0 {
+		return nil, fmt.Errorf("IterationsLogScale must be greater than zero if Iterations > 1")
+	}
+
 	if err = btp.bootstrapperBase.CheckKeys(btpKey); err != nil {
 		return nil, fmt.Errorf("invalid bootstrapping key: %w", err)
 	}
@@ -107,6 +115,10 @@ func newBootstrapperBase(params ckks.Parameters, btpParams Parameters, btpKey rl
 	bb.params = params
 	bb.Parameters = btpParams
 
+	if bb.Iterations == 0 {
+		bb.Iterations = 1
+	}
+
 	bb.dslots = params.Slots()
 	bb.logdslots = params.LogSlots()
 	if params.LogSlots() < params.MaxLogSlots() {
@@ -73,6 +73,37 @@ func benchEncoder(tc *testContext, b *testing.B) {
 			encoder.Decode(plaintext, logSlots)
 		}
 	})
+
+	encoderDD := NewEncoderDoubleDouble(tc.params)
+
+	b.Run(GetTestName(tc.params, "EncoderDoubleDouble/Encode"), func(b *testing.B) {
+
+		values := make([]complex128, 1<<logSlots)
+		for i := 0; i < 1<<logSlots; i++ {
+			values[i] = utils.RandComplex128(-1, 1)
+		}
+
+		plaintext := NewPlaintext(tc.params, tc.params.MaxLevel(), tc.params.DefaultScale())
+
+		for i := 0; i < b.N; i++ {
+			encoderDD.Encode(values, plaintext, logSlots)
+		}
+	})
+
+	b.Run(GetTestName(tc.params, "EncoderDoubleDouble/Decode"), func(b *testing.B) {
+
+		values := make([]complex128, 1<<logSlots)
+		for i := 0; i < 1<<logSlots; i++ {
+			values[i] = utils.RandComplex128(-1, 1)
+		}
+
+		plaintext := NewPlaintext(tc.params, tc.params.MaxLevel(), tc.params.DefaultScale())
+		encoderDD.Encode(values, plaintext, logSlots)
+
+		for i := 0; i < b.N; i++ {
+			encoderDD.Decode(plaintext, logSlots)
+		}
+	})
 }
 
 func benchKeyGen(tc *testContext, b *testing.B) {
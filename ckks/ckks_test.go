@@ -5,7 +5,9 @@ import (
 	"flag"
 	"fmt"
 	"math"
+	"math/big"
 	"math/cmplx"
+	"math/rand"
 	"runtime"
 	"testing"
 
@@ -90,6 +92,7 @@ func TestCKKS(t *testing.T) {
 		for _, testSet := range []func(tc *testContext, t *testing.T){
 			testParameters,
 			testEncoder,
+			testDoubleDouble,
 			testEvaluatorAdd,
 			testEvaluatorSub,
 			testEvaluatorRescale,
@@ -98,6 +101,7 @@ func TestCKKS(t *testing.T) {
 			testEvaluatorMultByConstAndAdd,
 			testEvaluatorMul,
 			testEvaluatorMulAndAdd,
+			testManagedEvaluator,
 			testFunctions,
 			testDecryptPublic,
 			testEvaluatePoly,
@@ -108,6 +112,8 @@ func TestCKKS(t *testing.T) {
 			testInnerSum,
 			testReplicate,
 			testLinearTransform,
+			testPermutation,
+			testPool,
 			testMarshaller,
 		} {
 			testSet(tc, t)
@@ -277,6 +283,148 @@ func testEncoder(tc *testContext, t *testing.T) {
 		require.GreaterOrEqual(t, math.Log2(1/meanprec), minPrec)
 	})
 
+	t.Run(GetTestName(tc.params, "Encoder/EncoderBigComplex"), func(t *testing.T) {
+
+		if tc.params.RingType() != ring.Standard {
+			t.Skip("EncoderBigComplex only supports the standard ring")
+		}
+
+		logSlots := utils.MinInt(4, tc.params.LogSlots())
+		slots := 1 << logSlots
+
+		encoderBig := NewEncoderBigComplex(tc.params, 128)
+
+		values := make([]complex128, slots)
+		valuesBig := make([]*ring.Complex, slots)
+		for i := range values {
+			values[i] = complex(utils.RandFloat64(-1, 1), utils.RandFloat64(-1, 1))
+			valuesBig[i] = ring.NewComplex(ring.NewFloat(real(values[i]), 128), ring.NewFloat(imag(values[i]), 128))
+		}
+
+		for _, level := range []int{0, tc.params.MaxLevel()} {
+
+			plaintext := encoderBig.EncodeNew(valuesBig, level, tc.params.DefaultScale(), logSlots)
+
+			// Cross-checks with the float64 encoder
+			verifyTestVectors(tc.params, tc.encoder, nil, values, plaintext, logSlots, 0, t)
+
+			valuesHave := encoderBig.Decode(plaintext, logSlots)
+
+			for i := range values {
+				re, _ := valuesHave[i].Real().Float64()
+				im, _ := valuesHave[i].Imag().Float64()
+				require.InDelta(t, real(values[i]), re, 1e-6)
+				require.InDelta(t, imag(values[i]), im, 1e-6)
+			}
+		}
+	})
+
+	t.Run(GetTestName(tc.params, "Encoder/EncoderDoubleDouble"), func(t *testing.T) {
+
+		encoderDD := NewEncoderDoubleDouble(tc.params)
+
+		logSlots := tc.params.LogSlots()
+
+		values, plaintext, _ := newTestVectors(tc, nil, complex(-1, -1), complex(1, 1), t)
+
+		// Decodes a plaintext encoded with the complex128 encoder
+		valuesHave := make([]complex128, len(values))
+		for i, v := range encoderDD.Decode(plaintext, logSlots) {
+			valuesHave[i] = v.Complex128()
+		}
+
+		verifyTestVectors(tc.params, tc.encoder, nil, values, valuesHave, logSlots, 0, t)
+
+		// Encodes a plaintext decoded with the complex128 encoder
+		verifyTestVectors(tc.params, tc.encoder, nil, values, encoderDD.EncodeNew(values, tc.params.MaxLevel(), tc.params.DefaultScale(), logSlots), logSlots, 0, t)
+
+		if tc.params.RingType() == ring.Standard {
+
+			// Same plaintext as the arbitrary precision encoder
+			logSlots := utils.MinInt(4, logSlots)
+
+			valuesBig := make([]*ring.Complex, 1<<logSlots)
+			for i := range valuesBig {
+				valuesBig[i] = ring.NewComplex(ring.NewFloat(real(values[i]), 128), ring.NewFloat(imag(values[i]), 128))
+			}
+
+			ptBig := NewEncoderBigComplex(tc.params, 128).EncodeNew(valuesBig, tc.params.MaxLevel(), tc.params.DefaultScale(), logSlots)
+			ptDD := encoderDD.EncodeNew(valuesBig, tc.params.MaxLevel(), tc.params.DefaultScale(), logSlots)
+
+			require.True(t, tc.ringQ.EqualLvl(tc.params.MaxLevel(), ptBig.Value, ptDD.Value))
+		}
+
+		// Precision beyond 53 bits with a scale close to the modulus
+		logQ := tc.ringQ.ModulusBigint.BitLen()
+		scale := math.Exp2(float64(logQ - 4))
+
+		valuesDD := make([]ComplexDoubleDouble, 1<<logSlots)
+		for i := range valuesDD {
+			valuesDD[i].Real = DoubleDouble{utils.RandFloat64(-1, 1), utils.RandFloat64(-1, 1) * 0x1p-54}.Add(DoubleDouble{})
+			if tc.params.RingType() == ring.Standard {
+				valuesDD[i].Imag = DoubleDouble{utils.RandFloat64(-1, 1), utils.RandFloat64(-1, 1) * 0x1p-54}.Add(DoubleDouble{})
+			}
+		}
+
+		valuesHaveDD := encoderDD.Decode(encoderDD.EncodeNew(valuesDD, tc.params.MaxLevel(), scale, logSlots), logSlots)
+
+		var maxErr float64
+		for i := range valuesDD {
+			maxErr = math.Max(maxErr, math.Abs(valuesHaveDD[i].Real.Sub(valuesDD[i].Real).Float64()))
+			maxErr = math.Max(maxErr, math.Abs(valuesHaveDD[i].Imag.Sub(valuesDD[i].Imag).Float64()))
+		}
+
+		if *printPrecisionStats {
+			t.Logf("log2(scale) = %d, precision = %.2f bits", logQ-4, math.Log2(1/maxErr))
+		}
+
+		require.GreaterOrEqual(t, math.Log2(1/maxErr), math.Min(float64(logQ-4-tc.params.LogN()), 95))
+	})
+}
+
+func testDoubleDouble(tc *testContext, t *testing.T) {
+
+	t.Run(GetTestName(tc.params, "DoubleDouble"), func(t *testing.T) {
+
+		prec := uint(256)
+
+		// |x - want| <= 2^-100 * |want|
+		requireClose := func(want *big.Float, x DoubleDouble) {
+			diff := new(big.Float).SetPrec(prec).Sub(want, x.BigFloat())
+			diff.Abs(diff)
+			bound := new(big.Float).SetPrec(prec).Abs(want)
+			bound.SetMantExp(bound, -100)
+			require.True(t, diff.Cmp(bound) <= 0, "%s != %s", want.Text('g', 40), x.BigFloat().Text('g', 40))
+		}
+
+		for i := 0; i < 1024; i++ {
+
+			af := new(big.Float).SetPrec(prec).SetFloat64(utils.RandFloat64(-1, 1))
+			af.Add(af, new(big.Float).SetFloat64(utils.RandFloat64(-1, 1)*0x1p-60))
+			bf := new(big.Float).SetPrec(prec).SetFloat64(utils.RandFloat64(-1, 1) * math.Exp2(float64(i%64)))
+			bf.Add(bf, new(big.Float).SetFloat64(utils.RandFloat64(-1, 1)*math.Exp2(float64(i%64-60))))
+			c := utils.RandFloat64(1, 2) * math.Exp2(float64(i%32))
+
+			a := NewDoubleDouble(af)
+			b := NewDoubleDouble(bf)
+
+			requireClose(af, a)
+			requireClose(new(big.Float).SetPrec(prec).Mul(af, bf), a.Mul(b))
+			requireClose(new(big.Float).SetPrec(prec).Mul(af, big.NewFloat(c)), a.MulFloat64(c))
+			requireClose(new(big.Float).SetPrec(prec).Quo(af, big.NewFloat(c)), a.QuoFloat64(c))
+
+			// The sum of operands of the same sign is well conditioned
+			bf.Abs(bf)
+			af.Abs(af)
+			requireClose(new(big.Float).SetPrec(prec).Add(af, bf), NewDoubleDouble(af).Add(NewDoubleDouble(bf)))
+
+			// round(x) = floor(x + 1/2) for x > 0
+			xf := NewDoubleDouble(bf.Mul(bf, big.NewFloat(0x1p60))).BigFloat()
+			want, _ := new(big.Float).SetPrec(prec).Add(xf, big.NewFloat(0.5)).Int(nil)
+			have, _ := NewDoubleDouble(xf).Round().BigFloat().Int(nil)
+			require.Zero(t, want.Cmp(have))
+		}
+	})
 }
 
 func testEvaluatorAdd(tc *testContext, t *testing.T) {
@@ -760,6 +908,46 @@ func testEvaluatorMulAndAdd(tc *testContext, t *testing.T) {
 	})
 }
 
+// testBootstrapper is a Bootstrapper that decrypts and re-encrypts at the maximum level.
+type testBootstrapper struct {
+	*testContext
+}
+
+func (btp *testBootstrapper) Bootstrapp(ctIn *Ciphertext) (ctOut *Ciphertext) {
+	values := btp.encoder.Decode(btp.decryptor.DecryptNew(ctIn), btp.params.LogSlots())
+	return btp.encryptorSk.EncryptNew(btp.encoder.EncodeNew(values, btp.params.MaxLevel(), btp.params.DefaultScale(), btp.params.LogSlots()))
+}
+
+func testManagedEvaluator(tc *testContext, t *testing.T) {
+
+	t.Run(GetTestName(tc.params, "ManagedEvaluator/MulRelin/Add"), func(t *testing.T) {
+
+		if tc.params.PCount() == 0 {
+			t.Skip("method is unsuported when params.PCount() == 0")
+		}
+
+		eval := NewManagedEvaluator(tc.params, tc.evaluator.ShallowCopy(), &testBootstrapper{tc})
+
+		valuesWant, _, ciphertext0 := newTestVectors(tc, tc.encryptorSk, complex(0.9, 0), complex(1.1, 0), t)
+
+		// Evaluates more multiplications than there are levels, adding a fresh ciphertext after each of them
+		for i := 0; i < tc.params.MaxLevel()+2; i++ {
+
+			values1, _, ciphertext1 := newTestVectors(tc, tc.encryptorSk, complex(0.9, 0), complex(1.1, 0), t)
+			values2, _, ciphertext2 := newTestVectors(tc, tc.encryptorSk, complex(-0.1, 0), complex(0.1, 0), t)
+
+			for j := range valuesWant {
+				valuesWant[j] = valuesWant[j]*values1[j] + values2[j]
+			}
+
+			eval.MulRelin(ciphertext0, ciphertext1, ciphertext0)
+			eval.Add(ciphertext0, ciphertext2, ciphertext0)
+		}
+
+		verifyTestVectors(tc.params, tc.encoder, tc.decryptor, valuesWant, ciphertext0, tc.params.LogSlots(), 0, t)
+	})
+}
+
 func testFunctions(tc *testContext, t *testing.T) {
 
 	t.Run(GetTestName(tc.params, "Evaluator/PowerOf2"), func(t *testing.T) {
@@ -923,6 +1111,64 @@ func testEvaluatePoly(tc *testContext, t *testing.T) {
 
 		verifyTestVectors(tc.params, tc.encoder, tc.decryptor, valuesWant, ciphertext, tc.params.LogSlots(), 0, t)
 	})
+
+	for _, basis := range []PolynomialBasis{StandardBasis, ChebyshevBasis} {
+
+		name := map[PolynomialBasis]string{StandardBasis: "Standard", ChebyshevBasis: "Chebyshev"}[basis]
+
+		t.Run(GetTestName(tc.params, "EvaluatePoly/PolyEncrypted/"+name), func(t *testing.T) {
+
+			if tc.params.PCount() == 0 {
+				t.Skip("method is unsuported when params.PCount() == 0")
+			}
+
+			if tc.params.MaxLevel() < 4 {
+				t.Skip("skipping test for params max level < 4")
+			}
+
+			values, _, ciphertext := newTestVectors(tc, tc.encryptorSk, complex(-1, 0), complex(1, 0), t)
+
+			// The coefficients need one more level than the input ciphertext
+			tc.evaluator.DropLevel(ciphertext, 1)
+
+			// Each slot is evaluated on its own polynomial, and the coefficient of degree 5 is zero
+			coeffs := make([][]complex128, 8)
+			poly := NewEncryptedPoly(make([]*Ciphertext, 8))
+			poly.Basis = basis
+			for i := range coeffs {
+				if i != 5 {
+					coeffs[i], _, poly.Coeffs[i] = newTestVectors(tc, tc.encryptorSk, complex(-1, -1), complex(1, 1), t)
+				}
+			}
+
+			for j := range values {
+				T0, T1 := complex(1, 0), values[j]
+				x, y := values[j], complex(0, 0)
+				for i := range coeffs {
+					var xi complex128
+					switch {
+					case i == 0:
+						xi = 1
+					case i == 1 || basis == StandardBasis:
+						xi = cmplx.Pow(x, complex(float64(i), 0))
+					default:
+						T0, T1 = T1, 2*x*T1-T0
+						xi = T1
+					}
+					if coeffs[i] != nil {
+						y += coeffs[i][j] * xi
+					}
+				}
+				values[j] = y
+			}
+
+			if ciphertext, err = tc.evaluator.EvaluateEncryptedPoly(ciphertext, poly, ciphertext.Scale); err != nil {
+				t.Fatal(err)
+			}
+
+			verifyTestVectors(tc.params, tc.encoder, tc.decryptor, values, ciphertext, tc.params.LogSlots(), 0, t)
+		})
+	}
 }
 
 func testChebyshevInterpolator(tc *testContext, t *testing.T) {
@@ -1345,6 +1591,70 @@ func testReplicate(tc *testContext, t *testing.T) {
 	})
 }
 
+func testPermutation(tc *testContext, t *testing.T) {
+
+	if tc.params.PCount() == 0 {
+		t.Skip("method is unsuported when params.PCount() == 0")
+	}
+
+	t.Run(GetTestName(tc.params, "Automorphism"), func(t *testing.T) {
+
+		params := tc.params
+
+		values, _, ciphertext := newTestVectors(tc, tc.encryptorSk, complex(-1, -1), complex(1, 1), t)
+
+		galEl := params.GaloisElementForColumnRotationBy(5)
+
+		rotKey := tc.kgen.GenRotationKeys([]uint64{galEl}, tc.sk)
+
+		eval := tc.evaluator.WithKey(rlwe.EvaluationKey{Rlk: tc.rlk, Rtks: rotKey})
+
+		eval.Automorphism(ciphertext, galEl, ciphertext)
+
+		verifyTestVectors(params, tc.encoder, tc.decryptor, utils.RotateComplex128Slice(values, 5), ciphertext, params.LogSlots(), 0, t)
+	})
+
+	t.Run(GetTestName(tc.params, "Permute"), func(t *testing.T) {
+
+		params := tc.params
+
+		values, _, ciphertext := newTestVectors(tc, tc.encryptorSk, complex(-1, -1), complex(1, 1), t)
+
+		// Permutes the slots inside blocks of 2^logBlock slots, so that the depth of the network fits in the test parameters
+		logBlock := utils.MinInt(params.LogSlots(), (params.MaxLevel()+1)/2)
+		block := 1 << logBlock
+
+		permutation := make([]int, params.Slots())
+		for i := 0; i < params.Slots(); i += block {
+			for j, k := range rand.Perm(block) {
+				permutation[i+j] = i + k
+			}
+		}
+
+		network := params.NewPermutationNetwork(permutation)
+
+		require.LessOrEqual(t, network.Depth(), params.MaxLevel())
+
+		perm := GenPermutation(tc.encoder, network, params.MaxLevel())
+
+		rotKey := tc.kgen.GenRotationKeys(network.GaloisElements(), tc.sk)
+
+		eval := tc.evaluator.WithKey(rlwe.EvaluationKey{Rlk: tc.rlk, Rtks: rotKey})
+
+		ctOut := eval.PermuteNew(ciphertext, perm)
+
+		require.Equal(t, params.MaxLevel()-network.Depth(), ctOut.Level())
+		require.Equal(t, ciphertext.Scale, ctOut.Scale)
+
+		want := make([]complex128, params.Slots())
+		for i := range want {
+			want[i] = values[permutation[i]]
+		}
+
+		verifyTestVectors(params, tc.encoder, tc.decryptor, want, ctOut, params.LogSlots(), 0, t)
+	})
+}
+
 func testLinearTransform(tc *testContext, t *testing.T) {
 
 	if tc.params.PCount() == 0 {
@@ -1445,6 +1755,76 @@ func testLinearTransform(tc *testContext, t *testing.T) {
 	})
 }
 
+func testPool(tc *testContext, t *testing.T) {
+
+	t.Run(GetTestName(tc.params, "Pool/Arena"), func(t *testing.T) {
+
+		if tc.params.PCount() == 0 {
+			t.Skip("method is unsuported when params.PCount() == 0")
+		}
+
+		if tc.params.MaxLevel() < 1 {
+			t.Skip("skipping test for params max level < 1")
+		}
+
+		params := tc.params
+
+		diagMatrix := make(map[int][]complex128)
+		for _, k := range []int{-4, -1, 0, 1, 2, 15} {
+			diagMatrix[k] = make([]complex128, params.Slots())
+			for i := range diagMatrix[k] {
+				diagMatrix[k][i] = complex(0.5, 0)
+			}
+		}
+
+		var linTransf interface{} = GenLinearTransformBSGS(tc.encoder, diagMatrix, params.MaxLevel(), params.DefaultScale(), 1.0, params.LogSlots())
+
+		batch, n := 3, 5
+
+		rots := append(params.RotationsForLinearTransform(diagMatrix, params.LogSlots(), 1.0), params.RotationsForInnerSum(batch, n)...)
+		rotKey := tc.kgen.GenRotationKeysForRotations(append(rots, 7), false, tc.sk)
+		eval := tc.evaluator.WithKey(rlwe.EvaluationKey{Rlk: tc.rlk, Rtks: rotKey})
+
+		_, _, ciphertext := newTestVectors(tc, tc.encryptorSk, complex(-1, -1), complex(1, 1), t)
+
+		ctOut := []*Ciphertext{NewCiphertext(params, 1, params.MaxLevel(), params.DefaultScale())}
+		ctTmp := NewCiphertext(params, 1, params.MaxLevel(), params.DefaultScale())
+
+		circuit := func(eval Evaluator) {
+			ctOut[0].Value[0].Coeffs = ctOut[0].Value[0].Coeffs[:params.MaxLevel()+1]
+			ctOut[0].Value[1].Coeffs = ctOut[0].Value[1].Coeffs[:params.MaxLevel()+1]
+			eval.LinearTransform(ciphertext, linTransf, ctOut)
+			eval.InnerSum(ctOut[0], batch, n, ctTmp)
+			eval.MulRelin(ctTmp, ciphertext, ctOut[0])
+			if err := eval.Rescale(ctOut[0], params.DefaultScale(), ctOut[0]); err != nil {
+				t.Fatal(err)
+			}
+			eval.Rotate(ctOut[0], 7, ctOut[0])
+		}
+
+		circuit(eval)
+		want := ctOut[0].CopyNew()
+
+		equal := func() bool {
+			return want.Value[0].Equals(ctOut[0].Value[0]) && want.Value[1].Equals(ctOut[0].Value[1])
+		}
+
+		// Sizes the arena with a first evaluation of the circuit
+		pool := rlwe.NewPool(params.Parameters)
+		circuit(eval.WithPool(pool))
+		require.True(t, equal())
+
+		sizeQ, sizeP := pool.MaxInUse()
+		require.Equal(t, 0, pool.Q.InUse())
+
+		evalArena := eval.WithPool(rlwe.NewArena(params.Parameters, sizeQ, sizeP))
+		circuit(evalArena)
+		require.True(t, equal())
+
+		require.Equal(t, 0.0, testing.AllocsPerRun(2, func() { circuit(evalArena) }))
+	})
+}
+
 func testMarshaller(testctx *testContext, t *testing.T) {
 
 	t.Run(GetTestName(testctx.params, "Marshaller/Parameters/Binary"), func(t *testing.T) {
@@ -1496,6 +1876,29 @@ func testMarshaller(testctx *testContext, t *testing.T) {
 		assert.Nil(t, err)
 		assert.Equal(t, 6.6, paramsWithCustomSecrets.Sigma())
 		assert.Equal(t, 192, paramsWithCustomSecrets.HammingWeight())
+
+		// checks that the limb size, the sampler type and the distributions are preserved
+		paramsWithDistributions, err := NewParametersFromLiteral(ParametersLiteral{
+			LogN:                testctx.params.LogN(),
+			LogQ:                []int{30, 30},
+			LogP:                []int{31},
+			DefaultScale:        1 << 20,
+			RingType:            testctx.params.RingType(),
+			LimbSize:            32,
+			GaussianSamplerType: ring.GaussianCDT,
+			Xs:                  &ring.Distribution{Type: ring.TernaryProbability, P: 0.5},
+			Xe:                  &ring.Distribution{Type: ring.DiscreteGaussian, Sigma: 6.4, Bound: 38.4},
+		})
+		require.NoError(t, err)
+		data, err = json.Marshal(paramsWithDistributions)
+		require.NoError(t, err)
+		var paramsWithDistributionsRec Parameters
+		require.NoError(t, json.Unmarshal(data, &paramsWithDistributionsRec))
+		assert.True(t, paramsWithDistributions.Equals(paramsWithDistributionsRec))
+		assert.Equal(t, 32, paramsWithDistributionsRec.LimbSize())
+		assert.Equal(t, ring.GaussianCDT, paramsWithDistributionsRec.GaussianSamplerType())
+		assert.Equal(t, ring.Distribution{Type: ring.TernaryProbability, P: 0.5}, paramsWithDistributionsRec.Xs())
+		assert.Equal(t, ring.Distribution{Type: ring.DiscreteGaussian, Sigma: 6.4, Bound: 38.4}, paramsWithDistributionsRec.Xe())
 	})
 
 	t.Run("Marshaller/Ciphertext/", func(t *testing.T) {
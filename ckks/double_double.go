@@ -0,0 +1,178 @@
+package ckks
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/tuneinsight/lattigo/v3/ring"
+)
+
+// DoubleDouble is a floating point number with about 106 bits of precision, represented
+// as the unevaluated sum of two float64 Hi + Lo with |Lo| <= ulp(Hi)/2.
+// The arithmetic is the one of Dekker and Knuth, and does not rely on fused multiply-add.
+type DoubleDouble struct {
+	Hi, Lo float64
+}
+
+// ComplexDoubleDouble is a complex number whose real and imaginary parts are DoubleDouble.
+type ComplexDoubleDouble struct {
+	Real, Imag DoubleDouble
+}
+
+// NewDoubleDouble returns the DoubleDouble closest to x.
+func NewDoubleDouble(x *big.Float) (a DoubleDouble) {
+	a.Hi, _ = x.Float64()
+	a.Lo, _ = new(big.Float).SetPrec(x.Prec()).Sub(x, big.NewFloat(a.Hi)).Float64()
+	return
+}
+
+// BigFloat returns the value of a as a new big.Float of 128 bits of precision.
+func (a DoubleDouble) BigFloat() *big.Float {
+	x := new(big.Float).SetPrec(128).SetFloat64(a.Hi)
+	return x.Add(x, big.NewFloat(a.Lo))
+}
+
+// Float64 returns the float64 closest to a.
+func (a DoubleDouble) Float64() float64 {
+	return a.Hi + a.Lo
+}
+
+// Neg returns -a.
+func (a DoubleDouble) Neg() DoubleDouble {
+	return DoubleDouble{-a.Hi, -a.Lo}
+}
+
+// Add returns a + b.
+func (a DoubleDouble) Add(b DoubleDouble) DoubleDouble {
+	s, e := twoSum(a.Hi, b.Hi)
+	t, f := twoSum(a.Lo, b.Lo)
+	e += t
+	s, e = quickTwoSum(s, e)
+	e += f
+	s, e = quickTwoSum(s, e)
+	return DoubleDouble{s, e}
+}
+
+// Sub returns a - b.
+func (a DoubleDouble) Sub(b DoubleDouble) DoubleDouble {
+	return a.Add(b.Neg())
+}
+
+// Mul returns a * b.
+func (a DoubleDouble) Mul(b DoubleDouble) DoubleDouble {
+	p, e := twoProd(a.Hi, b.Hi)
+	e += a.Hi*b.Lo + a.Lo*b.Hi
+	p, e = quickTwoSum(p, e)
+	return DoubleDouble{p, e}
+}
+
+// MulFloat64 returns a * b.
+func (a DoubleDouble) MulFloat64(b float64) DoubleDouble {
+	p, e := twoProd(a.Hi, b)
+	e += a.Lo * b
+	p, e = quickTwoSum(p, e)
+	return DoubleDouble{p, e}
+}
+
+// QuoFloat64 returns a / b.
+func (a DoubleDouble) QuoFloat64(b float64) DoubleDouble {
+	q1 := a.Hi / b
+	p, e := twoProd(q1, b)
+	s, f := twoSum(a.Hi, -p)
+	f -= e
+	f += a.Lo
+	q2 := (s + f) / b
+	q1, q2 = quickTwoSum(q1, q2)
+	return DoubleDouble{q1, q2}
+}
+
+// Round returns the nearest integer to a, computed as floor(a + 1/2).
+// The returned DoubleDouble has both its parts integer-valued.
+func (a DoubleDouble) Round() DoubleDouble {
+
+	hi := math.Floor(a.Hi)
+
+	if hi == a.Hi {
+		// If a.Lo is not an integer then |a.Lo| < 2^52 and a.Lo + 1/2 does not cross an integer when rounded
+		lo := math.Floor(a.Lo + 0.5)
+		if a.Lo == math.Floor(a.Lo) {
+			lo = a.Lo
+		}
+		hi, lo = quickTwoSum(hi, lo)
+		return DoubleDouble{hi, lo}
+	}
+
+	// a.Hi is not an integer, hence |a.Hi| < 2^52, 0 < a.Hi - hi < 1 and |a.Lo| < a.Hi - hi,
+	// and a.Hi - hi - 1/2 is computed exactly
+	if a.Hi-hi-0.5 >= -a.Lo {
+		hi++
+	}
+
+	return DoubleDouble{hi, 0}
+}
+
+// NewComplexDoubleDouble returns the ComplexDoubleDouble closest to x.
+func NewComplexDoubleDouble(x *ring.Complex) ComplexDoubleDouble {
+	return ComplexDoubleDouble{NewDoubleDouble(x.Real()), NewDoubleDouble(x.Imag())}
+}
+
+// BigComplex returns the value of a as a new ring.Complex of 128 bits of precision.
+func (a ComplexDoubleDouble) BigComplex() *ring.Complex {
+	return &ring.Complex{a.Real.BigFloat(), a.Imag.BigFloat()}
+}
+
+// Complex128 returns the complex128 closest to a.
+func (a ComplexDoubleDouble) Complex128() complex128 {
+	return complex(a.Real.Float64(), a.Imag.Float64())
+}
+
+// Add returns a + b.
+func (a ComplexDoubleDouble) Add(b ComplexDoubleDouble) ComplexDoubleDouble {
+	return ComplexDoubleDouble{a.Real.Add(b.Real), a.Imag.Add(b.Imag)}
+}
+
+// Sub returns a - b.
+func (a ComplexDoubleDouble) Sub(b ComplexDoubleDouble) ComplexDoubleDouble {
+	return ComplexDoubleDouble{a.Real.Sub(b.Real), a.Imag.Sub(b.Imag)}
+}
+
+// Mul returns a * b.
+func (a ComplexDoubleDouble) Mul(b ComplexDoubleDouble) ComplexDoubleDouble {
+	return ComplexDoubleDouble{
+		a.Real.Mul(b.Real).Sub(a.Imag.Mul(b.Imag)),
+		a.Real.Mul(b.Imag).Add(a.Imag.Mul(b.Real)),
+	}
+}
+
+// twoSum returns s = fl(a+b) and e such that s + e = a + b exactly.
+func twoSum(a, b float64) (s, e float64) {
+	s = a + b
+	bb := s - a
+	e = (a - (s - bb)) + (b - bb)
+	return
+}
+
+// quickTwoSum returns s = fl(a+b) and e such that s + e = a + b exactly, assuming |a| >= |b|.
+func quickTwoSum(a, b float64) (s, e float64) {
+	s = a + b
+	e = b - (s - a)
+	return
+}
+
+// split splits a into two non-overlapping 26-bit halves.
+func split(a float64) (hi, lo float64) {
+	// The explicit conversion prevents the fusion of the operations
+	t := float64(134217729 * a) // 2^27 + 1
+	hi = t - (t - a)
+	lo = a - hi
+	return
+}
+
+// twoProd returns p = fl(a*b) and e such that p + e = a * b exactly.
+func twoProd(a, b float64) (p, e float64) {
+	p = float64(a * b)
+	ah, al := split(a)
+	bh, bl := split(b)
+	e = ((ah*bh - p) + ah*bl + al*bh) + al*bl
+	return
+}
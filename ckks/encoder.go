@@ -604,6 +604,7 @@ type encoderBigComplex struct {
 	values       []*ring.Complex
 	valuesfloat  []*big.Float
 	roots        []*ring.Complex
+	bigintVec    *ring.BigintVec
 }
 
 // NewEncoderBigComplex creates a new encoder using arbitrary precision complex arithmetic.
@@ -654,6 +655,7 @@ func NewEncoderBigComplex(params Parameters, logPrecision int) EncoderBigComplex
 		roots:        roots,
 		values:       values,
 		valuesfloat:  valuesfloat,
+		bigintVec:    params.RingQ().NewBigintVecLvl(params.MaxLevel(), params.N()),
 	}
 }
 
@@ -685,7 +687,7 @@ func (ecd *encoderBigComplex) Encode(values []*ring.Complex, plaintext *Plaintex
 		ecd.valuesfloat[jdx].Set(ecd.values[i].Imag())
 	}
 
-	scaleUpVecExactBigFloat(ecd.valuesfloat, plaintext.Scale, ecd.params.RingQ().Modulus[:plaintext.Level()+1], plaintext.Value.Coeffs)
+	scaleUpVecExactBigFloat(ecd.valuesfloat[:ecd.params.N()], plaintext.Scale, plaintext.Level(), ecd.params.RingQ().CRT(), ecd.bigintVec, plaintext.Value)
 
 	for i := 0; i < (ecd.params.RingQ().N >> 1); i++ {
 		ecd.values[i].Real().Set(ecd.zero)
@@ -800,6 +802,7 @@ func (ecd *encoderBigComplex) ShallowCopy() EncoderBigComplex {
 		values:       values,
 		valuesfloat:  valuesfloat,
 		roots:        ecd.roots,
+		bigintVec:    ecd.params.RingQ().NewBigintVecLvl(ecd.params.MaxLevel(), ecd.params.N()),
 	}
 }
 
@@ -818,41 +821,23 @@ func (ecd *encoderBigComplex) decodePublic(plaintext *Plaintext, logSlots int, s
 		ecd.gaussianSampler.ReadAndAddFromDistLvl(plaintext.Level(), ecd.polypool, ecd.params.RingQ(), sigma, int(2.5066282746310002*sigma+0.5))
 	}
 
-	Q := ecd.bigintChain[plaintext.Level()]
-
 	maxSlots := ecd.params.RingQ().N >> 1
 
 	scaleFlo := ring.NewFloat(plaintext.Scale, ecd.logPrecision)
 
-	ecd.qHalf.Set(Q)
-	ecd.qHalf.Rsh(ecd.qHalf, 1)
-
 	gap := maxSlots / slots
 
-	ecd.params.RingQ().PolyToBigint(ecd.polypool, gap, ecd.bigintCoeffs)
+	// Reconstructs the coefficients centered around the current modulus
+	ecd.params.RingQ().CRT().ReconstructCentered(plaintext.Level(), ecd.polypool, gap, ecd.bigintVec)
 
-	var sign int
+	coeff := new(big.Int)
 
 	for i, j := 0, slots; i < slots; i, j = i+1, j+1 {
 
-		// Centers the value around the current modulus
-		ecd.bigintCoeffs[i].Mod(ecd.bigintCoeffs[i], Q)
-		sign = ecd.bigintCoeffs[i].Cmp(ecd.qHalf)
-		if sign == 1 || sign == 0 {
-			ecd.bigintCoeffs[i].Sub(ecd.bigintCoeffs[i], Q)
-		}
-
-		// Centers the value around the current modulus
-		ecd.bigintCoeffs[j].Mod(ecd.bigintCoeffs[j], Q)
-		sign = ecd.bigintCoeffs[j].Cmp(ecd.qHalf)
-		if sign == 1 || sign == 0 {
-			ecd.bigintCoeffs[j].Sub(ecd.bigintCoeffs[j], Q)
-		}
-
-		ecd.values[i].Real().SetInt(ecd.bigintCoeffs[i])
+		ecd.values[i].Real().SetInt(ecd.bigintVec.Get(i, coeff))
 		ecd.values[i].Real().Quo(ecd.values[i].Real(), scaleFlo)
 
-		ecd.values[i].Imag().SetInt(ecd.bigintCoeffs[j])
+		ecd.values[i].Imag().SetInt(ecd.bigintVec.Get(j, coeff))
 		ecd.values[i].Imag().Quo(ecd.values[i].Imag(), scaleFlo)
 	}
 
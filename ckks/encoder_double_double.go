@@ -0,0 +1,401 @@
+package ckks
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+
+	"github.com/tuneinsight/lattigo/v3/ring"
+)
+
+// EncoderDoubleDouble is an interface that implements the encoding and decoding operations with about 106 bits of precision,
+// using double-double arithmetic (see DoubleDouble). It is a faster alternative to EncoderBigComplex for workloads
+// requiring more precision than the complex128 Encoder, and supports the same ring types as the complex128 Encoder.
+type EncoderDoubleDouble interface {
+	Encode(values interface{}, plaintext *Plaintext, logSlots int)
+	EncodeNew(values interface{}, level int, scale float64, logSlots int) (plaintext *Plaintext)
+	EncodeSlots(values interface{}, plaintext *Plaintext, logSlots int)
+	EncodeSlotsNew(values interface{}, level int, scale float64, logSlots int) (plaintext *Plaintext)
+	Decode(plaintext *Plaintext, logSlots int) (res []ComplexDoubleDouble)
+	DecodeSlots(plaintext *Plaintext, logSlots int) (res []ComplexDoubleDouble)
+	DecodePublic(plaintext *Plaintext, logSlots int, sigma float64) (res []ComplexDoubleDouble)
+	DecodeSlotsPublic(plaintext *Plaintext, logSlots int, sigma float64) (res []ComplexDoubleDouble)
+	FFT(values []ComplexDoubleDouble, N int)
+	InvFFT(values []ComplexDoubleDouble, N int)
+	ShallowCopy() EncoderDoubleDouble
+}
+
+type encoderDoubleDouble struct {
+	encoder
+	values    []ComplexDoubleDouble
+	roots     []ComplexDoubleDouble
+	bigintVec *ring.BigintVec
+}
+
+// NewEncoderDoubleDouble creates a new EncoderDoubleDouble that is used to encode a slice of complex values of size at most N/2 (the number of slots) on a Plaintext.
+func NewEncoderDoubleDouble(params Parameters) EncoderDoubleDouble {
+
+	ecd := newEncoder(params)
+
+	return &encoderDoubleDouble{
+		encoder:   ecd,
+		values:    make([]ComplexDoubleDouble, ecd.m>>2),
+		roots:     genRootsDoubleDouble(ecd.m),
+		bigintVec: params.RingQ().NewBigintVecLvl(params.MaxLevel(), params.N()),
+	}
+}
+
+// genRootsDoubleDouble returns the m+1 powers of the m-th primitive root of unity exp(2*pi*i/m).
+// The first m/8+1 powers are computed with arbitrary precision arithmetic and the others are derived by symmetry.
+func genRootsDoubleDouble(m int) (roots []ComplexDoubleDouble) {
+
+	// The precision of the first m/8 powers degrades by at most log2(m/8) bits
+	prec := uint(106 + bits.Len64(uint64(m)))
+
+	PI := new(big.Float).SetPrec(prec)
+	PI.SetString(pi)
+
+	angle := new(big.Float).SetPrec(prec).SetInt64(2)
+	angle.Mul(angle, PI)
+	angle.Quo(angle, new(big.Float).SetPrec(prec).SetInt64(int64(m)))
+
+	PIHalf := new(big.Float).Quo(PI, new(big.Float).SetPrec(prec).SetInt64(2))
+
+	w := ring.NewComplex(ring.Cos(angle), ring.Cos(new(big.Float).Sub(PIHalf, angle)))
+	x := ring.NewComplex(ring.NewFloat(1, int(prec)), ring.NewFloat(0, int(prec)))
+
+	cMul := ring.NewComplexMultiplier()
+
+	roots = make([]ComplexDoubleDouble, m+1)
+
+	// exp(2*pi*i*k/m) for 0 <= k <= m/8
+	for k := 0; k <= m>>3; k++ {
+		roots[k] = NewComplexDoubleDouble(x)
+		cMul.Mul(x, w, x)
+	}
+
+	// exp(2*pi*i*(m/4-k)/m) = i * conj(exp(2*pi*i*k/m))
+	for k := 0; k <= m>>3; k++ {
+		roots[(m>>2)-k] = ComplexDoubleDouble{roots[k].Imag, roots[k].Real}
+	}
+
+	// exp(2*pi*i*(k+m/4)/m) = i * exp(2*pi*i*k/m)
+	for k := 0; k < m>>2; k++ {
+		roots[k+(m>>2)] = ComplexDoubleDouble{roots[k].Imag.Neg(), roots[k].Real}
+	}
+
+	// exp(2*pi*i*(k+m/2)/m) = -exp(2*pi*i*k/m)
+	for k := 0; k < m>>1; k++ {
+		roots[k+(m>>1)] = ComplexDoubleDouble{roots[k].Real.Neg(), roots[k].Imag.Neg()}
+	}
+
+	roots[m] = roots[0]
+
+	return
+}
+
+// Encode encodes a set of values on the target plaintext.
+// This method is identical to "EncodeSlots".
+// Encoding is done at the level and scale of the plaintext.
+// User must ensure that 1 <= len(values) <= 2^logSlots < 2^logN.
+// values.(type) can be either []ComplexDoubleDouble, []*ring.Complex, []complex128 or []float64.
+// The imaginary part of the values will be discarded if ringType == ring.ConjugateInvariant.
+// Returned plaintext is always in the NTT domain.
+func (ecd *encoderDoubleDouble) Encode(values interface{}, plaintext *Plaintext, logSlots int) {
+	ecd.EncodeSlots(values, plaintext, logSlots)
+}
+
+// EncodeNew encodes a set of values on a new plaintext.
+// This method is identical to "EncodeSlotsNew".
+// Encoding is done at the provided level and with the provided scale.
+// User must ensure that 1 <= len(values) <= 2^logSlots < 2^logN.
+// values.(type) can be either []ComplexDoubleDouble, []*ring.Complex, []complex128 or []float64.
+// The imaginary part of the values will be discarded if ringType == ring.ConjugateInvariant.
+// Returned plaintext is always in the NTT domain.
+func (ecd *encoderDoubleDouble) EncodeNew(values interface{}, level int, scale float64, logSlots int) (plaintext *Plaintext) {
+	return ecd.EncodeSlotsNew(values, level, scale, logSlots)
+}
+
+// EncodeSlots encodes a set of values on the target plaintext.
+// Encoding is done at the level and scale of the plaintext.
+// User must ensure that 1 <= len(values) <= 2^logSlots < 2^logN.
+// values.(type) can be either []ComplexDoubleDouble, []*ring.Complex, []complex128 or []float64.
+// The imaginary part of the values will be discarded if ringType == ring.ConjugateInvariant.
+// Returned plaintext is always in the NTT domain.
+func (ecd *encoderDoubleDouble) EncodeSlots(values interface{}, plaintext *Plaintext, logSlots int) {
+
+	if logSlots < minLogSlots || logSlots > ecd.params.MaxLogSlots() {
+		panic(fmt.Sprintf("cannot Encode: logSlots (%d) must be greater or equal to %d and smaller than %d\n", logSlots, minLogSlots, ecd.params.MaxLogSlots()))
+	}
+
+	slots := 1 << logSlots
+
+	var lenValues int
+
+	switch values := values.(type) {
+	case []ComplexDoubleDouble:
+		lenValues = len(values)
+	case []*ring.Complex:
+		lenValues = len(values)
+	case []complex128:
+		lenValues = len(values)
+	case []float64:
+		lenValues = len(values)
+	default:
+		panic("values.(Type) must be []ComplexDoubleDouble, []*ring.Complex, []complex128 or []float64")
+	}
+
+	if lenValues > ecd.params.MaxSlots() || lenValues > slots {
+		panic(fmt.Sprintf("cannot Encode: ensure that #values (%d) <= slots (%d) <= maxSlots (%d)\n", lenValues, slots, ecd.params.MaxSlots()))
+	}
+
+	switch values := values.(type) {
+	case []ComplexDoubleDouble:
+		copy(ecd.values, values)
+	case []*ring.Complex:
+		for i := range values {
+			ecd.values[i] = NewComplexDoubleDouble(values[i])
+		}
+	case []complex128:
+		for i := range values {
+			ecd.values[i] = ComplexDoubleDouble{DoubleDouble{Hi: real(values[i])}, DoubleDouble{Hi: imag(values[i])}}
+		}
+	case []float64:
+		for i := range values {
+			ecd.values[i] = ComplexDoubleDouble{Real: DoubleDouble{Hi: values[i]}}
+		}
+	}
+
+	isRingStandard := ecd.params.RingType() == ring.Standard
+
+	if !isRingStandard {
+		// Discards the imaginary part
+		for i := range ecd.values[:lenValues] {
+			ecd.values[i].Imag = DoubleDouble{}
+		}
+	}
+
+	for i := lenValues; i < slots; i++ {
+		ecd.values[i] = ComplexDoubleDouble{}
+	}
+
+	ecd.InvFFT(ecd.values, slots)
+
+	level := plaintext.Level()
+	scale := plaintext.Scale
+	ringQ := ecd.params.RingQ()
+
+	for i, v := range ecd.values[:slots] {
+		doubleDoubleToFixedPointCRT(level, i, v.Real, scale, ringQ, plaintext.Value.Coeffs)
+	}
+
+	if isRingStandard {
+		for i, v := range ecd.values[:slots] {
+			doubleDoubleToFixedPointCRT(level, i+slots, v.Imag, scale, ringQ, plaintext.Value.Coeffs)
+		}
+	}
+
+	NttAndMontgomeryLvl(level, logSlots, ringQ, false, plaintext.Value)
+	plaintext.Value.IsNTT = true
+}
+
+// EncodeSlotsNew encodes a set of values on a new plaintext.
+// Encoding is done at the provided level and with the provided scale.
+// User must ensure that 1 <= len(values) <= 2^logSlots < 2^logN.
+// values.(type) can be either []ComplexDoubleDouble, []*ring.Complex, []complex128 or []float64.
+// The imaginary part of the values will be discarded if ringType == ring.ConjugateInvariant.
+// Returned plaintext is always in the NTT domain.
+func (ecd *encoderDoubleDouble) EncodeSlotsNew(values interface{}, level int, scale float64, logSlots int) (plaintext *Plaintext) {
+	plaintext = NewPlaintext(ecd.params, level, scale)
+	ecd.EncodeSlots(values, plaintext, logSlots)
+	return
+}
+
+// Decode decodes the input plaintext on a new slice of ComplexDoubleDouble.
+// This method is the same as .DecodeSlots(*).
+func (ecd *encoderDoubleDouble) Decode(plaintext *Plaintext, logSlots int) (res []ComplexDoubleDouble) {
+	return ecd.decodePublic(plaintext, logSlots, 0)
+}
+
+// DecodeSlots decodes the input plaintext on a new slice of ComplexDoubleDouble.
+func (ecd *encoderDoubleDouble) DecodeSlots(plaintext *Plaintext, logSlots int) (res []ComplexDoubleDouble) {
+	return ecd.decodePublic(plaintext, logSlots, 0)
+}
+
+// DecodePublic decodes the input plaintext on a new slice of ComplexDoubleDouble.
+// This method is the same as .DecodeSlotsPublic(*).
+// Adds, before the decoding step, an error with standard deviation sigma and bound floor(sqrt(2*pi)*sigma).
+func (ecd *encoderDoubleDouble) DecodePublic(plaintext *Plaintext, logSlots int, sigma float64) (res []ComplexDoubleDouble) {
+	return ecd.decodePublic(plaintext, logSlots, sigma)
+}
+
+// DecodeSlotsPublic decodes the input plaintext on a new slice of ComplexDoubleDouble.
+// Adds, before the decoding step, an error with standard deviation sigma and bound floor(sqrt(2*pi)*sigma).
+func (ecd *encoderDoubleDouble) DecodeSlotsPublic(plaintext *Plaintext, logSlots int, sigma float64) (res []ComplexDoubleDouble) {
+	return ecd.decodePublic(plaintext, logSlots, sigma)
+}
+
+// FFT evaluates the decoding matrix on a slice of ComplexDoubleDouble values.
+func (ecd *encoderDoubleDouble) FFT(values []ComplexDoubleDouble, N int) {
+
+	SliceBitReverseInPlaceComplexDoubleDouble(values, N)
+
+	logN := int(bits.Len64(uint64(N))) - 1
+	logM := int(bits.Len64(uint64(ecd.m))) - 1
+
+	for loglen := 1; loglen <= logN; loglen++ {
+		len := 1 << loglen
+		lenh := len >> 1
+		lenq := len << 2
+		logGap := logM - 2 - loglen
+		mask := lenq - 1
+		for i := 0; i < N; i += len {
+			for j, k := 0, i; j < lenh; j, k = j+1, k+1 {
+				v := values[k+lenh].Mul(ecd.roots[(ecd.rotGroup[j]&mask)<<logGap])
+				values[k], values[k+lenh] = values[k].Add(v), values[k].Sub(v)
+			}
+		}
+	}
+}
+
+// InvFFT evaluates the encoding matrix on a slice of ComplexDoubleDouble values.
+func (ecd *encoderDoubleDouble) InvFFT(values []ComplexDoubleDouble, N int) {
+
+	logN := int(bits.Len64(uint64(N))) - 1
+	logM := int(bits.Len64(uint64(ecd.m))) - 1
+
+	for loglen := logN; loglen > 0; loglen-- {
+		len := 1 << loglen
+		lenh := len >> 1
+		lenq := len << 2
+		logGap := logM - 2 - loglen
+		mask := lenq - 1
+		for i := 0; i < N; i += len {
+			for j, k := 0, i; j < lenh; j, k = j+1, k+1 {
+				u, v := values[k], values[k+lenh]
+				values[k], values[k+lenh] = u.Add(v), u.Sub(v).Mul(ecd.roots[(lenq-(ecd.rotGroup[j]&mask))<<logGap])
+			}
+		}
+	}
+
+	// N is a power of two, so the division is exact
+	NInv := 1 / float64(N)
+	for i := range values[:N] {
+		values[i] = ComplexDoubleDouble{values[i].Real.MulFloat64(NInv), values[i].Imag.MulFloat64(NInv)}
+	}
+
+	SliceBitReverseInPlaceComplexDoubleDouble(values, N)
+}
+
+// ShallowCopy creates a shallow copy of this encoderDoubleDouble in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// EncoderDoubleDouble can be used concurrently.
+func (ecd *encoderDoubleDouble) ShallowCopy() EncoderDoubleDouble {
+	return &encoderDoubleDouble{
+		encoder:   *ecd.encoder.ShallowCopy(),
+		values:    make([]ComplexDoubleDouble, len(ecd.values)),
+		roots:     ecd.roots,
+		bigintVec: ecd.params.RingQ().NewBigintVecLvl(ecd.params.MaxLevel(), ecd.params.N()),
+	}
+}
+
+func (ecd *encoderDoubleDouble) decodePublic(plaintext *Plaintext, logSlots int, sigma float64) (res []ComplexDoubleDouble) {
+
+	if logSlots > ecd.params.MaxLogSlots() || logSlots < minLogSlots {
+		panic(fmt.Sprintf("cannot Decode: ensure that %d <= logSlots (%d) <= %d", minLogSlots, logSlots, ecd.params.MaxLogSlots()))
+	}
+
+	ringQ := ecd.params.RingQ()
+	level := plaintext.Level()
+
+	if plaintext.Value.IsNTT {
+		ringQ.InvNTTLvl(level, plaintext.Value, ecd.polypool)
+	} else {
+		ring.CopyValuesLvl(level, plaintext.Value, ecd.polypool)
+	}
+
+	// B = floor(sigma * sqrt(2*pi))
+	if sigma != 0 {
+		ecd.gaussianSampler.ReadAndAddFromDistLvl(level, ecd.polypool, ringQ, sigma, int(2.5066282746310002*sigma))
+	}
+
+	slots := 1 << logSlots
+	maxSlots := int(ringQ.NthRoot >> 2)
+	gap := maxSlots / slots
+
+	// Reconstructs the coefficients centered around the current modulus
+	ringQ.CRT().ReconstructCentered(level, ecd.polypool, gap, ecd.bigintVec)
+
+	isreal := ecd.params.RingType() == ring.ConjugateInvariant
+
+	for i := 0; i < slots; i++ {
+		ecd.values[i].Real = bigintVecToDoubleDouble(ecd.bigintVec, i).QuoFloat64(plaintext.Scale)
+		if isreal {
+			ecd.values[i].Imag = DoubleDouble{}
+		} else {
+			ecd.values[i].Imag = bigintVecToDoubleDouble(ecd.bigintVec, i+slots).QuoFloat64(plaintext.Scale)
+		}
+	}
+
+	if isreal { // [X]/(X^N+1) to [X+X^-1]/(X^N+1)
+		for i := 1; i < slots; i++ {
+			ecd.values[i].Imag = ecd.values[i].Imag.Sub(ecd.values[slots-i].Real)
+		}
+	}
+
+	ecd.FFT(ecd.values, slots)
+
+	res = make([]ComplexDoubleDouble, slots)
+	copy(res, ecd.values)
+
+	return
+}
+
+// doubleDoubleToFixedPointCRT writes round(value * scale) mod q_j on coeffs[j][i] for j = 0 to level.
+func doubleDoubleToFixedPointCRT(level, i int, value DoubleDouble, scale float64, ringQ *ring.Ring, coeffs [][]uint64) {
+
+	x := value.MulFloat64(scale).Round()
+
+	if math.IsNaN(x.Hi) || math.IsInf(x.Hi, 0) {
+		panic("cannot Encode: value * scale is not finite")
+	}
+
+	for j, qi := range ringQ.Modulus[:level+1] {
+		if coeffs[j][i] = floatIntMod(x.Hi, qi) + floatIntMod(x.Lo, qi); coeffs[j][i] >= qi {
+			coeffs[j][i] -= qi
+		}
+	}
+}
+
+// bigintVecToDoubleDouble returns the DoubleDouble closest to the i-th element of v,
+// truncating the words below the three most significant ones.
+func bigintVecToDoubleDouble(v *ring.BigintVec, i int) (x DoubleDouble) {
+
+	abs := v.Abs[i*v.Words : (i+1)*v.Words]
+
+	n := len(abs)
+	for n > 0 && abs[n-1] == 0 {
+		n--
+	}
+
+	lowest := n - 3
+	if lowest < 0 {
+		lowest = 0
+	}
+
+	for j := n - 1; j >= lowest; j-- {
+		// Both halves of the word are exactly representable
+		hi, lo := quickTwoSum(float64(abs[j]>>32)*0x1p32, float64(abs[j]&0xffffffff))
+		x = x.MulFloat64(0x1p64).Add(DoubleDouble{hi, lo})
+	}
+
+	x.Hi = math.Ldexp(x.Hi, 64*lowest)
+	x.Lo = math.Ldexp(x.Lo, 64*lowest)
+
+	if v.Neg[i] {
+		x = x.Neg()
+	}
+
+	return
+}
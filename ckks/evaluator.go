@@ -82,6 +82,12 @@ type Evaluator interface {
 	PermuteNTTHoisted(level int, c0, c1 *ring.Poly, c2DecompQP []rlwe.PolyQP, k int, cOut0, cOut1 *ring.Poly)
 	PermuteNTTHoistedNoModDown(level int, c0 *ring.Poly, c2DecompQP []rlwe.PolyQP, k int, ct0OutQ, ct1OutQ, ct0OutP, ct1OutP *ring.Poly)
 
+	// Automorphisms and slot permutations
+	AutomorphismNew(ctIn *Ciphertext, galEl uint64) (ctOut *Ciphertext)
+	Automorphism(ctIn *Ciphertext, galEl uint64, ctOut *Ciphertext)
+	PermuteNew(ctIn *Ciphertext, permutation Permutation) (ctOut *Ciphertext)
+	Permute(ctIn *Ciphertext, permutation Permutation, ctOut *Ciphertext)
+
 	// ===========================
 	// === Advanced Arithmetic ===
 	// ===========================
@@ -98,6 +104,7 @@ type Evaluator interface {
 	// Polynomial evaluation
 	EvaluatePoly(ctIn *Ciphertext, pol *Polynomial, targetScale float64) (ctOut *Ciphertext, err error)
 	EvaluatePolyVector(ctIn *Ciphertext, pols []*Polynomial, encoder Encoder, slotIndex map[int][]int, targetScale float64) (ctOut *Ciphertext, err error)
+	EvaluateEncryptedPoly(ctIn *Ciphertext, pol *EncryptedPolynomial, targetScale float64) (ctOut *Ciphertext, err error)
 
 	// Inversion
 	InverseNew(ctIn *Ciphertext, steps int) (ctOut *Ciphertext)
@@ -155,6 +162,7 @@ type Evaluator interface {
 	CtxPool() *Ciphertext
 	ShallowCopy() Evaluator
 	WithKey(rlwe.EvaluationKey) Evaluator
+	WithPool(*rlwe.Pool) Evaluator
 }
 
 // evaluator is a struct that holds the necessary elements to execute the homomorphic operations between Ciphertexts and/or Plaintexts.
@@ -167,6 +175,8 @@ type evaluator struct {
 	rlk             *rlwe.RelinearizationKey
 	rtks            *rlwe.RotationKeySet
 	permuteNTTIndex map[uint64][]uint64
+
+	pool *rlwe.Pool
 }
 
 type evaluatorBase struct {
@@ -174,8 +184,9 @@ type evaluatorBase struct {
 }
 
 type evaluatorBuffers struct {
-	poolQMul [3]*ring.Poly // Memory pool in order : for MForm(c0), MForm(c1), c2
-	ctxpool  *Ciphertext   // Memory pool for ciphertext that need to be scaled up (to be removed eventually)
+	poolQMul  [3]*ring.Poly          // Memory pool in order : for MForm(c0), MForm(c1), c2
+	ctxpool   *Ciphertext            // Memory pool for ciphertext that need to be scaled up (to be removed eventually)
+	ctInRotQP map[int][2]rlwe.PolyQP // Hoisted rotations of the inner sums and linear transforms
 }
 
 // PoolQMul returns a pointer to internal memory pool poolQMul.
@@ -200,6 +211,7 @@ func newEvaluatorBuffers(evalBase *evaluatorBase) *evaluatorBuffers {
 	ringQ := params.RingQ()
 	buff.poolQMul = [3]*ring.Poly{ringQ.NewPoly(), ringQ.NewPoly(), ringQ.NewPoly()}
 	buff.ctxpool = NewCiphertext(params, 2, params.MaxLevel(), params.DefaultScale())
+	buff.ctInRotQP = make(map[int][2]rlwe.PolyQP)
 	return buff
 }
 
@@ -235,6 +247,26 @@ func (eval *evaluator) permuteNTTIndexesForKey(rtks *rlwe.RotationKeySet) *map[u
 	return &permuteNTTIndex
 }
 
+// getCiphertext returns a Ciphertext of degree 1 at the given level in the NTT domain, whose polynomials are drawn
+// from the pool of the evaluator if any, and must then be released with releaseCiphertext.
+func (eval *evaluator) getCiphertext(level int) (ct *Ciphertext) {
+	if eval.pool == nil {
+		return NewCiphertext(eval.params, 1, level, 0)
+	}
+	ct = &Ciphertext{Ciphertext: eval.pool.GetCiphertext(1, level)}
+	for _, pol := range ct.Value {
+		pol.IsNTT = true
+	}
+	return
+}
+
+// releaseCiphertext releases the polynomials of a Ciphertext obtained with getCiphertext to the pool of the evaluator, if any.
+func (eval *evaluator) releaseCiphertext(ct *Ciphertext) {
+	if eval.pool != nil {
+		eval.pool.ReleaseCiphertext(ct.Ciphertext)
+	}
+}
+
 // GetKeySwitcher returns a pointer to the internal rlwe.KeySwither.
 func (eval *evaluator) GetKeySwitcher() *rlwe.KeySwitcher {
 	return eval.KeySwitcher
@@ -1470,6 +1502,32 @@ func (eval *evaluator) Conjugate(ct0 *Ciphertext, ctOut *Ciphertext) {
 	eval.permuteNTT(ct0, galEl, ctOut)
 }
 
+// AutomorphismNew applies the automorphism X -> X^galEl on ct0 and returns the result in a newly created element.
+// If the provided element is a Ciphertext, a key-switching operation is necessary and a rotation key for galEl needs to be provided.
+func (eval *evaluator) AutomorphismNew(ct0 *Ciphertext, galEl uint64) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(eval.params, ct0.Degree(), ct0.Level(), ct0.Scale)
+	eval.Automorphism(ct0, galEl, ctOut)
+	return
+}
+
+// Automorphism applies the automorphism X -> X^galEl on ct0 and returns the result in ctOut.
+// Rotate and Conjugate are special cases of Automorphism, for the galois elements
+// params.GaloisElementForColumnRotationBy(k) and params.GaloisElementForRowRotation().
+// If the provided element is a Ciphertext, a key-switching operation is necessary and a rotation key for galEl needs to be provided.
+func (eval *evaluator) Automorphism(ct0 *Ciphertext, galEl uint64, ctOut *Ciphertext) {
+
+	if ct0.Degree() != 1 || ctOut.Degree() != 1 {
+		panic("cannot Automorphism: input and output Ciphertext must be of degree 1")
+	}
+
+	if galEl&(eval.params.RingQ().NthRoot-1) == 1 {
+		ctOut.Copy(ct0)
+	} else {
+		ctOut.Scale = ct0.Scale
+		eval.permuteNTT(ct0, galEl, ctOut)
+	}
+}
+
 func (eval *evaluator) permuteNTT(ct0 *Ciphertext, galEl uint64, ctOut *Ciphertext) {
 
 	rtk, generated := eval.rtks.GetRotationKey(galEl)
@@ -1491,19 +1549,49 @@ func (eval *evaluator) permuteNTT(ct0 *Ciphertext, galEl uint64, ctOut *Cipherte
 	ringQ.PermuteNTTWithIndexLvl(level, pool3Q, index, ctOut.Value[1])
 }
 
+// RotateHoistedNoModDownNew returns the rotations of the hoisted ciphertext (c0, c2DecompQP) by the given rotations, without the division by P.
+// If the evaluator has a pool (see WithPool), the returned polynomials are drawn from it and can be released with rlwe.Pool.ReleasePolyQP.
 func (eval *evaluator) RotateHoistedNoModDownNew(level int, rotations []int, c0 *ring.Poly, c2DecompQP []rlwe.PolyQP) (cOut map[int][2]rlwe.PolyQP) {
-	ringQ := eval.params.RingQ()
-	ringP := eval.params.RingP()
 	cOut = make(map[int][2]rlwe.PolyQP)
 	for _, i := range rotations {
+		eval.rotateHoistedNoModDown(level, i, c0, c2DecompQP, cOut)
+	}
+	return
+}
 
-		if i != 0 {
-			cOut[i] = [2]rlwe.PolyQP{{Q: ringQ.NewPolyLvl(level), P: ringP.NewPoly()}, {Q: ringQ.NewPolyLvl(level), P: ringP.NewPoly()}}
-			eval.PermuteNTTHoistedNoModDown(level, c0, c2DecompQP, i, cOut[i][0].Q, cOut[i][1].Q, cOut[i][0].P, cOut[i][1].P)
+// rotateHoistedNoModDown adds on cOut the rotation by k of the hoisted ciphertext (c0, c2DecompQP), without the division by P.
+// The polynomials are drawn from the pool of the evaluator, if any, and are released by releaseRotateHoistedNoModDown.
+func (eval *evaluator) rotateHoistedNoModDown(level, k int, c0 *ring.Poly, c2DecompQP []rlwe.PolyQP, cOut map[int][2]rlwe.PolyQP) {
+
+	if k == 0 {
+		return
+	}
+
+	c, ok := cOut[k]
+	if !ok {
+		if eval.pool != nil {
+			levelP := eval.params.PCount() - 1
+			c = [2]rlwe.PolyQP{eval.pool.GetPolyQP(level, levelP), eval.pool.GetPolyQP(level, levelP)}
+		} else {
+			ringQ := eval.params.RingQ()
+			ringP := eval.params.RingP()
+			c = [2]rlwe.PolyQP{{Q: ringQ.NewPolyLvl(level), P: ringP.NewPoly()}, {Q: ringQ.NewPolyLvl(level), P: ringP.NewPoly()}}
 		}
+		cOut[k] = c
 	}
 
-	return
+	eval.PermuteNTTHoistedNoModDown(level, c0, c2DecompQP, k, c[0].Q, c[1].Q, c[0].P, c[1].P)
+}
+
+// releaseRotateHoistedNoModDown empties cOut and releases its polynomials to the pool of the evaluator, if any.
+func (eval *evaluator) releaseRotateHoistedNoModDown(cOut map[int][2]rlwe.PolyQP) {
+	for k, c := range cOut {
+		if eval.pool != nil {
+			eval.pool.ReleasePolyQP(c[0])
+			eval.pool.ReleasePolyQP(c[1])
+		}
+		delete(cOut, k)
+	}
 }
 
 func (eval *evaluator) PermuteNTTHoistedNoModDown(level int, c0 *ring.Poly, c2DecompQP []rlwe.PolyQP, k int, ct0OutQ, ct1OutQ, ct0OutP, ct1OutP *ring.Poly) {
@@ -1586,6 +1674,22 @@ func (eval *evaluator) ShallowCopy() Evaluator {
 	}
 }
 
+// WithPool creates a shallow copy of the receiver Evaluator which draws its temporary polynomials from the pool
+// and where the temporary buffers and the evaluation key are shared. The receiver and the returned Evaluators cannot be
+// used concurrently. If the pool is an arena (see rlwe.NewArena), the evaluation of a fixed circuit with the methods
+// that do not return new objects does not allocate.
+func (eval *evaluator) WithPool(pool *rlwe.Pool) Evaluator {
+	return &evaluator{
+		KeySwitcher:      eval.KeySwitcher,
+		evaluatorBase:    eval.evaluatorBase,
+		evaluatorBuffers: eval.evaluatorBuffers,
+		rlk:              eval.rlk,
+		rtks:             eval.rtks,
+		permuteNTTIndex:  eval.permuteNTTIndex,
+		pool:             pool,
+	}
+}
+
 // WithKey creates a shallow copy of the receiver Evaluator for which the new EvaluationKey is evaluationKey
 // and where the temporary buffers are shared. The receiver and the returned Evaluators cannot be used concurrently.
 func (eval *evaluator) WithKey(evaluationKey rlwe.EvaluationKey) Evaluator {
@@ -1602,5 +1706,6 @@ func (eval *evaluator) WithKey(evaluationKey rlwe.EvaluationKey) Evaluator {
 		rlk:              evaluationKey.Rlk,
 		rtks:             evaluationKey.Rtks,
 		permuteNTTIndex:  indexes,
+		pool:             eval.pool,
 	}
 }
@@ -1,8 +1,6 @@
 package ckks
 
 import (
-	"runtime"
-
 	"github.com/tuneinsight/lattigo/v3/ring"
 	"github.com/tuneinsight/lattigo/v3/rlwe"
 	"github.com/tuneinsight/lattigo/v3/utils"
@@ -110,6 +108,10 @@ type LinearTransform struct {
 	Level    int                 // Level is the level at which the matrix is encoded (can be circuit dependent)
 	Scale    float64             // Scale is the scale at which the matrix is encoded (can be circuit dependent)
 	Vec      map[int]rlwe.PolyQP // Vec is the matrix, in diagonal form, where each entry of vec is an indexed non-zero diagonal.
+
+	// BSGS index of the non-zero diagonals of Vec (see BsgsIndex), computed at the creation of the LinearTransform
+	index map[int][]int
+	rotN2 []int
 }
 
 // NewLinearTransform allocates a new LinearTransform with zero plaintexts at the specified level.
@@ -132,12 +134,13 @@ func NewLinearTransform(params Parameters, nonZeroDiags []int, level, logSlots i
 		}
 	} else if BSGSRatio > 0 {
 		N1 = FindBestBSGSSplit(nonZeroDiags, slots, BSGSRatio)
-		index, _, _ := BsgsIndex(nonZeroDiags, slots, N1)
+		index, _, rotN2 := BsgsIndex(nonZeroDiags, slots, N1)
 		for j := range index {
 			for _, i := range index[j] {
 				vec[j+i] = params.RingQP().NewPolyLvl(levelQ, levelP)
 			}
 		}
+		return LinearTransform{LogSlots: logSlots, N1: N1, Level: level, Vec: vec, index: index, rotN2: rotN2}
 	} else {
 		panic("BSGS ratio cannot be negative")
 	}
@@ -291,7 +294,7 @@ func GenLinearTransformBSGS(encoder Encoder, value interface{}, level int, scale
 	// N1*N2 = N
 	N1 := FindBestBSGSSplit(value, slots, BSGSRatio)
 
-	index, _, _ := BsgsIndex(value, slots, N1)
+	index, _, rotN2 := BsgsIndex(value, slots, N1)
 
 	vec := make(map[int]rlwe.PolyQP)
 
@@ -312,7 +315,7 @@ func GenLinearTransformBSGS(encoder Encoder, value interface{}, level int, scale
 		}
 	}
 
-	return LinearTransform{LogSlots: logSlots, N1: N1, Vec: vec, Level: level, Scale: scale}
+	return LinearTransform{LogSlots: logSlots, N1: N1, Vec: vec, Level: level, Scale: scale, index: index, rotN2: rotN2}
 }
 
 // BsgsIndex returns the index map and needed rotation for the BSGS matrix-vector multiplication algorithm.
@@ -677,12 +680,6 @@ func (eval *evaluator) InnerSum(ctIn *Ciphertext, batchSize, n int, ctOut *Ciphe
 		// If sum on at least two elements
 	} else {
 
-		// List of n-2 rotations
-		rotations := []int{}
-		for i := 1; i < n; i++ {
-			rotations = append(rotations, i*batchSize)
-		}
-
 		// Memory pool
 		tmp0QP := eval.Pool[1]
 		tmp1QP := eval.Pool[2]
@@ -692,7 +689,10 @@ func (eval *evaluator) InnerSum(ctIn *Ciphertext, batchSize, n int, ctOut *Ciphe
 
 		// Pre-rotates all [1, ..., n-1] rotations
 		// Hoisted rotation without division by P
-		ctInRotQP := eval.RotateHoistedNoModDownNew(levelQ, rotations, ctIn.Value[0], eval.PoolDecompQP)
+		ctInRotQP := eval.ctInRotQP
+		for i := 1; i < n; i++ {
+			eval.rotateHoistedNoModDown(levelQ, i*batchSize, ctIn.Value[0], eval.PoolDecompQP, ctInRotQP)
+		}
 
 		// P*c0 -> tmp0QP.Q
 		ringQ.MulScalarBigintLvl(levelQ, ctIn.Value[0], ringP.ModulusBigint, tmp0QP.Q)
@@ -736,6 +736,8 @@ func (eval *evaluator) InnerSum(ctIn *Ciphertext, batchSize, n int, ctOut *Ciphe
 			ringP.ReduceLvl(levelP, tmp1QP.P, tmp1QP.P)
 		}
 
+		eval.releaseRotateHoistedNoModDown(ctInRotQP)
+
 		// Division by P of sum(elements [2, ..., n-1] )
 		eval.BasisExtender.ModDownQPtoQNTT(levelQ, levelP, tmp0QP.Q, tmp0QP.P, tmp0QP.Q) // sum_{i=1, n-1}(phi(d0))/P
 		eval.BasisExtender.ModDownQPtoQNTT(levelQ, levelP, tmp1QP.Q, tmp1QP.P, tmp1QP.Q) // sum_{i=1, n-1}(phi(d1))/P
@@ -894,14 +896,20 @@ func (eval *evaluator) MultiplyByDiagMatrixBSGS(ctIn *Ciphertext, matrix LinearT
 
 	// Computes the N2 rotations indexes of the non-zero rows of the diagonalized DFT matrix for the baby-step giang-step algorithm
 
-	index, _, rotN2 := BsgsIndex(matrix.Vec, 1<<matrix.LogSlots, matrix.N1)
+	index, rotN2 := matrix.index, matrix.rotN2
+	if index == nil {
+		index, _, rotN2 = BsgsIndex(matrix.Vec, 1<<matrix.LogSlots, matrix.N1)
+	}
 
 	ring.CopyValuesLvl(levelQ, ctIn.Value[0], eval.ctxpool.Value[0])
 	ring.CopyValuesLvl(levelQ, ctIn.Value[1], eval.ctxpool.Value[1])
 	ctInTmp0, ctInTmp1 := eval.ctxpool.Value[0], eval.ctxpool.Value[1]
 
 	// Pre-rotates ciphertext for the baby-step giant-step algorithm, does not divide by P yet
-	ctInRotQP := eval.RotateHoistedNoModDownNew(levelQ, rotN2, ctInTmp0, eval.PoolDecompQP)
+	ctInRotQP := eval.ctInRotQP
+	for _, i := range rotN2 {
+		eval.rotateHoistedNoModDown(levelQ, i, ctInTmp0, eval.PoolDecompQP, ctInRotQP)
+	}
 
 	// Accumulator inner loop
 	tmp0QP := eval.Pool[1]
@@ -1034,7 +1042,5 @@ func (eval *evaluator) MultiplyByDiagMatrixBSGS(ctIn *Ciphertext, matrix LinearT
 
 	ctOut.Scale = matrix.Scale * ctIn.Scale
 
-	ctInRotQP = nil
-	runtime.GC()
-
+	eval.releaseRotateHoistedNoModDown(ctInRotQP)
 }
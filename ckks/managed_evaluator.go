@@ -0,0 +1,248 @@
+package ckks
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+// Bootstrapper is an interface for a procedure that re-encrypts a Ciphertext at a higher level,
+// such as the bootstrapping.Bootstrapper.
+type Bootstrapper interface {
+	Bootstrapp(ctIn *Ciphertext) (ctOut *Ciphertext)
+}
+
+// ManagedEvaluator is a wrapper around an Evaluator that automatically manages the level and the scale
+// of the operands of its arithmetic operations:
+//
+// - the result of a multiplication is rescaled lazily, i.e. only when it is used as the operand of a subsequent operation,
+//
+// - operands of an addition or a subtraction with incompatible scales are brought to the same scale, consuming one level,
+//
+// - operands whose level is too small to carry out the next operation are bootstrapped using the Bootstrapper.
+//
+// These steps are applied in place on the Ciphertext operands, without changing the message that they encrypt.
+// Methods of the Evaluator interface that are not redefined by the ManagedEvaluator are not managed.
+type ManagedEvaluator struct {
+	Evaluator
+	params Parameters
+	btp    Bootstrapper
+}
+
+// NewManagedEvaluator creates a new ManagedEvaluator wrapping the input Evaluator.
+// The Bootstrapper is used to refresh operands that do not have enough levels left, and can be nil,
+// in which case the ManagedEvaluator panics when an operand does not have enough levels.
+func NewManagedEvaluator(params Parameters, eval Evaluator, btp Bootstrapper) *ManagedEvaluator {
+	return &ManagedEvaluator{Evaluator: eval, params: params, btp: btp}
+}
+
+// Prepare rescales the input Ciphertext if a rescaling is pending, and bootstraps it if its level is
+// smaller than depth. The operation is done in place.
+func (eval *ManagedEvaluator) Prepare(ct *Ciphertext, depth int) {
+
+	if ct.Level() > 0 {
+		if err := eval.Rescale(ct, eval.params.DefaultScale(), ct); err != nil {
+			panic(err)
+		}
+	}
+
+	if ct.Level() < depth {
+
+		if eval.btp == nil {
+			panic(fmt.Errorf("cannot Prepare: ciphertext level %d < %d and no Bootstrapper is available", ct.Level(), depth))
+		}
+
+		ctBtp := eval.btp.Bootstrapp(ct)
+
+		if ctBtp.Level() < depth {
+			panic(fmt.Errorf("cannot Prepare: bootstrapped ciphertext level %d < %d", ctBtp.Level(), depth))
+		}
+
+		ct.Ciphertext = ctBtp.Ciphertext
+		ct.Scale = ctBtp.Scale
+	}
+}
+
+// prepareOperand calls Prepare on the operand if it is a Ciphertext.
+func (eval *ManagedEvaluator) prepareOperand(op Operand, depth int) {
+	if ct, isCiphertext := op.(*Ciphertext); isCiphertext {
+		eval.Prepare(ct, depth)
+	}
+}
+
+// matchScales brings two Ciphertexts to the same scale, by setting the scale of the one with
+// the highest level to the scale of the other one. This operation consumes one level.
+func (eval *ManagedEvaluator) matchScales(op0, op1 Operand) {
+
+	ct0, isCiphertext0 := op0.(*Ciphertext)
+	ct1, isCiphertext1 := op1.(*Ciphertext)
+
+	if !isCiphertext0 || !isCiphertext1 || ct0 == ct1 || scalesAreCompatible(ct0.Scale, ct1.Scale) {
+		return
+	}
+
+	if ct0.Level() < ct1.Level() {
+		ct0, ct1 = ct1, ct0
+	}
+
+	eval.Prepare(ct0, 1)
+
+	if scalesAreCompatible(ct0.Scale, ct1.Scale) {
+		return
+	}
+
+	if ct0.Level() > ct1.Level()+1 {
+		eval.DropLevel(ct0, ct0.Level()-ct1.Level()-1)
+	}
+
+	eval.SetScale(ct0, ct1.Scale)
+}
+
+// scalesAreCompatible returns true if the ratio between the two scales is an integer,
+// in which case the Evaluator can match them exactly without consuming a level.
+func scalesAreCompatible(scale0, scale1 float64) bool {
+	ratio := math.Max(scale0, scale1) / math.Min(scale0, scale1)
+	return ratio == math.Round(ratio)
+}
+
+// Add adds op0 to op1 and returns the result in ctOut.
+func (eval *ManagedEvaluator) Add(op0, op1 Operand, ctOut *Ciphertext) {
+	eval.prepareOperand(op0, 0)
+	eval.prepareOperand(op1, 0)
+	eval.matchScales(op0, op1)
+	eval.Evaluator.Add(op0, op1, ctOut)
+}
+
+// AddNew adds op0 to op1 and returns the result in a newly created element.
+func (eval *ManagedEvaluator) AddNew(op0, op1 Operand) (ctOut *Ciphertext) {
+	eval.prepareOperand(op0, 0)
+	eval.prepareOperand(op1, 0)
+	eval.matchScales(op0, op1)
+	return eval.Evaluator.AddNew(op0, op1)
+}
+
+// Sub subtracts op1 from op0 and returns the result in ctOut.
+func (eval *ManagedEvaluator) Sub(op0, op1 Operand, ctOut *Ciphertext) {
+	eval.prepareOperand(op0, 0)
+	eval.prepareOperand(op1, 0)
+	eval.matchScales(op0, op1)
+	eval.Evaluator.Sub(op0, op1, ctOut)
+}
+
+// SubNew subtracts op1 from op0 and returns the result in a newly created element.
+func (eval *ManagedEvaluator) SubNew(op0, op1 Operand) (ctOut *Ciphertext) {
+	eval.prepareOperand(op0, 0)
+	eval.prepareOperand(op1, 0)
+	eval.matchScales(op0, op1)
+	return eval.Evaluator.SubNew(op0, op1)
+}
+
+// AddConst adds the input constant to ct0 and returns the result in ctOut.
+func (eval *ManagedEvaluator) AddConst(ct0 *Ciphertext, constant interface{}, ctOut *Ciphertext) {
+	eval.Prepare(ct0, 0)
+	eval.Evaluator.AddConst(ct0, constant, ctOut)
+}
+
+// AddConstNew adds the input constant to ct0 and returns the result in a newly created element.
+func (eval *ManagedEvaluator) AddConstNew(ct0 *Ciphertext, constant interface{}) (ctOut *Ciphertext) {
+	eval.Prepare(ct0, 0)
+	return eval.Evaluator.AddConstNew(ct0, constant)
+}
+
+// MultByConst multiplies ct0 by the input constant and returns the result in ctOut.
+// The rescaling of ctOut is postponed to its next use by the ManagedEvaluator.
+func (eval *ManagedEvaluator) MultByConst(ct0 *Ciphertext, constant interface{}, ctOut *Ciphertext) {
+	eval.Prepare(ct0, 1)
+	eval.Evaluator.MultByConst(ct0, constant, ctOut)
+}
+
+// MultByConstNew multiplies ct0 by the input constant and returns the result in a newly created element.
+// The rescaling of ctOut is postponed to its next use by the ManagedEvaluator.
+func (eval *ManagedEvaluator) MultByConstNew(ct0 *Ciphertext, constant interface{}) (ctOut *Ciphertext) {
+	eval.Prepare(ct0, 1)
+	return eval.Evaluator.MultByConstNew(ct0, constant)
+}
+
+// Mul multiplies op0 with op1 without relinearization and returns the result in ctOut.
+// The rescaling of ctOut is postponed to its next use by the ManagedEvaluator.
+func (eval *ManagedEvaluator) Mul(op0, op1 Operand, ctOut *Ciphertext) {
+	eval.prepareOperand(op0, 1)
+	eval.prepareOperand(op1, 1)
+	eval.Evaluator.Mul(op0, op1, ctOut)
+}
+
+// MulNew multiplies op0 with op1 without relinearization and returns the result in a newly created element.
+// The rescaling of ctOut is postponed to its next use by the ManagedEvaluator.
+func (eval *ManagedEvaluator) MulNew(op0, op1 Operand) (ctOut *Ciphertext) {
+	eval.prepareOperand(op0, 1)
+	eval.prepareOperand(op1, 1)
+	return eval.Evaluator.MulNew(op0, op1)
+}
+
+// MulRelin multiplies op0 with op1 with relinearization and returns the result in ctOut.
+// The rescaling of ctOut is postponed to its next use by the ManagedEvaluator.
+func (eval *ManagedEvaluator) MulRelin(op0, op1 Operand, ctOut *Ciphertext) {
+	eval.prepareOperand(op0, 1)
+	eval.prepareOperand(op1, 1)
+	eval.Evaluator.MulRelin(op0, op1, ctOut)
+}
+
+// MulRelinNew multiplies op0 with op1 with relinearization and returns the result in a newly created element.
+// The rescaling of ctOut is postponed to its next use by the ManagedEvaluator.
+func (eval *ManagedEvaluator) MulRelinNew(op0, op1 Operand) (ctOut *Ciphertext) {
+	eval.prepareOperand(op0, 1)
+	eval.prepareOperand(op1, 1)
+	return eval.Evaluator.MulRelinNew(op0, op1)
+}
+
+// Rotate rotates the columns of ct0 by k positions to the left and returns the result in ctOut.
+func (eval *ManagedEvaluator) Rotate(ct0 *Ciphertext, k int, ctOut *Ciphertext) {
+	eval.Prepare(ct0, 0)
+	eval.Evaluator.Rotate(ct0, k, ctOut)
+}
+
+// RotateNew rotates the columns of ct0 by k positions to the left, and returns the result in a newly created element.
+func (eval *ManagedEvaluator) RotateNew(ct0 *Ciphertext, k int) (ctOut *Ciphertext) {
+	eval.Prepare(ct0, 0)
+	return eval.Evaluator.RotateNew(ct0, k)
+}
+
+// Conjugate conjugates ct0 (which is equivalent to a row rotation) and returns the result in ctOut.
+func (eval *ManagedEvaluator) Conjugate(ct0 *Ciphertext, ctOut *Ciphertext) {
+	eval.Prepare(ct0, 0)
+	eval.Evaluator.Conjugate(ct0, ctOut)
+}
+
+// ConjugateNew conjugates ct0 (which is equivalent to a row rotation) and returns the result in a newly created element.
+func (eval *ManagedEvaluator) ConjugateNew(ct0 *Ciphertext) (ctOut *Ciphertext) {
+	eval.Prepare(ct0, 0)
+	return eval.Evaluator.ConjugateNew(ct0)
+}
+
+// EvaluatePoly evaluates a polynomial on the input Ciphertext, after bootstrapping it if it
+// does not have enough levels for the evaluation. See Evaluator.EvaluatePoly.
+func (eval *ManagedEvaluator) EvaluatePoly(ct0 *Ciphertext, pol *Polynomial, targetScale float64) (ctOut *Ciphertext, err error) {
+	eval.Prepare(ct0, pol.Depth())
+	return eval.Evaluator.EvaluatePoly(ct0, pol, targetScale)
+}
+
+// EvaluateEncryptedPoly evaluates a polynomial with encrypted coefficients on the input Ciphertext, after
+// bootstrapping it if it does not have enough levels for the evaluation. See Evaluator.EvaluateEncryptedPoly.
+func (eval *ManagedEvaluator) EvaluateEncryptedPoly(ct0 *Ciphertext, pol *EncryptedPolynomial, targetScale float64) (ctOut *Ciphertext, err error) {
+	eval.Prepare(ct0, pol.Depth())
+	return eval.Evaluator.EvaluateEncryptedPoly(ct0, pol, targetScale)
+}
+
+// ShallowCopy creates a shallow copy of this ManagedEvaluator in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The Bootstrapper is shared with the receiver,
+// hence the two ManagedEvaluators must not bootstrap concurrently.
+func (eval *ManagedEvaluator) ShallowCopy() Evaluator {
+	return &ManagedEvaluator{Evaluator: eval.Evaluator.ShallowCopy(), params: eval.params, btp: eval.btp}
+}
+
+// WithKey creates a shallow copy of this ManagedEvaluator in which the read-only data-structures are
+// shared with the receiver but the EvaluationKey is evaluationKey. The Bootstrapper is shared with the receiver.
+func (eval *ManagedEvaluator) WithKey(evaluationKey rlwe.EvaluationKey) Evaluator {
+	return &ManagedEvaluator{Evaluator: eval.Evaluator.WithKey(evaluationKey), params: eval.params, btp: eval.btp}
+}
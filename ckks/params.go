@@ -315,6 +315,9 @@ var (
 // Optionally, users may specify the error variance (Sigma), the secrets' density (H), the ring
 // type (RingType) and the number of slots (in log_2, LogSlots). If left unset, standard default values for
 // these field are substituted at parameter creation (see NewParametersFromLiteral).
+//
+// The size of the RNS limbs (LimbSize), the type of the discrete Gaussian samplers (GaussianSamplerType) and
+// the distributions of the secrets, errors and ephemeral secrets (Xs, Xe and Xu) are those of rlwe.ParametersLiteral.
 type ParametersLiteral struct {
 	LogN         int // Ring degree (power of 2)
 	Q            []uint64
@@ -326,6 +329,32 @@ type ParametersLiteral struct {
 	LogSlots     int
 	DefaultScale float64
 	RingType     ring.Type
+	LimbSize     int `json:",omitempty"`
+
+	GaussianSamplerType ring.GaussianSamplerType `json:",omitempty"`
+
+	Xs *ring.Distribution `json:",omitempty"`
+	Xe *ring.Distribution `json:",omitempty"`
+	Xu *ring.Distribution `json:",omitempty"`
+}
+
+// RLWEParametersLiteral returns the rlwe.ParametersLiteral of the receiver.
+func (p ParametersLiteral) RLWEParametersLiteral() rlwe.ParametersLiteral {
+	return rlwe.ParametersLiteral{
+		LogN:                p.LogN,
+		Q:                   p.Q,
+		P:                   p.P,
+		LogQ:                p.LogQ,
+		LogP:                p.LogP,
+		H:                   p.H,
+		Sigma:               p.Sigma,
+		RingType:            p.RingType,
+		LimbSize:            p.LimbSize,
+		GaussianSamplerType: p.GaussianSamplerType,
+		Xs:                  p.Xs,
+		Xe:                  p.Xe,
+		Xu:                  p.Xu,
+	}
 }
 
 // DefaultParams is a set of default CKKS parameters ensuring 128 bit security in a classic setting.
@@ -371,7 +400,7 @@ func NewParameters(rlweParams rlwe.Parameters, logSlots int, defaultScale float6
 //
 // See `rlwe.NewParametersFromLiteral` for default values of the other optional fields.
 func NewParametersFromLiteral(pl ParametersLiteral) (Parameters, error) {
-	rlweParams, err := rlwe.NewParametersFromLiteral(rlwe.ParametersLiteral{LogN: pl.LogN, Q: pl.Q, P: pl.P, LogQ: pl.LogQ, LogP: pl.LogP, H: pl.H, Sigma: pl.Sigma, RingType: pl.RingType})
+	rlweParams, err := rlwe.NewParametersFromLiteral(pl.RLWEParametersLiteral())
 	if err != nil {
 		return Parameters{}, err
 	}
@@ -593,9 +622,34 @@ func (p Parameters) MarshalBinarySize() int {
 	return p.Parameters.MarshalBinarySize() + 9
 }
 
+// ParametersLiteral returns the ParametersLiteral of the receiver, from which NewParametersFromLiteral
+// instantiates the same parameters.
+func (p Parameters) ParametersLiteral() ParametersLiteral {
+	var limbSize int
+	if p.LimbSize() == 32 {
+		limbSize = 32
+	}
+	xs, xe, xu := p.Xs(), p.Xe(), p.Xu()
+	return ParametersLiteral{
+		LogN:                p.LogN(),
+		Q:                   p.Q(),
+		P:                   p.P(),
+		H:                   p.HammingWeight(),
+		Sigma:               p.Sigma(),
+		LogSlots:            p.logSlots,
+		DefaultScale:        p.defaultScale,
+		RingType:            p.RingType(),
+		LimbSize:            limbSize,
+		GaussianSamplerType: p.GaussianSamplerType(),
+		Xs:                  &xs,
+		Xe:                  &xe,
+		Xu:                  &xu,
+	}
+}
+
 // MarshalJSON returns a JSON representation of this parameter set. See `Marshal` from the `encoding/json` package.
 func (p Parameters) MarshalJSON() ([]byte, error) {
-	return json.Marshal(ParametersLiteral{LogN: p.LogN(), Q: p.Q(), P: p.P(), H: p.HammingWeight(), Sigma: p.Sigma(), LogSlots: p.logSlots, DefaultScale: p.defaultScale, RingType: p.RingType()})
+	return json.Marshal(p.ParametersLiteral())
 }
 
 // UnmarshalJSON reads a JSON representation of a parameter set into the receiver Parameter. See `Unmarshal` from the `encoding/json` package.
@@ -0,0 +1,106 @@
+package ckks
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// Permutation is a type for arbitrary permutations of the slots of ciphertexts.
+// It stores the layers of an rlwe.PermutationNetwork as LinearTransforms whose
+// diagonals are the masks of the layers, and can be evaluated on a ciphertext
+// by using the evaluator.Permute method.
+// The evaluation consumes one level per layer and requires the rotation keys
+// for the galois elements returned by the GaloisElements method of the network.
+type Permutation struct {
+	LogSlots         int               // Log of the number of slots of the permutation
+	Level            int               // Level is the level at which the first layer is encoded
+	LinearTransforms []LinearTransform // LinearTransforms are the layers of the network, the i-th layer being encoded at level Level-i
+}
+
+// GenPermutation encodes the layers of the rlwe.PermutationNetwork into a new Permutation, starting at the given level.
+// Each layer is encoded with a scale equal to the modulus of its level, so that the evaluation of a Permutation
+// leaves the scale of the ciphertext unchanged.
+// The network must have been generated for a single row of slots, i.e. with len(permutation) <= params.Slots().
+func GenPermutation(encoder Encoder, network *rlwe.PermutationNetwork, level int) Permutation {
+
+	enc, ok := encoder.(*encoderComplex128)
+	if !ok {
+		panic("encoder should be an encoderComplex128")
+	}
+
+	params := enc.params
+
+	if network.Depth() > level {
+		panic(fmt.Sprintf("cannot GenPermutation: network depth %d is larger than the level %d", network.Depth(), level))
+	}
+
+	slots := network.Slots
+	logSlots := bits.Len64(uint64(slots)) - 1
+
+	permutation := Permutation{LogSlots: logSlots, Level: level, LinearTransforms: make([]LinearTransform, network.Depth())}
+
+	for i, layer := range network.Layers {
+
+		diagMatrix := make(map[int][]float64)
+
+		for galEl, mask := range layer {
+
+			k, rowRotation := params.RotationForGaloisElement(galEl)
+			if rowRotation {
+				panic("cannot GenPermutation: the network must not use the row rotation")
+			}
+
+			diag := make([]float64, slots)
+			for j := range mask {
+				if mask[j] {
+					diag[j] = 1
+				}
+			}
+
+			diagMatrix[k&(slots-1)] = diag
+		}
+
+		permutation.LinearTransforms[i] = GenLinearTransform(encoder, diagMatrix, level-i, float64(params.Q()[level-i]), logSlots)
+	}
+
+	return permutation
+}
+
+// PermuteNew applies the Permutation on ctIn and returns the result on a new Ciphertext.
+func (eval *evaluator) PermuteNew(ctIn *Ciphertext, permutation Permutation) (ctOut *Ciphertext) {
+	ctOut = NewCiphertext(eval.params, 1, utils.MinInt(ctIn.Level(), permutation.Level), ctIn.Scale)
+	eval.Permute(ctIn, permutation, ctOut)
+	return
+}
+
+// Permute applies the Permutation on ctIn and returns the result on ctOut.
+// Each layer of the Permutation consumes one level, ctIn must be at a level greater or equal to permutation.Level,
+// as well as ctOut, and the output ciphertext is at level permutation.Level - len(permutation.LinearTransforms) with the same scale as ctIn.
+func (eval *evaluator) Permute(ctIn *Ciphertext, permutation Permutation, ctOut *Ciphertext) {
+
+	if ctIn.Degree() != 1 || ctOut.Degree() != 1 {
+		panic("cannot Permute: input and output Ciphertext must be of degree 1")
+	}
+
+	if len(permutation.LinearTransforms) == 0 {
+		ctOut.Copy(ctIn)
+		return
+	}
+
+	scale := ctIn.Scale
+
+	ct := ctIn
+	for _, LT := range permutation.LinearTransforms {
+
+		eval.LinearTransform(ct, LT, []*Ciphertext{ctOut})
+
+		if err := eval.Rescale(ctOut, scale, ctOut); err != nil {
+			panic(err)
+		}
+
+		ct = ctOut
+	}
+}
@@ -53,6 +53,35 @@ func NewPoly(coeffs []complex128) (p *Polynomial) {
 	return &Polynomial{Coeffs: c, MaxDeg: len(c) - 1, Lead: true}
 }
 
+// EncryptedPolynomial is a struct storing the encrypted coefficients of a polynomial
+// that then can be evaluated on the ciphertext. Each coefficient is a Ciphertext
+// encrypting the value of the coefficient in each slot, and nil coefficients are
+// treated as zero.
+type EncryptedPolynomial struct {
+	MaxDeg int
+	Coeffs []*Ciphertext
+	Lead   bool
+	Basis  PolynomialBasis
+}
+
+// NewEncryptedPoly creates a new EncryptedPolynomial from the input encrypted coefficients.
+// The coefficients are not copied.
+func NewEncryptedPoly(coeffs []*Ciphertext) (p *EncryptedPolynomial) {
+	c := make([]*Ciphertext, len(coeffs))
+	copy(c, coeffs)
+	return &EncryptedPolynomial{Coeffs: c, MaxDeg: len(c) - 1, Lead: true}
+}
+
+// Depth returns the number of levels needed to evaluate the polynomial.
+func (p *EncryptedPolynomial) Depth() int {
+	return int(math.Ceil(math.Log2(float64(len(p.Coeffs)))))
+}
+
+// Degree returns the degree of the polynomial
+func (p *EncryptedPolynomial) Degree() int {
+	return len(p.Coeffs) - 1
+}
+
 // checkEnoughLevels checks that enough levels are available to evaluate the polynomial.
 // Also checks if c is a Gaussian integer or not. If not, then one more level is needed
 // to evaluate the polynomial.
@@ -96,6 +125,28 @@ type polynomialVector struct {
 	SlotsIndex map[int][]int
 }
 
+// polynomial is the interface shared by polynomialVector and *EncryptedPolynomial,
+// so that both can be evaluated by the same baby-step giant-step algorithm.
+type polynomial interface {
+	depth() int
+	degree() int
+	maxDeg() int
+	lead() bool
+	basis() PolynomialBasis
+}
+
+func (p polynomialVector) depth() int             { return p.Value[0].Depth() }
+func (p polynomialVector) degree() int            { return p.Value[0].Degree() }
+func (p polynomialVector) maxDeg() int            { return p.Value[0].MaxDeg }
+func (p polynomialVector) lead() bool             { return p.Value[0].Lead }
+func (p polynomialVector) basis() PolynomialBasis { return p.Value[0].Basis }
+
+func (p *EncryptedPolynomial) depth() int             { return p.Depth() }
+func (p *EncryptedPolynomial) degree() int            { return p.Degree() }
+func (p *EncryptedPolynomial) maxDeg() int            { return p.MaxDeg }
+func (p *EncryptedPolynomial) lead() bool             { return p.Lead }
+func (p *EncryptedPolynomial) basis() PolynomialBasis { return p.Basis }
+
 // EvaluatePolyVector evaluates a vector of Polyomials on the input Ciphertext in ceil(log2(deg+1)) levels.
 // Returns an error if the input ciphertext does not have enough level to carry out the full polynomial evaluation.
 // Returns an error if something is wrong with the scale.
@@ -134,13 +185,43 @@ func (eval *evaluator) EvaluatePolyVector(ct0 *Ciphertext, pols []*Polynomial, e
 	return eval.evaluatePolyVector(ct0, polynomialVector{Encoder: encoder, Value: pols, SlotsIndex: slotsIndex}, targetScale)
 }
 
+// EvaluateEncryptedPoly evaluates a polynomial whose coefficients are encrypted on the input Ciphertext
+// in ceil(log2(deg+1)) levels. Each coefficient encrypts the value of the coefficient in each slot, hence
+// a different polynomial can be evaluated on each slot, and nil coefficients are treated as zero.
+// Before being multiplied with the power basis, each coefficient is brought to the required scale, which
+// consumes one of its levels: the coefficients must therefore have at least one more level than the input
+// ciphertext, and should all have the same scale.
+// Returns an error if the input ciphertext or the coefficients do not have enough levels to carry out the full polynomial evaluation.
+// Returns an error if something is wrong with the scale.
+// If the polynomial is given in Chebyshev basis, then a change of basis ct' = (2/(b-a)) * (ct + (-a-b)/(b-a))
+// is necessary before the polynomial evaluation to ensure correctness.
+func (eval *evaluator) EvaluateEncryptedPoly(ct0 *Ciphertext, pol *EncryptedPolynomial, targetScale float64) (opOut *Ciphertext, err error) {
+
+	if pol.Degree() < 0 {
+		return nil, fmt.Errorf("cannot EvaluateEncryptedPoly: polynomial has no coefficient")
+	}
+
+	return eval.evaluatePolynomial(ct0, pol, nil, nil, false, false, targetScale)
+}
+
 func (eval *evaluator) evaluatePolyVector(ct0 *Ciphertext, pol polynomialVector, targetScale float64) (opOut *Ciphertext, err error) {
 
 	if pol.SlotsIndex != nil && pol.Encoder == nil {
 		return nil, fmt.Errorf("cannot EvaluatePolyVector, missing Encoder input")
 	}
 
-	if err := checkEnoughLevels(ct0.Level(), pol.Value[0].Depth(), 1); err != nil {
+	var odd, even bool
+	for _, p := range pol.Value {
+		tmp0, tmp1 := isOddOrEvenPolynomial(p.Coeffs)
+		odd, even = odd && tmp0, even && tmp1
+	}
+
+	return eval.evaluatePolynomial(ct0, pol, pol.Encoder, pol.SlotsIndex, odd, even, targetScale)
+}
+
+func (eval *evaluator) evaluatePolynomial(ct0 *Ciphertext, pol polynomial, encoder Encoder, slotsIndex map[int][]int, odd, even bool, targetScale float64) (opOut *Ciphertext, err error) {
+
+	if err := checkEnoughLevels(ct0.Level(), pol.depth(), 1); err != nil {
 		return ct0, err
 	}
 
@@ -148,38 +229,34 @@ func (eval *evaluator) evaluatePolyVector(ct0 *Ciphertext, pol polynomialVector,
 
 	poweBasis[1] = ct0.CopyNew()
 
-	logDegree := bits.Len64(uint64(pol.Value[0].Degree()))
+	logDegree := bits.Len64(uint64(pol.degree()))
 	logSplit := (logDegree >> 1) //optimalSplit(logDegree) //
 
-	var odd, even bool
-	for _, p := range pol.Value {
-		tmp0, tmp1 := isOddOrEvenPolynomial(p.Coeffs)
-		odd, even = odd && tmp0, even && tmp1
-	}
-
 	for i := 2; i < (1 << logSplit); i++ {
 		if !(even || odd) || (i&1 == 0 && even) || (i&1 == 1 && odd) {
-			if err = computePowerBasis(i, poweBasis, targetScale, pol.Value[0].Basis, eval); err != nil {
+			if err = computePowerBasis(i, poweBasis, targetScale, pol.basis(), eval); err != nil {
 				return nil, err
 			}
 		}
 	}
 
 	for i := logSplit; i < logDegree; i++ {
-		if err = computePowerBasis(1<<i, poweBasis, targetScale, pol.Value[0].Basis, eval); err != nil {
+		if err = computePowerBasis(1<<i, poweBasis, targetScale, pol.basis(), eval); err != nil {
 			return nil, err
 		}
 	}
 
 	polyEval := &polynomialEvaluator{}
-	polyEval.slotsIndex = pol.SlotsIndex
+	polyEval.slotsIndex = slotsIndex
 	polyEval.Evaluator = eval
-	polyEval.Encoder = pol.Encoder
+	polyEval.Encoder = encoder
 	polyEval.powerBasis = poweBasis
 	polyEval.logDegree = logDegree
 	polyEval.logSplit = logSplit
 
-	opOut, err = polyEval.recurse(targetScale, pol)
+	if opOut, err = polyEval.recurse(targetScale, pol); err != nil {
+		return nil, err
+	}
 
 	opOut.Scale = targetScale // solves float64 precision issues
 
@@ -294,16 +371,64 @@ func splitCoeffsPolyVector(poly polynomialVector, split int) (polyq, polyr polyn
 	return polynomialVector{Value: coeffsq}, polynomialVector{Value: coeffsr}
 }
 
-func (polyEval *polynomialEvaluator) recurse(targetScale float64, pol polynomialVector) (res *Ciphertext, err error) {
+// splitEncryptedCoeffs splits an encrypted polynomial p such that p = q*C^degree + r.
+// The coefficients are shared with p unless they have to be modified.
+func (polyEval *polynomialEvaluator) splitEncryptedCoeffs(coeffs *EncryptedPolynomial, split int) (coeffsq, coeffsr *EncryptedPolynomial) {
+
+	coeffsr = &EncryptedPolynomial{}
+	coeffsr.Coeffs = make([]*Ciphertext, split)
+	if coeffs.MaxDeg == coeffs.Degree() {
+		coeffsr.MaxDeg = split - 1
+	} else {
+		coeffsr.MaxDeg = coeffs.MaxDeg - (coeffs.Degree() - split + 1)
+	}
+
+	copy(coeffsr.Coeffs, coeffs.Coeffs[:split])
+
+	coeffsq = &EncryptedPolynomial{}
+	coeffsq.Coeffs = make([]*Ciphertext, coeffs.Degree()-split+1)
+	coeffsq.MaxDeg = coeffs.MaxDeg
+
+	coeffsq.Coeffs[0] = coeffs.Coeffs[split]
+
+	if coeffs.Basis == StandardBasis {
+		copy(coeffsq.Coeffs[1:], coeffs.Coeffs[split+1:])
+	} else if coeffs.Basis == ChebyshevBasis {
+		for i, j := split+1, 1; i < coeffs.Degree()+1; i, j = i+1, j+1 {
+
+			if coeffs.Coeffs[i] == nil {
+				continue
+			}
+
+			coeffsq.Coeffs[i-split] = polyEval.AddNew(coeffs.Coeffs[i], coeffs.Coeffs[i])
+
+			if coeffsr.Coeffs[split-j] == nil {
+				coeffsr.Coeffs[split-j] = polyEval.NegNew(coeffs.Coeffs[i])
+			} else {
+				coeffsr.Coeffs[split-j] = polyEval.SubNew(coeffsr.Coeffs[split-j], coeffs.Coeffs[i])
+			}
+		}
+	}
+
+	if coeffs.Lead {
+		coeffsq.Lead = true
+	}
+
+	coeffsq.Basis, coeffsr.Basis = coeffs.Basis, coeffs.Basis
+
+	return
+}
+
+func (polyEval *polynomialEvaluator) recurse(targetScale float64, pol polynomial) (res *Ciphertext, err error) {
 
 	logSplit := polyEval.logSplit
 
 	// Recursively computes the evaluation of the Chebyshev polynomial using a baby-set giant-step algorithm.
-	if pol.Value[0].Degree() < (1 << logSplit) {
+	if pol.degree() < (1 << logSplit) {
 
-		if pol.Value[0].Lead && polyEval.logSplit > 1 && pol.Value[0].MaxDeg%(1<<(logSplit+1)) > (1<<(logSplit-1)) {
+		if pol.lead() && polyEval.logSplit > 1 && pol.maxDeg()%(1<<(logSplit+1)) > (1<<(logSplit-1)) {
 
-			logDegree := int(bits.Len64(uint64(pol.Value[0].Degree())))
+			logDegree := int(bits.Len64(uint64(pol.degree())))
 			logSplit := logDegree >> 1
 
 			polyEvalBis := new(polynomialEvaluator)
@@ -316,21 +441,32 @@ func (polyEval *polynomialEvaluator) recurse(targetScale float64, pol polynomial
 			return polyEvalBis.recurse(targetScale, pol)
 		}
 
-		return polyEval.evaluatePolyFromPowerBasis(targetScale, pol)
+		switch pol := pol.(type) {
+		case *EncryptedPolynomial:
+			return polyEval.evaluateEncryptedPolyFromPowerBasis(targetScale, pol)
+		default:
+			return polyEval.evaluatePolyFromPowerBasis(targetScale, pol.(polynomialVector))
+		}
 	}
 
 	var nextPower = 1 << polyEval.logSplit
-	for nextPower < (pol.Value[0].Degree()>>1)+1 {
+	for nextPower < (pol.degree()>>1)+1 {
 		nextPower <<= 1
 	}
 
-	coeffsq, coeffsr := splitCoeffsPolyVector(pol, nextPower)
+	var coeffsq, coeffsr polynomial
+	switch pol := pol.(type) {
+	case *EncryptedPolynomial:
+		coeffsq, coeffsr = polyEval.splitEncryptedCoeffs(pol, nextPower)
+	default:
+		coeffsq, coeffsr = splitCoeffsPolyVector(pol.(polynomialVector), nextPower)
+	}
 
 	XPow := polyEval.powerBasis[nextPower]
 
 	level := XPow.Level() - 1
 
-	if coeffsq.Value[0].MaxDeg >= 1<<(polyEval.logDegree-1) && coeffsq.Value[0].Lead {
+	if coeffsq.maxDeg() >= 1<<(polyEval.logDegree-1) && coeffsq.lead() {
 		level++
 	}
 
@@ -562,6 +698,103 @@ func (polyEval *polynomialEvaluator) evaluatePolyFromPowerBasis(targetScale floa
 	return
 }
 
+func (polyEval *polynomialEvaluator) evaluateEncryptedPolyFromPowerBasis(targetScale float64, pol *EncryptedPolynomial) (res *Ciphertext, err error) {
+
+	X := polyEval.powerBasis
+
+	params := polyEval.Evaluator.(*evaluator).params
+
+	minimumDegreeNonZeroCoefficient := 0
+
+	// Get the minimum non-zero degree coefficient
+	for i := pol.Degree(); i > 0; i-- {
+		if pol.Coeffs[i] != nil {
+			minimumDegreeNonZeroCoefficient = i
+			break
+		}
+	}
+
+	c := pol.Coeffs[0]
+
+	if minimumDegreeNonZeroCoefficient == 0 {
+
+		if c == nil {
+			return NewCiphertext(params, 1, X[1].Level(), targetScale), nil
+		}
+
+		return polyEval.scaleEncryptedCoeff(c, X[1].Level(), targetScale)
+	}
+
+	level := X[minimumDegreeNonZeroCoefficient].Level()
+
+	// Target output scale before the rescaling, such that the rescaling does not change the ciphertext scale
+	ctScale := targetScale * params.QiFloat64(level)
+
+	// The products are accumulated in degree two and relinearized once
+	res = NewCiphertext(params, 2, level, ctScale)
+
+	var tmp *Ciphertext
+
+	if c != nil {
+		if tmp, err = polyEval.scaleEncryptedCoeff(c, level, ctScale); err != nil {
+			return nil, err
+		}
+		polyEval.Add(res, tmp, res)
+	}
+
+	for key := pol.Degree(); key > 0; key-- {
+		if c = pol.Coeffs[key]; c != nil {
+			if tmp, err = polyEval.scaleEncryptedCoeff(c, level, ctScale/X[key].Scale); err != nil {
+				return nil, err
+			}
+			polyEval.MulAndAdd(X[key], tmp, res)
+		}
+	}
+
+	res.Scale = ctScale // solves float64 precision issues
+
+	polyEval.Relinearize(res, res)
+
+	if err = polyEval.Rescale(res, targetScale, res); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// scaleEncryptedCoeff returns a copy of the encrypted coefficient ct at the given level and scale.
+// The copy is multiplied by round(scale * q / ct.Scale), where q is the modulus at level+1, and then
+// rescaled by q, so that one level of the coefficient is consumed.
+func (polyEval *polynomialEvaluator) scaleEncryptedCoeff(ct *Ciphertext, level int, scale float64) (res *Ciphertext, err error) {
+
+	if ct.Level() <= level {
+		return nil, fmt.Errorf("cannot EvaluateEncryptedPoly: coefficient level %d < %d", ct.Level(), level+1)
+	}
+
+	qi := polyEval.Evaluator.(*evaluator).params.QiFloat64(level + 1)
+
+	constant := new(big.Int)
+	new(big.Float).SetFloat64(math.Round(scale * qi / ct.Scale)).Int(constant)
+
+	if constant.Sign() == 0 {
+		return nil, fmt.Errorf("cannot EvaluateEncryptedPoly: coefficient scale %f is too large", ct.Scale)
+	}
+
+	res = polyEval.DropLevelNew(ct, ct.Level()-level-1)
+
+	polyEval.MultByGaussianInteger(res, constant, int64(0), res)
+
+	res.Scale = scale * qi
+
+	if err = polyEval.Rescale(res, scale, res); err != nil {
+		return nil, err
+	}
+
+	res.Scale = scale // solves float64 precision issues
+
+	return
+}
+
 func isNotNegligible(c complex128) bool {
 	return (math.Abs(real(c)) > IsNegligbleThreshold || math.Abs(imag(c)) > IsNegligbleThreshold)
 }
@@ -0,0 +1,73 @@
+package simulation
+
+import (
+	"math"
+
+	"github.com/tuneinsight/lattigo/v3/ckks"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+// decryptor is a ckks.Decryptor that copies the scaled slot values of the ciphertexts on the plaintexts.
+type decryptor struct {
+	*simulator
+}
+
+// NewDecryptor creates a new ckks.Decryptor for the simulation. The secret key is ignored and can be nil.
+func NewDecryptor(params ckks.Parameters, sk *rlwe.SecretKey) ckks.Decryptor {
+	return &decryptor{simulator: newSimulator(params, false)}
+}
+
+// Decrypt decrypts the ciphertext and writes the result on plaintext. Values whose magnitude exceeds half
+// of the modulus at the level of the ciphertext are reduced modulo this modulus, which simulates the
+// wrap-around of an overflowing plaintext.
+func (dec *decryptor) Decrypt(ciphertext *ckks.Ciphertext, plaintext *ckks.Plaintext) {
+
+	level := ciphertext.Level()
+	if plaintext.Level() < level {
+		level = plaintext.Level()
+	}
+
+	var logQ float64
+	for _, qi := range dec.params.Q()[:level+1] {
+		logQ += math.Log2(float64(qi))
+	}
+
+	values := dec.getValues(ciphertext.Value[0])
+
+	// float64 cannot represent values larger than 2^1024
+	if logQ < 1023 {
+		Q := math.Exp2(logQ)
+		for i := range values {
+			values[i] = complex(centeredMod(real(values[i]), Q), centeredMod(imag(values[i]), Q))
+		}
+	}
+
+	plaintext.Value.Coeffs = plaintext.Value.Coeffs[:level+1]
+	plaintext.Scale = ciphertext.Scale
+	dec.setValues(plaintext.Value, values)
+}
+
+// DecryptNew decrypts the ciphertext and returns the result on a new plaintext.
+func (dec *decryptor) DecryptNew(ciphertext *ckks.Ciphertext) (plaintext *ckks.Plaintext) {
+	plaintext = dec.newPlaintext(ciphertext.Level(), ciphertext.Scale)
+	dec.Decrypt(ciphertext, plaintext)
+	return
+}
+
+// ShallowCopy creates a shallow copy of the decryptor that can be used concurrently with the receiver.
+func (dec *decryptor) ShallowCopy() ckks.Decryptor {
+	return &decryptor{simulator: dec.shallowCopy()}
+}
+
+// WithKey creates a shallow copy of the decryptor with a new key.
+func (dec *decryptor) WithKey(sk *rlwe.SecretKey) ckks.Decryptor {
+	return dec.ShallowCopy()
+}
+
+// centeredMod returns x mod Q in [-Q/2, Q/2).
+func centeredMod(x, Q float64) float64 {
+	if math.Abs(x) < Q/2 {
+		return x
+	}
+	return x - Q*math.Floor(x/Q+0.5)
+}
@@ -0,0 +1,155 @@
+package simulation
+
+import (
+	"fmt"
+
+	"github.com/tuneinsight/lattigo/v3/ckks"
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+// encoder is a ckks.Encoder that stores the scaled slot values on the plaintexts.
+type encoder struct {
+	*simulator
+	ecd ckks.Encoder // lazily allocated, only used by GetErrSTDCoeffDomain
+}
+
+// NewEncoder creates a new ckks.Encoder for the simulation. If noise is true, the error introduced by the
+// rounding of the encoding is simulated.
+func NewEncoder(params ckks.Parameters, noise bool) ckks.Encoder {
+	return &encoder{simulator: newSimulator(params, noise)}
+}
+
+func (ecd *encoder) checkLogSlots(logSlots int) {
+	if logSlots < 0 || logSlots > ecd.params.MaxLogSlots() {
+		panic(fmt.Sprintf("cannot Encode: logSlots (%d) must be greater or equal to 0 and smaller than %d", logSlots, ecd.params.MaxLogSlots()))
+	}
+}
+
+// Encode encodes a set of values on the target plaintext, at the level and scale of the plaintext.
+func (ecd *encoder) Encode(values interface{}, plaintext *ckks.Plaintext, logSlots int) {
+	ecd.Embed(values, logSlots, plaintext.Scale, false, plaintext.Value)
+}
+
+// EncodeNew encodes a set of values on a new plaintext at the given level and scale.
+func (ecd *encoder) EncodeNew(values interface{}, level int, scale float64, logSlots int) (plaintext *ckks.Plaintext) {
+	plaintext = ecd.newPlaintext(level, scale)
+	ecd.Encode(values, plaintext, logSlots)
+	return
+}
+
+// EncodeSlots encodes a set of values on the target plaintext, at the level and scale of the plaintext.
+func (ecd *encoder) EncodeSlots(values interface{}, plaintext *ckks.Plaintext, logSlots int) {
+	ecd.Encode(values, plaintext, logSlots)
+}
+
+// EncodeSlotsNew encodes a set of values on a new plaintext at the given level and scale.
+func (ecd *encoder) EncodeSlotsNew(values interface{}, level int, scale float64, logSlots int) (plaintext *ckks.Plaintext) {
+	return ecd.EncodeNew(values, level, scale, logSlots)
+}
+
+// Decode decodes the input plaintext on a new slice of complex128.
+func (ecd *encoder) Decode(plaintext *ckks.Plaintext, logSlots int) (res []complex128) {
+	return ecd.DecodeSlotsPublic(plaintext, logSlots, 0)
+}
+
+// DecodeSlots decodes the input plaintext on a new slice of complex128.
+func (ecd *encoder) DecodeSlots(plaintext *ckks.Plaintext, logSlots int) (res []complex128) {
+	return ecd.DecodeSlotsPublic(plaintext, logSlots, 0)
+}
+
+// DecodePublic decodes the input plaintext on a new slice of complex128, adding before the
+// decoding an error with standard deviation sigma in the coefficient domain.
+func (ecd *encoder) DecodePublic(plaintext *ckks.Plaintext, logSlots int, sigma float64) (res []complex128) {
+	return ecd.DecodeSlotsPublic(plaintext, logSlots, sigma)
+}
+
+// DecodeSlotsPublic decodes the input plaintext on a new slice of complex128, adding before the
+// decoding an error with standard deviation sigma in the coefficient domain.
+func (ecd *encoder) DecodeSlotsPublic(plaintext *ckks.Plaintext, logSlots int, sigma float64) (res []complex128) {
+
+	ecd.checkLogSlots(logSlots)
+
+	values := ecd.getValues(plaintext.Value)
+
+	if sigma != 0 {
+		ecd.addGaussian(values, sigma*sigma)
+	}
+
+	res = make([]complex128, 1<<logSlots)
+	for i := range res {
+		res[i] = values[i] / complex(plaintext.Scale, 0)
+	}
+
+	return
+}
+
+// EncodeCoeffs is not supported by the simulation.
+func (ecd *encoder) EncodeCoeffs(values []float64, plaintext *ckks.Plaintext) {
+	panic("cannot EncodeCoeffs: coefficient encoding is not supported by the simulation")
+}
+
+// EncodeCoeffsNew is not supported by the simulation.
+func (ecd *encoder) EncodeCoeffsNew(values []float64, level int, scale float64) (plaintext *ckks.Plaintext) {
+	panic("cannot EncodeCoeffsNew: coefficient encoding is not supported by the simulation")
+}
+
+// DecodeCoeffs is not supported by the simulation.
+func (ecd *encoder) DecodeCoeffs(plaintext *ckks.Plaintext) (res []float64) {
+	panic("cannot DecodeCoeffs: coefficient encoding is not supported by the simulation")
+}
+
+// DecodeCoeffsPublic is not supported by the simulation.
+func (ecd *encoder) DecodeCoeffsPublic(plaintext *ckks.Plaintext, bound float64) (res []float64) {
+	panic("cannot DecodeCoeffsPublic: coefficient encoding is not supported by the simulation")
+}
+
+// Embed encodes a set of values on the target polyOut, which can be either a rlwe.PolyQP or a *ring.Poly.
+// The montgomery flag is ignored.
+func (ecd *encoder) Embed(values interface{}, logSlots int, scale float64, montgomery bool, polyOut interface{}) {
+
+	ecd.checkLogSlots(logSlots)
+
+	var pol *ring.Poly
+	switch p := polyOut.(type) {
+	case rlwe.PolyQP:
+		pol = p.Q
+	case *ring.Poly:
+		pol = p
+	default:
+		panic("cannot Embed: invalid polyOut.(Type) must be rlwe.PolyQP or *ring.Poly")
+	}
+
+	res := ecd.replicate(toComplex128(values), logSlots)
+	for i := range res {
+		res[i] *= complex(scale, 0)
+	}
+
+	ecd.addNoise(res, 1.0/12)
+
+	ecd.setValues(pol, res)
+}
+
+// GetErrSTDCoeffDomain returns StandardDeviation(Encode(valuesWant-valuesHave))*scale.
+func (ecd *encoder) GetErrSTDCoeffDomain(valuesWant, valuesHave []complex128, scale float64) (std float64) {
+	if ecd.ecd == nil {
+		ecd.ecd = ckks.NewEncoder(ecd.params)
+	}
+	return ecd.ecd.GetErrSTDCoeffDomain(valuesWant, valuesHave, scale)
+}
+
+// GetErrSTDSlotDomain returns StandardDeviation(valuesWant-valuesHave)*scale.
+func (ecd *encoder) GetErrSTDSlotDomain(valuesWant, valuesHave []complex128, scale float64) (std float64) {
+	valuesFloat := make([]float64, 2*len(valuesWant))
+	for i := range valuesWant {
+		err := valuesWant[i] - valuesHave[i]
+		valuesFloat[2*i] = real(err)
+		valuesFloat[2*i+1] = imag(err)
+	}
+	return ckks.StandardDeviation(valuesFloat, scale)
+}
+
+// ShallowCopy creates a shallow copy of the encoder that can be used concurrently with the receiver.
+func (ecd *encoder) ShallowCopy() ckks.Encoder {
+	return &encoder{simulator: ecd.shallowCopy()}
+}
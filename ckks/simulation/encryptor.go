@@ -0,0 +1,100 @@
+package simulation
+
+import (
+	"github.com/tuneinsight/lattigo/v3/ckks"
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// encryptor is a ckks.Encryptor that copies the scaled slot values of the plaintexts on the ciphertexts.
+type encryptor struct {
+	*simulator
+	key interface{}
+}
+
+// NewEncryptor creates a new ckks.Encryptor for the simulation. The key argument can be *rlwe.PublicKey,
+// *rlwe.SecretKey or nil, and only its type is used: it determines the simulated encryption error if noise
+// is true. The keys can be allocated with rlwe.NewSecretKey and rlwe.NewPublicKey.
+func NewEncryptor(params ckks.Parameters, key interface{}, noise bool) ckks.Encryptor {
+	enc := &encryptor{simulator: newSimulator(params, noise)}
+	enc.setKey(key)
+	return enc
+}
+
+func (enc *encryptor) setKey(key interface{}) {
+	switch key.(type) {
+	case *rlwe.PublicKey, *rlwe.SecretKey, nil:
+		enc.key = key
+	default:
+		panic("key must be either *rlwe.PublicKey, *rlwe.SecretKey or nil")
+	}
+}
+
+func (enc *encryptor) encrypt(plaintext *ckks.Plaintext, ciphertext *ckks.Ciphertext, publicKey bool) {
+
+	level := plaintext.Level()
+	if ciphertext.Level() < level {
+		level = ciphertext.Level()
+	}
+
+	values := enc.getValues(plaintext.Value)
+
+	enc.addNoise(values, enc.freshNoiseVariance(publicKey))
+
+	enc.setOutput(ciphertext, 1, level, plaintext.Scale, values)
+}
+
+// Encrypt encrypts the input plaintext and writes the result on ciphertext.
+func (enc *encryptor) Encrypt(plaintext *ckks.Plaintext, ciphertext *ckks.Ciphertext) {
+
+	switch enc.key.(type) {
+	case *rlwe.PublicKey:
+		enc.encrypt(plaintext, ciphertext, true)
+	case *rlwe.SecretKey:
+		enc.encrypt(plaintext, ciphertext, false)
+	default:
+		panic("cannot encrypt: Encryptor has no key")
+	}
+}
+
+// EncryptNew encrypts the input plaintext and returns the result on a new ciphertext.
+func (enc *encryptor) EncryptNew(plaintext *ckks.Plaintext) (ciphertext *ckks.Ciphertext) {
+	ciphertext = enc.newCiphertext(1, plaintext.Level(), plaintext.Scale)
+	enc.Encrypt(plaintext, ciphertext)
+	return
+}
+
+// EncryptFromCRP encrypts the input plaintext and writes the result on ciphertext. The crp is ignored.
+func (enc *encryptor) EncryptFromCRP(plaintext *ckks.Plaintext, crp *ring.Poly, ciphertext *ckks.Ciphertext) {
+
+	if _, isSk := enc.key.(*rlwe.SecretKey); !isSk {
+		panic("cannot EncryptFromCRP: Encryptor has no secret key")
+	}
+
+	enc.encrypt(plaintext, ciphertext, false)
+}
+
+// EncryptFromCRPNew encrypts the input plaintext and returns the result on a new ciphertext. The crp is ignored.
+func (enc *encryptor) EncryptFromCRPNew(plaintext *ckks.Plaintext, crp *ring.Poly) (ciphertext *ckks.Ciphertext) {
+	ciphertext = enc.newCiphertext(1, plaintext.Level(), plaintext.Scale)
+	enc.EncryptFromCRP(plaintext, crp, ciphertext)
+	return
+}
+
+// ShallowCopy creates a shallow copy of the encryptor that can be used concurrently with the receiver.
+func (enc *encryptor) ShallowCopy() ckks.Encryptor {
+	return &encryptor{simulator: enc.shallowCopy(), key: enc.key}
+}
+
+// WithPRNG creates a shallow copy of the encryptor whose simulated encryption errors are drawn from a source seeded from prng.
+func (enc *encryptor) WithPRNG(prng utils.PRNG) ckks.Encryptor {
+	return &encryptor{simulator: newSimulatorWithPRNG(enc.params, enc.noise, prng), key: enc.key}
+}
+
+// WithKey creates a shallow copy of the encryptor with a new key.
+func (enc *encryptor) WithKey(key interface{}) ckks.Encryptor {
+	encCopy := &encryptor{simulator: enc.shallowCopy()}
+	encCopy.setKey(key)
+	return encCopy
+}
@@ -0,0 +1,1269 @@
+package simulation
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+
+	"github.com/tuneinsight/lattigo/v3/ckks"
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// evaluator is a ckks.Evaluator operating on the scaled slot values stored on the plaintexts and ciphertexts.
+// It follows the same rules as the ckks.Evaluator for the levels, the scales and the degrees of the operands,
+// and panics if the relinearization or rotation keys required by an operation are not available.
+type evaluator struct {
+	*simulator
+	rlk  *rlwe.RelinearizationKey
+	rtks *rlwe.RotationKeySet
+}
+
+// NewEvaluator creates a new ckks.Evaluator for the simulation. The evaluation key is only used to check the
+// availability of the relinearization and rotation keys, which can be placeholders created with NewEvaluationKey.
+// If noise is true, the errors introduced by the rescaling and the key-switching are simulated.
+func NewEvaluator(params ckks.Parameters, evaluationKey rlwe.EvaluationKey, noise bool) ckks.Evaluator {
+	return &evaluator{simulator: newSimulator(params, noise), rlk: evaluationKey.Rlk, rtks: evaluationKey.Rtks}
+}
+
+// NewEvaluationKey creates a new rlwe.EvaluationKey storing empty placeholder keys for the relinearization and
+// for the given rotations (and the conjugation if includeConjugate is true), to be given to NewEvaluator.
+func NewEvaluationKey(params ckks.Parameters, rotations []int, includeConjugate bool) rlwe.EvaluationKey {
+
+	rtks := &rlwe.RotationKeySet{Keys: make(map[uint64]*rlwe.SwitchingKey)}
+
+	for _, k := range rotations {
+		rtks.Keys[params.GaloisElementForColumnRotationBy(k)] = &rlwe.SwitchingKey{}
+	}
+
+	if includeConjugate {
+		rtks.Keys[params.GaloisElementForRowRotation()] = &rlwe.SwitchingKey{}
+	}
+
+	return rlwe.EvaluationKey{Rlk: &rlwe.RelinearizationKey{Keys: []*rlwe.SwitchingKey{{}}}, Rtks: rtks}
+}
+
+// ===========================
+// === Helpers and checks ====
+// ===========================
+
+func (eval *evaluator) checkBinary(op0, op1 ckks.Operand, ctOut *ckks.Ciphertext) {
+
+	if op0 == nil || op1 == nil || ctOut == nil {
+		panic("operands cannot be nil")
+	}
+
+	if op0.Degree()+op1.Degree() == 0 {
+		panic("operands cannot be both plaintext")
+	}
+}
+
+// values returns the scaled slot values of the operand.
+func (eval *evaluator) values(op ckks.Operand) []complex128 {
+	return eval.getValues(op.El().Value[0])
+}
+
+func (eval *evaluator) checkRotationKey(galEl uint64) {
+	if eval.rtks == nil {
+		panic(fmt.Sprintf("rotation key k=%d not available", eval.params.InverseGaloisElement(galEl)))
+	}
+	if _, generated := eval.rtks.GetRotationKey(galEl); !generated {
+		panic(fmt.Sprintf("rotation key k=%d not available", eval.params.InverseGaloisElement(galEl)))
+	}
+}
+
+func (eval *evaluator) checkRotations(rotations []int) {
+	for _, k := range rotations {
+		if k&(eval.params.MaxSlots()-1) != 0 {
+			eval.checkRotationKey(eval.params.GaloisElementForColumnRotationBy(k))
+		}
+	}
+}
+
+func (eval *evaluator) checkRelinearizationKey() {
+	if eval.rlk == nil || len(eval.rlk.Keys) == 0 {
+		panic("evaluator has no relinearization key")
+	}
+}
+
+// keySwitch adds to the values the error of a key-switching.
+func (eval *evaluator) keySwitch(values []complex128) {
+	eval.addNoise(values, eval.roundingNoiseVariance())
+}
+
+func minInt(values ...int) (m int) {
+	m = values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return
+}
+
+// getConstAndScale converts the constant to float64 and determines the scale by which it is multiplied before
+// being converted to an integer, which is the modulus at the given level if the constant is not a Gaussian integer.
+func (eval *evaluator) getConstAndScale(level int, constant interface{}) (cReal, cImag, scale float64) {
+
+	scale = 1
+
+	switch constant := constant.(type) {
+	case complex128:
+		cReal, cImag = real(constant), imag(constant)
+	case float64:
+		cReal = constant
+	case uint64:
+		cReal = float64(constant)
+	case int64:
+		cReal = float64(constant)
+	case int:
+		cReal = float64(constant)
+	}
+
+	if cReal != float64(int64(cReal)) || cImag != float64(int64(cImag)) {
+		scale = eval.params.QiFloat64(level)
+	}
+
+	if eval.params.RingType() == ring.ConjugateInvariant {
+		cImag = 0
+	}
+
+	return
+}
+
+// scaledConst returns the constant multiplied by scale and rounded, as done by the ckks.Evaluator.
+func scaledConst(cReal, cImag, scale float64) complex128 {
+	return complex(math.Round(cReal*scale), math.Round(cImag*scale))
+}
+
+func interfaceToFloat64(x interface{}) float64 {
+	switch x := x.(type) {
+	case uint64:
+		return float64(x)
+	case int64:
+		return float64(x)
+	case int:
+		return float64(x)
+	case *big.Int:
+		f, _ := new(big.Float).SetInt(x).Float64()
+		return f
+	default:
+		panic("constant must either be uint64, int64 or *big.Int")
+	}
+}
+
+func mulScalar(values []complex128, c complex128) {
+	for i := range values {
+		values[i] *= c
+	}
+}
+
+// ===========================
+// === Basic Arithmetic ======
+// ===========================
+
+// Add adds op0 to op1 and returns the result in ctOut.
+func (eval *evaluator) Add(op0, op1 ckks.Operand, ctOut *ckks.Ciphertext) {
+	eval.evaluate(op0, op1, ctOut, func(a, b complex128) complex128 { return a + b })
+}
+
+// AddNoMod adds op0 to op1 and returns the result in ctOut.
+func (eval *evaluator) AddNoMod(op0, op1 ckks.Operand, ctOut *ckks.Ciphertext) {
+	eval.Add(op0, op1, ctOut)
+}
+
+// AddNew adds op0 to op1 and returns the result in a newly created element.
+func (eval *evaluator) AddNew(op0, op1 ckks.Operand) (ctOut *ckks.Ciphertext) {
+	ctOut = eval.newCiphertextBinary(op0, op1)
+	eval.Add(op0, op1, ctOut)
+	return
+}
+
+// AddNoModNew adds op0 to op1 and returns the result in a newly created element.
+func (eval *evaluator) AddNoModNew(op0, op1 ckks.Operand) (ctOut *ckks.Ciphertext) {
+	return eval.AddNew(op0, op1)
+}
+
+// Sub subtracts op1 from op0 and returns the result in ctOut.
+func (eval *evaluator) Sub(op0, op1 ckks.Operand, ctOut *ckks.Ciphertext) {
+	eval.evaluate(op0, op1, ctOut, func(a, b complex128) complex128 { return a - b })
+}
+
+// SubNoMod subtracts op1 from op0 and returns the result in ctOut.
+func (eval *evaluator) SubNoMod(op0, op1 ckks.Operand, ctOut *ckks.Ciphertext) {
+	eval.Sub(op0, op1, ctOut)
+}
+
+// SubNew subtracts op1 from op0 and returns the result in a newly created element.
+func (eval *evaluator) SubNew(op0, op1 ckks.Operand) (ctOut *ckks.Ciphertext) {
+	ctOut = eval.newCiphertextBinary(op0, op1)
+	eval.Sub(op0, op1, ctOut)
+	return
+}
+
+// SubNoModNew subtracts op1 from op0 and returns the result in a newly created element.
+func (eval *evaluator) SubNoModNew(op0, op1 ckks.Operand) (ctOut *ckks.Ciphertext) {
+	return eval.SubNew(op0, op1)
+}
+
+func (eval *evaluator) newCiphertextBinary(op0, op1 ckks.Operand) *ckks.Ciphertext {
+	return eval.newCiphertext(utils.MaxInt(op0.Degree(), op1.Degree()), utils.MinInt(op0.Level(), op1.Level()), utils.MaxFloat64(op0.ScalingFactor(), op1.ScalingFactor()))
+}
+
+// evaluate applies f on the values of op0 and op1. As done by the ckks.Evaluator, if the scales of the operands
+// differ, the operand with the smallest scale is first multiplied by the floor of the ratio between the scales.
+func (eval *evaluator) evaluate(op0, op1 ckks.Operand, ctOut *ckks.Ciphertext, f func(a, b complex128) complex128) {
+
+	eval.checkBinary(op0, op1, ctOut)
+
+	level := minInt(op0.Level(), op1.Level(), ctOut.Level())
+
+	v0, v1 := eval.values(op0), eval.values(op1)
+
+	s0, s1 := op0.ScalingFactor(), op1.ScalingFactor()
+
+	if s0 > s1 {
+		if r := math.Floor(s0 / s1); r > 1 {
+			mulScalar(v1, complex(r, 0))
+		}
+	} else if s1 > s0 {
+		if r := math.Floor(s1 / s0); r > 1 {
+			mulScalar(v0, complex(r, 0))
+		}
+	}
+
+	for i := range v0 {
+		v0[i] = f(v0[i], v1[i])
+	}
+
+	eval.setOutput(ctOut, utils.MaxInt(op0.Degree(), op1.Degree()), level, utils.MaxFloat64(s0, s1), v0)
+}
+
+// unary applies f on the values of ct0 and writes the result on ctOut with the given scale.
+func (eval *evaluator) unary(ct0, ctOut *ckks.Ciphertext, scale float64, f func(a complex128) complex128) {
+	v := eval.values(ct0)
+	for i := range v {
+		v[i] = f(v[i])
+	}
+	eval.setOutput(ctOut, ct0.Degree(), minInt(ct0.Level(), ctOut.Level()), scale, v)
+}
+
+// Neg negates the value of ct0 and returns the result in ctOut.
+func (eval *evaluator) Neg(ct0 *ckks.Ciphertext, ctOut *ckks.Ciphertext) {
+	eval.unary(ct0, ctOut, ct0.Scale, func(a complex128) complex128 { return -a })
+}
+
+// NegNew negates ct0 and returns the result in a newly created element.
+func (eval *evaluator) NegNew(ct0 *ckks.Ciphertext) (ctOut *ckks.Ciphertext) {
+	ctOut = eval.newCiphertext(ct0.Degree(), ct0.Level(), ct0.Scale)
+	eval.Neg(ct0, ctOut)
+	return
+}
+
+// AddConstNew adds the input constant (which can be a uint64, int64, float64 or complex128) to ct0 and returns the result in a new element.
+func (eval *evaluator) AddConstNew(ct0 *ckks.Ciphertext, constant interface{}) (ctOut *ckks.Ciphertext) {
+	ctOut = ct0.CopyNew()
+	eval.AddConst(ct0, constant, ctOut)
+	return
+}
+
+// AddConst adds the input constant (which can be a uint64, int64, float64 or complex128) to ct0 and returns the result in ctOut.
+func (eval *evaluator) AddConst(ct0 *ckks.Ciphertext, constant interface{}, ctOut *ckks.Ciphertext) {
+	cReal, cImag, _ := eval.getConstAndScale(minInt(ct0.Level(), ctOut.Level()), constant)
+	c := scaledConst(cReal, cImag, ct0.Scale)
+	eval.unary(ct0, ctOut, ct0.Scale, func(a complex128) complex128 { return a + c })
+}
+
+// MultByConstNew multiplies ct0 by the input constant and returns the result in a newly created element.
+func (eval *evaluator) MultByConstNew(ct0 *ckks.Ciphertext, constant interface{}) (ctOut *ckks.Ciphertext) {
+	ctOut = eval.newCiphertext(ct0.Degree(), ct0.Level(), ct0.Scale)
+	eval.MultByConst(ct0, constant, ctOut)
+	return
+}
+
+// MultByConst multiplies ct0 by the input constant and returns the result in ctOut. If the constant is not a Gaussian
+// integer, it is scaled by the modulus at the level of ct0, which multiplies the scale of ctOut by this modulus.
+func (eval *evaluator) MultByConst(ct0 *ckks.Ciphertext, constant interface{}, ctOut *ckks.Ciphertext) {
+	cReal, cImag, scale := eval.getConstAndScale(minInt(ct0.Level(), ctOut.Level()), constant)
+	c := scaledConst(cReal, cImag, scale)
+	eval.unary(ct0, ctOut, ct0.Scale*scale, func(a complex128) complex128 { return a * c })
+}
+
+// MultByGaussianInteger multiples the ct0 by the gaussian integer cReal + i*cImag and returns the result on ctOut.
+// Accepted types for cReal and cImag are uint64, int64 and big.Int.
+func (eval *evaluator) MultByGaussianInteger(ct0 *ckks.Ciphertext, cReal, cImag interface{}, ctOut *ckks.Ciphertext) {
+	c := eval.gaussianInteger(cReal, cImag)
+	eval.unary(ct0, ctOut, ct0.Scale, func(a complex128) complex128 { return a * c })
+}
+
+func (eval *evaluator) gaussianInteger(cReal, cImag interface{}) complex128 {
+	if eval.params.RingType() == ring.ConjugateInvariant {
+		return complex(interfaceToFloat64(cReal), 0)
+	}
+	return complex(interfaceToFloat64(cReal), interfaceToFloat64(cImag))
+}
+
+// MultByConstAndAdd multiplies ct0 by the input constant, and adds it to ctOut. The level of ctOut is set to
+// min(ct0.Level(), ctOut.Level()) and its scale is managed as done by the ckks.Evaluator.
+func (eval *evaluator) MultByConstAndAdd(ct0 *ckks.Ciphertext, constant interface{}, ctOut *ckks.Ciphertext) {
+
+	level := minInt(ct0.Level(), ctOut.Level())
+
+	setLevel(ctOut, level)
+
+	cReal, cImag, scale := eval.getConstAndScale(level, constant)
+
+	if scale != 1 {
+		if ctOut.Scale < ct0.Scale*scale {
+			if scale := math.Floor((scale * ct0.Scale) / ctOut.Scale); scale > 1 {
+				eval.MultByConst(ctOut, scale, ctOut)
+			}
+			ctOut.Scale = scale * ct0.Scale
+		} else if ctOut.Scale > ct0.Scale*scale {
+			scale = ctOut.Scale / ct0.Scale
+		}
+	} else {
+		if ctOut.Scale > ct0.Scale {
+			scale = ctOut.Scale / ct0.Scale
+		} else if ct0.Scale > ctOut.Scale {
+			if scale := math.Floor(ct0.Scale / ctOut.Scale); scale > 1 {
+				eval.MultByConst(ctOut, scale, ctOut)
+			}
+			ctOut.Scale = ct0.Scale
+		}
+	}
+
+	eval.mulAndAdd(ct0, scaledConst(cReal, cImag, scale), ctOut)
+}
+
+// MultByGaussianIntegerAndAdd multiples the ct0 by the gaussian integer cReal + i*cImag and adds the result on ctOut.
+// Accepted types for cReal and cImag are uint64, int64 and big.Int.
+func (eval *evaluator) MultByGaussianIntegerAndAdd(ct0 *ckks.Ciphertext, cReal, cImag interface{}, ctOut *ckks.Ciphertext) {
+	setLevel(ctOut, minInt(ct0.Level(), ctOut.Level()))
+	eval.mulAndAdd(ct0, eval.gaussianInteger(cReal, cImag), ctOut)
+}
+
+// mulAndAdd adds ct0 * c to ctOut without changing the scale of ctOut.
+func (eval *evaluator) mulAndAdd(ct0 *ckks.Ciphertext, c complex128, ctOut *ckks.Ciphertext) {
+	v0, vOut := eval.values(ct0), eval.values(ctOut)
+	for i := range vOut {
+		vOut[i] += v0[i] * c
+	}
+	eval.setOutput(ctOut, utils.MaxInt(ct0.Degree(), ctOut.Degree()), ctOut.Level(), ctOut.Scale, vOut)
+}
+
+// MultByiNew multiplies ct0 by the imaginary number i, and returns the result in a newly created element.
+func (eval *evaluator) MultByiNew(ct0 *ckks.Ciphertext) (ctOut *ckks.Ciphertext) {
+	ctOut = eval.newCiphertext(1, ct0.Level(), ct0.Scale)
+	eval.MultByi(ct0, ctOut)
+	return
+}
+
+// MultByi multiplies ct0 by the imaginary number i, and returns the result in ctOut.
+func (eval *evaluator) MultByi(ct0 *ckks.Ciphertext, ctOut *ckks.Ciphertext) {
+	if eval.params.RingType() == ring.ConjugateInvariant {
+		panic("method MultByi is not supported when params.RingType() == ring.ConjugateInvariant")
+	}
+	eval.unary(ct0, ctOut, ct0.Scale, func(a complex128) complex128 { return a * 1i })
+}
+
+// DivByiNew multiplies ct0 by the imaginary number 1/i = -i, and returns the result in a newly created element.
+func (eval *evaluator) DivByiNew(ct0 *ckks.Ciphertext) (ctOut *ckks.Ciphertext) {
+	ctOut = eval.newCiphertext(1, ct0.Level(), ct0.Scale)
+	eval.DivByi(ct0, ctOut)
+	return
+}
+
+// DivByi multiplies ct0 by the imaginary number 1/i = -i, and returns the result in ctOut.
+func (eval *evaluator) DivByi(ct0 *ckks.Ciphertext, ctOut *ckks.Ciphertext) {
+	if eval.params.RingType() == ring.ConjugateInvariant {
+		panic("method DivByi is not supported when params.RingType() == ring.ConjugateInvariant")
+	}
+	eval.unary(ct0, ctOut, ct0.Scale, func(a complex128) complex128 { return a * -1i })
+}
+
+// ScaleUpNew multiplies ct0 by scale and sets its scale to its previous scale times scale.
+func (eval *evaluator) ScaleUpNew(ct0 *ckks.Ciphertext, scale float64) (ctOut *ckks.Ciphertext) {
+	ctOut = eval.newCiphertext(ct0.Degree(), ct0.Level(), ct0.Scale)
+	eval.ScaleUp(ct0, scale, ctOut)
+	return
+}
+
+// ScaleUp multiplies ct0 by scale and sets its scale to its previous scale times scale.
+func (eval *evaluator) ScaleUp(ct0 *ckks.Ciphertext, scale float64, ctOut *ckks.Ciphertext) {
+	eval.MultByConst(ct0, uint64(scale), ctOut)
+	ctOut.Scale = ct0.Scale * scale
+}
+
+// SetScale sets the scale of the ciphertext to the input scale (consumes a level).
+func (eval *evaluator) SetScale(ct *ckks.Ciphertext, scale float64) {
+	eval.MultByConst(ct, scale/ct.Scale, ct)
+	if err := eval.Rescale(ct, scale, ct); err != nil {
+		panic(err)
+	}
+	ct.Scale = scale
+}
+
+// MulByPow2New multiplies ct0 by 2^pow2 and returns the result in a newly created element.
+func (eval *evaluator) MulByPow2New(ct0 *ckks.Ciphertext, pow2 int) (ctOut *ckks.Ciphertext) {
+	ctOut = eval.newCiphertext(ct0.Degree(), ct0.Level(), ct0.Scale)
+	eval.MulByPow2(ct0, pow2, ctOut)
+	return
+}
+
+// MulByPow2 multiplies ct0 by 2^pow2 and returns the result in ctOut.
+func (eval *evaluator) MulByPow2(ct0 *ckks.Ciphertext, pow2 int, ctOut *ckks.Ciphertext) {
+	c := complex(math.Exp2(float64(pow2)), 0)
+	eval.unary(ct0, ctOut, ct0.Scale, func(a complex128) complex128 { return a * c })
+}
+
+// ReduceNew returns a copy of ct0 in a newly created element.
+func (eval *evaluator) ReduceNew(ct0 *ckks.Ciphertext) (ctOut *ckks.Ciphertext) {
+	ctOut = eval.newCiphertext(ct0.Degree(), ct0.Level(), ct0.Scale)
+	_ = eval.Reduce(ct0, ctOut)
+	return
+}
+
+// Reduce copies ct0 on ctOut.
+func (eval *evaluator) Reduce(ct0 *ckks.Ciphertext, ctOut *ckks.Ciphertext) error {
+	if ct0.Degree() != ctOut.Degree() {
+		return errors.New("cannot Reduce: degrees of receiver Ciphertext and input Ciphertext do not match")
+	}
+	eval.unary(ct0, ctOut, ct0.Scale, func(a complex128) complex128 { return a })
+	return nil
+}
+
+// DropLevelNew reduces the level of ct0 by levels and returns the result in a newly created element.
+func (eval *evaluator) DropLevelNew(ct0 *ckks.Ciphertext, levels int) (ctOut *ckks.Ciphertext) {
+	ctOut = ct0.CopyNew()
+	eval.DropLevel(ctOut, levels)
+	return
+}
+
+// DropLevel reduces the level of ct0 by levels and returns the result in ct0.
+func (eval *evaluator) DropLevel(ct0 *ckks.Ciphertext, levels int) {
+	setLevel(ct0, ct0.Level()-levels)
+}
+
+// RescaleNew divides ct0 by the last moduli of the moduli chain, as done by Rescale, and returns the result
+// in a newly created element.
+func (eval *evaluator) RescaleNew(ct0 *ckks.Ciphertext, minScale float64) (ctOut *ckks.Ciphertext, err error) {
+	ctOut = eval.newCiphertext(ct0.Degree(), ct0.Level(), ct0.Scale)
+	return ctOut, eval.Rescale(ct0, minScale, ctOut)
+}
+
+// Rescale divides ct0 by the last modulus in the moduli chain, and repeats this procedure (consuming one level
+// each time) as long as the scale does not go below minScale/2, and returns the result in ctOut.
+// Returns an error if minScale <= 0, ct.Scale = 0, ct.Level() = 0 or if the degrees of ctIn and ctOut differ.
+func (eval *evaluator) Rescale(ctIn *ckks.Ciphertext, minScale float64, ctOut *ckks.Ciphertext) (err error) {
+
+	if minScale <= 0 {
+		return errors.New("cannot Rescale: minScale is 0")
+	}
+
+	if ctIn.Scale == 0 {
+		return errors.New("cannot Rescale: ciphertext scale is 0")
+	}
+
+	if ctIn.Level() == 0 {
+		return errors.New("cannot Rescale: input Ciphertext already at level 0")
+	}
+
+	if ctOut.Degree() != ctIn.Degree() {
+		return errors.New("cannot Rescale : ctIn.Degree() != ctOut.Degree()")
+	}
+
+	scale := ctIn.Scale
+	level := ctIn.Level()
+
+	for level >= 0 && scale/eval.params.QiFloat64(level) >= minScale/2 {
+		scale /= eval.params.QiFloat64(level)
+		level--
+	}
+
+	values := eval.values(ctIn)
+
+	if level < ctIn.Level() {
+		c := complex(scale/ctIn.Scale, 0)
+		for i := range values {
+			values[i] *= c
+		}
+		eval.addNoise(values, eval.roundingNoiseVariance())
+	}
+
+	eval.setOutput(ctOut, ctIn.Degree(), level, scale, values)
+
+	return nil
+}
+
+// MulNew multiplies op0 with op1 without relinearization and returns the result in a newly created element.
+func (eval *evaluator) MulNew(op0, op1 ckks.Operand) (ctOut *ckks.Ciphertext) {
+	ctOut = eval.newCiphertext(op0.Degree()+op1.Degree(), utils.MinInt(op0.Level(), op1.Level()), 0)
+	eval.Mul(op0, op1, ctOut)
+	return
+}
+
+// Mul multiplies op0 with op1 without relinearization and returns the result in ctOut.
+func (eval *evaluator) Mul(op0, op1 ckks.Operand, ctOut *ckks.Ciphertext) {
+	eval.mulRelin(op0, op1, false, ctOut)
+}
+
+// MulRelinNew multiplies op0 with op1 with relinearization and returns the result in a newly created element.
+func (eval *evaluator) MulRelinNew(op0, op1 ckks.Operand) (ctOut *ckks.Ciphertext) {
+	ctOut = eval.newCiphertext(1, utils.MinInt(op0.Level(), op1.Level()), 0)
+	eval.MulRelin(op0, op1, ctOut)
+	return
+}
+
+// MulRelin multiplies op0 with op1 with relinearization and returns the result in ctOut.
+func (eval *evaluator) MulRelin(op0, op1 ckks.Operand, ctOut *ckks.Ciphertext) {
+	eval.mulRelin(op0, op1, true, ctOut)
+}
+
+// mulDegree checks the degrees of the operands of a multiplication and returns the degree of the result.
+func (eval *evaluator) mulDegree(op0, op1 ckks.Operand, relin bool) (degree int) {
+
+	if op0.Degree() > 1 || op1.Degree() > 1 {
+		panic("cannot MulRelin: input elements must be of degree 0 or 1")
+	}
+
+	if degree = op0.Degree() + op1.Degree(); degree == 2 && relin {
+		eval.checkRelinearizationKey()
+		degree = 1
+	}
+
+	return
+}
+
+func (eval *evaluator) mulRelin(op0, op1 ckks.Operand, relin bool, ctOut *ckks.Ciphertext) {
+
+	eval.checkBinary(op0, op1, ctOut)
+
+	degree := eval.mulDegree(op0, op1, relin)
+
+	v0, v1 := eval.values(op0), eval.values(op1)
+	for i := range v0 {
+		v0[i] *= v1[i]
+	}
+
+	if relin && op0.Degree()+op1.Degree() == 2 {
+		eval.keySwitch(v0)
+	}
+
+	eval.setOutput(ctOut, degree, minInt(op0.Level(), op1.Level(), ctOut.Level()), op0.ScalingFactor()*op1.ScalingFactor(), v0)
+}
+
+// MulAndAdd multiplies op0 with op1 without relinearization and adds the result on ctOut.
+// If ctOut.Scale < op0.Scale * op1.Scale, then scales up ctOut before adding the result.
+func (eval *evaluator) MulAndAdd(op0, op1 ckks.Operand, ctOut *ckks.Ciphertext) {
+	eval.mulRelinAndAdd(op0, op1, false, ctOut)
+}
+
+// MulRelinAndAdd multiplies op0 with op1 with relinearization and adds the result on ctOut.
+// If ctOut.Scale < op0.Scale * op1.Scale, then scales up ctOut before adding the result.
+func (eval *evaluator) MulRelinAndAdd(op0, op1 ckks.Operand, ctOut *ckks.Ciphertext) {
+	eval.mulRelinAndAdd(op0, op1, true, ctOut)
+}
+
+func (eval *evaluator) mulRelinAndAdd(op0, op1 ckks.Operand, relin bool, ctOut *ckks.Ciphertext) {
+
+	eval.checkBinary(op0, op1, ctOut)
+
+	degree := eval.mulDegree(op0, op1, relin)
+
+	if op0.El() == ctOut.El() || op1.El() == ctOut.El() {
+		panic("ctOut must be different from op0 and op1")
+	}
+
+	level := minInt(op0.Level(), op1.Level(), ctOut.Level())
+
+	setLevel(ctOut, level)
+
+	resScale := op0.ScalingFactor() * op1.ScalingFactor()
+
+	if ctOut.Scale < resScale {
+		eval.MultByConst(ctOut, math.Round(resScale/ctOut.Scale), ctOut)
+		ctOut.Scale = resScale
+	}
+
+	v0, v1, vOut := eval.values(op0), eval.values(op1), eval.values(ctOut)
+	for i := range vOut {
+		vOut[i] += v0[i] * v1[i]
+	}
+
+	if relin && op0.Degree()+op1.Degree() == 2 {
+		eval.keySwitch(vOut)
+	}
+
+	eval.setOutput(ctOut, utils.MaxInt(degree, ctOut.Degree()), level, ctOut.Scale, vOut)
+}
+
+// RelinearizeNew applies the relinearization procedure on ct0 and returns the result in a newly
+// created Ciphertext. The input Ciphertext must be of degree two.
+func (eval *evaluator) RelinearizeNew(ct0 *ckks.Ciphertext) (ctOut *ckks.Ciphertext) {
+	ctOut = eval.newCiphertext(1, ct0.Level(), ct0.Scale)
+	eval.Relinearize(ct0, ctOut)
+	return
+}
+
+// Relinearize applies the relinearization procedure on ct0 and returns the result in ctOut. The input Ciphertext must be of degree two.
+func (eval *evaluator) Relinearize(ct0 *ckks.Ciphertext, ctOut *ckks.Ciphertext) {
+
+	if ct0.Degree() != 2 {
+		panic("cannot Relinearize: input Ciphertext is not of degree 2")
+	}
+
+	eval.checkRelinearizationKey()
+
+	values := eval.values(ct0)
+	eval.keySwitch(values)
+	eval.setOutput(ctOut, 1, minInt(ct0.Level(), ctOut.Level()), ct0.Scale, values)
+}
+
+// SwitchKeysNew re-encrypts ct0 under a different key and returns the result in a newly created element.
+func (eval *evaluator) SwitchKeysNew(ct0 *ckks.Ciphertext, switchingKey *rlwe.SwitchingKey) (ctOut *ckks.Ciphertext) {
+	ctOut = eval.newCiphertext(ct0.Degree(), ct0.Level(), ct0.Scale)
+	eval.SwitchKeys(ct0, switchingKey, ctOut)
+	return
+}
+
+// SwitchKeys re-encrypts ct0 under a different key and returns the result in ctOut.
+func (eval *evaluator) SwitchKeys(ct0 *ckks.Ciphertext, switchingKey *rlwe.SwitchingKey, ctOut *ckks.Ciphertext) {
+
+	if ct0.Degree() != 1 || ctOut.Degree() != 1 {
+		panic("cannot SwitchKeys: input and output Ciphertext must be of degree 1")
+	}
+
+	if switchingKey == nil {
+		panic("cannot SwitchKeys: switchingKey cannot be nil")
+	}
+
+	values := eval.values(ct0)
+	eval.keySwitch(values)
+	eval.setOutput(ctOut, 1, minInt(ct0.Level(), ctOut.Level()), ct0.Scale, values)
+}
+
+// RotateNew rotates the columns of ct0 by k positions to the left, and returns the result in a newly created element.
+func (eval *evaluator) RotateNew(ct0 *ckks.Ciphertext, k int) (ctOut *ckks.Ciphertext) {
+	ctOut = eval.newCiphertext(ct0.Degree(), ct0.Level(), ct0.Scale)
+	eval.Rotate(ct0, k, ctOut)
+	return
+}
+
+// Rotate rotates the columns of ct0 by k positions to the left and returns the result in ctOut.
+func (eval *evaluator) Rotate(ct0 *ckks.Ciphertext, k int, ctOut *ckks.Ciphertext) {
+
+	if ct0.Degree() != 1 || ctOut.Degree() != 1 {
+		panic("cannot Rotate: input and output Ciphertext must be of degree 1")
+	}
+
+	values := eval.values(ct0)
+
+	if k != 0 {
+		eval.checkRotationKey(eval.params.GaloisElementForColumnRotationBy(k))
+		values = rotate(values, k)
+		eval.keySwitch(values)
+	}
+
+	eval.setOutput(ctOut, 1, minInt(ct0.Level(), ctOut.Level()), ct0.Scale, values)
+}
+
+// ConjugateNew conjugates ct0 and returns the result in a newly created element.
+func (eval *evaluator) ConjugateNew(ct0 *ckks.Ciphertext) (ctOut *ckks.Ciphertext) {
+
+	if eval.params.RingType() == ring.ConjugateInvariant {
+		panic("method ConjugateNew is not supported when params.RingType() == ring.ConjugateInvariant")
+	}
+
+	ctOut = eval.newCiphertext(ct0.Degree(), ct0.Level(), ct0.Scale)
+	eval.Conjugate(ct0, ctOut)
+	return
+}
+
+// Conjugate conjugates ct0 and returns the result in ctOut.
+func (eval *evaluator) Conjugate(ct0 *ckks.Ciphertext, ctOut *ckks.Ciphertext) {
+
+	if eval.params.RingType() == ring.ConjugateInvariant {
+		panic("method Conjugate is not supported when params.RingType() == ring.ConjugateInvariant")
+	}
+
+	if ct0.Degree() != 1 || ctOut.Degree() != 1 {
+		panic("input and output Ciphertext must be of degree 1")
+	}
+
+	eval.checkRotationKey(eval.params.GaloisElementForRowRotation())
+
+	values := eval.values(ct0)
+	for i := range values {
+		values[i] = complex(real(values[i]), -imag(values[i]))
+	}
+	eval.keySwitch(values)
+
+	eval.setOutput(ctOut, 1, minInt(ct0.Level(), ctOut.Level()), ct0.Scale, values)
+}
+
+// AutomorphismNew applies the automorphism X -> X^galEl on ct0 and returns the result in a newly created element.
+func (eval *evaluator) AutomorphismNew(ct0 *ckks.Ciphertext, galEl uint64) (ctOut *ckks.Ciphertext) {
+	ctOut = eval.newCiphertext(ct0.Degree(), ct0.Level(), ct0.Scale)
+	eval.Automorphism(ct0, galEl, ctOut)
+	return
+}
+
+// Automorphism applies the automorphism X -> X^galEl on ct0 and returns the result in ctOut.
+func (eval *evaluator) Automorphism(ct0 *ckks.Ciphertext, galEl uint64, ctOut *ckks.Ciphertext) {
+
+	if ct0.Degree() != 1 || ctOut.Degree() != 1 {
+		panic("cannot Automorphism: input and output Ciphertext must be of degree 1")
+	}
+
+	values := eval.values(ct0)
+
+	if galEl&(eval.params.RingQ().NthRoot-1) != 1 {
+
+		eval.checkRotationKey(galEl)
+
+		k, rowRotation := eval.params.RotationForGaloisElement(galEl)
+
+		values = rotate(values, k)
+
+		if rowRotation {
+			for i := range values {
+				values[i] = complex(real(values[i]), -imag(values[i]))
+			}
+		}
+
+		eval.keySwitch(values)
+	}
+
+	eval.setOutput(ctOut, 1, minInt(ct0.Level(), ctOut.Level()), ct0.Scale, values)
+}
+
+// PermuteNew applies the Permutation on ctIn and returns the result on a new Ciphertext.
+func (eval *evaluator) PermuteNew(ctIn *ckks.Ciphertext, permutation ckks.Permutation) (ctOut *ckks.Ciphertext) {
+	ctOut = eval.newCiphertext(1, minInt(ctIn.Level(), permutation.Level), ctIn.Scale)
+	eval.Permute(ctIn, permutation, ctOut)
+	return
+}
+
+// Permute applies the Permutation on ctIn and returns the result on ctOut, consuming one level per layer.
+func (eval *evaluator) Permute(ctIn *ckks.Ciphertext, permutation ckks.Permutation, ctOut *ckks.Ciphertext) {
+
+	if ctIn.Degree() != 1 || ctOut.Degree() != 1 {
+		panic("cannot Permute: input and output Ciphertext must be of degree 1")
+	}
+
+	if len(permutation.LinearTransforms) == 0 {
+		eval.setOutput(ctOut, 1, minInt(ctIn.Level(), ctOut.Level()), ctIn.Scale, eval.values(ctIn))
+		return
+	}
+
+	scale := ctIn.Scale
+
+	ct := ctIn
+	for _, LT := range permutation.LinearTransforms {
+
+		eval.LinearTransform(ct, LT, []*ckks.Ciphertext{ctOut})
+
+		if err := eval.Rescale(ctOut, scale, ctOut); err != nil {
+			panic(err)
+		}
+
+		ct = ctOut
+	}
+}
+
+// RotateHoistedNew takes an input Ciphertext and a list of rotations and returns a map of Ciphertext, where each element of the map
+// is the input Ciphertext rotation by one element of the list.
+func (eval *evaluator) RotateHoistedNew(ctIn *ckks.Ciphertext, rotations []int) (ctOut map[int]*ckks.Ciphertext) {
+	ctOut = make(map[int]*ckks.Ciphertext)
+	for _, i := range rotations {
+		ctOut[i] = eval.newCiphertext(1, ctIn.Level(), ctIn.Scale)
+	}
+	eval.RotateHoisted(ctIn, rotations, ctOut)
+	return
+}
+
+// RotateHoisted takes an input Ciphertext and a list of rotations and populates a map of pre-allocated Ciphertexts,
+// where each element of the map is the input Ciphertext rotation by one element of the list.
+func (eval *evaluator) RotateHoisted(ctIn *ckks.Ciphertext, rotations []int, ctOut map[int]*ckks.Ciphertext) {
+	for _, i := range rotations {
+		eval.Rotate(ctIn, i, ctOut[i])
+	}
+}
+
+// RotateHoistedNoModDownNew is not supported by the simulation.
+func (eval *evaluator) RotateHoistedNoModDownNew(level int, rotations []int, c0 *ring.Poly, c2DecompQP []rlwe.PolyQP) (cOut map[int][2]rlwe.PolyQP) {
+	panic("cannot RotateHoistedNoModDownNew: method is not supported by the simulation")
+}
+
+// PermuteNTTHoisted is not supported by the simulation.
+func (eval *evaluator) PermuteNTTHoisted(level int, c0, c1 *ring.Poly, c2DecompQP []rlwe.PolyQP, k int, cOut0, cOut1 *ring.Poly) {
+	panic("cannot PermuteNTTHoisted: method is not supported by the simulation")
+}
+
+// PermuteNTTHoistedNoModDown is not supported by the simulation.
+func (eval *evaluator) PermuteNTTHoistedNoModDown(level int, c0 *ring.Poly, c2DecompQP []rlwe.PolyQP, k int, ct0OutQ, ct1OutQ, ct0OutP, ct1OutP *ring.Poly) {
+	panic("cannot PermuteNTTHoistedNoModDown: method is not supported by the simulation")
+}
+
+// ===========================
+// === Advanced Arithmetic ===
+// ===========================
+
+// PowerOf2 computes op^(2^logPow2), consuming logPow2 levels, and returns the result on opOut.
+func (eval *evaluator) PowerOf2(op *ckks.Ciphertext, logPow2 int, opOut *ckks.Ciphertext) {
+
+	if logPow2 == 0 {
+		eval.unary(op, opOut, op.Scale, func(a complex128) complex128 { return a })
+		return
+	}
+
+	eval.MulRelin(op, op, opOut)
+
+	if err := eval.Rescale(opOut, op.Scale, opOut); err != nil {
+		panic(err)
+	}
+
+	for i := 1; i < logPow2; i++ {
+
+		eval.MulRelin(opOut, opOut, opOut)
+
+		if err := eval.Rescale(opOut, op.Scale, opOut); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// PowerNew computes op^degree, consuming log(degree) levels, and returns the result on a new element.
+func (eval *evaluator) PowerNew(op *ckks.Ciphertext, degree int) (opOut *ckks.Ciphertext) {
+	opOut = eval.newCiphertext(1, op.Level(), op.Scale)
+	eval.Power(op, degree, opOut)
+	return
+}
+
+// Power computes op^degree, consuming log(degree) levels, and returns the result on opOut.
+func (eval *evaluator) Power(op *ckks.Ciphertext, degree int, opOut *ckks.Ciphertext) {
+
+	if degree < 1 {
+		panic("eval.Power -> degree cannot be smaller than 1")
+	}
+
+	tmpct0 := op.CopyNew()
+
+	logDegree := bits.Len64(uint64(degree)) - 1
+	po2Degree := 1 << logDegree
+
+	eval.PowerOf2(tmpct0, logDegree, opOut)
+
+	degree -= po2Degree
+
+	for degree > 0 {
+
+		logDegree = bits.Len64(uint64(degree)) - 1
+		po2Degree = 1 << logDegree
+
+		tmp := eval.newCiphertext(1, tmpct0.Level(), tmpct0.Scale)
+
+		eval.PowerOf2(tmpct0, logDegree, tmp)
+
+		eval.MulRelin(opOut, tmp, opOut)
+
+		if err := eval.Rescale(opOut, op.Scale, opOut); err != nil {
+			panic(err)
+		}
+
+		degree -= po2Degree
+	}
+}
+
+// InverseNew computes 1/op and returns the result on a new element, iterating for n steps and consuming n levels.
+func (eval *evaluator) InverseNew(op *ckks.Ciphertext, steps int) (opOut *ckks.Ciphertext) {
+
+	cbar := eval.NegNew(op)
+
+	eval.AddConst(cbar, 1, cbar)
+
+	tmp := eval.AddConstNew(cbar, 1)
+	opOut = tmp.CopyNew()
+
+	for i := 1; i < steps; i++ {
+
+		eval.MulRelin(cbar, cbar, cbar)
+
+		if err := eval.Rescale(cbar, op.Scale, cbar); err != nil {
+			panic(err)
+		}
+
+		tmp = eval.AddConstNew(cbar, 1)
+
+		eval.MulRelin(tmp, opOut, tmp)
+
+		if err := eval.Rescale(tmp, op.Scale, tmp); err != nil {
+			panic(err)
+		}
+
+		opOut = tmp.CopyNew()
+	}
+
+	return opOut
+}
+
+// EvaluatePoly evaluates a polynomial on the input Ciphertext. As done by the ckks.Evaluator, ceil(log2(deg+1))
+// levels are consumed and the output Ciphertext has scale targetScale.
+// Returns an error if the input ciphertext does not have enough levels to carry out the polynomial evaluation.
+func (eval *evaluator) EvaluatePoly(ct0 *ckks.Ciphertext, pol *ckks.Polynomial, targetScale float64) (ctOut *ckks.Ciphertext, err error) {
+	return eval.evaluatePolyVector(ct0, []*ckks.Polynomial{pol}, nil, targetScale)
+}
+
+// EvaluatePolyVector evaluates a vector of Polynomials on the input Ciphertext, the i-th polynomial being
+// evaluated on the slots slotIndex[i]. As done by the ckks.Evaluator, ceil(log2(deg+1)) levels are consumed
+// and the output Ciphertext has scale targetScale.
+// Returns an error if the input ciphertext does not have enough levels to carry out the polynomial evaluation.
+// Returns an error if polynomials are not all in the same basis or do not all have the same degree.
+func (eval *evaluator) EvaluatePolyVector(ct0 *ckks.Ciphertext, pols []*ckks.Polynomial, encoder ckks.Encoder, slotIndex map[int][]int, targetScale float64) (ctOut *ckks.Ciphertext, err error) {
+
+	for i := range pols {
+		if pols[0].Basis != pols[i].Basis {
+			return nil, fmt.Errorf("polynomial basis must be the same for all polynomials in a polynomial vector")
+		}
+
+		if pols[0].MaxDeg != pols[i].MaxDeg {
+			return nil, fmt.Errorf("polynomial degree must all be the same")
+		}
+	}
+
+	if slotIndex != nil && encoder == nil {
+		return nil, fmt.Errorf("cannot EvaluatePolyVector, missing Encoder input")
+	}
+
+	return eval.evaluatePolyVector(ct0, pols, slotIndex, targetScale)
+}
+
+func (eval *evaluator) evaluatePolyVector(ct0 *ckks.Ciphertext, pols []*ckks.Polynomial, slotIndex map[int][]int, targetScale float64) (ctOut *ckks.Ciphertext, err error) {
+
+	depth := pols[0].Depth()
+
+	if ct0.Level() < depth {
+		return ct0, fmt.Errorf("%d levels < %d log(d) -> cannot evaluate", ct0.Level(), depth)
+	}
+
+	values := eval.values(ct0)
+
+	// index of the polynomial to evaluate on each slot, -1 if the slot is zeroed
+	index := make([]int, eval.params.Slots())
+	if slotIndex == nil {
+		for j := range index {
+			index[j] = 0
+		}
+	} else {
+		for j := range index {
+			index[j] = -1
+		}
+		for i, slots := range slotIndex {
+			for _, j := range slots {
+				index[j] = i
+			}
+		}
+	}
+
+	for j := range values {
+		if i := index[j%len(index)]; i >= 0 {
+			values[j] = evaluatePolynomial(pols[i], values[j]/complex(ct0.Scale, 0)) * complex(targetScale, 0)
+		} else {
+			values[j] = 0
+		}
+	}
+
+	eval.addNoise(values, eval.roundingNoiseVariance())
+
+	ctOut = eval.newCiphertext(1, ct0.Level()-depth, targetScale)
+	eval.setValues(ctOut.Value[0], values)
+
+	return ctOut, nil
+}
+
+// evaluatePolynomial evaluates the polynomial on x in its basis.
+func evaluatePolynomial(pol *ckks.Polynomial, x complex128) (y complex128) {
+
+	if pol.Basis == ckks.ChebyshevBasis {
+		T0, T1 := complex(1, 0), x
+		for i, c := range pol.Coeffs {
+			switch i {
+			case 0:
+				y += c * T0
+			case 1:
+				y += c * T1
+			default:
+				T0, T1 = T1, 2*x*T1-T0
+				y += c * T1
+			}
+		}
+		return
+	}
+
+	for i := len(pol.Coeffs) - 1; i >= 0; i-- {
+		y = y*x + pol.Coeffs[i]
+	}
+
+	return
+}
+
+// EvaluateEncryptedPoly evaluates a polynomial with encrypted coefficients on the input Ciphertext. As done by the
+// ckks.Evaluator, ceil(log2(deg+1)) levels are consumed, the output Ciphertext has scale targetScale and each
+// coefficient must have at least one more level than the output Ciphertext.
+// Returns an error if the input ciphertext or the coefficients do not have enough levels to carry out the polynomial evaluation.
+func (eval *evaluator) EvaluateEncryptedPoly(ct0 *ckks.Ciphertext, pol *ckks.EncryptedPolynomial, targetScale float64) (ctOut *ckks.Ciphertext, err error) {
+
+	if pol.Degree() < 0 {
+		return nil, fmt.Errorf("cannot EvaluateEncryptedPoly: polynomial has no coefficient")
+	}
+
+	depth := pol.Depth()
+
+	if ct0.Level() < depth {
+		return ct0, fmt.Errorf("%d levels < %d log(d) -> cannot evaluate", ct0.Level(), depth)
+	}
+
+	level := ct0.Level() - depth
+
+	if pol.Degree() > 0 {
+		eval.checkRelinearizationKey()
+	}
+
+	coeffs := make([][]complex128, len(pol.Coeffs))
+	for i, c := range pol.Coeffs {
+		if c != nil {
+			if c.Level() <= level {
+				return nil, fmt.Errorf("cannot EvaluateEncryptedPoly: coefficient level %d < %d", c.Level(), level+1)
+			}
+			coeffs[i] = eval.values(c)
+		}
+	}
+
+	values := eval.values(ct0)
+
+	slotPol := &ckks.Polynomial{Coeffs: make([]complex128, len(pol.Coeffs)), Basis: pol.Basis}
+
+	for j := range values {
+		for i, c := range coeffs {
+			if c != nil {
+				slotPol.Coeffs[i] = c[j] / complex(pol.Coeffs[i].Scale, 0)
+			} else {
+				slotPol.Coeffs[i] = 0
+			}
+		}
+		values[j] = evaluatePolynomial(slotPol, values[j]/complex(ct0.Scale, 0)) * complex(targetScale, 0)
+	}
+
+	eval.addNoise(values, eval.roundingNoiseVariance())
+
+	ctOut = eval.newCiphertext(1, level, targetScale)
+	eval.setValues(ctOut.Value[0], values)
+
+	return ctOut, nil
+}
+
+// LinearTransformNew evaluates a linear transform on the ciphertext and returns the result on a new ciphertext.
+// The linearTransform can either be a []ckks.LinearTransform or a single ckks.LinearTransform, which must have
+// been generated with GenLinearTransform or GenLinearTransformBSGS.
+func (eval *evaluator) LinearTransformNew(ctIn *ckks.Ciphertext, linearTransform interface{}) (ctOut []*ckks.Ciphertext) {
+
+	switch LTs := linearTransform.(type) {
+	case []ckks.LinearTransform:
+		ctOut = make([]*ckks.Ciphertext, len(LTs))
+		for i, LT := range LTs {
+			ctOut[i] = eval.newCiphertext(1, utils.MinInt(LT.Level, ctIn.Level()), ctIn.Scale)
+			eval.multiplyByDiagMatrix(ctIn, LT, ctOut[i])
+		}
+	case ckks.LinearTransform:
+		ctOut = []*ckks.Ciphertext{eval.newCiphertext(1, utils.MinInt(LTs.Level, ctIn.Level()), ctIn.Scale)}
+		eval.multiplyByDiagMatrix(ctIn, LTs, ctOut[0])
+	}
+
+	return
+}
+
+// LinearTransform evaluates a linear transform on the pre-allocated ciphertexts.
+// The linearTransform can either be a []ckks.LinearTransform or a single ckks.LinearTransform, which must have
+// been generated with GenLinearTransform or GenLinearTransformBSGS.
+func (eval *evaluator) LinearTransform(ctIn *ckks.Ciphertext, linearTransform interface{}, ctOut []*ckks.Ciphertext) {
+
+	switch LTs := linearTransform.(type) {
+	case []ckks.LinearTransform:
+		for i, LT := range LTs {
+			eval.multiplyByDiagMatrix(ctIn, LT, ctOut[i])
+		}
+	case ckks.LinearTransform:
+		eval.multiplyByDiagMatrix(ctIn, LTs, ctOut[0])
+	}
+}
+
+// MultiplyByDiagMatrix multiplies the ciphertext ctIn by the plaintext matrix and returns the result on ctOut.
+// The decomposition of the ciphertext c2DecompQP is ignored.
+func (eval *evaluator) MultiplyByDiagMatrix(ctIn *ckks.Ciphertext, matrix ckks.LinearTransform, c2DecompQP []rlwe.PolyQP, ctOut *ckks.Ciphertext) {
+	eval.multiplyByDiagMatrix(ctIn, matrix, ctOut)
+}
+
+// MultiplyByDiagMatrixBSGS multiplies the ciphertext ctIn by the plaintext matrix and returns the result on ctOut.
+// The decomposition of the ciphertext c2DecompQP is ignored.
+func (eval *evaluator) MultiplyByDiagMatrixBSGS(ctIn *ckks.Ciphertext, matrix ckks.LinearTransform, c2DecompQP []rlwe.PolyQP, ctOut *ckks.Ciphertext) {
+	eval.multiplyByDiagMatrix(ctIn, matrix, ctOut)
+}
+
+// multiplyByDiagMatrix evaluates sum_{j, i} Rotate(matrix.Vec[j+i] * Rotate(ctIn, i), j), with j = 0 if
+// matrix.N1 == 0 and j a multiple of matrix.N1 otherwise, which covers both the naive and the BSGS evaluations.
+func (eval *evaluator) multiplyByDiagMatrix(ctIn *ckks.Ciphertext, matrix ckks.LinearTransform, ctOut *ckks.Ciphertext) {
+
+	eval.checkRotations(matrix.Rotations())
+
+	values := eval.values(ctIn)
+
+	res := make([]complex128, len(values))
+
+	for idx, diag := range matrix.Vec {
+
+		j, i := 0, idx
+		if matrix.N1 != 0 {
+			j, i = (idx/matrix.N1)*matrix.N1, idx&(matrix.N1-1)
+		}
+
+		tmp := rotate(values, i)
+		vec := eval.getValues(diag.Q)
+		for k := range tmp {
+			tmp[k] *= vec[k]
+		}
+
+		tmp = rotate(tmp, j)
+		for k := range res {
+			res[k] += tmp[k]
+		}
+	}
+
+	eval.keySwitch(res)
+
+	eval.setOutput(ctOut, 1, minInt(ctIn.Level(), ctOut.Level(), matrix.Level), ctIn.Scale*matrix.Scale, res)
+}
+
+// innerSum sets ctOut to sum_{i=0}^{n-1} Rotate(ctIn, i*batch), after having checked that the given rotation keys are available.
+func (eval *evaluator) innerSum(ctIn *ckks.Ciphertext, batch, n int, rotations []int, ctOut *ckks.Ciphertext) {
+
+	if n != 1 {
+		eval.checkRotations(rotations)
+	}
+
+	values := eval.values(ctIn)
+	res := make([]complex128, len(values))
+	for i := 0; i < n; i++ {
+		tmp := rotate(values, i*batch)
+		for k := range res {
+			res[k] += tmp[k]
+		}
+	}
+
+	if n != 1 {
+		eval.keySwitch(res)
+	}
+
+	eval.setOutput(ctOut, 1, ctIn.Level(), ctIn.Scale, res)
+}
+
+// InnerSumLog adds together (in parallel) the SlotCount/batchSize sub-vectors of size batchSize of ctIn by groups of n,
+// and returns the result in ctOut. It requires the rotation keys for params.RotationsForInnerSumLog(batchSize, n).
+// Only the leftmost sub-vector of each group is guaranteed to match the output of the ckks.Evaluator.
+func (eval *evaluator) InnerSumLog(ctIn *ckks.Ciphertext, batchSize, n int, ctOut *ckks.Ciphertext) {
+	eval.innerSum(ctIn, batchSize, n, eval.params.RotationsForInnerSumLog(batchSize, n), ctOut)
+}
+
+// InnerSum adds together (in parallel) the SlotCount/batchSize sub-vectors of size batchSize of ctIn by groups of n,
+// and returns the result in ctOut. It requires the rotation keys for params.RotationsForInnerSum(batchSize, n).
+func (eval *evaluator) InnerSum(ctIn *ckks.Ciphertext, batchSize, n int, ctOut *ckks.Ciphertext) {
+	eval.innerSum(ctIn, batchSize, n, eval.params.RotationsForInnerSum(batchSize, n), ctOut)
+}
+
+// Average returns the average of vectors of batchSize elements.
+func (eval *evaluator) Average(ctIn *ckks.Ciphertext, logBatchSize int, ctOut *ckks.Ciphertext) {
+
+	if logBatchSize > eval.params.LogSlots() {
+		panic("batchSize must be smaller or equal to the number of slots")
+	}
+
+	n := eval.params.Slots() / (1 << logBatchSize)
+
+	eval.InnerSumLog(ctIn, 1<<logBatchSize, n, ctOut)
+
+	values := eval.values(ctOut)
+	mulScalar(values, complex(1/float64(n), 0))
+	eval.setValues(ctOut.Value[0], values)
+}
+
+// ReplicateLog replicates the sub-vectors of size batchSize of ctIn n times, and returns the result in ctOut.
+func (eval *evaluator) ReplicateLog(ctIn *ckks.Ciphertext, batchSize, n int, ctOut *ckks.Ciphertext) {
+	eval.InnerSumLog(ctIn, -batchSize, n, ctOut)
+}
+
+// Replicate replicates the sub-vectors of size batchSize of ctIn n times, and returns the result in ctOut.
+func (eval *evaluator) Replicate(ctIn *ckks.Ciphertext, batchSize, n int, ctOut *ckks.Ciphertext) {
+	eval.InnerSum(ctIn, -batchSize, n, ctOut)
+}
+
+// Trace maps X -> sum((-1)^i * X^{i*n+1}) for 0 <= i < N, for log(n) = logSlotsStart and log(N/2) = logSlotsEnd,
+// which is equivalent in the slot domain to the average of the rotations of ctIn by the multiples of 2^logSlotsStart
+// smaller than 2^logSlotsEnd. It requires the rotation keys for params.RotationsForTrace(logSlotsStart, logSlotsEnd).
+func (eval *evaluator) Trace(ctIn *ckks.Ciphertext, logSlotsStart, logSlotsEnd int, ctOut *ckks.Ciphertext) {
+
+	n := 1 << (logSlotsEnd - logSlotsStart)
+
+	rotations := eval.params.RotationsForTrace(logSlotsStart, logSlotsEnd)
+
+	level := minInt(ctIn.Level(), ctOut.Level())
+
+	eval.innerSum(ctIn, 1<<logSlotsStart, n, rotations, ctOut)
+
+	values := eval.values(ctOut)
+	mulScalar(values, complex(1/float64(n), 0))
+	eval.setOutput(ctOut, 1, level, ctIn.Scale, values)
+}
+
+// TraceNew maps X -> sum((-1)^i * X^{i*n+1}) for 0 <= i < N and returns the result on a new ciphertext.
+func (eval *evaluator) TraceNew(ctIn *ckks.Ciphertext, logSlotsStart, logSlotsEnd int) (ctOut *ckks.Ciphertext) {
+	ctOut = eval.newCiphertext(1, ctIn.Level(), ctIn.Scale)
+	eval.Trace(ctIn, logSlotsStart, logSlotsEnd, ctOut)
+	return
+}
+
+// ==============
+// === Others ===
+// ==============
+
+// GetKeySwitcher returns nil, as the simulation has no rlwe.KeySwitcher.
+func (eval *evaluator) GetKeySwitcher() *rlwe.KeySwitcher {
+	return nil
+}
+
+// PoolQMul returns empty polynomials, as the simulation has no memory pool.
+func (eval *evaluator) PoolQMul() [3]*ring.Poly {
+	return [3]*ring.Poly{eval.newPoly(eval.params.MaxLevel()), eval.newPoly(eval.params.MaxLevel()), eval.newPoly(eval.params.MaxLevel())}
+}
+
+// CtxPool returns a new ciphertext, as the simulation has no memory pool.
+func (eval *evaluator) CtxPool() *ckks.Ciphertext {
+	return eval.newCiphertext(2, eval.params.MaxLevel(), eval.params.DefaultScale())
+}
+
+// ShallowCopy creates a shallow copy of the evaluator that can be used concurrently with the receiver.
+func (eval *evaluator) ShallowCopy() ckks.Evaluator {
+	return &evaluator{simulator: eval.shallowCopy(), rlk: eval.rlk, rtks: eval.rtks}
+}
+
+// WithKey creates a shallow copy of the evaluator with a new evaluation key.
+func (eval *evaluator) WithKey(evaluationKey rlwe.EvaluationKey) ckks.Evaluator {
+	return &evaluator{simulator: eval.shallowCopy(), rlk: evaluationKey.Rlk, rtks: evaluationKey.Rtks}
+}
+
+// WithPool returns the receiver, as the simulation has no temporary polynomials.
+func (eval *evaluator) WithPool(pool *rlwe.Pool) ckks.Evaluator {
+	return eval
+}
@@ -0,0 +1,263 @@
+// Package simulation implements a simulation backend for the CKKS scheme. It provides implementations of the
+// ckks.Encoder, ckks.Encryptor, ckks.Decryptor and ckks.Evaluator interfaces that operate on cleartext slot
+// vectors instead of ciphertexts, while enforcing the same level, scale, degree and evaluation-key constraints
+// as the real implementations. It is intended to test applications built on top of the ckks package without
+// the cost of the encryption. Optionally, the errors introduced by the encoding, the encryption, the rescaling
+// and the key-switching can be simulated to surface precision issues.
+//
+// The objects of the simulation are regular ckks.Plaintext and ckks.Ciphertext, whose first polynomial stores
+// the scaled slot values (i.e. the message multiplied by the scale) as float64. They are allocated in a compact
+// form and are only meaningful for the simulation backend: they cannot be mixed with objects of the ckks package.
+package simulation
+
+import (
+	"encoding/binary"
+	"math"
+	"math/rand"
+
+	"github.com/tuneinsight/lattigo/v3/ckks"
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// simulator stores the parameters and the noise sampler shared by all the simulation objects.
+type simulator struct {
+	params ckks.Parameters
+	noise  bool
+	fork   *utils.PRNGFork // derives the sources of the shallow copies, nil if the simulator is randomly seeded
+	prng   *rand.Rand
+}
+
+func newSimulator(params ckks.Parameters, noise bool) *simulator {
+	return newSimulatorWithPRNG(params, noise, nil)
+}
+
+// newSimulatorWithPRNG creates a new simulator whose random source is seeded from source, or randomly if source is nil.
+func newSimulatorWithPRNG(params ckks.Parameters, noise bool, source utils.PRNG) *simulator {
+	fork := utils.NewPRNGFork(source)
+	seed := utils.RandUint64()
+	if source != nil {
+		buf := make([]byte, 8)
+		source.Clock(buf)
+		seed = binary.LittleEndian.Uint64(buf)
+	}
+	return &simulator{params: params, noise: noise, fork: fork, prng: rand.New(rand.NewSource(int64(seed)))}
+}
+
+func (s *simulator) shallowCopy() *simulator {
+	source, err := s.fork.Derive()
+	if err != nil {
+		panic(err)
+	}
+	return newSimulatorWithPRNG(s.params, s.noise, source)
+}
+
+// hammingWeight returns the Hamming weight of the secret.
+func (s *simulator) hammingWeight() float64 {
+	if h := s.params.HammingWeight(); h > 0 {
+		return float64(h)
+	}
+	return 2 * float64(s.params.N()) / 3
+}
+
+// freshNoiseVariance returns the variance of the error of a fresh encryption in the coefficient domain.
+// With a public key, the encryption is done modulo QP and the error is dominated by the division by P.
+func (s *simulator) freshNoiseVariance(publicKey bool) float64 {
+	sigma2 := s.params.Sigma() * s.params.Sigma()
+	if publicKey {
+		if s.params.PCount() > 0 {
+			return s.roundingNoiseVariance()
+		}
+		return sigma2 * (2*s.hammingWeight() + 1)
+	}
+	return sigma2
+}
+
+// roundingNoiseVariance returns the variance of the error introduced by a rounding of the ciphertext (e.g. a
+// rescaling or the division by P of a key-switching) in the coefficient domain.
+func (s *simulator) roundingNoiseVariance() float64 {
+	return (1 + s.hammingWeight()) / 12
+}
+
+// addNoise adds to the values the image in the slot domain of a Gaussian error of the given variance in the
+// coefficient domain. Does nothing if the noise is disabled.
+func (s *simulator) addNoise(values []complex128, variance float64) {
+	if s.noise {
+		s.addGaussian(values, variance)
+	}
+}
+
+// addGaussian adds to the values the image in the slot domain of a Gaussian error of the given variance in the
+// coefficient domain.
+func (s *simulator) addGaussian(values []complex128, variance float64) {
+
+	std := math.Sqrt(variance * float64(s.params.N()) / 2)
+
+	for i := range values {
+		if s.params.RingType() == ring.ConjugateInvariant {
+			values[i] = complex(real(values[i])+std*s.prng.NormFloat64(), 0)
+		} else {
+			values[i] += complex(std*s.prng.NormFloat64(), std*s.prng.NormFloat64())
+		}
+	}
+}
+
+// newPoly returns a compact polynomial at the given level, that can only store slot values.
+func (s *simulator) newPoly(level int) (pol *ring.Poly) {
+	pol = &ring.Poly{Coeffs: make([][]uint64, level+1), IsNTT: true}
+	pol.Coeffs[0] = make([]uint64, s.params.N())
+	for i := 1; i < level+1; i++ {
+		pol.Coeffs[i] = []uint64{}
+	}
+	return
+}
+
+// NewPlaintext allocates a new compact ckks.Plaintext for the simulation.
+func NewPlaintext(params ckks.Parameters, level int, scale float64) *ckks.Plaintext {
+	return newSimulator(params, false).newPlaintext(level, scale)
+}
+
+// NewCiphertext allocates a new compact ckks.Ciphertext for the simulation.
+func NewCiphertext(params ckks.Parameters, degree, level int, scale float64) *ckks.Ciphertext {
+	return newSimulator(params, false).newCiphertext(degree, level, scale)
+}
+
+func (s *simulator) newPlaintext(level int, scale float64) *ckks.Plaintext {
+	return &ckks.Plaintext{Plaintext: &rlwe.Plaintext{Value: s.newPoly(level)}, Scale: scale}
+}
+
+func (s *simulator) newCiphertext(degree, level int, scale float64) *ckks.Ciphertext {
+	ct := &ckks.Ciphertext{Ciphertext: &rlwe.Ciphertext{Value: make([]*ring.Poly, degree+1)}, Scale: scale}
+	for i := range ct.Value {
+		ct.Value[i] = s.newPoly(level)
+	}
+	return ct
+}
+
+// resize sets the degree of the ciphertext.
+func (s *simulator) resize(ct *ckks.Ciphertext, degree int) {
+	if ct.Degree() > degree {
+		ct.Value = ct.Value[:degree+1]
+	}
+	for ct.Degree() < degree {
+		ct.Value = append(ct.Value, s.newPoly(ct.Level()))
+	}
+}
+
+// setLevel drops the level of the ciphertext to the given level.
+func setLevel(ct *ckks.Ciphertext, level int) {
+	if level < 0 {
+		panic("cannot set level: level cannot be negative")
+	}
+	if level > ct.Level() {
+		panic("cannot set level: level is larger than the level of the ciphertext")
+	}
+	for i := range ct.Value {
+		ct.Value[i].Coeffs = ct.Value[i].Coeffs[:level+1]
+	}
+}
+
+// setOutput sets the degree, level, scale and values of the ciphertext.
+func (s *simulator) setOutput(ct *ckks.Ciphertext, degree, level int, scale float64, values []complex128) {
+	s.resize(ct, degree)
+	setLevel(ct, level)
+	ct.Scale = scale
+	s.setValues(ct.Value[0], values)
+}
+
+// getValues returns the scaled slot values stored on the polynomial.
+func (s *simulator) getValues(pol *ring.Poly) (values []complex128) {
+
+	values = make([]complex128, s.params.MaxSlots())
+
+	coeffs := pol.Coeffs[0]
+
+	if len(coeffs) < s.params.N() {
+		return
+	}
+
+	if s.params.RingType() == ring.ConjugateInvariant {
+		for i := range values {
+			values[i] = complex(math.Float64frombits(coeffs[i]), 0)
+		}
+	} else {
+		for i := range values {
+			values[i] = complex(math.Float64frombits(coeffs[2*i]), math.Float64frombits(coeffs[2*i+1]))
+		}
+	}
+
+	return
+}
+
+// setValues stores the scaled slot values on the polynomial.
+func (s *simulator) setValues(pol *ring.Poly, values []complex128) {
+
+	if len(pol.Coeffs[0]) < s.params.N() {
+		pol.Coeffs[0] = make([]uint64, s.params.N())
+	}
+
+	coeffs := pol.Coeffs[0]
+
+	if s.params.RingType() == ring.ConjugateInvariant {
+		for i := range values {
+			coeffs[i] = math.Float64bits(real(values[i]))
+		}
+	} else {
+		for i := range values {
+			coeffs[2*i] = math.Float64bits(real(values[i]))
+			coeffs[2*i+1] = math.Float64bits(imag(values[i]))
+		}
+	}
+}
+
+// replicate returns a vector of params.MaxSlots() values storing the input values, padded with zeros to
+// 2^logSlots values and replicated, as done by the sparse encoding of the ckks.Encoder.
+func (s *simulator) replicate(values []complex128, logSlots int) (res []complex128) {
+
+	slots := 1 << logSlots
+
+	if len(values) > slots {
+		panic("cannot encode: number of values is larger than 2^logSlots")
+	}
+
+	res = make([]complex128, s.params.MaxSlots())
+	for i := range res {
+		if j := i & (slots - 1); j < len(values) {
+			res[i] = values[j]
+			if s.params.RingType() == ring.ConjugateInvariant {
+				res[i] = complex(real(res[i]), 0)
+			}
+		}
+	}
+
+	return
+}
+
+// rotate returns the values rotated by k positions to the left.
+func rotate(values []complex128, k int) (res []complex128) {
+	n := len(values)
+	k = ((k % n) + n) % n
+	res = make([]complex128, n)
+	for i := range res {
+		res[i] = values[(i+k)%n]
+	}
+	return
+}
+
+// toComplex128 converts the input values to a []complex128.
+func toComplex128(values interface{}) (res []complex128) {
+	switch values := values.(type) {
+	case []complex128:
+		res = make([]complex128, len(values))
+		copy(res, values)
+	case []float64:
+		res = make([]complex128, len(values))
+		for i := range values {
+			res[i] = complex(values[i], 0)
+		}
+	default:
+		panic("cannot encode: values must be []complex128 or []float64")
+	}
+	return
+}
@@ -0,0 +1,157 @@
+package simulation
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tuneinsight/lattigo/v3/ckks"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+var testParams = ckks.ParametersLiteral{
+	LogN:         10,
+	LogQ:         []int{55, 40, 40, 40, 40, 40},
+	LogP:         []int{60},
+	DefaultScale: 1 << 40,
+	Sigma:        rlwe.DefaultSigma,
+}
+
+type testContext struct {
+	params    ckks.Parameters
+	encoder   ckks.Encoder
+	encryptor ckks.Encryptor
+	decryptor ckks.Decryptor
+	eval      ckks.Evaluator
+}
+
+var rotations = []int{1, -3}
+
+func newTestContext(t *testing.T, params ckks.Parameters, simulated, noise bool) *testContext {
+
+	if simulated {
+		sk := rlwe.NewSecretKey(params.Parameters)
+		return &testContext{
+			params:    params,
+			encoder:   NewEncoder(params, noise),
+			encryptor: NewEncryptor(params, sk, noise),
+			decryptor: NewDecryptor(params, sk),
+			eval:      NewEvaluator(params, NewEvaluationKey(params, rotations, true), noise),
+		}
+	}
+
+	kgen := ckks.NewKeyGenerator(params)
+	sk := kgen.GenSecretKey()
+	rlk := kgen.GenRelinearizationKey(sk, 1)
+	rtks := kgen.GenRotationKeysForRotations(rotations, true, sk)
+
+	return &testContext{
+		params:    params,
+		encoder:   ckks.NewEncoder(params),
+		encryptor: ckks.NewEncryptor(params, sk),
+		decryptor: ckks.NewDecryptor(params, sk),
+		eval:      ckks.NewEvaluator(params, rlwe.EvaluationKey{Rlk: rlk, Rtks: rtks}),
+	}
+}
+
+// evaluate computes a circuit exercising the scale and level management of the evaluator.
+func (tc *testContext) evaluate(t *testing.T, values []complex128) *ckks.Ciphertext {
+
+	params, eval := tc.params, tc.eval
+
+	ct := tc.encryptor.EncryptNew(tc.encoder.EncodeNew(values, params.MaxLevel(), params.DefaultScale(), params.LogSlots()))
+
+	tmp := eval.MulRelinNew(ct, ct)
+	require.NoError(t, eval.Rescale(tmp, params.DefaultScale(), tmp))
+
+	eval.Add(tmp, ct, tmp)
+	eval.MultByConst(tmp, 0.5, tmp)
+	eval.AddConst(tmp, complex(0.25, -0.125), tmp)
+	require.NoError(t, eval.Rescale(tmp, params.DefaultScale(), tmp))
+
+	eval.Rotate(tmp, 1, tmp)
+	eval.Conjugate(tmp, tmp)
+	eval.MultByi(tmp, tmp)
+
+	pol := ckks.NewPoly([]complex128{0.1, 0.5})
+	tmp, err := eval.EvaluatePoly(tmp, pol, params.DefaultScale())
+	require.NoError(t, err)
+
+	tmp, err = eval.EvaluateEncryptedPoly(tmp, ckks.NewEncryptedPoly([]*ckks.Ciphertext{ct, ct}), params.DefaultScale())
+	require.NoError(t, err)
+
+	res := tmp.CopyNew()
+	eval.MulRelinAndAdd(ct, tmp, res)
+
+	return res
+}
+
+func TestSimulation(t *testing.T) {
+
+	params, err := ckks.NewParametersFromLiteral(testParams)
+	require.NoError(t, err)
+
+	values := make([]complex128, params.Slots())
+	for i := range values {
+		values[i] = complex(utils.RandFloat64(-1, 1), utils.RandFloat64(-1, 1))
+	}
+
+	ref := newTestContext(t, params, false, false)
+	sim := newTestContext(t, params, true, false)
+	simNoise := newTestContext(t, params, true, true)
+
+	t.Run("Correctness", func(t *testing.T) {
+
+		ctReal := ref.evaluate(t, values)
+		ctSim := sim.evaluate(t, values)
+
+		require.Equal(t, ctReal.Level(), ctSim.Level())
+		require.Equal(t, ctReal.Degree(), ctSim.Degree())
+		require.Equal(t, ctReal.Scale, ctSim.Scale)
+
+		want := sim.encoder.Decode(sim.decryptor.DecryptNew(ctSim), params.LogSlots())
+
+		precStats := ckks.GetPrecisionStats(params, ref.encoder, ref.decryptor, want, ctReal, params.LogSlots(), 0)
+		require.GreaterOrEqual(t, precStats.MeanPrecision.L2, 20.0)
+	})
+
+	t.Run("Noise", func(t *testing.T) {
+
+		have := sim.encoder.Decode(sim.decryptor.DecryptNew(sim.evaluate(t, values)), params.LogSlots())
+		haveNoise := simNoise.encoder.Decode(simNoise.decryptor.DecryptNew(simNoise.evaluate(t, values)), params.LogSlots())
+		haveReal := ref.encoder.Decode(ref.decryptor.DecryptNew(ref.evaluate(t, values)), params.LogSlots())
+
+		var errNoise, errReal float64
+		for i := range have {
+			errNoise += real(have[i]-haveNoise[i])*real(have[i]-haveNoise[i]) + imag(have[i]-haveNoise[i])*imag(have[i]-haveNoise[i])
+			errReal += real(have[i]-haveReal[i])*real(have[i]-haveReal[i]) + imag(have[i]-haveReal[i])*imag(have[i]-haveReal[i])
+		}
+
+		require.NotZero(t, errNoise)
+
+		// The simulated error must be within a few bits of the error of the real scheme.
+		require.Less(t, math.Abs(math.Log2(errNoise/errReal)), 4.0)
+	})
+
+	t.Run("Constraints", func(t *testing.T) {
+
+		eval := NewEvaluator(params, rlwe.EvaluationKey{}, false)
+
+		ct := sim.encryptor.EncryptNew(sim.encoder.EncodeNew(values, 1, params.DefaultScale(), params.LogSlots()))
+
+		require.Panics(t, func() { eval.MulRelinNew(ct, ct) })
+		require.Panics(t, func() { eval.RotateNew(ct, 1) })
+		require.Panics(t, func() { sim.eval.RotateNew(ct, 2) })
+		require.Panics(t, func() { sim.eval.RotateNew(eval.MulNew(ct, ct), 1) })
+		require.NotPanics(t, func() { sim.eval.RotateNew(ct, 1) })
+
+		ct = eval.MulNew(ct, ct)
+		require.NoError(t, eval.Rescale(ct, params.DefaultScale(), ct))
+		require.Equal(t, 0, ct.Level())
+		require.Error(t, eval.Rescale(ct, params.DefaultScale(), ct))
+
+		_, err := eval.EvaluatePoly(ct, ckks.NewPoly([]complex128{0, 1}), params.DefaultScale())
+		require.Error(t, err)
+	})
+}
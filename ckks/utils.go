@@ -3,6 +3,7 @@ package ckks
 import (
 	"math"
 	"math/big"
+	"math/bits"
 
 	"github.com/tuneinsight/lattigo/v3/ring"
 )
@@ -182,42 +183,56 @@ func singleFloatToFixedPointCRT(level, i int, value float64, scale float64, ring
 
 func scaleUpExact(value float64, n float64, q uint64) (res uint64) {
 
+	x := n * value
+
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		panic("cannot scaleUpExact: value * n is not finite")
+	}
+
+	return floatIntMod(math.Round(x), q)
+}
+
+// floatIntMod returns x mod q in [0, q) for an integer-valued and finite x.
+func floatIntMod(x float64, q uint64) (res uint64) {
+
 	var isNegative bool
-	var xFlo *big.Float
-	var xInt *big.Int
 
-	isNegative = false
-	if value < 0 {
+	if x < 0 {
 		isNegative = true
-		xFlo = big.NewFloat(-n * value)
-	} else {
-		xFlo = big.NewFloat(n * value)
+		x = -x
 	}
 
-	xFlo.Add(xFlo, big.NewFloat(0.5))
+	if x < 0x1p64 {
+		res = uint64(x) % q
+	} else {
 
-	xInt = new(big.Int)
-	xFlo.Int(xInt)
-	xInt.Mod(xInt, ring.NewUint(q))
+		// x = mant * 2^shift with mant < 2^53
+		frac, exp := math.Frexp(x)
+		res = uint64(math.Ldexp(frac, 53)) % q
 
-	res = xInt.Uint64()
+		for shift := exp - 53; shift > 0; {
+			s := shift
+			if s > 63 {
+				s = 63
+			}
+			res = bits.Rem64(res>>(64-s), res<<s, q)
+			shift -= s
+		}
+	}
 
-	if isNegative {
+	if isNegative && res != 0 {
 		res = q - res
 	}
 
 	return
 }
 
-func scaleUpVecExactBigFloat(values []*big.Float, scale float64, moduli []uint64, coeffs [][]uint64) {
+func scaleUpVecExactBigFloat(values []*big.Float, scale float64, level int, crt *ring.CRT, v *ring.BigintVec, p *ring.Poly) {
 
 	prec := int(values[0].Prec())
 
 	xFlo := ring.NewFloat(0, prec)
 	xInt := new(big.Int)
-	tmp := new(big.Int)
-
-	zero := ring.NewFloat(0, prec)
 
 	scaleFlo := ring.NewFloat(scale, prec)
 	half := ring.NewFloat(0.5, prec)
@@ -226,7 +241,7 @@ func scaleUpVecExactBigFloat(values []*big.Float, scale float64, moduli []uint64
 
 		xFlo.Mul(scaleFlo, values[i])
 
-		if values[i].Cmp(zero) < 0 {
+		if values[i].Sign() < 0 {
 			xFlo.Sub(xFlo, half)
 		} else {
 			xFlo.Add(xFlo, half)
@@ -234,23 +249,36 @@ func scaleUpVecExactBigFloat(values []*big.Float, scale float64, moduli []uint64
 
 		xFlo.Int(xInt)
 
-		for j := range moduli {
+		v.Set(i, xInt)
+	}
+
+	crt.Decompose(level, v, p)
+}
 
-			Q := ring.NewUint(moduli[j])
+// SliceBitReverseInPlaceComplex128 applies an in-place bit-reverse permuation on the input slice.
+func SliceBitReverseInPlaceComplex128(slice []complex128, N int) {
 
-			tmp.Mod(xInt, Q)
+	var bit, j int
 
-			if values[i].Cmp(zero) < 0 {
-				tmp.Add(tmp, Q)
-			}
+	for i := 1; i < N; i++ {
+
+		bit = N >> 1
 
-			coeffs[j][i] = tmp.Uint64()
+		for j >= bit {
+			j -= bit
+			bit >>= 1
+		}
+
+		j += bit
+
+		if i < j {
+			slice[i], slice[j] = slice[j], slice[i]
 		}
 	}
 }
 
-// SliceBitReverseInPlaceComplex128 applies an in-place bit-reverse permuation on the input slice.
-func SliceBitReverseInPlaceComplex128(slice []complex128, N int) {
+// SliceBitReverseInPlaceComplexDoubleDouble applies an in-place bit-reverse permuation on the input slice.
+func SliceBitReverseInPlaceComplexDoubleDouble(slice []ComplexDoubleDouble, N int) {
 
 	var bit, j int
 
@@ -0,0 +1,70 @@
+package dbfv
+
+import (
+	"github.com/tuneinsight/lattigo/v3/bfv"
+	"github.com/tuneinsight/lattigo/v3/drlwe"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// CollectiveDecryptProtocol is the protocol for the collective decryption of a BFV ciphertext, either publicly or to a
+// designated receiver. See drlwe.CollectiveDecryptProtocol.
+type CollectiveDecryptProtocol struct {
+	drlwe.CollectiveDecryptProtocol
+	params  bfv.Parameters
+	encoder bfv.Encoder
+}
+
+// NewCollectiveDecryptProtocol creates a new CollectiveDecryptProtocol instance. The output is only decryptable by the owner
+// of the secret key of receiver or, if receiver is nil, publicly.
+func NewCollectiveDecryptProtocol(params bfv.Parameters, receiver *rlwe.PublicKey, sigmaSmudging float64) *CollectiveDecryptProtocol {
+	return &CollectiveDecryptProtocol{*drlwe.NewCollectiveDecryptProtocol(params.Parameters, receiver, sigmaSmudging), params, bfv.NewEncoder(params)}
+}
+
+// ShallowCopy creates a shallow copy of CollectiveDecryptProtocol in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// CollectiveDecryptProtocol can be used concurrently.
+func (cdp *CollectiveDecryptProtocol) ShallowCopy() *CollectiveDecryptProtocol {
+	return &CollectiveDecryptProtocol{*cdp.CollectiveDecryptProtocol.ShallowCopy(), cdp.params, cdp.encoder.ShallowCopy()}
+}
+
+// WithPRNG creates a shallow copy of CollectiveDecryptProtocol whose samplers draw their randomness from prng, making the
+// generated shares reproducible from the seed of prng. If prng is nil, the randomness is drawn from crypto/rand.
+func (cdp *CollectiveDecryptProtocol) WithPRNG(prng utils.PRNG) *CollectiveDecryptProtocol {
+	return &CollectiveDecryptProtocol{*cdp.CollectiveDecryptProtocol.WithPRNG(prng), cdp.params, cdp.encoder.ShallowCopy()}
+}
+
+// AllocateShare allocates the share of one party in the CollectiveDecrypt protocol for BFV.
+func (cdp *CollectiveDecryptProtocol) AllocateShare() *drlwe.CollectiveDecryptShare {
+	return cdp.CollectiveDecryptProtocol.AllocateShare(cdp.params.MaxLevel())
+}
+
+// GenShare generates the share of a party, with secret key sk, for the ciphertext ct.
+func (cdp *CollectiveDecryptProtocol) GenShare(sk *rlwe.SecretKey, ct *bfv.Ciphertext, shareOut *drlwe.CollectiveDecryptShare) {
+	cdp.CollectiveDecryptProtocol.GenShare(sk, ct.Ciphertext, shareOut)
+}
+
+// KeySwitch applies the aggregated share to ct and writes the result on ctOut.
+func (cdp *CollectiveDecryptProtocol) KeySwitch(ct *bfv.Ciphertext, aggregate *drlwe.CollectiveDecryptShare, ctOut *bfv.Ciphertext) {
+	cdp.CollectiveDecryptProtocol.KeySwitch(ct.Ciphertext, aggregate, ctOut.Ciphertext)
+}
+
+// DecryptNew applies the aggregated share to ct and returns the plaintext decrypted with the secret key of the receiver.
+// For a public decryption, skReceiver is ignored and can be nil.
+func (cdp *CollectiveDecryptProtocol) DecryptNew(skReceiver *rlwe.SecretKey, ct *bfv.Ciphertext, aggregate *drlwe.CollectiveDecryptShare) (pt *bfv.Plaintext) {
+	pt = bfv.NewPlaintext(cdp.params)
+	cdp.CollectiveDecryptProtocol.Decrypt(skReceiver, ct.Ciphertext, aggregate, pt.Plaintext)
+	return
+}
+
+// DecodeUintNew applies the aggregated share to ct, decrypts the result with the secret key of the receiver and returns
+// the decoded coefficients. For a public decryption, skReceiver is ignored and can be nil.
+func (cdp *CollectiveDecryptProtocol) DecodeUintNew(skReceiver *rlwe.SecretKey, ct *bfv.Ciphertext, aggregate *drlwe.CollectiveDecryptShare) (coeffs []uint64) {
+	return cdp.encoder.DecodeUintNew(cdp.DecryptNew(skReceiver, ct, aggregate))
+}
+
+// DecodeIntNew applies the aggregated share to ct, decrypts the result with the secret key of the receiver and returns
+// the decoded coefficients as signed integers. For a public decryption, skReceiver is ignored and can be nil.
+func (cdp *CollectiveDecryptProtocol) DecodeIntNew(skReceiver *rlwe.SecretKey, ct *bfv.Ciphertext, aggregate *drlwe.CollectiveDecryptShare) (coeffs []int64) {
+	return cdp.encoder.DecodeIntNew(cdp.DecryptNew(skReceiver, ct, aggregate))
+}
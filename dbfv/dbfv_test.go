@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"math"
 	"math/big"
 	"runtime"
 	"testing"
@@ -87,12 +88,16 @@ func Test_DBFV(t *testing.T) {
 
 			testPublicKeyGen,
 			testRelinKeyGen,
+			testPKRelinKeyGen,
 			testKeyswitching,
 			testPublicKeySwitching,
+			testCollectiveDecrypt,
+			testKeyRotation,
 			testRotKeyGenRotRows,
 			testRotKeyGenRotCols,
 			testEncToShares,
 			testRefresh,
+			testRefreshWithSmudging,
 			testRefreshAndPermutation,
 			testMarshalling,
 		} {
@@ -268,6 +273,63 @@ func testRelinKeyGen(testCtx *testContext, t *testing.T) {
 
 }
 
+func testPKRelinKeyGen(testCtx *testContext, t *testing.T) {
+
+	sk0Shards := testCtx.sk0Shards
+	encryptorPk0 := testCtx.encryptorPk0
+	decryptorSk0 := testCtx.decryptorSk0
+
+	t.Run(testString("PKRelinKeyGen", parties, testCtx.params), func(t *testing.T) {
+
+		type Party struct {
+			*PKRKGProtocol
+			sk    *rlwe.SecretKey
+			share *drlwe.PKRKGShare
+		}
+
+		rkgParties := make([]*Party, parties)
+
+		for i := range rkgParties {
+			p := new(Party)
+			p.PKRKGProtocol = NewPKRKGProtocol(testCtx.params)
+			p.sk = sk0Shards[i]
+			p.share = p.AllocateShare()
+			rkgParties[i] = p
+		}
+
+		P0 := rkgParties[0]
+
+		crp := P0.SampleCRP(testCtx.crs)
+
+		// Single round
+		for i, p := range rkgParties {
+			p.GenShare(p.sk, crp, p.share)
+			if i > 0 {
+				P0.AggregateShare(p.share, P0.share, P0.share)
+			}
+		}
+
+		rlk := drlwe.NewPKRelinearizationKey(testCtx.params.Parameters)
+		P0.GenRelinearizationKey(P0.share, crp, rlk)
+
+		coeffs, _, ciphertext := newTestVectors(testCtx, encryptorPk0, t)
+		for i := range coeffs {
+			coeffs[i] *= coeffs[i]
+			coeffs[i] %= testCtx.ringT.Modulus[0]
+		}
+
+		ciphertextMul := bfv.NewCiphertext(testCtx.params, ciphertext.Degree()*2)
+		testCtx.evaluator.Mul(ciphertext, ciphertext, ciphertextMul)
+
+		res := bfv.NewCiphertext(testCtx.params, 1)
+		NewPKRelinearizer(testCtx.params, rlk).Relinearize(ciphertextMul, res)
+
+		require.Equal(t, 1, res.Degree())
+
+		verifyTestVectors(testCtx, decryptorSk0, coeffs, res, t)
+	})
+}
+
 func testKeyswitching(testCtx *testContext, t *testing.T) {
 
 	sk0Shards := testCtx.sk0Shards
@@ -365,6 +427,82 @@ func testPublicKeySwitching(testCtx *testContext, t *testing.T) {
 	})
 }
 
+func testCollectiveDecrypt(testCtx *testContext, t *testing.T) {
+
+	for _, receiver := range []*rlwe.PublicKey{testCtx.pk1, nil} {
+
+		name := "CollectiveDecrypt/Receiver"
+		skReceiver := testCtx.sk1
+		if receiver == nil {
+			name, skReceiver = "CollectiveDecrypt/Public", nil
+		}
+
+		t.Run(testString(name, parties, testCtx.params), func(t *testing.T) {
+
+			cdp := NewCollectiveDecryptProtocol(testCtx.params, receiver, 6.36)
+			require.Equal(t, receiver == nil, cdp.Public())
+
+			coeffs, _, ciphertext := newTestVectors(testCtx, testCtx.encryptorPk0, t)
+
+			seed := []byte{'d', 'i', 'g', 'e', 's', 't'}
+			nbPoints := 2
+
+			shares := make([]*drlwe.CollectiveDecryptShare, parties)
+			digests := make([]*drlwe.ShareDigest, parties)
+			agg := cdp.AllocateShare()
+			for i := range shares {
+				shares[i] = cdp.AllocateShare()
+				cdp.GenShare(testCtx.sk0Shards[i], ciphertext, shares[i])
+				digests[i] = cdp.GenShareDigest(seed, nbPoints, shares[i])
+				cdp.AggregateShare(shares[i], agg, agg)
+			}
+
+			require.NoError(t, cdp.VerifyAggregate(seed, nbPoints, agg, digests))
+			require.True(t, utils.EqualSliceUint64(coeffs, cdp.DecodeUintNew(skReceiver, ciphertext, agg)))
+
+			// An aggregator dropping a share is detected by the receiver
+			cdp.AggregateShare(shares[0], shares[1], agg)
+			require.Error(t, cdp.VerifyAggregate(seed, nbPoints, agg, digests))
+		})
+	}
+}
+
+func testKeyRotation(testCtx *testContext, t *testing.T) {
+
+	params := testCtx.params
+
+	t.Run(testString("KeyRotation", parties, params), func(t *testing.T) {
+
+		// The first party leaves, the other ones stay and a new party joins
+		kgen := bfv.NewKeyGenerator(params)
+		skOld := append(testCtx.sk0Shards, nil)
+		skNew := make([]*rlwe.SecretKey, parties+1)
+		skNewIdeal := bfv.NewSecretKey(params)
+		ringQP, levelQ, levelP := params.RingQP(), params.QCount()-1, params.PCount()-1
+		for i := 1; i < len(skNew); i++ {
+			skNew[i] = kgen.GenSecretKey()
+			ringQP.AddLvl(levelQ, levelP, skNewIdeal.Value, skNew[i].Value, skNewIdeal.Value)
+		}
+
+		krp := NewKeyRotationProtocol(params, 3.2)
+
+		coeffs, _, ciphertext := newTestVectors(testCtx, testCtx.encryptorPk0, t)
+
+		share, agg := krp.AllocateShare(), krp.AllocateShare()
+		for i := range skNew {
+			krp.GenShare(skOld[i], skNew[i], ciphertext.Value[1], share)
+			krp.AggregateShare(share, agg, agg)
+		}
+
+		ctRotated := bfv.NewCiphertext(params, 1)
+		krp.KeySwitch(ciphertext, agg, ctRotated)
+
+		verifyTestVectors(testCtx, bfv.NewDecryptor(params, skNewIdeal), coeffs, ctRotated, t)
+
+		require.Panics(t, func() { krp.GenShare(nil, nil, ciphertext.Value[1], share) })
+	})
+}
+
 func testRotKeyGenRotRows(testCtx *testContext, t *testing.T) {
 
 	encryptorPk0 := testCtx.encryptorPk0
@@ -651,6 +789,44 @@ func testRefresh(testCtx *testContext, t *testing.T) {
 	})
 }
 
+func testRefreshWithSmudging(testCtx *testContext, t *testing.T) {
+
+	t.Run(testString("RefreshWithSmudging", parties, testCtx.params), func(t *testing.T) {
+
+		// A fresh ciphertext has a noise bounded by about 2^5
+		sp := NewSmudgingParameters(testCtx.params, parties, 5, 20)
+
+		sigma, _, ok := sp.Estimate()
+		require.True(t, ok)
+		require.Equal(t, math.Exp2(25), sigma)
+
+		rfp, err := NewRefreshProtocolWithSmudging(testCtx.params, sp)
+		require.NoError(t, err)
+
+		crp := rfp.SampleCRP(testCtx.params.MaxLevel(), testCtx.crs)
+		coeffs, _, ciphertext := newTestVectors(testCtx, testCtx.encryptorPk0, t)
+
+		share, agg := rfp.AllocateShare(), rfp.AllocateShare()
+		for i := 0; i < parties; i++ {
+			rfp.GenShare(testCtx.sk0Shards[i], ciphertext.Value[1], crp, share)
+			if i == 0 {
+				rfp.GenShare(testCtx.sk0Shards[i], ciphertext.Value[1], crp, agg)
+			} else {
+				rfp.Aggregate(share, agg, agg)
+			}
+		}
+
+		ctRes := bfv.NewCiphertext(testCtx.params, 1)
+		rfp.Finalize(ciphertext, crp, agg, ctRes)
+
+		verifyTestVectors(testCtx, testCtx.decryptorSk0, coeffs, ctRes, t)
+
+		// Not enough room for a noise of Q
+		_, err = NewRefreshProtocolWithSmudging(testCtx.params, NewSmudgingParameters(testCtx.params, parties, float64(testCtx.params.LogQ()), 30))
+		require.Error(t, err)
+	})
+}
+
 func testRefreshAndPermutation(testCtx *testContext, t *testing.T) {
 
 	encryptorPk0 := testCtx.encryptorPk0
@@ -4,6 +4,7 @@ package dbfv
 import (
 	"github.com/tuneinsight/lattigo/v3/bfv"
 	"github.com/tuneinsight/lattigo/v3/drlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
 )
 
 // CKGProtocol is the structure storing the parameters and state for a party in the collective key generation protocol.
@@ -23,6 +24,12 @@ func (ckg *CKGProtocol) ShallowCopy() *CKGProtocol {
 	return &CKGProtocol{*ckg.CKGProtocol.ShallowCopy()}
 }
 
+// WithPRNG creates a shallow copy of CKGProtocol whose samplers draw their randomness from prng, making the
+// generated shares reproducible from the seed of prng. If prng is nil, the randomness is drawn from crypto/rand.
+func (ckg *CKGProtocol) WithPRNG(prng utils.PRNG) *CKGProtocol {
+	return &CKGProtocol{*ckg.CKGProtocol.WithPRNG(prng)}
+}
+
 // RKGProtocol is the structure storing the parameters and state for a party in the collective relinearization key
 // generation protocol.
 type RKGProtocol struct {
@@ -42,6 +49,12 @@ func (rkg *RKGProtocol) ShallowCopy() *RKGProtocol {
 	return &RKGProtocol{*rkg.RKGProtocol.ShallowCopy()}
 }
 
+// WithPRNG creates a shallow copy of RKGProtocol whose samplers draw their randomness from prng, making the
+// generated shares reproducible from the seed of prng. If prng is nil, the randomness is drawn from crypto/rand.
+func (rkg *RKGProtocol) WithPRNG(prng utils.PRNG) *RKGProtocol {
+	return &RKGProtocol{*rkg.RKGProtocol.WithPRNG(prng)}
+}
+
 // RTGProtocol is the structure storing the parameters for the collective rotation-keys generation.
 type RTGProtocol struct {
 	drlwe.RTGProtocol
@@ -58,3 +71,80 @@ func NewRotKGProtocol(params bfv.Parameters) (rtg *RTGProtocol) {
 func (rtg *RTGProtocol) ShallowCopy() *RTGProtocol {
 	return &RTGProtocol{*rtg.RTGProtocol.ShallowCopy()}
 }
+
+// WithPRNG creates a shallow copy of RTGProtocol whose samplers draw their randomness from prng, making the
+// generated shares reproducible from the seed of prng. If prng is nil, the randomness is drawn from crypto/rand.
+func (rtg *RTGProtocol) WithPRNG(prng utils.PRNG) *RTGProtocol {
+	return &RTGProtocol{*rtg.RTGProtocol.WithPRNG(prng)}
+}
+
+// EKGProtocol is the structure storing the parameters for the collective generation, in two rounds, of a relinearization
+// key and of the rotation keys of a list of Galois elements.
+type EKGProtocol struct {
+	drlwe.EKGProtocol
+}
+
+// NewEKGProtocol creates a new EKGProtocol instance generating the rotation keys for the Galois elements galEls
+// and, if relin is true, the relinearization key.
+func NewEKGProtocol(params bfv.Parameters, galEls []uint64, relin bool) *EKGProtocol {
+	return &EKGProtocol{*drlwe.NewEKGProtocol(params.Parameters, galEls, relin)}
+}
+
+// ShallowCopy creates a shallow copy of EKGProtocol in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// EKGProtocol can be used concurrently.
+func (ekg *EKGProtocol) ShallowCopy() *EKGProtocol {
+	return &EKGProtocol{*ekg.EKGProtocol.ShallowCopy()}
+}
+
+// WithPRNG creates a shallow copy of EKGProtocol whose samplers draw their randomness from prng, making the
+// generated shares reproducible from the seed of prng. If prng is nil, the randomness is drawn from crypto/rand.
+func (ekg *EKGProtocol) WithPRNG(prng utils.PRNG) *EKGProtocol {
+	return &EKGProtocol{*ekg.EKGProtocol.WithPRNG(prng)}
+}
+
+// PKRKGProtocol is the structure storing the parameters and state for a party in the one-round collective generation
+// of a public-key relinearization key.
+type PKRKGProtocol struct {
+	drlwe.PKRKGProtocol
+}
+
+// NewPKRKGProtocol creates a new PKRKGProtocol instance.
+func NewPKRKGProtocol(params bfv.Parameters) *PKRKGProtocol {
+	return &PKRKGProtocol{*drlwe.NewPKRKGProtocol(params.Parameters)}
+}
+
+// ShallowCopy creates a shallow copy of PKRKGProtocol in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// PKRKGProtocol can be used concurrently.
+func (rkg *PKRKGProtocol) ShallowCopy() *PKRKGProtocol {
+	return &PKRKGProtocol{*rkg.PKRKGProtocol.ShallowCopy()}
+}
+
+// WithPRNG creates a shallow copy of PKRKGProtocol whose samplers draw their randomness from prng, making the
+// generated shares reproducible from the seed of prng. If prng is nil, the randomness is drawn from crypto/rand.
+func (rkg *PKRKGProtocol) WithPRNG(prng utils.PRNG) *PKRKGProtocol {
+	return &PKRKGProtocol{*rkg.PKRKGProtocol.WithPRNG(prng)}
+}
+
+// PKRelinearizer relinearizes bfv ciphertexts with a public-key relinearization key.
+type PKRelinearizer struct {
+	*drlwe.PKRelinearizer
+}
+
+// NewPKRelinearizer creates a new PKRelinearizer from a public-key relinearization key.
+func NewPKRelinearizer(params bfv.Parameters, rlk *drlwe.PKRelinearizationKey) *PKRelinearizer {
+	return &PKRelinearizer{drlwe.NewPKRelinearizer(params.Parameters, rlk)}
+}
+
+// ShallowCopy creates a shallow copy of PKRelinearizer in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// PKRelinearizer can be used concurrently.
+func (r *PKRelinearizer) ShallowCopy() *PKRelinearizer {
+	return &PKRelinearizer{r.PKRelinearizer.ShallowCopy()}
+}
+
+// Relinearize relinearizes the degree-2 ciphertext ctIn and writes the result on ctOut.
+func (r *PKRelinearizer) Relinearize(ctIn, ctOut *bfv.Ciphertext) {
+	r.PKRelinearizer.Relinearize(ctIn.Ciphertext, ctOut.Ciphertext)
+}
@@ -3,6 +3,7 @@ package dbfv
 import (
 	"github.com/tuneinsight/lattigo/v3/bfv"
 	"github.com/tuneinsight/lattigo/v3/drlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
 )
 
 // CKSProtocol is a structure storing the parameters for the collective key-switching protocol.
@@ -35,6 +36,12 @@ func (cks *CKSProtocol) ShallowCopy() *CKSProtocol {
 	return &CKSProtocol{*cks.CKSProtocol.ShallowCopy(), cks.maxLevel}
 }
 
+// WithPRNG creates a shallow copy of CKSProtocol whose samplers draw their randomness from prng, making the
+// generated shares reproducible from the seed of prng. If prng is nil, the randomness is drawn from crypto/rand.
+func (cks *CKSProtocol) WithPRNG(prng utils.PRNG) *CKSProtocol {
+	return &CKSProtocol{*cks.CKSProtocol.WithPRNG(prng), cks.maxLevel}
+}
+
 // PCKSProtocol is the structure storing the parameters for the collective public key-switching.
 type PCKSProtocol struct {
 	drlwe.PCKSProtocol
@@ -63,3 +70,44 @@ func (pcks *PCKSProtocol) KeySwitch(ctIn *bfv.Ciphertext, combined *drlwe.PCKSSh
 func (pcks *PCKSProtocol) ShallowCopy() *PCKSProtocol {
 	return &PCKSProtocol{*pcks.PCKSProtocol.ShallowCopy(), pcks.maxLevel}
 }
+
+// WithPRNG creates a shallow copy of PCKSProtocol whose samplers draw their randomness from prng, making the
+// generated shares reproducible from the seed of prng. If prng is nil, the randomness is drawn from crypto/rand.
+func (pcks *PCKSProtocol) WithPRNG(prng utils.PRNG) *PCKSProtocol {
+	return &PCKSProtocol{*pcks.PCKSProtocol.WithPRNG(prng), pcks.maxLevel}
+}
+
+// KeyRotationProtocol is the structure storing the parameters for the rotation of a collective key. See drlwe.KeyRotationProtocol.
+type KeyRotationProtocol struct {
+	drlwe.KeyRotationProtocol
+	maxLevel int
+}
+
+// NewKeyRotationProtocol creates a new KeyRotationProtocol that will be used to switch the ciphertexts encrypted under the collective
+// key of an old committee to the collective key of a new committee.
+func NewKeyRotationProtocol(params bfv.Parameters, sigmaSmudging float64) *KeyRotationProtocol {
+	return &KeyRotationProtocol{*drlwe.NewKeyRotationProtocol(params.Parameters, sigmaSmudging), params.MaxLevel()}
+}
+
+// AllocateShare allocates the shares of one party in the KeyRotation protocol for BFV.
+func (krp *KeyRotationProtocol) AllocateShare() *drlwe.CKSShare {
+	return krp.KeyRotationProtocol.AllocateShare(krp.maxLevel)
+}
+
+// KeySwitch performs the actual keyswitching operation on a ciphertext ct and put the result in ctOut.
+func (krp *KeyRotationProtocol) KeySwitch(ctIn *bfv.Ciphertext, combined *drlwe.CKSShare, ctOut *bfv.Ciphertext) {
+	krp.KeyRotationProtocol.KeySwitch(ctIn.Ciphertext, combined, ctOut.Ciphertext)
+}
+
+// ShallowCopy creates a shallow copy of KeyRotationProtocol in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// KeyRotationProtocol can be used concurrently.
+func (krp *KeyRotationProtocol) ShallowCopy() *KeyRotationProtocol {
+	return &KeyRotationProtocol{*krp.KeyRotationProtocol.ShallowCopy(), krp.maxLevel}
+}
+
+// WithPRNG creates a shallow copy of KeyRotationProtocol whose samplers draw their randomness from prng, making the
+// generated shares reproducible from the seed of prng. If prng is nil, the randomness is drawn from crypto/rand.
+func (krp *KeyRotationProtocol) WithPRNG(prng utils.PRNG) *KeyRotationProtocol {
+	return &KeyRotationProtocol{*krp.KeyRotationProtocol.WithPRNG(prng), krp.maxLevel}
+}
@@ -0,0 +1,243 @@
+// Package pir implements a multiparty private-information-retrieval (PIR) protocol based on the dbfv package, as described in
+// "Multiparty Homomorphic Encryption: From Theory to Practice" (<https://eprint.iacr.org/2020/304>).
+//
+// A server stores a database of rows, each of them a vector of plaintext slots encrypted under the collective public key of
+// a set of parties. A client retrieves one row without disclosing its index to the server or to the parties: it encrypts a
+// one-hot encoding of the index under the collective public key, the server obliviously selects the row with homomorphic
+// operations, and the selected row is then decrypted to the client with the dbfv.CollectiveDecryptProtocol.
+//
+// The server requires the collective relinearization key and the collective rotation keys for the Galois elements returned
+// by GaloisElements.
+package pir
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/tuneinsight/lattigo/v3/bfv"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+// GaloisElements returns the Galois elements of the rotation keys required by the Server.
+func GaloisElements(params bfv.Parameters) (galEls []uint64) {
+	return params.GaloisElementsForRowInnerSum()
+}
+
+// Query is an encrypted PIR query. The database is split in chunks of params.N() rows and the query stores one ciphertext
+// per chunk, which encrypts the one-hot encoding of the index of the row within its chunk.
+type Query struct {
+	Value []*bfv.Ciphertext
+}
+
+// MarshalBinary encodes the query on a slice of bytes.
+func (q *Query) MarshalBinary() (data []byte, err error) {
+
+	data = make([]byte, 4)
+	binary.LittleEndian.PutUint32(data, uint32(len(q.Value)))
+
+	for _, ct := range q.Value {
+		var ctData []byte
+		if ctData, err = ct.MarshalBinary(); err != nil {
+			return nil, err
+		}
+		data = append(data, make([]byte, 4)...)
+		binary.LittleEndian.PutUint32(data[len(data)-4:], uint32(len(ctData)))
+		data = append(data, ctData...)
+	}
+
+	return data, nil
+}
+
+// UnmarshalBinary decodes a slice of bytes on the query.
+func (q *Query) UnmarshalBinary(data []byte) (err error) {
+
+	if len(data) < 4 {
+		return errors.New("cannot UnmarshalBinary: data is too short")
+	}
+
+	// Each ciphertext is prefixed by its length on 4 bytes, which bounds the number of ciphertexts
+	// before allocating them.
+	if count := uint64(binary.LittleEndian.Uint32(data)); count > uint64(len(data)-4)/4 {
+		return fmt.Errorf("cannot UnmarshalBinary: data is too short for %d ciphertexts", count)
+	}
+
+	q.Value = make([]*bfv.Ciphertext, binary.LittleEndian.Uint32(data))
+
+	ptr := 4
+	for i := range q.Value {
+
+		if len(data[ptr:]) < 4 {
+			return errors.New("cannot UnmarshalBinary: data is too short")
+		}
+
+		ctLen := int(binary.LittleEndian.Uint32(data[ptr:]))
+		ptr += 4
+
+		if len(data[ptr:]) < ctLen {
+			return errors.New("cannot UnmarshalBinary: data is too short")
+		}
+
+		q.Value[i] = new(bfv.Ciphertext)
+		if err = q.Value[i].UnmarshalBinary(data[ptr : ptr+ctLen]); err != nil {
+			return err
+		}
+		ptr += ctLen
+	}
+
+	return nil
+}
+
+// Client is the party retrieving a row of the database.
+type Client struct {
+	params    bfv.Parameters
+	encoder   bfv.Encoder
+	encryptor bfv.Encryptor
+}
+
+// NewClient creates a new Client encrypting its queries under the collective public key pk.
+func NewClient(params bfv.Parameters, pk *rlwe.PublicKey) *Client {
+	return &Client{params: params, encoder: bfv.NewEncoder(params), encryptor: bfv.NewEncryptor(params, pk)}
+}
+
+// GenQuery returns the query for the row index of a database of nbRows rows.
+// It returns an error if index is not a valid row index.
+func (c *Client) GenQuery(index, nbRows int) (q *Query, err error) {
+
+	if index < 0 || index >= nbRows {
+		return nil, fmt.Errorf("cannot GenQuery: index %d is out of range for %d rows", index, nbRows)
+	}
+
+	N := c.params.N()
+	q = &Query{Value: make([]*bfv.Ciphertext, (nbRows+N-1)/N)}
+
+	coeffs := make([]uint64, N)
+	pt := bfv.NewPlaintext(c.params)
+	for i := range q.Value {
+		for j := range coeffs {
+			coeffs[j] = 0
+		}
+		if i == index/N {
+			coeffs[index%N] = 1
+		}
+		c.encoder.EncodeUint(coeffs, pt)
+		q.Value[i] = c.encryptor.EncryptNew(pt)
+	}
+
+	return q, nil
+}
+
+// Server is the party storing the encrypted database and answering the queries.
+type Server struct {
+	params    bfv.Parameters
+	evaluator bfv.Evaluator
+	rows      []*bfv.Ciphertext
+	masks     []*bfv.PlaintextMul
+}
+
+// NewServer creates a new Server for the encrypted database rows, from the collective relinearization and rotation keys in evk.
+func NewServer(params bfv.Parameters, evk rlwe.EvaluationKey, rows []*bfv.Ciphertext) *Server {
+
+	encoder := bfv.NewEncoder(params)
+
+	// masks[i] = encode([0, ..., 0, 1_i, 0, ..., 0])
+	masks := make([]*bfv.PlaintextMul, len(rows))
+	if len(masks) > params.N() {
+		masks = masks[:params.N()]
+	}
+
+	coeffs := make([]uint64, params.N())
+	for i := range masks {
+		coeffs[i] = 1
+		masks[i] = bfv.NewPlaintextMul(params)
+		encoder.EncodeUintMul(coeffs, masks[i])
+		coeffs[i] = 0
+	}
+
+	return &Server{params: params, evaluator: bfv.NewEvaluator(params, evk), rows: rows, masks: masks}
+}
+
+// ShallowCopy creates a shallow copy of Server in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// Server can be used concurrently.
+func (s *Server) ShallowCopy() *Server {
+	return &Server{params: s.params, evaluator: s.evaluator.ShallowCopy(), rows: s.rows, masks: s.masks}
+}
+
+// NbRows returns the number of rows of the database.
+func (s *Server) NbRows() int {
+	return len(s.rows)
+}
+
+// NbChunks returns the number of chunks of params.N() rows of the database, i.e. the number of ciphertexts of its queries.
+func (s *Server) NbChunks() int {
+	N := s.params.N()
+	return (len(s.rows) + N - 1) / N
+}
+
+// UnmarshalQuery decodes a query received by the Server. It returns an error if the number of ciphertexts of the
+// query does not match the number of chunks of the database, without decoding them, or if a ciphertext is not a
+// degree-1 ciphertext at the maximum level.
+func (s *Server) UnmarshalQuery(data []byte) (q *Query, err error) {
+
+	if len(data) < 4 {
+		return nil, errors.New("cannot UnmarshalQuery: data is too short")
+	}
+
+	if count := binary.LittleEndian.Uint32(data); uint64(count) != uint64(s.NbChunks()) {
+		return nil, fmt.Errorf("cannot UnmarshalQuery: query of %d ciphertexts for %d chunks", count, s.NbChunks())
+	}
+
+	q = new(Query)
+	if err = q.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+
+	for i, ct := range q.Value {
+		if ct.Degree() != 1 {
+			return nil, fmt.Errorf("cannot UnmarshalQuery: ciphertext %d is of degree %d", i, ct.Degree())
+		}
+		for _, pol := range ct.Value {
+			if pol.LenModuli() != s.params.QCount() {
+				return nil, fmt.Errorf("cannot UnmarshalQuery: ciphertext %d is not at the maximum level", i)
+			}
+			for _, coeffs := range pol.Coeffs {
+				if len(coeffs) != s.params.N() {
+					return nil, fmt.Errorf("cannot UnmarshalQuery: ciphertext %d is not of degree N=%d", i, s.params.N())
+				}
+			}
+		}
+	}
+
+	return q, nil
+}
+
+// AnswerNew returns the answer to the query, i.e. the requested row encrypted under the collective public key.
+// It returns an error if the query does not match the size of the database.
+func (s *Server) AnswerNew(q *Query) (ct *bfv.Ciphertext, err error) {
+
+	N := s.params.N()
+
+	if len(q.Value) != s.NbChunks() {
+		return nil, fmt.Errorf("cannot AnswerNew: query of %d ciphertexts for %d rows", len(q.Value), len(s.rows))
+	}
+
+	tmp := bfv.NewCiphertext(s.params, 1)
+	partial := bfv.NewCiphertext(s.params, 2)
+	acc := bfv.NewCiphertext(s.params, 2)
+
+	for i, row := range s.rows {
+		// 1) Selects the i-th slot of the query
+		s.evaluator.Mul(q.Value[i/N], s.masks[i%N], tmp)
+		// 2) Populates all the slots with the selected slot
+		s.evaluator.InnerSum(tmp, tmp)
+		// 3) Multiplies the result with the i-th row
+		s.evaluator.Mul(tmp, row, partial)
+		s.evaluator.Add(acc, partial, acc)
+	}
+
+	ct = bfv.NewCiphertext(s.params, 1)
+	s.evaluator.Relinearize(acc, ct)
+
+	return ct, nil
+}
@@ -0,0 +1,155 @@
+package pir
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tuneinsight/lattigo/v3/bfv"
+	"github.com/tuneinsight/lattigo/v3/dbfv"
+	"github.com/tuneinsight/lattigo/v3/drlwe"
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+var nbParties = 3
+
+// genCollectiveKeys runs the collective key generation protocols and returns the secret-key shares of the parties,
+// the collective public key and the collective evaluation key for the Server.
+func genCollectiveKeys(params bfv.Parameters) (sks []*rlwe.SecretKey, pk *rlwe.PublicKey, evk rlwe.EvaluationKey) {
+
+	crs, _ := utils.NewKeyedPRNG([]byte{'t', 'e', 's', 't'})
+	kgen := bfv.NewKeyGenerator(params)
+
+	sks = make([]*rlwe.SecretKey, nbParties)
+	for i := range sks {
+		sks[i] = kgen.GenSecretKey()
+	}
+
+	ckg := dbfv.NewCKGProtocol(params)
+	ckgCRP := ckg.SampleCRP(crs)
+	ckgShare, ckgAgg := ckg.AllocateShare(), ckg.AllocateShare()
+	for _, sk := range sks {
+		ckg.GenShare(sk, ckgCRP, ckgShare)
+		ckg.AggregateShare(ckgShare, ckgAgg, ckgAgg)
+	}
+	pk = bfv.NewPublicKey(params)
+	ckg.GenPublicKey(ckgAgg, ckgCRP, pk)
+
+	rkg := dbfv.NewRKGProtocol(params)
+	rkgCRP := rkg.SampleCRP(crs)
+	ephSks := make([]*rlwe.SecretKey, nbParties)
+	_, rkgAgg1, rkgAgg2 := rkg.AllocateShare()
+	var rkgShare1, rkgShare2 *drlwe.RKGShare
+	for i, sk := range sks {
+		ephSks[i], rkgShare1, _ = rkg.AllocateShare()
+		rkg.GenShareRoundOne(sk, rkgCRP, ephSks[i], rkgShare1)
+		rkg.AggregateShare(rkgShare1, rkgAgg1, rkgAgg1)
+	}
+	for i, sk := range sks {
+		_, _, rkgShare2 = rkg.AllocateShare()
+		rkg.GenShareRoundTwo(ephSks[i], sk, rkgAgg1, rkgShare2)
+		rkg.AggregateShare(rkgShare2, rkgAgg2, rkgAgg2)
+	}
+	evk.Rlk = bfv.NewRelinearizationKey(params, 1)
+	rkg.GenRelinearizationKey(rkgAgg1, rkgAgg2, evk.Rlk)
+
+	rtg := dbfv.NewRotKGProtocol(params)
+	galEls := GaloisElements(params)
+	evk.Rtks = bfv.NewRotationKeySet(params, galEls)
+	for _, galEl := range galEls {
+		rtgCRP := rtg.SampleCRP(crs)
+		rtgShare, rtgAgg := rtg.AllocateShare(), rtg.AllocateShare()
+		for _, sk := range sks {
+			rtg.GenShare(sk, galEl, rtgCRP, rtgShare)
+			rtg.AggregateShare(rtgShare, rtgAgg, rtgAgg)
+		}
+		rtg.GenRotationKey(rtgAgg, rtgCRP, evk.Rtks.Keys[galEl])
+	}
+
+	return
+}
+
+func TestPIR(t *testing.T) {
+
+	paramsLit := bfv.PN13QP218
+	paramsLit.T = 65537
+	params, err := bfv.NewParametersFromLiteral(paramsLit)
+	require.NoError(t, err)
+
+	sks, pk, evk := genCollectiveKeys(params)
+
+	encoder := bfv.NewEncoder(params)
+	encryptor := bfv.NewEncryptor(params, pk)
+	prng, _ := utils.NewPRNG()
+	sampler := ring.NewUniformSampler(prng, params.RingT())
+
+	nbRows := 4
+	rows := make([][]uint64, nbRows)
+	encRows := make([]*bfv.Ciphertext, nbRows)
+	pt := bfv.NewPlaintext(params)
+	for i := range rows {
+		rows[i] = sampler.ReadNew().Coeffs[0]
+		encoder.EncodeUint(rows[i], pt)
+		encRows[i] = encryptor.EncryptNew(pt)
+	}
+
+	client := NewClient(params, pk)
+	server := NewServer(params, evk, encRows)
+	require.Equal(t, nbRows, server.NbRows())
+
+	t.Run(fmt.Sprintf("PIR/LogN=%d/rows=%d/parties=%d", params.LogN(), nbRows, nbParties), func(t *testing.T) {
+
+		index := 2
+
+		query, err := client.GenQuery(index, server.NbRows())
+		require.NoError(t, err)
+
+		// The query is sent to the server
+		data, err := query.MarshalBinary()
+		require.NoError(t, err)
+		queryServer, err := server.UnmarshalQuery(data)
+		require.NoError(t, err)
+
+		answer, err := server.AnswerNew(queryServer)
+		require.NoError(t, err)
+
+		// Decryption of the answer to the client, under a key pair of its own
+		skClient, pkClient := bfv.NewKeyGenerator(params).GenKeyPair()
+		cdp := dbfv.NewCollectiveDecryptProtocol(params, pkClient, 3.2)
+		share, agg := cdp.AllocateShare(), cdp.AllocateShare()
+		for _, sk := range sks {
+			cdp.GenShare(sk, answer, share)
+			cdp.AggregateShare(share, agg, agg)
+		}
+
+		require.Equal(t, rows[index], cdp.DecodeUintNew(skClient, answer, agg))
+	})
+
+	t.Run(fmt.Sprintf("PIR/InvalidQuery/LogN=%d", params.LogN()), func(t *testing.T) {
+
+		_, err := client.GenQuery(nbRows, nbRows)
+		require.Error(t, err)
+
+		// A database of more than N rows is queried with one ciphertext per chunk of N rows
+		query, err := client.GenQuery(params.N(), params.N()+1)
+		require.NoError(t, err)
+		require.Len(t, query.Value, 2)
+
+		_, err = server.AnswerNew(query)
+		require.Error(t, err)
+
+		// The server rejects the queries whose number of ciphertexts does not match its chunks
+		data, err := query.MarshalBinary()
+		require.NoError(t, err)
+		_, err = server.UnmarshalQuery(data)
+		require.Error(t, err)
+
+		// A header announcing more ciphertexts than the data can hold is rejected before allocating them
+		require.Error(t, new(Query).UnmarshalBinary([]byte{0xff, 0xff, 0xff, 0xff}))
+		require.Error(t, new(Query).UnmarshalBinary(data[:12]))
+		_, err = server.UnmarshalQuery([]byte{0xff, 0xff, 0xff, 0xff})
+		require.Error(t, err)
+	})
+}
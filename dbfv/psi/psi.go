@@ -0,0 +1,137 @@
+// Package psi implements a multiparty private-set-intersection (PSI) protocol based on the dbfv package, as described in
+// "Multiparty Homomorphic Encryption: From Theory to Practice" (<https://eprint.iacr.org/2020/304>).
+//
+// The parties first generate a collective public key and relinearization key with the dbfv protocols. Each party then hashes
+// the items of its set to the slots of a BFV plaintext, which stores 1 for the occupied slots and 0 for the other ones, and
+// encrypts it under the collective public key. The server multiplies the encrypted sets of all the parties, so that a slot of
+// the result is 1 if and only if it is occupied in every set. The result is finally decrypted to a receiver with the
+// dbfv.CollectiveDecryptProtocol, and the receiver recovers the items of its set that are in the intersection with Filter.
+//
+// Since items are hashed to slots, two different items can collide and the intersection can contain false positives: their
+// probability grows with the size of the sets relative to the number of slots.
+package psi
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/tuneinsight/lattigo/v3/bfv"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"golang.org/x/crypto/blake2b"
+)
+
+// Hasher is a keyed hash function mapping items to the slots of a BFV plaintext. All the parties must use the same key.
+type Hasher struct {
+	key   []byte
+	slots int
+}
+
+// NewHasher creates a new Hasher for the slots of the given parameters. The key must be at most 64 bytes long.
+func NewHasher(params bfv.Parameters, key []byte) (*Hasher, error) {
+	if len(key) > blake2b.Size {
+		return nil, errors.New("cannot NewHasher: the key must be at most 64 bytes long")
+	}
+	return &Hasher{key: append([]byte{}, key...), slots: params.N()}, nil
+}
+
+// Slot returns the slot to which item is hashed.
+func (h *Hasher) Slot(item []byte) int {
+	hash, err := blake2b.New256(h.key)
+	if err != nil {
+		panic(err)
+	}
+	hash.Write(item)
+	return int(binary.LittleEndian.Uint64(hash.Sum(nil)) % uint64(h.slots))
+}
+
+// Encode returns the slot vector of a set: the slots to which at least one item of the set is hashed are 1 and the other ones are 0.
+func (h *Hasher) Encode(items [][]byte) (slots []uint64) {
+	slots = make([]uint64, h.slots)
+	for _, item := range items {
+		slots[h.Slot(item)] = 1
+	}
+	return
+}
+
+// Filter returns the items whose slot is non-zero in the decoded slot vector of an intersection.
+func (h *Hasher) Filter(items [][]byte, intersection []uint64) (inter [][]byte) {
+	for _, item := range items {
+		if intersection[h.Slot(item)] != 0 {
+			inter = append(inter, item)
+		}
+	}
+	return
+}
+
+// Party is a data owner in the PSI protocol, which encrypts its set under the collective public key.
+type Party struct {
+	hasher    *Hasher
+	encoder   bfv.Encoder
+	encryptor bfv.Encryptor
+	pt        *bfv.Plaintext
+}
+
+// NewParty creates a new Party encrypting its set under the collective public key pk.
+func NewParty(params bfv.Parameters, hasher *Hasher, pk *rlwe.PublicKey) *Party {
+	return &Party{
+		hasher:    hasher,
+		encoder:   bfv.NewEncoder(params),
+		encryptor: bfv.NewEncryptor(params, pk),
+		pt:        bfv.NewPlaintext(params),
+	}
+}
+
+// EncryptSetNew hashes the items of the set to the slots and returns their encryption under the collective public key.
+func (p *Party) EncryptSetNew(items [][]byte) (ct *bfv.Ciphertext) {
+	p.encoder.EncodeUint(p.hasher.Encode(items), p.pt)
+	return p.encryptor.EncryptNew(p.pt)
+}
+
+// Server is the party computing the encrypted intersection of the encrypted sets of the parties.
+type Server struct {
+	params    bfv.Parameters
+	evaluator bfv.Evaluator
+}
+
+// NewServer creates a new Server from the collective relinearization key rlk.
+func NewServer(params bfv.Parameters, rlk *rlwe.RelinearizationKey) *Server {
+	return &Server{params: params, evaluator: bfv.NewEvaluator(params, rlwe.EvaluationKey{Rlk: rlk})}
+}
+
+// ShallowCopy creates a shallow copy of Server in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// Server can be used concurrently.
+func (s *Server) ShallowCopy() *Server {
+	return &Server{params: s.params, evaluator: s.evaluator.ShallowCopy()}
+}
+
+// IntersectNew returns the encrypted intersection of the encrypted sets. The sets are multiplied along a binary tree, so the
+// parameters must support a multiplicative depth of ceil(log2(len(sets))).
+// It returns an error if sets is empty.
+func (s *Server) IntersectNew(sets []*bfv.Ciphertext) (ct *bfv.Ciphertext, err error) {
+
+	if len(sets) == 0 {
+		return nil, errors.New("cannot IntersectNew: no set")
+	}
+
+	level := make([]*bfv.Ciphertext, len(sets))
+	for i := range sets {
+		level[i] = sets[i].CopyNew()
+	}
+
+	for len(level) > 1 {
+		next := make([]*bfv.Ciphertext, 0, (len(level)+1)>>1)
+		for i := 0; i+1 < len(level); i += 2 {
+			res := bfv.NewCiphertext(s.params, 2)
+			s.evaluator.Mul(level[i], level[i+1], res)
+			s.evaluator.Relinearize(res, level[i])
+			next = append(next, level[i])
+		}
+		if len(level)&1 == 1 {
+			next = append(next, level[len(level)-1])
+		}
+		level = next
+	}
+
+	return level[0], nil
+}
@@ -0,0 +1,124 @@
+package psi
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tuneinsight/lattigo/v3/bfv"
+	"github.com/tuneinsight/lattigo/v3/dbfv"
+	"github.com/tuneinsight/lattigo/v3/drlwe"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+var nbParties = 3
+
+// genCollectiveKeys runs the collective key generation protocols and returns the secret-key shares of the parties,
+// the collective public key and the collective relinearization key.
+func genCollectiveKeys(params bfv.Parameters) (sks []*rlwe.SecretKey, pk *rlwe.PublicKey, rlk *rlwe.RelinearizationKey) {
+
+	crs, _ := utils.NewKeyedPRNG([]byte{'t', 'e', 's', 't'})
+	kgen := bfv.NewKeyGenerator(params)
+
+	sks = make([]*rlwe.SecretKey, nbParties)
+	for i := range sks {
+		sks[i] = kgen.GenSecretKey()
+	}
+
+	ckg := dbfv.NewCKGProtocol(params)
+	ckgCRP := ckg.SampleCRP(crs)
+	ckgShare, ckgAgg := ckg.AllocateShare(), ckg.AllocateShare()
+	for _, sk := range sks {
+		ckg.GenShare(sk, ckgCRP, ckgShare)
+		ckg.AggregateShare(ckgShare, ckgAgg, ckgAgg)
+	}
+	pk = bfv.NewPublicKey(params)
+	ckg.GenPublicKey(ckgAgg, ckgCRP, pk)
+
+	rkg := dbfv.NewRKGProtocol(params)
+	rkgCRP := rkg.SampleCRP(crs)
+	ephSks := make([]*rlwe.SecretKey, nbParties)
+	_, rkgAgg1, rkgAgg2 := rkg.AllocateShare()
+	var rkgShare1, rkgShare2 *drlwe.RKGShare
+	for i, sk := range sks {
+		ephSks[i], rkgShare1, _ = rkg.AllocateShare()
+		rkg.GenShareRoundOne(sk, rkgCRP, ephSks[i], rkgShare1)
+		rkg.AggregateShare(rkgShare1, rkgAgg1, rkgAgg1)
+	}
+	for i, sk := range sks {
+		_, _, rkgShare2 = rkg.AllocateShare()
+		rkg.GenShareRoundTwo(ephSks[i], sk, rkgAgg1, rkgShare2)
+		rkg.AggregateShare(rkgShare2, rkgAgg2, rkgAgg2)
+	}
+	rlk = bfv.NewRelinearizationKey(params, 1)
+	rkg.GenRelinearizationKey(rkgAgg1, rkgAgg2, rlk)
+
+	return
+}
+
+func TestPSI(t *testing.T) {
+
+	paramsLit := bfv.PN13QP218
+	paramsLit.T = 65537
+	params, err := bfv.NewParametersFromLiteral(paramsLit)
+	require.NoError(t, err)
+
+	sks, pk, rlk := genCollectiveKeys(params)
+
+	hasher, err := NewHasher(params, []byte("psi test key"))
+	require.NoError(t, err)
+
+	_, err = NewHasher(params, make([]byte, 65))
+	require.Error(t, err)
+
+	// Each party holds the common items and some items of its own
+	sets := make([][][]byte, nbParties)
+	for i := range sets {
+		for j := 0; j < 32; j++ {
+			sets[i] = append(sets[i], []byte(fmt.Sprintf("common-%d", j)))
+			sets[i] = append(sets[i], []byte(fmt.Sprintf("party-%d-%d", i, j)))
+		}
+	}
+
+	t.Run(fmt.Sprintf("PSI/LogN=%d/parties=%d", params.LogN(), nbParties), func(t *testing.T) {
+
+		encSets := make([]*bfv.Ciphertext, nbParties)
+		for i := range encSets {
+			encSets[i] = NewParty(params, hasher, pk).EncryptSetNew(sets[i])
+		}
+
+		server := NewServer(params, rlk)
+		encInter, err := server.IntersectNew(encSets)
+		require.NoError(t, err)
+
+		_, err = server.IntersectNew(nil)
+		require.Error(t, err)
+
+		// Decryption of the intersection to the first party, under a key pair of its own
+		skReceiver, pkReceiver := bfv.NewKeyGenerator(params).GenKeyPair()
+		cdp := dbfv.NewCollectiveDecryptProtocol(params, pkReceiver, 3.2)
+		share, agg := cdp.AllocateShare(), cdp.AllocateShare()
+		for _, sk := range sks {
+			cdp.GenShare(sk, encInter, share)
+			cdp.AggregateShare(share, agg, agg)
+		}
+
+		inter := hasher.Filter(sets[0], cdp.DecodeUintNew(skReceiver, encInter, agg))
+
+		// An item of the first party is in the intersection if and only if its slot is occupied in every set
+		var want [][]byte
+		for _, item := range sets[0] {
+			in := true
+			for _, set := range sets[1:] {
+				in = in && hasher.Encode(set)[hasher.Slot(item)] == 1
+			}
+			if in {
+				want = append(want, item)
+			}
+		}
+
+		require.Equal(t, want, inter)
+		require.GreaterOrEqual(t, len(inter), 32)
+	})
+}
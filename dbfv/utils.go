@@ -0,0 +1,29 @@
+package dbfv
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/tuneinsight/lattigo/v3/bfv"
+	"github.com/tuneinsight/lattigo/v3/drlwe"
+)
+
+// NewSmudgingParameters returns the smudging parameters to hide a ciphertext noise of at most 2^logNoiseBound with statistical
+// security lambda, when nParties parties add smudging noise. The room is the largest noise for which the decryption is correct, i.e. Q/(2T).
+func NewSmudgingParameters(params bfv.Parameters, nParties int, logNoiseBound float64, lambda int) drlwe.SmudgingParameters {
+	var logQ float64
+	for _, qi := range params.Q() {
+		logQ += math.Log2(float64(qi))
+	}
+	return drlwe.NewSmudgingParameters(params.Parameters, nParties, logNoiseBound, lambda, logQ-math.Log2(float64(params.T()))-1)
+}
+
+// NewRefreshProtocolWithSmudging creates a new Refresh protocol instance whose smudging noise is estimated from sp.
+// It returns an error if the parameters do not have enough room for the smudging noise.
+func NewRefreshProtocolWithSmudging(params bfv.Parameters, sp drlwe.SmudgingParameters) (*RefreshProtocol, error) {
+	sigmaSmudging, err := sp.Sigma()
+	if err != nil {
+		return nil, fmt.Errorf("cannot NewRefreshProtocolWithSmudging: %w", err)
+	}
+	return NewRefreshProtocol(params, sigmaSmudging), nil
+}
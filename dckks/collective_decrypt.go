@@ -0,0 +1,60 @@
+package dckks
+
+import (
+	"github.com/tuneinsight/lattigo/v3/ckks"
+	"github.com/tuneinsight/lattigo/v3/drlwe"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// CollectiveDecryptProtocol is the protocol for the collective decryption of a CKKS ciphertext, either publicly or to a
+// designated receiver. See drlwe.CollectiveDecryptProtocol.
+type CollectiveDecryptProtocol struct {
+	drlwe.CollectiveDecryptProtocol
+	params  ckks.Parameters
+	encoder ckks.Encoder
+}
+
+// NewCollectiveDecryptProtocol creates a new CollectiveDecryptProtocol instance. The output is only decryptable by the owner
+// of the secret key of receiver or, if receiver is nil, publicly.
+func NewCollectiveDecryptProtocol(params ckks.Parameters, receiver *rlwe.PublicKey, sigmaSmudging float64) *CollectiveDecryptProtocol {
+	return &CollectiveDecryptProtocol{*drlwe.NewCollectiveDecryptProtocol(params.Parameters, receiver, sigmaSmudging), params, ckks.NewEncoder(params)}
+}
+
+// ShallowCopy creates a shallow copy of CollectiveDecryptProtocol in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// CollectiveDecryptProtocol can be used concurrently.
+func (cdp *CollectiveDecryptProtocol) ShallowCopy() *CollectiveDecryptProtocol {
+	return &CollectiveDecryptProtocol{*cdp.CollectiveDecryptProtocol.ShallowCopy(), cdp.params, cdp.encoder.ShallowCopy()}
+}
+
+// WithPRNG creates a shallow copy of CollectiveDecryptProtocol whose samplers draw their randomness from prng, making the
+// generated shares reproducible from the seed of prng. If prng is nil, the randomness is drawn from crypto/rand.
+func (cdp *CollectiveDecryptProtocol) WithPRNG(prng utils.PRNG) *CollectiveDecryptProtocol {
+	return &CollectiveDecryptProtocol{*cdp.CollectiveDecryptProtocol.WithPRNG(prng), cdp.params, cdp.encoder.ShallowCopy()}
+}
+
+// GenShare generates the share of a party, with secret key sk, for the ciphertext ct.
+func (cdp *CollectiveDecryptProtocol) GenShare(sk *rlwe.SecretKey, ct *ckks.Ciphertext, shareOut *drlwe.CollectiveDecryptShare) {
+	cdp.CollectiveDecryptProtocol.GenShare(sk, ct.Ciphertext, shareOut)
+}
+
+// KeySwitch applies the aggregated share to ct and writes the result on ctOut.
+func (cdp *CollectiveDecryptProtocol) KeySwitch(ct *ckks.Ciphertext, aggregate *drlwe.CollectiveDecryptShare, ctOut *ckks.Ciphertext) {
+	cdp.CollectiveDecryptProtocol.KeySwitch(ct.Ciphertext, aggregate, ctOut.Ciphertext)
+	ctOut.Scale = ct.Scale
+}
+
+// DecryptNew applies the aggregated share to ct and returns the plaintext decrypted with the secret key of the receiver.
+// For a public decryption, skReceiver is ignored and can be nil.
+func (cdp *CollectiveDecryptProtocol) DecryptNew(skReceiver *rlwe.SecretKey, ct *ckks.Ciphertext, aggregate *drlwe.CollectiveDecryptShare) (pt *ckks.Plaintext) {
+	pt = ckks.NewPlaintext(cdp.params, ct.Level(), ct.Scale)
+	cdp.CollectiveDecryptProtocol.Decrypt(skReceiver, ct.Ciphertext, aggregate, pt.Plaintext)
+	return
+}
+
+// DecodeNew applies the aggregated share to ct, decrypts the result with the secret key of the receiver and returns
+// the 2^logSlots decoded values. For a public decryption, skReceiver is ignored and can be nil.
+func (cdp *CollectiveDecryptProtocol) DecodeNew(skReceiver *rlwe.SecretKey, ct *ckks.Ciphertext, aggregate *drlwe.CollectiveDecryptShare, logSlots int) (values []complex128) {
+	return cdp.encoder.Decode(cdp.DecryptNew(skReceiver, ct, aggregate), logSlots)
+}
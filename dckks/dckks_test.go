@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"math"
 	"runtime"
 	"testing"
 
@@ -101,13 +102,18 @@ func TestDCKKS(t *testing.T) {
 		for _, testSet := range []func(tc *testContext, t *testing.T){
 			testPublicKeyGen,
 			testRelinKeyGen,
+			testPKRelinKeyGen,
 			testKeyswitching,
+			testKeyRotation,
 			testPublicKeySwitching,
+			testCollectiveDecrypt,
 			testRotKeyGenConjugate,
 			testRotKeyGenCols,
+			testEvaluationKeyGen,
 			testE2SProtocol,
 			testRefresh,
 			testRefreshAndTransform,
+			testSmudging,
 			testMarshalling,
 		} {
 			testSet(tc, t)
@@ -280,6 +286,63 @@ func testRelinKeyGen(testCtx *testContext, t *testing.T) {
 
 }
 
+func testPKRelinKeyGen(testCtx *testContext, t *testing.T) {
+
+	encryptorPk0 := testCtx.encryptorPk0
+	decryptorSk0 := testCtx.decryptorSk0
+	sk0Shards := testCtx.sk0Shards
+	params := testCtx.params
+
+	t.Run(testString("PKRelinKeyGen", parties, params), func(t *testing.T) {
+
+		type Party struct {
+			*PKRKGProtocol
+			sk    *rlwe.SecretKey
+			share *drlwe.PKRKGShare
+		}
+
+		rkgParties := make([]*Party, parties)
+
+		for i := range rkgParties {
+			p := new(Party)
+			p.PKRKGProtocol = NewPKRKGProtocol(params)
+			p.sk = sk0Shards[i]
+			p.share = p.AllocateShare()
+			rkgParties[i] = p
+		}
+
+		P0 := rkgParties[0]
+
+		crp := P0.SampleCRP(testCtx.crs)
+
+		// Single round
+		for i, p := range rkgParties {
+			p.GenShare(p.sk, crp, p.share)
+			if i > 0 {
+				P0.AggregateShare(p.share, P0.share, P0.share)
+			}
+		}
+
+		rlk := drlwe.NewPKRelinearizationKey(params.Parameters)
+		P0.GenRelinearizationKey(P0.share, crp, rlk)
+
+		coeffs, _, ciphertext := newTestVectors(testCtx, encryptorPk0, -1, 1)
+
+		for i := range coeffs {
+			coeffs[i] *= coeffs[i]
+		}
+
+		ciphertextMul := testCtx.evaluator.MulNew(ciphertext, ciphertext)
+		NewPKRelinearizer(params, rlk).Relinearize(ciphertextMul, ciphertext)
+
+		testCtx.evaluator.Rescale(ciphertext, params.DefaultScale(), ciphertext)
+
+		require.Equal(t, ciphertext.Degree(), 1)
+
+		verifyTestVectors(testCtx, decryptorSk0, coeffs, ciphertext, t)
+	})
+}
+
 func testKeyswitching(testCtx *testContext, t *testing.T) {
 
 	encryptorPk0 := testCtx.encryptorPk0
@@ -341,6 +404,50 @@ func testKeyswitching(testCtx *testContext, t *testing.T) {
 	})
 }
 
+func testKeyRotation(testCtx *testContext, t *testing.T) {
+
+	params := testCtx.params
+
+	t.Run(testString("KeyRotation", parties, params), func(t *testing.T) {
+
+		// The first party leaves, the other ones stay and a new party joins
+		kgen := ckks.NewKeyGenerator(params)
+		skOld := append(testCtx.sk0Shards, nil)
+		skNew := make([]*rlwe.SecretKey, parties+1)
+		skNewIdeal := ckks.NewSecretKey(params)
+		ringQP, levelQ, levelP := params.RingQP(), params.QCount()-1, params.PCount()-1
+		for i := 1; i < len(skNew); i++ {
+			skNew[i] = kgen.GenSecretKey()
+			ringQP.AddLvl(levelQ, levelP, skNewIdeal.Value, skNew[i].Value, skNewIdeal.Value)
+		}
+
+		krp := make([]*KeyRotationProtocol, len(skNew))
+		for i := range krp {
+			if i == 0 {
+				krp[i] = NewKeyRotationProtocol(params, 3.2)
+			} else {
+				krp[i] = krp[0].ShallowCopy()
+			}
+		}
+
+		coeffs, _, ciphertext := newTestVectors(testCtx, testCtx.encryptorPk0, -1, 1)
+
+		share, agg := krp[0].AllocateShare(ciphertext.Level()), krp[0].AllocateShare(ciphertext.Level())
+		for i := range krp {
+			krp[i].GenShare(skOld[i], skNew[i], ciphertext.Value[1], share)
+			krp[0].AggregateShare(share, agg, agg)
+		}
+
+		ctRotated := ckks.NewCiphertext(params, 1, ciphertext.Level(), ciphertext.Scale/2)
+		krp[0].KeySwitch(ciphertext, agg, ctRotated)
+		require.Equal(t, ciphertext.Scale, ctRotated.Scale)
+
+		verifyTestVectors(testCtx, ckks.NewDecryptor(params, skNewIdeal), coeffs, ctRotated, t)
+
+		require.Panics(t, func() { krp[0].GenShare(nil, nil, ciphertext.Value[1], share) })
+	})
+}
+
 func testPublicKeySwitching(testCtx *testContext, t *testing.T) {
 
 	encryptorPk0 := testCtx.encryptorPk0
@@ -395,6 +502,56 @@ func testPublicKeySwitching(testCtx *testContext, t *testing.T) {
 	})
 }
 
+func testCollectiveDecrypt(testCtx *testContext, t *testing.T) {
+
+	params := testCtx.params
+
+	for _, receiver := range []*rlwe.PublicKey{testCtx.pk1, nil} {
+
+		name := "CollectiveDecrypt/Receiver"
+		skReceiver := testCtx.sk1
+		if receiver == nil {
+			name, skReceiver = "CollectiveDecrypt/Public", nil
+		}
+
+		t.Run(testString(name, parties, params), func(t *testing.T) {
+
+			cdp := NewCollectiveDecryptProtocol(params, receiver, 3.2)
+
+			coeffs, _, ciphertextFullLevels := newTestVectors(testCtx, testCtx.encryptorPk0, -1, 1)
+
+			for _, dropped := range []int{0, ciphertextFullLevels.Level()} { // runs the test for full and level zero
+				ciphertext := testCtx.evaluator.DropLevelNew(ciphertextFullLevels, dropped)
+
+				t.Run(fmt.Sprintf("atLevel=%d", ciphertext.Level()), func(t *testing.T) {
+
+					seed := []byte{'d', 'i', 'g', 'e', 's', 't'}
+					nbPoints := 2
+
+					digests := make([]*drlwe.ShareDigest, parties)
+					share, agg := cdp.AllocateShare(ciphertext.Level()), cdp.AllocateShare(ciphertext.Level())
+					for i := range digests {
+						cdp.GenShare(testCtx.sk0Shards[i], ciphertext, share)
+						digests[i] = cdp.GenShareDigest(seed, nbPoints, share)
+						cdp.AggregateShare(share, agg, agg)
+					}
+
+					require.NoError(t, cdp.VerifyAggregate(seed, nbPoints, agg, digests))
+
+					pt := cdp.DecryptNew(skReceiver, ciphertext, agg)
+					require.Equal(t, ciphertext.Scale, pt.Scale)
+					verifyTestVectors(testCtx, nil, coeffs, pt, t)
+					verifyTestVectors(testCtx, nil, coeffs, cdp.DecodeNew(skReceiver, ciphertext, agg, params.LogSlots()), t)
+
+					// A tampered aggregated share is detected by the receiver
+					testCtx.uniformSampler.ReadLvl(ciphertext.Level(), agg.Value[0])
+					require.Error(t, cdp.VerifyAggregate(seed, nbPoints, agg, digests))
+				})
+			}
+		})
+	}
+}
+
 func testRotKeyGenConjugate(testCtx *testContext, t *testing.T) {
 
 	encryptorPk0 := testCtx.encryptorPk0
@@ -514,6 +671,97 @@ func testRotKeyGenCols(testCtx *testContext, t *testing.T) {
 	})
 }
 
+func testEvaluationKeyGen(testCtx *testContext, t *testing.T) {
+
+	encryptorPk0 := testCtx.encryptorPk0
+	decryptorSk0 := testCtx.decryptorSk0
+	sk0Shards := testCtx.sk0Shards
+	params := testCtx.params
+
+	t.Run(testString("EvaluationKeyGen", parties, params), func(t *testing.T) {
+
+		type Party struct {
+			*EKGProtocol
+			ephSk  *rlwe.SecretKey
+			sk     *rlwe.SecretKey
+			share1 *drlwe.EKGShareRoundOne
+			share2 *drlwe.RKGShare
+		}
+
+		galEls := params.GaloisElementsForRowInnerSum()
+
+		ekgParties := make([]*Party, parties)
+		for i := range ekgParties {
+			p := new(Party)
+			if i == 0 {
+				p.EKGProtocol = NewEKGProtocol(params, galEls, true)
+			} else {
+				p.EKGProtocol = ekgParties[0].ShallowCopy()
+			}
+			p.sk = sk0Shards[i]
+			p.ephSk, p.share1, p.share2 = p.AllocateShare()
+			ekgParties[i] = p
+		}
+
+		P0 := ekgParties[0]
+
+		crp := P0.SampleCRP(testCtx.crs)
+
+		// ROUND 1: the shares of all the keys are sent in a single message
+		for i, p := range ekgParties {
+			p.GenShareRoundOne(p.sk, crp, p.ephSk, p.share1)
+			if i > 0 {
+				data, err := p.share1.MarshalBinary()
+				require.NoError(t, err)
+				received := new(drlwe.EKGShareRoundOne)
+				require.NoError(t, received.UnmarshalBinary(data))
+				P0.AggregateShareRoundOne(received, P0.share1, P0.share1)
+			}
+		}
+
+		// A share lacking the share of one of the Galois elements is rejected
+		truncated := &drlwe.EKGShareRoundOne{RKG: P0.share1.RKG, RTG: make(map[uint64]*drlwe.RTGShare)}
+		for _, galEl := range galEls[1:] {
+			truncated.RTG[galEl] = P0.share1.RTG[galEl]
+		}
+		require.Error(t, P0.CheckShareRoundOne(truncated))
+		require.Panics(t, func() { P0.AggregateShareRoundOne(truncated, P0.share1, P0.share1) })
+		require.NoError(t, P0.CheckShareRoundOne(P0.share1))
+
+		// ROUND 2
+		for i, p := range ekgParties {
+			p.GenShareRoundTwo(p.ephSk, p.sk, P0.share1, p.share2)
+			if i > 0 {
+				P0.AggregateShareRoundTwo(p.share2, P0.share2, P0.share2)
+			}
+		}
+
+		evk := rlwe.EvaluationKey{Rlk: ckks.NewRelinearizationKey(params), Rtks: ckks.NewRotationKeySet(params, galEls)}
+		P0.GenEvaluationKey(P0.share1, P0.share2, crp, evk)
+
+		evaluator := testCtx.evaluator.WithKey(evk)
+
+		coeffs, _, ciphertext := newTestVectors(testCtx, encryptorPk0, -1, 1)
+
+		for i := range coeffs {
+			coeffs[i] *= coeffs[i]
+		}
+
+		evaluator.MulRelin(ciphertext, ciphertext, ciphertext)
+		evaluator.Rescale(ciphertext, params.DefaultScale(), ciphertext)
+
+		require.Equal(t, ciphertext.Degree(), 1)
+
+		verifyTestVectors(testCtx, decryptorSk0, coeffs, ciphertext, t)
+
+		receiver := ckks.NewCiphertext(params, ciphertext.Degree(), ciphertext.Level(), ciphertext.Scale)
+		for k := 1; k < params.Slots(); k <<= 1 {
+			evaluator.Rotate(ciphertext, k, receiver)
+			verifyTestVectors(testCtx, decryptorSk0, utils.RotateComplex128Slice(coeffs, k), receiver, t)
+		}
+	})
+}
+
 func testE2SProtocol(testCtx *testContext, t *testing.T) {
 
 	params := testCtx.params
@@ -736,6 +984,29 @@ func testRefreshAndTransform(testCtx *testContext, t *testing.T) {
 	})
 }
 
+func testSmudging(testCtx *testContext, t *testing.T) {
+
+	params := testCtx.params
+
+	t.Run(testString("Smudging", parties, params), func(t *testing.T) {
+
+		sp := NewSmudgingParameters(params, parties, 5, 10, 8)
+		require.Equal(t, math.Log2(params.DefaultScale())-8, sp.LogRoom)
+
+		_, logNoise, ok := sp.Estimate()
+		require.True(t, ok)
+		require.LessOrEqual(t, logNoise, sp.LogRoom)
+
+		rfp, err := NewMaskedTransformProtocolWithSmudging(params, 128, sp)
+		require.NoError(t, err)
+		require.NotNil(t, rfp)
+
+		// Requiring more precision than the scale allows
+		_, err = NewMaskedTransformProtocolWithSmudging(params, 128, NewSmudgingParameters(params, parties, 5, 10, int(math.Log2(params.DefaultScale()))))
+		require.Error(t, err)
+	})
+}
+
 func testMarshalling(testCtx *testContext, t *testing.T) {
 	params := testCtx.params
 
@@ -3,6 +3,7 @@ package dckks
 import (
 	"github.com/tuneinsight/lattigo/v3/ckks"
 	"github.com/tuneinsight/lattigo/v3/drlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
 )
 
 // CKSProtocol is a structure storing the parameters for the collective key-switching protocol.
@@ -30,6 +31,12 @@ func (cks *CKSProtocol) ShallowCopy() *CKSProtocol {
 	return &CKSProtocol{*cks.CKSProtocol.ShallowCopy()}
 }
 
+// WithPRNG creates a shallow copy of CKSProtocol whose samplers draw their randomness from prng, making the
+// generated shares reproducible from the seed of prng. If prng is nil, the randomness is drawn from crypto/rand.
+func (cks *CKSProtocol) WithPRNG(prng utils.PRNG) *CKSProtocol {
+	return &CKSProtocol{*cks.CKSProtocol.WithPRNG(prng)}
+}
+
 // PCKSProtocol is the structure storing the parameters for the collective public key-switching.
 type PCKSProtocol struct {
 	drlwe.PCKSProtocol
@@ -53,3 +60,39 @@ func (pcks *PCKSProtocol) KeySwitch(ctIn *ckks.Ciphertext, combined *drlwe.PCKSS
 func (pcks *PCKSProtocol) ShallowCopy() *PCKSProtocol {
 	return &PCKSProtocol{*pcks.PCKSProtocol.ShallowCopy()}
 }
+
+// WithPRNG creates a shallow copy of PCKSProtocol whose samplers draw their randomness from prng, making the
+// generated shares reproducible from the seed of prng. If prng is nil, the randomness is drawn from crypto/rand.
+func (pcks *PCKSProtocol) WithPRNG(prng utils.PRNG) *PCKSProtocol {
+	return &PCKSProtocol{*pcks.PCKSProtocol.WithPRNG(prng)}
+}
+
+// KeyRotationProtocol is the structure storing the parameters for the rotation of a collective key. See drlwe.KeyRotationProtocol.
+type KeyRotationProtocol struct {
+	drlwe.KeyRotationProtocol
+}
+
+// NewKeyRotationProtocol creates a new KeyRotationProtocol that will be used to switch the ciphertexts encrypted under the collective
+// key of an old committee to the collective key of a new committee.
+func NewKeyRotationProtocol(params ckks.Parameters, sigmaSmudging float64) *KeyRotationProtocol {
+	return &KeyRotationProtocol{*drlwe.NewKeyRotationProtocol(params.Parameters, sigmaSmudging)}
+}
+
+// KeySwitch performs the actual keyswitching operation on a ciphertext ct and put the result in ctOut.
+func (krp *KeyRotationProtocol) KeySwitch(ctIn *ckks.Ciphertext, combined *drlwe.CKSShare, ctOut *ckks.Ciphertext) {
+	krp.KeyRotationProtocol.KeySwitch(ctIn.Ciphertext, combined, ctOut.Ciphertext)
+	ctOut.Scale = ctIn.Scale
+}
+
+// ShallowCopy creates a shallow copy of KeyRotationProtocol in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// KeyRotationProtocol can be used concurrently.
+func (krp *KeyRotationProtocol) ShallowCopy() *KeyRotationProtocol {
+	return &KeyRotationProtocol{*krp.KeyRotationProtocol.ShallowCopy()}
+}
+
+// WithPRNG creates a shallow copy of KeyRotationProtocol whose samplers draw their randomness from prng, making the
+// generated shares reproducible from the seed of prng. If prng is nil, the randomness is drawn from crypto/rand.
+func (krp *KeyRotationProtocol) WithPRNG(prng utils.PRNG) *KeyRotationProtocol {
+	return &KeyRotationProtocol{*krp.KeyRotationProtocol.WithPRNG(prng)}
+}
@@ -18,6 +18,7 @@ type E2SProtocol struct {
 	params     ckks.Parameters
 	zero       *rlwe.SecretKey
 	maskBigint []*big.Int
+	maskVec    *ring.BigintVec
 	pool       *ring.Poly
 }
 
@@ -36,6 +37,7 @@ func (e2s *E2SProtocol) ShallowCopy() *E2SProtocol {
 		params:      e2s.params,
 		zero:        e2s.zero,
 		maskBigint:  maskBigint,
+		maskVec:     e2s.params.RingQ().NewBigintVecLvl(e2s.params.MaxLevel(), e2s.params.N()),
 		pool:        e2s.params.RingQ().NewPoly(),
 	}
 }
@@ -50,6 +52,7 @@ func NewE2SProtocol(params ckks.Parameters, sigmaSmudging float64) *E2SProtocol
 	for i := range e2s.maskBigint {
 		e2s.maskBigint[i] = new(big.Int)
 	}
+	e2s.maskVec = params.RingQ().NewBigintVecLvl(params.MaxLevel(), params.N())
 	e2s.pool = e2s.params.RingQ().NewPoly()
 	return e2s
 }
@@ -115,7 +118,9 @@ func (e2s *E2SProtocol) GenShare(sk *rlwe.SecretKey, logBound, logSlots int, ct1
 	// Generates an encryption of zero and subtracts the mask
 	e2s.CKSProtocol.GenShare(sk, e2s.zero, ct1, publicShareOut)
 
-	ringQ.SetCoefficientsBigintLvl(levelQ, secretShareOut.Value[:dslots], e2s.pool)
+	maskVec := e2s.maskVec.Slice(0, dslots)
+	maskVec.SetBigints(secretShareOut.Value[:dslots])
+	ringQ.CRT().Decompose(levelQ, maskVec, e2s.pool)
 	ckks.NttAndMontgomeryLvl(levelQ, logSlots, ringQ, false, e2s.pool)
 
 	// Substracts the mask to the encryption of zero
@@ -147,7 +152,7 @@ func (e2s *E2SProtocol) GetShare(secretShare *rlwe.AdditiveShareBigint, aggregat
 	gap := ringQ.N / dslots
 
 	// Switches the LSSS RNS ciphertext outside of the RNS domain
-	ringQ.PolyToBigintCenteredLvl(levelQ, e2s.pool, gap, e2s.maskBigint)
+	ringQ.CRT().ReconstructCentered(levelQ, e2s.pool, gap, e2s.maskVec)
 
 	// Substracts the last mask
 	if secretShare != nil {
@@ -155,13 +160,12 @@ func (e2s *E2SProtocol) GetShare(secretShare *rlwe.AdditiveShareBigint, aggregat
 		b := e2s.maskBigint
 		c := secretShare.Value
 		for i := range secretShareOut.Value[:dslots] {
-			a[i].Add(c[i], b[i])
+			a[i].Add(c[i], e2s.maskVec.Get(i, b[i]))
 		}
 	} else {
 		a := secretShareOut.Value
-		b := e2s.maskBigint
 		for i := range secretShareOut.Value[:dslots] {
-			a[i].Set(b[i])
+			e2s.maskVec.Get(i, a[i])
 		}
 	}
 }
@@ -170,10 +174,10 @@ func (e2s *E2SProtocol) GetShare(secretShare *rlwe.AdditiveShareBigint, aggregat
 // required by the shares-to-encryption protocol.
 type S2EProtocol struct {
 	CKSProtocol
-	params   ckks.Parameters
-	tmp      *ring.Poly
-	ssBigint []*big.Int
-	zero     *rlwe.SecretKey
+	params ckks.Parameters
+	tmp    *ring.Poly
+	ssVec  *ring.BigintVec
+	zero   *rlwe.SecretKey
 }
 
 // ShallowCopy creates a shallow copy of S2EProtocol in which all the read-only data-structures are
@@ -184,7 +188,7 @@ func (s2e *S2EProtocol) ShallowCopy() *S2EProtocol {
 		CKSProtocol: *s2e.CKSProtocol.ShallowCopy(),
 		params:      s2e.params,
 		tmp:         s2e.params.RingQ().NewPoly(),
-		ssBigint:    make([]*big.Int, s2e.params.N()),
+		ssVec:       s2e.params.RingQ().NewBigintVecLvl(s2e.params.MaxLevel(), s2e.params.N()),
 		zero:        s2e.zero,
 	}
 }
@@ -195,7 +199,7 @@ func NewS2EProtocol(params ckks.Parameters, sigmaSmudging float64) *S2EProtocol
 	s2e.CKSProtocol = *NewCKSProtocol(params, sigmaSmudging)
 	s2e.params = params
 	s2e.tmp = s2e.params.RingQ().NewPoly()
-	s2e.ssBigint = make([]*big.Int, s2e.params.N())
+	s2e.ssVec = params.RingQ().NewBigintVecLvl(params.MaxLevel(), params.N())
 	s2e.zero = rlwe.NewSecretKey(params.Parameters)
 	return s2e
 }
@@ -228,7 +232,9 @@ func (s2e *S2EProtocol) GenShare(sk *rlwe.SecretKey, crs drlwe.CKSCRP, logSlots
 		dslots *= 2
 	}
 
-	ringQ.SetCoefficientsBigintLvl(c1.Level(), secretShare.Value[:dslots], s2e.tmp)
+	ssVec := s2e.ssVec.Slice(0, dslots)
+	ssVec.SetBigints(secretShare.Value[:dslots])
+	ringQ.CRT().Decompose(c1.Level(), ssVec, s2e.tmp)
 	ckks.NttAndMontgomeryLvl(c1.Level(), logSlots, ringQ, false, s2e.tmp)
 
 	ringQ.AddLvl(c1.Level(), c0ShareOut.Value, s2e.tmp, c0ShareOut.Value)
@@ -1,10 +1,13 @@
 package dckks
 
 import (
+	"fmt"
 	"math"
 	"math/bits"
 
 	"github.com/tuneinsight/lattigo/v3/ckks"
+	"github.com/tuneinsight/lattigo/v3/ckks/bootstrapping"
+	"github.com/tuneinsight/lattigo/v3/drlwe"
 	"github.com/tuneinsight/lattigo/v3/ring"
 	"github.com/tuneinsight/lattigo/v3/rlwe"
 )
@@ -35,6 +38,35 @@ func GetMinimumLevelForBootstrapping(lambda int, scale float64, nParties int, mo
 	return minLevel, logBound, true
 }
 
+// NewSmudgingParameters returns the smudging parameters to hide a ciphertext noise of at most 2^logNoiseBound with statistical
+// security lambda, when nParties parties add smudging noise. The room is the largest noise that preserves logPrecision bits of
+// precision on messages encoded at the default scale, i.e. DefaultScale/2^logPrecision.
+func NewSmudgingParameters(params ckks.Parameters, nParties int, logNoiseBound float64, lambda, logPrecision int) drlwe.SmudgingParameters {
+	return drlwe.NewSmudgingParameters(params.Parameters, nParties, logNoiseBound, lambda, math.Log2(params.DefaultScale())-float64(logPrecision))
+}
+
+// NewMaskedTransformProtocolWithSmudging creates a new instance of the PermuteProtocol whose smudging noise is estimated from sp.
+// It returns an error if the parameters do not have enough room for the smudging noise.
+func NewMaskedTransformProtocolWithSmudging(params ckks.Parameters, precision int, sp drlwe.SmudgingParameters) (*MaskedTransformProtocol, error) {
+	sigmaSmudging, err := sp.Sigma()
+	if err != nil {
+		return nil, fmt.Errorf("cannot NewMaskedTransformProtocolWithSmudging: %w", err)
+	}
+	return NewMaskedTransformProtocol(params, precision, sigmaSmudging), nil
+}
+
+// GaloisElementsForBootstrapping returns the Galois elements of all the rotation keys, including the conjugation key,
+// required by the bootstrapping with the given parameters. They can be given to NewEKGProtocol to generate the full
+// bootstrapping key set collectively in two rounds.
+func GaloisElementsForBootstrapping(params ckks.Parameters, btpParams bootstrapping.Parameters) (galEls []uint64) {
+	rotations := btpParams.RotationsForBootstrapping(params.LogN(), params.LogSlots())
+	galEls = make([]uint64, len(rotations), len(rotations)+1)
+	for i, k := range rotations {
+		galEls[i] = params.GaloisElementForColumnRotationBy(k)
+	}
+	return append(galEls, params.GaloisElementForRowRotation())
+}
+
 // NewAdditiveShareBigint instantiates a new additive share struct composed of "n" big.Int elements
 func NewAdditiveShareBigint(params ckks.Parameters, logSlots int) *rlwe.AdditiveShareBigint {
 	dslots := 1 << logSlots
@@ -0,0 +1,191 @@
+package drlwe
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// PartyID is the identifier of a party in the multiparty protocols.
+type PartyID int
+
+// Share is an interface for the shares of the multiparty protocols.
+type Share interface {
+	MarshalBinary() ([]byte, error)
+	UnmarshalBinary(data []byte) error
+}
+
+// AggregateFunc is a function aggregating share1 and share2 into shareOut,
+// e.g. a wrapper around the AggregateShare method of a protocol.
+type AggregateFunc func(share1, share2, shareOut Share)
+
+// PartialAggregate is the aggregation of the shares of a set of parties.
+type PartialAggregate struct {
+	Parties []PartyID
+	Share   Share
+}
+
+// NewPartialAggregate creates a new PartialAggregate storing the share of a single party.
+func NewPartialAggregate(party PartyID, share Share) *PartialAggregate {
+	return &PartialAggregate{Parties: []PartyID{party}, Share: share}
+}
+
+// MarshalBinary encodes the target element on a slice of bytes.
+func (pa *PartialAggregate) MarshalBinary() (data []byte, err error) {
+
+	var shareData []byte
+	if shareData, err = pa.Share.MarshalBinary(); err != nil {
+		return nil, err
+	}
+
+	data = make([]byte, 8+8*len(pa.Parties)+len(shareData))
+	binary.LittleEndian.PutUint64(data, uint64(len(pa.Parties)))
+	ptr := 8
+	for _, id := range pa.Parties {
+		binary.LittleEndian.PutUint64(data[ptr:], uint64(id))
+		ptr += 8
+	}
+	copy(data[ptr:], shareData)
+
+	return data, nil
+}
+
+// UnmarshalBinary decodes a slice of bytes on the target element.
+// The field Share must be set to an allocated share of the expected type.
+func (pa *PartialAggregate) UnmarshalBinary(data []byte) (err error) {
+
+	if pa.Share == nil {
+		return errors.New("cannot UnmarshalBinary: Share must be allocated")
+	}
+
+	if len(data) < 8 {
+		return errors.New("cannot UnmarshalBinary: data is too short")
+	}
+
+	nParties := binary.LittleEndian.Uint64(data)
+	if uint64(len(data)-8)/8 < nParties {
+		return errors.New("cannot UnmarshalBinary: data is too short")
+	}
+
+	pa.Parties = make([]PartyID, nParties)
+	ptr := 8
+	for i := range pa.Parties {
+		pa.Parties[i] = PartyID(binary.LittleEndian.Uint64(data[ptr:]))
+		ptr += 8
+	}
+
+	return pa.Share.UnmarshalBinary(data[ptr:])
+}
+
+// ShareAggregator aggregates the PartialAggregate of a known set of parties, for example along a tree,
+// while keeping track of the contributing parties. It refuses contributions from unknown parties and
+// contributions that would count a party twice. A ShareAggregator can be used concurrently.
+type ShareAggregator struct {
+	mu        sync.Mutex
+	expected  map[PartyID]bool
+	received  map[PartyID]bool
+	aggregate AggregateFunc
+	result    Share
+}
+
+// NewShareAggregator creates a new ShareAggregator expecting the contributions of the given parties,
+// which aggregates the shares with the function aggregate.
+func NewShareAggregator(parties []PartyID, aggregate AggregateFunc) *ShareAggregator {
+	expected := make(map[PartyID]bool, len(parties))
+	for _, id := range parties {
+		expected[id] = true
+	}
+	return &ShareAggregator{expected: expected, received: make(map[PartyID]bool, len(parties)), aggregate: aggregate}
+}
+
+// Add aggregates the partial aggregate pa into the aggregator. It returns an error, and leaves the aggregator
+// unchanged, if pa contains a party that is not expected, that appears twice in pa or that already contributed.
+// The aggregator takes ownership of the share of the first partial aggregate it is given.
+func (a *ShareAggregator) Add(pa *PartialAggregate) (err error) {
+
+	if len(pa.Parties) == 0 {
+		return errors.New("cannot Add: empty partial aggregate")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	seen := make(map[PartyID]bool, len(pa.Parties))
+	for _, id := range pa.Parties {
+		if !a.expected[id] {
+			return fmt.Errorf("cannot Add: unexpected party %d", id)
+		}
+		if a.received[id] || seen[id] {
+			return fmt.Errorf("cannot Add: duplicate contribution of party %d", id)
+		}
+		seen[id] = true
+	}
+
+	if a.result == nil {
+		a.result = pa.Share
+	} else {
+		a.aggregate(a.result, pa.Share, a.result)
+	}
+
+	for _, id := range pa.Parties {
+		a.received[id] = true
+	}
+
+	return nil
+}
+
+// Complete returns true if all the expected parties contributed.
+func (a *ShareAggregator) Complete() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.received) == len(a.expected)
+}
+
+// Missing returns the sorted list of the expected parties that did not contribute yet.
+func (a *ShareAggregator) Missing() (missing []PartyID) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for id := range a.expected {
+		if !a.received[id] {
+			missing = append(missing, id)
+		}
+	}
+	sortPartyIDs(missing)
+	return
+}
+
+// PartialAggregate returns the current aggregate along with the sorted list of its contributing parties,
+// e.g. to be forwarded to the parent node of a tree. It returns nil if no party contributed yet.
+func (a *ShareAggregator) PartialAggregate() *PartialAggregate {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.result == nil {
+		return nil
+	}
+
+	parties := make([]PartyID, 0, len(a.received))
+	for id := range a.received {
+		parties = append(parties, id)
+	}
+	sortPartyIDs(parties)
+
+	return &PartialAggregate{Parties: parties, Share: a.result}
+}
+
+// Result returns the aggregation of the shares of all the expected parties,
+// or an error listing the missing parties if the aggregation is not complete.
+func (a *ShareAggregator) Result() (share Share, err error) {
+	if missing := a.Missing(); len(missing) > 0 {
+		return nil, fmt.Errorf("cannot Result: missing contributions of parties %v", missing)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.result, nil
+}
+
+func sortPartyIDs(ids []PartyID) {
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+}
@@ -0,0 +1,276 @@
+package drlwe
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// CollectiveDecryptProtocol is the protocol for the collective decryption of a ciphertext encrypted under the collective key.
+// If a receiver public key is given, the parties key-switch the ciphertext to it with the PCKS protocol and only the receiver
+// can decrypt the result. Otherwise, the parties key-switch the ciphertext to the zero key with the CKS protocol and the
+// aggregated share decrypts the ciphertext publicly.
+//
+// The protocol optionally provides a consistency check of the aggregated share: each party sends the ShareDigest of its
+// share directly to the receiver, which checks with VerifyAggregate that the aggregated share is consistent with them.
+type CollectiveDecryptProtocol struct {
+	params   rlwe.Parameters
+	receiver *rlwe.PublicKey
+	cks      *CKSProtocol
+	pcks     *PCKSProtocol
+	zero     *rlwe.SecretKey
+	ctBuff   *rlwe.Ciphertext
+}
+
+// CollectiveDecryptShare is a party's share in the CollectiveDecrypt protocol. It stores one polynomial for a public decryption
+// and two polynomials for a decryption to a receiver.
+type CollectiveDecryptShare struct {
+	Value []*ring.Poly
+}
+
+// ShareDigest is a short linear digest of a CollectiveDecryptShare: the evaluations of each polynomial of the share, modulo each
+// prime of Q, at random points derived from a seed. Since it is linear, the digest of an aggregated share is the sum of the
+// digests of the aggregated shares.
+type ShareDigest struct {
+	Value []uint64
+}
+
+// NewCollectiveDecryptProtocol creates a new CollectiveDecryptProtocol instance. The output is only decryptable by the owner
+// of the secret key of receiver or, if receiver is nil, publicly. sigmaSmudging is the standard deviation of the smudging noise.
+func NewCollectiveDecryptProtocol(params rlwe.Parameters, receiver *rlwe.PublicKey, sigmaSmudging float64) *CollectiveDecryptProtocol {
+	cdp := &CollectiveDecryptProtocol{params: params, receiver: receiver}
+	if receiver != nil {
+		cdp.pcks = NewPCKSProtocol(params, sigmaSmudging)
+	} else {
+		cdp.cks = NewCKSProtocol(params, sigmaSmudging)
+		cdp.zero = rlwe.NewSecretKey(params)
+	}
+	cdp.ctBuff = rlwe.NewCiphertext(params, 1, params.MaxLevel())
+	return cdp
+}
+
+// ShallowCopy creates a shallow copy of CollectiveDecryptProtocol in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// CollectiveDecryptProtocol can be used concurrently.
+func (cdp *CollectiveDecryptProtocol) ShallowCopy() *CollectiveDecryptProtocol {
+	cdpCopy := &CollectiveDecryptProtocol{params: cdp.params, receiver: cdp.receiver, zero: cdp.zero, ctBuff: rlwe.NewCiphertext(cdp.params, 1, cdp.params.MaxLevel())}
+	if cdp.pcks != nil {
+		cdpCopy.pcks = cdp.pcks.ShallowCopy()
+	} else {
+		cdpCopy.cks = cdp.cks.ShallowCopy()
+	}
+	return cdpCopy
+}
+
+// WithPRNG creates a shallow copy of CollectiveDecryptProtocol whose samplers draw their randomness from prng,
+// making the generated shares reproducible from the seed of prng. If prng is nil, the randomness is drawn from crypto/rand.
+func (cdp *CollectiveDecryptProtocol) WithPRNG(prng utils.PRNG) *CollectiveDecryptProtocol {
+	cdpCopy := &CollectiveDecryptProtocol{params: cdp.params, receiver: cdp.receiver, zero: cdp.zero, ctBuff: rlwe.NewCiphertext(cdp.params, 1, cdp.params.MaxLevel())}
+	if cdp.pcks != nil {
+		cdpCopy.pcks = cdp.pcks.WithPRNG(prng)
+	} else {
+		cdpCopy.cks = cdp.cks.WithPRNG(prng)
+	}
+	return cdpCopy
+}
+
+// Public returns true if the protocol decrypts publicly, i.e. without a designated receiver.
+func (cdp *CollectiveDecryptProtocol) Public() bool {
+	return cdp.receiver == nil
+}
+
+// AllocateShare allocates a party's share in the CollectiveDecrypt protocol at the given level.
+func (cdp *CollectiveDecryptProtocol) AllocateShare(level int) *CollectiveDecryptShare {
+	if cdp.Public() {
+		return &CollectiveDecryptShare{Value: []*ring.Poly{cdp.params.RingQ().NewPolyLvl(level)}}
+	}
+	share := cdp.pcks.AllocateShare(level)
+	return &CollectiveDecryptShare{Value: share.Value[:]}
+}
+
+// GenShare generates a party's share in the CollectiveDecrypt protocol for the ciphertext ct, encrypted under the collective key.
+func (cdp *CollectiveDecryptProtocol) GenShare(sk *rlwe.SecretKey, ct *rlwe.Ciphertext, shareOut *CollectiveDecryptShare) {
+	if cdp.Public() {
+		cdp.cks.GenShare(sk, cdp.zero, ct.Value[1], &CKSShare{Value: shareOut.Value[0]})
+	} else {
+		cdp.pcks.GenShare(sk, cdp.receiver, ct.Value[1], &PCKSShare{Value: [2]*ring.Poly{shareOut.Value[0], shareOut.Value[1]}})
+	}
+}
+
+// AggregateShare aggregates two shares in the CollectiveDecrypt protocol.
+func (cdp *CollectiveDecryptProtocol) AggregateShare(share1, share2, shareOut *CollectiveDecryptShare) {
+	level := utils.MinInt(share1.Value[0].Level(), share2.Value[0].Level())
+	for i := range shareOut.Value {
+		cdp.params.RingQ().AddLvl(level, share1.Value[i], share2.Value[i], shareOut.Value[i])
+	}
+}
+
+// KeySwitch applies the aggregated share to the ciphertext ct and writes the result on ctOut. For a public decryption, ctOut
+// is a degree-0 ciphertext, i.e. the plaintext. Otherwise, ctOut is a degree-1 ciphertext encrypted under the key of the receiver.
+func (cdp *CollectiveDecryptProtocol) KeySwitch(ct *rlwe.Ciphertext, aggregate *CollectiveDecryptShare, ctOut *rlwe.Ciphertext) {
+	level := utils.MinInt(utils.MinInt(ct.Level(), aggregate.Value[0].Level()), ctOut.Level())
+	ctOut.Resize(cdp.params, len(aggregate.Value)-1)
+	cdp.params.RingQ().AddLvl(level, ct.Value[0], aggregate.Value[0], ctOut.Value[0])
+	if !cdp.Public() {
+		ring.CopyValuesLvl(level, aggregate.Value[1], ctOut.Value[1])
+	}
+	for _, pol := range ctOut.Value {
+		pol.IsNTT = ct.Value[0].IsNTT
+	}
+}
+
+// Decrypt applies the aggregated share to the ciphertext ct and decrypts the result on ptOut with the secret key of the
+// receiver. For a public decryption, skReceiver is ignored and can be nil.
+// The level of the output plaintext is min(ct.Level(), aggregate.Value[0].Level(), ptOut.Level()).
+func (cdp *CollectiveDecryptProtocol) Decrypt(skReceiver *rlwe.SecretKey, ct *rlwe.Ciphertext, aggregate *CollectiveDecryptShare, ptOut *rlwe.Plaintext) {
+
+	if cdp.Public() {
+		skReceiver = cdp.zero
+	} else if skReceiver == nil {
+		panic("cannot Decrypt: the secret key of the receiver is required")
+	}
+
+	level := utils.MinInt(utils.MinInt(ct.Level(), aggregate.Value[0].Level()), ptOut.Level())
+
+	cdp.KeySwitch(ct, aggregate, cdp.ctBuff)
+
+	ctOut := &rlwe.Ciphertext{Value: make([]*ring.Poly, len(cdp.ctBuff.Value))}
+	for i, pol := range cdp.ctBuff.Value {
+		ctOut.Value[i] = &ring.Poly{Coeffs: pol.Coeffs[:level+1], IsNTT: pol.IsNTT}
+	}
+
+	rlwe.NewDecryptor(cdp.params, skReceiver).Decrypt(ctOut, ptOut)
+}
+
+// GenShareDigest returns the digest of the share for the points derived from seed, which must be known to the parties and
+// to the receiver only. nbPoints is the number of evaluation points per polynomial and per prime.
+func (cdp *CollectiveDecryptProtocol) GenShareDigest(seed []byte, nbPoints int, share *CollectiveDecryptShare) *ShareDigest {
+
+	prng, err := utils.NewKeyedPRNG(seed)
+	if err != nil {
+		panic(err)
+	}
+
+	ringQ := cdp.params.RingQ()
+	level := share.Value[0].Level()
+	digest := &ShareDigest{Value: make([]uint64, 0, len(share.Value)*(level+1)*nbPoints)}
+	buff := make([]byte, 8)
+
+	for _, pol := range share.Value {
+		for i, qi := range ringQ.Modulus[:level+1] {
+			bredParams := ringQ.BredParams[i]
+			for k := 0; k < nbPoints; k++ {
+				prng.Clock(buff)
+				x := binary.LittleEndian.Uint64(buff) % qi
+				// Horner evaluation of the polynomial at x mod qi
+				var y uint64
+				coeffs := pol.Coeffs[i]
+				for j := len(coeffs) - 1; j >= 0; j-- {
+					y = ring.CRed(ring.BRed(y, x, qi, bredParams)+coeffs[j], qi)
+				}
+				digest.Value = append(digest.Value, y)
+			}
+		}
+	}
+
+	return digest
+}
+
+// VerifyAggregate checks, with the digests of the shares of all the parties generated with GenShareDigest for the same seed
+// and number of points, that the aggregated share is the aggregation of the shares of the parties. It returns an error if the
+// aggregated share is inconsistent with the digests.
+func (cdp *CollectiveDecryptProtocol) VerifyAggregate(seed []byte, nbPoints int, aggregate *CollectiveDecryptShare, digests []*ShareDigest) (err error) {
+
+	if len(digests) == 0 {
+		return errors.New("cannot VerifyAggregate: no digest")
+	}
+
+	ringQ := cdp.params.RingQ()
+	level := aggregate.Value[0].Level()
+	want := cdp.GenShareDigest(seed, nbPoints, aggregate)
+
+	sum := make([]uint64, len(want.Value))
+	for i, digest := range digests {
+		if len(digest.Value) != len(sum) {
+			return fmt.Errorf("cannot VerifyAggregate: digest %d has an invalid length", i)
+		}
+		for j := range sum {
+			qi := ringQ.Modulus[(j/nbPoints)%(level+1)]
+			sum[j] = ring.CRed(sum[j]+digest.Value[j], qi)
+		}
+	}
+
+	if !utils.EqualSliceUint64(sum, want.Value) {
+		return errors.New("inconsistent aggregated share")
+	}
+
+	return nil
+}
+
+// MarshalBinary encodes the share on a slice of bytes.
+func (share *CollectiveDecryptShare) MarshalBinary() (data []byte, err error) {
+
+	dataLen := 1
+	for _, pol := range share.Value {
+		dataLen += pol.GetDataLen(true)
+	}
+
+	data = make([]byte, dataLen)
+	data[0] = uint8(len(share.Value))
+
+	ptr, inc := 1, 0
+	for _, pol := range share.Value {
+		if inc, err = pol.WriteTo(data[ptr:]); err != nil {
+			return nil, err
+		}
+		ptr += inc
+	}
+
+	return data, nil
+}
+
+// UnmarshalBinary decodes a slice of bytes on the share.
+func (share *CollectiveDecryptShare) UnmarshalBinary(data []byte) (err error) {
+
+	if len(data) < 1 {
+		return errors.New("cannot UnmarshalBinary: data is too short")
+	}
+
+	share.Value = make([]*ring.Poly, data[0])
+
+	ptr, inc := 1, 0
+	for i := range share.Value {
+		share.Value[i] = new(ring.Poly)
+		if inc, err = share.Value[i].DecodePolyNew(data[ptr:]); err != nil {
+			return err
+		}
+		ptr += inc
+	}
+
+	return nil
+}
+
+// MarshalBinary encodes the digest on a slice of bytes.
+func (digest *ShareDigest) MarshalBinary() (data []byte, err error) {
+	data = make([]byte, 8*len(digest.Value))
+	for i, v := range digest.Value {
+		binary.LittleEndian.PutUint64(data[8*i:], v)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary decodes a slice of bytes on the digest.
+func (digest *ShareDigest) UnmarshalBinary(data []byte) (err error) {
+	if len(data)%8 != 0 {
+		return errors.New("cannot UnmarshalBinary: invalid data length")
+	}
+	digest.Value = make([]uint64, len(data)/8)
+	for i := range digest.Value {
+		digest.Value[i] = binary.LittleEndian.Uint64(data[8*i:])
+	}
+	return nil
+}
@@ -86,6 +86,10 @@ func TestDRLWE(t *testing.T) {
 			testPublicKeySwitching,
 			testRelinKeyGen,
 			testRotKeyGen,
+			testShareProofs,
+			testShareAggregator,
+			testSmudging,
+			testWithPRNG,
 			testMarshalling,
 		} {
 			testSet(textCtx, t)
@@ -94,6 +98,69 @@ func TestDRLWE(t *testing.T) {
 	}
 }
 
+func TestDRLWEGaussianSamplers(t *testing.T) {
+
+	for _, samplerType := range []ring.GaussianSamplerType{ring.GaussianCDT, ring.GaussianConvolution} {
+
+		paramsLit := TestParams[0]
+		paramsLit.GaussianSamplerType = samplerType
+
+		params, err := rlwe.NewParametersFromLiteral(paramsLit)
+		if err != nil {
+			panic(err)
+		}
+
+		textCtx := newTestContext(params)
+
+		for _, testSet := range []func(textCtx testContext, t *testing.T){
+			testPublicKeyGen,
+			testKeySwitching,
+			testPublicKeySwitching,
+			testRelinKeyGen,
+			testRotKeyGen,
+			testShareProofs,
+		} {
+			testSet(textCtx, t)
+			runtime.GC()
+		}
+
+		t.Run(testString(params, "GaussianSamplers/"+samplerType.String()), func(t *testing.T) {
+			// large smudging noise is sampled in constant time by convolution
+			cks := NewCKSProtocol(params, math.Exp2(20))
+			require.IsType(t, &ring.ConvolutionGaussianSampler{}, cks.gaussianSampler)
+			pcks := NewPCKSProtocol(params, math.Exp2(20))
+			require.IsType(t, &ring.ConvolutionGaussianSampler{}, pcks.gaussianSampler)
+		})
+	}
+}
+
+func TestDRLWEDistributions(t *testing.T) {
+
+	paramsLit := TestParams[0]
+	paramsLit.Xs = &ring.Distribution{Type: ring.TernaryProbability, P: 0.5}
+	paramsLit.Xe = &ring.Distribution{Type: ring.UniformRange, Min: -4, Max: 4}
+	paramsLit.Xu = &ring.Distribution{Type: ring.Binary}
+
+	params, err := rlwe.NewParametersFromLiteral(paramsLit)
+	if err != nil {
+		panic(err)
+	}
+
+	textCtx := newTestContext(params)
+
+	for _, testSet := range []func(textCtx testContext, t *testing.T){
+		testPublicKeyGen,
+		testKeySwitching,
+		testPublicKeySwitching,
+		testRelinKeyGen,
+		testRotKeyGen,
+		testShareProofs,
+	} {
+		testSet(textCtx, t)
+		runtime.GC()
+	}
+}
+
 func testPublicKeyGen(testCtx testContext, t *testing.T) {
 
 	params := testCtx.params
@@ -166,6 +233,11 @@ func testKeySwitching(testCtx testContext, t *testing.T) {
 
 		var _ KeySwitchingProtocol = cks[0]
 
+		// The copies, and the copies of the copies, keep the standard deviation of the smudging noise
+		require.Equal(t, rlwe.DefaultSigma, cks[1].sigmaSmudging)
+		require.Equal(t, rlwe.DefaultSigma, cks[1].ShallowCopy().sigmaSmudging)
+		require.Equal(t, rlwe.DefaultSigma, NewKeyRotationProtocol(params, rlwe.DefaultSigma).ShallowCopy().ShallowCopy().sigmaSmudging)
+
 		skout := make([]*rlwe.SecretKey, nbParties)
 		skOutIdeal := rlwe.NewSecretKey(params)
 		for i := range skout {
@@ -351,6 +423,232 @@ func testRelinKeyGen(testCtx testContext, t *testing.T) {
 	})
 }
 
+func testShareProofs(testCtx testContext, t *testing.T) {
+
+	params := testCtx.params
+	ringQ := params.RingQ()
+
+	ckg := NewCKGProtocol(params)
+	ckgCRP := ckg.SampleCRP(testCtx.crs)
+	ckgShares := make([]*CKGShare, nbParties)
+	for i := range ckgShares {
+		ckgShares[i] = ckg.AllocateShare()
+		ckg.GenShare(testCtx.skShares[i], ckgCRP, ckgShares[i])
+	}
+
+	t.Run(testString(params, "ShareProofs/CKG"), func(t *testing.T) {
+
+		proof, err := ckg.GenShareProof(testCtx.skShares[0], ckgCRP, ckgShares[0])
+		require.NoError(t, err)
+		require.True(t, ckg.VerifyShare(ckgCRP, ckgShares[0], proof))
+
+		// Proof for the share of another party
+		require.False(t, ckg.VerifyShare(ckgCRP, ckgShares[1], proof))
+
+		// Share generated with another secret key
+		_, err = ckg.GenShareProof(testCtx.skShares[1], ckgCRP, ckgShares[0])
+		require.Error(t, err)
+
+		// Malformed share
+		share := ckg.AllocateShare()
+		share.Value.Copy(ckgShares[0].Value)
+		for i := range share.Value.Q.Coeffs {
+			share.Value.Q.Coeffs[i][0] = ring.CRed(share.Value.Q.Coeffs[i][0]+1, ringQ.Modulus[i])
+		}
+		require.False(t, ckg.VerifyShare(ckgCRP, share, proof))
+		_, err = ckg.GenShareProof(testCtx.skShares[0], ckgCRP, share)
+		require.Error(t, err)
+	})
+
+	t.Run(testString(params, "ShareProofs/RKG"), func(t *testing.T) {
+
+		if params.PCount() == 0 {
+			t.Skip("method is unsuported when params.PCount() == 0")
+		}
+
+		rkg := NewRKGProtocol(params)
+		crp := rkg.SampleCRP(testCtx.crs)
+
+		ephSk := make([]*rlwe.SecretKey, nbParties)
+		share1 := make([]*RKGShare, nbParties)
+		share2 := make([]*RKGShare, nbParties)
+		for i := range ephSk {
+			ephSk[i], share1[i], share2[i] = rkg.AllocateShare()
+			rkg.GenShareRoundOne(testCtx.skShares[i], crp, ephSk[i], share1[i])
+		}
+
+		proof, err := rkg.GenShareRoundOneProof(testCtx.skShares[0], ephSk[0], crp, share1[0])
+		require.NoError(t, err)
+		require.True(t, rkg.VerifyShareRoundOne(crp, share1[0], proof))
+		require.False(t, rkg.VerifyShareRoundOne(crp, share1[1], proof))
+
+		_, err = rkg.GenShareRoundOneProof(testCtx.skShares[0], ephSk[1], crp, share1[0])
+		require.Error(t, err)
+
+		_, round1Aggregated, _ := rkg.AllocateShare()
+		rkg.AggregateShare(share1[0], share1[1], round1Aggregated)
+		for i := 2; i < nbParties; i++ {
+			rkg.AggregateShare(round1Aggregated, share1[i], round1Aggregated)
+		}
+
+		for i := range share2 {
+			rkg.GenShareRoundTwo(ephSk[i], testCtx.skShares[i], round1Aggregated, share2[i])
+		}
+
+		proof, err = rkg.GenShareRoundTwoProof(ephSk[0], testCtx.skShares[0], crp, share1[0], round1Aggregated, share2[0])
+		require.NoError(t, err)
+		require.True(t, rkg.VerifyShareRoundTwo(crp, share1[0], round1Aggregated, share2[0], proof))
+		require.False(t, rkg.VerifyShareRoundTwo(crp, share1[1], round1Aggregated, share2[0], proof))
+
+		// Round two share generated with an ephemeral key that differs from the one of round one
+		rkg.GenShareRoundTwo(ephSk[1], testCtx.skShares[0], round1Aggregated, share2[0])
+		_, err = rkg.GenShareRoundTwoProof(ephSk[0], testCtx.skShares[0], crp, share1[0], round1Aggregated, share2[0])
+		require.Error(t, err)
+	})
+
+	t.Run(testString(params, "ShareProofs/CKS"), func(t *testing.T) {
+
+		cks := NewCKSProtocol(params, rlwe.DefaultSigma)
+
+		skOut := testCtx.kgen.GenSecretKey()
+		ckgShareOut := ckg.AllocateShare()
+		ckg.GenShare(skOut, ckgCRP, ckgShareOut)
+
+		input := CKGCommitment{CRP: ckgCRP, Share: ckgShares[0]}
+		output := &CKGCommitment{CRP: ckgCRP, Share: ckgShareOut}
+
+		// tampered is an output key that differs from the committed one
+		tampered := testCtx.kgen.GenSecretKey()
+
+		for _, isNTT := range []bool{true, false} {
+
+			c1 := ringQ.NewPoly()
+			testCtx.uniformSampler.Read(c1)
+			c1.IsNTT = isNTT
+
+			share := cks.AllocateShare(c1.Level())
+			cks.GenShare(testCtx.skShares[0], skOut, c1, share)
+
+			proof, err := cks.GenShareProof(testCtx.skShares[0], skOut, c1, share, input, output)
+			require.NoError(t, err)
+			require.True(t, cks.VerifyShare(c1, share, input, output, proof))
+
+			// The share must be generated with the secret key of the public key share
+			require.False(t, cks.VerifyShare(c1, share, CKGCommitment{CRP: ckgCRP, Share: ckgShares[1]}, output, proof))
+			_, err = cks.GenShareProof(testCtx.skShares[0], skOut, c1, share, CKGCommitment{CRP: ckgCRP, Share: ckgShares[1]}, output)
+			require.Error(t, err)
+
+			// The share must be generated with the committed output key
+			shareTampered := cks.AllocateShare(c1.Level())
+			cks.GenShare(testCtx.skShares[0], tampered, c1, shareTampered)
+			require.False(t, cks.VerifyShare(c1, shareTampered, input, output, proof))
+			_, err = cks.GenShareProof(testCtx.skShares[0], tampered, c1, shareTampered, input, output)
+			require.Error(t, err)
+
+			data, err := proof.MarshalBinary()
+			require.NoError(t, err)
+			proofNew := new(ShareProof)
+			require.NoError(t, proofNew.UnmarshalBinary(data))
+			require.Equal(t, proof, proofNew)
+
+			// Decryption shares are bound to the zero output key
+			zero := rlwe.NewSecretKey(params)
+			cks.GenShare(testCtx.skShares[0], zero, c1, share)
+			proof, err = cks.GenShareProof(testCtx.skShares[0], nil, c1, share, input, nil)
+			require.NoError(t, err)
+			require.True(t, cks.VerifyShare(c1, share, input, nil, proof))
+			require.False(t, cks.VerifyShare(c1, share, input, output, proof))
+
+			cks.GenShare(testCtx.skShares[0], tampered, c1, shareTampered)
+			require.False(t, cks.VerifyShare(c1, shareTampered, input, nil, proof))
+			_, err = cks.GenShareProof(testCtx.skShares[0], nil, c1, shareTampered, input, nil)
+			require.Error(t, err)
+		}
+	})
+
+	t.Run(testString(params, "ShareProofs/CKS/LargeSmudging"), func(t *testing.T) {
+
+		// The masks of the proof cannot be represented for such a smudging noise
+		cks := NewCKSProtocol(params, math.Exp2(50))
+
+		c1 := ringQ.NewPoly()
+		testCtx.uniformSampler.Read(c1)
+		c1.IsNTT = true
+
+		share := cks.AllocateShare(c1.Level())
+		cks.GenShare(testCtx.skShares[0], rlwe.NewSecretKey(params), c1, share)
+
+		input := CKGCommitment{CRP: ckgCRP, Share: ckgShares[0]}
+		_, err := cks.GenShareProof(testCtx.skShares[0], nil, c1, share, input, nil)
+		require.Error(t, err)
+		require.False(t, cks.VerifyShare(c1, share, input, nil, &ShareProof{}))
+	})
+}
+
+func testShareAggregator(testCtx testContext, t *testing.T) {
+
+	params := testCtx.params
+
+	t.Run(testString(params, "ShareAggregator"), func(t *testing.T) {
+
+		ckg := NewCKGProtocol(params)
+		crp := ckg.SampleCRP(testCtx.crs)
+
+		aggregate := func(share1, share2, shareOut Share) {
+			ckg.AggregateShare(share1.(*CKGShare), share2.(*CKGShare), shareOut.(*CKGShare))
+		}
+
+		parties := make([]PartyID, nbParties)
+		shares := make([]*CKGShare, nbParties)
+		for i := range shares {
+			parties[i] = PartyID(i)
+			shares[i] = ckg.AllocateShare()
+			ckg.GenShare(testCtx.skShares[i], crp, shares[i])
+		}
+
+		want := ckg.AllocateShare()
+		for i := range shares {
+			ckg.AggregateShare(want, shares[i], want)
+		}
+
+		// Leaf node aggregating all the parties but the first one.
+		leaf := NewShareAggregator(parties[1:], aggregate)
+		for i := 1; i < nbParties; i++ {
+			require.NoError(t, leaf.Add(NewPartialAggregate(parties[i], shares[i])))
+		}
+		require.True(t, leaf.Complete())
+		require.Error(t, leaf.Add(NewPartialAggregate(parties[1], ckg.AllocateShare())))
+		require.Error(t, leaf.Add(NewPartialAggregate(parties[0], shares[0])))
+
+		// Serialization of the partial aggregate sent to the root.
+		data, err := leaf.PartialAggregate().MarshalBinary()
+		require.NoError(t, err)
+		partial := &PartialAggregate{Share: ckg.AllocateShare()}
+		require.NoError(t, partial.UnmarshalBinary(data))
+		require.Equal(t, parties[1:], partial.Parties)
+
+		root := NewShareAggregator(parties, aggregate)
+		require.False(t, root.Complete())
+		require.NoError(t, root.Add(partial))
+		require.Equal(t, []PartyID{parties[0]}, root.Missing())
+
+		_, err = root.Result()
+		require.Error(t, err)
+
+		// Double counting a party already contained in a partial aggregate is refused.
+		require.Error(t, root.Add(&PartialAggregate{Parties: []PartyID{parties[0], parties[1]}, Share: ckg.AllocateShare()}))
+		require.Equal(t, []PartyID{parties[0]}, root.Missing())
+
+		require.NoError(t, root.Add(NewPartialAggregate(parties[0], shares[0])))
+		require.True(t, root.Complete())
+		require.Empty(t, root.Missing())
+
+		result, err := root.Result()
+		require.NoError(t, err)
+		require.True(t, want.Value.Equals(result.(*CKGShare).Value))
+	})
+}
+
 func testRotKeyGen(testCtx testContext, t *testing.T) {
 
 	params := testCtx.params
@@ -438,6 +736,208 @@ func testRotKeyGen(testCtx testContext, t *testing.T) {
 	})
 }
 
+func testSmudging(testCtx testContext, t *testing.T) {
+
+	params := testCtx.params
+	ringQ := params.RingQ()
+
+	// A fresh ciphertext has a noise bounded by 6*sigma < 2^5
+	sp := NewSmudgingParameters(params, nbParties, 5, 16, 40)
+
+	logMinModulus := math.Inf(1)
+	for _, qi := range params.Q() {
+		logMinModulus = math.Min(logMinModulus, math.Log2(float64(qi)))
+	}
+
+	sigma, logNoise, ok := sp.Estimate()
+
+	t.Run(testString(params, "Smudging/Estimate"), func(t *testing.T) {
+
+		require.Equal(t, logMinModulus, sp.LogMaxSample)
+		require.True(t, ok)
+		require.Equal(t, math.Exp2(21), sigma)
+
+		// Not enough room on the output
+		spNoRoom := sp
+		spNoRoom.LogRoom = logNoise - 1
+		_, err := spNoRoom.Sigma()
+		require.Error(t, err)
+		_, err = NewCKSProtocolWithSmudging(params, spNoRoom)
+		require.Error(t, err)
+
+		// Smudging noise larger than the moduli
+		_, _, ok = NewSmudgingParameters(params, nbParties, 5, 64, 200).Estimate()
+		require.False(t, ok)
+		_, err = NewPCKSProtocolWithSmudging(params, NewSmudgingParameters(params, nbParties, 5, 64, 200))
+		require.Error(t, err)
+	})
+
+	// The measured noise of the key-switched ciphertexts must match the estimate: the estimate is a
+	// worst-case bound and the smudging noise must not be scaled down by the special modulus P.
+	requireNoiseMatchesEstimate := func(t *testing.T, ct *rlwe.Ciphertext, skOut *rlwe.SecretKey) {
+		pt := ringQ.NewPolyLvl(ct.Level())
+		ringQ.MulCoeffsMontgomeryLvl(ct.Level(), ct.Value[1], skOut.Value.Q, pt)
+		ringQ.AddLvl(ct.Level(), pt, ct.Value[0], pt)
+		ringQ.InvNTTLvl(ct.Level(), pt, pt)
+		logMeasured := math.Log2(maxNormCentered(ringQ.Modulus[0], pt.Coeffs[0]))
+		require.LessOrEqual(t, logMeasured, logNoise)
+		require.GreaterOrEqual(t, logMeasured, logNoise-3)
+	}
+
+	newCiphertext := func() *rlwe.Ciphertext {
+		ct := rlwe.NewCiphertextNTT(params, 1, params.MaxLevel())
+		rlwe.NewEncryptor(params, testCtx.skIdeal).Encrypt(rlwe.NewPlaintext(params, params.MaxLevel()), ct)
+		return ct
+	}
+
+	t.Run(testString(params, "Smudging/KeySwitching"), func(t *testing.T) {
+
+		cks, err := NewCKSProtocolWithSmudging(params, sp)
+		require.NoError(t, err)
+		require.Equal(t, sigma, cks.sigmaSmudging)
+
+		skOut := rlwe.NewSecretKey(params)
+		skOutShares := make([]*rlwe.SecretKey, nbParties)
+		for i := range skOutShares {
+			skOutShares[i] = testCtx.kgen.GenSecretKey()
+			params.RingQP().AddLvl(params.QCount()-1, params.PCount()-1, skOut.Value, skOutShares[i].Value, skOut.Value)
+		}
+
+		ct := newCiphertext()
+
+		share, agg := cks.AllocateShare(ct.Level()), cks.AllocateShare(ct.Level())
+		for i := range skOutShares {
+			cks.GenShare(testCtx.skShares[i], skOutShares[i], ct.Value[1], share)
+			if i == 0 {
+				agg.Value.Copy(share.Value)
+			} else {
+				cks.AggregateShare(share, agg, agg)
+			}
+		}
+
+		ctOut := rlwe.NewCiphertextNTT(params, 1, ct.Level())
+		cks.KeySwitch(ct, agg, ctOut)
+
+		requireNoiseMatchesEstimate(t, ctOut, skOut)
+	})
+
+	t.Run(testString(params, "Smudging/PublicKeySwitching"), func(t *testing.T) {
+
+		pcks, err := NewPCKSProtocolWithSmudging(params, sp)
+		require.NoError(t, err)
+		require.Equal(t, sigma, pcks.sigmaSmudging)
+
+		skOut, pkOut := testCtx.kgen.GenKeyPair()
+
+		ct := newCiphertext()
+
+		share, agg := pcks.AllocateShare(ct.Level()), pcks.AllocateShare(ct.Level())
+		for i := range testCtx.skShares {
+			pcks.GenShare(testCtx.skShares[i], pkOut, ct.Value[1], share)
+			if i == 0 {
+				agg.Value[0].Copy(share.Value[0])
+				agg.Value[1].Copy(share.Value[1])
+			} else {
+				pcks.AggregateShare(share, agg, agg)
+			}
+		}
+
+		ctOut := rlwe.NewCiphertextNTT(params, 1, ct.Level())
+		pcks.KeySwitch(ct, agg, ctOut)
+
+		requireNoiseMatchesEstimate(t, ctOut, skOut)
+	})
+}
+
+// maxNormCentered returns the largest absolute value of the coefficients mod q, centered in (-q/2, q/2].
+func maxNormCentered(q uint64, coeffs []uint64) (max float64) {
+	for _, c := range coeffs {
+		if c > q>>1 {
+			c = q - c
+		}
+		max = math.Max(max, float64(c))
+	}
+	return
+}
+
+func testWithPRNG(testCtx testContext, t *testing.T) {
+
+	params := testCtx.params
+	ringQ := params.RingQ()
+	sk := testCtx.skShares[0]
+
+	newPRNG := func() utils.PRNG {
+		prng, err := utils.NewKeyedPRNG([]byte{'l', 'a', 't', 't', 'i', 'g', 'o'})
+		if err != nil {
+			panic(err)
+		}
+		return prng
+	}
+
+	marshal := func(obj interface{ MarshalBinary() ([]byte, error) }) []byte {
+		data, err := obj.MarshalBinary()
+		require.NoError(t, err)
+		return data
+	}
+
+	t.Run(testString(params, "WithPRNG/KeyGen"), func(t *testing.T) {
+
+		ckg := NewCKGProtocol(params)
+		ckgCRP := ckg.SampleCRP(testCtx.crs)
+		ckg1, ckg2 := ckg.WithPRNG(newPRNG()), ckg.WithPRNG(newPRNG())
+		ckgShare1, ckgShare2 := ckg.AllocateShare(), ckg.AllocateShare()
+		ckg1.GenShare(sk, ckgCRP, ckgShare1)
+		ckg2.GenShare(sk, ckgCRP, ckgShare2)
+		require.Equal(t, marshal(ckgShare1), marshal(ckgShare2))
+
+		// The copies are keyed from the PRNG of their parent
+		ckg1.ShallowCopy().GenShare(sk, ckgCRP, ckgShare1)
+		ckg2.ShallowCopy().GenShare(sk, ckgCRP, ckgShare2)
+		require.Equal(t, marshal(ckgShare1), marshal(ckgShare2))
+
+		rkg := NewRKGProtocol(params)
+		rkgCRP := rkg.SampleCRP(testCtx.crs)
+		rkg1, rkg2 := rkg.WithPRNG(newPRNG()), rkg.WithPRNG(newPRNG())
+		ephSk1, rkgShare1, _ := rkg.AllocateShare()
+		ephSk2, rkgShare2, _ := rkg.AllocateShare()
+		rkg1.GenShareRoundOne(sk, rkgCRP, ephSk1, rkgShare1)
+		rkg2.GenShareRoundOne(sk, rkgCRP, ephSk2, rkgShare2)
+		require.Equal(t, marshal(rkgShare1), marshal(rkgShare2))
+
+		rtg := NewRTGProtocol(params)
+		galEl := params.GaloisElementForColumnRotationBy(1)
+		rtgCRP := rtg.SampleCRP(testCtx.crs)
+		rtg1, rtg2 := rtg.WithPRNG(newPRNG()), rtg.WithPRNG(newPRNG())
+		rtgShare1, rtgShare2 := rtg.AllocateShare(), rtg.AllocateShare()
+		rtg1.GenShare(sk, galEl, rtgCRP, rtgShare1)
+		rtg2.GenShare(sk, galEl, rtgCRP, rtgShare2)
+		require.Equal(t, marshal(rtgShare1), marshal(rtgShare2))
+	})
+
+	t.Run(testString(params, "WithPRNG/KeySwitching"), func(t *testing.T) {
+
+		c1 := ringQ.NewPoly()
+		testCtx.uniformSampler.Read(c1)
+		c1.IsNTT = true
+
+		cks := NewCKSProtocol(params, rlwe.DefaultSigma)
+		cks1, cks2 := cks.WithPRNG(newPRNG()), cks.WithPRNG(newPRNG())
+		cksShare1, cksShare2 := cks.AllocateShare(params.MaxLevel()), cks.AllocateShare(params.MaxLevel())
+		cks1.GenShare(sk, testCtx.skShares[1], c1, cksShare1)
+		cks2.GenShare(sk, testCtx.skShares[1], c1, cksShare2)
+		require.Equal(t, marshal(cksShare1), marshal(cksShare2))
+
+		_, pk := testCtx.kgen.GenKeyPair()
+
+		pcks := NewPCKSProtocol(params, rlwe.DefaultSigma)
+		pcks1, pcks2 := pcks.WithPRNG(newPRNG()), pcks.WithPRNG(newPRNG())
+		pcksShare1, pcksShare2 := pcks.AllocateShare(params.MaxLevel()), pcks.AllocateShare(params.MaxLevel())
+		pcks1.GenShare(sk, pk, c1, pcksShare1)
+		pcks2.GenShare(sk, pk, c1, pcksShare2)
+		require.Equal(t, marshal(pcksShare1), marshal(pcksShare2))
+	})
+}
+
 func testMarshalling(testCtx testContext, t *testing.T) {
 
 	params := testCtx.params
@@ -477,6 +977,36 @@ func testMarshalling(testCtx testContext, t *testing.T) {
 		}
 	})
 
+	t.Run(testString(params, "Marshalling/PKRKG"), func(t *testing.T) {
+
+		if params.PCount() == 0 {
+			t.Skip("method is unsuported when params.PCount() == 0")
+		}
+
+		rkg := NewPKRKGProtocol(params)
+		share := rkg.AllocateShare()
+		rkg.GenShare(testCtx.skShares[0], rkg.SampleCRP(testCtx.crs), share)
+
+		data, err := share.MarshalBinary()
+		require.NoError(t, err)
+
+		shareAfter := new(PKRKGShare)
+		require.NoError(t, shareAfter.UnmarshalBinary(data))
+
+		require.Equal(t, len(share.Value), len(shareAfter.Value))
+		for i := range share.Value {
+			for j := range share.Value[i] {
+				require.True(t, share.Value[i][j].Equals(shareAfter.Value[i][j]))
+			}
+		}
+
+		// Empty and truncated data are rejected
+		require.Error(t, new(PKRKGShare).UnmarshalBinary(nil))
+		for _, n := range []int{1, 2, 5, len(data) / 2, len(data) - 1} {
+			require.Error(t, new(PKRKGShare).UnmarshalBinary(data[:n]))
+		}
+	})
+
 	t.Run(testString(params, "Marshalling/PCKS"), func(t *testing.T) {
 		//Check marshalling for the PCKS
 
@@ -521,6 +1051,31 @@ func testMarshalling(testCtx testContext, t *testing.T) {
 		require.Equal(t, cksshare.Value.Coeffs, cksshareAfter.Value.Coeffs)
 	})
 
+	t.Run(testString(params, "Marshalling/CollectiveDecrypt"), func(t *testing.T) {
+
+		_, pkOut := testCtx.kgen.GenKeyPair()
+		cdp := NewCollectiveDecryptProtocol(testCtx.params, pkOut, testCtx.params.Sigma())
+		share := cdp.AllocateShare(ciphertext.Level())
+		cdp.GenShare(testCtx.skShares[0], ciphertext, share)
+
+		data, err := share.MarshalBinary()
+		require.NoError(t, err)
+		shareAfter := new(CollectiveDecryptShare)
+		require.NoError(t, shareAfter.UnmarshalBinary(data))
+
+		require.Equal(t, len(share.Value), len(shareAfter.Value))
+		for i := range share.Value {
+			require.True(t, share.Value[i].Equals(shareAfter.Value[i]))
+		}
+
+		digest := cdp.GenShareDigest([]byte{'d', 'i', 'g', 'e', 's', 't'}, 2, share)
+		data, err = digest.MarshalBinary()
+		require.NoError(t, err)
+		digestAfter := new(ShareDigest)
+		require.NoError(t, digestAfter.UnmarshalBinary(data))
+		require.Equal(t, digest.Value, digestAfter.Value)
+	})
+
 	t.Run(testString(params, "Marshalling/RKG"), func(t *testing.T) {
 
 		if params.PCount() == 0 {
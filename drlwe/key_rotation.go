@@ -0,0 +1,63 @@
+package drlwe
+
+import (
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// KeyRotationProtocol is the protocol for the rotation of a long-lived collective key. It lets an old committee, holding
+// the additive shares of the current collective secret key, hand over to a new committee, which may share members with
+// the old one, without decrypting or re-encrypting the data:
+//
+//  1. the members of the new committee sample fresh secret-key shares and generate the new collective public key with the
+//     CKGProtocol,
+//  2. every ciphertext encrypted under the current collective key is switched to the new one: each member of the union of the
+//     two committees generates a share with GenShare, the shares are aggregated and applied with KeySwitch,
+//  3. the members of the old committee that are not in the new one can then discard their shares.
+//
+// Running the protocol with the same committee re-randomizes the shares of the collective key. The evaluation keys of the old
+// collective key must be re-generated for the new one.
+type KeyRotationProtocol struct {
+	CKSProtocol
+	zero *rlwe.SecretKey
+}
+
+// NewKeyRotationProtocol creates a new KeyRotationProtocol instance, where sigmaSmudging is the standard deviation of the
+// smudging noise added to the shares.
+func NewKeyRotationProtocol(params rlwe.Parameters, sigmaSmudging float64) *KeyRotationProtocol {
+	return &KeyRotationProtocol{*NewCKSProtocol(params, sigmaSmudging), rlwe.NewSecretKey(params)}
+}
+
+// ShallowCopy creates a shallow copy of KeyRotationProtocol in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// KeyRotationProtocol can be used concurrently.
+func (krp *KeyRotationProtocol) ShallowCopy() *KeyRotationProtocol {
+	return &KeyRotationProtocol{*krp.CKSProtocol.ShallowCopy(), krp.zero}
+}
+
+// WithPRNG creates a shallow copy of KeyRotationProtocol whose smudging sampler draws its randomness from prng,
+// making the generated shares reproducible from the seed of prng. If prng is nil, the randomness is drawn from crypto/rand.
+func (krp *KeyRotationProtocol) WithPRNG(prng utils.PRNG) *KeyRotationProtocol {
+	return &KeyRotationProtocol{*krp.CKSProtocol.WithPRNG(prng), krp.zero}
+}
+
+// GenShare generates the share of a party in the KeyRotation protocol for the ciphertext component c1, where skOld is the
+// share of the party in the current collective key and skNew its share in the new one. skOld, respectively skNew, must be nil
+// if the party is not a member of the old, respectively new, committee.
+func (krp *KeyRotationProtocol) GenShare(skOld, skNew *rlwe.SecretKey, c1 *ring.Poly, shareOut *CKSShare) {
+
+	if skOld == nil && skNew == nil {
+		panic("cannot GenShare: the party is a member of neither committee")
+	}
+
+	if skOld == nil {
+		skOld = krp.zero
+	}
+
+	if skNew == nil {
+		skNew = krp.zero
+	}
+
+	krp.CKSProtocol.GenShare(skOld, skNew, c1, shareOut)
+}
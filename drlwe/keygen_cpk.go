@@ -1,4 +1,4 @@
-//Package drlwe implements a distributed (or threshold) version of the CKKS scheme that enables secure multiparty computation solutions with secret-shared secret keys.
+// Package drlwe implements a distributed (or threshold) version of the CKKS scheme that enables secure multiparty computation solutions with secret-shared secret keys.
 package drlwe
 
 import (
@@ -17,20 +17,28 @@ type CollectivePublicKeyGenerator interface {
 
 // CKGProtocol is the structure storing the parameters and and precomputations for the collective key generation protocol.
 type CKGProtocol struct {
-	params           rlwe.Parameters
-	gaussianSamplerQ *ring.GaussianSampler
+	params     rlwe.Parameters
+	fork       *utils.PRNGFork
+	xeSamplerQ ring.DistributionSampler
 }
 
 // ShallowCopy creates a shallow copy of CKGProtocol in which all the read-only data-structures are
 // shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
 // CKGProtocol can be used concurrently.
 func (ckg *CKGProtocol) ShallowCopy() *CKGProtocol {
-	prng, err := utils.NewPRNG()
+	prng, err := ckg.fork.Derive()
 	if err != nil {
 		panic(err)
 	}
 
-	return &CKGProtocol{ckg.params, ring.NewGaussianSampler(prng, ckg.params.RingQ(), ckg.params.Sigma(), int(6*ckg.params.Sigma()))}
+	return ckg.WithPRNG(prng)
+}
+
+// WithPRNG creates a shallow copy of CKGProtocol whose error sampler draws its randomness from prng,
+// making the generated shares reproducible from the seed of prng. If prng is nil, the randomness is
+// drawn from crypto/rand.
+func (ckg *CKGProtocol) WithPRNG(prng utils.PRNG) *CKGProtocol {
+	return newCKGProtocol(ckg.params, prng)
 }
 
 // CKGShare is a struct storing the CKG protocol's share.
@@ -58,14 +66,18 @@ func (share *CKGShare) UnmarshalBinary(data []byte) (err error) {
 
 // NewCKGProtocol creates a new CKGProtocol instance
 func NewCKGProtocol(params rlwe.Parameters) *CKGProtocol {
+	return newCKGProtocol(params, nil)
+}
+
+func newCKGProtocol(params rlwe.Parameters, source utils.PRNG) *CKGProtocol {
 	ckg := new(CKGProtocol)
 	ckg.params = params
-	var err error
-	prng, err := utils.NewPRNG()
+	ckg.fork = utils.NewPRNGFork(source)
+	prng, err := utils.DefaultPRNG(source)
 	if err != nil {
 		panic(err)
 	}
-	ckg.gaussianSamplerQ = ring.NewGaussianSampler(prng, params.RingQ(), params.Sigma(), int(6*params.Sigma()))
+	ckg.xeSamplerQ = params.Xe().NewSampler(prng, params.RingQ(), params.GaussianSamplerType())
 	return ckg
 }
 
@@ -90,7 +102,7 @@ func (ckg *CKGProtocol) SampleCRP(crs CRS) CKGCRP {
 func (ckg *CKGProtocol) GenShare(sk *rlwe.SecretKey, crp CKGCRP, shareOut *CKGShare) {
 	ringQP := ckg.params.RingQP()
 
-	ckg.gaussianSamplerQ.Read(shareOut.Value.Q)
+	ckg.xeSamplerQ.Read(shareOut.Value.Q)
 
 	if ringQP.RingP != nil {
 		ringQP.ExtendBasisSmallNormAndCenter(shareOut.Value.Q, ckg.params.PCount()-1, nil, shareOut.Value.P)
@@ -112,3 +124,26 @@ func (ckg *CKGProtocol) GenPublicKey(roundShare *CKGShare, crp CKGCRP, pubkey *r
 	pubkey.Value[0].Copy(roundShare.Value)
 	pubkey.Value[1].Copy(rlwe.PolyQP(crp))
 }
+
+// relation returns the relation share = -crp*s_i + e_i proven by the proofs of the CKG shares.
+func (ckg *CKGProtocol) relation(crp CKGCRP, share *CKGShare) *shareRelation {
+	levelQ, levelP := ckg.params.QCount()-1, ckg.params.PCount()-1
+	rel := newShareRelation(ckg.params, "CKG", []int64{normBound(ckg.params.Xs()), normBound(ckg.params.Xe())})
+	row := rel.addRow(levelQ, levelP, share.Value, 1)
+	rel.addTerm(row, 0, rlwe.PolyQP(crp), true)
+	return rel
+}
+
+// GenShareProof generates a zero-knowledge proof that the share was generated by GenShare from the secret key sk
+// and the common reference polynomial crp, i.e., that share = -crp*s_i + e_i with s_i and e_i bounded as the samples
+// of the distributions Xs and Xe of the parameters. Returns an error if the share is not of this form.
+func (ckg *CKGProtocol) GenShareProof(sk *rlwe.SecretKey, crp CKGCRP, share *CKGShare) (proof *ShareProof, err error) {
+	rel := ckg.relation(crp, share)
+	return rel.prove([][]int64{rel.secretToInt64(sk.Value), nil})
+}
+
+// VerifyShare checks the proof that the share was correctly generated with respect to the common reference
+// polynomial crp. It should be called on each share before aggregating it with AggregateShare.
+func (ckg *CKGProtocol) VerifyShare(crp CKGCRP, share *CKGShare, proof *ShareProof) bool {
+	return ckg.relation(crp, share).verify(proof)
+}
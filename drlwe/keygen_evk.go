@@ -0,0 +1,298 @@
+package drlwe
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// EKGProtocol is the structure storing the parameters for the collective generation of an evaluation-key set, i.e. a
+// relinearization key and the rotation keys of a list of Galois elements, in two rounds. In the first round, each party
+// generates in a single message its shares of the rotation keys and its first-round share of the relinearization key.
+// The second round is only needed for the relinearization key.
+type EKGProtocol struct {
+	RKGProtocol
+	RTGProtocol
+
+	galEls []uint64
+	relin  bool
+}
+
+// EKGShareRoundOne is a struct storing a party's first-round share in the EKG protocol.
+type EKGShareRoundOne struct {
+	RKG *RKGShare
+	RTG map[uint64]*RTGShare
+}
+
+// EKGCRP is a type for the common reference polynomials in the EKG protocol.
+type EKGCRP struct {
+	RKG RKGCRP
+	RTG map[uint64]RTGCRP
+}
+
+// NewEKGProtocol creates a new EKGProtocol instance generating the rotation keys for the Galois elements galEls
+// and, if relin is true, the relinearization key.
+func NewEKGProtocol(params rlwe.Parameters, galEls []uint64, relin bool) *EKGProtocol {
+	ekg := &EKGProtocol{
+		RKGProtocol: *NewRKGProtocol(params),
+		RTGProtocol: *NewRTGProtocol(params),
+		galEls:      sortedGaloisElements(galEls),
+		relin:       relin,
+	}
+	return ekg
+}
+
+// ShallowCopy creates a shallow copy of EKGProtocol in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// EKGProtocol can be used concurrently.
+func (ekg *EKGProtocol) ShallowCopy() *EKGProtocol {
+	return &EKGProtocol{
+		RKGProtocol: *ekg.RKGProtocol.ShallowCopy(),
+		RTGProtocol: *ekg.RTGProtocol.ShallowCopy(),
+		galEls:      ekg.galEls,
+		relin:       ekg.relin,
+	}
+}
+
+// WithPRNG creates a shallow copy of EKGProtocol whose samplers draw their randomness from prng, making the
+// generated shares reproducible from the seed of prng. If prng is nil, the randomness is drawn from crypto/rand.
+func (ekg *EKGProtocol) WithPRNG(prng utils.PRNG) *EKGProtocol {
+	return &EKGProtocol{
+		RKGProtocol: *ekg.RKGProtocol.WithPRNG(prng),
+		RTGProtocol: *ekg.RTGProtocol.WithPRNG(prng),
+		galEls:      ekg.galEls,
+		relin:       ekg.relin,
+	}
+}
+
+// GaloisElements returns the sorted list of Galois elements for which the rotation keys are generated.
+func (ekg *EKGProtocol) GaloisElements() []uint64 {
+	return append([]uint64(nil), ekg.galEls...)
+}
+
+// AllocateShare allocates the shares of the EKG protocol. The ephemeral secret key and the second-round
+// share are nil if the protocol does not generate the relinearization key.
+func (ekg *EKGProtocol) AllocateShare() (ephSk *rlwe.SecretKey, r1 *EKGShareRoundOne, r2 *RKGShare) {
+	r1 = &EKGShareRoundOne{RTG: make(map[uint64]*RTGShare, len(ekg.galEls))}
+	if ekg.relin {
+		ephSk, r1.RKG, r2 = ekg.RKGProtocol.AllocateShare()
+	}
+	for _, galEl := range ekg.galEls {
+		r1.RTG[galEl] = ekg.RTGProtocol.AllocateShare()
+	}
+	return
+}
+
+// SampleCRP samples the common random polynomials of the EKG protocol from the provided common reference string.
+// The polynomials are read as a single stream, first for the relinearization key and then for the rotation keys
+// by increasing Galois element.
+func (ekg *EKGProtocol) SampleCRP(crs CRS) (crp EKGCRP) {
+	crp.RTG = make(map[uint64]RTGCRP, len(ekg.galEls))
+	if ekg.relin {
+		crp.RKG = ekg.RKGProtocol.SampleCRP(crs)
+	}
+	for _, galEl := range ekg.galEls {
+		crp.RTG[galEl] = ekg.RTGProtocol.SampleCRP(crs)
+	}
+	return
+}
+
+// GenShareRoundOne generates a party's first-round share in the EKG protocol, i.e. its shares of all the rotation
+// keys and its first-round share of the relinearization key, along with its ephemeral secret key.
+func (ekg *EKGProtocol) GenShareRoundOne(sk *rlwe.SecretKey, crp EKGCRP, ephSkOut *rlwe.SecretKey, shareOut *EKGShareRoundOne) {
+	if ekg.relin {
+		ekg.RKGProtocol.GenShareRoundOne(sk, crp.RKG, ephSkOut, shareOut.RKG)
+	}
+	for _, galEl := range ekg.galEls {
+		ekg.RTGProtocol.GenShare(sk, galEl, crp.RTG[galEl], shareOut.RTG[galEl])
+	}
+}
+
+// GenShareRoundTwo generates a party's second-round share in the EKG protocol from the aggregated first-round shares.
+// It must only be called if the protocol generates the relinearization key.
+func (ekg *EKGProtocol) GenShareRoundTwo(ephSk, sk *rlwe.SecretKey, round1 *EKGShareRoundOne, shareOut *RKGShare) {
+	if !ekg.relin {
+		panic("cannot GenShareRoundTwo: the protocol does not generate the relinearization key")
+	}
+	ekg.RKGProtocol.GenShareRoundTwo(ephSk, sk, round1.RKG, shareOut)
+}
+
+// CheckShareRoundOne returns an error if share does not hold exactly the first-round shares of the keys generated
+// by the protocol, for example if a share decoded with UnmarshalBinary lacks the share of one of its Galois elements.
+func (ekg *EKGProtocol) CheckShareRoundOne(share *EKGShareRoundOne) error {
+
+	if share == nil {
+		return errors.New("share is nil")
+	}
+
+	if ekg.relin != (share.RKG != nil) {
+		return fmt.Errorf("share of the relinearization key is present: %t, expected: %t", share.RKG != nil, ekg.relin)
+	}
+
+	if share.RKG != nil && len(share.RKG.Value) != ekg.RKGProtocol.params.Beta() {
+		return fmt.Errorf("share of the relinearization key has %d elements, expected %d", len(share.RKG.Value), ekg.RKGProtocol.params.Beta())
+	}
+
+	if len(share.RTG) != len(ekg.galEls) {
+		return fmt.Errorf("share has %d rotation key shares, expected %d", len(share.RTG), len(ekg.galEls))
+	}
+
+	for _, galEl := range ekg.galEls {
+		rtgShare, ok := share.RTG[galEl]
+		if !ok || rtgShare == nil {
+			return fmt.Errorf("share lacks the rotation key share of the Galois element %d", galEl)
+		}
+		if len(rtgShare.Value) != ekg.RTGProtocol.params.Beta() {
+			return fmt.Errorf("rotation key share of the Galois element %d has %d elements, expected %d", galEl, len(rtgShare.Value), ekg.RTGProtocol.params.Beta())
+		}
+	}
+
+	return nil
+}
+
+// AggregateShareRoundOne aggregates two first-round shares in the EKG protocol.
+// It panics if one of the shares does not pass CheckShareRoundOne.
+func (ekg *EKGProtocol) AggregateShareRoundOne(share1, share2, shareOut *EKGShareRoundOne) {
+	for _, share := range []*EKGShareRoundOne{share1, share2, shareOut} {
+		if err := ekg.CheckShareRoundOne(share); err != nil {
+			panic(fmt.Errorf("cannot AggregateShareRoundOne: %w", err))
+		}
+	}
+	if ekg.relin {
+		ekg.RKGProtocol.AggregateShare(share1.RKG, share2.RKG, shareOut.RKG)
+	}
+	for _, galEl := range ekg.galEls {
+		ekg.RTGProtocol.AggregateShare(share1.RTG[galEl], share2.RTG[galEl], shareOut.RTG[galEl])
+	}
+}
+
+// AggregateShareRoundTwo aggregates two second-round shares in the EKG protocol.
+func (ekg *EKGProtocol) AggregateShareRoundTwo(share1, share2, shareOut *RKGShare) {
+	ekg.RKGProtocol.AggregateShare(share1, share2, shareOut)
+}
+
+// GenEvaluationKey finalizes the EKG protocol and populates evk with the collective keys. The relinearization key
+// is only generated if the protocol generates it, in which case round2 must be the aggregated second-round share and
+// evk.Rlk must be allocated. evk.Rtks must be allocated for all the Galois elements of the protocol.
+func (ekg *EKGProtocol) GenEvaluationKey(round1 *EKGShareRoundOne, round2 *RKGShare, crp EKGCRP, evk rlwe.EvaluationKey) {
+	if ekg.relin {
+		ekg.RKGProtocol.GenRelinearizationKey(round1.RKG, round2, evk.Rlk)
+	}
+	for _, galEl := range ekg.galEls {
+		ekg.RTGProtocol.GenRotationKey(round1.RTG[galEl], crp.RTG[galEl], evk.Rtks.Keys[galEl])
+	}
+}
+
+// MarshalBinary encodes the target element on a slice of bytes.
+func (share *EKGShareRoundOne) MarshalBinary() (data []byte, err error) {
+
+	var rkgData []byte
+	if share.RKG != nil {
+		if rkgData, err = share.RKG.MarshalBinary(); err != nil {
+			return nil, err
+		}
+	}
+
+	galEls := make([]uint64, 0, len(share.RTG))
+	for galEl := range share.RTG {
+		galEls = append(galEls, galEl)
+	}
+	galEls = sortedGaloisElements(galEls)
+
+	rtgData := make([][]byte, len(galEls))
+	dataLen := 16 + len(rkgData)
+	for i, galEl := range galEls {
+		if rtgData[i], err = share.RTG[galEl].MarshalBinary(); err != nil {
+			return nil, err
+		}
+		dataLen += 16 + len(rtgData[i])
+	}
+
+	data = make([]byte, dataLen)
+	binary.LittleEndian.PutUint64(data[0:], uint64(len(rkgData)))
+	ptr := 8 + copy(data[8:], rkgData)
+	binary.LittleEndian.PutUint64(data[ptr:], uint64(len(galEls)))
+	ptr += 8
+	for i, galEl := range galEls {
+		binary.LittleEndian.PutUint64(data[ptr:], galEl)
+		binary.LittleEndian.PutUint64(data[ptr+8:], uint64(len(rtgData[i])))
+		ptr += 16
+		ptr += copy(data[ptr:], rtgData[i])
+	}
+
+	return data, nil
+}
+
+// UnmarshalBinary decodes a slice of bytes on the target element.
+func (share *EKGShareRoundOne) UnmarshalBinary(data []byte) (err error) {
+
+	if len(data) < 8 {
+		return errors.New("cannot UnmarshalBinary: data is too short")
+	}
+
+	ptr := 0
+	next := func(n uint64) ([]byte, error) {
+		if uint64(len(data)-ptr) < n {
+			return nil, errors.New("cannot UnmarshalBinary: data is too short")
+		}
+		b := data[ptr : ptr+int(n)]
+		ptr += int(n)
+		return b, nil
+	}
+
+	var b []byte
+	if b, err = next(8); err != nil {
+		return
+	}
+
+	share.RKG = nil
+	if rkgLen := binary.LittleEndian.Uint64(b); rkgLen > 0 {
+		if b, err = next(rkgLen); err != nil {
+			return
+		}
+		share.RKG = new(RKGShare)
+		if err = share.RKG.UnmarshalBinary(b); err != nil {
+			return
+		}
+	}
+
+	if b, err = next(8); err != nil {
+		return
+	}
+
+	nGalEls := binary.LittleEndian.Uint64(b)
+	share.RTG = make(map[uint64]*RTGShare)
+	for i := uint64(0); i < nGalEls; i++ {
+		if b, err = next(16); err != nil {
+			return
+		}
+		galEl, rtgLen := binary.LittleEndian.Uint64(b), binary.LittleEndian.Uint64(b[8:])
+		if b, err = next(rtgLen); err != nil {
+			return
+		}
+		share.RTG[galEl] = new(RTGShare)
+		if err = share.RTG[galEl].UnmarshalBinary(b); err != nil {
+			return
+		}
+	}
+
+	return nil
+}
+
+// sortedGaloisElements returns a sorted copy of galEls without duplicates.
+func sortedGaloisElements(galEls []uint64) (sorted []uint64) {
+	sorted = make([]uint64, 0, len(galEls))
+	seen := make(map[uint64]bool, len(galEls))
+	for _, galEl := range galEls {
+		if !seen[galEl] {
+			seen[galEl] = true
+			sorted = append(sorted, galEl)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return
+}
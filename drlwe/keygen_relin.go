@@ -20,10 +20,11 @@ type RelinearizationKeyGenerator interface {
 
 // RKGProtocol is the structure storing the parameters and and precomputations for the collective relinearization key generation protocol.
 type RKGProtocol struct {
-	params           rlwe.Parameters
-	pBigInt          *big.Int
-	gaussianSamplerQ *ring.GaussianSampler
-	ternarySamplerQ  *ring.TernarySampler // sampling in Montgomerry form
+	params     rlwe.Parameters
+	fork       *utils.PRNGFork
+	pBigInt    *big.Int
+	xeSamplerQ ring.DistributionSampler
+	xuSamplerQ ring.DistributionSampler
 
 	tmpPoly1 rlwe.PolyQP
 	tmpPoly2 rlwe.PolyQP
@@ -33,22 +34,19 @@ type RKGProtocol struct {
 // shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
 // RKGProtocol can be used concurrently.
 func (ekg *RKGProtocol) ShallowCopy() *RKGProtocol {
-	var err error
-	prng, err := utils.NewPRNG()
+	prng, err := ekg.fork.Derive()
 	if err != nil {
 		panic(err)
 	}
 
-	params := ekg.params
+	return ekg.WithPRNG(prng)
+}
 
-	return &RKGProtocol{
-		params:           ekg.params,
-		pBigInt:          ekg.pBigInt,
-		gaussianSamplerQ: ring.NewGaussianSampler(prng, params.RingQ(), params.Sigma(), int(6*params.Sigma())),
-		ternarySamplerQ:  ring.NewTernarySamplerWithHammingWeight(prng, params.RingQ(), params.HammingWeight(), false),
-		tmpPoly1:         params.RingQP().NewPoly(),
-		tmpPoly2:         params.RingQP().NewPoly(),
-	}
+// WithPRNG creates a shallow copy of RKGProtocol whose samplers (error and ephemeral secret) draw their
+// randomness from prng, making the generated shares reproducible from the seed of prng. If prng is nil,
+// the randomness is drawn from crypto/rand.
+func (ekg *RKGProtocol) WithPRNG(prng utils.PRNG) *RKGProtocol {
+	return newRKGProtocol(ekg.params, prng)
 }
 
 // RKGShare is a share in the RKG protocol.
@@ -61,18 +59,22 @@ type RKGCRP []rlwe.PolyQP
 
 // NewRKGProtocol creates a new RKG protocol struct.
 func NewRKGProtocol(params rlwe.Parameters) *RKGProtocol {
+	return newRKGProtocol(params, nil)
+}
+
+func newRKGProtocol(params rlwe.Parameters, source utils.PRNG) *RKGProtocol {
 	rkg := new(RKGProtocol)
 	rkg.params = params
+	rkg.fork = utils.NewPRNGFork(source)
 
-	var err error
-	prng, err := utils.NewPRNG()
+	prng, err := utils.DefaultPRNG(source)
 	if err != nil {
 		panic(err)
 	}
 
 	rkg.pBigInt = params.PBigInt()
-	rkg.gaussianSamplerQ = ring.NewGaussianSampler(prng, params.RingQ(), params.Sigma(), int(6*params.Sigma()))
-	rkg.ternarySamplerQ = ring.NewTernarySamplerWithHammingWeight(prng, params.RingQ(), params.HammingWeight(), false)
+	rkg.xeSamplerQ = params.Xe().NewSampler(prng, params.RingQ(), params.GaussianSamplerType())
+	rkg.xuSamplerQ = params.Xu().NewSampler(prng, params.RingQ(), params.GaussianSamplerType())
 	rkg.tmpPoly1 = params.RingQP().NewPoly()
 	rkg.tmpPoly2 = params.RingQP().NewPoly()
 	return rkg
@@ -121,14 +123,14 @@ func (ekg *RKGProtocol) GenShareRoundOne(sk *rlwe.SecretKey, crp RKGCRP, ephSkOu
 	ringQ.MulScalarBigint(sk.Value.Q, ekg.pBigInt, ekg.tmpPoly1.Q)
 	ringQ.InvMForm(ekg.tmpPoly1.Q, ekg.tmpPoly1.Q)
 
-	ekg.ternarySamplerQ.Read(ephSkOut.Value.Q)
+	ekg.xuSamplerQ.Read(ephSkOut.Value.Q)
 	ringQP.ExtendBasisSmallNormAndCenter(ephSkOut.Value.Q, levelP, nil, ephSkOut.Value.P)
 	ringQP.NTTLvl(levelQ, levelP, ephSkOut.Value, ephSkOut.Value)
 	ringQP.MFormLvl(levelQ, levelP, ephSkOut.Value, ephSkOut.Value)
 
 	for i := 0; i < ekg.params.Beta(); i++ {
 		// h = e
-		ekg.gaussianSamplerQ.Read(shareOut.Value[i][0].Q)
+		ekg.xeSamplerQ.Read(shareOut.Value[i][0].Q)
 		ringQP.ExtendBasisSmallNormAndCenter(shareOut.Value[i][0].Q, levelP, nil, shareOut.Value[i][0].P)
 		ringQP.NTTLvl(levelQ, levelP, shareOut.Value[i][0], shareOut.Value[i][0])
 
@@ -155,7 +157,7 @@ func (ekg *RKGProtocol) GenShareRoundOne(sk *rlwe.SecretKey, crp RKGCRP, ephSkOu
 
 		// Second Element
 		// e_2i
-		ekg.gaussianSamplerQ.Read(shareOut.Value[i][1].Q)
+		ekg.xeSamplerQ.Read(shareOut.Value[i][1].Q)
 		ringQP.ExtendBasisSmallNormAndCenter(shareOut.Value[i][1].Q, levelP, nil, shareOut.Value[i][1].P)
 		ringQP.NTTLvl(levelQ, levelP, shareOut.Value[i][1], shareOut.Value[i][1])
 		// s*a + e_2i
@@ -189,14 +191,14 @@ func (ekg *RKGProtocol) GenShareRoundTwo(ephSk, sk *rlwe.SecretKey, round1 *RKGS
 		ringQP.MulCoeffsMontgomeryConstantLvl(levelQ, levelP, round1.Value[i][0], sk.Value, shareOut.Value[i][0])
 
 		// (AggregateShareRoundTwo samples) * sk + e_1i
-		ekg.gaussianSamplerQ.Read(ekg.tmpPoly2.Q)
+		ekg.xeSamplerQ.Read(ekg.tmpPoly2.Q)
 		ringQP.ExtendBasisSmallNormAndCenter(ekg.tmpPoly2.Q, levelP, nil, ekg.tmpPoly2.P)
 		ringQP.NTTLvl(levelQ, levelP, ekg.tmpPoly2, ekg.tmpPoly2)
 		ringQP.AddLvl(levelQ, levelP, shareOut.Value[i][0], ekg.tmpPoly2, shareOut.Value[i][0])
 
 		// second part
 		// (u - s) * (sum [x][s*a_i + e_2i]) + e3i
-		ekg.gaussianSamplerQ.Read(shareOut.Value[i][1].Q)
+		ekg.xeSamplerQ.Read(shareOut.Value[i][1].Q)
 		ringQP.ExtendBasisSmallNormAndCenter(shareOut.Value[i][1].Q, levelP, nil, shareOut.Value[i][1].P)
 		ringQP.NTTLvl(levelQ, levelP, shareOut.Value[i][1], shareOut.Value[i][1])
 		ringQP.MulCoeffsMontgomeryAndAddLvl(levelQ, levelP, ekg.tmpPoly1, round1.Value[i][1], shareOut.Value[i][1])
@@ -274,3 +276,112 @@ func (share *RKGShare) UnmarshalBinary(data []byte) (err error) {
 
 	return nil
 }
+
+// relationRoundOne returns the relation proven by the proofs of the round one shares of the RKG protocol.
+// The witnesses are s_i, u_i and the errors of the round one share.
+func (ekg *RKGProtocol) relationRoundOne(crp RKGCRP, share *RKGShare) *shareRelation {
+	bounds := make([]int64, 2+2*ekg.params.Beta())
+	bounds[0], bounds[1] = normBound(ekg.params.Xs()), normBound(ekg.params.Xu())
+	for i := 2; i < len(bounds); i++ {
+		bounds[i] = normBound(ekg.params.Xe())
+	}
+	rel := newShareRelation(ekg.params, "RKG-1", bounds)
+	ekg.addRoundOneRows(rel, crp, share)
+	return rel
+}
+
+// relationRoundTwo returns the relation proven by the proofs of the round two shares of the RKG protocol.
+// The witnesses are s_i, u_i and the errors of the round one and round two shares, so that the proof
+// also guarantees that the same secret and ephemeral keys were used in both rounds.
+func (ekg *RKGProtocol) relationRoundTwo(crp RKGCRP, round1, round1Aggregated, share *RKGShare) *shareRelation {
+
+	beta := ekg.params.Beta()
+	levelQ, levelP := ekg.params.QCount()-1, ekg.params.PCount()-1
+
+	bounds := make([]int64, 2+4*beta)
+	bounds[0], bounds[1] = normBound(ekg.params.Xs()), normBound(ekg.params.Xu())
+	for i := 2; i < len(bounds); i++ {
+		bounds[i] = normBound(ekg.params.Xe())
+	}
+	rel := newShareRelation(ekg.params, "RKG-2", bounds)
+	ekg.addRoundOneRows(rel, crp, round1)
+
+	for i := 0; i < beta; i++ {
+		// [sum(-u_j*a + s_j*w + e_j)] * s_i + e_i1
+		row := rel.addRow(levelQ, levelP, share.Value[i][0], 2+2*beta+2*i)
+		rel.addTerm(row, 0, round1Aggregated.Value[i][0], false)
+
+		// (u_i - s_i) * [sum(s_j*a + e_j2)] + e_i3
+		row = rel.addRow(levelQ, levelP, share.Value[i][1], 3+2*beta+2*i)
+		rel.addTerm(row, 1, round1Aggregated.Value[i][1], false)
+		rel.addTerm(row, 0, round1Aggregated.Value[i][1], true)
+	}
+
+	return rel
+}
+
+// addRoundOneRows adds the rows [-u_i*a + s_i*w + e_i0, s_i*a + e_i1] of the round one share to the relation.
+func (ekg *RKGProtocol) addRoundOneRows(rel *shareRelation, crp RKGCRP, share *RKGShare) {
+
+	ringQ := ekg.params.RingQ()
+	levelQ, levelP := ekg.params.QCount()-1, ekg.params.PCount()-1
+
+	constQ := make([]uint64, ekg.params.QCount())
+	constP := make([]uint64, ekg.params.PCount())
+
+	for i := 0; i < ekg.params.Beta(); i++ {
+
+		// w_i = P on the moduli of the i-th element of the CRT decomposition and zero elsewhere
+		for j := range constQ {
+			constQ[j] = 0
+		}
+		for j := 0; j < ekg.params.PCount(); j++ {
+			index := i*ekg.params.PCount() + j
+			if index >= ekg.params.QCount() {
+				break
+			}
+			constQ[index] = new(big.Int).Mod(ekg.pBigInt, ring.NewUint(ringQ.Modulus[index])).Uint64()
+		}
+
+		row := rel.addRow(levelQ, levelP, share.Value[i][0], 2+2*i)
+		rel.addTerm(row, 1, crp[i], true)
+		rel.addConstantTerm(row, 0, constQ, constP)
+
+		row = rel.addRow(levelQ, levelP, share.Value[i][1], 3+2*i)
+		rel.addTerm(row, 0, crp[i], false)
+	}
+}
+
+// GenShareRoundOneProof generates a zero-knowledge proof that the round one share was generated by GenShareRoundOne
+// from the secret key sk, the ephemeral secret key ephSk and the common reference polynomial crp.
+// Returns an error if the share is not of this form.
+func (ekg *RKGProtocol) GenShareRoundOneProof(sk, ephSk *rlwe.SecretKey, crp RKGCRP, share *RKGShare) (proof *ShareProof, err error) {
+	rel := ekg.relationRoundOne(crp, share)
+	w := make([][]int64, len(rel.bounds))
+	w[0], w[1] = rel.secretToInt64(sk.Value), rel.secretToInt64(ephSk.Value)
+	return rel.prove(w)
+}
+
+// VerifyShareRoundOne checks the proof that the round one share was correctly generated with respect to the
+// common reference polynomial crp. It should be called on each share before aggregating it with AggregateShare.
+func (ekg *RKGProtocol) VerifyShareRoundOne(crp RKGCRP, share *RKGShare, proof *ShareProof) bool {
+	return ekg.relationRoundOne(crp, share).verify(proof)
+}
+
+// GenShareRoundTwoProof generates a zero-knowledge proof that the round two share was generated by GenShareRoundTwo
+// from the aggregated round one shares round1Aggregated, with the same secret key sk and ephemeral secret key ephSk
+// as the party's round one share round1.
+// Returns an error if the shares are not of this form.
+func (ekg *RKGProtocol) GenShareRoundTwoProof(ephSk, sk *rlwe.SecretKey, crp RKGCRP, round1, round1Aggregated, share *RKGShare) (proof *ShareProof, err error) {
+	rel := ekg.relationRoundTwo(crp, round1, round1Aggregated, share)
+	w := make([][]int64, len(rel.bounds))
+	w[0], w[1] = rel.secretToInt64(sk.Value), rel.secretToInt64(ephSk.Value)
+	return rel.prove(w)
+}
+
+// VerifyShareRoundTwo checks the proof that the round two share was correctly generated from the aggregated
+// round one shares round1Aggregated, with the same keys as the party's round one share round1. It should be
+// called on each share before aggregating it with AggregateShare.
+func (ekg *RKGProtocol) VerifyShareRoundTwo(crp RKGCRP, round1, round1Aggregated, share *RKGShare, proof *ShareProof) bool {
+	return ekg.relationRoundTwo(crp, round1, round1Aggregated, share).verify(proof)
+}
@@ -0,0 +1,372 @@
+package drlwe
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// PKRKGProtocol is the structure storing the parameters and precomputations for the one-round collective generation of a
+// public-key relinearization key. Unlike the RKGProtocol, the parties do not need to interact in a second round:
+// each party sends, in a single message, its share of a collective gadget public key b = [-s*a_i + e_i] together with
+// encryptions of its secret share s_i and of a fresh ephemeral secret r_i. The aggregated key is used by the PKRelinearizer,
+// which relinearizes with two key-switchings instead of one, at the cost of a larger noise.
+//
+// The secrets are not encrypted under the collective public key of the CKGProtocol: a relinearization key needs encryptions
+// of s * P * w_i for each element w_i of the gadget decomposition, hence a gadget public key with one polynomial per element
+// rather than the single polynomial of the collective public key, and encryptions of s alone under the collective public
+// key can only be combined into an encryption of s^2 with a second round, as in the RKGProtocol. Instead, the parties generate
+// the collective gadget public key in the same message, from the common reference polynomials a_i (see Chen, Dai, Kim and
+// Song, "Efficient Multi-Key Homomorphic Encryption with Packed Ciphertexts", CCS 2019). The protocol still has a single
+// round because every component of a share is linear in the secrets s_i and r_i of its party and in the public a_i and a'_i:
+// the aggregated share is the same expression in s = sum_i s_i and r = sum_i r_i, and the product r * s is never computed
+// by the parties but cancelled by the second key-switching of the PKRelinearizer.
+type PKRKGProtocol struct {
+	params     rlwe.Parameters
+	fork       *utils.PRNGFork
+	pBigInt    *big.Int
+	xeSamplerQ ring.DistributionSampler
+	xsSamplerQ ring.DistributionSampler
+
+	tmpPoly0 rlwe.PolyQP
+	tmpPoly1 rlwe.PolyQP
+	ephSk    rlwe.PolyQP
+}
+
+// PKRKGShare is a share in the PKRKG protocol. For each element i of the gadget decomposition, it stores
+// [-s*a_i + e, -s*a'_i + r*P*w_i + e, r*a_i + s*P*w_i + e].
+type PKRKGShare struct {
+	Value [][3]rlwe.PolyQP
+}
+
+// PKRKGCRP is a type for the common reference polynomials [a_i, a'_i] in the PKRKG protocol.
+type PKRKGCRP [][2]rlwe.PolyQP
+
+// PKRelinearizationKey is a relinearization key generated by the PKRKG protocol, where r is the sum of the ephemeral secrets
+// of the parties. The first key switches c from s^2 to the pair of secrets (r, s), i.e. to [c', t] with c'*r + t*s ~ c*s^2,
+// and the second one switches c' from r to s.
+type PKRelinearizationKey struct {
+	Keys [2]*rlwe.SwitchingKey
+}
+
+// NewPKRelinearizationKey allocates a new PKRelinearizationKey.
+func NewPKRelinearizationKey(params rlwe.Parameters) *PKRelinearizationKey {
+	levelQ, levelP := params.QCount()-1, params.PCount()-1
+	return &PKRelinearizationKey{Keys: [2]*rlwe.SwitchingKey{rlwe.NewSwitchingKey(params, levelQ, levelP), rlwe.NewSwitchingKey(params, levelQ, levelP)}}
+}
+
+// NewPKRKGProtocol creates a new PKRKGProtocol instance.
+func NewPKRKGProtocol(params rlwe.Parameters) *PKRKGProtocol {
+
+	if params.PCount() == 0 {
+		panic("cannot NewPKRKGProtocol: the parameters must have a special modulus P")
+	}
+
+	return newPKRKGProtocol(params, nil)
+}
+
+func newPKRKGProtocol(params rlwe.Parameters, source utils.PRNG) *PKRKGProtocol {
+
+	prng, err := utils.DefaultPRNG(source)
+	if err != nil {
+		panic(err)
+	}
+
+	return &PKRKGProtocol{
+		params:     params,
+		fork:       utils.NewPRNGFork(source),
+		pBigInt:    params.PBigInt(),
+		xeSamplerQ: params.Xe().NewSampler(prng, params.RingQ(), params.GaussianSamplerType()),
+		xsSamplerQ: params.Xs().NewSampler(prng, params.RingQ(), params.GaussianSamplerType()),
+		tmpPoly0:   params.RingQP().NewPoly(),
+		tmpPoly1:   params.RingQP().NewPoly(),
+		ephSk:      params.RingQP().NewPoly(),
+	}
+}
+
+// ShallowCopy creates a shallow copy of PKRKGProtocol in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// PKRKGProtocol can be used concurrently.
+func (rkg *PKRKGProtocol) ShallowCopy() *PKRKGProtocol {
+	prng, err := rkg.fork.Derive()
+	if err != nil {
+		panic(err)
+	}
+
+	return rkg.WithPRNG(prng)
+}
+
+// WithPRNG creates a shallow copy of PKRKGProtocol whose samplers (error and ephemeral secret) draw their
+// randomness from prng, making the generated shares reproducible from the seed of prng. If prng is nil,
+// the randomness is drawn from crypto/rand.
+func (rkg *PKRKGProtocol) WithPRNG(prng utils.PRNG) *PKRKGProtocol {
+	return newPKRKGProtocol(rkg.params, prng)
+}
+
+// AllocateShare allocates a party's share in the PKRKG protocol.
+func (rkg *PKRKGProtocol) AllocateShare() (share *PKRKGShare) {
+	share = &PKRKGShare{Value: make([][3]rlwe.PolyQP, rkg.params.Beta())}
+	for i := range share.Value {
+		for j := range share.Value[i] {
+			share.Value[i][j] = rkg.params.RingQP().NewPoly()
+		}
+	}
+	return
+}
+
+// SampleCRP samples the common random polynomials to be used in the PKRKG protocol from the provided
+// common reference string.
+func (rkg *PKRKGProtocol) SampleCRP(crs CRS) PKRKGCRP {
+	crp := make([][2]rlwe.PolyQP, rkg.params.Beta())
+	us := rlwe.NewUniformSamplerQP(rkg.params, crs)
+	for i := range crp {
+		for j := range crp[i] {
+			crp[i][j] = rkg.params.RingQP().NewPoly()
+			us.Read(&crp[i][j])
+		}
+	}
+	return PKRKGCRP(crp)
+}
+
+// GenShare generates a party's share in the PKRKG protocol. A fresh ephemeral secret is sampled at each call.
+func (rkg *PKRKGProtocol) GenShare(sk *rlwe.SecretKey, crp PKRKGCRP, shareOut *PKRKGShare) {
+
+	ringQ := rkg.params.RingQ()
+	ringQP := rkg.params.RingQP()
+	levelQ := rkg.params.QCount() - 1
+	levelP := rkg.params.PCount() - 1
+
+	// P*s (NTT, non-Montgomery)
+	ringQ.MulScalarBigint(sk.Value.Q, rkg.pBigInt, rkg.tmpPoly0.Q)
+	ringQ.InvMForm(rkg.tmpPoly0.Q, rkg.tmpPoly0.Q)
+
+	// r (NTT, Montgomery) and P*r (NTT, non-Montgomery)
+	rkg.xsSamplerQ.Read(rkg.ephSk.Q)
+	ringQP.ExtendBasisSmallNormAndCenter(rkg.ephSk.Q, levelP, nil, rkg.ephSk.P)
+	ringQP.NTTLvl(levelQ, levelP, rkg.ephSk, rkg.ephSk)
+	ringQ.MulScalarBigint(rkg.ephSk.Q, rkg.pBigInt, rkg.tmpPoly1.Q)
+	ringQP.MFormLvl(levelQ, levelP, rkg.ephSk, rkg.ephSk)
+
+	for i := 0; i < rkg.params.Beta(); i++ {
+
+		for j := range shareOut.Value[i] {
+			rkg.xeSamplerQ.Read(shareOut.Value[i][j].Q)
+			ringQP.ExtendBasisSmallNormAndCenter(shareOut.Value[i][j].Q, levelP, nil, shareOut.Value[i][j].P)
+			ringQP.NTTLvl(levelQ, levelP, shareOut.Value[i][j], shareOut.Value[i][j])
+		}
+
+		// -s*a_i + e
+		ringQP.MulCoeffsMontgomeryAndSubLvl(levelQ, levelP, sk.Value, crp[i][0], shareOut.Value[i][0])
+
+		// -s*a'_i + r*P*w_i + e
+		rkg.addGadget(i, rkg.tmpPoly1.Q, shareOut.Value[i][1].Q)
+		ringQP.MulCoeffsMontgomeryAndSubLvl(levelQ, levelP, sk.Value, crp[i][1], shareOut.Value[i][1])
+
+		// r*a_i + s*P*w_i + e
+		rkg.addGadget(i, rkg.tmpPoly0.Q, shareOut.Value[i][2].Q)
+		ringQP.MulCoeffsMontgomeryAndAddLvl(levelQ, levelP, rkg.ephSk, crp[i][0], shareOut.Value[i][2])
+	}
+}
+
+// addGadget adds pol on the moduli q_j of the i-th element of the gadget decomposition of polOut.
+func (rkg *PKRKGProtocol) addGadget(i int, pol, polOut *ring.Poly) {
+	ringQ := rkg.params.RingQ()
+	for j := 0; j < rkg.params.PCount(); j++ {
+		index := i*rkg.params.PCount() + j
+
+		// Handles the case where nb pj does not divides nb qi
+		if index >= rkg.params.QCount() {
+			break
+		}
+
+		qi := ringQ.Modulus[index]
+		p0, p1 := pol.Coeffs[index], polOut.Coeffs[index]
+		for w := 0; w < ringQ.N; w++ {
+			p1[w] = ring.CRed(p1[w]+p0[w], qi)
+		}
+	}
+}
+
+// AggregateShare aggregates two shares in the PKRKG protocol.
+func (rkg *PKRKGProtocol) AggregateShare(share1, share2, shareOut *PKRKGShare) {
+	ringQP, levelQ, levelP := rkg.params.RingQP(), rkg.params.QCount()-1, rkg.params.PCount()-1
+	for i := range shareOut.Value {
+		for j := range shareOut.Value[i] {
+			ringQP.AddLvl(levelQ, levelP, share1.Value[i][j], share2.Value[i][j], shareOut.Value[i][j])
+		}
+	}
+}
+
+// GenRelinearizationKey computes the public-key relinearization key from the aggregated shares and writes the result in rlkOut.
+func (rkg *PKRKGProtocol) GenRelinearizationKey(share *PKRKGShare, crp PKRKGCRP, rlkOut *PKRelinearizationKey) {
+	ringQP, levelQ, levelP := rkg.params.RingQP(), rkg.params.QCount()-1, rkg.params.PCount()-1
+	for i := range share.Value {
+		// [-s*a_i + e, r*a_i + s*P*w_i + e]
+		ringQP.MFormLvl(levelQ, levelP, share.Value[i][0], rlkOut.Keys[0].Value[i][0])
+		ringQP.MFormLvl(levelQ, levelP, share.Value[i][2], rlkOut.Keys[0].Value[i][1])
+		// [-s*a'_i + r*P*w_i + e, a'_i]
+		ringQP.MFormLvl(levelQ, levelP, share.Value[i][1], rlkOut.Keys[1].Value[i][0])
+		ringQP.MFormLvl(levelQ, levelP, crp[i][1], rlkOut.Keys[1].Value[i][1])
+	}
+}
+
+// MarshalBinary encodes the target element on a slice of bytes.
+func (share *PKRKGShare) MarshalBinary() (data []byte, err error) {
+
+	if len(share.Value) > 0xFF {
+		return []byte{}, errors.New("PKRKGShare : uint8 overflow on length")
+	}
+
+	data = make([]byte, 1+3*share.Value[0][0].GetDataLen(true)*len(share.Value))
+	data[0] = uint8(len(share.Value))
+
+	ptr := 1
+	var inc int
+	for _, elem := range share.Value {
+		for j := range elem {
+			if inc, err = elem[j].WriteTo(data[ptr:]); err != nil {
+				return []byte{}, err
+			}
+			ptr += inc
+		}
+	}
+
+	return data, nil
+}
+
+// UnmarshalBinary decodes a slice of bytes on the target element.
+// It returns an error if data is truncated.
+func (share *PKRKGShare) UnmarshalBinary(data []byte) (err error) {
+
+	if len(data) < 1 {
+		return errors.New("cannot UnmarshalBinary: data is too short")
+	}
+
+	share.Value = make([][3]rlwe.PolyQP, data[0])
+	ptr := 1
+	var inc int
+	for i := range share.Value {
+		for j := range share.Value[i] {
+			if inc, err = polyQPDataLen(data[ptr:]); err != nil {
+				return fmt.Errorf("cannot UnmarshalBinary: %w", err)
+			}
+			if _, err = share.Value[i][j].DecodePolyNew(data[ptr : ptr+inc]); err != nil {
+				return err
+			}
+			ptr += inc
+		}
+	}
+
+	return nil
+}
+
+// polyQPDataLen returns the number of bytes of the rlwe.PolyQP encoded at the beginning of data,
+// or an error if data is too short to contain it.
+func polyQPDataLen(data []byte) (n int, err error) {
+
+	if len(data) < 2 {
+		return 0, errors.New("data is too short")
+	}
+
+	n = 2
+	for _, present := range data[:2] {
+
+		if present != 1 {
+			continue
+		}
+
+		// Metadata of the ring.Poly: log2(N), number of moduli, flags and the degree on 4 more bytes if log2(N) = 0xFF.
+		if len(data)-n < 4 {
+			return 0, errors.New("data is too short")
+		}
+
+		var N uint64
+		moduli, wordLen, metaLen := uint64(data[n+1]), uint64(8), 4
+		if data[n+2]&2 == 2 {
+			wordLen = 4
+		}
+
+		switch {
+		case data[n] == 0xFF:
+			if len(data)-n < 8 {
+				return 0, errors.New("data is too short")
+			}
+			N, metaLen = uint64(binary.BigEndian.Uint32(data[n+4:])), 8
+		case data[n] > 32:
+			return 0, errors.New("invalid ring degree")
+		default:
+			N = 1 << data[n]
+		}
+
+		if uint64(len(data)-n-metaLen) < N*moduli*wordLen {
+			return 0, errors.New("data is too short")
+		}
+
+		n += metaLen + int(N*moduli*wordLen)
+	}
+
+	return n, nil
+}
+
+// PKRelinearizer relinearizes degree-2 ciphertexts with a PKRelinearizationKey.
+type PKRelinearizer struct {
+	*rlwe.KeySwitcher
+	params rlwe.Parameters
+	rlk    *PKRelinearizationKey
+
+	c, t, u0, u1 *ring.Poly
+}
+
+// NewPKRelinearizer creates a new PKRelinearizer from a PKRelinearizationKey.
+func NewPKRelinearizer(params rlwe.Parameters, rlk *PKRelinearizationKey) *PKRelinearizer {
+	ringQ := params.RingQ()
+	return &PKRelinearizer{
+		KeySwitcher: rlwe.NewKeySwitcher(params),
+		params:      params,
+		rlk:         rlk,
+		c:           ringQ.NewPoly(),
+		t:           ringQ.NewPoly(),
+		u0:          ringQ.NewPoly(),
+		u1:          ringQ.NewPoly(),
+	}
+}
+
+// ShallowCopy creates a shallow copy of PKRelinearizer in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// PKRelinearizer can be used concurrently.
+func (r *PKRelinearizer) ShallowCopy() *PKRelinearizer {
+	return NewPKRelinearizer(r.params, r.rlk)
+}
+
+// Relinearize relinearizes the degree-2 ciphertext ctIn and writes the degree-1 result on ctOut.
+// The ciphertext can be either in or out of the NTT domain.
+//
+// Given ctIn = [c0, c1, c2], it computes [c', t] = Switch(c2, Keys[0]), where c' ~ -s*alpha and t ~ r*alpha + c2*s,
+// then [u0, u1] = Switch(c', Keys[1]) where u0 + u1*s ~ -r*s*alpha, and returns [c0 + u0, c1 + t + u1].
+func (r *PKRelinearizer) Relinearize(ctIn, ctOut *rlwe.Ciphertext) {
+
+	if ctIn.Degree() != 2 {
+		panic("cannot Relinearize: input ciphertext is not of degree 2")
+	}
+
+	level := utils.MinInt(ctIn.Level(), ctOut.Level())
+	ringQ := r.params.RingQ()
+
+	r.c.IsNTT = ctIn.Value[2].IsNTT
+
+	r.SwitchKeysInPlace(level, ctIn.Value[2], r.rlk.Keys[0], r.c, r.t)
+	r.SwitchKeysInPlace(level, r.c, r.rlk.Keys[1], r.u0, r.u1)
+
+	ringQ.AddLvl(level, ctIn.Value[0], r.u0, ctOut.Value[0])
+	ringQ.AddLvl(level, ctIn.Value[1], r.t, ctOut.Value[1])
+	ringQ.AddLvl(level, ctOut.Value[1], r.u1, ctOut.Value[1])
+
+	ctOut.Value[0].IsNTT, ctOut.Value[1].IsNTT = ctIn.Value[0].IsNTT, ctIn.Value[1].IsNTT
+
+	ctOut.Resize(r.params, 1)
+}
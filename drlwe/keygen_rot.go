@@ -26,41 +26,47 @@ type RTGCRP []rlwe.PolyQP
 
 // RTGProtocol is the structure storing the parameters for the collective rotation-keys generation.
 type RTGProtocol struct {
-	params           rlwe.Parameters
-	tmpPoly0         rlwe.PolyQP
-	tmpPoly1         rlwe.PolyQP
-	gaussianSamplerQ *ring.GaussianSampler
+	params     rlwe.Parameters
+	fork       *utils.PRNGFork
+	tmpPoly0   rlwe.PolyQP
+	tmpPoly1   rlwe.PolyQP
+	xeSamplerQ ring.DistributionSampler
 }
 
 // ShallowCopy creates a shallow copy of RTGProtocol in which all the read-only data-structures are
 // shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
 // RTGProtocol can be used concurrently.
 func (rtg *RTGProtocol) ShallowCopy() *RTGProtocol {
-	prng, err := utils.NewPRNG()
+	prng, err := rtg.fork.Derive()
 	if err != nil {
 		panic(err)
 	}
 
-	params := rtg.params
+	return rtg.WithPRNG(prng)
+}
 
-	return &RTGProtocol{
-		params:           rtg.params,
-		tmpPoly0:         params.RingQP().NewPoly(),
-		tmpPoly1:         params.RingQP().NewPoly(),
-		gaussianSamplerQ: ring.NewGaussianSampler(prng, params.RingQ(), params.Sigma(), int(6*params.Sigma())),
-	}
+// WithPRNG creates a shallow copy of RTGProtocol whose error sampler draws its randomness from prng,
+// making the generated shares reproducible from the seed of prng. If prng is nil, the randomness is
+// drawn from crypto/rand.
+func (rtg *RTGProtocol) WithPRNG(prng utils.PRNG) *RTGProtocol {
+	return newRTGProtocol(rtg.params, prng)
 }
 
 // NewRTGProtocol creates a RTGProtocol instance.
 func NewRTGProtocol(params rlwe.Parameters) *RTGProtocol {
+	return newRTGProtocol(params, nil)
+}
+
+func newRTGProtocol(params rlwe.Parameters, source utils.PRNG) *RTGProtocol {
 	rtg := new(RTGProtocol)
 	rtg.params = params
+	rtg.fork = utils.NewPRNGFork(source)
 
-	prng, err := utils.NewPRNG()
+	prng, err := utils.DefaultPRNG(source)
 	if err != nil {
 		panic(err)
 	}
-	rtg.gaussianSamplerQ = ring.NewGaussianSampler(prng, params.RingQ(), params.Sigma(), int(6*params.Sigma()))
+	rtg.xeSamplerQ = params.Xe().NewSampler(prng, params.RingQ(), params.GaussianSamplerType())
 	rtg.tmpPoly0 = params.RingQP().NewPoly()
 	rtg.tmpPoly1 = params.RingQP().NewPoly()
 	return rtg
@@ -109,7 +115,7 @@ func (rtg *RTGProtocol) GenShare(sk *rlwe.SecretKey, galEl uint64, crp RTGCRP, s
 	for i := 0; i < rtg.params.Beta(); i++ {
 
 		// e
-		rtg.gaussianSamplerQ.Read(shareOut.Value[i].Q)
+		rtg.xeSamplerQ.Read(shareOut.Value[i].Q)
 		ringQP.ExtendBasisSmallNormAndCenter(shareOut.Value[i].Q, levelP, nil, shareOut.Value[i].P)
 		ringQP.NTTLazyLvl(levelQ, levelP, shareOut.Value[i], shareOut.Value[i])
 		ringQP.MFormLvl(levelQ, levelP, shareOut.Value[i], shareOut.Value[i])
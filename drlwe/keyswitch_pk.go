@@ -23,20 +23,34 @@ type PCKSShare struct {
 type PCKSProtocol struct {
 	params        rlwe.Parameters
 	sigmaSmudging float64
+	fork          *utils.PRNGFork
 
 	tmpQP rlwe.PolyQP
 	tmpP  [2]*ring.Poly
 
-	basisExtender             *ring.BasisExtender
-	gaussianSampler           *ring.GaussianSampler
-	ternarySamplerMontgomeryQ *ring.TernarySampler
+	basisExtender   *ring.BasisExtender
+	gaussianSampler ring.DiscreteGaussianSampler
+	xeSamplerQ      ring.DistributionSampler
+	xuSamplerQ      ring.DistributionSampler
 }
 
 // ShallowCopy creates a shallow copy of PCKSProtocol in which all the read-only data-structures are
 // shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
 // PCKSProtocol can be used concurrently.
 func (pcks *PCKSProtocol) ShallowCopy() *PCKSProtocol {
-	prng, err := utils.NewPRNG()
+	prng, err := pcks.fork.Derive()
+	if err != nil {
+		panic(err)
+	}
+
+	return pcks.WithPRNG(prng)
+}
+
+// WithPRNG creates a shallow copy of PCKSProtocol whose samplers (smudging, error and ephemeral secret) draw their
+// randomness from prng, making the generated shares reproducible from the seed of prng. If prng is nil,
+// the randomness is drawn from crypto/rand.
+func (pcks *PCKSProtocol) WithPRNG(source utils.PRNG) *PCKSProtocol {
+	prng, err := utils.DefaultPRNG(source)
 	if err != nil {
 		panic(err)
 	}
@@ -49,18 +63,22 @@ func (pcks *PCKSProtocol) ShallowCopy() *PCKSProtocol {
 	}
 
 	return &PCKSProtocol{
-		params:                    params,
-		sigmaSmudging:             pcks.sigmaSmudging,
-		tmpQP:                     params.RingQP().NewPoly(),
-		tmpP:                      tmpP,
-		basisExtender:             pcks.basisExtender.ShallowCopy(),
-		gaussianSampler:           ring.NewGaussianSampler(prng, params.RingQ(), pcks.sigmaSmudging, int(6*pcks.sigmaSmudging)),
-		ternarySamplerMontgomeryQ: ring.NewTernarySamplerWithHammingWeight(prng, params.RingQ(), params.HammingWeight(), false),
+		params:          params,
+		sigmaSmudging:   pcks.sigmaSmudging,
+		fork:            utils.NewPRNGFork(source),
+		tmpQP:           params.RingQP().NewPoly(),
+		tmpP:            tmpP,
+		basisExtender:   pcks.basisExtender.ShallowCopy(),
+		gaussianSampler: ring.NewDiscreteGaussianSampler(prng, params.RingQ(), pcks.sigmaSmudging, 6*pcks.sigmaSmudging, params.GaussianSamplerType()),
+		xeSamplerQ:      params.Xe().NewSampler(prng, params.RingQ(), params.GaussianSamplerType()),
+		xuSamplerQ:      params.Xu().NewSampler(prng, params.RingQ(), params.GaussianSamplerType()),
 	}
 }
 
 // NewPCKSProtocol creates a new PCKSProtocol object and will be used to re-encrypt a ciphertext ctx encrypted under a secret-shared key among j parties under a new
 // collective public-key.
+// The smudging noise is sampled with the discrete Gaussian sampler type of the parameters: with ring.GaussianCDT, large
+// values of sigmaSmudging are sampled in constant time by convolution (see ring.NewDiscreteGaussianSampler).
 func NewPCKSProtocol(params rlwe.Parameters, sigmaSmudging float64) (pcks *PCKSProtocol) {
 	pcks = new(PCKSProtocol)
 	pcks.params = params
@@ -77,8 +95,9 @@ func NewPCKSProtocol(params rlwe.Parameters, sigmaSmudging float64) (pcks *PCKSP
 	if err != nil {
 		panic(err)
 	}
-	pcks.gaussianSampler = ring.NewGaussianSampler(prng, params.RingQ(), sigmaSmudging, int(6*sigmaSmudging))
-	pcks.ternarySamplerMontgomeryQ = ring.NewTernarySamplerWithHammingWeight(prng, params.RingQ(), params.HammingWeight(), false)
+	pcks.gaussianSampler = ring.NewDiscreteGaussianSampler(prng, params.RingQ(), sigmaSmudging, 6*sigmaSmudging, params.GaussianSamplerType())
+	pcks.xeSamplerQ = params.Xe().NewSampler(prng, params.RingQ(), params.GaussianSamplerType())
+	pcks.xuSamplerQ = params.Xu().NewSampler(prng, params.RingQ(), params.GaussianSamplerType())
 
 	return pcks
 }
@@ -90,9 +109,12 @@ func (pcks *PCKSProtocol) AllocateShare(levelQ int) (s *PCKSShare) {
 
 // GenShare is the first part of the unique round of the PCKSProtocol protocol. Each party computes the following :
 //
-// [s_i * ct[1] + (u_i * pk[0] + e_0i)/P, (u_i * pk[1] + e_1i)/P]
+// [s_i * ct[1] + (u_i * pk[0])/P + e_0i, (u_i * pk[1] + e_1i)/P]
 //
-// and broadcasts the result to the other j-1 parties.
+// and broadcasts the result to the other j-1 parties. The smudging noise e_0i is added after the division
+// by the special modulus P, so that sigmaSmudging is the standard deviation of the noise that the share
+// adds to the key-switched ciphertext. The noise e_1i, which is multiplied by the output secret key upon
+// decryption, is sampled from the error distribution of the parameters.
 // ct1 is the degree 1 element of the rlwe.Ciphertext to keyswitch, i.e. ct1 = rlwe.Ciphertext.Value[1].
 // NTT flag for ct1 is expected to be set correctly.
 func (pcks *PCKSProtocol) GenShare(sk *rlwe.SecretKey, pk *rlwe.PublicKey, ct1 *ring.Poly, shareOut *PCKSShare) {
@@ -108,7 +130,7 @@ func (pcks *PCKSProtocol) GenShare(sk *rlwe.SecretKey, pk *rlwe.PublicKey, ct1 *
 	}
 
 	// samples MForm(u_i) in Q and P separately
-	pcks.ternarySamplerMontgomeryQ.ReadLvl(levelQ, pcks.tmpQP.Q)
+	pcks.xuSamplerQ.ReadLvl(levelQ, pcks.tmpQP.Q)
 
 	if ringP != nil {
 		ringQP.ExtendBasisSmallNormAndCenter(pcks.tmpQP.Q, levelP, nil, pcks.tmpQP.P)
@@ -128,16 +150,8 @@ func (pcks *PCKSProtocol) GenShare(sk *rlwe.SecretKey, pk *rlwe.PublicKey, ct1 *
 	ringQP.InvNTTLvl(levelQ, levelP, shareOutQP0, shareOutQP0)
 	ringQP.InvNTTLvl(levelQ, levelP, shareOutQP1, shareOutQP1)
 
-	// h_0 = u_i * pk_0
-	pcks.gaussianSampler.ReadLvl(levelQ, pcks.tmpQP.Q)
-	if ringP != nil {
-		ringQP.ExtendBasisSmallNormAndCenter(pcks.tmpQP.Q, levelP, nil, pcks.tmpQP.P)
-	}
-
-	ringQP.AddLvl(levelQ, levelP, shareOutQP0, pcks.tmpQP, shareOutQP0)
-
 	// h_1 = u_i * pk_1 + e1
-	pcks.gaussianSampler.ReadLvl(levelQ, pcks.tmpQP.Q)
+	pcks.xeSamplerQ.ReadLvl(levelQ, pcks.tmpQP.Q)
 	if ringP != nil {
 		ringQP.ExtendBasisSmallNormAndCenter(pcks.tmpQP.Q, levelP, nil, pcks.tmpQP.P)
 	}
@@ -145,14 +159,18 @@ func (pcks *PCKSProtocol) GenShare(sk *rlwe.SecretKey, pk *rlwe.PublicKey, ct1 *
 	ringQP.AddLvl(levelQ, levelP, shareOutQP1, pcks.tmpQP, shareOutQP1)
 
 	if ringP != nil {
-		// h_0 = (u_i * pk_0 + e0)/P
+		// h_0 = (u_i * pk_0)/P
 		pcks.basisExtender.ModDownQPtoQ(levelQ, levelP, shareOutQP0.Q, shareOutQP0.P, shareOutQP0.Q)
 
 		// h_1 = (u_i * pk_1 + e1)/P
 		pcks.basisExtender.ModDownQPtoQ(levelQ, levelP, shareOutQP1.Q, shareOutQP1.P, shareOutQP1.Q)
 	}
 
-	// h_0 = s_i*c_1 + (u_i * pk_0 + e0)/P
+	// h_0 = (u_i * pk_0)/P + e0
+	pcks.gaussianSampler.ReadLvl(levelQ, pcks.tmpQP.Q)
+	ringQ.AddLvl(levelQ, shareOut.Value[0], pcks.tmpQP.Q, shareOut.Value[0])
+
+	// h_0 = s_i*c_1 + (u_i * pk_0)/P + e0
 	if ct1.IsNTT {
 		ringQ.NTTLvl(levelQ, shareOut.Value[0], shareOut.Value[0])
 		ringQ.NTTLvl(levelQ, shareOut.Value[1], shareOut.Value[1])
@@ -163,7 +181,7 @@ func (pcks *PCKSProtocol) GenShare(sk *rlwe.SecretKey, pk *rlwe.PublicKey, ct1 *
 		ringQ.MulCoeffsMontgomeryConstantLvl(levelQ, pcks.tmpQP.Q, sk.Value.Q, pcks.tmpQP.Q)
 		ringQ.InvNTTLvl(levelQ, pcks.tmpQP.Q, pcks.tmpQP.Q)
 
-		// h_0 = s_i*c_1 + (u_i * pk_0 + e0)/P
+		// h_0 = s_i*c_1 + (u_i * pk_0)/P + e0
 		ringQ.AddLvl(levelQ, shareOut.Value[0], pcks.tmpQP.Q, shareOut.Value[0])
 	}
 }
@@ -1,6 +1,8 @@
 package drlwe
 
 import (
+	"math"
+
 	"github.com/tuneinsight/lattigo/v3/ring"
 	"github.com/tuneinsight/lattigo/v3/rlwe"
 	"github.com/tuneinsight/lattigo/v3/utils"
@@ -18,9 +20,9 @@ type KeySwitchingProtocol interface {
 type CKSProtocol struct {
 	params          rlwe.Parameters
 	sigmaSmudging   float64
-	gaussianSampler *ring.GaussianSampler
-	basisExtender   *ring.BasisExtender
-	tmpQP           rlwe.PolyQP
+	fork            *utils.PRNGFork
+	gaussianSampler ring.DiscreteGaussianSampler
+	tmpQ            *ring.Poly
 	tmpDelta        *ring.Poly
 }
 
@@ -28,7 +30,19 @@ type CKSProtocol struct {
 // shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
 // CKSProtocol can be used concurrently.
 func (cks *CKSProtocol) ShallowCopy() *CKSProtocol {
-	prng, err := utils.NewPRNG()
+	prng, err := cks.fork.Derive()
+	if err != nil {
+		panic(err)
+	}
+
+	return cks.WithPRNG(prng)
+}
+
+// WithPRNG creates a shallow copy of CKSProtocol whose smudging sampler draws its randomness from prng,
+// making the generated shares reproducible from the seed of prng. If prng is nil, the randomness is
+// drawn from crypto/rand.
+func (cks *CKSProtocol) WithPRNG(source utils.PRNG) *CKSProtocol {
+	prng, err := utils.DefaultPRNG(source)
 	if err != nil {
 		panic(err)
 	}
@@ -37,9 +51,10 @@ func (cks *CKSProtocol) ShallowCopy() *CKSProtocol {
 
 	return &CKSProtocol{
 		params:          params,
-		gaussianSampler: ring.NewGaussianSampler(prng, params.RingQ(), cks.sigmaSmudging, int(6*cks.sigmaSmudging)),
-		basisExtender:   cks.basisExtender.ShallowCopy(),
-		tmpQP:           params.RingQP().NewPoly(),
+		sigmaSmudging:   cks.sigmaSmudging,
+		fork:            utils.NewPRNGFork(source),
+		gaussianSampler: ring.NewDiscreteGaussianSampler(prng, params.RingQ(), cks.sigmaSmudging, 6*cks.sigmaSmudging, params.GaussianSamplerType()),
+		tmpQ:            params.RingQ().NewPoly(),
 		tmpDelta:        params.RingQ().NewPoly(),
 	}
 }
@@ -66,6 +81,8 @@ func (ckss *CKSShare) UnmarshalBinary(data []byte) (err error) {
 // NewCKSProtocol creates a new CKSProtocol that will be used to perform a collective key-switching on a ciphertext encrypted under a collective public-key, whose
 // secret-shares are distributed among j parties, re-encrypting the ciphertext under another public-key, whose secret-shares are also known to the
 // parties.
+// The smudging noise is sampled with the discrete Gaussian sampler type of the parameters: with ring.GaussianCDT, large
+// values of sigmaSmudging are sampled in constant time by convolution (see ring.NewDiscreteGaussianSampler).
 func NewCKSProtocol(params rlwe.Parameters, sigmaSmudging float64) *CKSProtocol {
 	cks := new(CKSProtocol)
 	cks.params = params
@@ -74,12 +91,8 @@ func NewCKSProtocol(params rlwe.Parameters, sigmaSmudging float64) *CKSProtocol
 	if err != nil {
 		panic(err)
 	}
-	cks.gaussianSampler = ring.NewGaussianSampler(prng, params.RingQ(), sigmaSmudging, int(6*sigmaSmudging))
-
-	if cks.params.RingP() != nil {
-		cks.basisExtender = ring.NewBasisExtender(params.RingQ(), params.RingP())
-	}
-	cks.tmpQP = params.RingQP().NewPoly()
+	cks.gaussianSampler = ring.NewDiscreteGaussianSampler(prng, params.RingQ(), sigmaSmudging, 6*sigmaSmudging, params.GaussianSamplerType())
+	cks.tmpQ = params.RingQ().NewPoly()
 	cks.tmpDelta = params.RingQ().NewPoly()
 	return cks
 }
@@ -100,72 +113,36 @@ func (cks *CKSProtocol) SampleCRP(level int, crs CRS) CKSCRP {
 // GenShare computes a party's share in the CKS protocol.
 // ct1 is the degree 1 element of the rlwe.Ciphertext to keyswitch, i.e. ct1 = rlwe.Ciphertext.Value[1].
 // NTT flag for ct1 is expected to be set correctly.
+// The smudging noise is added to the share as is (it is not scaled down by the special modulus P), so that
+// sigmaSmudging is the standard deviation of the noise that the share adds to the key-switched ciphertext.
 func (cks *CKSProtocol) GenShare(skInput, skOutput *rlwe.SecretKey, c1 *ring.Poly, shareOut *CKSShare) {
 
 	ringQ := cks.params.RingQ()
-	ringP := cks.params.RingP()
-	ringQP := cks.params.RingQP()
 
 	levelQ := utils.MinInt(shareOut.Value.Level(), c1.Level())
-	levelP := cks.params.PCount() - 1
 
 	ringQ.SubLvl(levelQ, skInput.Value.Q, skOutput.Value.Q, cks.tmpDelta)
 
 	ct1 := c1
 	if !c1.IsNTT {
-		ringQ.NTTLazyLvl(levelQ, c1, cks.tmpQP.Q)
-		ct1 = cks.tmpQP.Q
+		ringQ.NTTLazyLvl(levelQ, c1, cks.tmpQ)
+		ct1 = cks.tmpQ
 	}
 
 	// a * (skIn - skOut) mod Q
-	ringQ.MulCoeffsMontgomeryConstantLvl(levelQ, ct1, cks.tmpDelta, shareOut.Value)
+	ringQ.MulCoeffsMontgomeryLvl(levelQ, ct1, cks.tmpDelta, shareOut.Value)
 
-	if ringP != nil {
-		// P * a * (skIn - skOut) mod QP (mod P = 0)
-		ringQ.MulScalarBigintLvl(levelQ, shareOut.Value, ringP.ModulusBigint, shareOut.Value)
-	}
+	// Samples e in Q
+	cks.gaussianSampler.ReadLvl(levelQ, cks.tmpQ)
 
 	if !c1.IsNTT {
-		// InvNTT(P * a * (skIn - skOut)) mod QP (mod P = 0)
-		ringQ.InvNTTLazyLvl(levelQ, shareOut.Value, shareOut.Value)
-
-		// Samples e in Q
-		cks.gaussianSampler.ReadLvl(levelQ, cks.tmpQP.Q)
-
-		if ringP != nil {
-			// Extend e to P (assumed to have norm < qi)
-			ringQP.ExtendBasisSmallNormAndCenter(cks.tmpQP.Q, levelP, nil, cks.tmpQP.P)
-		}
-
-		// InvNTT(P * a * (skIn - skOut) + e) mod QP (mod P = e)
-		ringQ.AddNoModLvl(levelQ, shareOut.Value, cks.tmpQP.Q, shareOut.Value)
-
-		if ringP != nil {
-			// InvNTT(P * a * (skIn - skOut) + e) * (1/P) mod QP (mod P = e)
-			cks.basisExtender.ModDownQPtoQ(levelQ, levelP, shareOut.Value, cks.tmpQP.P, shareOut.Value)
-		}
-
-	} else {
-		// Sample e in Q
-		cks.gaussianSampler.ReadLvl(levelQ, cks.tmpQP.Q)
-
-		if ringP != nil {
-			// Extend e to P (assumed to have norm < qi)
-			ringQP.ExtendBasisSmallNormAndCenter(cks.tmpQP.Q, levelP, nil, cks.tmpQP.P)
-		}
-
-		// Takes the error to the NTT domain
+		// InvNTT(a * (skIn - skOut)) + e mod Q
 		ringQ.InvNTTLvl(levelQ, shareOut.Value, shareOut.Value)
-
-		// P * a * (skIn - skOut) + e mod Q (mod P = 0, so P = e)
-		ringQ.AddLvl(levelQ, shareOut.Value, cks.tmpQP.Q, shareOut.Value)
-
-		if ringP != nil {
-			// (P * a * (skIn - skOut) + e) * (1/P) mod QP (mod P = e)
-			cks.basisExtender.ModDownQPtoQ(levelQ, levelP, shareOut.Value, cks.tmpQP.P, shareOut.Value)
-		}
-
-		ringQ.NTTLvl(levelQ, shareOut.Value, shareOut.Value)
+		ringQ.AddLvl(levelQ, shareOut.Value, cks.tmpQ, shareOut.Value)
+	} else {
+		// a * (skIn - skOut) + NTT(e) mod Q
+		ringQ.NTTLvl(levelQ, cks.tmpQ, cks.tmpQ)
+		ringQ.AddLvl(levelQ, shareOut.Value, cks.tmpQ, shareOut.Value)
 	}
 
 	shareOut.Value.Coeffs = shareOut.Value.Coeffs[:levelQ+1]
@@ -184,3 +161,78 @@ func (cks *CKSProtocol) KeySwitch(ctIn *rlwe.Ciphertext, combined *CKSShare, ctO
 	cks.params.RingQ().AddLvl(level, ctIn.Value[0], combined.Value, ctOut.Value[0])
 	ring.CopyValuesLvl(level, ctIn.Value[1], ctOut.Value[1])
 }
+
+// CKGCommitment is the share ckgShare = -ckgCRP*s_i + e_i of a party in the generation of a collective public key,
+// together with its common reference polynomial ckgCRP. It commits the party to its secret key s_i in the proofs
+// of the CKS shares.
+type CKGCommitment struct {
+	CRP   CKGCRP
+	Share *CKGShare
+}
+
+// relation returns the relation share = c1*(skInput_i - skOutput_i) + e_i proven by the proofs of the CKS shares,
+// together with the relations ckgShare = -ckgCRP*sk_i + e'_i of the commitments of the party to skInput_i and
+// skOutput_i, which guarantee that the share is generated from the party's shares of the collective keys.
+// If output is nil, the output key is the zero key (i.e. the share is a decryption share) and the relation is
+// share = c1*skInput_i + e_i.
+func (cks *CKSProtocol) relation(c1 *ring.Poly, share *CKSShare, input CKGCommitment, output *CKGCommitment) *shareRelation {
+
+	params := cks.params
+	ringQ := params.RingQ()
+
+	levelQ := utils.MinInt(share.Value.Level(), c1.Level())
+
+	// The witnesses are skInput_i, e_i, e'_i for the input key and, if output is not nil, skOutput_i and e'_i
+	// for the output key.
+	bounds := []int64{normBound(params.Xs()), int64(math.Ceil(6 * cks.sigmaSmudging)), normBound(params.Xe())}
+	if output != nil {
+		bounds = append(bounds, normBound(params.Xs()), normBound(params.Xe()))
+	}
+
+	rel := newShareRelation(params, "CKS", bounds)
+
+	ct1, h := c1, share.Value
+	if !c1.IsNTT {
+		ct1, h = ringQ.NewPolyLvl(levelQ), ringQ.NewPolyLvl(levelQ)
+		ringQ.NTTLvl(levelQ, c1, ct1)
+		ringQ.NTTLvl(levelQ, share.Value, h)
+	}
+
+	row := rel.addRow(levelQ, -1, rlwe.PolyQP{Q: h}, 1)
+	rel.addTerm(row, 0, rlwe.PolyQP{Q: ct1}, false)
+	if output != nil {
+		rel.addTerm(row, 3, rlwe.PolyQP{Q: ct1}, true)
+	}
+
+	row = rel.addRow(params.QCount()-1, params.PCount()-1, input.Share.Value, 2)
+	rel.addTerm(row, 0, rlwe.PolyQP(input.CRP), true)
+
+	if output != nil {
+		row = rel.addRow(params.QCount()-1, params.PCount()-1, output.Share.Value, 4)
+		rel.addTerm(row, 3, rlwe.PolyQP(output.CRP), true)
+	}
+
+	return rel
+}
+
+// GenShareProof generates a zero-knowledge proof that the share was generated by GenShare from the ciphertext
+// element c1, the input secret key skInput and the output secret key skOutput, where input and output are the
+// commitments of the party to these keys, i.e. its shares in the generation of the corresponding collective
+// public keys. If output is nil, the output key must be the zero key, i.e. skOutput must be nil or zero, as for
+// the shares of a collective decryption. Returns an error if the share is not of this form.
+func (cks *CKSProtocol) GenShareProof(skInput, skOutput *rlwe.SecretKey, c1 *ring.Poly, share *CKSShare, input CKGCommitment, output *CKGCommitment) (proof *ShareProof, err error) {
+	rel := cks.relation(c1, share, input, output)
+	w := [][]int64{rel.secretToInt64(skInput.Value), nil, nil}
+	if output != nil {
+		w = append(w, rel.secretToInt64(skOutput.Value), nil)
+	}
+	return rel.prove(w)
+}
+
+// VerifyShare checks the proof that the share was correctly generated from the ciphertext element c1, with the
+// secret keys to which the party committed with input and output. If output is nil, the proof guarantees that
+// the share was generated for the zero output key, i.e. that it is a decryption share. VerifyShare should be
+// called on each share before aggregating it with AggregateShare.
+func (cks *CKSProtocol) VerifyShare(c1 *ring.Poly, share *CKSShare, input CKGCommitment, output *CKGCommitment, proof *ShareProof) bool {
+	return cks.relation(c1, share, input, output).verify(proof)
+}
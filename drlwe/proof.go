@@ -0,0 +1,608 @@
+package drlwe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"math"
+	"math/bits"
+
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+	"golang.org/x/crypto/blake2b"
+)
+
+// ShareProof is a non-interactive zero-knowledge proof that a share of a protocol was correctly generated, i.e.,
+// that the share is of the form sum_j a_j * w_j, where the a_j are the public polynomials of the protocol
+// (common reference polynomials, ciphertexts, shares of the previous rounds) and the w_j are short polynomials
+// known to the party (secret key, ephemeral key and errors).
+//
+// The proof is a Fiat-Shamir transformed sigma protocol with rejection sampling (a proof of shortness with
+// slack). A valid proof guarantees that the prover knows short polynomials w'_j and a challenge difference c'
+// (a polynomial with at most 2*kappa non-zero coefficients in [-2, 2]) such that c' * share = sum_j a_j * w'_j,
+// where the norm of each w'_j is at most 2 * kappa * N * #w times the norm bound of the honest w_j.
+type ShareProof struct {
+	Challenge []byte
+	Response  [][]int64
+}
+
+// MarshalBinary encodes the proof on a slice of bytes.
+func (proof *ShareProof) MarshalBinary() (data []byte, err error) {
+	buf := new(bytes.Buffer)
+	if err = binary.Write(buf, binary.LittleEndian, uint32(len(proof.Challenge))); err != nil {
+		return nil, err
+	}
+	buf.Write(proof.Challenge)
+	if err = binary.Write(buf, binary.LittleEndian, uint32(len(proof.Response))); err != nil {
+		return nil, err
+	}
+	for _, z := range proof.Response {
+		if err = binary.Write(buf, binary.LittleEndian, uint32(len(z))); err != nil {
+			return nil, err
+		}
+		if err = binary.Write(buf, binary.LittleEndian, z); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a slice of bytes on the target proof.
+func (proof *ShareProof) UnmarshalBinary(data []byte) (err error) {
+	buf := bytes.NewReader(data)
+	var n uint32
+	if err = binary.Read(buf, binary.LittleEndian, &n); err != nil {
+		return err
+	}
+	if int(n) > buf.Len() {
+		return errors.New("ShareProof: invalid encoding")
+	}
+	proof.Challenge = make([]byte, n)
+	if _, err = buf.Read(proof.Challenge); err != nil {
+		return err
+	}
+	if err = binary.Read(buf, binary.LittleEndian, &n); err != nil {
+		return err
+	}
+	if int(n) > buf.Len()/4 {
+		return errors.New("ShareProof: invalid encoding")
+	}
+	proof.Response = make([][]int64, n)
+	for i := range proof.Response {
+		if err = binary.Read(buf, binary.LittleEndian, &n); err != nil {
+			return err
+		}
+		if int(n) > buf.Len()/8 {
+			return errors.New("ShareProof: invalid encoding")
+		}
+		proof.Response[i] = make([]int64, n)
+		if err = binary.Read(buf, binary.LittleEndian, proof.Response[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maxProofAttempts is the maximum number of rejections before the proof generation fails.
+// For honest witnesses, each attempt is accepted with probability about 1/e.
+const maxProofAttempts = 256
+
+// shareRelation is a linear relation t_i = sum_j a_ij * w_j over R_QP (or R_Q for the rows
+// with levelP = -1), where the w_j are polynomials with small coefficients.
+type shareRelation struct {
+	params rlwe.Parameters
+	domain string
+	bounds []int64
+	rows   []*relationRow
+	digest []byte
+}
+
+// relationRow is a row of a shareRelation. The non-zero a_j are stored in the NTT and Montgomery
+// domain and t is stored in the NTT domain. The witness err has coefficient one in the row.
+type relationRow struct {
+	levelQ, levelP int
+	a              []*rlwe.PolyQP
+	t              rlwe.PolyQP
+	err            int
+}
+
+// newShareRelation creates a new relation for witnesses with the given infinity norm bounds.
+// The domain separates the proofs of different protocols.
+func newShareRelation(params rlwe.Parameters, domain string, bounds []int64) *shareRelation {
+	return &shareRelation{params: params, domain: domain, bounds: bounds}
+}
+
+// normBound returns the infinity norm bound of the polynomials sampled from the distribution d.
+func normBound(d ring.Distribution) int64 {
+	return int64(math.Ceil(d.NormBound()))
+}
+
+// addRow adds the row t = w_err + sum_j a_j * w_j to the relation, where t is in the NTT domain.
+func (rel *shareRelation) addRow(levelQ, levelP int, t rlwe.PolyQP, err int) (row *relationRow) {
+
+	row = &relationRow{levelQ: levelQ, levelP: levelP, a: make([]*rlwe.PolyQP, len(rel.bounds)), t: t, err: err}
+
+	one := rel.newPoly(levelQ, levelP)
+	ringQ, ringP := rel.params.RingQ(), rel.params.RingP()
+	for i := 0; i < levelQ+1; i++ {
+		c := ring.MForm(1, ringQ.Modulus[i], ringQ.BredParams[i])
+		for k := range one.Q.Coeffs[i] {
+			one.Q.Coeffs[i][k] = c
+		}
+	}
+	for i := 0; i < levelP+1; i++ {
+		c := ring.MForm(1, ringP.Modulus[i], ringP.BredParams[i])
+		for k := range one.P.Coeffs[i] {
+			one.P.Coeffs[i][k] = c
+		}
+	}
+	row.a[err] = &one
+
+	rel.rows = append(rel.rows, row)
+	return
+}
+
+func (rel *shareRelation) newPoly(levelQ, levelP int) (p rlwe.PolyQP) {
+	p.Q = rel.params.RingQ().NewPolyLvl(levelQ)
+	if levelP > -1 {
+		p.P = rel.params.RingP().NewPolyLvl(levelP)
+	}
+	return
+}
+
+// addTerm adds a (or -a if negate is true) to the coefficient of w_j in the row, where a is in the NTT domain.
+func (rel *shareRelation) addTerm(row *relationRow, j int, a rlwe.PolyQP, negate bool) {
+
+	ringQ, ringP := rel.params.RingQ(), rel.params.RingP()
+
+	tmp := rel.newPoly(row.levelQ, row.levelP)
+	ringQ.MFormLvl(row.levelQ, a.Q, tmp.Q)
+	if negate {
+		ringQ.NegLvl(row.levelQ, tmp.Q, tmp.Q)
+	}
+	if row.levelP > -1 {
+		ringP.MFormLvl(row.levelP, a.P, tmp.P)
+		if negate {
+			ringP.NegLvl(row.levelP, tmp.P, tmp.P)
+		}
+	}
+
+	if row.a[j] == nil {
+		row.a[j] = &tmp
+	} else {
+		rel.addLvl(row.levelQ, row.levelP, *row.a[j], tmp, *row.a[j])
+	}
+}
+
+// addConstantTerm adds the constant polynomial whose CRT representation is given by the constant on each
+// modulus of Q and P to the coefficient of w_j in the row.
+func (rel *shareRelation) addConstantTerm(row *relationRow, j int, constQ, constP []uint64) {
+
+	ringQ, ringP := rel.params.RingQ(), rel.params.RingP()
+
+	tmp := rel.newPoly(row.levelQ, row.levelP)
+	for i := 0; i < row.levelQ+1; i++ {
+		c := ring.MForm(constQ[i], ringQ.Modulus[i], ringQ.BredParams[i])
+		for k := range tmp.Q.Coeffs[i] {
+			tmp.Q.Coeffs[i][k] = c
+		}
+	}
+	for i := 0; i < row.levelP+1; i++ {
+		c := ring.MForm(constP[i], ringP.Modulus[i], ringP.BredParams[i])
+		for k := range tmp.P.Coeffs[i] {
+			tmp.P.Coeffs[i][k] = c
+		}
+	}
+
+	if row.a[j] == nil {
+		row.a[j] = &tmp
+	} else {
+		rel.addLvl(row.levelQ, row.levelP, *row.a[j], tmp, *row.a[j])
+	}
+}
+
+func (rel *shareRelation) addLvl(levelQ, levelP int, p1, p2, p3 rlwe.PolyQP) {
+	rel.params.RingQ().AddLvl(levelQ, p1.Q, p2.Q, p3.Q)
+	if levelP > -1 {
+		rel.params.RingP().AddLvl(levelP, p1.P, p2.P, p3.P)
+	}
+}
+
+// secretToInt64 returns the centered coefficients of a secret polynomial in the NTT and Montgomery domain.
+func (rel *shareRelation) secretToInt64(sk rlwe.PolyQP) []int64 {
+	ringQ := rel.params.RingQ()
+	tmp := ringQ.NewPolyLvl(0)
+	ringQ.InvMFormLvl(0, sk.Q, tmp)
+	ringQ.InvNTTLvl(0, tmp, tmp)
+	return centered(tmp.Coeffs[0], ringQ.Modulus[0])
+}
+
+func centered(coeffs []uint64, q uint64) (v []int64) {
+	v = make([]int64, len(coeffs))
+	for i, c := range coeffs {
+		c %= q
+		if c >= q>>1 {
+			v[i] = -int64(q - c)
+		} else {
+			v[i] = int64(c)
+		}
+	}
+	return
+}
+
+// int64ToPoly sets p to the NTT of the polynomial with the given coefficients.
+func (rel *shareRelation) int64ToPoly(levelQ, levelP int, v []int64, p rlwe.PolyQP) {
+	ringQ, ringP := rel.params.RingQ(), rel.params.RingP()
+
+	set := func(level int, modulus []uint64, coeffs [][]uint64) {
+		for i := 0; i < level+1; i++ {
+			q := modulus[i]
+			for k, c := range v {
+				if c < 0 {
+					coeffs[i][k] = q - uint64(-c)%q
+					if coeffs[i][k] == q {
+						coeffs[i][k] = 0
+					}
+				} else {
+					coeffs[i][k] = uint64(c) % q
+				}
+			}
+		}
+	}
+
+	set(levelQ, ringQ.Modulus, p.Q.Coeffs)
+	ringQ.NTTLvl(levelQ, p.Q, p.Q)
+
+	if levelP > -1 {
+		set(levelP, ringP.Modulus, p.P.Coeffs)
+		ringP.NTTLvl(levelP, p.P, p.P)
+	}
+}
+
+// witnessToPolys returns the NTT of the witness polynomials at the maximum levels used by the relation.
+func (rel *shareRelation) witnessToPolys(w [][]int64) (polys []rlwe.PolyQP) {
+	levelQ, levelP := rel.maxLevels()
+	polys = make([]rlwe.PolyQP, len(w))
+	for j := range w {
+		polys[j] = rel.newPoly(levelQ, levelP)
+		rel.int64ToPoly(levelQ, levelP, w[j], polys[j])
+	}
+	return
+}
+
+func (rel *shareRelation) maxLevels() (levelQ, levelP int) {
+	levelQ, levelP = -1, -1
+	for _, row := range rel.rows {
+		levelQ, levelP = utils.MaxInt(levelQ, row.levelQ), utils.MaxInt(levelP, row.levelP)
+	}
+	return
+}
+
+// evaluate returns sum_j a_j * w_j for the given row, where the w_j are in the NTT domain.
+func (rel *shareRelation) evaluate(row *relationRow, w []rlwe.PolyQP) (res rlwe.PolyQP) {
+	ringQ, ringP := rel.params.RingQ(), rel.params.RingP()
+	res = rel.newPoly(row.levelQ, row.levelP)
+	for j, a := range row.a {
+		if a != nil {
+			ringQ.MulCoeffsMontgomeryAndAddLvl(row.levelQ, a.Q, w[j].Q, res.Q)
+			if row.levelP > -1 {
+				ringP.MulCoeffsMontgomeryAndAddLvl(row.levelP, a.P, w[j].P, res.P)
+			}
+		}
+	}
+	return
+}
+
+// solveErrors sets the missing error witnesses to w_err = t - sum_{j != err} a_j * w_j.
+func (rel *shareRelation) solveErrors(w [][]int64) {
+
+	ringQ := rel.params.RingQ()
+
+	// The missing errors are zero in polys
+	polys := rel.witnessToPolys(w)
+
+	for _, row := range rel.rows {
+
+		if w[row.err] != nil {
+			continue
+		}
+
+		res := rel.evaluate(row, polys)
+
+		ringQ.SubLvl(0, row.t.Q, res.Q, res.Q)
+		ringQ.InvNTTLvl(0, res.Q, res.Q)
+		w[row.err] = centered(res.Q.Coeffs[0], ringQ.Modulus[0])
+	}
+}
+
+// hash returns the digest of the relation and the commitments.
+func (rel *shareRelation) hash(commitments []rlwe.PolyQP) []byte {
+
+	// The digest of the relation is computed once, since it does not depend on the commitments
+	if rel.digest == nil {
+		h := rel.newHash()
+		rel.writeUint64(h, uint64(rel.params.N()))
+		for _, qi := range rel.params.Q() {
+			rel.writeUint64(h, qi)
+		}
+		for _, pi := range rel.params.P() {
+			rel.writeUint64(h, pi)
+		}
+		for _, b := range rel.bounds {
+			rel.writeUint64(h, uint64(b))
+		}
+		for _, row := range rel.rows {
+			rel.writeUint64(h, uint64(row.levelQ+1))
+			rel.writeUint64(h, uint64(row.levelP+1))
+			rel.writeUint64(h, uint64(row.err))
+			for j, a := range row.a {
+				if a != nil {
+					rel.writeUint64(h, uint64(j))
+					rel.writePoly(h, row.levelQ, row.levelP, *a)
+				}
+			}
+			rel.writePoly(h, row.levelQ, row.levelP, row.t)
+		}
+		rel.digest = h.Sum(nil)
+	}
+
+	h := rel.newHash()
+	h.Write(rel.digest)
+	for i, row := range rel.rows {
+		rel.writePoly(h, row.levelQ, row.levelP, commitments[i])
+	}
+
+	return h.Sum(nil)
+}
+
+func (rel *shareRelation) newHash() hash.Hash {
+	h, err := blake2b.New256([]byte(rel.domain))
+	if err != nil {
+		panic(err)
+	}
+	return h
+}
+
+func (rel *shareRelation) writeUint64(h hash.Hash, x uint64) {
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, x)
+	h.Write(buf)
+}
+
+// writePoly writes the reduced coefficients of p on h.
+func (rel *shareRelation) writePoly(h hash.Hash, levelQ, levelP int, p rlwe.PolyQP) {
+
+	buf := make([]byte, 8*rel.params.N())
+
+	write := func(coeffs []uint64, q uint64) {
+		for k, c := range coeffs {
+			binary.LittleEndian.PutUint64(buf[8*k:], c%q)
+		}
+		h.Write(buf)
+	}
+
+	for i := 0; i < levelQ+1; i++ {
+		write(p.Q.Coeffs[i], rel.params.RingQ().Modulus[i])
+	}
+	for i := 0; i < levelP+1; i++ {
+		write(p.P.Coeffs[i], rel.params.RingP().Modulus[i])
+	}
+}
+
+// challengeWeight returns the smallest number of non-zero coefficients kappa of a ternary challenge
+// such that the challenge space has at least 2^128 elements.
+func challengeWeight(N int) (kappa int) {
+	lgN, _ := math.Lgamma(float64(N + 1))
+	for kappa = 1; kappa < N; kappa++ {
+		lgK, _ := math.Lgamma(float64(kappa + 1))
+		lgNK, _ := math.Lgamma(float64(N - kappa + 1))
+		if (lgN-lgK-lgNK)/math.Ln2+float64(kappa) >= 128 {
+			break
+		}
+	}
+	return
+}
+
+// challenge expands the digest into a polynomial with kappa coefficients in {-1, 1} and all others zero.
+func challenge(digest []byte, N, kappa int) (c []int64) {
+
+	prng, err := utils.NewKeyedPRNG(digest)
+	if err != nil {
+		panic(err)
+	}
+
+	buf := make([]byte, 8)
+	randInt := func(bound uint64) uint64 {
+		mask := ^uint64(0) >> uint(64-bits.Len64(bound))
+		for {
+			prng.Clock(buf)
+			if x := binary.LittleEndian.Uint64(buf) & mask; x < bound {
+				return x
+			}
+		}
+	}
+
+	c = make([]int64, N)
+	for i := N - kappa; i < N; i++ {
+		j := randInt(uint64(i + 1))
+		c[i] = c[j]
+		c[j] = 1 - 2*int64(randInt(2))
+	}
+
+	return
+}
+
+// mulChallenge returns the negacyclic product of the sparse challenge c with w.
+func mulChallenge(c, w []int64) (res []int64) {
+	N := len(w)
+	res = make([]int64, N)
+	for p, s := range c {
+		if s == 0 {
+			continue
+		}
+		for k, x := range w {
+			if idx := k + p; idx < N {
+				res[idx] += s * x
+			} else {
+				res[idx-N] -= s * x
+			}
+		}
+	}
+	return
+}
+
+// maskBounds returns the weight of the challenges and the bounds of the masks of the witnesses, or an error
+// if the masks cannot be represented.
+func (rel *shareRelation) maskBounds() (kappa int, masks []int64, err error) {
+	N := rel.params.N()
+	kappa = challengeWeight(N)
+	slack := int64(kappa) * int64(N) * int64(len(rel.bounds))
+	masks = make([]int64, len(rel.bounds))
+	for j, b := range rel.bounds {
+		// The masks are sampled in [-B, B], hence 2*B+1 must not overflow
+		if b < 0 || b > (math.MaxInt64/2)/slack {
+			return 0, nil, fmt.Errorf("witness %d has a norm bound %d too large for the proof", j, b)
+		}
+		masks[j] = slack * b
+	}
+	return
+}
+
+// prove generates a proof of knowledge of the witness w. The errors that are not given (i.e., nil) are
+// derived from the relation. Returns an error if the witness does not satisfy the norm bounds.
+func (rel *shareRelation) prove(w [][]int64) (proof *ShareProof, err error) {
+
+	N := rel.params.N()
+
+	rel.solveErrors(w)
+
+	for j := range w {
+		for _, c := range w[j] {
+			if c > rel.bounds[j] || c < -rel.bounds[j] {
+				return nil, fmt.Errorf("cannot generate proof: witness %d has norm larger than %d", j, rel.bounds[j])
+			}
+		}
+	}
+
+	kappa, masks, err := rel.maskBounds()
+	if err != nil {
+		return nil, fmt.Errorf("cannot generate proof: %w", err)
+	}
+
+	prng, err := utils.NewPRNG()
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 8)
+	y := make([][]int64, len(w))
+	for j := range y {
+		y[j] = make([]int64, N)
+	}
+
+	commitments := make([]rlwe.PolyQP, len(rel.rows))
+
+	for attempt := 0; attempt < maxProofAttempts; attempt++ {
+
+		// Samples the masks uniformly in [-B, B]
+		for j := range y {
+			width := uint64(2*masks[j] + 1)
+			mask := ^uint64(0) >> uint(64-bits.Len64(width))
+			for k := range y[j] {
+				for {
+					prng.Clock(buf)
+					if x := binary.LittleEndian.Uint64(buf) & mask; x < width {
+						y[j][k] = int64(x) - masks[j]
+						break
+					}
+				}
+			}
+		}
+
+		yPolys := rel.witnessToPolys(y)
+		for i, row := range rel.rows {
+			commitments[i] = rel.evaluate(row, yPolys)
+		}
+
+		digest := rel.hash(commitments)
+		c := challenge(digest, N, kappa)
+
+		// z = y + c * w, rejected if a coefficient is not in [-(B - kappa * bound), B - kappa * bound]
+		z := make([][]int64, len(w))
+		accepted := true
+		for j := range w {
+			z[j] = mulChallenge(c, w[j])
+			limit := masks[j] - int64(kappa)*rel.bounds[j]
+			for k := range z[j] {
+				z[j][k] += y[j][k]
+				if z[j][k] > limit || z[j][k] < -limit {
+					accepted = false
+					break
+				}
+			}
+			if !accepted {
+				break
+			}
+		}
+
+		if accepted {
+			return &ShareProof{Challenge: digest, Response: z}, nil
+		}
+	}
+
+	return nil, errors.New("cannot generate proof: too many rejections")
+}
+
+// verify checks the proof against the relation.
+func (rel *shareRelation) verify(proof *ShareProof) bool {
+
+	N := rel.params.N()
+
+	if proof == nil || len(proof.Response) != len(rel.bounds) {
+		return false
+	}
+
+	kappa, masks, err := rel.maskBounds()
+	if err != nil {
+		return false
+	}
+
+	for j, z := range proof.Response {
+		if len(z) != N {
+			return false
+		}
+		limit := masks[j] - int64(kappa)*rel.bounds[j]
+		for _, c := range z {
+			if c > limit || c < -limit {
+				return false
+			}
+		}
+	}
+
+	ringQ, ringP := rel.params.RingQ(), rel.params.RingP()
+
+	// c in the NTT and Montgomery domain
+	levelQ, levelP := rel.maxLevels()
+	c := rel.newPoly(levelQ, levelP)
+	rel.int64ToPoly(levelQ, levelP, challenge(proof.Challenge, N, kappa), c)
+	ringQ.MFormLvl(levelQ, c.Q, c.Q)
+	if levelP > -1 {
+		ringP.MFormLvl(levelP, c.P, c.P)
+	}
+
+	// w1 = A * z - c * t
+	zPolys := rel.witnessToPolys(proof.Response)
+	commitments := make([]rlwe.PolyQP, len(rel.rows))
+	for i, row := range rel.rows {
+		commitments[i] = rel.evaluate(row, zPolys)
+		ringQ.MulCoeffsMontgomeryAndSubLvl(row.levelQ, c.Q, row.t.Q, commitments[i].Q)
+		if row.levelP > -1 {
+			ringP.MulCoeffsMontgomeryAndSubLvl(row.levelP, c.P, row.t.P, commitments[i].P)
+		}
+	}
+
+	return bytes.Equal(rel.hash(commitments), proof.Challenge)
+}
@@ -0,0 +1,90 @@
+package drlwe
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+// SmudgingParameters stores the inputs of the estimation of the smudging noise of the CKS and PCKS protocols, and of the
+// protocols based on them. The smudging noise is sampled such that the share of a single honest party statistically hides
+// the noise of the input ciphertext, regardless of the other parties.
+type SmudgingParameters struct {
+	// Parties is the number of parties adding smudging noise.
+	Parties int
+	// LogNoiseBound is the log2 of a bound on the noise of the input ciphertext, i.e. of the noise to hide.
+	LogNoiseBound float64
+	// Lambda is the statistical security parameter: the statistical distance between the outputs of the protocol
+	// for two input ciphertexts that differ only by their noise is at most 2^-Lambda.
+	Lambda int
+	// LogRoom is the log2 of the largest noise that can be tolerated on the output of the protocol.
+	LogRoom float64
+	// LogMaxSample is the log2 of the largest noise that the samplers can generate, i.e. of the smallest modulus of Q.
+	LogMaxSample float64
+}
+
+// NewSmudgingParameters creates a new SmudgingParameters for the given parameters, where logRoom is the log2 of the
+// largest noise that can be tolerated on the output of the protocol.
+// The smudging noise is added to the shares in R_Q after the division by the special modulus P, hence only the
+// moduli of Q bound the samples.
+func NewSmudgingParameters(params rlwe.Parameters, nParties int, logNoiseBound float64, lambda int, logRoom float64) SmudgingParameters {
+	logMaxSample := math.Inf(1)
+	for _, qi := range params.Q() {
+		logMaxSample = math.Min(logMaxSample, math.Log2(float64(qi)))
+	}
+	return SmudgingParameters{
+		Parties:       nParties,
+		LogNoiseBound: logNoiseBound,
+		Lambda:        lambda,
+		LogRoom:       logRoom,
+		LogMaxSample:  logMaxSample,
+	}
+}
+
+// Estimate returns the standard deviation sigma of the smudging noise that each party must sample, the log2 of a bound
+// on the noise of the output of the protocol, which includes the noise of the input ciphertext and the smudging noise of
+// all the parties, and ok, which is set to false if this bound exceeds the room sp.LogRoom or if the smudging noise
+// cannot be sampled.
+func (sp SmudgingParameters) Estimate() (sigma, logNoise float64, ok bool) {
+
+	sigma = math.Max(math.Exp2(sp.LogNoiseBound+float64(sp.Lambda)), rlwe.DefaultSigma)
+
+	// The samplers are truncated at 6*sigma and the noise of the parties adds up in the worst case.
+	bound := 6 * sigma
+	logNoise = math.Log2(math.Exp2(sp.LogNoiseBound) + float64(sp.Parties)*bound)
+
+	ok = logNoise <= sp.LogRoom && math.Log2(bound) < sp.LogMaxSample
+
+	return sigma, logNoise, ok
+}
+
+// Sigma returns the standard deviation of the smudging noise that each party must sample, or an error if
+// the parameters do not have enough room for it.
+func (sp SmudgingParameters) Sigma() (sigma float64, err error) {
+	sigma, logNoise, ok := sp.Estimate()
+	if !ok {
+		return 0, fmt.Errorf("not enough room for the smudging noise: output noise 2^%.2f for a room of 2^%.2f and samples of at most 2^%.2f", logNoise, sp.LogRoom, sp.LogMaxSample)
+	}
+	return sigma, nil
+}
+
+// NewCKSProtocolWithSmudging creates a new CKSProtocol whose smudging noise is estimated from sp.
+// It returns an error if the parameters do not have enough room for the smudging noise.
+func NewCKSProtocolWithSmudging(params rlwe.Parameters, sp SmudgingParameters) (*CKSProtocol, error) {
+	sigmaSmudging, err := sp.Sigma()
+	if err != nil {
+		return nil, fmt.Errorf("cannot NewCKSProtocolWithSmudging: %w", err)
+	}
+	return NewCKSProtocol(params, sigmaSmudging), nil
+}
+
+// NewPCKSProtocolWithSmudging creates a new PCKSProtocol whose smudging noise is estimated from sp.
+// It returns an error if the parameters do not have enough room for the smudging noise.
+func NewPCKSProtocolWithSmudging(params rlwe.Parameters, sp SmudgingParameters) (*PCKSProtocol, error) {
+	sigmaSmudging, err := sp.Sigma()
+	if err != nil {
+		return nil, fmt.Errorf("cannot NewPCKSProtocolWithSmudging: %w", err)
+	}
+	return NewPCKSProtocol(params, sigmaSmudging), nil
+}
@@ -4,11 +4,11 @@ import (
 	"log"
 	"os"
 	"strconv"
-	"sync"
 	"time"
 
 	"github.com/tuneinsight/lattigo/v3/bfv"
 	"github.com/tuneinsight/lattigo/v3/dbfv"
+	"github.com/tuneinsight/lattigo/v3/dbfv/pir"
 	"github.com/tuneinsight/lattigo/v3/drlwe"
 	"github.com/tuneinsight/lattigo/v3/rlwe"
 	"github.com/tuneinsight/lattigo/v3/utils"
@@ -40,19 +40,11 @@ type party struct {
 	rkgShareOne *drlwe.RKGShare
 	rkgShareTwo *drlwe.RKGShare
 	rtgShare    *drlwe.RTGShare
-	cksShare    *drlwe.CKSShare
+	cdShare     *drlwe.CollectiveDecryptShare
 
 	input []uint64
 }
 
-type maskTask struct {
-	query           *bfv.Ciphertext
-	mask            *bfv.PlaintextMul
-	row             *bfv.Ciphertext
-	res             *bfv.Ciphertext
-	elapsedmaskTask time.Duration
-}
-
 var elapsedCKGCloud time.Duration
 var elapsedCKGParty time.Duration
 var elapsedRKGCloud time.Duration
@@ -63,7 +55,6 @@ var elapsedCKSCloud time.Duration
 var elapsedPCKSParty time.Duration
 var elapsedRequestParty time.Duration
 var elapsedRequestCloud time.Duration
-var elapsedRequestCloudCPU time.Duration
 
 func main() {
 
@@ -76,12 +67,12 @@ func main() {
 	//
 	// For more details see
 	//    Multiparty Homomorphic Encryption: From Theory to Practice (<https://eprint.iacr.org/2020/304>)
+	// The protocol itself is implemented by the dbfv/pir package.
 
 	l := log.New(os.Stderr, "", 0)
 
-	// $go run main.go arg1 arg2
+	// $go run main.go arg1
 	// arg1: number of parties
-	// arg2: number of Go routines
 	// MinDelta number of parties for n=8192: 512 parties (this is a memory intensive process)
 
 	N := 3 // Default number of parties
@@ -91,12 +82,6 @@ func main() {
 		check(err)
 	}
 
-	NGoRoutine := 1 // Default number of Go routines
-	if len(os.Args[1:]) >= 2 {
-		NGoRoutine, err = strconv.Atoi(os.Args[2])
-		check(err)
-	}
-
 	// Index of the ciphertext to retrieve.
 	queryIndex := 2
 
@@ -136,21 +121,6 @@ func main() {
 	encoder := bfv.NewEncoder(params)
 	l.Println("> Memory alloc Phase")
 	encInputs := make([]*bfv.Ciphertext, N)
-	plainMask := make([]*bfv.PlaintextMul, N)
-
-	// Ciphertexts to be retrieved
-	for i := range encInputs {
-		encInputs[i] = bfv.NewCiphertext(params, 1)
-	}
-
-	// Plaintext masks: plainmask[i] = encode([0, ..., 0, 1_i, 0, ..., 0])
-	// (zero with a 1 at the i-th position).
-	for i := range plainMask {
-		maskCoeffs := make([]uint64, params.N())
-		maskCoeffs[i] = 1
-		plainMask[i] = bfv.NewPlaintextMul(params)
-		encoder.EncodeUintMul(maskCoeffs, plainMask[i])
-	}
 
 	// Ciphertexts encrypted under CKG and stored in the cloud
 	l.Println("> Encrypt Phase")
@@ -159,7 +129,7 @@ func main() {
 	elapsedEncryptParty := runTimedParty(func() {
 		for i, pi := range P {
 			encoder.EncodeUint(pi.input, pt)
-			encryptor.Encrypt(pt, encInputs[i])
+			encInputs[i] = encryptor.EncryptNew(pt)
 		}
 	}, N)
 
@@ -167,59 +137,58 @@ func main() {
 	l.Printf("\tdone (cloud: %s, party: %s)\n", elapsedEncryptCloud, elapsedEncryptParty)
 
 	// Request phase
-	encQuery := genquery(params, queryIndex, encoder, encryptor)
+	server := pir.NewServer(params, rlwe.EvaluationKey{Rlk: rlk, Rtks: rtk}, encInputs)
+
+	query := genquery(params, queryIndex, server.NbRows(), pk)
 
-	result := requestphase(params, queryIndex, NGoRoutine, encQuery, encInputs, plainMask, rlk, rtk)
+	result := requestphase(server, query)
 
-	// Collective (partial) decryption (key switch)
-	encOut := cksphase(params, P, result)
+	// Key pair of the external party
+	skOut, pkOut := bfv.NewKeyGenerator(params).GenKeyPair()
+
+	// Collective decryption to the external party
+	cdp, cdCombined := cksphase(params, P, pkOut, result)
 
 	l.Println("> Result:")
 
 	// Decryption by the external party
-	decryptor := bfv.NewDecryptor(params, P[0].sk)
-	ptres := bfv.NewPlaintext(params)
+	var res []uint64
 	elapsedDecParty := runTimed(func() {
-		decryptor.Decrypt(encOut, ptres)
+		res = cdp.DecodeUintNew(skOut, result, cdCombined)
 	})
 
-	res := encoder.DecodeUintNew(ptres)
-
 	l.Printf("\t%v\n", res[:16])
 	l.Printf("> Finished (total cloud: %s, total party: %s)\n",
-		elapsedCKGCloud+elapsedRKGCloud+elapsedRTGCloud+elapsedEncryptCloud+elapsedRequestCloudCPU+elapsedCKSCloud,
+		elapsedCKGCloud+elapsedRKGCloud+elapsedRTGCloud+elapsedEncryptCloud+elapsedRequestCloud+elapsedCKSCloud,
 		elapsedCKGParty+elapsedRKGParty+elapsedRTGParty+elapsedEncryptParty+elapsedRequestParty+elapsedPCKSParty+elapsedDecParty)
 }
 
-func cksphase(params bfv.Parameters, P []*party, result *bfv.Ciphertext) *bfv.Ciphertext {
+func cksphase(params bfv.Parameters, P []*party, pkOut *rlwe.PublicKey, result *bfv.Ciphertext) (cdp *dbfv.CollectiveDecryptProtocol, cdCombined *drlwe.CollectiveDecryptShare) {
 	l := log.New(os.Stderr, "", 0)
 
 	l.Println("> CKS Phase")
 
-	cks := dbfv.NewCKSProtocol(params, 3.19) // Collective public-key re-encryption
+	cdp = dbfv.NewCollectiveDecryptProtocol(params, pkOut, 3.19) // Collective public-key re-encryption
 
 	for _, pi := range P {
-		pi.cksShare = cks.AllocateShare()
+		pi.cdShare = cdp.AllocateShare()
 	}
 
-	zero := bfv.NewSecretKey(params)
-	cksCombined := cks.AllocateShare()
+	cdCombined = cdp.AllocateShare()
 	elapsedPCKSParty = runTimedParty(func() {
-		for _, pi := range P[1:] {
-			cks.GenShare(pi.sk, zero, result.Value[1], pi.cksShare)
+		for _, pi := range P {
+			cdp.GenShare(pi.sk, result, pi.cdShare)
 		}
-	}, len(P)-1)
+	}, len(P))
 
-	encOut := bfv.NewCiphertext(params, 1)
 	elapsedCKSCloud = runTimed(func() {
 		for _, pi := range P {
-			cks.AggregateShare(pi.cksShare, cksCombined, cksCombined)
+			cdp.AggregateShare(pi.cdShare, cdCombined, cdCombined)
 		}
-		cks.KeySwitch(result, cksCombined, encOut)
 	})
 	l.Printf("\tdone (cloud: %s, party: %s)\n", elapsedCKSCloud, elapsedPCKSParty)
 
-	return encOut
+	return
 }
 
 func genparties(params bfv.Parameters, N int) []*party {
@@ -336,7 +305,7 @@ func rtkphase(params bfv.Parameters, crs utils.PRNG, P []*party) *rlwe.RotationK
 		pi.rtgShare = rtg.AllocateShare()
 	}
 
-	galEls := params.GaloisElementsForRowInnerSum()
+	galEls := pir.GaloisElements(params)
 	rotKeySet := bfv.NewRotationKeySet(params, galEls)
 
 	for _, galEl := range galEls {
@@ -363,95 +332,33 @@ func rtkphase(params bfv.Parameters, crs utils.PRNG, P []*party) *rlwe.RotationK
 	return rotKeySet
 }
 
-func genquery(params bfv.Parameters, queryIndex int, encoder bfv.Encoder, encryptor bfv.Encryptor) *bfv.Ciphertext {
+func genquery(params bfv.Parameters, queryIndex, nbRows int, pk *rlwe.PublicKey) *pir.Query {
 	// Query ciphertext
-	queryCoeffs := make([]uint64, params.N())
-	queryCoeffs[queryIndex] = 1
-	query := bfv.NewPlaintext(params)
-	var encQuery *bfv.Ciphertext
+	var query *pir.Query
+	var err error
 	elapsedRequestParty += runTimed(func() {
-		encoder.EncodeUint(queryCoeffs, query)
-		encQuery = encryptor.EncryptNew(query)
+		query, err = pir.NewClient(params, pk).GenQuery(queryIndex, nbRows)
 	})
+	check(err)
 
-	return encQuery
+	return query
 }
 
-func requestphase(params bfv.Parameters, queryIndex, NGoRoutine int, encQuery *bfv.Ciphertext, encInputs []*bfv.Ciphertext, plainMask []*bfv.PlaintextMul, rlk *rlwe.RelinearizationKey, rtk *rlwe.RotationKeySet) *bfv.Ciphertext {
+func requestphase(server *pir.Server, query *pir.Query) *bfv.Ciphertext {
 
 	l := log.New(os.Stderr, "", 0)
 
 	l.Println("> Request Phase")
 
-	// Buffer for the intermediate computation done by the cloud
-	encPartial := make([]*bfv.Ciphertext, len(encInputs))
-	for i := range encPartial {
-		encPartial[i] = bfv.NewCiphertext(params, 2)
-	}
-
-	evaluator := bfv.NewEvaluator(params, rlwe.EvaluationKey{Rlk: rlk, Rtks: rtk})
-
-	// Split the task among the Go routines
-	tasks := make(chan *maskTask)
-	workers := &sync.WaitGroup{}
-	workers.Add(NGoRoutine)
-	for i := 1; i <= NGoRoutine; i++ {
-		go func(i int) {
-			evaluator := evaluator.ShallowCopy() // creates a shallow evaluator copy for this goroutine
-			tmp := bfv.NewCiphertext(params, 1)
-			for task := range tasks {
-				task.elapsedmaskTask = runTimed(func() {
-					// 1) Multiplication of the query with the plaintext mask
-					evaluator.Mul(task.query, task.mask, tmp)
-					// 2) Inner sum (populate all the slots with the sum of all the slots)
-					evaluator.InnerSum(tmp, tmp)
-					// 3) Multiplication of 2) with the i-th ciphertext stored in the cloud
-					evaluator.Mul(tmp, task.row, task.res)
-				})
-			}
-			//l.Println("\t evaluator", i, "down")
-			workers.Done()
-		}(i)
-		//l.Println("\t evaluator", i, "started")
-	}
-
-	taskList := make([]*maskTask, 0)
-
+	var result *bfv.Ciphertext
+	var err error
 	elapsedRequestCloud += runTimed(func() {
-		for i := range encInputs {
-			task := &maskTask{
-				query: encQuery,
-				mask:  plainMask[i],
-				row:   encInputs[i],
-				res:   encPartial[i],
-			}
-			taskList = append(taskList, task)
-			tasks <- task
-		}
-		close(tasks)
-		workers.Wait()
+		result, err = server.AnswerNew(query)
 	})
+	check(err)
 
-	for _, t := range taskList {
-		elapsedRequestCloudCPU += t.elapsedmaskTask
-	}
-
-	resultDeg2 := bfv.NewCiphertext(params, 2)
-	result := bfv.NewCiphertext(params, 1)
-
-	// Summation of all the partial result among the different Go routines
-	finalAddDuration := runTimed(func() {
-		for i := 0; i < len(encInputs); i++ {
-			evaluator.Add(resultDeg2, encPartial[i], resultDeg2)
-		}
-		evaluator.Relinearize(resultDeg2, result)
-	})
-
-	elapsedRequestCloud += finalAddDuration
-	elapsedRequestCloudCPU += finalAddDuration
-
-	l.Printf("\tdone (cloud: %s/%s, party: %s)\n",
-		elapsedRequestCloud, elapsedRequestCloudCPU, elapsedRequestParty)
+	l.Printf("\tdone (cloud: %s, party: %s)\n",
+		elapsedRequestCloud, elapsedRequestParty)
 
 	return result
 }
@@ -1,14 +1,16 @@
 package main
 
 import (
+	"fmt"
 	"log"
+	"math"
 	"os"
 	"strconv"
-	"sync"
 	"time"
 
 	"github.com/tuneinsight/lattigo/v3/bfv"
 	"github.com/tuneinsight/lattigo/v3/dbfv"
+	"github.com/tuneinsight/lattigo/v3/dbfv/psi"
 	"github.com/tuneinsight/lattigo/v3/drlwe"
 	"github.com/tuneinsight/lattigo/v3/rlwe"
 	"github.com/tuneinsight/lattigo/v3/utils"
@@ -39,16 +41,9 @@ type party struct {
 	ckgShare    *drlwe.CKGShare
 	rkgShareOne *drlwe.RKGShare
 	rkgShareTwo *drlwe.RKGShare
-	pcksShare   *drlwe.PCKSShare
+	cdShare     *drlwe.CollectiveDecryptShare
 
-	input []uint64
-}
-type multTask struct {
-	wg              *sync.WaitGroup
-	op1             *bfv.Ciphertext
-	op2             *bfv.Ciphertext
-	res             *bfv.Ciphertext
-	elapsedmultTask time.Duration
+	input [][]byte
 }
 
 var elapsedEncryptParty time.Duration
@@ -59,19 +54,18 @@ var elapsedRKGCloud time.Duration
 var elapsedRKGParty time.Duration
 var elapsedPCKSCloud time.Duration
 var elapsedPCKSParty time.Duration
-var elapsedEvalCloudCPU time.Duration
 var elapsedEvalCloud time.Duration
 var elapsedEvalParty time.Duration
 
 func main() {
 	// For more details about the PSI example see
 	//     Multiparty Homomorphic Encryption: From Theory to Practice (<https://eprint.iacr.org/2020/304>)
+	// The protocol itself is implemented by the dbfv/psi package.
 
 	l := log.New(os.Stderr, "", 0)
 
-	// $go run main.go arg1 arg2
+	// $go run main.go arg1
 	// arg1: number of parties
-	// arg2: number of Go routines
 
 	// Largest for n=8192: 512 parties
 	N := 8 // Default number of parties
@@ -81,12 +75,6 @@ func main() {
 		check(err)
 	}
 
-	NGoRoutine := 1 // Default number of Go routines
-	if len(os.Args[1:]) >= 2 {
-		NGoRoutine, err = strconv.Atoi(os.Args[2])
-		check(err)
-	}
-
 	// Creating encryption parameters from a default params with logN=14, logQP=438 with a plaintext modulus T=65537
 	paramsDef := bfv.PN14QP438
 	paramsDef.T = 65537
@@ -100,7 +88,9 @@ func main() {
 		panic(err)
 	}
 
-	encoder := bfv.NewEncoder(params)
+	// Hash-to-slot function shared by the parties
+	hasher, err := psi.NewHasher(params, []byte{'l', 'a', 't', 't', 'i', 'g', 'o'})
+	check(err)
 
 	// Target private and public keys
 	tsk, tpk := bfv.NewKeyGenerator(params).GenKeyPair()
@@ -109,7 +99,7 @@ func main() {
 	P := genparties(params, N)
 
 	// Inputs & expected result
-	expRes := genInputs(params, P)
+	expRes := genInputs(hasher, P)
 
 	// 1) Collective public key generation
 	pk := ckgphase(params, crs, P)
@@ -117,31 +107,30 @@ func main() {
 	// 2) Collective relinearization key generation
 	rlk := rkgphase(params, crs, P)
 
-	l.Printf("\tdone (cloud: %s, party: %s)\n",
-		elapsedRKGCloud, elapsedRKGParty)
 	l.Printf("\tSetup done (cloud: %s, party: %s)\n",
 		elapsedRKGCloud+elapsedCKGCloud, elapsedRKGParty+elapsedCKGParty)
 
-	encInputs := encPhase(params, P, pk, encoder)
+	encInputs := encPhase(params, hasher, P, pk)
 
-	encRes := evalPhase(params, NGoRoutine, encInputs, rlk)
+	encRes := evalPhase(params, encInputs, rlk)
 
-	encOut := pcksPhase(params, tpk, encRes, P)
+	cdp, cdCombined := pcksPhase(params, tpk, encRes, P)
 
 	// Decrypt the result with the target secret key
 	l.Println("> Result:")
-	decryptor := bfv.NewDecryptor(params, tsk)
-	ptres := bfv.NewPlaintext(params)
+	var res [][]byte
 	elapsedDecParty := runTimed(func() {
-		decryptor.Decrypt(encOut, ptres)
+		res = hasher.Filter(P[0].input, cdp.DecodeUintNew(tsk, encRes, cdCombined))
 	})
 
 	// Check the result
-	res := encoder.DecodeUintNew(ptres)
-	l.Printf("\t%v\n", res[:16])
+	l.Printf("\t%d items in the intersection\n", len(res))
+	if len(res) != len(expRes) {
+		l.Println("\tincorrect")
+		return
+	}
 	for i := range expRes {
-		if expRes[i] != res[i] {
-			//l.Printf("\t%v\n", expRes)
+		if string(expRes[i]) != string(res[i]) {
 			l.Println("\tincorrect")
 			return
 		}
@@ -153,24 +142,19 @@ func main() {
 
 }
 
-func encPhase(params bfv.Parameters, P []*party, pk *rlwe.PublicKey, encoder bfv.Encoder) (encInputs []*bfv.Ciphertext) {
+func encPhase(params bfv.Parameters, hasher *psi.Hasher, P []*party, pk *rlwe.PublicKey) (encInputs []*bfv.Ciphertext) {
 
 	l := log.New(os.Stderr, "", 0)
 
 	encInputs = make([]*bfv.Ciphertext, len(P))
-	for i := range encInputs {
-		encInputs[i] = bfv.NewCiphertext(params, 1)
-	}
 
-	// Each party encrypts its input vector
+	// Each party hashes its set to the slots and encrypts it
 	l.Println("> Encrypt Phase")
-	encryptor := bfv.NewEncryptor(params, pk)
+	psiParty := psi.NewParty(params, hasher, pk)
 
-	pt := bfv.NewPlaintext(params)
 	elapsedEncryptParty = runTimedParty(func() {
 		for i, pi := range P {
-			encoder.EncodeUint(pi.input, pt)
-			encryptor.Encrypt(pt, encInputs[i])
+			encInputs[i] = psiParty.EncryptSetNew(pi.input)
 		}
 	}, len(P))
 
@@ -180,73 +164,21 @@ func encPhase(params bfv.Parameters, P []*party, pk *rlwe.PublicKey, encoder bfv
 	return
 }
 
-func evalPhase(params bfv.Parameters, NGoRoutine int, encInputs []*bfv.Ciphertext, rlk *rlwe.RelinearizationKey) (encRes *bfv.Ciphertext) {
+func evalPhase(params bfv.Parameters, encInputs []*bfv.Ciphertext, rlk *rlwe.RelinearizationKey) (encRes *bfv.Ciphertext) {
 
 	l := log.New(os.Stderr, "", 0)
 
-	encLvls := make([][]*bfv.Ciphertext, 0)
-	encLvls = append(encLvls, encInputs)
-	for nLvl := len(encInputs) / 2; nLvl > 0; nLvl = nLvl >> 1 {
-		encLvl := make([]*bfv.Ciphertext, nLvl)
-		for i := range encLvl {
-			encLvl[i] = bfv.NewCiphertext(params, 2)
-		}
-		encLvls = append(encLvls, encLvl)
-	}
-	encRes = encLvls[len(encLvls)-1][0]
-
-	evaluator := bfv.NewEvaluator(params, rlwe.EvaluationKey{Rlk: rlk, Rtks: nil})
-	// Split the task among the Go routines
-	tasks := make(chan *multTask)
-	workers := &sync.WaitGroup{}
-	workers.Add(NGoRoutine)
-	//l.Println("> Spawning", NGoRoutine, "evaluator goroutine")
-	for i := 1; i <= NGoRoutine; i++ {
-		go func(i int) {
-			evaluator := evaluator.ShallowCopy() // creates a shallow evaluator copy for this goroutine
-			for task := range tasks {
-				task.elapsedmultTask = runTimed(func() {
-					// 1) Multiplication of two input vectors
-					evaluator.Mul(task.op1, task.op2, task.res)
-					// 2) Relinearization
-					evaluator.Relinearize(task.res, task.res)
-				})
-				task.wg.Done()
-			}
-			//l.Println("\t evaluator", i, "down")
-			workers.Done()
-		}(i)
-		//l.Println("\t evaluator", i, "started")
-	}
-
-	// Start the tasks
-	taskList := make([]*multTask, 0)
 	l.Println("> Eval Phase")
+	server := psi.NewServer(params, rlk)
+
+	var err error
 	elapsedEvalCloud = runTimed(func() {
-		for i, lvl := range encLvls[:len(encLvls)-1] {
-			nextLvl := encLvls[i+1]
-			l.Println("\tlevel", i, len(lvl), "->", len(nextLvl))
-			wg := &sync.WaitGroup{}
-			wg.Add(len(nextLvl))
-			for j, nextLvlCt := range nextLvl {
-				task := multTask{wg, lvl[2*j], lvl[2*j+1], nextLvlCt, 0}
-				taskList = append(taskList, &task)
-				tasks <- &task
-			}
-			wg.Wait()
-		}
+		encRes, err = server.IntersectNew(encInputs)
 	})
-	elapsedEvalCloudCPU = time.Duration(0)
-	for _, t := range taskList {
-		elapsedEvalCloudCPU += t.elapsedmultTask
-	}
-	elapsedEvalParty = time.Duration(0)
-	l.Printf("\tdone (cloud: %s (wall: %s), party: %s)\n",
-		elapsedEvalCloudCPU, elapsedEvalCloud, elapsedEvalParty)
+	check(err)
 
-	//l.Println("> Shutting down workers")
-	close(tasks)
-	workers.Wait()
+	elapsedEvalParty = time.Duration(0)
+	l.Printf("\tdone (cloud: %s, party: %s)\n", elapsedEvalCloud, elapsedEvalParty)
 
 	return
 }
@@ -265,61 +197,59 @@ func genparties(params bfv.Parameters, N int) []*party {
 	return P
 }
 
-func genInputs(params bfv.Parameters, P []*party) (expRes []uint64) {
-
-	expRes = make([]uint64, params.N())
-	for i := range expRes {
-		expRes[i] = 1
-	}
+func genInputs(hasher *psi.Hasher, P []*party) (expRes [][]byte) {
 
+	// Each party holds a subset of 1024 items, such that each item is in the intersection with probability about 1/2
 	for _, pi := range P {
-
-		pi.input = make([]uint64, params.N())
-		for i := range pi.input {
-			if utils.RandFloat64(0, 1) > 0.3 || i == 4 {
-				pi.input[i] = 1
+		for j := 0; j < 1024; j++ {
+			if utils.RandFloat64(0, 1) < math.Pow(0.5, 1/float64(len(P))) || j == 4 {
+				pi.input = append(pi.input, []byte(fmt.Sprintf("item-%d", j)))
 			}
-			expRes[i] *= pi.input[i]
 		}
+	}
 
+	// The expected result is the set of the items of the first party whose slot is occupied in every set
+	for _, item := range P[0].input {
+		in := true
+		for _, pi := range P[1:] {
+			in = in && hasher.Encode(pi.input)[hasher.Slot(item)] == 1
+		}
+		if in {
+			expRes = append(expRes, item)
+		}
 	}
 
 	return
 }
 
-func pcksPhase(params bfv.Parameters, tpk *rlwe.PublicKey, encRes *bfv.Ciphertext, P []*party) (encOut *bfv.Ciphertext) {
+func pcksPhase(params bfv.Parameters, tpk *rlwe.PublicKey, encRes *bfv.Ciphertext, P []*party) (cdp *dbfv.CollectiveDecryptProtocol, cdCombined *drlwe.CollectiveDecryptShare) {
 
 	l := log.New(os.Stderr, "", 0)
 
-	// Collective key switching from the collective secret key to
-	// the target public key
+	// Collective decryption of the result to the target public key
 
-	pcks := dbfv.NewPCKSProtocol(params, 3.19)
+	cdp = dbfv.NewCollectiveDecryptProtocol(params, tpk, 3.19)
 
 	for _, pi := range P {
-		pi.pcksShare = pcks.AllocateShare()
+		pi.cdShare = cdp.AllocateShare()
 	}
 
 	l.Println("> PCKS Phase")
 	elapsedPCKSParty = runTimedParty(func() {
 		for _, pi := range P {
-			pcks.GenShare(pi.sk, tpk, encRes.Value[1], pi.pcksShare)
+			cdp.GenShare(pi.sk, encRes, pi.cdShare)
 		}
 	}, len(P))
 
-	pcksCombined := pcks.AllocateShare()
-	encOut = bfv.NewCiphertext(params, 1)
+	cdCombined = cdp.AllocateShare()
 	elapsedPCKSCloud = runTimed(func() {
 		for _, pi := range P {
-			pcks.AggregateShare(pi.pcksShare, pcksCombined, pcksCombined)
+			cdp.AggregateShare(pi.cdShare, cdCombined, cdCombined)
 		}
-		pcks.KeySwitch(encRes, pcksCombined, encOut)
-
 	})
 	l.Printf("\tdone (cloud: %s, party: %s)\n", elapsedPCKSCloud, elapsedPCKSParty)
 
 	return
-
 }
 
 func rkgphase(params bfv.Parameters, crs utils.PRNG, P []*party) *rlwe.RelinearizationKey {
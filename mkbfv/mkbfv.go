@@ -0,0 +1,229 @@
+// Package mkbfv implements a multi-key variant of the BFV scheme. Each party encrypts under its own secret key with
+// the regular bfv package, and the ciphertexts of different parties can be combined homomorphically into multi-key
+// ciphertexts which are extended with one component per involved party. The relinearization and the rotations use the
+// individual evaluation keys of the parties (see the mkrlwe package) and the decryption is a distributed protocol.
+package mkbfv
+
+import (
+	"math/big"
+
+	"github.com/tuneinsight/lattigo/v3/bfv"
+	"github.com/tuneinsight/lattigo/v3/drlwe"
+	"github.com/tuneinsight/lattigo/v3/mkrlwe"
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+// PartyID is the identifier of a party in the multi-key scheme.
+type PartyID = mkrlwe.PartyID
+
+// Ciphertext is a multi-key BFV ciphertext.
+type Ciphertext struct {
+	*mkrlwe.Ciphertext
+}
+
+// NewCiphertext allocates a new multi-key ciphertext for the given parties.
+func NewCiphertext(params bfv.Parameters, parties []PartyID) *Ciphertext {
+	return &Ciphertext{mkrlwe.NewCiphertext(params.Parameters, parties, params.MaxLevel())}
+}
+
+// NewCiphertextFromBFV creates a new multi-key ciphertext from a degree-1 bfv.Ciphertext encrypted under the secret key of party.
+func NewCiphertextFromBFV(party PartyID, ct *bfv.Ciphertext) *Ciphertext {
+	return &Ciphertext{mkrlwe.NewCiphertextFromRLWE(party, ct.Ciphertext)}
+}
+
+// CopyNew creates a deep copy of the ciphertext.
+func (ct *Ciphertext) CopyNew() *Ciphertext {
+	return &Ciphertext{ct.Ciphertext.CopyNew()}
+}
+
+// Evaluator implements the homomorphic operations on multi-key BFV ciphertexts.
+type Evaluator struct {
+	*mkrlwe.Evaluator
+	params bfv.Parameters
+
+	ringQ               *ring.Ring
+	ringQMul            *ring.Ring
+	basisExtenderQ1toQ2 *ring.BasisExtender
+	pHalf               *big.Int
+
+	poolQ       []*ring.Poly
+	poolQMul    []*ring.Poly
+	tensorQ     [][]*ring.Poly
+	tensorQMul  [][]*ring.Poly
+	tensorParty int
+}
+
+// NewEvaluator creates a new Evaluator from the evaluation keys of the parties.
+func NewEvaluator(params bfv.Parameters, keys map[PartyID]*mkrlwe.EvaluationKey) *Evaluator {
+	return newEvaluator(params, mkrlwe.NewEvaluator(params.Parameters, keys))
+}
+
+func newEvaluator(params bfv.Parameters, eval *mkrlwe.Evaluator) *Evaluator {
+	ringQ, ringQMul := params.RingQ(), params.RingQMul()
+	return &Evaluator{
+		Evaluator:           eval,
+		params:              params,
+		ringQ:               ringQ,
+		ringQMul:            ringQMul,
+		basisExtenderQ1toQ2: ring.NewBasisExtender(ringQ, ringQMul),
+		pHalf:               new(big.Int).Rsh(ringQMul.ModulusBigint, 1),
+		tensorParty:         -1,
+	}
+}
+
+// ShallowCopy creates a shallow copy of Evaluator in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// Evaluator can be used concurrently.
+func (eval *Evaluator) ShallowCopy() *Evaluator {
+	return newEvaluator(eval.params, eval.Evaluator.ShallowCopy())
+}
+
+// Add adds ct0 and ct1 and writes the result on ctOut.
+func (eval *Evaluator) Add(ct0, ct1, ctOut *Ciphertext) {
+	eval.Evaluator.Add(ct0.Ciphertext, ct1.Ciphertext, ctOut.Ciphertext)
+}
+
+// Sub subtracts ct1 from ct0 and writes the result on ctOut.
+func (eval *Evaluator) Sub(ct0, ct1, ctOut *Ciphertext) {
+	eval.Evaluator.Sub(ct0.Ciphertext, ct1.Ciphertext, ctOut.Ciphertext)
+}
+
+// Neg negates ct and writes the result on ctOut.
+func (eval *Evaluator) Neg(ct, ctOut *Ciphertext) {
+	eval.Evaluator.Neg(ct.Ciphertext, ctOut.Ciphertext)
+}
+
+// MulRelin multiplies ct0 by ct1, relinearizes the result with the relinearization keys of the involved parties
+// and writes it on ctOut. As in the bfv package, the tensor product is computed in the extended basis QQMul and
+// then scaled by t/Q.
+func (eval *Evaluator) MulRelin(ct0, ct1, ctOut *Ciphertext) {
+
+	ringQ, ringQMul := eval.ringQ, eval.ringQMul
+	levelQ, levelQMul := len(ringQ.Modulus)-1, len(ringQMul.Modulus)-1
+
+	parties := mkrlwe.MergeParties(ct0.Parties, ct1.Parties)
+	k := len(parties)
+
+	eval.allocateTensor(k)
+
+	tensorQ := make([][]*ring.Poly, k+1)
+	tensorQMul := make([][]*ring.Poly, k+1)
+	for i := range tensorQ {
+		tensorQ[i], tensorQMul[i] = eval.tensorQ[i][:k+1], eval.tensorQMul[i][:k+1]
+		for j := range tensorQ[i] {
+			tensorQ[i][j].Zero()
+			tensorQMul[i][j].Zero()
+		}
+	}
+
+	// Extends the basis of the ciphertexts from Q to QQMul and transforms them to the NTT domain
+	c0Q, c0QMul := eval.poolQ[:len(ct0.Value)], eval.poolQMul[:len(ct0.Value)]
+	c1Q, c1QMul := eval.poolQ[k+1:k+1+len(ct1.Value)], eval.poolQMul[k+1:k+1+len(ct1.Value)]
+	eval.modUpAndNTT(ct0.Value, c0Q, c0QMul)
+	eval.modUpAndNTT(ct1.Value, c1Q, c1QMul)
+
+	for i := range c0Q {
+		ringQ.MForm(c0Q[i], c0Q[i])
+		ringQMul.MForm(c0QMul[i], c0QMul[i])
+	}
+
+	index0, index1 := mkrlwe.TensorIndex(ct0.Parties, parties), mkrlwe.TensorIndex(ct1.Parties, parties)
+
+	for i := range c0Q {
+		for j := range c1Q {
+			ringQ.MulCoeffsMontgomeryAndAdd(c0Q[i], c1Q[j], tensorQ[index0[i]][index1[j]])
+			ringQMul.MulCoeffsMontgomeryAndAdd(c0QMul[i], c1QMul[j], tensorQMul[index0[i]][index1[j]])
+		}
+	}
+
+	// Scales the tensor by t/Q and reduces its basis from QQMul to Q
+	for i := range tensorQ {
+		for j := range tensorQ[i] {
+
+			tQ, tQMul := tensorQ[i][j], tensorQMul[i][j]
+
+			ringQ.InvNTT(tQ, tQ)
+			ringQMul.InvNTT(tQMul, tQMul)
+
+			eval.basisExtenderQ1toQ2.ModDownQPtoP(levelQ, levelQMul, tQ, tQMul, tQMul)
+
+			ringQMul.AddScalarBigint(tQMul, eval.pHalf, tQMul)
+			eval.basisExtenderQ1toQ2.ModUpPtoQ(levelQMul, levelQ, tQMul, tQ)
+			ringQ.SubScalarBigint(tQ, eval.pHalf, tQ)
+
+			ringQ.MulScalar(tQ, eval.params.T(), tQ)
+			tQ.IsNTT = false
+		}
+	}
+
+	eval.RelinearizeTensor(levelQ, tensorQ, parties, ctOut.Ciphertext)
+}
+
+func (eval *Evaluator) modUpAndNTT(value, cQ, cQMul []*ring.Poly) {
+	levelQ, levelQMul := len(eval.ringQ.Modulus)-1, len(eval.ringQMul.Modulus)-1
+	for i := range value {
+		eval.basisExtenderQ1toQ2.ModUpQtoP(levelQ, levelQMul, value[i], cQMul[i])
+		eval.ringQ.NTT(value[i], cQ[i])
+		eval.ringQMul.NTT(cQMul[i], cQMul[i])
+	}
+}
+
+// RotateColumns rotates the columns of ct by k positions to the left, using the rotation keys of the involved parties,
+// and writes the result on ctOut.
+func (eval *Evaluator) RotateColumns(ct *Ciphertext, k int, ctOut *Ciphertext) {
+	eval.Automorphism(ct.Ciphertext, eval.params.GaloisElementForColumnRotationBy(k), ctOut.Ciphertext)
+}
+
+// RotateRows swaps the rows of ct, using the row-rotation keys of the involved parties, and writes the result on ctOut.
+func (eval *Evaluator) RotateRows(ct *Ciphertext, ctOut *Ciphertext) {
+	eval.Automorphism(ct.Ciphertext, eval.params.GaloisElementForRowRotation(), ctOut.Ciphertext)
+}
+
+// allocateTensor ensures that the tensor buffers can hold the tensor product on k parties.
+func (eval *Evaluator) allocateTensor(k int) {
+	if eval.tensorParty >= k {
+		return
+	}
+	eval.tensorParty = k
+	eval.poolQ, eval.poolQMul = make([]*ring.Poly, 2*(k+1)), make([]*ring.Poly, 2*(k+1))
+	for i := range eval.poolQ {
+		eval.poolQ[i], eval.poolQMul[i] = eval.ringQ.NewPoly(), eval.ringQMul.NewPoly()
+	}
+	eval.tensorQ, eval.tensorQMul = make([][]*ring.Poly, k+1), make([][]*ring.Poly, k+1)
+	for i := range eval.tensorQ {
+		eval.tensorQ[i], eval.tensorQMul[i] = make([]*ring.Poly, k+1), make([]*ring.Poly, k+1)
+		for j := range eval.tensorQ[i] {
+			eval.tensorQ[i][j], eval.tensorQMul[i][j] = eval.ringQ.NewPoly(), eval.ringQMul.NewPoly()
+		}
+	}
+}
+
+// DecryptionProtocol is the distributed decryption protocol of multi-key BFV ciphertexts.
+type DecryptionProtocol struct {
+	*mkrlwe.DecryptionProtocol
+}
+
+// NewDecryptionProtocol creates a new DecryptionProtocol, where sigmaSmudging is the standard deviation of the
+// smudging noise added to the decryption shares.
+func NewDecryptionProtocol(params bfv.Parameters, sigmaSmudging float64) *DecryptionProtocol {
+	return &DecryptionProtocol{mkrlwe.NewDecryptionProtocol(params.Parameters, sigmaSmudging)}
+}
+
+// ShallowCopy creates a shallow copy of DecryptionProtocol in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// DecryptionProtocol can be used concurrently.
+func (dec *DecryptionProtocol) ShallowCopy() *DecryptionProtocol {
+	return &DecryptionProtocol{dec.DecryptionProtocol.ShallowCopy()}
+}
+
+// GenShare generates the decryption share of party, with secret key sk, for the ciphertext ct.
+func (dec *DecryptionProtocol) GenShare(party PartyID, sk *rlwe.SecretKey, ct *Ciphertext, shareOut *drlwe.CKSShare) {
+	dec.DecryptionProtocol.GenShare(party, sk, ct.Ciphertext, shareOut)
+}
+
+// Decrypt combines the decryption shares of all the parties involved in ct and writes the plaintext on ptOut.
+// It returns an error if the share of an involved party is missing.
+func (dec *DecryptionProtocol) Decrypt(ct *Ciphertext, shares map[PartyID]*drlwe.CKSShare, ptOut *bfv.Plaintext) (err error) {
+	return dec.DecryptionProtocol.Decrypt(ct.Ciphertext, shares, ptOut.Value)
+}
@@ -0,0 +1,195 @@
+package mkbfv
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tuneinsight/lattigo/v3/bfv"
+	"github.com/tuneinsight/lattigo/v3/drlwe"
+	"github.com/tuneinsight/lattigo/v3/mkrlwe"
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+var nbParties = 3
+
+func testString(opname string, parties int, params bfv.Parameters) string {
+	return fmt.Sprintf("%s/LogN=%d/logQ=%d/parties=%d", opname, params.LogN(), params.LogQP(), parties)
+}
+
+type testContext struct {
+	params    bfv.Parameters
+	encoder   bfv.Encoder
+	parties   []PartyID
+	sks       map[PartyID]*rlwe.SecretKey
+	encryptor map[PartyID]bfv.Encryptor
+	evaluator *Evaluator
+	dec       *DecryptionProtocol
+}
+
+func TestMKBFV(t *testing.T) {
+
+	// The noise of the multi-key relinearization between nbParties parties does not leave
+	// enough room for the depth of testMulRelin with the parameters bfv.PN12QP109.
+	defaultParams := bfv.DefaultParams[1:]
+	if testing.Short() {
+		defaultParams = bfv.DefaultParams[1:2]
+	}
+
+	for _, p := range defaultParams {
+
+		params, err := bfv.NewParametersFromLiteral(p)
+		if err != nil {
+			panic(err)
+		}
+
+		tc := genTestContext(params)
+
+		for _, testSet := range []func(tc *testContext, t *testing.T){
+			testAdd,
+			testMulRelin,
+			testRotate,
+			testDecrypt,
+		} {
+			testSet(tc, t)
+		}
+	}
+}
+
+func genTestContext(params bfv.Parameters) (tc *testContext) {
+
+	tc = &testContext{
+		params:    params,
+		encoder:   bfv.NewEncoder(params),
+		sks:       make(map[PartyID]*rlwe.SecretKey),
+		encryptor: make(map[PartyID]bfv.Encryptor),
+		dec:       NewDecryptionProtocol(params, 3.2),
+	}
+
+	galEls := []uint64{params.GaloisElementForColumnRotationBy(1), params.GaloisElementForRowRotation()}
+	keys := make(map[PartyID]*mkrlwe.EvaluationKey)
+
+	for i := 0; i < nbParties; i++ {
+		id := PartyID(i + 1)
+		crs, _ := utils.NewKeyedPRNG([]byte{'t', 'e', 's', 't'})
+		kgen := mkrlwe.NewKeyGenerator(params.Parameters, crs)
+		tc.parties = append(tc.parties, id)
+		tc.sks[id] = kgen.GenSecretKey()
+		tc.encryptor[id] = bfv.NewEncryptor(params, tc.sks[id])
+		keys[id] = kgen.GenEvaluationKey(tc.sks[id], galEls)
+	}
+
+	tc.evaluator = NewEvaluator(params, keys)
+
+	return
+}
+
+func newTestVectors(tc *testContext, party PartyID) (coeffs []uint64, ct *Ciphertext) {
+	prng, _ := utils.NewPRNG()
+	coeffs = ring.NewUniformSampler(prng, tc.params.RingT()).ReadNew().Coeffs[0]
+	pt := bfv.NewPlaintext(tc.params)
+	tc.encoder.EncodeUint(coeffs, pt)
+	return coeffs, NewCiphertextFromBFV(party, tc.encryptor[party].EncryptNew(pt))
+}
+
+func decrypt(tc *testContext, ct *Ciphertext) []uint64 {
+	shares := make(map[PartyID]*drlwe.CKSShare)
+	for _, id := range ct.Parties {
+		shares[id] = tc.dec.AllocateShare(ct.Level())
+		tc.dec.GenShare(id, tc.sks[id], ct, shares[id])
+	}
+	pt := bfv.NewPlaintext(tc.params)
+	if err := tc.dec.Decrypt(ct, shares, pt); err != nil {
+		panic(err)
+	}
+	return tc.encoder.DecodeUintNew(pt)
+}
+
+func testAdd(tc *testContext, t *testing.T) {
+
+	t.Run(testString("Add", nbParties, tc.params), func(t *testing.T) {
+
+		ringT := tc.params.RingT()
+
+		want, ctSum := newTestVectors(tc, tc.parties[0])
+		for _, id := range tc.parties[1:] {
+			coeffs, ct := newTestVectors(tc, id)
+			for i := range want {
+				want[i] = ring.CRed(want[i]+coeffs[i], ringT.Modulus[0])
+			}
+			tc.evaluator.Add(ctSum, ct, ctSum)
+		}
+
+		require.Equal(t, tc.parties, ctSum.Parties)
+		require.True(t, utils.EqualSliceUint64(want, decrypt(tc, ctSum)))
+
+		coeffs, ct := newTestVectors(tc, tc.parties[1])
+		for i := range want {
+			want[i] = ring.CRed(want[i]+ringT.Modulus[0]-coeffs[i], ringT.Modulus[0])
+		}
+		ctOut := NewCiphertext(tc.params, nil)
+		tc.evaluator.Sub(ctSum, ct, ctOut)
+
+		require.Equal(t, tc.parties, ctOut.Parties)
+		require.True(t, utils.EqualSliceUint64(want, decrypt(tc, ctOut)))
+	})
+}
+
+func testMulRelin(tc *testContext, t *testing.T) {
+
+	t.Run(testString("MulRelin", nbParties, tc.params), func(t *testing.T) {
+
+		ringT := tc.params.RingT()
+
+		want, ct := newTestVectors(tc, tc.parties[0])
+		for _, id := range tc.parties[1:] {
+			coeffs, ct1 := newTestVectors(tc, id)
+			tc.evaluator.MulRelin(ct, ct1, ct)
+			ringT.MulCoeffs(&ring.Poly{Coeffs: [][]uint64{want}}, &ring.Poly{Coeffs: [][]uint64{coeffs}}, &ring.Poly{Coeffs: [][]uint64{want}})
+		}
+
+		require.Equal(t, tc.parties, ct.Parties)
+		require.True(t, utils.EqualSliceUint64(want, decrypt(tc, ct)))
+	})
+}
+
+func testRotate(tc *testContext, t *testing.T) {
+
+	t.Run(testString("RotateColumns", nbParties, tc.params), func(t *testing.T) {
+
+		ct := NewCiphertext(tc.params, nil)
+		want := make([]uint64, tc.params.N())
+		for _, id := range tc.parties {
+			coeffs, ct1 := newTestVectors(tc, id)
+			tc.evaluator.Add(ct, ct1, ct)
+			tc.params.RingT().Add(&ring.Poly{Coeffs: [][]uint64{want}}, &ring.Poly{Coeffs: [][]uint64{coeffs}}, &ring.Poly{Coeffs: [][]uint64{want}})
+		}
+
+		ctOut := NewCiphertext(tc.params, nil)
+		tc.evaluator.RotateColumns(ct, 1, ctOut)
+		require.True(t, utils.EqualSliceUint64(utils.RotateUint64Slots(want, 1), decrypt(tc, ctOut)))
+
+		tc.evaluator.RotateRows(ct, ct)
+		require.True(t, utils.EqualSliceUint64(append(want[tc.params.N()>>1:], want[:tc.params.N()>>1]...), decrypt(tc, ct)))
+	})
+}
+
+func testDecrypt(tc *testContext, t *testing.T) {
+
+	t.Run(testString("DecryptMissingShare", nbParties, tc.params), func(t *testing.T) {
+
+		_, ct0 := newTestVectors(tc, tc.parties[0])
+		_, ct1 := newTestVectors(tc, tc.parties[1])
+		tc.evaluator.Add(ct0, ct1, ct0)
+
+		share := tc.dec.AllocateShare(ct0.Level())
+		tc.dec.GenShare(tc.parties[0], tc.sks[tc.parties[0]], ct0, share)
+
+		err := tc.dec.Decrypt(ct0, map[PartyID]*drlwe.CKSShare{tc.parties[0]: share}, bfv.NewPlaintext(tc.params))
+		require.Error(t, err)
+
+		require.Panics(t, func() { tc.dec.GenShare(tc.parties[2], tc.sks[tc.parties[2]], ct0, share) })
+	})
+}
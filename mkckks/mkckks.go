@@ -0,0 +1,241 @@
+// Package mkckks implements a multi-key variant of the CKKS scheme. Each party encrypts under its own secret key with
+// the regular ckks package, and the ciphertexts of different parties can be combined homomorphically into multi-key
+// ciphertexts which are extended with one component per involved party. The relinearization and the rotations use the
+// individual evaluation keys of the parties (see the mkrlwe package) and the decryption is a distributed protocol.
+package mkckks
+
+import (
+	"errors"
+
+	"github.com/tuneinsight/lattigo/v3/ckks"
+	"github.com/tuneinsight/lattigo/v3/drlwe"
+	"github.com/tuneinsight/lattigo/v3/mkrlwe"
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// PartyID is the identifier of a party in the multi-key scheme.
+type PartyID = mkrlwe.PartyID
+
+// Ciphertext is a multi-key CKKS ciphertext.
+type Ciphertext struct {
+	*mkrlwe.Ciphertext
+	Scale float64
+}
+
+// NewCiphertext allocates a new multi-key ciphertext for the given parties at the given level and scale.
+func NewCiphertext(params ckks.Parameters, parties []PartyID, level int, scale float64) *Ciphertext {
+	ct := &Ciphertext{Ciphertext: mkrlwe.NewCiphertext(params.Parameters, parties, level), Scale: scale}
+	for _, pol := range ct.Value {
+		pol.IsNTT = true
+	}
+	return ct
+}
+
+// NewCiphertextFromCKKS creates a new multi-key ciphertext from a degree-1 ckks.Ciphertext encrypted under the secret key of party.
+func NewCiphertextFromCKKS(party PartyID, ct *ckks.Ciphertext) *Ciphertext {
+	return &Ciphertext{Ciphertext: mkrlwe.NewCiphertextFromRLWE(party, ct.Ciphertext), Scale: ct.Scale}
+}
+
+// CopyNew creates a deep copy of the ciphertext.
+func (ct *Ciphertext) CopyNew() *Ciphertext {
+	return &Ciphertext{Ciphertext: ct.Ciphertext.CopyNew(), Scale: ct.Scale}
+}
+
+// Evaluator implements the homomorphic operations on multi-key CKKS ciphertexts.
+type Evaluator struct {
+	*mkrlwe.Evaluator
+	params ckks.Parameters
+
+	poolQ  *ring.Poly
+	mForm  []*ring.Poly
+	tensor [][]*ring.Poly
+}
+
+// NewEvaluator creates a new Evaluator from the evaluation keys of the parties.
+func NewEvaluator(params ckks.Parameters, keys map[PartyID]*mkrlwe.EvaluationKey) *Evaluator {
+	return &Evaluator{
+		Evaluator: mkrlwe.NewEvaluator(params.Parameters, keys),
+		params:    params,
+		poolQ:     params.RingQ().NewPoly(),
+	}
+}
+
+// ShallowCopy creates a shallow copy of Evaluator in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// Evaluator can be used concurrently.
+func (eval *Evaluator) ShallowCopy() *Evaluator {
+	return &Evaluator{
+		Evaluator: eval.Evaluator.ShallowCopy(),
+		params:    eval.params,
+		poolQ:     eval.params.RingQ().NewPoly(),
+	}
+}
+
+// Add adds ct0 and ct1 and writes the result on ctOut. The two ciphertexts are expected to have the same scale.
+func (eval *Evaluator) Add(ct0, ct1, ctOut *Ciphertext) {
+	eval.Evaluator.Add(ct0.Ciphertext, ct1.Ciphertext, ctOut.Ciphertext)
+	ctOut.Scale = utils.MaxFloat64(ct0.Scale, ct1.Scale)
+}
+
+// Sub subtracts ct1 from ct0 and writes the result on ctOut. The two ciphertexts are expected to have the same scale.
+func (eval *Evaluator) Sub(ct0, ct1, ctOut *Ciphertext) {
+	eval.Evaluator.Sub(ct0.Ciphertext, ct1.Ciphertext, ctOut.Ciphertext)
+	ctOut.Scale = utils.MaxFloat64(ct0.Scale, ct1.Scale)
+}
+
+// Neg negates ct and writes the result on ctOut.
+func (eval *Evaluator) Neg(ct, ctOut *Ciphertext) {
+	eval.Evaluator.Neg(ct.Ciphertext, ctOut.Ciphertext)
+	ctOut.Scale = ct.Scale
+}
+
+// MulRelin multiplies ct0 by ct1, relinearizes the result with the relinearization keys of the involved parties
+// and writes it on ctOut. The scale of ctOut is the product of the scales of ct0 and ct1.
+func (eval *Evaluator) MulRelin(ct0, ct1, ctOut *Ciphertext) {
+
+	ringQ := eval.params.RingQ()
+	level := utils.MinInt(ct0.Level(), ct1.Level())
+	parties := mkrlwe.MergeParties(ct0.Parties, ct1.Parties)
+
+	eval.allocateTensor(len(parties))
+
+	for i := range eval.tensor[:len(parties)+1] {
+		for _, pol := range eval.tensor[i][:len(parties)+1] {
+			pol.Zero()
+			pol.IsNTT = true
+		}
+	}
+
+	index0, index1 := mkrlwe.TensorIndex(ct0.Parties, parties), mkrlwe.TensorIndex(ct1.Parties, parties)
+
+	for i := range ct0.Value {
+		ringQ.MFormLvl(level, ct0.Value[i], eval.mForm[i])
+	}
+
+	for i := range ct0.Value {
+		for j := range ct1.Value {
+			ringQ.MulCoeffsMontgomeryAndAddLvl(level, eval.mForm[i], ct1.Value[j], eval.tensor[index0[i]][index1[j]])
+		}
+	}
+
+	tensor := make([][]*ring.Poly, len(parties)+1)
+	for i := range tensor {
+		tensor[i] = eval.tensor[i][:len(parties)+1]
+	}
+
+	scale := ct0.Scale * ct1.Scale
+	eval.RelinearizeTensor(level, tensor, parties, ctOut.Ciphertext)
+	ctOut.Scale = scale
+}
+
+// Rescale divides ctIn by the last moduli of its modulus chain, as long as the scale of the result is
+// not smaller than minScale/2, and writes the result on ctOut, which must have the same parties as ctIn.
+func (eval *Evaluator) Rescale(ctIn *Ciphertext, minScale float64, ctOut *Ciphertext) (err error) {
+
+	ringQ := eval.params.RingQ()
+
+	if minScale <= 0 {
+		return errors.New("cannot Rescale: minScale is 0")
+	}
+
+	if ctIn.Scale == 0 {
+		return errors.New("cannot Rescale: ciphertext scale is 0")
+	}
+
+	if ctIn.Level() == 0 {
+		return errors.New("cannot Rescale: input Ciphertext already at level 0")
+	}
+
+	if len(ctOut.Value) != len(ctIn.Value) {
+		return errors.New("cannot Rescale: ctIn and ctOut must have the same parties")
+	}
+
+	level := ctIn.Level()
+	scale := ctIn.Scale
+
+	var nbRescales int
+	for level-nbRescales >= 0 && scale/float64(ringQ.Modulus[level-nbRescales]) >= minScale/2 {
+		scale /= float64(ringQ.Modulus[level-nbRescales])
+		nbRescales++
+	}
+
+	for i := range ctOut.Value {
+		if nbRescales > 0 {
+			ringQ.DivRoundByLastModulusManyNTTLvl(level, nbRescales, ctIn.Value[i], eval.poolQ, ctOut.Value[i])
+		} else {
+			ring.CopyValuesLvl(level, ctIn.Value[i], ctOut.Value[i])
+		}
+		ctOut.Value[i].Coeffs = ctOut.Value[i].Coeffs[:level+1-nbRescales]
+		ctOut.Value[i].IsNTT = true
+	}
+
+	ctOut.Parties = append([]PartyID(nil), ctIn.Parties...)
+	ctOut.Scale = scale
+
+	return nil
+}
+
+// Rotate rotates the slots of ct by k positions to the left, using the rotation keys of the involved parties,
+// and writes the result on ctOut.
+func (eval *Evaluator) Rotate(ct *Ciphertext, k int, ctOut *Ciphertext) {
+	eval.Automorphism(ct.Ciphertext, eval.params.GaloisElementForColumnRotationBy(k), ctOut.Ciphertext)
+	ctOut.Scale = ct.Scale
+}
+
+// Conjugate conjugates the slots of ct, using the conjugation keys of the involved parties, and writes the result on ctOut.
+func (eval *Evaluator) Conjugate(ct *Ciphertext, ctOut *Ciphertext) {
+	eval.Automorphism(ct.Ciphertext, eval.params.GaloisElementForRowRotation(), ctOut.Ciphertext)
+	ctOut.Scale = ct.Scale
+}
+
+// allocateTensor ensures that the tensor buffers can hold the tensor product on k parties.
+func (eval *Evaluator) allocateTensor(k int) {
+	if len(eval.tensor) > k {
+		return
+	}
+	ringQ := eval.params.RingQ()
+	eval.mForm = make([]*ring.Poly, k+1)
+	eval.tensor = make([][]*ring.Poly, k+1)
+	for i := range eval.tensor {
+		eval.mForm[i] = ringQ.NewPoly()
+		eval.tensor[i] = make([]*ring.Poly, k+1)
+		for j := range eval.tensor[i] {
+			eval.tensor[i][j] = ringQ.NewPoly()
+		}
+	}
+}
+
+// DecryptionProtocol is the distributed decryption protocol of multi-key CKKS ciphertexts.
+type DecryptionProtocol struct {
+	*mkrlwe.DecryptionProtocol
+}
+
+// NewDecryptionProtocol creates a new DecryptionProtocol, where sigmaSmudging is the standard deviation of the
+// smudging noise added to the decryption shares.
+func NewDecryptionProtocol(params ckks.Parameters, sigmaSmudging float64) *DecryptionProtocol {
+	return &DecryptionProtocol{mkrlwe.NewDecryptionProtocol(params.Parameters, sigmaSmudging)}
+}
+
+// ShallowCopy creates a shallow copy of DecryptionProtocol in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// DecryptionProtocol can be used concurrently.
+func (dec *DecryptionProtocol) ShallowCopy() *DecryptionProtocol {
+	return &DecryptionProtocol{dec.DecryptionProtocol.ShallowCopy()}
+}
+
+// GenShare generates the decryption share of party, with secret key sk, for the ciphertext ct.
+func (dec *DecryptionProtocol) GenShare(party PartyID, sk *rlwe.SecretKey, ct *Ciphertext, shareOut *drlwe.CKSShare) {
+	dec.DecryptionProtocol.GenShare(party, sk, ct.Ciphertext, shareOut)
+}
+
+// Decrypt combines the decryption shares of all the parties involved in ct and writes the plaintext on ptOut.
+// It returns an error if the share of an involved party is missing.
+func (dec *DecryptionProtocol) Decrypt(ct *Ciphertext, shares map[PartyID]*drlwe.CKSShare, ptOut *ckks.Plaintext) (err error) {
+	if err = dec.DecryptionProtocol.Decrypt(ct.Ciphertext, shares, ptOut.Value); err != nil {
+		return err
+	}
+	ptOut.Scale = ct.Scale
+	return nil
+}
@@ -0,0 +1,204 @@
+package mkckks
+
+import (
+	"flag"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tuneinsight/lattigo/v3/ckks"
+	"github.com/tuneinsight/lattigo/v3/drlwe"
+	"github.com/tuneinsight/lattigo/v3/mkrlwe"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+var flagLongTest = flag.Bool("long", false, "run the long test suite (all parameters). Overrides -short and requires -timeout=0.")
+
+var nbParties = 3
+var minPrec float64 = 15.0
+
+func testString(opname string, parties int, params ckks.Parameters) string {
+	return fmt.Sprintf("%s/LogN=%d/logQ=%d/levels=%d/parties=%d", opname, params.LogN(), params.LogQP(), params.MaxLevel()+1, parties)
+}
+
+type testContext struct {
+	params    ckks.Parameters
+	encoder   ckks.Encoder
+	parties   []PartyID
+	sks       map[PartyID]*rlwe.SecretKey
+	encryptor map[PartyID]ckks.Encryptor
+	evaluator *Evaluator
+	dec       *DecryptionProtocol
+}
+
+func TestMKCKKS(t *testing.T) {
+
+	var defaultParams []ckks.ParametersLiteral
+	switch {
+	case *flagLongTest:
+		defaultParams = ckks.DefaultParams
+	case testing.Short():
+		defaultParams = ckks.DefaultParams[:2]
+	default:
+		defaultParams = ckks.DefaultParams[:4]
+	}
+
+	for _, p := range defaultParams {
+
+		params, err := ckks.NewParametersFromLiteral(p)
+		if err != nil {
+			panic(err)
+		}
+
+		tc := genTestContext(params)
+
+		for _, testSet := range []func(tc *testContext, t *testing.T){
+			testAdd,
+			testMulRelin,
+			testRotate,
+		} {
+			testSet(tc, t)
+		}
+	}
+}
+
+func genTestContext(params ckks.Parameters) (tc *testContext) {
+
+	tc = &testContext{
+		params:    params,
+		encoder:   ckks.NewEncoder(params),
+		sks:       make(map[PartyID]*rlwe.SecretKey),
+		encryptor: make(map[PartyID]ckks.Encryptor),
+		dec:       NewDecryptionProtocol(params, 3.2),
+	}
+
+	galEls := []uint64{params.GaloisElementForColumnRotationBy(1), params.GaloisElementForRowRotation()}
+	keys := make(map[PartyID]*mkrlwe.EvaluationKey)
+
+	for i := 0; i < nbParties; i++ {
+		id := PartyID(i + 1)
+		crs, _ := utils.NewKeyedPRNG([]byte{'t', 'e', 's', 't'})
+		kgen := mkrlwe.NewKeyGenerator(params.Parameters, crs)
+		tc.parties = append(tc.parties, id)
+		tc.sks[id] = kgen.GenSecretKey()
+		tc.encryptor[id] = ckks.NewEncryptor(params, tc.sks[id])
+		keys[id] = kgen.GenEvaluationKey(tc.sks[id], galEls)
+	}
+
+	tc.evaluator = NewEvaluator(params, keys)
+
+	return
+}
+
+func newTestVectors(tc *testContext, party PartyID) (values []complex128, ct *Ciphertext) {
+	values = make([]complex128, tc.params.Slots())
+	for i := range values {
+		values[i] = complex(utils.RandFloat64(-1, 1), utils.RandFloat64(-1, 1))
+	}
+	pt := tc.encoder.EncodeNew(values, tc.params.MaxLevel(), tc.params.DefaultScale(), tc.params.LogSlots())
+	return values, NewCiphertextFromCKKS(party, tc.encryptor[party].EncryptNew(pt))
+}
+
+func verifyTestVectors(tc *testContext, valuesWant []complex128, ct *Ciphertext, t *testing.T) {
+
+	shares := make(map[PartyID]*drlwe.CKSShare)
+	for _, id := range ct.Parties {
+		shares[id] = tc.dec.AllocateShare(ct.Level())
+		tc.dec.GenShare(id, tc.sks[id], ct, shares[id])
+	}
+
+	pt := ckks.NewPlaintext(tc.params, ct.Level(), 0)
+	require.NoError(t, tc.dec.Decrypt(ct, shares, pt))
+
+	precStats := ckks.GetPrecisionStats(tc.params, tc.encoder, nil, valuesWant, pt, tc.params.LogSlots(), 0)
+
+	require.GreaterOrEqual(t, precStats.MeanPrecision.Real, minPrec)
+	require.GreaterOrEqual(t, precStats.MeanPrecision.Imag, minPrec)
+}
+
+func testAdd(tc *testContext, t *testing.T) {
+
+	t.Run(testString("Add", nbParties, tc.params), func(t *testing.T) {
+
+		want, ctSum := newTestVectors(tc, tc.parties[0])
+		for _, id := range tc.parties[1:] {
+			values, ct := newTestVectors(tc, id)
+			for i := range want {
+				want[i] += values[i]
+			}
+			tc.evaluator.Add(ctSum, ct, ctSum)
+		}
+
+		require.Equal(t, tc.parties, ctSum.Parties)
+		verifyTestVectors(tc, want, ctSum, t)
+
+		values, ct := newTestVectors(tc, tc.parties[2])
+		for i := range want {
+			want[i] -= values[i]
+		}
+		tc.evaluator.Sub(ctSum, ct, ctSum)
+		verifyTestVectors(tc, want, ctSum, t)
+	})
+}
+
+func testMulRelin(tc *testContext, t *testing.T) {
+
+	t.Run(testString("MulRelin", nbParties, tc.params), func(t *testing.T) {
+
+		values0, ct0 := newTestVectors(tc, tc.parties[0])
+		values1, ct1 := newTestVectors(tc, tc.parties[1])
+		values2, ct2 := newTestVectors(tc, tc.parties[2])
+
+		want := make([]complex128, len(values0))
+		for i := range want {
+			want[i] = values0[i] * (values1[i] + values2[i])
+		}
+
+		tc.evaluator.Add(ct1, ct2, ct1)
+
+		ctOut := NewCiphertext(tc.params, nil, tc.params.MaxLevel(), 0)
+		tc.evaluator.MulRelin(ct0, ct1, ctOut)
+		require.Equal(t, tc.parties, ctOut.Parties)
+
+		require.NoError(t, tc.evaluator.Rescale(ctOut, tc.params.DefaultScale(), ctOut))
+		verifyTestVectors(tc, want, ctOut, t)
+
+		// Squaring of a multi-key ciphertext
+		for i := range want {
+			want[i] *= want[i]
+		}
+
+		if ctOut.Level() > 0 {
+			tc.evaluator.MulRelin(ctOut, ctOut, ctOut)
+			require.NoError(t, tc.evaluator.Rescale(ctOut, tc.params.DefaultScale(), ctOut))
+			verifyTestVectors(tc, want, ctOut, t)
+		}
+	})
+}
+
+func testRotate(tc *testContext, t *testing.T) {
+
+	t.Run(testString("Rotate", nbParties, tc.params), func(t *testing.T) {
+
+		values0, ct := newTestVectors(tc, tc.parties[0])
+		values1, ct1 := newTestVectors(tc, tc.parties[1])
+		tc.evaluator.Add(ct, ct1, ct)
+
+		want := make([]complex128, len(values0))
+		for i := range want {
+			want[i] = values0[i] + values1[i]
+		}
+
+		ctOut := NewCiphertext(tc.params, nil, ct.Level(), 0)
+		tc.evaluator.Rotate(ct, 1, ctOut)
+		verifyTestVectors(tc, utils.RotateComplex128Slice(want, 1), ctOut, t)
+
+		for i := range want {
+			want[i] = complex(real(want[i]), -imag(want[i]))
+		}
+
+		tc.evaluator.Conjugate(ct, ct)
+		verifyTestVectors(tc, want, ct, t)
+	})
+}
@@ -0,0 +1,150 @@
+// Package mkrlwe implements the scheme-agnostic part of the multi-key variants of the RLWE-based homomorphic
+// encryption schemes. In the multi-key setting, each party has its own secret key and encrypts under it, and
+// ciphertexts are extended with one component per involved party: a multi-key ciphertext [c0, c_1, ..., c_k]
+// decrypts as c0 + sum_i c_i*s_i. The homomorphic operations use the individual evaluation keys of the parties,
+// and the decryption is a distributed protocol in which each involved party provides a share.
+package mkrlwe
+
+import (
+	"sort"
+
+	"github.com/tuneinsight/lattigo/v3/drlwe"
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// PartyID is the identifier of a party in the multi-key schemes.
+type PartyID = drlwe.PartyID
+
+// Ciphertext is a multi-key ciphertext. Value[0] is the component common to all the parties and Value[i+1] is
+// the component of the party Parties[i]. Parties is sorted in increasing order.
+type Ciphertext struct {
+	Value   []*ring.Poly
+	Parties []PartyID
+}
+
+// NewCiphertext allocates a new multi-key ciphertext for the given parties at the given level.
+func NewCiphertext(params rlwe.Parameters, parties []PartyID, level int) *Ciphertext {
+	ct := &Ciphertext{Parties: sortedParties(parties)}
+	ct.Value = make([]*ring.Poly, len(ct.Parties)+1)
+	for i := range ct.Value {
+		ct.Value[i] = ring.NewPoly(params.N(), level+1)
+	}
+	return ct
+}
+
+// NewCiphertextFromRLWE creates a new multi-key ciphertext from a degree-1 ciphertext encrypted under the secret key of party.
+// The polynomials of ct are copied.
+func NewCiphertextFromRLWE(party PartyID, ct *rlwe.Ciphertext) *Ciphertext {
+	if ct.Degree() != 1 {
+		panic("cannot NewCiphertextFromRLWE: ciphertext must be of degree 1")
+	}
+	return &Ciphertext{Value: []*ring.Poly{ct.Value[0].CopyNew(), ct.Value[1].CopyNew()}, Parties: []PartyID{party}}
+}
+
+// Level returns the level of the ciphertext.
+func (ct *Ciphertext) Level() int {
+	return ct.Value[0].Level()
+}
+
+// IsNTT returns true if the ciphertext is in the NTT domain.
+func (ct *Ciphertext) IsNTT() bool {
+	return ct.Value[0].IsNTT
+}
+
+// CopyNew creates a deep copy of the ciphertext.
+func (ct *Ciphertext) CopyNew() *Ciphertext {
+	ctCopy := &Ciphertext{Value: make([]*ring.Poly, len(ct.Value)), Parties: append([]PartyID(nil), ct.Parties...)}
+	for i := range ct.Value {
+		ctCopy.Value[i] = ct.Value[i].CopyNew()
+	}
+	return ctCopy
+}
+
+// Component returns the component of the ciphertext associated to party, or nil if the party is not involved.
+func (ct *Ciphertext) Component(party PartyID) *ring.Poly {
+	if i, ok := ct.index(party); ok {
+		return ct.Value[i+1]
+	}
+	return nil
+}
+
+func (ct *Ciphertext) index(party PartyID) (int, bool) {
+	for i, id := range ct.Parties {
+		if id == party {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// reshape ensures that ct has the given parties and at least the given level. It returns the
+// polynomials in which to write a result, which are freshly allocated if the parties of ct change,
+// so that ct can alias an operand of the operation.
+func (ct *Ciphertext) reshape(params rlwe.Parameters, parties []PartyID, level int) []*ring.Poly {
+	if equalParties(ct.Parties, parties) && ct.Level() >= level {
+		return ct.Value
+	}
+	value := make([]*ring.Poly, len(parties)+1)
+	for i := range value {
+		value[i] = ring.NewPoly(params.N(), level+1)
+	}
+	return value
+}
+
+// MergeParties returns the sorted union of the parties of a and b.
+func MergeParties(a, b []PartyID) []PartyID {
+	return sortedParties(append(append([]PartyID(nil), a...), b...))
+}
+
+// TensorIndex returns, for each element of a ciphertext on the given parties,
+// its index in the tensor product on the merged parties, which must contain the given parties.
+func TensorIndex(parties, merged []PartyID) (index []int) {
+	index = make([]int, len(parties)+1)
+	for i, j := 0, 0; i < len(parties); j++ {
+		if merged[j] == parties[i] {
+			index[i+1] = j + 1
+			i++
+		}
+	}
+	return
+}
+
+func sortedParties(parties []PartyID) (sorted []PartyID) {
+	seen := make(map[PartyID]bool, len(parties))
+	for _, id := range parties {
+		if !seen[id] {
+			seen[id] = true
+			sorted = append(sorted, id)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return
+}
+
+func equalParties(a, b []PartyID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func minLevel(ct0, ct1 *Ciphertext) int {
+	return utils.MinInt(ct0.Level(), ct1.Level())
+}
+
+// set assigns the result value of an operation on the given parties to ct, at the given level.
+func (ct *Ciphertext) set(value []*ring.Poly, parties []PartyID, level int, isNTT bool) {
+	for _, pol := range value {
+		pol.Coeffs = pol.Coeffs[:level+1]
+		pol.IsNTT = isNTT
+	}
+	ct.Value = value
+	ct.Parties = append([]PartyID(nil), parties...)
+}
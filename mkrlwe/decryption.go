@@ -0,0 +1,74 @@
+package mkrlwe
+
+import (
+	"fmt"
+
+	"github.com/tuneinsight/lattigo/v3/drlwe"
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+// DecryptionProtocol is the distributed decryption protocol of multi-key ciphertexts. Each party involved in a ciphertext
+// computes, with the CKS protocol towards the zero key, a share c_i*s_i + e_i from its component c_i of the ciphertext.
+// The plaintext is then recovered as c0 + sum_i (c_i*s_i + e_i).
+type DecryptionProtocol struct {
+	cks    *drlwe.CKSProtocol
+	params rlwe.Parameters
+	zero   *rlwe.SecretKey
+}
+
+// NewDecryptionProtocol creates a new DecryptionProtocol, where sigmaSmudging is the standard deviation of the
+// smudging noise added to the shares.
+func NewDecryptionProtocol(params rlwe.Parameters, sigmaSmudging float64) *DecryptionProtocol {
+	return &DecryptionProtocol{
+		cks:    drlwe.NewCKSProtocol(params, sigmaSmudging),
+		params: params,
+		zero:   rlwe.NewSecretKey(params),
+	}
+}
+
+// ShallowCopy creates a shallow copy of DecryptionProtocol in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// DecryptionProtocol can be used concurrently.
+func (dec *DecryptionProtocol) ShallowCopy() *DecryptionProtocol {
+	return &DecryptionProtocol{cks: dec.cks.ShallowCopy(), params: dec.params, zero: dec.zero}
+}
+
+// AllocateShare allocates a decryption share at the given level.
+func (dec *DecryptionProtocol) AllocateShare(level int) *drlwe.CKSShare {
+	return dec.cks.AllocateShare(level)
+}
+
+// GenShare generates the decryption share of party, with secret key sk, for the ciphertext ct.
+// It panics if the party is not involved in ct.
+func (dec *DecryptionProtocol) GenShare(party PartyID, sk *rlwe.SecretKey, ct *Ciphertext, shareOut *drlwe.CKSShare) {
+	c := ct.Component(party)
+	if c == nil {
+		panic(fmt.Sprintf("cannot GenShare: party %d is not involved in the ciphertext", party))
+	}
+	dec.cks.GenShare(sk, dec.zero, c, shareOut)
+}
+
+// Decrypt combines the decryption shares of all the parties involved in ct and writes the decrypted plaintext on ptOut,
+// in the domain of ct. It returns an error if the share of an involved party is missing.
+func (dec *DecryptionProtocol) Decrypt(ct *Ciphertext, shares map[PartyID]*drlwe.CKSShare, ptOut *ring.Poly) (err error) {
+
+	for _, party := range ct.Parties {
+		if _, ok := shares[party]; !ok {
+			return fmt.Errorf("cannot Decrypt: missing decryption share of party %d", party)
+		}
+	}
+
+	ringQ := dec.params.RingQ()
+	level := ct.Level()
+
+	ring.CopyValuesLvl(level, ct.Value[0], ptOut)
+	for _, party := range ct.Parties {
+		ringQ.AddLvl(level, ptOut, shares[party].Value, ptOut)
+	}
+
+	ptOut.Coeffs = ptOut.Coeffs[:level+1]
+	ptOut.IsNTT = ct.IsNTT()
+
+	return nil
+}
@@ -0,0 +1,225 @@
+package mkrlwe
+
+import (
+	"fmt"
+
+	"github.com/tuneinsight/lattigo/v3/drlwe"
+	"github.com/tuneinsight/lattigo/v3/ring"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+// Evaluator implements the scheme-agnostic homomorphic operations on multi-key ciphertexts. It relinearizes and rotates
+// with the individual evaluation keys of the parties involved in each ciphertext.
+type Evaluator struct {
+	*rlwe.KeySwitcher
+	params rlwe.Parameters
+	keys   map[PartyID]*EvaluationKey
+
+	permuteNTTIndex map[uint64][]uint64
+
+	swk          *rlwe.SwitchingKey
+	c, t, u0, u1 *ring.Poly
+}
+
+// NewEvaluator creates a new Evaluator from the evaluation keys of the parties.
+func NewEvaluator(params rlwe.Parameters, keys map[PartyID]*EvaluationKey) *Evaluator {
+
+	eval := newEvaluator(params, keys)
+
+	ringQ := params.RingQ()
+	eval.permuteNTTIndex = make(map[uint64][]uint64)
+	for _, evk := range keys {
+		if evk.RotationKeys != nil {
+			for galEl := range evk.RotationKeys.Keys {
+				if _, ok := eval.permuteNTTIndex[galEl]; !ok {
+					eval.permuteNTTIndex[galEl] = ringQ.PermuteNTTIndex(galEl)
+				}
+			}
+		}
+	}
+
+	return eval
+}
+
+func newEvaluator(params rlwe.Parameters, keys map[PartyID]*EvaluationKey) *Evaluator {
+	ringQ := params.RingQ()
+	return &Evaluator{
+		KeySwitcher: rlwe.NewKeySwitcher(params),
+		params:      params,
+		keys:        keys,
+		swk:         &rlwe.SwitchingKey{Value: make([][2]rlwe.PolyQP, params.Beta())},
+		c:           ringQ.NewPoly(),
+		t:           ringQ.NewPoly(),
+		u0:          ringQ.NewPoly(),
+		u1:          ringQ.NewPoly(),
+	}
+}
+
+// ShallowCopy creates a shallow copy of Evaluator in which all the read-only data-structures are
+// shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
+// Evaluator can be used concurrently.
+func (eval *Evaluator) ShallowCopy() *Evaluator {
+	evalCopy := newEvaluator(eval.params, eval.keys)
+	evalCopy.permuteNTTIndex = eval.permuteNTTIndex
+	return evalCopy
+}
+
+// Parameters returns the parameters of the evaluator.
+func (eval *Evaluator) Parameters() rlwe.Parameters {
+	return eval.params
+}
+
+// Add adds ct0 and ct1 and writes the result on ctOut. The parties of ctOut are the union of the parties of ct0 and ct1.
+func (eval *Evaluator) Add(ct0, ct1, ctOut *Ciphertext) {
+	eval.addOrSub(ct0, ct1, ctOut, false)
+}
+
+// Sub subtracts ct1 from ct0 and writes the result on ctOut. The parties of ctOut are the union of the parties of ct0 and ct1.
+func (eval *Evaluator) Sub(ct0, ct1, ctOut *Ciphertext) {
+	eval.addOrSub(ct0, ct1, ctOut, true)
+}
+
+func (eval *Evaluator) addOrSub(ct0, ct1, ctOut *Ciphertext, sub bool) {
+
+	if ct0.IsNTT() != ct1.IsNTT() {
+		panic("cannot Add/Sub: ciphertexts must be in the same domain")
+	}
+
+	ringQ := eval.params.RingQ()
+	level := minLevel(ct0, ct1)
+	parties := MergeParties(ct0.Parties, ct1.Parties)
+	value := ctOut.reshape(eval.params, parties, level)
+
+	for i := range value {
+
+		p0, p1 := ct0.Value[0], ct1.Value[0]
+		if i > 0 {
+			p0, p1 = ct0.Component(parties[i-1]), ct1.Component(parties[i-1])
+		}
+
+		switch {
+		case p0 != nil && p1 != nil && sub:
+			ringQ.SubLvl(level, p0, p1, value[i])
+		case p0 != nil && p1 != nil:
+			ringQ.AddLvl(level, p0, p1, value[i])
+		case p0 != nil:
+			ring.CopyValuesLvl(level, p0, value[i])
+		case sub:
+			ringQ.NegLvl(level, p1, value[i])
+		default:
+			ring.CopyValuesLvl(level, p1, value[i])
+		}
+	}
+
+	ctOut.set(value, parties, level, ct0.IsNTT())
+}
+
+// Neg negates ct and writes the result on ctOut.
+func (eval *Evaluator) Neg(ct, ctOut *Ciphertext) {
+	level := ct.Level()
+	value := ctOut.reshape(eval.params, ct.Parties, level)
+	for i := range value {
+		eval.params.RingQ().NegLvl(level, ct.Value[i], value[i])
+	}
+	ctOut.set(value, ct.Parties, level, ct.IsNTT())
+}
+
+// RelinearizeTensor relinearizes the tensor product of two multi-key ciphertexts on the given parties and writes
+// the result on ctOut. tensor[i][j] is the coefficient of s_i*s_j, where s_0 = 1 and s_i is the secret key of
+// parties[i-1], and all its entries must be allocated at least at the given level, in the same domain.
+// The entries of tensor are used as buffers and are modified.
+//
+// Each term c*s_i*s_j, for i, j > 0, is relinearized with the individual keys of the parties i and j:
+// [c', t] = Switch(c, [b_j, d2_i]) and [u0, u1] = Switch(c', Keys[1] of i), which adds u0 to the first
+// component, u1 to the component of party i and t to the component of party j.
+func (eval *Evaluator) RelinearizeTensor(level int, tensor [][]*ring.Poly, parties []PartyID, ctOut *Ciphertext) {
+
+	ringQ := eval.params.RingQ()
+	isNTT := tensor[0][0].IsNTT
+	k := len(parties)
+
+	for i := 0; i <= k; i++ {
+		for j := i + 1; j <= k; j++ {
+			ringQ.AddLvl(level, tensor[i][j], tensor[j][i], tensor[i][j])
+		}
+	}
+
+	value := ctOut.reshape(eval.params, parties, level)
+
+	for i := 0; i <= k; i++ {
+		ring.CopyValuesLvl(level, tensor[0][i], value[i])
+	}
+
+	eval.c.IsNTT = isNTT
+
+	for i := 1; i <= k; i++ {
+
+		rlkI := eval.relinearizationKey(parties[i-1])
+
+		for j := i; j <= k; j++ {
+
+			rlkJ := eval.relinearizationKey(parties[j-1])
+
+			for w := range eval.swk.Value {
+				eval.swk.Value[w] = [2]rlwe.PolyQP{rlkJ.Keys[0].Value[w][0], rlkI.Keys[0].Value[w][1]}
+			}
+
+			tensor[i][j].IsNTT = isNTT
+			eval.SwitchKeysInPlace(level, tensor[i][j], eval.swk, eval.c, eval.t)
+			eval.SwitchKeysInPlace(level, eval.c, rlkI.Keys[1], eval.u0, eval.u1)
+
+			ringQ.AddLvl(level, value[0], eval.u0, value[0])
+			ringQ.AddLvl(level, value[i], eval.u1, value[i])
+			ringQ.AddLvl(level, value[j], eval.t, value[j])
+		}
+	}
+
+	ctOut.set(value, parties, level, isNTT)
+}
+
+// Automorphism applies the automorphism X^i -> X^(i*galEl) on ct, using the rotation keys of the parties of ct, and writes
+// the result on ctOut. Ciphertexts outside of the NTT domain must be at the maximum level.
+func (eval *Evaluator) Automorphism(ct *Ciphertext, galEl uint64, ctOut *Ciphertext) {
+
+	ringQ := eval.params.RingQ()
+	level := ct.Level()
+	isNTT := ct.IsNTT()
+	value := ctOut.reshape(eval.params, ct.Parties, level)
+
+	ring.CopyValuesLvl(level, ct.Value[0], eval.c)
+
+	for i, party := range ct.Parties {
+		eval.SwitchKeysInPlace(level, ct.Value[i+1], eval.rotationKey(party, galEl), eval.u0, eval.u1)
+		ringQ.AddLvl(level, eval.c, eval.u0, eval.c)
+		eval.permute(level, eval.u1, galEl, value[i+1], isNTT)
+	}
+
+	eval.permute(level, eval.c, galEl, value[0], isNTT)
+
+	ctOut.set(value, ct.Parties, level, isNTT)
+}
+
+func (eval *Evaluator) permute(level int, polIn *ring.Poly, galEl uint64, polOut *ring.Poly, isNTT bool) {
+	if isNTT {
+		eval.params.RingQ().PermuteNTTWithIndexLvl(level, polIn, eval.permuteNTTIndex[galEl], polOut)
+	} else {
+		eval.params.RingQ().Permute(polIn, galEl, polOut)
+	}
+}
+
+func (eval *Evaluator) relinearizationKey(party PartyID) *drlwe.PKRelinearizationKey {
+	evk, ok := eval.keys[party]
+	if !ok || evk.RelinearizationKey == nil {
+		panic(fmt.Sprintf("relinearization key of party %d not available", party))
+	}
+	return evk.RelinearizationKey
+}
+
+func (eval *Evaluator) rotationKey(party PartyID, galEl uint64) *rlwe.SwitchingKey {
+	if evk, ok := eval.keys[party]; ok && evk.RotationKeys != nil {
+		if rtk, ok := evk.RotationKeys.GetRotationKey(galEl); ok {
+			return rtk
+		}
+	}
+	panic(fmt.Sprintf("rotation key k=%d of party %d not available", eval.params.InverseGaloisElement(galEl), party))
+}
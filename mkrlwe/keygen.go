@@ -0,0 +1,51 @@
+package mkrlwe
+
+import (
+	"github.com/tuneinsight/lattigo/v3/drlwe"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+// EvaluationKey is the public evaluation material of a single party in the multi-key schemes.
+// The relinearization key of a party is a PKRelinearizationKey generated by the party alone
+// from the common reference polynomials shared by all the parties.
+type EvaluationKey struct {
+	RelinearizationKey *drlwe.PKRelinearizationKey
+	RotationKeys       *rlwe.RotationKeySet
+}
+
+// KeyGenerator generates the keys of a party in the multi-key schemes.
+type KeyGenerator struct {
+	rlwe.KeyGenerator
+	params rlwe.Parameters
+	rkg    *drlwe.PKRKGProtocol
+	crp    drlwe.PKRKGCRP
+}
+
+// NewKeyGenerator creates a new KeyGenerator. The common reference polynomials of the relinearization keys are
+// sampled from crs, which must be a fresh common reference string shared by all the parties.
+func NewKeyGenerator(params rlwe.Parameters, crs drlwe.CRS) *KeyGenerator {
+	rkg := drlwe.NewPKRKGProtocol(params)
+	return &KeyGenerator{
+		KeyGenerator: rlwe.NewKeyGenerator(params),
+		params:       params,
+		rkg:          rkg,
+		crp:          rkg.SampleCRP(crs),
+	}
+}
+
+// GenEvaluationKey generates the evaluation key of the party with secret key sk, with the
+// rotation keys for the Galois elements galEls.
+func (kgen *KeyGenerator) GenEvaluationKey(sk *rlwe.SecretKey, galEls []uint64) *EvaluationKey {
+
+	share := kgen.rkg.AllocateShare()
+	kgen.rkg.GenShare(sk, kgen.crp, share)
+
+	evk := &EvaluationKey{RelinearizationKey: drlwe.NewPKRelinearizationKey(kgen.params)}
+	kgen.rkg.GenRelinearizationKey(share, kgen.crp, evk.RelinearizationKey)
+
+	if len(galEls) > 0 {
+		evk.RotationKeys = kgen.GenRotationKeys(galEls, sk)
+	}
+
+	return evk
+}
@@ -0,0 +1,38 @@
+package mkrlwe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+func TestMKRLWE(t *testing.T) {
+
+	t.Run("MergeParties", func(t *testing.T) {
+		require.Equal(t, []PartyID{1, 2, 3, 5}, MergeParties([]PartyID{5, 2}, []PartyID{3, 1, 2}))
+		require.Nil(t, MergeParties(nil, nil))
+	})
+
+	t.Run("TensorIndex", func(t *testing.T) {
+		require.Equal(t, []int{0, 2, 4}, TensorIndex([]PartyID{2, 5}, []PartyID{1, 2, 3, 5}))
+		require.Equal(t, []int{0}, TensorIndex(nil, []PartyID{1, 2}))
+	})
+
+	t.Run("Ciphertext", func(t *testing.T) {
+
+		params, err := rlwe.NewParametersFromLiteral(rlwe.TestPN12QP109)
+		require.NoError(t, err)
+
+		ct := NewCiphertext(params, []PartyID{3, 1, 3}, params.MaxLevel())
+		require.Equal(t, []PartyID{1, 3}, ct.Parties)
+		require.Len(t, ct.Value, 3)
+		require.Equal(t, params.MaxLevel(), ct.Level())
+		require.True(t, ct.Component(3) == ct.Value[2])
+		require.Nil(t, ct.Component(2))
+
+		ctCopy := ct.CopyNew()
+		require.True(t, ctCopy.Value[1] != ct.Value[1])
+		require.Equal(t, ct.Parties, ctCopy.Parties)
+	})
+}
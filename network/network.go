@@ -0,0 +1,156 @@
+// Package network implements the orchestration of the multiparty protocols of the drlwe, dbfv and dckks
+// packages over a pluggable transport layer. Each party runs its own Party instance which exchanges the
+// protocol shares with the other parties along an aggregation Topology (e.g. a star or a tree), using
+// the MarshalBinary and UnmarshalBinary methods of the shares for their serialization.
+package network
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tuneinsight/lattigo/v3/drlwe"
+)
+
+// PartyID is the identifier of a party in the network.
+type PartyID = drlwe.PartyID
+
+// ErrTimeout is the error returned when a message is not received within the allocated time.
+var ErrTimeout = errors.New("network: timeout")
+
+// ErrClosed is the error returned when operating on a closed Transport.
+var ErrClosed = errors.New("network: transport closed")
+
+// Transport is an interface for the point-to-point, tagged, message-passing layer used by the parties.
+// Messages are identified by their sender and their tag, and Receive returns the message matching
+// both, independently of the order in which messages were delivered.
+type Transport interface {
+	// ID returns the identifier of the party owning the transport.
+	ID() PartyID
+	// Send sends payload to the party to under the given tag.
+	Send(to PartyID, tag string, payload []byte) error
+	// Receive blocks until the message with the given tag from the party from is received,
+	// and returns ErrTimeout if it is not received within timeout. A non-positive timeout waits indefinitely.
+	Receive(from PartyID, tag string, timeout time.Duration) ([]byte, error)
+	// Close releases the resources of the transport.
+	Close() error
+}
+
+type mailboxKey struct {
+	from PartyID
+	tag  string
+}
+
+// mailbox stores the received messages until they are claimed by a call to receive.
+type mailbox struct {
+	mu     sync.Mutex
+	boxes  map[mailboxKey]chan []byte
+	closed chan struct{}
+	once   sync.Once
+}
+
+func newMailbox() *mailbox {
+	return &mailbox{boxes: make(map[mailboxKey]chan []byte), closed: make(chan struct{})}
+}
+
+func (m *mailbox) box(key mailboxKey) chan []byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	box, ok := m.boxes[key]
+	if !ok {
+		box = make(chan []byte, 1)
+		m.boxes[key] = box
+	}
+	return box
+}
+
+func (m *mailbox) put(from PartyID, tag string, payload []byte) error {
+	select {
+	case <-m.closed:
+		return ErrClosed
+	default:
+	}
+
+	select {
+	case m.box(mailboxKey{from, tag}) <- payload:
+		return nil
+	default:
+		return fmt.Errorf("network: duplicate message with tag %q from party %d", tag, from)
+	}
+}
+
+func (m *mailbox) receive(from PartyID, tag string, timeout time.Duration) ([]byte, error) {
+
+	key := mailboxKey{from, tag}
+	box := m.box(key)
+
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	select {
+	case payload := <-box:
+		m.mu.Lock()
+		delete(m.boxes, key)
+		m.mu.Unlock()
+		return payload, nil
+	case <-deadline:
+		return nil, fmt.Errorf("receiving %q from party %d: %w", tag, from, ErrTimeout)
+	case <-m.closed:
+		return nil, ErrClosed
+	}
+}
+
+func (m *mailbox) close() {
+	m.once.Do(func() { close(m.closed) })
+}
+
+// localTransport is an in-memory Transport.
+type localTransport struct {
+	id      PartyID
+	mailbox *mailbox
+	peers   map[PartyID]*localTransport
+}
+
+// NewLocalTransports returns a set of in-memory transports, connected to each other, for the parties
+// with the given identifiers. It is intended for simulating all the parties within a single process.
+func NewLocalTransports(ids []PartyID) map[PartyID]Transport {
+	peers := make(map[PartyID]*localTransport, len(ids))
+	for _, id := range ids {
+		peers[id] = &localTransport{id: id, mailbox: newMailbox(), peers: peers}
+	}
+	transports := make(map[PartyID]Transport, len(ids))
+	for id, t := range peers {
+		transports[id] = t
+	}
+	return transports
+}
+
+// ID returns the identifier of the party owning the transport.
+func (t *localTransport) ID() PartyID {
+	return t.id
+}
+
+// Send sends a copy of payload to the party to under the given tag.
+func (t *localTransport) Send(to PartyID, tag string, payload []byte) error {
+	peer, ok := t.peers[to]
+	if !ok {
+		return fmt.Errorf("network: unknown party %d", to)
+	}
+	return peer.mailbox.put(t.id, tag, append([]byte(nil), payload...))
+}
+
+// Receive blocks until the message with the given tag from the party from is received.
+func (t *localTransport) Receive(from PartyID, tag string, timeout time.Duration) ([]byte, error) {
+	return t.mailbox.receive(from, tag, timeout)
+}
+
+// Close closes the transport.
+func (t *localTransport) Close() error {
+	t.mailbox.close()
+	return nil
+}
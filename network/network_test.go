@@ -0,0 +1,388 @@
+package network
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/tuneinsight/lattigo/v3/bfv"
+	"github.com/tuneinsight/lattigo/v3/ckks"
+	"github.com/tuneinsight/lattigo/v3/dbfv"
+	"github.com/tuneinsight/lattigo/v3/dckks"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+const parties = 5
+
+func partyIDs(n int) (ids []PartyID) {
+	ids = make([]PartyID, n)
+	for i := range ids {
+		ids[i] = PartyID(i)
+	}
+	return
+}
+
+// run runs f concurrently for each party and returns the first error.
+func run(ids []PartyID, f func(i int, id PartyID) error) error {
+	errs := make([]error, len(ids))
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id PartyID) {
+			defer wg.Done()
+			errs[i] = f(i, id)
+		}(i, id)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func genSecretKeys(params rlwe.Parameters, n int) (skShares []*rlwe.SecretKey, skIdeal *rlwe.SecretKey) {
+	kgen := rlwe.NewKeyGenerator(params)
+	skShares = make([]*rlwe.SecretKey, n)
+	skIdeal = rlwe.NewSecretKey(params)
+	ringQP, levelQ, levelP := params.RingQP(), params.QCount()-1, params.PCount()-1
+	for i := range skShares {
+		skShares[i] = kgen.GenSecretKey()
+		ringQP.AddLvl(levelQ, levelP, skIdeal.Value, skShares[i].Value, skIdeal.Value)
+	}
+	return
+}
+
+func TestNetwork(t *testing.T) {
+
+	params, err := bfv.NewParametersFromLiteral(bfv.PN12QP109)
+	require.NoError(t, err)
+
+	ids := partyIDs(parties)
+
+	for _, topo := range []struct {
+		name     string
+		topology Topology
+	}{
+		{"Star", NewStarTopology(ids)},
+		{"Tree", NewTreeTopology(ids, 2)},
+	} {
+		t.Run(fmt.Sprintf("%s/LogN=%d/parties=%d", topo.name, params.LogN(), parties), func(t *testing.T) {
+			testBFVProtocols(t, params, ids, NewLocalTransports(ids), topo.topology)
+		})
+	}
+
+	t.Run("TCP", func(t *testing.T) {
+
+		n := 3
+		ids := partyIDs(n)
+		transports := make(map[PartyID]Transport, n)
+		addrs := make(map[PartyID]string, n)
+		for _, id := range ids {
+			tcp, err := NewTCPTransport(id, "127.0.0.1:0")
+			require.NoError(t, err)
+			transports[id] = tcp
+			addrs[id] = tcp.Addr()
+		}
+		for _, tr := range transports {
+			tr.(*TCPTransport).SetPeers(addrs)
+		}
+
+		testBFVProtocols(t, params, ids, transports, NewStarTopology(ids))
+	})
+
+	t.Run("TCP/MaxFrameSize", func(t *testing.T) {
+
+		tcp, err := NewTCPTransport(0, "127.0.0.1:0")
+		require.NoError(t, err)
+		defer tcp.Close()
+		tcp.SetMaxFrameSize(64)
+		tcp.SetPeers(map[PartyID]string{0: tcp.Addr()})
+
+		require.Error(t, tcp.Send(0, "tag", make([]byte, 64)))
+
+		// A frame announcing an oversized payload closes the connection without allocating it
+		conn, err := net.Dial("tcp", tcp.Addr())
+		require.NoError(t, err)
+		defer conn.Close()
+
+		header := make([]byte, 8+4+3+8)
+		binary.LittleEndian.PutUint32(header[8:], 3)
+		copy(header[12:], "tag")
+		binary.LittleEndian.PutUint64(header[15:], math.MaxUint64)
+		_, err = conn.Write(header)
+		require.NoError(t, err)
+
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		_, err = conn.Read(make([]byte, 1))
+		require.ErrorIs(t, err, io.EOF)
+
+		// The closed connection is no longer tracked
+		require.Eventually(t, func() bool {
+			tcp.mu.Lock()
+			defer tcp.mu.Unlock()
+			return len(tcp.inbound) == 0
+		}, time.Second, 10*time.Millisecond)
+
+		// Frames within the limit are still received
+		require.NoError(t, tcp.Send(0, "tag", []byte{1}))
+		data, err := tcp.Receive(0, "tag", time.Second)
+		require.NoError(t, err)
+		require.Equal(t, []byte{1}, data)
+	})
+
+	t.Run("TCP/Redial", func(t *testing.T) {
+
+		tcp, err := NewTCPTransport(0, "127.0.0.1:0")
+		require.NoError(t, err)
+		defer tcp.Close()
+		tcp.SetPeers(map[PartyID]string{0: tcp.Addr()})
+
+		require.NoError(t, tcp.Send(0, "first", []byte{1}))
+
+		// The cached connection is broken and must be redialed
+		tcp.mu.Lock()
+		tcp.conns[0].conn.Close()
+		tcp.mu.Unlock()
+
+		require.NoError(t, tcp.Send(0, "second", []byte{2}))
+
+		for tag, want := range map[string][]byte{"first": {1}, "second": {2}} {
+			data, err := tcp.Receive(0, tag, time.Second)
+			require.NoError(t, err)
+			require.Equal(t, want, data)
+		}
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+
+		ids := partyIDs(3)
+		transports := NewLocalTransports(ids)
+		topology := NewStarTopology(ids)
+		skShares, _ := genSecretKeys(params.Parameters, len(ids))
+
+		// The last party never participates.
+		err := run(ids[:2], func(i int, id PartyID) error {
+			ckg := dbfv.NewCKGProtocol(params)
+			prng, _ := utils.NewKeyedPRNG([]byte{'n', 'e', 't'})
+			return NewParty(transports[id], topology, 100*time.Millisecond).RunCKG(&ckg.CKGProtocol, skShares[i], ckg.SampleCRP(prng), rlwe.NewPublicKey(params.Parameters))
+		})
+
+		require.Error(t, err)
+		require.True(t, errors.Is(err, ErrTimeout))
+
+		for _, tr := range transports {
+			require.NoError(t, tr.Close())
+		}
+	})
+
+	t.Run("InvalidEKGShare", func(t *testing.T) {
+
+		ids := partyIDs(2)
+		transports := NewLocalTransports(ids)
+		skShares, _ := genSecretKeys(params.Parameters, len(ids))
+		prng, _ := utils.NewKeyedPRNG([]byte{'n', 'e', 't'})
+
+		galEls := []uint64{params.GaloisElementForColumnRotationBy(1), params.GaloisElementForRowRotation()}
+		ekg := dbfv.NewEKGProtocol(params, galEls, true)
+		crp := ekg.SampleCRP(prng)
+
+		// The second party sends a first-round share lacking the share of one of the Galois elements.
+		ephSk, share, _ := ekg.AllocateShare()
+		ekg.GenShareRoundOne(skShares[1], crp, ephSk, share)
+		delete(share.RTG, galEls[0])
+		data, err := share.MarshalBinary()
+		require.NoError(t, err)
+		require.NoError(t, transports[1].Send(0, "1/share", data))
+
+		evk := rlwe.EvaluationKey{Rlk: rlwe.NewRelinKey(params.Parameters, 1), Rtks: rlwe.NewRotationKeySet(params.Parameters, galEls)}
+		err = NewParty(transports[0], NewStarTopology(ids), time.Second).RunEKG(&ekg.EKGProtocol, skShares[0], crp, evk)
+		require.Error(t, err)
+
+		for _, tr := range transports {
+			require.NoError(t, tr.Close())
+		}
+	})
+
+	t.Run("DuplicateMessage", func(t *testing.T) {
+		transports := NewLocalTransports(partyIDs(2))
+		require.NoError(t, transports[0].Send(1, "tag", []byte{1}))
+		require.Error(t, transports[0].Send(1, "tag", []byte{2}))
+		data, err := transports[1].Receive(0, "tag", time.Second)
+		require.NoError(t, err)
+		require.Equal(t, []byte{1}, data)
+	})
+}
+
+func testBFVProtocols(t *testing.T, params bfv.Parameters, ids []PartyID, transports map[PartyID]Transport, topology Topology) {
+
+	defer func() {
+		for _, tr := range transports {
+			require.NoError(t, tr.Close())
+		}
+	}()
+
+	skShares, skIdeal := genSecretKeys(params.Parameters, len(ids))
+	skOutShares, skOutIdeal := genSecretKeys(params.Parameters, len(ids))
+
+	galEl := params.GaloisElementForColumnRotationBy(1)
+
+	nodes := make([]*Party, len(ids))
+	pks := make([]*rlwe.PublicKey, len(ids))
+	rlks := make([]*rlwe.RelinearizationKey, len(ids))
+	rtks := make([]*rlwe.RotationKeySet, len(ids))
+	evks := make([]rlwe.EvaluationKey, len(ids))
+	galEls := []uint64{galEl, params.GaloisElementForRowRotation()}
+
+	// Each party samples the common reference polynomials from the same keyed PRNG, in the same order.
+	require.NoError(t, run(ids, func(i int, id PartyID) (err error) {
+
+		nodes[i] = NewParty(transports[id], topology, 10*time.Second)
+		prng, _ := utils.NewKeyedPRNG([]byte{'n', 'e', 't'})
+
+		ckg := dbfv.NewCKGProtocol(params)
+		pks[i] = rlwe.NewPublicKey(params.Parameters)
+		if err = nodes[i].RunCKG(&ckg.CKGProtocol, skShares[i], ckg.SampleCRP(prng), pks[i]); err != nil {
+			return
+		}
+
+		rkg := dbfv.NewRKGProtocol(params)
+		rlks[i] = rlwe.NewRelinKey(params.Parameters, 1)
+		if err = nodes[i].RunRKG(&rkg.RKGProtocol, skShares[i], rkg.SampleCRP(prng), rlks[i]); err != nil {
+			return
+		}
+
+		rtg := dbfv.NewRotKGProtocol(params)
+		rtks[i] = rlwe.NewRotationKeySet(params.Parameters, []uint64{galEl})
+		if err = nodes[i].RunRTG(&rtg.RTGProtocol, skShares[i], galEl, rtg.SampleCRP(prng), rtks[i].Keys[galEl]); err != nil {
+			return
+		}
+
+		ekg := dbfv.NewEKGProtocol(params, galEls, true)
+		evks[i] = rlwe.EvaluationKey{Rlk: rlwe.NewRelinKey(params.Parameters, 1), Rtks: rlwe.NewRotationKeySet(params.Parameters, galEls)}
+		return nodes[i].RunEKG(&ekg.EKGProtocol, skShares[i], ekg.SampleCRP(prng), evks[i])
+	}))
+
+	for i := range ids {
+		require.True(t, pks[0].Equals(pks[i]))
+	}
+
+	encoder := bfv.NewEncoder(params)
+	coeffs := make([]uint64, params.N())
+	for i := range coeffs {
+		coeffs[i] = uint64(i) % 16
+	}
+	pt := bfv.NewPlaintext(params)
+	encoder.EncodeUint(coeffs, pt)
+
+	// Evaluates the square and a column rotation with the collective keys.
+	eval := bfv.NewEvaluator(params, rlwe.EvaluationKey{Rlk: rlks[0], Rtks: rtks[0]})
+	ct := bfv.NewEncryptor(params, pks[0]).EncryptNew(pt)
+	ct = eval.MulNew(ct, ct)
+	eval.Relinearize(ct, ct)
+	ct = eval.RotateColumnsNew(ct, 1)
+
+	half := params.N() >> 1
+	want := make([]uint64, params.N())
+	for i := range want {
+		j := (i/half)*half + (i%half+1)%half
+		want[i] = (coeffs[j] * coeffs[j]) % params.T()
+	}
+
+	dec := bfv.NewDecryptor(params, skIdeal)
+	require.Equal(t, want, encoder.DecodeUintNew(dec.DecryptNew(ct)))
+
+	// Same evaluation with the keys generated in two rounds by the EKG protocol.
+	ctEKG := bfv.NewEncryptor(params, pks[0]).EncryptNew(pt)
+	evalEKG := bfv.NewEvaluator(params, evks[len(ids)-1])
+	ctEKG = evalEKG.MulNew(ctEKG, ctEKG)
+	evalEKG.Relinearize(ctEKG, ctEKG)
+	ctEKG = evalEKG.RotateColumnsNew(ctEKG, 1)
+	require.Equal(t, want, encoder.DecodeUintNew(dec.DecryptNew(ctEKG)))
+
+	refreshed := make([]*bfv.Ciphertext, len(ids))
+	switched := make([]*bfv.Ciphertext, len(ids))
+	pkSwitched := make([]*bfv.Ciphertext, len(ids))
+	pkOut := bfv.NewKeyGenerator(params).GenPublicKey(skOutIdeal)
+
+	require.NoError(t, run(ids, func(i int, id PartyID) (err error) {
+
+		prng, _ := utils.NewKeyedPRNG([]byte{'r', 'e', 'f'})
+
+		rfp := dbfv.NewRefreshProtocol(params, 3.2)
+		refreshed[i] = bfv.NewCiphertext(params, 1)
+		if err = nodes[i].RunRefreshBFV(rfp, skShares[i], rfp.SampleCRP(params.MaxLevel(), prng), ct, refreshed[i]); err != nil {
+			return
+		}
+
+		cks := dbfv.NewCKSProtocol(params, 3.2)
+		switched[i] = bfv.NewCiphertext(params, 1)
+		if err = nodes[i].RunCKS(&cks.CKSProtocol, skShares[i], skOutShares[i], refreshed[i].Ciphertext, switched[i].Ciphertext); err != nil {
+			return
+		}
+
+		pcks := dbfv.NewPCKSProtocol(params, 3.2)
+		pkSwitched[i] = bfv.NewCiphertext(params, 1)
+		return nodes[i].RunPCKS(&pcks.PCKSProtocol, skShares[i], pkOut, ct.Ciphertext, pkSwitched[i].Ciphertext)
+	}))
+
+	require.Equal(t, want, encoder.DecodeUintNew(dec.DecryptNew(refreshed[0])))
+
+	decOut := bfv.NewDecryptor(params, skOutIdeal)
+	for i := range ids {
+		require.Equal(t, want, encoder.DecodeUintNew(decOut.DecryptNew(switched[i])))
+		require.Equal(t, want, encoder.DecodeUintNew(decOut.DecryptNew(pkSwitched[i])))
+	}
+}
+
+func TestRefreshCKKS(t *testing.T) {
+
+	params, err := ckks.NewParametersFromLiteral(ckks.PN14QP438)
+	require.NoError(t, err)
+
+	ids := partyIDs(3)
+	transports := NewLocalTransports(ids)
+	topology := NewTreeTopology(ids, 2)
+
+	minLevel, logBound, ok := dckks.GetMinimumLevelForBootstrapping(128, params.DefaultScale(), len(ids), params.Q())
+	if !ok || minLevel+1 > params.MaxLevel() {
+		t.Skip("not enough levels to ensure correctness and 128-bit security")
+	}
+
+	skShares, skIdeal := genSecretKeys(params.Parameters, len(ids))
+
+	values := make([]complex128, params.Slots())
+	for i := range values {
+		values[i] = complex(utils.RandFloat64(-1, 1), utils.RandFloat64(-1, 1))
+	}
+
+	encoder := ckks.NewEncoder(params)
+	pt := encoder.EncodeNew(values, minLevel, params.DefaultScale(), params.LogSlots())
+	ct := ckks.NewEncryptor(params, skIdeal).EncryptNew(pt)
+
+	refreshed := make([]*ckks.Ciphertext, len(ids))
+	require.NoError(t, run(ids, func(i int, id PartyID) (err error) {
+		prng, _ := utils.NewKeyedPRNG([]byte{'r', 'e', 'f'})
+		rfp := dckks.NewRefreshProtocol(params, logBound, 3.2)
+		refreshed[i] = ckks.NewCiphertext(params, 1, params.MaxLevel(), params.DefaultScale())
+		return NewParty(transports[id], topology, 10*time.Second).RunRefreshCKKS(rfp, skShares[i], logBound, params.LogSlots(), rfp.SampleCRP(params.MaxLevel(), prng), ct, refreshed[i])
+	}))
+
+	for i := range ids {
+		require.Equal(t, params.MaxLevel(), refreshed[i].Level())
+		have := encoder.Decode(ckks.NewDecryptor(params, skIdeal).DecryptNew(refreshed[i]), params.LogSlots())
+		for j := range values {
+			require.Less(t, math.Abs(real(have[j])-real(values[j])), 1e-3)
+			require.Less(t, math.Abs(imag(have[j])-imag(values[j])), 1e-3)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package network
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tuneinsight/lattigo/v3/bfv"
+	"github.com/tuneinsight/lattigo/v3/ckks"
+	"github.com/tuneinsight/lattigo/v3/dbfv"
+	"github.com/tuneinsight/lattigo/v3/dckks"
+	"github.com/tuneinsight/lattigo/v3/drlwe"
+	"github.com/tuneinsight/lattigo/v3/rlwe"
+)
+
+// Share is an interface for the shares of the multiparty protocols.
+type Share = drlwe.Share
+
+// Party is a party running the multiparty protocols over a Transport, aggregating the shares along a Topology.
+// All the parties must run the same sequence of protocols, in the same order.
+type Party struct {
+	Transport Transport
+	Topology  Topology
+	// Timeout is the maximum time waited for each message. A non-positive timeout waits indefinitely.
+	Timeout time.Duration
+
+	session uint64
+}
+
+// NewParty creates a new Party communicating over the given transport and topology.
+func NewParty(transport Transport, topology Topology, timeout time.Duration) *Party {
+	return &Party{Transport: transport, Topology: topology, Timeout: timeout}
+}
+
+// ID returns the identifier of the party.
+func (p *Party) ID() PartyID {
+	return p.Transport.ID()
+}
+
+// Aggregate aggregates the shares of all the parties along the topology. On input, share must contain the
+// share of the party and on output it contains the aggregation of the shares of all the parties.
+// newShare must return an allocated share and aggregate must aggregate share1 and share2 into shareOut.
+func (p *Party) Aggregate(share Share, newShare func() Share, aggregate func(share1, share2, shareOut Share)) (err error) {
+
+	p.session++
+	up := fmt.Sprintf("%d/share", p.session)
+	down := fmt.Sprintf("%d/aggregate", p.session)
+
+	var data []byte
+
+	for _, child := range p.Topology.Children(p.ID()) {
+		if data, err = p.Transport.Receive(child, up, p.Timeout); err != nil {
+			return err
+		}
+		childShare := newShare()
+		if err = childShare.UnmarshalBinary(data); err != nil {
+			return fmt.Errorf("network: invalid share from party %d: %w", child, err)
+		}
+		aggregate(share, childShare, share)
+	}
+
+	if parent, ok := p.Topology.Parent(p.ID()); ok {
+
+		if data, err = share.MarshalBinary(); err != nil {
+			return err
+		}
+
+		if err = p.Transport.Send(parent, up, data); err != nil {
+			return err
+		}
+
+		if data, err = p.Transport.Receive(parent, down, p.Timeout); err != nil {
+			return err
+		}
+
+		if err = share.UnmarshalBinary(data); err != nil {
+			return fmt.Errorf("network: invalid aggregated share from party %d: %w", parent, err)
+		}
+	}
+
+	if children := p.Topology.Children(p.ID()); len(children) > 0 {
+
+		if data, err = share.MarshalBinary(); err != nil {
+			return err
+		}
+
+		for _, child := range children {
+			if err = p.Transport.Send(child, down, data); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// RunCKG runs the collective public key generation protocol and writes the collective public key on pkOut.
+func (p *Party) RunCKG(ckg *drlwe.CKGProtocol, sk *rlwe.SecretKey, crp drlwe.CKGCRP, pkOut *rlwe.PublicKey) (err error) {
+
+	share := ckg.AllocateShare()
+	ckg.GenShare(sk, crp, share)
+
+	if err = p.Aggregate(share,
+		func() Share { return ckg.AllocateShare() },
+		func(share1, share2, shareOut Share) {
+			ckg.AggregateShare(share1.(*drlwe.CKGShare), share2.(*drlwe.CKGShare), shareOut.(*drlwe.CKGShare))
+		}); err != nil {
+		return err
+	}
+
+	ckg.GenPublicKey(share, crp, pkOut)
+	return nil
+}
+
+// RunRKG runs the two rounds of the collective relinearization key generation protocol and writes
+// the collective relinearization key on rlkOut.
+func (p *Party) RunRKG(rkg *drlwe.RKGProtocol, sk *rlwe.SecretKey, crp drlwe.RKGCRP, rlkOut *rlwe.RelinearizationKey) (err error) {
+
+	ephSk, round1, round2 := rkg.AllocateShare()
+
+	newShare := func() Share {
+		_, share, _ := rkg.AllocateShare()
+		return share
+	}
+
+	aggregate := func(share1, share2, shareOut Share) {
+		rkg.AggregateShare(share1.(*drlwe.RKGShare), share2.(*drlwe.RKGShare), shareOut.(*drlwe.RKGShare))
+	}
+
+	rkg.GenShareRoundOne(sk, crp, ephSk, round1)
+
+	if err = p.Aggregate(round1, newShare, aggregate); err != nil {
+		return err
+	}
+
+	rkg.GenShareRoundTwo(ephSk, sk, round1, round2)
+
+	if err = p.Aggregate(round2, newShare, aggregate); err != nil {
+		return err
+	}
+
+	rkg.GenRelinearizationKey(round1, round2, rlkOut)
+	return nil
+}
+
+// RunRTG runs the collective rotation key generation protocol for the Galois element galEl
+// and writes the collective rotation key on rtkOut.
+func (p *Party) RunRTG(rtg *drlwe.RTGProtocol, sk *rlwe.SecretKey, galEl uint64, crp drlwe.RTGCRP, rtkOut *rlwe.SwitchingKey) (err error) {
+
+	share := rtg.AllocateShare()
+	rtg.GenShare(sk, galEl, crp, share)
+
+	if err = p.Aggregate(share,
+		func() Share { return rtg.AllocateShare() },
+		func(share1, share2, shareOut Share) {
+			rtg.AggregateShare(share1.(*drlwe.RTGShare), share2.(*drlwe.RTGShare), shareOut.(*drlwe.RTGShare))
+		}); err != nil {
+		return err
+	}
+
+	rtg.GenRotationKey(share, crp, rtkOut)
+	return nil
+}
+
+// RunEKG runs the two rounds of the collective evaluation-key generation protocol and writes the collective keys on evk.
+// evk.Rlk must be allocated if the protocol generates the relinearization key and evk.Rtks must be allocated for all
+// the Galois elements of the protocol.
+func (p *Party) RunEKG(ekg *drlwe.EKGProtocol, sk *rlwe.SecretKey, crp drlwe.EKGCRP, evk rlwe.EvaluationKey) (err error) {
+
+	ephSk, round1, round2 := ekg.AllocateShare()
+	ekg.GenShareRoundOne(sk, crp, ephSk, round1)
+
+	if err = p.Aggregate(ekgShareRoundOne{round1, ekg},
+		func() Share {
+			_, share, _ := ekg.AllocateShare()
+			return ekgShareRoundOne{share, ekg}
+		},
+		func(share1, share2, shareOut Share) {
+			ekg.AggregateShareRoundOne(share1.(ekgShareRoundOne).EKGShareRoundOne, share2.(ekgShareRoundOne).EKGShareRoundOne, shareOut.(ekgShareRoundOne).EKGShareRoundOne)
+		}); err != nil {
+		return err
+	}
+
+	if round2 != nil {
+
+		ekg.GenShareRoundTwo(ephSk, sk, round1, round2)
+
+		if err = p.Aggregate(round2,
+			func() Share {
+				_, _, share := ekg.AllocateShare()
+				return share
+			},
+			func(share1, share2, shareOut Share) {
+				ekg.AggregateShareRoundTwo(share1.(*drlwe.RKGShare), share2.(*drlwe.RKGShare), shareOut.(*drlwe.RKGShare))
+			}); err != nil {
+			return err
+		}
+	}
+
+	ekg.GenEvaluationKey(round1, round2, crp, evk)
+	return nil
+}
+
+// ekgShareRoundOne is a first-round share of the EKG protocol which is checked against the protocol when it is
+// decoded, so that the shares received from the other parties with missing or unexpected keys are rejected.
+type ekgShareRoundOne struct {
+	*drlwe.EKGShareRoundOne
+	ekg *drlwe.EKGProtocol
+}
+
+// UnmarshalBinary decodes a slice of bytes on the target element and checks it against the protocol.
+func (share ekgShareRoundOne) UnmarshalBinary(data []byte) (err error) {
+	if err = share.EKGShareRoundOne.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	return share.ekg.CheckShareRoundOne(share.EKGShareRoundOne)
+}
+
+// RunCKS runs the collective key-switching protocol from skIn to skOut on ctIn and writes the result on ctOut.
+func (p *Party) RunCKS(cks *drlwe.CKSProtocol, skIn, skOut *rlwe.SecretKey, ctIn, ctOut *rlwe.Ciphertext) (err error) {
+
+	level := ctIn.Level()
+
+	share := cks.AllocateShare(level)
+	cks.GenShare(skIn, skOut, ctIn.Value[1], share)
+
+	if err = p.Aggregate(share,
+		func() Share { return cks.AllocateShare(level) },
+		func(share1, share2, shareOut Share) {
+			cks.AggregateShare(share1.(*drlwe.CKSShare), share2.(*drlwe.CKSShare), shareOut.(*drlwe.CKSShare))
+		}); err != nil {
+		return err
+	}
+
+	cks.KeySwitch(ctIn, share, ctOut)
+	return nil
+}
+
+// RunPCKS runs the collective public key-switching protocol from sk to pkOut on ctIn and writes the result on ctOut.
+func (p *Party) RunPCKS(pcks *drlwe.PCKSProtocol, sk *rlwe.SecretKey, pkOut *rlwe.PublicKey, ctIn, ctOut *rlwe.Ciphertext) (err error) {
+
+	level := ctIn.Level()
+
+	share := pcks.AllocateShare(level)
+	pcks.GenShare(sk, pkOut, ctIn.Value[1], share)
+
+	if err = p.Aggregate(share,
+		func() Share { return pcks.AllocateShare(level) },
+		func(share1, share2, shareOut Share) {
+			pcks.AggregateShare(share1.(*drlwe.PCKSShare), share2.(*drlwe.PCKSShare), shareOut.(*drlwe.PCKSShare))
+		}); err != nil {
+		return err
+	}
+
+	pcks.KeySwitch(ctIn, share, ctOut)
+	return nil
+}
+
+// RunRefreshBFV runs the dbfv collective refresh protocol on ctIn and writes the result on ctOut.
+func (p *Party) RunRefreshBFV(rfp *dbfv.RefreshProtocol, sk *rlwe.SecretKey, crp drlwe.CKSCRP, ctIn, ctOut *bfv.Ciphertext) (err error) {
+
+	share := rfp.AllocateShare()
+	rfp.GenShare(sk, ctIn.Value[1], crp, share)
+
+	if err = p.Aggregate(share,
+		func() Share { return rfp.AllocateShare() },
+		func(share1, share2, shareOut Share) {
+			rfp.Aggregate(share1.(*dbfv.RefreshShare), share2.(*dbfv.RefreshShare), shareOut.(*dbfv.RefreshShare))
+		}); err != nil {
+		return err
+	}
+
+	rfp.Finalize(ctIn, crp, share, ctOut)
+	return nil
+}
+
+// RunRefreshCKKS runs the dckks collective refresh protocol on ctIn and writes the result on ctOut.
+// The refreshed ciphertext is returned at the level of ctOut. logBound should be obtained with
+// dckks.GetMinimumLevelForBootstrapping.
+func (p *Party) RunRefreshCKKS(rfp *dckks.RefreshProtocol, sk *rlwe.SecretKey, logBound, logSlots int, crp drlwe.CKSCRP, ctIn, ctOut *ckks.Ciphertext) (err error) {
+
+	levelIn, levelOut := ctIn.Level(), ctOut.Level()
+
+	share := rfp.AllocateShare(levelIn, levelOut)
+	rfp.GenShare(sk, logBound, logSlots, ctIn.Value[1], ctIn.Scale, crp, share)
+
+	if err = p.Aggregate(share,
+		func() Share { return rfp.AllocateShare(levelIn, levelOut) },
+		func(share1, share2, shareOut Share) {
+			rfp.AggregateShare(share1.(*dckks.RefreshShare), share2.(*dckks.RefreshShare), shareOut.(*dckks.RefreshShare))
+		}); err != nil {
+		return err
+	}
+
+	rfp.Finalize(ctIn, logSlots, crp, share, ctOut)
+	return nil
+}
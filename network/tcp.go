@@ -0,0 +1,258 @@
+package network
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// DefaultMaxFrameSize is the default maximum size, in bytes, of the tag and the payload of a frame
+// accepted by a TCPTransport.
+const DefaultMaxFrameSize = 1 << 30
+
+// TCPTransport is a Transport over TCP connections. Each message is sent as a frame
+// [sender id | tag length | tag | payload length | payload] over a connection dialed lazily
+// to the recipient, and redialed if it is broken. The transport does not provide confidentiality
+// nor authentication of the channels, which must be ensured by the underlying network if required.
+// The connections on which a frame larger than the maximum frame size is announced are closed.
+type TCPTransport struct {
+	id       PartyID
+	listener net.Listener
+	mailbox  *mailbox
+
+	mu           sync.Mutex
+	peers        map[PartyID]string
+	conns        map[PartyID]*tcpConn
+	maxFrameSize uint64
+
+	inbound map[net.Conn]struct{}
+	wg      sync.WaitGroup
+}
+
+type tcpConn struct {
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewTCPTransport creates a new TCPTransport for the party id listening on the given address
+// (e.g. "127.0.0.1:0"). The addresses of the other parties must be set with SetPeers before sending.
+func NewTCPTransport(id PartyID, address string) (t *TCPTransport, err error) {
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	t = &TCPTransport{
+		id:       id,
+		listener: listener,
+		mailbox:  newMailbox(),
+		peers:    make(map[PartyID]string),
+		conns:    make(map[PartyID]*tcpConn),
+		inbound:  make(map[net.Conn]struct{}),
+
+		maxFrameSize: DefaultMaxFrameSize,
+	}
+
+	t.wg.Add(1)
+	go t.accept()
+
+	return t, nil
+}
+
+// Addr returns the address on which the transport listens.
+func (t *TCPTransport) Addr() string {
+	return t.listener.Addr().String()
+}
+
+// SetPeers sets the addresses of the other parties.
+func (t *TCPTransport) SetPeers(peers map[PartyID]string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, addr := range peers {
+		t.peers[id] = addr
+	}
+}
+
+// SetMaxFrameSize sets the maximum size, in bytes, of the tag and the payload of the frames sent and
+// received by the transport. The default is DefaultMaxFrameSize.
+func (t *TCPTransport) SetMaxFrameSize(size int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.maxFrameSize = uint64(size)
+}
+
+func (t *TCPTransport) getMaxFrameSize() uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.maxFrameSize
+}
+
+// ID returns the identifier of the party owning the transport.
+func (t *TCPTransport) ID() PartyID {
+	return t.id
+}
+
+// Send sends payload to the party to under the given tag.
+func (t *TCPTransport) Send(to PartyID, tag string, payload []byte) (err error) {
+
+	if size := uint64(len(tag)) + uint64(len(payload)); size > t.getMaxFrameSize() {
+		return fmt.Errorf("network: sending %q to party %d: frame of %d bytes exceeds the maximum frame size", tag, to, size)
+	}
+
+	frame := make([]byte, 8+4+len(tag)+8+len(payload))
+	binary.LittleEndian.PutUint64(frame[0:], uint64(t.id))
+	binary.LittleEndian.PutUint32(frame[8:], uint32(len(tag)))
+	copy(frame[12:], tag)
+	binary.LittleEndian.PutUint64(frame[12+len(tag):], uint64(len(payload)))
+	copy(frame[20+len(tag):], payload)
+
+	// A cached connection may have been closed by the peer, in which case it is
+	// dropped and the frame is sent again on a new connection.
+	for attempt := 0; attempt < 2; attempt++ {
+
+		var c *tcpConn
+		if c, err = t.conn(to); err != nil {
+			return err
+		}
+
+		c.mu.Lock()
+		_, err = c.conn.Write(frame)
+		c.mu.Unlock()
+
+		if err == nil {
+			return nil
+		}
+
+		t.dropConn(to, c)
+	}
+
+	return fmt.Errorf("network: sending %q to party %d: %w", tag, to, err)
+}
+
+// Receive blocks until the message with the given tag from the party from is received.
+func (t *TCPTransport) Receive(from PartyID, tag string, timeout time.Duration) ([]byte, error) {
+	return t.mailbox.receive(from, tag, timeout)
+}
+
+// Close closes the listener and all the connections of the transport.
+func (t *TCPTransport) Close() (err error) {
+	t.mailbox.close()
+	err = t.listener.Close()
+
+	t.mu.Lock()
+	for _, c := range t.conns {
+		c.conn.Close()
+	}
+	for conn := range t.inbound {
+		conn.Close()
+	}
+	t.mu.Unlock()
+
+	t.wg.Wait()
+	return err
+}
+
+func (t *TCPTransport) conn(to PartyID) (*tcpConn, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if c, ok := t.conns[to]; ok {
+		return c, nil
+	}
+
+	addr, ok := t.peers[to]
+	if !ok {
+		return nil, fmt.Errorf("network: unknown party %d", to)
+	}
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &tcpConn{conn: conn}
+	t.conns[to] = c
+	return c, nil
+}
+
+// dropConn closes the connection c to the party to and removes it from the cache.
+func (t *TCPTransport) dropConn(to PartyID, c *tcpConn) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.conns[to] == c {
+		delete(t.conns, to)
+	}
+	c.conn.Close()
+}
+
+func (t *TCPTransport) accept() {
+	defer t.wg.Done()
+	for {
+		conn, err := t.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		t.mu.Lock()
+		t.inbound[conn] = struct{}{}
+		t.mu.Unlock()
+
+		t.wg.Add(1)
+		go t.read(conn)
+	}
+}
+
+func (t *TCPTransport) read(conn net.Conn) {
+	defer t.wg.Done()
+	defer func() {
+		t.mu.Lock()
+		delete(t.inbound, conn)
+		t.mu.Unlock()
+		conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	header := make([]byte, 12)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return
+		}
+
+		maxFrameSize := t.getMaxFrameSize()
+
+		from := PartyID(binary.LittleEndian.Uint64(header[0:]))
+
+		tagLen := uint64(binary.LittleEndian.Uint32(header[8:]))
+		if tagLen > maxFrameSize {
+			return
+		}
+
+		tag := make([]byte, tagLen)
+		if _, err := io.ReadFull(r, tag); err != nil {
+			return
+		}
+
+		if _, err := io.ReadFull(r, header[:8]); err != nil {
+			return
+		}
+
+		payloadLen := binary.LittleEndian.Uint64(header[:8])
+		if payloadLen > maxFrameSize-tagLen {
+			return
+		}
+
+		payload := make([]byte, payloadLen)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return
+		}
+
+		if err := t.mailbox.put(from, string(tag), payload); err != nil {
+			return
+		}
+	}
+}
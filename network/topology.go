@@ -0,0 +1,58 @@
+package network
+
+// Topology is an interface for the aggregation topologies of the shares. Each party aggregates the shares
+// of its children with its own share and forwards the result to its parent. The root obtains the
+// aggregation of all the shares, which is then broadcast back down the topology.
+type Topology interface {
+	// Parent returns the parent of the party id, and false if id is the root.
+	Parent(id PartyID) (parent PartyID, ok bool)
+	// Children returns the children of the party id.
+	Children(id PartyID) []PartyID
+}
+
+type treeTopology struct {
+	parent   map[PartyID]PartyID
+	children map[PartyID][]PartyID
+}
+
+// NewTreeTopology returns a complete tree Topology of the given arity over the parties ids,
+// rooted at ids[0], in which the parent of ids[i] is ids[(i-1)/arity].
+func NewTreeTopology(ids []PartyID, arity int) Topology {
+
+	if arity < 1 {
+		panic("cannot NewTreeTopology: arity must be at least 1")
+	}
+
+	t := &treeTopology{
+		parent:   make(map[PartyID]PartyID, len(ids)),
+		children: make(map[PartyID][]PartyID, len(ids)),
+	}
+
+	for i := 1; i < len(ids); i++ {
+		parent := ids[(i-1)/arity]
+		t.parent[ids[i]] = parent
+		t.children[parent] = append(t.children[parent], ids[i])
+	}
+
+	return t
+}
+
+// NewStarTopology returns a star Topology over the parties ids, in which ids[0]
+// is the aggregator and all the other parties are its children.
+func NewStarTopology(ids []PartyID) Topology {
+	if len(ids) < 2 {
+		return NewTreeTopology(ids, 1)
+	}
+	return NewTreeTopology(ids, len(ids)-1)
+}
+
+// Parent returns the parent of the party id, and false if id is the root.
+func (t *treeTopology) Parent(id PartyID) (parent PartyID, ok bool) {
+	parent, ok = t.parent[id]
+	return
+}
+
+// Children returns the children of the party id.
+func (t *treeTopology) Children(id PartyID) []PartyID {
+	return t.children[id]
+}
@@ -0,0 +1,225 @@
+package ring
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// DistributionType is the type of a distribution of polynomial coefficients.
+type DistributionType int
+
+// TernaryProbability, TernaryHammingWeight, Binary, UniformRange and DiscreteGaussian are the types of distributions.
+// The zero value is not a valid distribution type.
+const (
+	TernaryProbability   = DistributionType(1) // coefficients in {-1, 0, 1}, with probability P of being 0
+	TernaryHammingWeight = DistributionType(2) // coefficients in {-1, 0, 1}, with exactly H non-zero coefficients
+	Binary               = DistributionType(3) // coefficients uniform in {0, 1}
+	UniformRange         = DistributionType(4) // coefficients uniform in [Min, Max]
+	DiscreteGaussian     = DistributionType(5) // discrete Gaussian coefficients of standard deviation Sigma truncated at Bound
+)
+
+// String returns the string representation of the DistributionType.
+func (dt DistributionType) String() string {
+	switch dt {
+	case TernaryProbability:
+		return "TernaryProbability"
+	case TernaryHammingWeight:
+		return "TernaryHammingWeight"
+	case Binary:
+		return "Binary"
+	case UniformRange:
+		return "UniformRange"
+	case DiscreteGaussian:
+		return "DiscreteGaussian"
+	default:
+		return "Invalid"
+	}
+}
+
+// UnmarshalJSON reads a JSON byte slice into the receiver DistributionType.
+func (dt *DistributionType) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	switch s {
+	default:
+		return fmt.Errorf("invalid distribution type: %s", s)
+	case "TernaryProbability":
+		*dt = TernaryProbability
+	case "TernaryHammingWeight":
+		*dt = TernaryHammingWeight
+	case "Binary":
+		*dt = Binary
+	case "UniformRange":
+		*dt = UniformRange
+	case "DiscreteGaussian":
+		*dt = DiscreteGaussian
+	}
+
+	return nil
+}
+
+// MarshalJSON marshals the receiver DistributionType into a JSON []byte.
+func (dt DistributionType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(dt.String())
+}
+
+// Distribution is a serializable description of a distribution of polynomial coefficients. The fields that are
+// used depend on the Type:
+//
+//	TernaryProbability:   P, the probability of a coefficient being 0, in ]0, 1[
+//	TernaryHammingWeight: H, the number of non-zero coefficients
+//	Binary:               none
+//	UniformRange:         Min and Max, the bounds of the range
+//	DiscreteGaussian:     Sigma, the standard deviation, and Bound, the maximum norm of the coefficients
+//
+// The unused fields must be left to their zero value.
+type Distribution struct {
+	Type  DistributionType
+	P     float64 `json:",omitempty"`
+	H     int     `json:",omitempty"`
+	Min   int64   `json:",omitempty"`
+	Max   int64   `json:",omitempty"`
+	Sigma float64 `json:",omitempty"`
+	Bound float64 `json:",omitempty"`
+}
+
+// Validate checks that the receiver is a valid distribution of the coefficients of polynomials of degree N.
+func (d Distribution) Validate(N int) error {
+
+	unused := func(fields ...bool) error {
+		for _, isSet := range fields {
+			if isSet {
+				return fmt.Errorf("invalid %s distribution: unused fields must be zero", d.Type)
+			}
+		}
+		return nil
+	}
+
+	switch d.Type {
+	case TernaryProbability:
+		if !(d.P > 0 && d.P < 1) {
+			return fmt.Errorf("invalid %s distribution: P=%f is not in ]0, 1[", d.Type, d.P)
+		}
+		return unused(d.H != 0, d.Min != 0, d.Max != 0, d.Sigma != 0, d.Bound != 0)
+	case TernaryHammingWeight:
+		if d.H < 0 || d.H > N {
+			return fmt.Errorf("invalid %s distribution: H=%d is not in [0, %d]", d.Type, d.H, N)
+		}
+		return unused(d.P != 0, d.Min != 0, d.Max != 0, d.Sigma != 0, d.Bound != 0)
+	case Binary:
+		return unused(d.P != 0, d.H != 0, d.Min != 0, d.Max != 0, d.Sigma != 0, d.Bound != 0)
+	case UniformRange:
+		if d.Min > d.Max {
+			return fmt.Errorf("invalid %s distribution: Min=%d is larger than Max=%d", d.Type, d.Min, d.Max)
+		}
+		return unused(d.P != 0, d.H != 0, d.Sigma != 0, d.Bound != 0)
+	case DiscreteGaussian:
+		if d.Sigma < 0 || d.Bound < 0 || math.IsNaN(d.Sigma) || math.IsNaN(d.Bound) {
+			return fmt.Errorf("invalid %s distribution: Sigma=%f and Bound=%f must be non-negative", d.Type, d.Sigma, d.Bound)
+		}
+		return unused(d.P != 0, d.H != 0, d.Min != 0, d.Max != 0)
+	default:
+		return fmt.Errorf("invalid distribution type %d", d.Type)
+	}
+}
+
+// NewSampler returns a new sampler of polynomials of the given ring with coefficients following the receiver
+// distribution. The samplerType is the algorithm used for the DiscreteGaussian type and is ignored otherwise.
+// The method panics if the receiver is not a valid distribution (see Validate).
+func (d Distribution) NewSampler(prng utils.PRNG, baseRing *Ring, samplerType GaussianSamplerType) DistributionSampler {
+
+	if err := d.Validate(baseRing.N); err != nil {
+		panic(err)
+	}
+
+	switch d.Type {
+	case TernaryProbability:
+		return NewTernarySampler(prng, baseRing, d.P, false)
+	case TernaryHammingWeight:
+		return NewTernarySamplerWithHammingWeight(prng, baseRing, d.H, false)
+	case Binary:
+		return NewBinarySampler(prng, baseRing)
+	case UniformRange:
+		return NewUniformRangeSampler(prng, baseRing, d.Min, d.Max)
+	default:
+		return NewDiscreteGaussianSampler(prng, baseRing, d.Sigma, d.Bound, samplerType)
+	}
+}
+
+// NormBound returns the largest absolute value of a coefficient sampled from the receiver distribution.
+func (d Distribution) NormBound() float64 {
+	switch d.Type {
+	case TernaryProbability, TernaryHammingWeight, Binary:
+		return 1
+	case UniformRange:
+		return math.Max(math.Abs(float64(d.Min)), math.Abs(float64(d.Max)))
+	case DiscreteGaussian:
+		return d.Bound
+	default:
+		return 0
+	}
+}
+
+// MarshalBinarySize returns the length of the []byte encoding of the receiver.
+func (d Distribution) MarshalBinarySize() int {
+	return 17
+}
+
+// MarshalBinary returns a []byte representation of the receiver.
+func (d Distribution) MarshalBinary() ([]byte, error) {
+
+	// 1 byte : Type
+	// 8 byte : P, H, Min or Sigma
+	// 8 byte : Max or Bound
+	var x, y uint64
+	switch d.Type {
+	case TernaryProbability:
+		x = math.Float64bits(d.P)
+	case TernaryHammingWeight:
+		x = uint64(d.H)
+	case UniformRange:
+		x, y = uint64(d.Min), uint64(d.Max)
+	case DiscreteGaussian:
+		x, y = math.Float64bits(d.Sigma), math.Float64bits(d.Bound)
+	}
+
+	b := utils.NewBuffer(make([]byte, 0, d.MarshalBinarySize()))
+	b.WriteUint8(uint8(d.Type))
+	b.WriteUint64(x)
+	b.WriteUint64(y)
+	return b.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a []byte into the receiver.
+func (d *Distribution) UnmarshalBinary(data []byte) error {
+
+	if len(data) < d.MarshalBinarySize() {
+		return fmt.Errorf("invalid ring.Distribution serialization")
+	}
+
+	b := utils.NewBuffer(data)
+	dt := DistributionType(b.ReadUint8())
+	x, y := b.ReadUint64(), b.ReadUint64()
+
+	*d = Distribution{Type: dt}
+	switch dt {
+	case TernaryProbability:
+		d.P = math.Float64frombits(x)
+	case TernaryHammingWeight:
+		d.H = int(x)
+	case Binary:
+	case UniformRange:
+		d.Min, d.Max = int64(x), int64(y)
+	case DiscreteGaussian:
+		d.Sigma, d.Bound = math.Float64frombits(x), math.Float64frombits(y)
+	default:
+		return fmt.Errorf("invalid distribution type %d", dt)
+	}
+
+	return nil
+}
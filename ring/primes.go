@@ -77,8 +77,9 @@ func GenerateNTTPrimesQ(logQ, NthRoot, levels int) (primes []uint64) {
 
 	Qpow2 = uint64(1 << logQ)
 
-	nextPrime = Qpow2 + 1
-	previousPrime = Qpow2 + 1
+	// smallest integer congruent to 1 mod NthRoot after 2^logQ - NthRoot (2^logQ + 1 if NthRoot is a power of two)
+	nextPrime = Qpow2 + 1 - Qpow2%uint64(NthRoot)
+	previousPrime = nextPrime
 
 	checkfornextprime = true
 	checkforpreviousprime = true
@@ -144,7 +145,7 @@ func GenerateNTTPrimesP(logP, NthRoot, n int) (primes []uint64) {
 
 	Ppow2 = uint64(1 << logP)
 
-	x = Ppow2 + 1
+	x = Ppow2 + 1 - Ppow2%uint64(NthRoot)
 
 	for {
 
@@ -17,10 +17,11 @@ import (
 // Type is the type of ring used by the cryptographic scheme
 type Type int
 
-// RingStandard and RingConjugateInvariant are two types of Rings.
+// RingStandard, RingConjugateInvariant and Cyclotomic are the types of Rings.
 const (
 	Standard           = Type(0) // Z[X]/(X^N + 1) (Default)
 	ConjugateInvariant = Type(1) // Z[X+X^-1]/(X^2N + 1)
+	Cyclotomic         = Type(2) // Z[X]/(Phi_M(X)) with N = phi(M)
 )
 
 // String returns the string representation of the ring Type
@@ -30,6 +31,8 @@ func (rt Type) String() string {
 		return "Standard"
 	case ConjugateInvariant:
 		return "ConjugateInvariant"
+	case Cyclotomic:
+		return "Cyclotomic"
 	default:
 		return "Invalid"
 	}
@@ -48,6 +51,8 @@ func (rt *Type) UnmarshalJSON(b []byte) error {
 		*rt = Standard
 	case "ConjugateInvariant":
 		*rt = ConjugateInvariant
+	case "Cyclotomic":
+		*rt = Cyclotomic
 	}
 
 	return nil
@@ -77,6 +82,9 @@ type Ring struct {
 	// Product of the Moduli
 	ModulusBigint *big.Int
 
+	// CRT reconstruction and decomposition parameters
+	crt *CRT
+
 	// Fast reduction parameters
 	BredParams [][]uint64
 	MredParams []uint64
@@ -93,11 +101,12 @@ type Ring struct {
 	NttNInv   []uint64   //[N^-1] mod Qi in Montgomery form
 }
 
-// NewRing creates a new RNS Ring with degree N and coefficient moduli Moduli with Standard NTT. N must be a power of two larger than 8. Moduli should be
+// NewRing creates a new RNS Ring with degree N and coefficient moduli Moduli with Standard NTT, computed with
+// NumberTheoreticTransformerVectorized if the CPU supports its kernels. N must be a power of two larger than 8. Moduli should be
 // a non-empty []uint64 with distinct prime elements. All moduli must also be equal to 1 modulo 2*N.
 // An error is returned with a nil *Ring in the case of non NTT-enabling parameters.
 func NewRing(N int, Moduli []uint64) (r *Ring, err error) {
-	return NewRingWithCustomNTT(N, Moduli, NumberTheoreticTransformerStandard{}, 2*N)
+	return NewRingWithCustomNTT(N, Moduli, standardNTT(), 2*N)
 }
 
 // NewRingConjugateInvariant creates a new RNS Ring with degree N and coefficient moduli Moduli with Conjugate Invariant NTT. N must be a power of two larger than 8. Moduli should be
@@ -115,9 +124,11 @@ func NewRingConjugateInvariant(N int, Moduli []uint64) (r *Ring, err error) {
 func NewRingFromType(N int, Moduli []uint64, ringType Type) (r *Ring, err error) {
 	switch ringType {
 	case Standard:
-		return NewRingWithCustomNTT(N, Moduli, NumberTheoreticTransformerStandard{}, 2*N)
+		return NewRingWithCustomNTT(N, Moduli, standardNTT(), 2*N)
 	case ConjugateInvariant:
 		return NewRingWithCustomNTT(N, Moduli, NumberTheoreticTransformerConjugateInvariant{}, 4*N)
+	case Cyclotomic:
+		return nil, fmt.Errorf("invalid ring type: the degree does not determine a Cyclotomic ring (see NewRingCyclotomic)")
 	default:
 		return nil, fmt.Errorf("invalid ring type")
 	}
@@ -148,8 +159,11 @@ func NewRingWithCustomNTT(N int, Moduli []uint64, ntt NumberTheoreticTransformer
 // if `r.Type()==Standard`, then the method returns a ring with ring degree N/2.
 // The returned Ring is a shallow copy of the receiver.
 func (r *Ring) ConjugateInvariantRing() (*Ring, error) {
-	if r.Type() == ConjugateInvariant {
+	switch r.Type() {
+	case ConjugateInvariant:
 		return r, nil
+	case Cyclotomic:
+		return nil, fmt.Errorf("invalid ring type: Cyclotomic ring has no conjugate invariant ring")
 	}
 	cr := *r
 	cr.N = r.N >> 1
@@ -162,23 +176,28 @@ func (r *Ring) ConjugateInvariantRing() (*Ring, error) {
 // if `r.Type()==ConjugateInvariant`, then the method returns a ring with ring degree 2N.
 // The returned Ring is a shallow copy of the receiver.
 func (r *Ring) StandardRing() (*Ring, error) {
-	if r.Type() == Standard {
+	switch r.Type() {
+	case Standard:
 		return r, nil
+	case Cyclotomic:
+		return nil, fmt.Errorf("invalid ring type: Cyclotomic ring has no standard ring")
 	}
 
 	sr := *r
 	sr.N = r.N << 1
-	sr.NumberTheoreticTransformer = NumberTheoreticTransformerStandard{}
+	sr.NumberTheoreticTransformer = standardNTT()
 	return &sr, sr.genNTTParams(uint64(sr.N) << 1)
 }
 
-// Type returns the Type of the ring which might be either `Standard` or `ConjugateInvariant`.
+// Type returns the Type of the ring which might be either `Standard`, `ConjugateInvariant` or `Cyclotomic`.
 func (r *Ring) Type() Type {
 	switch r.NumberTheoreticTransformer.(type) {
-	case NumberTheoreticTransformerStandard:
+	case NumberTheoreticTransformerStandard, NumberTheoreticTransformerVectorized:
 		return Standard
 	case NumberTheoreticTransformerConjugateInvariant:
 		return ConjugateInvariant
+	case NumberTheoreticTransformerCyclotomic:
+		return Cyclotomic
 	default:
 		panic("invalid NumberTheoreticTransformer type")
 	}
@@ -193,6 +212,17 @@ func (r *Ring) setParameters(N int, Modulus []uint64) error {
 		return errors.New("invalid ring degree (must be a power of 2 >= 8)")
 	}
 
+	return r.setModulus(N, Modulus)
+}
+
+// setModulus sets the degree and the moduli of the ring with the required pre-computed values, without any
+// restriction on the degree other than being a positive multiple of 8.
+func (r *Ring) setModulus(N int, Modulus []uint64) error {
+
+	if N <= 0 || N&7 != 0 {
+		return errors.New("invalid ring degree (must be a positive multiple of 8)")
+	}
+
 	if len(Modulus) == 0 {
 		return errors.New("invalid modulus (must be a non-empty []uint64)")
 	}
@@ -219,6 +249,11 @@ func (r *Ring) setParameters(N int, Modulus []uint64) error {
 		r.ModulusBigint.Mul(r.ModulusBigint, NewUint(qi))
 	}
 
+	var err error
+	if r.crt, err = NewCRT(r.Modulus); err != nil {
+		return err
+	}
+
 	// Compute the fast reduction parameters
 	r.BredParams = make([][]uint64, len(r.Modulus))
 	r.MredParams = make([]uint64, len(r.Modulus))
@@ -265,17 +300,7 @@ func (r *Ring) genNTTParams(NthRoot uint64) error {
 
 	r.NthRoot = NthRoot
 
-	r.RescaleParams = make([][]uint64, len(r.Modulus)-1)
-
-	for j := len(r.Modulus) - 1; j > 0; j-- {
-
-		r.RescaleParams[j-1] = make([]uint64, j)
-
-		for i := 0; i < j; i++ {
-
-			r.RescaleParams[j-1][i] = MForm(r.Modulus[i]-ModExp(r.Modulus[j], r.Modulus[i]-2, r.Modulus[i]), r.Modulus[i], r.BredParams[i])
-		}
-	}
+	r.genRescaleParams()
 
 	r.PsiMont = make([]uint64, len(r.Modulus))
 	r.PsiInvMont = make([]uint64, len(r.Modulus))
@@ -326,17 +351,34 @@ func (r *Ring) genNTTParams(NthRoot uint64) error {
 	return nil
 }
 
+// genRescaleParams computes the constants -qj^-1 mod qi used to divide by the last modulus.
+func (r *Ring) genRescaleParams() {
+
+	r.RescaleParams = make([][]uint64, len(r.Modulus)-1)
+
+	for j := len(r.Modulus) - 1; j > 0; j-- {
+
+		r.RescaleParams[j-1] = make([]uint64, j)
+
+		for i := 0; i < j; i++ {
+
+			r.RescaleParams[j-1][i] = MForm(r.Modulus[i]-ModExp(r.Modulus[j], r.Modulus[i]-2, r.Modulus[i]), r.Modulus[i], r.BredParams[i])
+		}
+	}
+}
+
 // Minimal required information to recover the full ring. Used to import and export the ring.
 type ringParams struct {
 	N       int
 	NthRoot uint64
 	Modulus []uint64
+	Type    Type
 }
 
 // MarshalBinary encodes the target ring on a slice of bytes.
 func (r *Ring) MarshalBinary() ([]byte, error) {
 
-	parameters := ringParams{r.N, r.NthRoot, r.Modulus}
+	parameters := ringParams{r.N, r.NthRoot, r.Modulus, r.Type()}
 
 	var buf bytes.Buffer
 	enc := gob.NewEncoder(&buf)
@@ -357,6 +399,20 @@ func (r *Ring) UnmarshalBinary(data []byte) error {
 		return err
 	}
 
+	switch parameters.Type {
+	case Cyclotomic:
+		rc, err := NewRingCyclotomic(int(parameters.NthRoot), parameters.Modulus)
+		if err != nil {
+			return err
+		}
+		*r = *rc
+		return nil
+	case ConjugateInvariant:
+		r.NumberTheoreticTransformer = NumberTheoreticTransformerConjugateInvariant{}
+	default:
+		r.NumberTheoreticTransformer = standardNTT()
+	}
+
 	if err := r.setParameters(parameters.N, parameters.Modulus); err != nil {
 		return err
 	}
@@ -424,29 +480,12 @@ func (r *Ring) SetCoefficientsString(coeffs []string, p1 *Poly) {
 
 // SetCoefficientsBigint sets the coefficients of p1 from an array of Int variables.
 func (r *Ring) SetCoefficientsBigint(coeffs []*big.Int, p1 *Poly) {
-	QiBigint := new(big.Int)
-	coeffTmp := new(big.Int)
-	for i, Qi := range r.Modulus {
-		QiBigint.SetUint64(Qi)
-		for j, coeff := range coeffs {
-			p1.Coeffs[i][j] = coeffTmp.Mod(coeff, QiBigint).Uint64()
-
-		}
-	}
+	r.crt.DecomposeBigints(len(r.Modulus)-1, coeffs, p1)
 }
 
 // SetCoefficientsBigintLvl sets the coefficients of p1 from an array of Int variables.
 func (r *Ring) SetCoefficientsBigintLvl(level int, coeffs []*big.Int, p1 *Poly) {
-
-	QiBigint := new(big.Int)
-	coeffTmp := new(big.Int)
-	for i := 0; i < level+1; i++ {
-		QiBigint.SetUint64(r.Modulus[i])
-		for j, coeff := range coeffs {
-			p1.Coeffs[i][j] = coeffTmp.Mod(coeff, QiBigint).Uint64()
-
-		}
-	}
+	r.crt.DecomposeBigints(level, coeffs, p1)
 }
 
 // PolyToString reconstructs p1 and returns the result in an array of string.
@@ -475,40 +514,11 @@ func (r *Ring) PolyToBigint(p1 *Poly, gap int, coeffsBigint []*big.Int) {
 // gap defines coefficients X^{i*gap} that will be reconstructed.
 // For example, if gap = 1, then all coefficients are reconstructed, while
 // if gap = 2 then only coefficients X^{2*i} are reconstructed.
+// The nil elements of coeffsBigint are allocated.
 func (r *Ring) PolyToBigintLvl(level int, p1 *Poly, gap int, coeffsBigint []*big.Int) {
-	var qi uint64
-
-	crtReconstruction := make([]*big.Int, level+1)
-
-	QiB := new(big.Int)
-	tmp := new(big.Int)
-	modulusBigint := NewUint(1)
-
-	for i := 0; i < level+1; i++ {
-
-		qi = r.Modulus[i]
-		QiB.SetUint64(qi)
-
-		modulusBigint.Mul(modulusBigint, QiB)
-
-		crtReconstruction[i] = new(big.Int)
-		crtReconstruction[i].Quo(r.ModulusBigint, QiB)
-		tmp.ModInverse(crtReconstruction[i], QiB)
-		tmp.Mod(tmp, QiB)
-		crtReconstruction[i].Mul(crtReconstruction[i], tmp)
-	}
-
-	for i, j := 0, 0; j < r.N; i, j = i+1, j+gap {
-
-		tmp.SetUint64(0)
-		coeffsBigint[i] = new(big.Int)
-
-		for k := 0; k < level+1; k++ {
-			coeffsBigint[i].Add(coeffsBigint[i], tmp.Mul(NewUint(p1.Coeffs[k][j]), crtReconstruction[k]))
-		}
-
-		coeffsBigint[i].Mod(coeffsBigint[i], modulusBigint)
-	}
+	v := r.NewBigintVecLvl(level, (r.N+gap-1)/gap)
+	r.crt.Reconstruct(level, p1, gap, v)
+	v.GetBigints(coeffsBigint[:v.Len()])
 }
 
 // PolyToBigintCenteredLvl reconstructs p1 and returns the result in an array of Int.
@@ -516,51 +526,23 @@ func (r *Ring) PolyToBigintLvl(level int, p1 *Poly, gap int, coeffsBigint []*big
 // gap defines coefficients X^{i*gap} that will be reconstructed.
 // For example, if gap = 1, then all coefficients are reconstructed, while
 // if gap = 2 then only coefficients X^{2*i} are reconstructed.
+// The nil elements of coeffsBigint are allocated.
 func (r *Ring) PolyToBigintCenteredLvl(level int, p1 *Poly, gap int, coeffsBigint []*big.Int) {
-	var qi uint64
-
-	crtReconstruction := make([]*big.Int, level+1)
-
-	QiB := new(big.Int)
-	tmp := new(big.Int)
-	modulusBigint := NewUint(1)
-
-	for i := 0; i < level+1; i++ {
-
-		qi = r.Modulus[i]
-		QiB.SetUint64(qi)
-
-		modulusBigint.Mul(modulusBigint, QiB)
-
-		crtReconstruction[i] = new(big.Int)
-		crtReconstruction[i].Quo(r.ModulusBigint, QiB)
-		tmp.ModInverse(crtReconstruction[i], QiB)
-		tmp.Mod(tmp, QiB)
-		crtReconstruction[i].Mul(crtReconstruction[i], tmp)
-	}
-
-	modulusBigintHalf := new(big.Int)
-	modulusBigintHalf.Rsh(modulusBigint, 1)
-
-	var sign int
-	for i, j := 0, 0; j < r.N; i, j = i+1, j+gap {
-
-		tmp.SetUint64(0)
-		coeffsBigint[i].SetUint64(0)
-
-		for k := 0; k < level+1; k++ {
-			coeffsBigint[i].Add(coeffsBigint[i], tmp.Mul(NewUint(p1.Coeffs[k][j]), crtReconstruction[k]))
-		}
-
-		coeffsBigint[i].Mod(coeffsBigint[i], modulusBigint)
+	v := r.NewBigintVecLvl(level, (r.N+gap-1)/gap)
+	r.crt.ReconstructCentered(level, p1, gap, v)
+	v.GetBigints(coeffsBigint[:v.Len()])
+}
 
-		// Centers the coefficients
-		sign = coeffsBigint[i].Cmp(modulusBigintHalf)
+// CRT returns the pre-computed constants for the conversion between the RNS representation of the
+// polynomials of the ring and their representation as big integers.
+func (r *Ring) CRT() *CRT {
+	return r.crt
+}
 
-		if sign == 1 || sign == 0 {
-			coeffsBigint[i].Sub(coeffsBigint[i], modulusBigint)
-		}
-	}
+// NewBigintVecLvl allocates a new BigintVec of n elements, large enough to store the integers modulo
+// the product of the moduli up to the given level, as well as their sum with up to 2^64 other such integers.
+func (r *Ring) NewBigintVecLvl(level, n int) *BigintVec {
+	return NewBigintVec(n, r.crt.Words(level)+1)
 }
 
 // Equal checks if p1 = p2 in the given Ring.
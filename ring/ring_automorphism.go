@@ -30,6 +30,10 @@ func GenGaloisParams(n, gen uint64) (galElRotCol []uint64) {
 // PermuteNTTIndex computes the index table for PermuteNTT.
 func (r *Ring) PermuteNTTIndex(galEl uint64) (index []uint64) {
 
+	if rntt, isCyclotomic := r.NumberTheoreticTransformer.(NumberTheoreticTransformerCyclotomic); isCyclotomic {
+		return rntt.permuteNTTIndex(galEl)
+	}
+
 	var mask, tmp1, tmp2, logNthRoot uint64
 	logNthRoot = uint64(bits.Len64(r.NthRoot) - 2)
 	mask = r.NthRoot - 1
@@ -116,6 +120,11 @@ func (r *Ring) PermuteNTTWithIndexAndAddNoModLvl(level int, polIn *Poly, index [
 // It must be noted that the result cannot be in-place.
 func (r *Ring) Permute(polIn *Poly, gen uint64, polOut *Poly) {
 
+	if rntt, isCyclotomic := r.NumberTheoreticTransformer.(NumberTheoreticTransformerCyclotomic); isCyclotomic {
+		rntt.permute(r, polIn, gen, polOut)
+		return
+	}
+
 	var mask, index, indexRaw, logN, tmp uint64
 
 	mask = uint64(r.N - 1)
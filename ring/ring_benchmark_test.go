@@ -39,6 +39,7 @@ func BenchmarkRing(b *testing.B) {
 		benchExtendBasis(testContext, b)
 		benchDivByLastModulus(testContext, b)
 		benchDivByRNSBasis(testContext, b)
+		benchCRT(testContext, b)
 		benchMRed(testContext, b)
 		benchBRed(testContext, b)
 		benchBRedAdd(testContext, b)
@@ -141,15 +142,31 @@ func benchNTT(testContext *testParams, b *testing.B) {
 
 	p := testContext.uniformSamplerQ.ReadNew()
 
+	ringQStandard, _ := NewRingWithCustomNTT(testContext.ringQ.N, testContext.ringQ.Modulus, NumberTheoreticTransformerStandard{}, 2*testContext.ringQ.N)
+
 	b.Run(testString("NTT/Forward/Standard/", testContext.ringQ), func(b *testing.B) {
 		for i := 0; i < b.N; i++ {
-			testContext.ringQ.NTT(p, p)
+			ringQStandard.NTT(p, p)
 		}
 	})
 
 	b.Run(testString("NTT/Backward/Standard/", testContext.ringQ), func(b *testing.B) {
 		for i := 0; i < b.N; i++ {
-			testContext.ringQ.InvNTT(p, p)
+			ringQStandard.InvNTT(p, p)
+		}
+	})
+
+	ringQVectorized, _ := NewRingWithCustomNTT(testContext.ringQ.N, testContext.ringQ.Modulus, NumberTheoreticTransformerVectorized{}, 2*testContext.ringQ.N)
+
+	b.Run(testString("NTT/Forward/Vectorized/", testContext.ringQ), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ringQVectorized.NTT(p, p)
+		}
+	})
+
+	b.Run(testString("NTT/Backward/Vectorized/", testContext.ringQ), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ringQVectorized.InvNTT(p, p)
 		}
 	})
 
@@ -395,6 +412,27 @@ func benchDivByRNSBasis(testContext *testParams, b *testing.B) {
 	})
 }
 
+func benchCRT(testContext *testParams, b *testing.B) {
+
+	ringQ := testContext.ringQ
+	level := len(ringQ.Modulus) - 1
+
+	p := testContext.uniformSamplerQ.ReadNew()
+	v := ringQ.NewBigintVecLvl(level, ringQ.N)
+
+	b.Run(testString("CRT/ReconstructCentered/", ringQ), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ringQ.CRT().ReconstructCentered(level, p, 1, v)
+		}
+	})
+
+	b.Run(testString("CRT/Decompose/", ringQ), func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ringQ.CRT().Decompose(level, v, p)
+		}
+	})
+}
+
 func benchBRed(testContext *testParams, b *testing.B) {
 
 	var q, x, y uint64 = 1033576114481528833, 0xFFFFFFFFFFFFFFFF, 0xFFFFFFFFFFFFFFFF
@@ -0,0 +1,434 @@
+package ring
+
+import (
+	"errors"
+	"math/big"
+	"math/bits"
+
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// BigintVec is a vector of signed multi-word integers, stored as
+// sign-magnitude with a fixed number of 64-bit words per element.
+// It is used as an allocation-free intermediate representation between
+// polynomials in the RNS domain and slices of big.Int (see CRT).
+type BigintVec struct {
+	// Words is the number of 64-bit words per element.
+	Words int
+	// Abs stores the absolute values of the elements, each on Words little-endian words.
+	Abs []uint64
+	// Neg stores the signs of the elements.
+	Neg []bool
+}
+
+// NewBigintVec allocates a new BigintVec of n elements of the given number of 64-bit words, all set to zero.
+func NewBigintVec(n, words int) *BigintVec {
+	return &BigintVec{Words: words, Abs: make([]uint64, n*words), Neg: make([]bool, n)}
+}
+
+// Len returns the number of elements of the vector.
+func (v *BigintVec) Len() int {
+	return len(v.Neg)
+}
+
+// Slice returns the sub-vector of the elements start to end-1, which shares its memory with the receiver.
+func (v *BigintVec) Slice(start, end int) *BigintVec {
+	return &BigintVec{Words: v.Words, Abs: v.Abs[start*v.Words : end*v.Words], Neg: v.Neg[start:end]}
+}
+
+// Get sets b to the i-th element of the vector and returns b.
+// Get does not allocate if b has enough capacity.
+func (v *BigintVec) Get(i int, b *big.Int) *big.Int {
+
+	abs := v.Abs[i*v.Words : (i+1)*v.Words]
+
+	n := len(abs)
+	for n > 0 && abs[n-1] == 0 {
+		n--
+	}
+
+	if bits.UintSize == 32 {
+		n <<= 1
+	}
+
+	words := b.Bits()
+	if cap(words) < n {
+		words = make([]big.Word, n)
+	}
+	words = words[:n]
+
+	if bits.UintSize == 64 {
+		for j := range words {
+			words[j] = big.Word(abs[j])
+		}
+	} else {
+		for j := range words {
+			words[j] = big.Word(abs[j>>1] >> (32 * uint(j&1)))
+		}
+	}
+
+	b.SetBits(words)
+
+	if v.Neg[i] {
+		b.Neg(b)
+	}
+
+	return b
+}
+
+// Set sets the i-th element of the vector to b.
+// The method panics if the absolute value of b does not fit on Words 64-bit words.
+func (v *BigintVec) Set(i int, b *big.Int) {
+
+	abs := v.Abs[i*v.Words : (i+1)*v.Words]
+
+	if (b.BitLen()+63)>>6 > len(abs) {
+		panic("cannot Set: value is too large for the number of words of the BigintVec")
+	}
+
+	bigintToWords(b, abs)
+
+	v.Neg[i] = b.Sign() < 0
+}
+
+// GetBigints sets the first len(b) elements of b to the first elements of the vector.
+// The nil elements of b are allocated.
+func (v *BigintVec) GetBigints(b []*big.Int) {
+	for i := range b {
+		if b[i] == nil {
+			b[i] = new(big.Int)
+		}
+		v.Get(i, b[i])
+	}
+}
+
+// SetBigints sets the first len(b) elements of the vector to b.
+// The method panics if one of the values does not fit on Words 64-bit words.
+func (v *BigintVec) SetBigints(b []*big.Int) {
+	for i := range b {
+		v.Set(i, b[i])
+	}
+}
+
+// CRT stores the pre-computed constants for the conversion between the RNS representation
+// of the polynomials and their representation as big integers, at any level of a chain of moduli.
+//
+// The reconstruction uses Garner's algorithm: the residues of a coefficient are first converted
+// into mixed-radix digits, using the pre-computed constants q_j^{-1} mod q_k, which are
+// independent of the level, and the big integer is then obtained by a Horner evaluation on words.
+// The decomposition evaluates each word of the big integer against the pre-computed constants
+// 2^{64j} mod q_k. All the modular multiplications by a constant use Shoup's precomputation.
+type CRT struct {
+	modulus []uint64
+
+	// garner[k][j] = q_j^{-1} mod q_k for j < k
+	garner      [][]uint64
+	garnerShoup [][]uint64
+
+	// oneShoup[k] = floor(2^64/q_k), used to reduce a word modulo q_k
+	oneShoup []uint64
+
+	// pow[k][j] = 2^{64j} mod q_k for 0 <= j <= chunk
+	pow      [][]uint64
+	powShoup [][]uint64
+	chunk    int
+
+	// q[level] = q_0 * ... * q_level and qHalf[level] = floor(q[level]/2), on words(level) words.
+	q     [][]uint64
+	qHalf [][]uint64
+}
+
+// NewCRT creates the CRT constants for the given moduli, which must be pairwise co-prime and smaller than 2^63.
+func NewCRT(moduli []uint64) (crt *CRT, err error) {
+
+	if len(moduli) == 0 {
+		return nil, errors.New("invalid modulus (must be a non-empty []uint64)")
+	}
+
+	crt = new(CRT)
+	crt.modulus = make([]uint64, len(moduli))
+	copy(crt.modulus, moduli)
+
+	crt.garner = make([][]uint64, len(moduli))
+	crt.garnerShoup = make([][]uint64, len(moduli))
+	crt.oneShoup = make([]uint64, len(moduli))
+
+	qk := new(big.Int)
+	qj := new(big.Int)
+	inv := new(big.Int)
+
+	for k, q := range moduli {
+
+		if q < 2 || q>>63 != 0 {
+			return nil, errors.New("invalid modulus (moduli must be in [2, 2^63))")
+		}
+
+		crt.oneShoup[k] = shoupConstant(1, q)
+
+		qk.SetUint64(q)
+
+		crt.garner[k] = make([]uint64, k)
+		crt.garnerShoup[k] = make([]uint64, k)
+
+		for j := 0; j < k; j++ {
+			qj.SetUint64(moduli[j] % q)
+			if inv.ModInverse(qj, qk) == nil {
+				return nil, errors.New("invalid modulus (moduli are not pairwise co-prime)")
+			}
+			crt.garner[k][j] = inv.Uint64()
+			crt.garnerShoup[k][j] = shoupConstant(crt.garner[k][j], q)
+		}
+	}
+
+	crt.q = make([][]uint64, len(moduli))
+	crt.qHalf = make([][]uint64, len(moduli))
+
+	Q := NewUint(1)
+	QHalf := new(big.Int)
+
+	for level, q := range moduli {
+		Q.Mul(Q, qk.SetUint64(q))
+		QHalf.Rsh(Q, 1)
+		words := (Q.BitLen() + 63) >> 6
+		crt.q[level] = NewBigintVec(1, words).Abs
+		crt.qHalf[level] = NewBigintVec(1, words).Abs
+		bigintToWords(Q, crt.q[level])
+		bigintToWords(QHalf, crt.qHalf[level])
+	}
+
+	crt.chunk = len(crt.q[len(moduli)-1])
+
+	crt.pow = make([][]uint64, len(moduli))
+	crt.powShoup = make([][]uint64, len(moduli))
+
+	for k, q := range moduli {
+
+		crt.pow[k] = make([]uint64, crt.chunk+1)
+		crt.powShoup[k] = make([]uint64, crt.chunk+1)
+
+		// 2^64 mod q
+		pow64 := bits.Rem64(1, 0, q)
+
+		crt.pow[k][0] = 1 % q
+		for j := 1; j < crt.chunk+1; j++ {
+			hi, lo := bits.Mul64(crt.pow[k][j-1], pow64)
+			crt.pow[k][j] = bits.Rem64(hi, lo, q)
+		}
+
+		for j := range crt.pow[k] {
+			crt.powShoup[k][j] = shoupConstant(crt.pow[k][j], q)
+		}
+	}
+
+	return
+}
+
+// Words returns the number of 64-bit words needed to store the integers in [0, q_0 * ... * q_level).
+func (crt *CRT) Words(level int) int {
+	return len(crt.q[level])
+}
+
+// Reconstruct reconstructs the coefficients X^{i*gap} of p at the given level on v, with values in [0, q_0 * ... * q_level).
+// The number of coefficients reconstructed is the minimum between v.Len() and ceil(N/gap).
+// The method panics if v.Words is smaller than Words(level).
+func (crt *CRT) Reconstruct(level int, p *Poly, gap int, v *BigintVec) {
+	crt.reconstruct(level, p, gap, v, false)
+}
+
+// ReconstructCentered reconstructs the coefficients X^{i*gap} of p at the given level on v, with values in
+// [-Q/2, Q/2) for Q = q_0 * ... * q_level (values x >= floor(Q/2) are mapped to x - Q).
+// The number of coefficients reconstructed is the minimum between v.Len() and ceil(N/gap).
+// The method panics if v.Words is smaller than Words(level).
+func (crt *CRT) ReconstructCentered(level int, p *Poly, gap int, v *BigintVec) {
+	crt.reconstruct(level, p, gap, v, true)
+}
+
+func (crt *CRT) reconstruct(level int, p *Poly, gap int, v *BigintVec, centered bool) {
+
+	words := crt.Words(level)
+
+	if v.Words < words {
+		panic("cannot Reconstruct: the BigintVec has not enough words for the level")
+	}
+
+	moduli := crt.modulus[:level+1]
+	Q := crt.q[level]
+	QHalf := crt.qHalf[level]
+
+	digits := make([]uint64, level+1)
+
+	var hi, lo, carry, c uint64
+
+	for i, j := 0, 0; i < v.Len() && j < len(p.Coeffs[0]); i, j = i+1, j+gap {
+
+		// Mixed-radix digits
+		for k, qk := range moduli {
+
+			x := p.Coeffs[k][j]
+			if x >= qk {
+				x = reduceShoup(x, 1, crt.oneShoup[k], qk)
+			}
+
+			garner := crt.garner[k]
+			garnerShoup := crt.garnerShoup[k]
+
+			for l := 0; l < k; l++ {
+				d := digits[l]
+				if d >= qk {
+					d = reduceShoup(d, 1, crt.oneShoup[k], qk)
+				}
+				x = reduceShoup(x+qk-d, garner[l], garnerShoup[l], qk)
+			}
+
+			digits[k] = x
+		}
+
+		// Horner evaluation
+		x := v.Abs[i*v.Words : (i+1)*v.Words]
+
+		for w := range x {
+			x[w] = 0
+		}
+
+		x[0] = digits[level]
+
+		for k := level - 1; k >= 0; k-- {
+			carry = digits[k]
+			for w := 0; w < words; w++ {
+				hi, lo = bits.Mul64(x[w], moduli[k])
+				lo, c = bits.Add64(lo, carry, 0)
+				x[w] = lo
+				carry = hi + c
+			}
+		}
+
+		v.Neg[i] = false
+
+		if centered && cmpWords(x[:words], QHalf) >= 0 {
+			// x = Q - x
+			c = 0
+			for w := 0; w < words; w++ {
+				x[w], c = bits.Sub64(Q[w], x[w], c)
+			}
+			v.Neg[i] = true
+		}
+	}
+}
+
+// Decompose sets the first v.Len() coefficients of p at the given level to the values of v, reduced modulo each q_k.
+// Values of any sign are supported.
+func (crt *CRT) Decompose(level int, v *BigintVec, p *Poly) {
+	for i := 0; i < v.Len(); i++ {
+		crt.decompose(level, v.Abs[i*v.Words:(i+1)*v.Words], v.Neg[i], p, i)
+	}
+}
+
+// DecomposeBigints sets the first len(coeffs) coefficients of p at the given level to the values of coeffs,
+// reduced modulo each q_k. Values of any sign and size are supported.
+func (crt *CRT) DecomposeBigints(level int, coeffs []*big.Int, p *Poly) {
+
+	var abs []uint64
+
+	for i, b := range coeffs {
+
+		n := len(b.Bits())
+		if bits.UintSize == 32 {
+			n = (n + 1) >> 1
+		}
+
+		if cap(abs) < n {
+			abs = make([]uint64, n)
+		}
+
+		abs = abs[:n]
+
+		bigintToWords(b, abs)
+
+		crt.decompose(level, abs, b.Sign() < 0, p, i)
+	}
+}
+
+func (crt *CRT) decompose(level int, abs []uint64, neg bool, p *Poly, i int) {
+
+	n := len(abs)
+	for n > 0 && abs[n-1] == 0 {
+		n--
+	}
+
+	abs = abs[:n]
+
+	for k, qk := range crt.modulus[:level+1] {
+
+		pow := crt.pow[k]
+		powShoup := crt.powShoup[k]
+
+		var r uint64
+
+		// Processes the words by chunks, starting with the most significant ones
+		for start := ((n - 1) / crt.chunk) * crt.chunk; start >= 0 && n > 0; start -= crt.chunk {
+
+			if r != 0 {
+				r = reduceShoup(r, pow[crt.chunk], powShoup[crt.chunk], qk)
+			}
+
+			for j, w := range abs[start:utils.MinInt(start+crt.chunk, n)] {
+				if r += reduceShoup(w, pow[j], powShoup[j], qk); r >= qk {
+					r -= qk
+				}
+			}
+		}
+
+		if neg && r != 0 {
+			r = qk - r
+		}
+
+		p.Coeffs[k][i] = r
+	}
+}
+
+// shoupConstant returns floor(c * 2^64 / q), for c < q.
+func shoupConstant(c, q uint64) (cShoup uint64) {
+	cShoup, _ = bits.Div64(c, 0, q)
+	return
+}
+
+// reduceShoup returns x * c mod q in [0, q), for any x, c < q < 2^63 and cShoup = floor(c * 2^64 / q).
+func reduceShoup(x, c, cShoup, q uint64) (r uint64) {
+	hi, _ := bits.Mul64(x, cShoup)
+	if r = x*c - hi*q; r >= q {
+		r -= q
+	}
+	return
+}
+
+// bigintToWords sets words to the absolute value of b, which must fit on len(words) 64-bit words.
+func bigintToWords(b *big.Int, words []uint64) {
+
+	bw := b.Bits()
+
+	for j := range words {
+		words[j] = 0
+	}
+
+	if bits.UintSize == 64 {
+		for j, w := range bw {
+			words[j] = uint64(w)
+		}
+	} else {
+		for j, w := range bw {
+			words[j>>1] |= uint64(w) << (32 * uint(j&1))
+		}
+	}
+}
+
+// cmpWords compares two integers of the same number of 64-bit words.
+func cmpWords(a, b []uint64) int {
+	for i := len(a) - 1; i >= 0; i-- {
+		if a[i] > b[i] {
+			return 1
+		} else if a[i] < b[i] {
+			return -1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,430 @@
+package ring
+
+import (
+	"fmt"
+	"math/bits"
+	"sync"
+
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// NumberTheoreticTransformerCyclotomic computes the NTT in the ring Z[X]/(Phi_M(X)) for an arbitrary cyclotomic order M,
+// i.e. the evaluations of a polynomial of degree N = phi(M) at the primitive M-th roots of unity zeta^k mod each qi,
+// for the k in Z_M^* in ascending order (see Units).
+//
+// The length-M DFT is computed with Bluestein's algorithm, which turns it into a product of polynomials computed with
+// the nega-cyclic NTT of size L, the smallest power of two larger than 2M-2. The backward transform reduces the output
+// of the inverse DFT modulo Phi_M. The moduli must therefore be congruent to 1 modulo CyclotomicNTTRoot(M).
+type NumberTheoreticTransformerCyclotomic struct {
+	*cyclotomicParams
+}
+
+// cyclotomicParams stores the pre-computed values of the NTT in Z[X]/(Phi_M(X)).
+type cyclotomicParams struct {
+	m int
+
+	// Z_M^* in ascending order, and the index of each of its elements (-1 for the non-units)
+	units     []uint64
+	unitIndex []int
+
+	// indexes of the non-zero coefficients of Phi_M except the leading one, and their opposite mod each qi
+	phiIndex []int
+	phiNeg   [][]uint64
+
+	// power-of-two ring of degree L used for the products of polynomials
+	ringL *Ring
+
+	// psi^(k^2) and psi^(-k^2) for 0 <= k < M in Montgomery form, with psi a 2M-th primitive root of unity
+	chirp    [][]uint64
+	chirpInv [][]uint64
+
+	// NTT of the Bluestein kernels of the forward and backward transforms in Montgomery form
+	kernel    [][]uint64
+	kernelInv [][]uint64
+
+	pool *sync.Pool
+}
+
+// CyclotomicNTTRoot returns the integer NthRoot such that a prime qi enables the NTT in Z[X]/(Phi_M(X)) if and only if
+// qi = 1 mod NthRoot. It can be given to GenerateNTTPrimes to generate such primes.
+func CyclotomicNTTRoot(M int) uint64 {
+	odd := uint64(M)
+	for odd&1 == 0 {
+		odd >>= 1
+	}
+	return odd * uint64(cyclotomicNTTSize(M)) << 1
+}
+
+// EulerTotient returns phi(M), the number of integers in [1, M] coprime with M, which is the degree of Phi_M.
+func EulerTotient(M int) (phi uint64) {
+	phi = uint64(M)
+	for _, p := range getFactors(uint64(M)) {
+		phi = phi / p * (p - 1)
+	}
+	return
+}
+
+// cyclotomicNTTSize returns the degree L of the power-of-two ring used for the Bluestein products.
+func cyclotomicNTTSize(M int) int {
+	return utils.MaxInt(16, 1<<bits.Len64(uint64(2*M-2)))
+}
+
+// NewRingCyclotomic creates a new RNS Ring Z[X]/(Phi_M(X)) of degree N = phi(M) and coefficient moduli Moduli, for the
+// cyclotomic order M. N must be a multiple of 8. Moduli should be a non-empty []uint64 with distinct prime elements.
+// All moduli must also be equal to 1 modulo CyclotomicNTTRoot(M). The field NthRoot of the returned ring stores M.
+// An error is returned with a nil *Ring in the case of non NTT-enabling parameters.
+func NewRingCyclotomic(M int, Moduli []uint64) (r *Ring, err error) {
+
+	if M < 3 || M > 1<<20 {
+		return nil, fmt.Errorf("invalid cyclotomic order (must be in [3, 2^20])")
+	}
+
+	phi := cyclotomicPolynomial(M)
+	N := len(phi) - 1
+
+	r = new(Ring)
+	if err = r.setModulus(N, Moduli); err != nil {
+		return nil, err
+	}
+
+	NthRoot := CyclotomicNTTRoot(M)
+	for i, qi := range r.Modulus {
+		if !IsPrime(qi) {
+			return nil, fmt.Errorf("invalid modulus (Modulus[%d] is not prime)", i)
+		}
+
+		if qi%NthRoot != 1 {
+			return nil, fmt.Errorf("invalid modulus (Modulus[%d] != 1 mod CyclotomicNTTRoot(M))", i)
+		}
+	}
+
+	cp := &cyclotomicParams{m: M}
+
+	L := cyclotomicNTTSize(M)
+	if cp.ringL, err = NewRing(L, r.Modulus); err != nil {
+		return nil, err
+	}
+
+	cp.pool = &sync.Pool{New: func() interface{} { return make([]uint64, L) }}
+
+	cp.unitIndex = make([]int, M)
+	cp.units = make([]uint64, 0, N)
+	for k := 0; k < M; k++ {
+		if gcd(uint64(k), uint64(M)) == 1 {
+			cp.unitIndex[k] = len(cp.units)
+			cp.units = append(cp.units, uint64(k))
+		} else {
+			cp.unitIndex[k] = -1
+		}
+	}
+
+	for i, c := range phi[:N] {
+		if c != 0 {
+			cp.phiIndex = append(cp.phiIndex, i)
+		}
+	}
+
+	m := uint64(M)
+	twoM := 2 * m
+
+	cp.phiNeg = make([][]uint64, len(r.Modulus))
+	cp.chirp = make([][]uint64, len(r.Modulus))
+	cp.chirpInv = make([][]uint64, len(r.Modulus))
+	cp.kernel = make([][]uint64, len(r.Modulus))
+	cp.kernelInv = make([][]uint64, len(r.Modulus))
+
+	for i, qi := range r.Modulus {
+
+		bredParams := r.BredParams[i]
+
+		cp.phiNeg[i] = make([]uint64, len(cp.phiIndex))
+		for j, idx := range cp.phiIndex {
+			c := phi[idx]
+			if c > 0 {
+				cp.phiNeg[i][j] = qi - uint64(c)%qi
+			} else {
+				cp.phiNeg[i][j] = uint64(-c) % qi
+			}
+		}
+
+		// powers of a 2M-th primitive root of unity
+		psi := ModExp(primitiveRoot(qi), (qi-1)/twoM, qi)
+		psiPow := make([]uint64, twoM)
+		psiPow[0] = 1
+		for j := uint64(1); j < twoM; j++ {
+			psiPow[j] = BRed(psiPow[j-1], psi, qi, bredParams)
+		}
+
+		cp.chirp[i] = make([]uint64, M)
+		cp.chirpInv[i] = make([]uint64, M)
+		for k := uint64(0); k < m; k++ {
+			e := (k * k) % twoM
+			cp.chirp[i][k] = MForm(psiPow[e], qi, bredParams)
+			cp.chirpInv[i][k] = MForm(psiPow[(twoM-e)%twoM], qi, bredParams)
+		}
+
+		// kernel[s+N-1] = psi^(-s^2) for -N < s < M
+		cp.kernel[i] = make([]uint64, L)
+		for s := 1 - N; s < M; s++ {
+			e := uint64(s*s) % twoM
+			cp.kernel[i][s+N-1] = psiPow[(twoM-e)%twoM]
+		}
+
+		// kernelInv[s+M-1] = psi^(s^2) / M for -M < s < M
+		mInv := ModExp(m, qi-2, qi)
+		cp.kernelInv[i] = make([]uint64, L)
+		for s := 1 - M; s < M; s++ {
+			cp.kernelInv[i][s+M-1] = BRed(psiPow[uint64(s*s)%twoM], mInv, qi, bredParams)
+		}
+
+		cp.ringL.NTTSingle(i, cp.kernel[i], cp.kernel[i])
+		cp.ringL.NTTSingle(i, cp.kernelInv[i], cp.kernelInv[i])
+		MFormVec(cp.kernel[i], cp.kernel[i], qi, bredParams)
+		MFormVec(cp.kernelInv[i], cp.kernelInv[i], qi, bredParams)
+	}
+
+	r.NumberTheoreticTransformer = NumberTheoreticTransformerCyclotomic{cp}
+	r.NthRoot = m
+	r.genRescaleParams()
+	r.AllowsNTT = true
+
+	return r, nil
+}
+
+// cyclotomicPolynomial returns the coefficients of the M-th cyclotomic polynomial Phi_M, by increasing degree.
+// It uses Phi_{np}(X) = Phi_n(X^p) / Phi_n(X) for p a prime not dividing n, and Phi_M(X) = Phi_rad(M)(X^(M/rad(M))).
+func cyclotomicPolynomial(M int) (phi []int64) {
+
+	phi = []int64{-1, 1}
+
+	rad := 1
+	for _, p := range getFactors(uint64(M)) {
+
+		num := make([]int64, (len(phi)-1)*int(p)+1)
+		for i, c := range phi {
+			num[i*int(p)] = c
+		}
+
+		// exact division of num by the monic polynomial phi
+		deg := len(phi) - 1
+		quo := make([]int64, len(num)-deg)
+		for i := len(quo) - 1; i >= 0; i-- {
+			c := num[i+deg]
+			quo[i] = c
+			for j := range phi {
+				num[i+j] -= c * phi[j]
+			}
+		}
+
+		phi = quo
+		rad *= int(p)
+	}
+
+	if s := M / rad; s > 1 {
+		expanded := make([]int64, (len(phi)-1)*s+1)
+		for i, c := range phi {
+			expanded[i*s] = c
+		}
+		phi = expanded
+	}
+
+	return
+}
+
+// Units returns the elements k of Z_M^* in ascending order: the i-th coefficient of a polynomial in the NTT domain
+// is its evaluation at zeta^Units()[i], where zeta is an M-th primitive root of unity.
+func (rntt NumberTheoreticTransformerCyclotomic) Units() (units []uint64) {
+	units = make([]uint64, len(rntt.units))
+	copy(units, rntt.units)
+	return
+}
+
+// reduce reduces the polynomial of degree smaller than M stored in the first M values of buff modulo Phi_M and qi.
+func (rntt NumberTheoreticTransformerCyclotomic) reduce(r *Ring, level int, buff []uint64) {
+
+	N, qi, bredParams := r.N, r.Modulus[level], r.BredParams[level]
+	phiNeg := rntt.phiNeg[level]
+
+	for d := rntt.m - 1; d >= N; d-- {
+		if c := buff[d]; c != 0 {
+			for j, idx := range rntt.phiIndex {
+				buff[d-N+idx] = CRed(buff[d-N+idx]+BRed(c, phiNeg[j], qi, bredParams), qi)
+			}
+		}
+	}
+}
+
+func (rntt NumberTheoreticTransformerCyclotomic) forward(r *Ring, level int, p1, p2 []uint64) {
+
+	N, qi, mredParams := r.N, r.Modulus[level], r.MredParams[level]
+	chirp := rntt.chirp[level]
+
+	buff := rntt.pool.Get().([]uint64)
+	defer rntt.pool.Put(buff)
+
+	for j := 0; j < N; j++ {
+		buff[j] = MRed(p1[j], chirp[j], qi, mredParams)
+	}
+
+	for j := N; j < len(buff); j++ {
+		buff[j] = 0
+	}
+
+	rntt.ringL.NTTSingle(level, buff, buff)
+	MulCoeffsMontgomeryVec(buff, rntt.kernel[level], buff, qi, mredParams)
+	rntt.ringL.InvNTTSingle(level, buff, buff)
+
+	for i, k := range rntt.units {
+		p2[i] = MRed(buff[int(k)+N-1], chirp[k], qi, mredParams)
+	}
+}
+
+func (rntt NumberTheoreticTransformerCyclotomic) backward(r *Ring, level int, p1, p2 []uint64) {
+
+	M, qi, mredParams := rntt.m, r.Modulus[level], r.MredParams[level]
+	chirpInv := rntt.chirpInv[level]
+
+	buff := rntt.pool.Get().([]uint64)
+	defer rntt.pool.Put(buff)
+
+	for j := range buff {
+		buff[j] = 0
+	}
+
+	for i, k := range rntt.units {
+		buff[k] = MRed(p1[i], chirpInv[k], qi, mredParams)
+	}
+
+	rntt.ringL.NTTSingle(level, buff, buff)
+	MulCoeffsMontgomeryVec(buff, rntt.kernelInv[level], buff, qi, mredParams)
+	rntt.ringL.InvNTTSingle(level, buff, buff)
+
+	for j := 0; j < M; j++ {
+		buff[j] = MRed(buff[j+M-1], chirpInv[j], qi, mredParams)
+	}
+
+	rntt.reduce(r, level, buff)
+
+	copy(p2[:r.N], buff)
+}
+
+// Forward writes the forward NTT in Z[X]/(Phi_M(X)) of p1 on p2.
+func (rntt NumberTheoreticTransformerCyclotomic) Forward(r *Ring, p1, p2 *Poly) {
+	rntt.ForwardLvl(r, len(r.Modulus)-1, p1, p2)
+}
+
+// ForwardLvl writes the forward NTT in Z[X]/(Phi_M(X)) of p1 on p2.
+// Only computes the NTT for the first level+1 moduli.
+func (rntt NumberTheoreticTransformerCyclotomic) ForwardLvl(r *Ring, level int, p1, p2 *Poly) {
+	for x := 0; x < level+1; x++ {
+		rntt.forward(r, x, p1.Coeffs[x], p2.Coeffs[x])
+	}
+}
+
+// ForwardLazy writes the forward NTT in Z[X]/(Phi_M(X)) of p1 on p2.
+// Returns values in the range [0, 2q-1].
+func (rntt NumberTheoreticTransformerCyclotomic) ForwardLazy(r *Ring, p1, p2 *Poly) {
+	rntt.ForwardLvl(r, len(r.Modulus)-1, p1, p2)
+}
+
+// ForwardLazyLvl writes the forward NTT in Z[X]/(Phi_M(X)) of p1 on p2.
+// Only computes the NTT for the first level+1 moduli and returns values in the range [0, 2q-1].
+func (rntt NumberTheoreticTransformerCyclotomic) ForwardLazyLvl(r *Ring, level int, p1, p2 *Poly) {
+	rntt.ForwardLvl(r, level, p1, p2)
+}
+
+// Backward writes the backward NTT in Z[X]/(Phi_M(X)) of p1 on p2.
+func (rntt NumberTheoreticTransformerCyclotomic) Backward(r *Ring, p1, p2 *Poly) {
+	rntt.BackwardLvl(r, len(r.Modulus)-1, p1, p2)
+}
+
+// BackwardLvl writes the backward NTT in Z[X]/(Phi_M(X)) of p1 on p2.
+// Only computes the NTT for the first level+1 moduli.
+func (rntt NumberTheoreticTransformerCyclotomic) BackwardLvl(r *Ring, level int, p1, p2 *Poly) {
+	for x := 0; x < level+1; x++ {
+		rntt.backward(r, x, p1.Coeffs[x], p2.Coeffs[x])
+	}
+}
+
+// BackwardLazy writes the backward NTT in Z[X]/(Phi_M(X)) of p1 on p2.
+// Returns values in the range [0, 2q-1].
+func (rntt NumberTheoreticTransformerCyclotomic) BackwardLazy(r *Ring, p1, p2 *Poly) {
+	rntt.BackwardLvl(r, len(r.Modulus)-1, p1, p2)
+}
+
+// BackwardLazyLvl writes the backward NTT in Z[X]/(Phi_M(X)) of p1 on p2.
+// Only computes the NTT for the first level+1 moduli and returns values in the range [0, 2q-1].
+func (rntt NumberTheoreticTransformerCyclotomic) BackwardLazyLvl(r *Ring, level int, p1, p2 *Poly) {
+	rntt.BackwardLvl(r, level, p1, p2)
+}
+
+// ForwardVec writes the forward NTT in Z[X]/(Phi_M(X)) of the i-th level of p1 on the i-th level of p2.
+func (rntt NumberTheoreticTransformerCyclotomic) ForwardVec(r *Ring, level int, p1, p2 []uint64) {
+	rntt.forward(r, level, p1, p2)
+}
+
+// ForwardLazyVec writes the forward NTT in Z[X]/(Phi_M(X)) of the i-th level of p1 on the i-th level of p2.
+// Returns values in the range [0, 2q-1].
+func (rntt NumberTheoreticTransformerCyclotomic) ForwardLazyVec(r *Ring, level int, p1, p2 []uint64) {
+	rntt.forward(r, level, p1, p2)
+}
+
+// BackwardVec writes the backward NTT in Z[X]/(Phi_M(X)) of the i-th level of p1 on the i-th level of p2.
+func (rntt NumberTheoreticTransformerCyclotomic) BackwardVec(r *Ring, level int, p1, p2 []uint64) {
+	rntt.backward(r, level, p1, p2)
+}
+
+// BackwardLazyVec writes the backward NTT in Z[X]/(Phi_M(X)) of the i-th level of p1 on the i-th level of p2.
+// Returns values in the range [0, 2q-1].
+func (rntt NumberTheoreticTransformerCyclotomic) BackwardLazyVec(r *Ring, level int, p1, p2 []uint64) {
+	rntt.backward(r, level, p1, p2)
+}
+
+// permuteNTTIndex returns the index table of the automorphism X -> X^galEl in the NTT domain, which maps the
+// evaluation at zeta^k to the evaluation at zeta^(k*galEl).
+func (rntt NumberTheoreticTransformerCyclotomic) permuteNTTIndex(galEl uint64) (index []uint64) {
+
+	m := uint64(rntt.m)
+	galEl %= m
+
+	if rntt.unitIndex[galEl] < 0 {
+		panic("invalid Galois element: not invertible modulo the cyclotomic order")
+	}
+
+	index = make([]uint64, len(rntt.units))
+	for i, k := range rntt.units {
+		index[i] = uint64(rntt.unitIndex[(k*galEl)%m])
+	}
+
+	return
+}
+
+// permute applies the automorphism X -> X^gen on a polynomial outside of the NTT domain, by mapping the coefficients
+// of X^i to X^(i*gen mod M) and reducing the result modulo Phi_M.
+func (rntt NumberTheoreticTransformerCyclotomic) permute(r *Ring, polIn *Poly, gen uint64, polOut *Poly) {
+
+	m := uint64(rntt.m)
+	gen %= m
+
+	if rntt.unitIndex[gen] < 0 {
+		panic("invalid Galois element: not invertible modulo the cyclotomic order")
+	}
+
+	buff := rntt.pool.Get().([]uint64)
+	defer rntt.pool.Put(buff)
+
+	for level := 0; level < utils.MinInt(polIn.Level(), polOut.Level())+1; level++ {
+
+		for j := range buff[:m] {
+			buff[j] = 0
+		}
+
+		for i, c := range polIn.Coeffs[level][:r.N] {
+			buff[(uint64(i)*gen)%m] = c
+		}
+
+		rntt.reduce(r, level, buff)
+
+		copy(polOut.Coeffs[level][:r.N], buff)
+	}
+}
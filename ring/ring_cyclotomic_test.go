@@ -0,0 +1,180 @@
+package ring
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+func TestRingCyclotomic(t *testing.T) {
+
+	t.Run("CyclotomicPolynomial", func(t *testing.T) {
+		require.Equal(t, []int64{1, 1, 1}, cyclotomicPolynomial(3))
+		require.Equal(t, []int64{1, 0, 0, 0, 1}, cyclotomicPolynomial(8))
+		require.Equal(t, []int64{1, 0, -1, 0, 1}, cyclotomicPolynomial(12))
+
+		// Phi_105 is the smallest cyclotomic polynomial with a coefficient other than 0, 1 and -1
+		phi := cyclotomicPolynomial(105)
+		require.Equal(t, 49, len(phi))
+		require.Equal(t, int64(-2), phi[7])
+	})
+
+	t.Run("NewRingCyclotomic", func(t *testing.T) {
+		_, err := NewRingCyclotomic(97, []uint64{97}) // Passing non NTT-enabling coeff modulus
+		require.Error(t, err)
+
+		_, err = NewRingCyclotomic(11, GenerateNTTPrimes(40, int(CyclotomicNTTRoot(11)), 1)) // phi(11) is not a multiple of 8
+		require.Error(t, err)
+	})
+
+	prng, err := utils.NewPRNG()
+	require.NoError(t, err)
+
+	// 3*2^5, a prime and a product of three odd primes
+	for _, M := range []int{96, 97, 105} {
+
+		r, err := NewRingCyclotomic(M, GenerateNTTPrimes(55, int(CyclotomicNTTRoot(M)), 2))
+		require.NoError(t, err)
+		require.Equal(t, Cyclotomic, r.Type())
+		require.Equal(t, uint64(M), r.NthRoot)
+
+		sampler := NewUniformSampler(prng, r)
+
+		t.Run(fmt.Sprintf("Cyclotomic/M=%d/N=%d/NTT", M, r.N), func(t *testing.T) {
+
+			p1 := sampler.ReadNew()
+			p2 := r.NewPoly()
+
+			r.NTT(p1, p2)
+			r.InvNTT(p2, p2)
+			require.True(t, r.Equal(p1, p2))
+		})
+
+		t.Run(fmt.Sprintf("Cyclotomic/M=%d/N=%d/MulCoeffs", M, r.N), func(t *testing.T) {
+
+			p1 := sampler.ReadNew()
+			p2 := sampler.ReadNew()
+
+			pWant := r.NewPoly()
+			phi := cyclotomicPolynomial(M)
+			for i, qi := range r.Modulus {
+
+				bredParams := r.BredParams[i]
+
+				// schoolbook product
+				prod := make([]uint64, 2*r.N-1)
+				for j, c1 := range p1.Coeffs[i] {
+					for k, c2 := range p2.Coeffs[i] {
+						prod[j+k] = CRed(prod[j+k]+BRed(c1, c2, qi, bredParams), qi)
+					}
+				}
+
+				// reduction modulo Phi_M
+				for d := len(prod) - 1; d >= r.N; d-- {
+					for k, c := range phi[:r.N] {
+						v := BRed(prod[d], uint64((c%int64(qi)+int64(qi))%int64(qi)), qi, bredParams)
+						prod[d-r.N+k] = CRed(prod[d-r.N+k]+qi-v, qi)
+					}
+				}
+
+				copy(pWant.Coeffs[i], prod[:r.N])
+			}
+
+			r.NTT(p1, p1)
+			r.NTT(p2, p2)
+			r.MForm(p1, p1)
+			r.MulCoeffsMontgomery(p1, p2, p1)
+			r.InvNTT(p1, p1)
+
+			require.True(t, r.Equal(p1, pWant))
+		})
+
+		t.Run(fmt.Sprintf("Cyclotomic/M=%d/N=%d/Permute", M, r.N), func(t *testing.T) {
+
+			for _, galEl := range []uint64{2, uint64(M - 1), 11} {
+
+				if gcd(galEl, uint64(M)) != 1 {
+					require.Panics(t, func() { r.PermuteNTTIndex(galEl) })
+					continue
+				}
+
+				p1 := sampler.ReadNew()
+				p2 := r.NewPoly()
+				p3 := r.NewPoly()
+
+				// Permute commutes with the NTT
+				r.Permute(p1, galEl, p2)
+				r.NTT(p2, p2)
+
+				r.NTT(p1, p1)
+				r.PermuteNTT(p1, galEl, p3)
+
+				require.True(t, r.Equal(p2, p3))
+
+				// X -> X^galEl is a ring homomorphism: (X^galEl)^(M-1) = X^-galEl
+				r.InvNTT(p1, p1)
+				r.Permute(p1, galEl, p2)
+				r.Permute(p2, uint64(M-1), p3)
+				r.Permute(p1, uint64(M)-galEl, p2)
+				require.True(t, r.Equal(p2, p3))
+			}
+		})
+
+		t.Run(fmt.Sprintf("Cyclotomic/M=%d/N=%d/MarshalBinary", M, r.N), func(t *testing.T) {
+
+			data, err := r.MarshalBinary()
+			require.NoError(t, err)
+
+			rTest := new(Ring)
+			require.NoError(t, rTest.UnmarshalBinary(data))
+			require.Equal(t, Cyclotomic, rTest.Type())
+			require.Equal(t, r.N, rTest.N)
+			require.Equal(t, r.Modulus, rTest.Modulus)
+
+			p1 := sampler.ReadNew()
+			p2 := r.NewPoly()
+			r.NTT(p1, p2)
+			rTest.NTT(p1, p1)
+			require.True(t, r.Equal(p1, p2))
+		})
+
+		t.Run(fmt.Sprintf("Cyclotomic/M=%d/N=%d/MarshalBinaryPoly", M, r.N), func(t *testing.T) {
+
+			p := sampler.ReadNew()
+			p.IsNTT = true
+
+			data, err := p.MarshalBinary()
+			require.NoError(t, err)
+			require.Equal(t, p.GetDataLen(true), len(data))
+
+			pTest := new(Poly)
+			require.NoError(t, pTest.UnmarshalBinary(data))
+			require.True(t, r.Equal(p, pTest))
+			require.Equal(t, p.IsNTT, pTest.IsNTT)
+
+			pTest = new(Poly)
+			_, err = pTest.DecodePolyNew(data)
+			require.NoError(t, err)
+			require.True(t, r.Equal(p, pTest))
+
+			p32 := r.NewPoly()
+			for i := range p32.Coeffs {
+				for j := range p32.Coeffs[i] {
+					p32.Coeffs[i][j] = p.Coeffs[i][j] & 0xFFFFFFFF
+				}
+			}
+
+			data = make([]byte, p32.GetDataLen32(true))
+			_, err = p32.WriteTo32(data)
+			require.NoError(t, err)
+
+			pTest = new(Poly)
+			_, err = pTest.DecodePolyNew32(data)
+			require.NoError(t, err)
+			require.True(t, r.Equal(p32, pTest))
+		})
+	}
+}
@@ -153,81 +153,96 @@ func NTTLazy(coeffsIn, coeffsOut []uint64, N int, nttPsi []uint64, Q, QInv uint6
 	// Continue the rest of the second to the n-1 butterflies on p2 with approximate reduction
 	var reduce bool
 
-	for m := 2; m < N; m <<= 1 {
+	for m := 2; m < N>>3; m <<= 1 {
 
 		reduce = (bits.Len64(uint64(m))&1 == 1)
 
 		t >>= 1
 
-		if t >= 8 {
-
-			for i := 0; i < m; i++ {
+		for i := 0; i < m; i++ {
 
-				j1 = (i * t) << 1
+			j1 = (i * t) << 1
 
-				j2 = j1 + t - 1
+			j2 = j1 + t - 1
 
-				F = nttPsi[m+i]
+			F = nttPsi[m+i]
 
-				if reduce {
+			if reduce {
 
-					for jx, jy := j1, j1+t; jx <= j2; jx, jy = jx+8, jy+8 {
+				for jx, jy := j1, j1+t; jx <= j2; jx, jy = jx+8, jy+8 {
 
-						x := (*[8]uint64)(unsafe.Pointer(&coeffsOut[jx]))
-						y := (*[8]uint64)(unsafe.Pointer(&coeffsOut[jy]))
+					x := (*[8]uint64)(unsafe.Pointer(&coeffsOut[jx]))
+					y := (*[8]uint64)(unsafe.Pointer(&coeffsOut[jy]))
 
-						x[0], y[0] = butterfly(x[0], y[0], F, twoQ, fourQ, Q, QInv)
-						x[1], y[1] = butterfly(x[1], y[1], F, twoQ, fourQ, Q, QInv)
-						x[2], y[2] = butterfly(x[2], y[2], F, twoQ, fourQ, Q, QInv)
-						x[3], y[3] = butterfly(x[3], y[3], F, twoQ, fourQ, Q, QInv)
-						x[4], y[4] = butterfly(x[4], y[4], F, twoQ, fourQ, Q, QInv)
-						x[5], y[5] = butterfly(x[5], y[5], F, twoQ, fourQ, Q, QInv)
-						x[6], y[6] = butterfly(x[6], y[6], F, twoQ, fourQ, Q, QInv)
-						x[7], y[7] = butterfly(x[7], y[7], F, twoQ, fourQ, Q, QInv)
-					}
+					x[0], y[0] = butterfly(x[0], y[0], F, twoQ, fourQ, Q, QInv)
+					x[1], y[1] = butterfly(x[1], y[1], F, twoQ, fourQ, Q, QInv)
+					x[2], y[2] = butterfly(x[2], y[2], F, twoQ, fourQ, Q, QInv)
+					x[3], y[3] = butterfly(x[3], y[3], F, twoQ, fourQ, Q, QInv)
+					x[4], y[4] = butterfly(x[4], y[4], F, twoQ, fourQ, Q, QInv)
+					x[5], y[5] = butterfly(x[5], y[5], F, twoQ, fourQ, Q, QInv)
+					x[6], y[6] = butterfly(x[6], y[6], F, twoQ, fourQ, Q, QInv)
+					x[7], y[7] = butterfly(x[7], y[7], F, twoQ, fourQ, Q, QInv)
+				}
 
-				} else {
+			} else {
 
-					for jx, jy := j1, j1+t; jx <= j2; jx, jy = jx+8, jy+8 {
+				for jx, jy := j1, j1+t; jx <= j2; jx, jy = jx+8, jy+8 {
 
-						x := (*[8]uint64)(unsafe.Pointer(&coeffsOut[jx]))
-						y := (*[8]uint64)(unsafe.Pointer(&coeffsOut[jy]))
+					x := (*[8]uint64)(unsafe.Pointer(&coeffsOut[jx]))
+					y := (*[8]uint64)(unsafe.Pointer(&coeffsOut[jy]))
 
-						V = MRedConstant(y[0], F, Q, QInv)
-						x[0], y[0] = x[0]+V, x[0]+twoQ-V
+					V = MRedConstant(y[0], F, Q, QInv)
+					x[0], y[0] = x[0]+V, x[0]+twoQ-V
 
-						V = MRedConstant(y[1], F, Q, QInv)
-						x[1], y[1] = x[1]+V, x[1]+twoQ-V
+					V = MRedConstant(y[1], F, Q, QInv)
+					x[1], y[1] = x[1]+V, x[1]+twoQ-V
 
-						V = MRedConstant(y[2], F, Q, QInv)
-						x[2], y[2] = x[2]+V, x[2]+twoQ-V
+					V = MRedConstant(y[2], F, Q, QInv)
+					x[2], y[2] = x[2]+V, x[2]+twoQ-V
 
-						V = MRedConstant(y[3], F, Q, QInv)
-						x[3], y[3] = x[3]+V, x[3]+twoQ-V
+					V = MRedConstant(y[3], F, Q, QInv)
+					x[3], y[3] = x[3]+V, x[3]+twoQ-V
 
-						V = MRedConstant(y[4], F, Q, QInv)
-						x[4], y[4] = x[4]+V, x[4]+twoQ-V
+					V = MRedConstant(y[4], F, Q, QInv)
+					x[4], y[4] = x[4]+V, x[4]+twoQ-V
 
-						V = MRedConstant(y[5], F, Q, QInv)
-						x[5], y[5] = x[5]+V, x[5]+twoQ-V
+					V = MRedConstant(y[5], F, Q, QInv)
+					x[5], y[5] = x[5]+V, x[5]+twoQ-V
 
-						V = MRedConstant(y[6], F, Q, QInv)
-						x[6], y[6] = x[6]+V, x[6]+twoQ-V
+					V = MRedConstant(y[6], F, Q, QInv)
+					x[6], y[6] = x[6]+V, x[6]+twoQ-V
 
-						V = MRedConstant(y[7], F, Q, QInv)
-						x[7], y[7] = x[7]+V, x[7]+twoQ-V
-					}
+					V = MRedConstant(y[7], F, Q, QInv)
+					x[7], y[7] = x[7]+V, x[7]+twoQ-V
 				}
 			}
+		}
+	}
 
-		} else if t == 4 {
+	nttLazySmallStrides(coeffsOut, N, nttPsi, Q, QInv)
+}
+
+// nttLazySmallStrides computes the last three rounds of butterflies (t = 4, 2 and 1) of NTTLazy in place on coeffs.
+func nttLazySmallStrides(coeffs []uint64, N int, nttPsi []uint64, Q, QInv uint64) {
+	var V uint64
+
+	fourQ := 4 * Q
+	twoQ := 2 * Q
+
+	var reduce bool
+
+	for m, t := N>>3, 4; m < N; m, t = m<<1, t>>1 {
+
+		reduce = (bits.Len64(uint64(m))&1 == 1)
+
+		if t == 4 {
 
 			if reduce {
 
 				for i, j1 := m, 0; i < 2*m; i, j1 = i+2, j1+4*t {
 
 					psi := (*[2]uint64)(unsafe.Pointer(&nttPsi[i]))
-					x := (*[16]uint64)(unsafe.Pointer(&coeffsOut[j1]))
+					x := (*[16]uint64)(unsafe.Pointer(&coeffs[j1]))
 
 					x[0], x[4] = butterfly(x[0], x[4], psi[0], twoQ, fourQ, Q, QInv)
 					x[1], x[5] = butterfly(x[1], x[5], psi[0], twoQ, fourQ, Q, QInv)
@@ -244,7 +259,7 @@ func NTTLazy(coeffsIn, coeffsOut []uint64, N int, nttPsi []uint64, Q, QInv uint6
 				for i, j1 := m, 0; i < 2*m; i, j1 = i+2, j1+4*t {
 
 					psi := (*[2]uint64)(unsafe.Pointer(&nttPsi[i]))
-					x := (*[16]uint64)(unsafe.Pointer(&coeffsOut[j1]))
+					x := (*[16]uint64)(unsafe.Pointer(&coeffs[j1]))
 
 					V = MRedConstant(x[4], psi[0], Q, QInv)
 					x[0], x[4] = x[0]+V, x[0]+twoQ-V
@@ -281,7 +296,7 @@ func NTTLazy(coeffsIn, coeffsOut []uint64, N int, nttPsi []uint64, Q, QInv uint6
 				for i, j1 := m, 0; i < 2*m; i, j1 = i+4, j1+8*t {
 
 					psi := (*[4]uint64)(unsafe.Pointer(&nttPsi[i]))
-					x := (*[16]uint64)(unsafe.Pointer(&coeffsOut[j1]))
+					x := (*[16]uint64)(unsafe.Pointer(&coeffs[j1]))
 
 					x[0], x[2] = butterfly(x[0], x[2], psi[0], twoQ, fourQ, Q, QInv)
 					x[1], x[3] = butterfly(x[1], x[3], psi[0], twoQ, fourQ, Q, QInv)
@@ -297,7 +312,7 @@ func NTTLazy(coeffsIn, coeffsOut []uint64, N int, nttPsi []uint64, Q, QInv uint6
 				for i, j1 := m, 0; i < 2*m; i, j1 = i+4, j1+8*t {
 
 					psi := (*[4]uint64)(unsafe.Pointer(&nttPsi[i]))
-					x := (*[16]uint64)(unsafe.Pointer(&coeffsOut[j1]))
+					x := (*[16]uint64)(unsafe.Pointer(&coeffs[j1]))
 
 					V = MRedConstant(x[2], psi[0], Q, QInv)
 					x[0], x[2] = x[0]+V, x[0]+twoQ-V
@@ -330,7 +345,7 @@ func NTTLazy(coeffsIn, coeffsOut []uint64, N int, nttPsi []uint64, Q, QInv uint6
 			for i, j1 := m, 0; i < 2*m; i, j1 = i+8, j1+16 {
 
 				psi := (*[8]uint64)(unsafe.Pointer(&nttPsi[i]))
-				x := (*[16]uint64)(unsafe.Pointer(&coeffsOut[j1]))
+				x := (*[16]uint64)(unsafe.Pointer(&coeffs[j1]))
 
 				x[0], x[1] = butterfly(x[0], x[1], psi[0], twoQ, fourQ, Q, QInv)
 				x[2], x[3] = butterfly(x[2], x[3], psi[1], twoQ, fourQ, Q, QInv)
@@ -346,7 +361,7 @@ func NTTLazy(coeffsIn, coeffsOut []uint64, N int, nttPsi []uint64, Q, QInv uint6
 				for i := uint64(0); i < m; i = i + 8 {
 
 					psi := (*[8]uint64)(unsafe.Pointer(&nttPsi[m+i]))
-					x := (*[16]uint64)(unsafe.Pointer(&coeffsOut[2*i]))
+					x := (*[16]uint64)(unsafe.Pointer(&coeffs[2*i]))
 
 					V = MRedConstant(x[1], psi[0], Q, QInv)
 					x[0], x[1] = x[0]+V, x[0]+twoQ-V
@@ -378,9 +393,48 @@ func NTTLazy(coeffsIn, coeffsOut []uint64, N int, nttPsi []uint64, Q, QInv uint6
 }
 
 func invNTTCore(coeffsIn, coeffsOut []uint64, N int, nttPsiInv []uint64, Q, QInv uint64) {
-	var h, t int
+	var h int
 	var F uint64
 
+	twoQ := Q << 1
+	fourQ := Q << 2
+
+	invNTTSmallStrides(coeffsIn, coeffsOut, N, nttPsiInv, Q, QInv)
+
+	// Continue the rest of the butterflies on p2 with approximate reduction
+	t := 8
+	for m := N >> 3; m > 1; m >>= 1 {
+
+		h = m >> 1
+
+		for i, j1, j2 := 0, 0, t-1; i < h; i, j1, j2 = i+1, j1+2*t, j2+2*t {
+
+			F = nttPsiInv[h+i]
+
+			for jx, jy := j1, j1+t; jx <= j2; jx, jy = jx+8, jy+8 {
+
+				x := (*[8]uint64)(unsafe.Pointer(&coeffsOut[jx]))
+				y := (*[8]uint64)(unsafe.Pointer(&coeffsOut[jy]))
+
+				x[0], y[0] = invbutterfly(x[0], y[0], F, twoQ, fourQ, Q, QInv)
+				x[1], y[1] = invbutterfly(x[1], y[1], F, twoQ, fourQ, Q, QInv)
+				x[2], y[2] = invbutterfly(x[2], y[2], F, twoQ, fourQ, Q, QInv)
+				x[3], y[3] = invbutterfly(x[3], y[3], F, twoQ, fourQ, Q, QInv)
+				x[4], y[4] = invbutterfly(x[4], y[4], F, twoQ, fourQ, Q, QInv)
+				x[5], y[5] = invbutterfly(x[5], y[5], F, twoQ, fourQ, Q, QInv)
+				x[6], y[6] = invbutterfly(x[6], y[6], F, twoQ, fourQ, Q, QInv)
+				x[7], y[7] = invbutterfly(x[7], y[7], F, twoQ, fourQ, Q, QInv)
+			}
+		}
+
+		t <<= 1
+	}
+}
+
+// invNTTSmallStrides computes the first three rounds of butterflies (t = 1, 2 and 4) of invNTTCore from coeffsIn to coeffsOut.
+func invNTTSmallStrides(coeffsIn, coeffsOut []uint64, N int, nttPsiInv []uint64, Q, QInv uint64) {
+	var h, t int
+
 	// Copy the result of the first round of butterflies on p2 with approximate reduction
 	t = 1
 	h = N >> 1
@@ -403,35 +457,13 @@ func invNTTCore(coeffsIn, coeffsOut []uint64, N int, nttPsiInv []uint64, Q, QInv
 		xout[14], xout[15] = invbutterfly(xin[14], xin[15], psi[7], twoQ, fourQ, Q, QInv)
 	}
 
-	// Continue the rest of the second to the n-1 butterflies on p2 with approximate reduction
+	// Continue the second and third rounds of butterflies on p2 with approximate reduction
 	t <<= 1
-	for m := N >> 1; m > 1; m >>= 1 {
+	for m := N >> 1; m > N>>3; m >>= 1 {
 
 		h = m >> 1
 
-		if t >= 8 {
-
-			for i, j1, j2 := 0, 0, t-1; i < h; i, j1, j2 = i+1, j1+2*t, j2+2*t {
-
-				F = nttPsiInv[h+i]
-
-				for jx, jy := j1, j1+t; jx <= j2; jx, jy = jx+8, jy+8 {
-
-					x := (*[8]uint64)(unsafe.Pointer(&coeffsOut[jx]))
-					y := (*[8]uint64)(unsafe.Pointer(&coeffsOut[jy]))
-
-					x[0], y[0] = invbutterfly(x[0], y[0], F, twoQ, fourQ, Q, QInv)
-					x[1], y[1] = invbutterfly(x[1], y[1], F, twoQ, fourQ, Q, QInv)
-					x[2], y[2] = invbutterfly(x[2], y[2], F, twoQ, fourQ, Q, QInv)
-					x[3], y[3] = invbutterfly(x[3], y[3], F, twoQ, fourQ, Q, QInv)
-					x[4], y[4] = invbutterfly(x[4], y[4], F, twoQ, fourQ, Q, QInv)
-					x[5], y[5] = invbutterfly(x[5], y[5], F, twoQ, fourQ, Q, QInv)
-					x[6], y[6] = invbutterfly(x[6], y[6], F, twoQ, fourQ, Q, QInv)
-					x[7], y[7] = invbutterfly(x[7], y[7], F, twoQ, fourQ, Q, QInv)
-				}
-			}
-
-		} else if t == 4 {
+		if t == 4 {
 
 			for i, j1 := h, 0; i < 2*h; i, j1 = i+2, j1+4*t {
 
@@ -105,6 +105,103 @@ func (rntt NumberTheoreticTransformerStandard) BackwardLazyVec(r *Ring, level in
 	InvNTTLazy(p1, p2, r.N, r.NttPsiInv[level], r.NttNInv[level], r.Modulus[level], r.MredParams[level])
 }
 
+// NumberTheoreticTransformerVectorized computes the standard nega-cyclic NTT in the ring Z[X]/(X^N+1) with the SIMD
+// kernels of the CPU. The results are identical to the ones of NumberTheoreticTransformerStandard, which is used as a
+// fallback if the CPU does not support the kernels. The only kernels are for AVX-512F and AVX-512DQ on amd64: there is
+// no AVX2 or NEON path of the NTT (see simd.go).
+// A ring using this transform is of type Standard. It is the transform of the Standard rings created by NewRing,
+// NewRingFromType, StandardRing and UnmarshalBinary when the CPU supports the kernels.
+type NumberTheoreticTransformerVectorized struct {
+}
+
+// standardNTT returns the transform of the Standard rings: NumberTheoreticTransformerVectorized if the CPU supports
+// its kernels and NumberTheoreticTransformerStandard otherwise.
+func standardNTT() NumberTheoreticTransformer {
+	if hasNTTKernels() {
+		return NumberTheoreticTransformerVectorized{}
+	}
+	return NumberTheoreticTransformerStandard{}
+}
+
+// Forward writes the forward NTT in Z[X]/(X^N+1) of p1 on p2.
+func (rntt NumberTheoreticTransformerVectorized) Forward(r *Ring, p1, p2 *Poly) {
+	rntt.ForwardLvl(r, len(r.Modulus)-1, p1, p2)
+}
+
+// ForwardLvl writes the forward NTT in Z[X]/(X^N+1) of p1 on p2.
+// Only computes the NTT for the first level+1 moduli.
+func (rntt NumberTheoreticTransformerVectorized) ForwardLvl(r *Ring, level int, p1, p2 *Poly) {
+	for x := 0; x < level+1; x++ {
+		rntt.ForwardVec(r, x, p1.Coeffs[x], p2.Coeffs[x])
+	}
+}
+
+// ForwardLazy writes the forward NTT in Z[X]/(X^N+1) of p1 on p2.
+// Returns values in the range [0, 2q-1].
+func (rntt NumberTheoreticTransformerVectorized) ForwardLazy(r *Ring, p1, p2 *Poly) {
+	rntt.ForwardLazyLvl(r, len(r.Modulus)-1, p1, p2)
+}
+
+// ForwardLazyLvl writes the forward NTT in Z[X]/(X^N+1) of p1 on p2.
+// Only computes the NTT for the first level+1 moduli and returns values in the range [0, 2q-1].
+func (rntt NumberTheoreticTransformerVectorized) ForwardLazyLvl(r *Ring, level int, p1, p2 *Poly) {
+	for x := 0; x < level+1; x++ {
+		rntt.ForwardLazyVec(r, x, p1.Coeffs[x], p2.Coeffs[x])
+	}
+}
+
+// Backward writes the backward NTT in Z[X]/(X^N+1) on p2.
+func (rntt NumberTheoreticTransformerVectorized) Backward(r *Ring, p1, p2 *Poly) {
+	rntt.BackwardLvl(r, len(r.Modulus)-1, p1, p2)
+}
+
+// BackwardLvl writes the backward NTT in Z[X]/(X^N+1) on p2.
+// Only computes the NTT for the first level+1 moduli.
+func (rntt NumberTheoreticTransformerVectorized) BackwardLvl(r *Ring, level int, p1, p2 *Poly) {
+	for x := 0; x < level+1; x++ {
+		rntt.BackwardVec(r, x, p1.Coeffs[x], p2.Coeffs[x])
+	}
+}
+
+// BackwardLazy writes the backward NTT in Z[X]/(X^N+1) on p2.
+// Returns values in the range [0, 2q-1].
+func (rntt NumberTheoreticTransformerVectorized) BackwardLazy(r *Ring, p1, p2 *Poly) {
+	rntt.BackwardLazyLvl(r, len(r.Modulus)-1, p1, p2)
+}
+
+// BackwardLazyLvl writes the backward NTT in Z[X]/(X^N+1) on p2.
+// Only computes the NTT for the first level+1 moduli and returns values in the range [0, 2q-1].
+func (rntt NumberTheoreticTransformerVectorized) BackwardLazyLvl(r *Ring, level int, p1, p2 *Poly) {
+	for x := 0; x < level+1; x++ {
+		rntt.BackwardLazyVec(r, x, p1.Coeffs[x], p2.Coeffs[x])
+	}
+}
+
+// ForwardVec writes the forward NTT in Z[X]/(X^N+1) of the i-th level of p1 on the i-th level of p2.
+func (rntt NumberTheoreticTransformerVectorized) ForwardVec(r *Ring, level int, p1, p2 []uint64) {
+	nttLazyVectorized(p1, p2, r.N, r.NttPsi[level], r.Modulus[level], r.MredParams[level], r.BredParams[level])
+	ReduceVec(p2, p2, r.Modulus[level], r.BredParams[level])
+}
+
+// ForwardLazyVec writes the forward NTT in Z[X]/(X^N+1) of the i-th level of p1 on the i-th level of p2.
+// Returns values in the range [0, 2q-1].
+func (rntt NumberTheoreticTransformerVectorized) ForwardLazyVec(r *Ring, level int, p1, p2 []uint64) {
+	nttLazyVectorized(p1, p2, r.N, r.NttPsi[level], r.Modulus[level], r.MredParams[level], r.BredParams[level])
+}
+
+// BackwardVec writes the backward NTT in Z[X]/(X^N+1) of the i-th level of p1 on the i-th level of p2.
+func (rntt NumberTheoreticTransformerVectorized) BackwardVec(r *Ring, level int, p1, p2 []uint64) {
+	invNTTCoreVectorized(p1, p2, r.N, r.NttPsiInv[level], r.Modulus[level], r.MredParams[level])
+	MulScalarMontgomeryVec(p2, p2, r.NttNInv[level], r.Modulus[level], r.MredParams[level])
+}
+
+// BackwardLazyVec writes the backward NTT in Z[X]/(X^N+1) of the i-th level of p1 on the i-th level of p2.
+// Returns values in the range [0, 2q-1].
+func (rntt NumberTheoreticTransformerVectorized) BackwardLazyVec(r *Ring, level int, p1, p2 []uint64) {
+	invNTTCoreVectorized(p1, p2, r.N, r.NttPsiInv[level], r.Modulus[level], r.MredParams[level])
+	MulScalarMontgomeryConstantVec(p2, p2, r.NttNInv[level], r.Modulus[level], r.MredParams[level])
+}
+
 // NumberTheoreticTransformerConjugateInvariant computes the NTT in the ring Z[X+X^-1]/(X^2N+1).
 // Z[X+X^-1]/(X^2N+1) is a closed sub-ring of Z[X]/(X^2N+1). Note that the input polynomial only needs to be size N
 // since the right half does not provide any additional information.
@@ -314,9 +314,8 @@ func (r *Ring) AddScalarBigint(p1 *Poly, scalar *big.Int, p2 *Poly) {
 
 // AddScalarBigintLvl adds a big.Int scalar to each coefficient of p1 and writes the result on p2.
 func (r *Ring) AddScalarBigintLvl(level int, p1 *Poly, scalar *big.Int, p2 *Poly) {
-	tmp := new(big.Int)
 	for i := 0; i < level+1; i++ {
-		AddScalarVec(p1.Coeffs[i][:r.N], p1.Coeffs[i][:r.N], tmp.Mod(scalar, NewUint(r.Modulus[i])).Uint64(), r.Modulus[i])
+		AddScalarVec(p1.Coeffs[i][:r.N], p1.Coeffs[i][:r.N], bigintMod(scalar, r.Modulus[i]), r.Modulus[i])
 	}
 }
 
@@ -339,9 +338,8 @@ func (r *Ring) SubScalarBigint(p1 *Poly, scalar *big.Int, p2 *Poly) {
 
 // SubScalarBigintLvl subtracts a big.Int scalar from each coefficient of p1 and writes the result on p2.
 func (r *Ring) SubScalarBigintLvl(level int, p1 *Poly, scalar *big.Int, p2 *Poly) {
-	tmp := new(big.Int)
 	for i := 0; i < level+1; i++ {
-		SubScalarVec(p1.Coeffs[i][:r.N], p1.Coeffs[i][:r.N], tmp.Mod(scalar, NewUint(r.Modulus[i])).Uint64(), r.Modulus[i])
+		SubScalarVec(p1.Coeffs[i][:r.N], p1.Coeffs[i][:r.N], bigintMod(scalar, r.Modulus[i]), r.Modulus[i])
 	}
 }
 
@@ -365,13 +363,30 @@ func (r *Ring) MulScalarBigint(p1 *Poly, scalar *big.Int, p2 *Poly) {
 // MulScalarBigintLvl multiplies each coefficient of p1 by a big.Int scalar
 //for the moduli from q_0 up to q_level and writes the result on p2.
 func (r *Ring) MulScalarBigintLvl(level int, p1 *Poly, scalar *big.Int, p2 *Poly) {
-	scalarQi := new(big.Int)
 	for i := 0; i < level+1; i++ {
-		scalarQi.Mod(scalar, NewUint(r.Modulus[i]))
-		MulScalarMontgomeryVec(p1.Coeffs[i][:r.N], p2.Coeffs[i][:r.N], MForm(BRedAdd(scalarQi.Uint64(), r.Modulus[i], r.BredParams[i]), r.Modulus[i], r.BredParams[i]), r.Modulus[i], r.MredParams[i])
+		scalarQi := bigintMod(scalar, r.Modulus[i])
+		MulScalarMontgomeryVec(p1.Coeffs[i][:r.N], p2.Coeffs[i][:r.N], MForm(scalarQi, r.Modulus[i], r.BredParams[i]), r.Modulus[i], r.MredParams[i])
 	}
 }
 
+// bigintMod returns scalar mod q in [0, q-1] without allocating, by Horner's
+// method on the words of scalar.
+func bigintMod(scalar *big.Int, q uint64) (r uint64) {
+	words := scalar.Bits()
+	for i := len(words) - 1; i >= 0; i-- {
+		// r < q, hence r * 2^UintSize + words[i] is reduced with a single 128-bit division
+		if bits.UintSize == 64 {
+			r = bits.Rem64(r, uint64(words[i]), q)
+		} else {
+			r = bits.Rem64(r>>32, r<<32|uint64(words[i]), q)
+		}
+	}
+	if scalar.Sign() < 0 && r != 0 {
+		r = q - r
+	}
+	return
+}
+
 // Shift circulary shifts the coefficients of the polynomial p1 by n positions to the left and writes the result on p2.
 func (r *Ring) Shift(p1 *Poly, n int, p2 *Poly) {
 	mask := (1 << r.N) - 1
@@ -166,6 +166,69 @@ func (pol *Poly) GetCoefficients() (coeffs [][]uint64) {
 	return
 }
 
+// metadataLen returns the number of bytes of the metadata of the polynomial: 4 bytes, plus 4 bytes
+// storing the degree if it is not a power of two.
+func (pol *Poly) metadataLen() int {
+	if N := pol.Degree(); N&(N-1) != 0 {
+		return 8
+	}
+	return 4
+}
+
+// writeMetadata writes the metadata of the polynomial on data and returns the number of written bytes.
+// The first byte stores log2(N), or 0xFF if N is not a power of two, in which case N is stored after the
+// first 4 bytes. The second bit of the third byte is set if the coefficients are written on 32 bits.
+func (pol *Poly) writeMetadata(data []byte, word32 bool) (pointer int) {
+
+	N := pol.Degree()
+
+	data[0] = uint8(bits.Len64(uint64(N)) - 1)
+	data[1] = uint8(pol.LenModuli())
+	if pol.IsNTT {
+		data[2] = 1
+	}
+
+	if word32 {
+		data[2] |= 2
+	}
+
+	if pol.IsMForm {
+		data[3] = 1
+	}
+
+	if N&(N-1) != 0 {
+		data[0] = 0xFF
+		binary.BigEndian.PutUint32(data[4:8], uint32(N))
+		return 8
+	}
+
+	return 4
+}
+
+// readMetadata reads the metadata of a polynomial written by writeMetadata, sets the NTT and Montgomery flags of
+// the polynomial and returns the degree, the number of moduli, whether the coefficients are written on 32 bits
+// and the number of read bytes.
+func (pol *Poly) readMetadata(data []byte) (N, numberModuli int, word32 bool, pointer int) {
+
+	N = int(1 << data[0])
+	numberModuli = int(data[1])
+	word32 = data[2]&2 == 2
+
+	if data[2]&1 == 1 {
+		pol.IsNTT = true
+	}
+
+	if data[3] == 1 {
+		pol.IsMForm = true
+	}
+
+	if data[0] == 0xFF {
+		return int(binary.BigEndian.Uint32(data[4:8])), numberModuli, word32, 8
+	}
+
+	return N, numberModuli, word32, 4
+}
+
 // WriteCoeffsTo converts a matrix of coefficients to a byte array.
 func WriteCoeffsTo(pointer, N, numberModuli int, coeffs [][]uint64, data []byte) (int, error) {
 	tmp := N << 3
@@ -190,22 +253,19 @@ func (pol *Poly) WriteTo(data []byte) (int, error) {
 		// The data is not big enough to write all the information
 		return 0, errors.New("data array is too small to write ring.Poly")
 	}
-	data[0] = uint8(bits.Len64(uint64(N)) - 1)
-	data[1] = uint8(numberModuli)
-	if pol.IsNTT {
-		data[2] = 1
-	}
 
-	if pol.IsMForm {
-		data[3] = 1
-	}
+	pointer := pol.writeMetadata(data, false)
 
-	cnt, err := WriteCoeffsTo(4, N, numberModuli, pol.Coeffs, data)
+	cnt, err := WriteCoeffsTo(pointer, N, numberModuli, pol.Coeffs, data)
 
 	return cnt, err
 }
 
-// WriteTo32 writes the given poly to the data array.
+// MaxModulusSize32 is the largest bit-length of the moduli of the polynomials that can be written with WriteTo32.
+const MaxModulusSize32 = 32
+
+// WriteTo32 writes the given poly to the data array, using 32 bits per coefficient.
+// The polynomial must be defined over moduli smaller than 2^32.
 // It returns the number of written bytes, and the corresponding error, if it occurred.
 func (pol *Poly) WriteTo32(data []byte) (int, error) {
 
@@ -216,17 +276,10 @@ func (pol *Poly) WriteTo32(data []byte) (int, error) {
 		//The data is not big enough to write all the information
 		return 0, errors.New("data array is too small to write ring.Poly")
 	}
-	data[0] = uint8(bits.Len64(uint64(N)) - 1)
-	data[1] = uint8(numberModuli)
-	if pol.IsNTT {
-		data[2] = 1
-	}
 
-	if pol.IsMForm {
-		data[3] = 1
-	}
+	pointer := pol.writeMetadata(data, true)
 
-	cnt, err := WriteCoeffsTo32(4, N, numberModuli, pol.Coeffs, data)
+	cnt, err := WriteCoeffsTo32(pointer, N, numberModuli, pol.Coeffs, data)
 
 	return cnt, err
 }
@@ -250,7 +303,7 @@ func (pol *Poly) GetDataLen32(WithMetadata bool) (cnt int) {
 	cnt = (pol.LenModuli() * pol.Degree()) << 2
 
 	if WithMetadata {
-		cnt += 4
+		cnt += pol.metadataLen()
 	}
 	return
 }
@@ -268,7 +321,7 @@ func (pol *Poly) GetDataLen(WithMetadata bool) (cnt int) {
 	cnt = (pol.LenModuli() * pol.Degree()) << 3
 
 	if WithMetadata {
-		cnt += 4
+		cnt += pol.metadataLen()
 	}
 	return
 }
@@ -310,20 +363,18 @@ func (pol *Poly) MarshalBinary() (data []byte, err error) {
 // UnmarshalBinary decodes a slice of byte on the target polynomial.
 func (pol *Poly) UnmarshalBinary(data []byte) (err error) {
 
-	N := int(1 << data[0])
-	numberModulies := int(data[1])
-
-	if data[2] == 1 {
-		pol.IsNTT = true
+	if len(data) < 4 {
+		return errors.New("invalid polynomial encoding")
 	}
 
-	if data[3] == 1 {
-		pol.IsMForm = true
-	}
+	N, numberModulies, word32, pointer := pol.readMetadata(data)
 
-	pointer := 4
+	wordSize := 3
+	if word32 {
+		wordSize = 2
+	}
 
-	if ((len(data) - pointer) >> 3) != N*numberModulies {
+	if ((len(data) - pointer) >> wordSize) != N*numberModulies {
 		return errors.New("invalid polynomial encoding")
 	}
 
@@ -335,26 +386,19 @@ func (pol *Poly) UnmarshalBinary(data []byte) (err error) {
 }
 
 // DecodePolyNew decodes a slice of bytes in the target polynomial returns the number of bytes
-// decoded.
+// decoded. Polynomials written with WriteTo32 are recognized and decoded from their 32-bit encoding.
 func (pol *Poly) DecodePolyNew(data []byte) (pointer int, err error) {
 
-	N := int(1 << data[0])
-	numberModulies := int(data[1])
-
-	if data[2] == 1 {
-		pol.IsNTT = true
-	}
-
-	if data[3] == 1 {
-		pol.IsMForm = true
-	}
-
-	pointer = 4
+	N, numberModulies, word32, pointer := pol.readMetadata(data)
 
 	if pol.Coeffs == nil {
 		pol.Coeffs = make([][]uint64, numberModulies)
 	}
 
+	if word32 {
+		return DecodeCoeffsNew32(pointer, N, numberModulies, pol.Coeffs, data)
+	}
+
 	if pointer, err = DecodeCoeffsNew(pointer, N, numberModulies, pol.Coeffs, data); err != nil {
 		return pointer, err
 	}
@@ -366,18 +410,7 @@ func (pol *Poly) DecodePolyNew(data []byte) (pointer int, err error) {
 // decoded.
 func (pol *Poly) DecodePolyNew32(data []byte) (pointer int, err error) {
 
-	N := int(1 << data[0])
-	numberModulies := int(data[1])
-
-	if data[2] == 1 {
-		pol.IsNTT = true
-	}
-
-	if data[3] == 1 {
-		pol.IsMForm = true
-	}
-
-	pointer = 4
+	N, numberModulies, _, pointer := pol.readMetadata(data)
 
 	if pol.Coeffs == nil {
 		pol.Coeffs = make([][]uint64, numberModulies)
@@ -0,0 +1,125 @@
+package ring
+
+// PolyPool is a memory pool of polynomials of a Ring, from which the evaluators
+// draw their temporary polynomials and to which they release them once done.
+//
+// The pool keeps one list of free polynomials per level. A polynomial obtained
+// from the pool must be released at most once and must not be used after its release.
+//
+// In pool mode (see NewPolyPool), the pool allocates a new polynomial when no free
+// polynomial of a sufficient level is available, so that it grows to the peak
+// number of polynomials in use by the circuit and then stops allocating.
+// In arena mode (see NewPolyArena), all the polynomials are carved at creation
+// from a single contiguous buffer, and the pool panics when it is exhausted.
+//
+// A PolyPool is not safe for concurrent use.
+type PolyPool struct {
+	ring     *Ring
+	free     [][]*Poly
+	arena    bool
+	inUse    int
+	maxInUse int
+}
+
+// NewPolyPool creates a new empty PolyPool for the Ring r, which allocates new
+// polynomials on demand.
+func NewPolyPool(r *Ring) *PolyPool {
+	return &PolyPool{ring: r, free: make([][]*Poly, len(r.Modulus))}
+}
+
+// NewPolyArena creates a new PolyPool for the Ring r which holds size polynomials
+// at the maximum level, all allocated in a single contiguous buffer.
+// The pool never allocates afterwards and panics if more than size polynomials
+// are in use at the same time.
+func NewPolyArena(r *Ring, size int) *PolyPool {
+
+	pool := NewPolyPool(r)
+	pool.arena = true
+
+	levels := len(r.Modulus)
+
+	buff := make([]uint64, size*levels*r.N)
+	coeffs := make([][]uint64, size*levels)
+	polys := make([]Poly, size)
+
+	free := make([]*Poly, size)
+	for i := range polys {
+		polys[i].Coeffs = coeffs[i*levels : (i+1)*levels : (i+1)*levels]
+		for j := range polys[i].Coeffs {
+			start := (i*levels + j) * r.N
+			polys[i].Coeffs[j] = buff[start : start+r.N : start+r.N]
+		}
+		free[i] = &polys[i]
+	}
+
+	pool.free[levels-1] = free
+
+	return pool
+}
+
+// Ring returns the Ring of the polynomials of the pool.
+func (pool *PolyPool) Ring() *Ring {
+	return pool.ring
+}
+
+// InUse returns the number of polynomials obtained from the pool and not yet released.
+func (pool *PolyPool) InUse() int {
+	return pool.inUse
+}
+
+// MaxInUse returns the peak number of polynomials in use since the creation of the pool.
+// It is the size of the arena needed to evaluate the same circuit (see NewPolyArena).
+func (pool *PolyPool) MaxInUse() int {
+	return pool.maxInUse
+}
+
+// GetPoly returns a polynomial at the maximum level from the pool.
+func (pool *PolyPool) GetPoly() *Poly {
+	return pool.GetPolyLvl(len(pool.ring.Modulus) - 1)
+}
+
+// GetPolyLvl returns a polynomial at the given level from the pool.
+// The coefficients of the returned polynomial are not specified and
+// its flags IsNTT and IsMForm are set to false.
+func (pool *PolyPool) GetPolyLvl(level int) (p *Poly) {
+
+	for i := level; i < len(pool.free); i++ {
+		if n := len(pool.free[i]); n != 0 {
+			p = pool.free[i][n-1]
+			pool.free[i][n-1] = nil
+			pool.free[i] = pool.free[i][:n-1]
+			break
+		}
+	}
+
+	if p == nil {
+		if pool.arena {
+			panic("cannot GetPolyLvl: the arena is exhausted")
+		}
+		p = pool.ring.NewPolyLvl(level)
+	}
+
+	p.Coeffs = p.Coeffs[:level+1]
+	p.IsNTT = false
+	p.IsMForm = false
+
+	if pool.inUse++; pool.inUse > pool.maxInUse {
+		pool.maxInUse = pool.inUse
+	}
+
+	return
+}
+
+// Release returns the polynomials, which must have been obtained from the pool, to the pool.
+// Nil polynomials are ignored.
+func (pool *PolyPool) Release(polys ...*Poly) {
+	for _, p := range polys {
+		if p == nil {
+			continue
+		}
+		p.Coeffs = p.Coeffs[:cap(p.Coeffs)]
+		level := p.Level()
+		pool.free[level] = append(pool.free[level], p)
+		pool.inUse--
+	}
+}
@@ -1,6 +1,9 @@
 package ring
 
 import (
+	"encoding/json"
+	"fmt"
+
 	"github.com/tuneinsight/lattigo/v3/utils"
 )
 
@@ -17,3 +20,85 @@ type baseSampler struct {
 type Sampler interface {
 	Read(pOut *Poly)
 }
+
+// DistributionSampler is an interface for the polynomial samplers of a Distribution.
+type DistributionSampler interface {
+	Sampler
+	ReadNew() (pol *Poly)
+	ReadLvl(level int, pol *Poly)
+	ReadLvlNew(level int) (pol *Poly)
+	ReadAndAddLvl(level int, pol *Poly)
+}
+
+// DiscreteGaussianSampler is an interface for truncated discrete Gaussian polynomial samplers.
+type DiscreteGaussianSampler interface {
+	DistributionSampler
+}
+
+// GaussianSamplerType is the type of algorithm used to sample truncated discrete Gaussian polynomials.
+type GaussianSamplerType int
+
+// GaussianFloat, GaussianCDT and GaussianConvolution are the types of discrete Gaussian samplers.
+const (
+	GaussianFloat       = GaussianSamplerType(0) // float64 rejection sampling, see GaussianSampler (Default)
+	GaussianCDT         = GaussianSamplerType(1) // constant-time cumulative distribution table, see CDTGaussianSampler
+	GaussianConvolution = GaussianSamplerType(2) // constant-time convolution of CDT samples, see ConvolutionGaussianSampler
+)
+
+// String returns the string representation of the GaussianSamplerType.
+func (gt GaussianSamplerType) String() string {
+	switch gt {
+	case GaussianFloat:
+		return "Float"
+	case GaussianCDT:
+		return "CDT"
+	case GaussianConvolution:
+		return "Convolution"
+	default:
+		return "Invalid"
+	}
+}
+
+// UnmarshalJSON reads a JSON byte slice into the receiver GaussianSamplerType.
+func (gt *GaussianSamplerType) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	switch s {
+	default:
+		return fmt.Errorf("invalid Gaussian sampler type: %s", s)
+	case "Float":
+		*gt = GaussianFloat
+	case "CDT":
+		*gt = GaussianCDT
+	case "Convolution":
+		*gt = GaussianConvolution
+	}
+
+	return nil
+}
+
+// MarshalJSON marshals the receiver GaussianSamplerType into a JSON []byte.
+func (gt GaussianSamplerType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(gt.String())
+}
+
+// NewDiscreteGaussianSampler creates a new truncated discrete Gaussian sampler of the given type from a PRNG, a ring
+// definition, the standard deviation sigma and the bound on the norm of the coefficients.
+// For the GaussianCDT type, bounds larger than MaxCDTBound are sampled with the GaussianConvolution type.
+func NewDiscreteGaussianSampler(prng utils.PRNG, baseRing *Ring, sigma, bound float64, samplerType GaussianSamplerType) DiscreteGaussianSampler {
+	switch samplerType {
+	case GaussianFloat:
+		return NewGaussianSampler(prng, baseRing, sigma, int(bound))
+	case GaussianCDT:
+		if bound > MaxCDTBound {
+			return NewConvolutionGaussianSampler(prng, baseRing, sigma, bound)
+		}
+		return NewCDTGaussianSampler(prng, baseRing, sigma, int(bound))
+	case GaussianConvolution:
+		return NewConvolutionGaussianSampler(prng, baseRing, sigma, bound)
+	default:
+		panic(fmt.Sprintf("invalid Gaussian sampler type: %d", samplerType))
+	}
+}
@@ -0,0 +1,166 @@
+package ring
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// MaxCDTBound is the largest bound supported by the CDTGaussianSampler.
+const MaxCDTBound = 512
+
+// CDTGaussianSampler keeps the state of a constant-time truncated discrete Gaussian polynomial sampler based
+// on a cumulative distribution table. The time taken to sample a coefficient depends only on the bound, which
+// makes it suited for small standard deviations.
+type CDTGaussianSampler struct {
+	baseSampler
+	table        []uint64
+	randomBuffer []byte
+	ptr          int
+	signs        uint64
+	signsLeft    int
+}
+
+// NewCDTGaussianSampler creates a new instance of CDTGaussianSampler from a PRNG, a ring definition and the truncated
+// Gaussian distribution parameters. Sigma is the desired standard deviation and bound is the maximum coefficient norm
+// in absolute value, which must not exceed MaxCDTBound.
+func NewCDTGaussianSampler(prng utils.PRNG, baseRing *Ring, sigma float64, bound int) *CDTGaussianSampler {
+
+	if bound < 0 || bound > MaxCDTBound {
+		panic(fmt.Sprintf("cannot NewCDTGaussianSampler: bound=%d is not in [0, %d]", bound, MaxCDTBound))
+	}
+
+	cdtSampler := new(CDTGaussianSampler)
+	cdtSampler.prng = prng
+	cdtSampler.baseRing = baseRing
+	cdtSampler.table = genCDT(sigma, bound)
+	cdtSampler.randomBuffer = make([]byte, 1024)
+	cdtSampler.ptr = len(cdtSampler.randomBuffer)
+	return cdtSampler
+}
+
+// genCDT returns the table of the values 2^64 * P(|x| <= k) for k in [0, bound) where x follows the discrete
+// Gaussian distribution of standard deviation sigma truncated at bound.
+func genCDT(sigma float64, bound int) (table []uint64) {
+
+	if sigma == 0 {
+		return []uint64{}
+	}
+
+	// Probabilities of |x| = k, up to normalization
+	rho := make([]float64, bound+1)
+	rho[0] = 1
+	sum := 1.0
+	for k := 1; k <= bound; k++ {
+		rho[k] = 2 * math.Exp(-float64(k*k)/(2*sigma*sigma))
+		sum += rho[k]
+	}
+
+	// The tail probabilities P(|x| > k) are accumulated from the smallest ones to preserve their precision
+	table = make([]uint64, bound)
+	var tail float64
+	for k := bound - 1; k >= 0; k-- {
+		tail += rho[k+1] / sum
+		if t := uint64(math.Ldexp(tail, 64)); t == 0 {
+			table[k] = math.MaxUint64
+		} else {
+			table[k] = -t
+		}
+	}
+
+	return
+}
+
+// Read samples a truncated discrete Gaussian polynomial on "pol" at the maximum level in the default ring, standard deviation and bound.
+func (cdtSampler *CDTGaussianSampler) Read(pol *Poly) {
+	cdtSampler.ReadLvl(len(cdtSampler.baseRing.Modulus)-1, pol)
+}
+
+// ReadNew samples a new truncated discrete Gaussian polynomial at the maximum level in the default ring, standard deviation and bound.
+func (cdtSampler *CDTGaussianSampler) ReadNew() (pol *Poly) {
+	pol = cdtSampler.baseRing.NewPoly()
+	cdtSampler.Read(pol)
+	return pol
+}
+
+// ReadLvl samples a truncated discrete Gaussian polynomial at the provided level, in the default ring, standard deviation and bound.
+func (cdtSampler *CDTGaussianSampler) ReadLvl(level int, pol *Poly) {
+
+	ringQ := cdtSampler.baseRing
+
+	for i := 0; i < ringQ.N; i++ {
+		mag, sign := cdtSampler.sample()
+		for j, qi := range ringQ.Modulus[:level+1] {
+			pol.Coeffs[j][i] = signedModConstant(mag, sign, qi, ringQ.BredParams[j])
+		}
+	}
+}
+
+// ReadLvlNew samples a new truncated discrete Gaussian polynomial at the provided level, in the default ring, standard deviation and bound.
+func (cdtSampler *CDTGaussianSampler) ReadLvlNew(level int) (pol *Poly) {
+	pol = cdtSampler.baseRing.NewPolyLvl(level)
+	cdtSampler.ReadLvl(level, pol)
+	return pol
+}
+
+// ReadAndAddLvl samples a truncated discrete Gaussian polynomial at the given level for the receiver's default standard deviation and bound and adds it on "pol".
+func (cdtSampler *CDTGaussianSampler) ReadAndAddLvl(level int, pol *Poly) {
+
+	ringQ := cdtSampler.baseRing
+
+	for i := 0; i < ringQ.N; i++ {
+		mag, sign := cdtSampler.sample()
+		for j, qi := range ringQ.Modulus[:level+1] {
+			pol.Coeffs[j][i] = condSubConstant(pol.Coeffs[j][i]+signedModConstant(mag, sign, qi, ringQ.BredParams[j]), qi)
+		}
+	}
+}
+
+// sample returns the absolute value and the sign bit of a sample. The full table is scanned for each sample.
+func (cdtSampler *CDTGaussianSampler) sample() (mag, sign uint64) {
+
+	r := cdtSampler.readUint64()
+
+	var borrow uint64
+	for _, t := range cdtSampler.table {
+		_, borrow = bits.Sub64(r, t, 0)
+		mag += borrow ^ 1
+	}
+
+	if cdtSampler.signsLeft == 0 {
+		cdtSampler.signs = cdtSampler.readUint64()
+		cdtSampler.signsLeft = 64
+	}
+
+	sign = cdtSampler.signs & 1
+	cdtSampler.signs >>= 1
+	cdtSampler.signsLeft--
+
+	return
+}
+
+func (cdtSampler *CDTGaussianSampler) readUint64() (r uint64) {
+	if cdtSampler.ptr == len(cdtSampler.randomBuffer) {
+		cdtSampler.prng.Clock(cdtSampler.randomBuffer)
+		cdtSampler.ptr = 0
+	}
+	r = binary.BigEndian.Uint64(cdtSampler.randomBuffer[cdtSampler.ptr : cdtSampler.ptr+8])
+	cdtSampler.ptr += 8
+	return
+}
+
+// signedModConstant returns (-1)^sign * mag mod q in constant time.
+func signedModConstant(mag, sign, q uint64, u []uint64) uint64 {
+	mag = condSubConstant(BRedAddConstant(mag, q, u), q)
+	mask := -sign
+	return condSubConstant((mag&^mask)|((q-mag)&mask), q)
+}
+
+// condSubConstant returns a mod q in constant time, where a is between 0 and 2*q-1.
+func condSubConstant(a, q uint64) uint64 {
+	d, borrow := bits.Sub64(a, q, 0)
+	return d + (q & -borrow)
+}
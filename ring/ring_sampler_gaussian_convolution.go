@@ -0,0 +1,123 @@
+package ring
+
+import (
+	"math"
+
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+const (
+	// convolutionBase is the base k of the convolution x = sum_j k^j * x_j.
+	convolutionBase = 4
+	// convolutionSmoothing is the smoothing parameter eta_eps(Z) = sqrt(ln(2 + 2/eps)/pi) of the integers for eps = 2^-64.
+	convolutionSmoothing = 3.787
+	// convolutionMinBaseSigma is the smallest standard deviation sqrt(2) * k * eta_eps(Z) of the samples x_j for which
+	// x_j + k * x' is statistically close to a discrete Gaussian sample.
+	convolutionMinBaseSigma = math.Sqrt2 * convolutionBase * convolutionSmoothing
+)
+
+// ConvolutionGaussianSampler keeps the state of a constant-time discrete Gaussian polynomial sampler for arbitrarily large
+// standard deviations. A sample is the convolution x = sum_j 4^j * x_j of samples x_j of a CDTGaussianSampler of
+// standard deviation sigma/sqrt(sum_j 4^(2j)). The number of digits x_j is the largest for which this standard deviation
+// is at least sqrt(2) * 4 * eta_eps(Z) ~ 21.4 with eps = 2^-64, so that each of the convolution steps adds a statistical
+// distance of at most about eps to the discrete Gaussian distribution of standard deviation sigma (see Micciancio and
+// Walter, "Gaussian sampling over the integers: efficient, generic, constant-time", CRYPTO 2017). Standard deviations
+// smaller than sqrt(17) times this bound, about 88, are sampled with a single digit, i.e. directly by the CDTGaussianSampler.
+// The samples are computed directly in the RNS basis and can therefore span several moduli.
+//
+// The samples x_j are truncated at min(bound/sum_j 4^j, MaxCDTBound), so that the samples are bounded by bound in
+// absolute value.
+type ConvolutionGaussianSampler struct {
+	baseRing  *Ring
+	base      *CDTGaussianSampler
+	baseSigma float64
+	mags      []uint64
+	signs     []uint64
+}
+
+// NewConvolutionGaussianSampler creates a new instance of ConvolutionGaussianSampler from a PRNG, a ring definition and the
+// truncated Gaussian distribution parameters. Sigma is the desired standard deviation and bound is the maximum coefficient
+// norm in absolute value.
+func NewConvolutionGaussianSampler(prng utils.PRNG, baseRing *Ring, sigma, bound float64) *ConvolutionGaussianSampler {
+
+	// Finds the largest number of digits for which the base standard deviation is large enough
+	digits := 1
+	sumSquares, sum := 1.0, 1.0
+	for sigma/math.Sqrt(sumSquares*convolutionBase*convolutionBase+1) >= convolutionMinBaseSigma {
+		sumSquares = sumSquares*convolutionBase*convolutionBase + 1
+		sum = sum*convolutionBase + 1
+		digits++
+	}
+
+	baseSigma := sigma / math.Sqrt(sumSquares)
+	baseBound := int(math.Min(math.Floor(bound/sum), MaxCDTBound))
+
+	return &ConvolutionGaussianSampler{
+		baseRing:  baseRing,
+		base:      NewCDTGaussianSampler(prng, baseRing, baseSigma, baseBound),
+		baseSigma: baseSigma,
+		mags:      make([]uint64, digits),
+		signs:     make([]uint64, digits),
+	}
+}
+
+// Read samples a discrete Gaussian polynomial on "pol" at the maximum level in the default ring and standard deviation.
+func (convSampler *ConvolutionGaussianSampler) Read(pol *Poly) {
+	convSampler.ReadLvl(len(convSampler.baseRing.Modulus)-1, pol)
+}
+
+// ReadNew samples a new discrete Gaussian polynomial at the maximum level in the default ring and standard deviation.
+func (convSampler *ConvolutionGaussianSampler) ReadNew() (pol *Poly) {
+	pol = convSampler.baseRing.NewPoly()
+	convSampler.Read(pol)
+	return pol
+}
+
+// ReadLvl samples a discrete Gaussian polynomial at the provided level, in the default ring and standard deviation.
+func (convSampler *ConvolutionGaussianSampler) ReadLvl(level int, pol *Poly) {
+
+	ringQ := convSampler.baseRing
+
+	for i := 0; i < ringQ.N; i++ {
+		convSampler.sample()
+		for j, qi := range ringQ.Modulus[:level+1] {
+			pol.Coeffs[j][i] = convSampler.horner(qi, ringQ.BredParams[j])
+		}
+	}
+}
+
+// ReadLvlNew samples a new discrete Gaussian polynomial at the provided level, in the default ring and standard deviation.
+func (convSampler *ConvolutionGaussianSampler) ReadLvlNew(level int) (pol *Poly) {
+	pol = convSampler.baseRing.NewPolyLvl(level)
+	convSampler.ReadLvl(level, pol)
+	return pol
+}
+
+// ReadAndAddLvl samples a discrete Gaussian polynomial at the given level for the receiver's default standard deviation and adds it on "pol".
+func (convSampler *ConvolutionGaussianSampler) ReadAndAddLvl(level int, pol *Poly) {
+
+	ringQ := convSampler.baseRing
+
+	for i := 0; i < ringQ.N; i++ {
+		convSampler.sample()
+		for j, qi := range ringQ.Modulus[:level+1] {
+			pol.Coeffs[j][i] = condSubConstant(pol.Coeffs[j][i]+convSampler.horner(qi, ringQ.BredParams[j]), qi)
+		}
+	}
+}
+
+// sample draws the digits x_j of a sample.
+func (convSampler *ConvolutionGaussianSampler) sample() {
+	for j := range convSampler.mags {
+		convSampler.mags[j], convSampler.signs[j] = convSampler.base.sample()
+	}
+}
+
+// horner returns sum_j 4^j * x_j mod q in constant time.
+func (convSampler *ConvolutionGaussianSampler) horner(q uint64, u []uint64) (acc uint64) {
+	for j := len(convSampler.mags) - 1; j >= 0; j-- {
+		acc = condSubConstant(BRedAddConstant(acc*convolutionBase, q, u), q)
+		acc = condSubConstant(acc+signedModConstant(convSampler.mags[j], convSampler.signs[j], q, u), q)
+	}
+	return
+}
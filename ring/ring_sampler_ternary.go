@@ -15,6 +15,7 @@ type TernarySampler struct {
 	p            float64
 	hw           int
 	sample       func(lvl int, poly *Poly)
+	buff         *Poly
 }
 
 // NewTernarySampler creates a new instance of TernarySampler from a PRNG, the ring definition and the distribution
@@ -75,6 +76,15 @@ func (ts *TernarySampler) ReadLvlNew(lvl int) (pol *Poly) {
 	return pol
 }
 
+// ReadAndAddLvl samples a polynomial at the specified level and adds it on pol.
+func (ts *TernarySampler) ReadAndAddLvl(lvl int, pol *Poly) {
+	if ts.buff == nil {
+		ts.buff = ts.baseRing.NewPoly()
+	}
+	ts.sample(lvl, ts.buff)
+	ts.baseRing.AddLvl(lvl, pol, ts.buff, pol)
+}
+
 func (ts *TernarySampler) initializeMatrix(montgomery bool) {
 	ts.matrixValues = make([][3]uint64, len(ts.baseRing.Modulus))
 
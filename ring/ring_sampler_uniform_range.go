@@ -0,0 +1,118 @@
+package ring
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// UniformRangeSampler keeps the state of a polynomial sampler with coefficients following a uniform distribution
+// over the integer range [min, max].
+type UniformRangeSampler struct {
+	baseSampler
+	min          int64
+	width        uint64
+	mask         uint64
+	randomBuffer []byte
+	ptr          int
+	buff         *Poly
+}
+
+// NewUniformRangeSampler creates a new instance of UniformRangeSampler from a PRNG, the ring definition and the
+// bounds of the range [min, max].
+func NewUniformRangeSampler(prng utils.PRNG, baseRing *Ring, min, max int64) *UniformRangeSampler {
+
+	if min > max {
+		panic(fmt.Sprintf("cannot NewUniformRangeSampler: min=%d is larger than max=%d", min, max))
+	}
+
+	rangeSampler := new(UniformRangeSampler)
+	rangeSampler.prng = prng
+	rangeSampler.baseRing = baseRing
+	rangeSampler.min = min
+	rangeSampler.width = uint64(max-min) + 1
+
+	// width = 0 if the range spans the full int64 range
+	if rangeSampler.width == 0 {
+		rangeSampler.mask = math.MaxUint64
+	} else {
+		rangeSampler.mask = (1 << bits.Len64(rangeSampler.width-1)) - 1
+	}
+
+	rangeSampler.randomBuffer = make([]byte, 1024)
+	rangeSampler.ptr = len(rangeSampler.randomBuffer)
+	return rangeSampler
+}
+
+// NewBinarySampler creates a new instance of UniformRangeSampler with coefficients following a uniform distribution over {0, 1}.
+func NewBinarySampler(prng utils.PRNG, baseRing *Ring) *UniformRangeSampler {
+	return NewUniformRangeSampler(prng, baseRing, 0, 1)
+}
+
+// Read samples a polynomial into pol at the maximum level.
+func (rangeSampler *UniformRangeSampler) Read(pol *Poly) {
+	rangeSampler.ReadLvl(len(rangeSampler.baseRing.Modulus)-1, pol)
+}
+
+// ReadNew allocates and samples a polynomial at the maximum level.
+func (rangeSampler *UniformRangeSampler) ReadNew() (pol *Poly) {
+	pol = rangeSampler.baseRing.NewPoly()
+	rangeSampler.Read(pol)
+	return pol
+}
+
+// ReadLvl samples a polynomial into pol at the specified level.
+func (rangeSampler *UniformRangeSampler) ReadLvl(level int, pol *Poly) {
+
+	ringQ := rangeSampler.baseRing
+
+	for i := 0; i < ringQ.N; i++ {
+
+		coeff := rangeSampler.min + int64(rangeSampler.sample())
+
+		for j, qi := range ringQ.Modulus[:level+1] {
+			if coeff < 0 {
+				// -coeff is computed on uint64 to handle coeff = math.MinInt64
+				pol.Coeffs[j][i] = CRed(qi-BRedAdd(-uint64(coeff), qi, ringQ.BredParams[j]), qi)
+			} else {
+				pol.Coeffs[j][i] = BRedAdd(uint64(coeff), qi, ringQ.BredParams[j])
+			}
+		}
+	}
+}
+
+// ReadLvlNew allocates and samples a polynomial at the specified level.
+func (rangeSampler *UniformRangeSampler) ReadLvlNew(level int) (pol *Poly) {
+	pol = rangeSampler.baseRing.NewPolyLvl(level)
+	rangeSampler.ReadLvl(level, pol)
+	return pol
+}
+
+// ReadAndAddLvl samples a polynomial at the specified level and adds it on pol.
+func (rangeSampler *UniformRangeSampler) ReadAndAddLvl(level int, pol *Poly) {
+	if rangeSampler.buff == nil {
+		rangeSampler.buff = rangeSampler.baseRing.NewPoly()
+	}
+	rangeSampler.ReadLvl(level, rangeSampler.buff)
+	rangeSampler.baseRing.AddLvl(level, pol, rangeSampler.buff, pol)
+}
+
+// sample returns a uniform value in [0, width-1] by rejection sampling.
+func (rangeSampler *UniformRangeSampler) sample() (r uint64) {
+	for {
+		if rangeSampler.ptr == len(rangeSampler.randomBuffer) {
+			rangeSampler.prng.Clock(rangeSampler.randomBuffer)
+			rangeSampler.ptr = 0
+		}
+
+		r = binary.BigEndian.Uint64(rangeSampler.randomBuffer[rangeSampler.ptr:rangeSampler.ptr+8]) & rangeSampler.mask
+		rangeSampler.ptr += 8
+
+		if rangeSampler.width == 0 || r < rangeSampler.width {
+			return
+		}
+	}
+}
@@ -1,8 +1,10 @@
 package ring
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
+	"math"
 	"math/big"
 	"testing"
 
@@ -76,6 +78,7 @@ func TestRing(t *testing.T) {
 		testUniformSampler(testContext, t)
 		testGaussianSampler(testContext, t)
 		testTernarySampler(testContext, t)
+		testDistribution(testContext, t)
 		testGaloisShift(testContext, t)
 		testModularReduction(testContext, t)
 		testMForm(testContext, t)
@@ -83,6 +86,8 @@ func TestRing(t *testing.T) {
 		testExtendBasis(testContext, t)
 		testScaling(testContext, t)
 		testMultByMonomial(testContext, t)
+		testPolyPool(testContext, t)
+		testCRT(testContext, t)
 	}
 }
 
@@ -322,6 +327,25 @@ func testMarshalBinary(testContext *testParams, t *testing.T) {
 			require.Equal(t, p.Coeffs[i][:testContext.ringQ.N], pTest.Coeffs[i][:testContext.ringQ.N])
 		}
 	})
+
+	t.Run(testString("MarshalBinary/Poly32/", testContext.ringQ), func(t *testing.T) {
+
+		p := testContext.uniformSamplerQ.ReadNew()
+		for i := range p.Coeffs {
+			for j := range p.Coeffs[i] {
+				p.Coeffs[i][j] &= 0xFFFFFFFF
+			}
+		}
+
+		data := make([]byte, p.GetDataLen32(true))
+		_, err := p.WriteTo32(data)
+		require.NoError(t, err)
+
+		// the 32-bit encoding is recognized by the default decoding
+		pTest := new(Poly)
+		require.NoError(t, pTest.UnmarshalBinary(data))
+		require.True(t, testContext.ringQ.Equal(p, pTest))
+	})
 }
 
 func testUniformSampler(testContext *testParams, t *testing.T) {
@@ -358,6 +382,76 @@ func testGaussianSampler(testContext *testParams, t *testing.T) {
 			}
 		}
 	})
+	// Returns the centered coefficients of pol and their standard deviation
+	centeredStd := func(pol *Poly) (coeffs []*big.Int, std float64) {
+		coeffs = make([]*big.Int, testContext.ringQ.N)
+		for i := range coeffs {
+			coeffs[i] = new(big.Int)
+		}
+		testContext.ringQ.PolyToBigintCenteredLvl(pol.Level(), pol, 1, coeffs)
+		sum := new(big.Float)
+		for _, c := range coeffs {
+			f := new(big.Float).SetInt(c)
+			sum.Add(sum, f.Mul(f, f))
+		}
+		variance, _ := sum.Quo(sum, new(big.Float).SetInt64(int64(len(coeffs)))).Float64()
+		return coeffs, math.Sqrt(variance)
+	}
+
+	t.Run(testString("CDTGaussianSampler/", testContext.ringQ), func(t *testing.T) {
+
+		sampler := NewDiscreteGaussianSampler(testContext.prng, testContext.ringQ, DefaultSigma, float64(DefaultBound), GaussianCDT)
+		require.IsType(t, &CDTGaussianSampler{}, sampler)
+
+		pol := sampler.ReadNew()
+
+		// the coefficients are the same integers in every modulus
+		coeffs, std := centeredStd(pol)
+		for i, c := range coeffs {
+			require.LessOrEqual(t, c.CmpAbs(big.NewInt(int64(DefaultBound))), 0)
+			for j, qi := range testContext.ringQ.Modulus {
+				require.Equal(t, new(big.Int).Mod(c, NewUint(qi)).Uint64(), pol.Coeffs[j][i])
+			}
+		}
+
+		require.InDelta(t, DefaultSigma, std, 0.5)
+
+		polAdd := pol.CopyNew()
+		sampler.ReadAndAddLvl(pol.Level(), polAdd)
+		testContext.ringQ.Sub(polAdd, pol, polAdd)
+		_, std = centeredStd(polAdd)
+		require.InDelta(t, DefaultSigma, std, 0.5)
+	})
+
+	t.Run(testString("ConvolutionGaussianSampler/", testContext.ringQ), func(t *testing.T) {
+
+		require.IsType(t, &ConvolutionGaussianSampler{}, NewDiscreteGaussianSampler(testContext.prng, testContext.ringQ, 1024, 6*1024, GaussianCDT))
+
+		// The standard deviation of the digits is never below the minimum of the convolution
+		for _, sigma := range []float64{3.2, 65, 88, 89, 1024, math.Exp2(40), math.Exp2(70)} {
+			sampler := NewConvolutionGaussianSampler(testContext.prng, testContext.ringQ, sigma, 6*sigma)
+			if len(sampler.mags) > 1 {
+				require.GreaterOrEqual(t, sampler.baseSigma, float64(convolutionMinBaseSigma))
+			} else {
+				require.Equal(t, sigma, sampler.baseSigma)
+			}
+			require.LessOrEqual(t, sampler.baseSigma, math.Sqrt(convolutionBase*convolutionBase+1)*convolutionMinBaseSigma)
+		}
+
+		// sigma spans several moduli when possible
+		logSigma := utils.MinInt(70, testContext.ringQ.ModulusBigint.BitLen()-8)
+		sigma := math.Exp2(float64(logSigma))
+
+		sampler := NewDiscreteGaussianSampler(testContext.prng, testContext.ringQ, sigma, 6*sigma, GaussianConvolution)
+
+		coeffs, std := centeredStd(sampler.ReadNew())
+		bound := new(big.Float).SetFloat64(6 * sigma)
+		for _, c := range coeffs {
+			require.LessOrEqual(t, new(big.Float).Abs(new(big.Float).SetInt(c)).Cmp(bound), 0)
+		}
+
+		require.InDelta(t, 1, std/sigma, 0.1)
+	})
 }
 
 func testTernarySampler(testContext *testParams, t *testing.T) {
@@ -414,6 +508,83 @@ func testTernarySampler(testContext *testParams, t *testing.T) {
 	}
 }
 
+func testDistribution(testContext *testParams, t *testing.T) {
+
+	ringQ := testContext.ringQ
+
+	for _, d := range []Distribution{
+		{Type: TernaryProbability, P: 1. / 3.},
+		{Type: TernaryHammingWeight, H: 64},
+		{Type: Binary},
+		{Type: UniformRange, Min: -5, Max: 2},
+		{Type: DiscreteGaussian, Sigma: 3.2, Bound: 19},
+	} {
+		t.Run(testString(fmt.Sprintf("Distribution/%s/", d.Type), ringQ), func(t *testing.T) {
+
+			require.NoError(t, d.Validate(ringQ.N))
+
+			pol := ringQ.NewPoly()
+			d.NewSampler(testContext.prng, ringQ, GaussianCDT).ReadAndAddLvl(len(ringQ.Modulus)-1, pol)
+
+			min, max := int64(math.MaxInt64), int64(math.MinInt64)
+			for j := 0; j < ringQ.N; j++ {
+				for i, qi := range ringQ.Modulus {
+					c := int64(pol.Coeffs[i][j])
+					if pol.Coeffs[i][j] > qi>>1 {
+						c = -int64(qi - pol.Coeffs[i][j])
+					}
+
+					require.Equal(t, pol.Coeffs[0][j] > ringQ.Modulus[0]>>1, c < 0)
+					require.LessOrEqual(t, math.Abs(float64(c)), d.NormBound())
+
+					if c < min {
+						min = c
+					}
+					if c > max {
+						max = c
+					}
+				}
+			}
+
+			switch d.Type {
+			case Binary:
+				require.Equal(t, []int64{0, 1}, []int64{min, max})
+			case UniformRange:
+				require.Equal(t, []int64{d.Min, d.Max}, []int64{min, max})
+			}
+
+			data, err := d.MarshalBinary()
+			require.NoError(t, err)
+			require.Equal(t, d.MarshalBinarySize(), len(data))
+
+			var dTest Distribution
+			require.NoError(t, dTest.UnmarshalBinary(data))
+			require.Equal(t, d, dTest)
+
+			data, err = json.Marshal(d)
+			require.NoError(t, err)
+
+			dTest = Distribution{}
+			require.NoError(t, json.Unmarshal(data, &dTest))
+			require.Equal(t, d, dTest)
+		})
+	}
+
+	t.Run(testString("Distribution/Validate/", ringQ), func(t *testing.T) {
+		for _, d := range []Distribution{
+			{},
+			{Type: TernaryProbability},
+			{Type: TernaryProbability, P: 0.5, H: 1},
+			{Type: TernaryHammingWeight, H: ringQ.N + 1},
+			{Type: UniformRange, Min: 1, Max: 0},
+			{Type: DiscreteGaussian, Sigma: -1},
+		} {
+			require.Error(t, d.Validate(ringQ.N))
+			require.Panics(t, func() { d.NewSampler(testContext.prng, ringQ, GaussianFloat) })
+		}
+	})
+}
+
 func testModularReduction(testContext *testParams, t *testing.T) {
 
 	t.Run(testString("ModularReduction/BRed/", testContext.ringQ), func(t *testing.T) {
@@ -606,6 +777,11 @@ func testMulScalarBigint(testContext *testParams, t *testing.T) {
 		testContext.ringQ.MulScalarBigint(polTest, scalarBigint, polTest)
 
 		require.True(t, testContext.ringQ.Equal(polWant, polTest))
+
+		testContext.ringQ.Neg(polWant, polWant)
+		testContext.ringQ.MulScalarBigint(polTest, scalarBigint.Neg(NewUint(1)), polTest)
+
+		require.True(t, testContext.ringQ.Equal(polWant, polTest))
 	})
 }
 
@@ -772,3 +948,196 @@ func testMultByMonomial(testContext *testParams, t *testing.T) {
 		require.Equal(t, p3Want.Coeffs[0][:testContext.ringQ.N], p3Test.Coeffs[0][:testContext.ringQ.N])
 	})
 }
+
+func testPolyPool(testContext *testParams, t *testing.T) {
+
+	ringQ := testContext.ringQ
+	maxLevel := len(ringQ.Modulus) - 1
+
+	t.Run(testString("PolyPool/Pool/", ringQ), func(t *testing.T) {
+
+		pool := NewPolyPool(ringQ)
+
+		p0 := pool.GetPolyLvl(0)
+		p1 := pool.GetPoly()
+		require.Equal(t, 0, p0.Level())
+		require.Equal(t, maxLevel, p1.Level())
+		require.Equal(t, 2, pool.InUse())
+
+		pool.Release(p0, p1)
+		require.Equal(t, 0, pool.InUse())
+
+		// Released polynomials are reused, at a lower level if needed
+		p1.IsNTT = true
+		require.True(t, p1 == pool.GetPolyLvl(maxLevel))
+		require.False(t, p1.IsNTT)
+		require.True(t, p0 == pool.GetPolyLvl(0))
+		pool.Release(p0, p1)
+
+		// The smallest level is used first
+		require.True(t, p0 == pool.GetPolyLvl(0))
+		require.True(t, p1 == pool.GetPolyLvl(0))
+		require.Equal(t, 0, p1.Level())
+		pool.Release(p0, p1)
+
+		require.True(t, p1 == pool.GetPoly())
+		require.Equal(t, maxLevel, p1.Level())
+		pool.Release(p1)
+
+		require.Equal(t, 2, pool.MaxInUse())
+	})
+
+	t.Run(testString("PolyPool/Arena/", ringQ), func(t *testing.T) {
+
+		arena := NewPolyArena(ringQ, 2)
+
+		p0 := arena.GetPoly()
+		p1 := arena.GetPolyLvl(0)
+		require.Panics(t, func() { arena.GetPoly() })
+
+		// The polynomials of the arena do not overlap
+		testContext.uniformSamplerQ.Read(p0)
+		want := p0.CopyNew()
+		p1.Zero()
+		require.True(t, ringQ.Equal(want, p0))
+
+		arena.Release(p0, p1)
+
+		allocs := testing.AllocsPerRun(10, func() {
+			p0, p1 := arena.GetPoly(), arena.GetPolyLvl(maxLevel>>1)
+			arena.Release(p0, p1)
+		})
+		require.Equal(t, 0.0, allocs)
+		require.Equal(t, 0, arena.InUse())
+	})
+}
+
+func testCRT(testContext *testParams, t *testing.T) {
+
+	ringQ := testContext.ringQ
+	crt := ringQ.CRT()
+
+	t.Run(testString("CRT/NewCRT/", ringQ), func(t *testing.T) {
+		_, err := NewCRT([]uint64{15, 21})
+		require.Error(t, err)
+		_, err = NewCRT([]uint64{1 << 63})
+		require.Error(t, err)
+	})
+
+	t.Run(testString("CRT/BigintVec/", ringQ), func(t *testing.T) {
+
+		v := NewBigintVec(4, 3)
+		values := []*big.Int{
+			NewInt(0),
+			NewInt(-1),
+			new(big.Int).Lsh(NewInt(1), 191),
+			new(big.Int).Neg(new(big.Int).Sub(new(big.Int).Lsh(NewInt(1), 130), NewInt(1))),
+		}
+		v.SetBigints(values)
+
+		have := make([]*big.Int, 4)
+		v.GetBigints(have)
+		for i := range values {
+			require.Zero(t, values[i].Cmp(have[i]))
+		}
+
+		require.Panics(t, func() { v.Set(0, new(big.Int).Lsh(NewInt(1), 192)) })
+	})
+
+	for level := 0; level < len(ringQ.Modulus); level++ {
+
+		Q := NewUint(1)
+		for _, qi := range ringQ.Modulus[:level+1] {
+			Q.Mul(Q, NewUint(qi))
+		}
+		QHalf := new(big.Int).Rsh(Q, 1)
+
+		t.Run(testString(fmt.Sprintf("CRT/Reconstruct/level=%d/", level), ringQ), func(t *testing.T) {
+
+			p := testContext.uniformSamplerQ.ReadNew()
+
+			for _, gap := range []int{1, 4} {
+
+				v := ringQ.NewBigintVecLvl(level, ringQ.N/gap)
+				vCentered := ringQ.NewBigintVecLvl(level, ringQ.N/gap)
+				crt.Reconstruct(level, p, gap, v)
+				crt.ReconstructCentered(level, p, gap, vCentered)
+
+				want := new(big.Int)
+				have := new(big.Int)
+				tmp := new(big.Int)
+
+				for i := 0; i < v.Len(); i++ {
+
+					// Reference: sum_k [x_k * (Q/q_k)^{-1}]_{q_k} * Q/q_k mod Q
+					want.SetUint64(0)
+					for k, qk := range ringQ.Modulus[:level+1] {
+						qkBig := NewUint(qk)
+						QOverqk := new(big.Int).Quo(Q, qkBig)
+						tmp.ModInverse(QOverqk, qkBig)
+						tmp.Mul(tmp, NewUint(p.Coeffs[k][i*gap]))
+						tmp.Mod(tmp, qkBig)
+						want.Add(want, tmp.Mul(tmp, QOverqk))
+					}
+					want.Mod(want, Q)
+
+					require.Zero(t, want.Cmp(v.Get(i, have)))
+
+					if want.Cmp(QHalf) >= 0 {
+						want.Sub(want, Q)
+					}
+
+					require.Zero(t, want.Cmp(vCentered.Get(i, have)))
+				}
+			}
+		})
+
+		t.Run(testString(fmt.Sprintf("CRT/Decompose/level=%d/", level), ringQ), func(t *testing.T) {
+
+			coeffs := make([]*big.Int, ringQ.N)
+			bound := new(big.Int).Lsh(Q, 70)
+			for i := range coeffs {
+				coeffs[i] = RandInt(bound)
+				if i&1 == 1 {
+					coeffs[i].Neg(coeffs[i])
+				}
+			}
+
+			pHave := ringQ.NewPolyLvl(level)
+			pWant := ringQ.NewPolyLvl(level)
+
+			crt.DecomposeBigints(level, coeffs, pHave)
+
+			tmp := new(big.Int)
+			for k, qk := range ringQ.Modulus[:level+1] {
+				for i := range coeffs {
+					pWant.Coeffs[k][i] = tmp.Mod(coeffs[i], NewUint(qk)).Uint64()
+				}
+			}
+
+			require.True(t, ringQ.EqualLvl(level, pWant, pHave))
+
+			// BigintVec path on the centered values
+			for i := range coeffs {
+				coeffs[i].Mod(coeffs[i], Q)
+				if coeffs[i].Cmp(QHalf) >= 0 {
+					coeffs[i].Sub(coeffs[i], Q)
+				}
+			}
+
+			v := ringQ.NewBigintVecLvl(level, ringQ.N)
+			v.SetBigints(coeffs)
+
+			crt.Decompose(level, v, pHave)
+
+			require.True(t, ringQ.EqualLvl(level, pWant, pHave))
+
+			crt.ReconstructCentered(level, pHave, 1, v)
+
+			have := new(big.Int)
+			for i := range coeffs {
+				require.Zero(t, coeffs[i].Cmp(v.Get(i, have)))
+			}
+		})
+	}
+}
@@ -5,8 +5,9 @@ import (
 )
 
 // AddVec returns p3 = p1 + p2 mod qi.
+// The computation uses the SIMD kernels of the CPU if available.
 func AddVec(p1, p2, p3 []uint64, qi uint64) {
-	for j := 0; j < len(p1); j = j + 8 {
+	for j := addVecKernel(p1, p2, p3, qi); j < len(p1); j = j + 8 {
 		x := (*[8]uint64)(unsafe.Pointer(&p1[j]))
 		y := (*[8]uint64)(unsafe.Pointer(&p2[j]))
 		z := (*[8]uint64)(unsafe.Pointer(&p3[j]))
@@ -204,8 +205,9 @@ func MulCoeffsAndAddNoModVec(p1, p2, p3 []uint64, qi uint64, bredParams []uint64
 }
 
 // MulCoeffsMontgomeryVec returns p3 = p1*p2 mod qi.
+// The computation uses the SIMD kernels of the CPU if available.
 func MulCoeffsMontgomeryVec(p1, p2, p3 []uint64, qi, mredParams uint64) {
-	for j := 0; j < len(p1); j = j + 8 {
+	for j := mulCoeffsMontgomeryKernel(p1, p2, p3, qi, mredParams); j < len(p1); j = j + 8 {
 		x := (*[8]uint64)(unsafe.Pointer(&p1[j]))
 		y := (*[8]uint64)(unsafe.Pointer(&p2[j]))
 		z := (*[8]uint64)(unsafe.Pointer(&p3[j]))
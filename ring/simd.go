@@ -0,0 +1,22 @@
+package ring
+
+// The SIMD kernels of the package are written in Go assembly and selected at runtime according to the features
+// of the CPU reported by golang.org/x/sys/cpu:
+//
+//	amd64: AddVec (AVX2 or AVX-512), MulCoeffsMontgomeryVec and the butterflies of NumberTheoreticTransformerVectorized (AVX-512F and AVX-512DQ)
+//	arm64: AddVec (NEON)
+//
+// NumberTheoreticTransformerVectorized is the default transform of the Standard rings on the CPUs supporting AVX-512F and
+// AVX-512DQ. There is no AVX2 or NEON kernel for the NTT and MulCoeffsMontgomeryVec: the AVX-512 kernels emulate the
+// 64x64 -> 128-bit products with 32-bit products and the 64-bit VPMULLQ of AVX-512DQ, which neither AVX2 nor NEON provide.
+//
+// The kernels compute exactly the same values as the portable Go code, which is used for the remaining operations,
+// on the other architectures and when the package is built with the purego build tag.
+//
+// The 52-bit integer fused multiply-add of AVX-512 IFMA is not used: the Montgomery form of the package is defined
+// for R = 2^64 and moduli of up to 61 bits, which requires the full 64x64 -> 128-bit products.
+var (
+	hasAVX2   bool
+	hasAVX512 bool
+	hasNEON   bool
+)
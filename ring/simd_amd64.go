@@ -0,0 +1,123 @@
+//go:build amd64 && !purego
+// +build amd64,!purego
+
+package ring
+
+import (
+	"math/bits"
+
+	"golang.org/x/sys/cpu"
+)
+
+func init() {
+	hasAVX2 = cpu.X86.HasAVX2
+	hasAVX512 = cpu.X86.HasAVX512F && cpu.X86.HasAVX512DQ
+}
+
+//go:noescape
+func addVecAVX2(p1, p2, p3 []uint64, qi uint64)
+
+//go:noescape
+func addVecAVX512(p1, p2, p3 []uint64, qi uint64)
+
+//go:noescape
+func mulCoeffsMontgomeryAVX512(p1, p2, p3 []uint64, qi, mredParams uint64)
+
+//go:noescape
+func nttButterfliesAVX512(xin, yin, xout, yout []uint64, psi, Q, QInv uint64)
+
+//go:noescape
+func nttButterfliesLazyAVX512(xin, yin, xout, yout []uint64, psi, Q, QInv uint64)
+
+//go:noescape
+func invNTTButterfliesAVX512(x, y []uint64, psi, Q, QInv uint64)
+
+// addVecKernel computes p3 = p1 + p2 mod qi on the largest prefix of the vectors whose length is a multiple
+// of 8 and returns its length, or returns 0 if the CPU does not support the kernel.
+func addVecKernel(p1, p2, p3 []uint64, qi uint64) (n int) {
+	n = len(p1) &^ 7
+	switch {
+	case n == 0:
+	case hasAVX512:
+		addVecAVX512(p1[:n], p2[:n], p3[:n], qi)
+	case hasAVX2:
+		addVecAVX2(p1[:n], p2[:n], p3[:n], qi)
+	default:
+		n = 0
+	}
+	return
+}
+
+// mulCoeffsMontgomeryKernel computes p3 = p1*p2 mod qi on the largest prefix of the vectors whose length is a
+// multiple of 8 and returns its length, or returns 0 if the CPU does not support the kernel.
+func mulCoeffsMontgomeryKernel(p1, p2, p3 []uint64, qi, mredParams uint64) (n int) {
+	n = len(p1) &^ 7
+	if n == 0 || !hasAVX512 {
+		return 0
+	}
+	mulCoeffsMontgomeryAVX512(p1[:n], p2[:n], p3[:n], qi, mredParams)
+	return
+}
+
+// hasNTTKernels returns true if the CPU supports the kernels of NumberTheoreticTransformerVectorized.
+func hasNTTKernels() bool {
+	return hasAVX512
+}
+
+// nttLazyVectorized computes the same values as NTTLazy, with the rounds of butterflies of size t >= 8
+// computed by the AVX-512 kernels.
+func nttLazyVectorized(coeffsIn, coeffsOut []uint64, N int, nttPsi []uint64, Q, QInv uint64, bredParams []uint64) {
+
+	if !hasAVX512 || N < 16 {
+		NTTLazy(coeffsIn, coeffsOut, N, nttPsi, Q, QInv, bredParams)
+		return
+	}
+
+	// Copy the result of the first round of butterflies on p2 with approximate reduction
+	t := N >> 1
+	nttButterfliesLazyAVX512(coeffsIn[:t], coeffsIn[t:N], coeffsOut[:t], coeffsOut[t:N], nttPsi[1], Q, QInv)
+
+	for m := 2; m < N>>3; m <<= 1 {
+
+		t >>= 1
+
+		for i := 0; i < m; i++ {
+
+			j1 := (i * t) << 1
+
+			x, y := coeffsOut[j1:j1+t], coeffsOut[j1+t:j1+2*t]
+
+			if bits.Len64(uint64(m))&1 == 1 {
+				nttButterfliesAVX512(x, y, x, y, nttPsi[m+i], Q, QInv)
+			} else {
+				nttButterfliesLazyAVX512(x, y, x, y, nttPsi[m+i], Q, QInv)
+			}
+		}
+	}
+
+	nttLazySmallStrides(coeffsOut, N, nttPsi, Q, QInv)
+}
+
+// invNTTCoreVectorized computes the same values as invNTTCore, with the rounds of butterflies of size t >= 8
+// computed by the AVX-512 kernels.
+func invNTTCoreVectorized(coeffsIn, coeffsOut []uint64, N int, nttPsiInv []uint64, Q, QInv uint64) {
+
+	if !hasAVX512 || N < 16 {
+		invNTTCore(coeffsIn, coeffsOut, N, nttPsiInv, Q, QInv)
+		return
+	}
+
+	invNTTSmallStrides(coeffsIn, coeffsOut, N, nttPsiInv, Q, QInv)
+
+	t := 8
+	for m := N >> 3; m > 1; m >>= 1 {
+
+		h := m >> 1
+
+		for i, j1 := 0, 0; i < h; i, j1 = i+1, j1+2*t {
+			invNTTButterfliesAVX512(coeffsOut[j1:j1+t], coeffsOut[j1+t:j1+2*t], nttPsiInv[h+i], Q, QInv)
+		}
+
+		t <<= 1
+	}
+}
@@ -0,0 +1,313 @@
+//go:build amd64 && !purego
+// +build amd64,!purego
+
+#include "textflag.h"
+
+// Constant registers of the AVX-512 kernels:
+//
+//	Z31: 0x00000000ffffffff
+//	Z30: q
+//	Z29: qInv = q^-1 mod 2^64
+//	Z28: q >> 32
+//	Z27: 4q
+//	Z26: 2q
+//	Z25: psi
+//	Z24: psi >> 32
+#define LOADCONSTANTS(q, qInv, tmp) \
+	MOVQ         $0xffffffff, tmp; \
+	VPBROADCASTQ tmp, Z31;         \
+	VPBROADCASTQ q, Z30;           \
+	VPBROADCASTQ qInv, Z29;        \
+	MOVQ         q, tmp;           \
+	SHRQ         $32, tmp;         \
+	VPBROADCASTQ tmp, Z28;         \
+	MOVQ         q, tmp;           \
+	SHLQ         $1, tmp;          \
+	VPBROADCASTQ tmp, Z26;         \
+	SHLQ         $1, tmp;          \
+	VPBROADCASTQ tmp, Z27
+
+#define LOADPSI(psi) \
+	VPBROADCASTQ psi, Z25; \
+	SHRQ         $32, psi; \
+	VPBROADCASTQ psi, Z24
+
+// MUL64 computes the 128-bit products hi:lo = a * b of the 64-bit lanes of a and b, where b1 = b >> 32,
+// with four 32x32 -> 64-bit products. The registers a, b and b1 are preserved.
+#define MUL64(a, b, b1, hi, lo, t0, t1, t2, t3) \
+	VPSRLQ   $32, a, t0;  \
+	VPMULUDQ b, a, lo;    \
+	VPMULUDQ b1, a, t1;   \
+	VPMULUDQ b, t0, t2;   \
+	VPMULUDQ b1, t0, hi;  \
+	VPSRLQ   $32, lo, t0; \
+	VPANDQ   Z31, t1, t3; \
+	VPADDQ   t3, t0, t0;  \
+	VPANDQ   Z31, t2, t3; \
+	VPADDQ   t3, t0, t0;  \
+	VPSRLQ   $32, t1, t1; \
+	VPADDQ   t1, hi, hi;  \
+	VPSRLQ   $32, t2, t2; \
+	VPADDQ   t2, hi, hi;  \
+	VPSRLQ   $32, t0, t1; \
+	VPADDQ   t1, hi, hi;  \
+	VPSLLQ   $32, t0, t0; \
+	VPANDQ   Z31, lo, lo; \
+	VPORQ    t0, lo, lo
+
+// MULHI64 computes the high 64 bits hi of the products a * q of the 64-bit lanes of a.
+#define MULHI64(a, hi, t0, t1, t2, t3) \
+	VPSRLQ   $32, a, t0;  \
+	VPMULUDQ Z30, a, t3;  \
+	VPMULUDQ Z28, a, t1;  \
+	VPMULUDQ Z30, t0, t2; \
+	VPMULUDQ Z28, t0, hi; \
+	VPSRLQ   $32, t3, t0; \
+	VPANDQ   Z31, t1, t3; \
+	VPADDQ   t3, t0, t0;  \
+	VPANDQ   Z31, t2, t3; \
+	VPADDQ   t3, t0, t0;  \
+	VPSRLQ   $32, t1, t1; \
+	VPADDQ   t1, hi, hi;  \
+	VPSRLQ   $32, t2, t2; \
+	VPADDQ   t2, hi, hi;  \
+	VPSRLQ   $32, t0, t0; \
+	VPADDQ   t0, hi, hi
+
+// MREDCONSTANT computes r = a * b * 2^-64 mod q in [0, 2q-1] on the 64-bit lanes of a and b, where b1 = b >> 32,
+// with the same operations as MRedConstant.
+#define MREDCONSTANT(a, b, b1, r, t0, t1, t2, t3, t4, t5) \
+	MUL64(a, b, b1, r, t4, t0, t1, t2, t3); \
+	VPMULLQ Z29, t4, t4;                    \
+	MULHI64(t4, t5, t0, t1, t2, t3);        \
+	VPSUBQ  t5, r, r;                       \
+	VPADDQ  Z30, r, r
+
+// func addVecAVX2(p1, p2, p3 []uint64, qi uint64)
+TEXT ·addVecAVX2(SB), NOSPLIT, $0-80
+	MOVQ p1_base+0(FP), SI
+	MOVQ p1_len+8(FP), CX
+	MOVQ p2_base+24(FP), DI
+	MOVQ p3_base+48(FP), DX
+	MOVQ qi+72(FP), AX
+
+	// The unsigned comparison a >= q is computed as the signed comparison (a ^ 2^63) > ((q-1) ^ 2^63)
+	MOVQ         $0x8000000000000000, BX
+	MOVQ         BX, X15
+	VPBROADCASTQ X15, Y15
+	MOVQ         AX, X14
+	VPBROADCASTQ X14, Y14
+	DECQ         AX
+	XORQ         BX, AX
+	MOVQ         AX, X13
+	VPBROADCASTQ X13, Y13
+
+	SHRQ $3, CX
+	JZ   done
+
+loop:
+	VMOVDQU  (SI), Y0
+	VMOVDQU  32(SI), Y1
+	VPADDQ   (DI), Y0, Y0
+	VPADDQ   32(DI), Y1, Y1
+	VPXOR    Y15, Y0, Y2
+	VPXOR    Y15, Y1, Y3
+	VPCMPGTQ Y13, Y2, Y2
+	VPCMPGTQ Y13, Y3, Y3
+	VPAND    Y14, Y2, Y2
+	VPAND    Y14, Y3, Y3
+	VPSUBQ   Y2, Y0, Y0
+	VPSUBQ   Y3, Y1, Y1
+	VMOVDQU  Y0, (DX)
+	VMOVDQU  Y1, 32(DX)
+
+	ADDQ $64, SI
+	ADDQ $64, DI
+	ADDQ $64, DX
+	DECQ CX
+	JNZ  loop
+
+done:
+	VZEROUPPER
+	RET
+
+// func addVecAVX512(p1, p2, p3 []uint64, qi uint64)
+TEXT ·addVecAVX512(SB), NOSPLIT, $0-80
+	MOVQ p1_base+0(FP), SI
+	MOVQ p1_len+8(FP), CX
+	MOVQ p2_base+24(FP), DI
+	MOVQ p3_base+48(FP), DX
+	MOVQ qi+72(FP), AX
+
+	VPBROADCASTQ AX, Z30
+
+	SHRQ $3, CX
+	JZ   done
+
+loop:
+	// min(a, a-q) = a mod q for a in [0, 2q-1]
+	VMOVDQU64 (SI), Z0
+	VPADDQ    (DI), Z0, Z0
+	VPSUBQ    Z30, Z0, Z1
+	VPMINUQ   Z1, Z0, Z0
+	VMOVDQU64 Z0, (DX)
+
+	ADDQ $64, SI
+	ADDQ $64, DI
+	ADDQ $64, DX
+	DECQ CX
+	JNZ  loop
+
+done:
+	VZEROUPPER
+	RET
+
+// func mulCoeffsMontgomeryAVX512(p1, p2, p3 []uint64, qi, mredParams uint64)
+TEXT ·mulCoeffsMontgomeryAVX512(SB), NOSPLIT, $0-88
+	MOVQ p1_base+0(FP), SI
+	MOVQ p1_len+8(FP), CX
+	MOVQ p2_base+24(FP), DI
+	MOVQ p3_base+48(FP), DX
+	MOVQ qi+72(FP), AX
+	MOVQ mredParams+80(FP), BX
+
+	LOADCONSTANTS(AX, BX, R8)
+
+	SHRQ $3, CX
+	JZ   done
+
+loop:
+	VMOVDQU64 (SI), Z0
+	VMOVDQU64 (DI), Z1
+	VPSRLQ    $32, Z1, Z2
+	MREDCONSTANT(Z0, Z1, Z2, Z3, Z4, Z5, Z6, Z7, Z8, Z9)
+	VPSUBQ    Z30, Z3, Z4
+	VPMINUQ   Z4, Z3, Z3
+	VMOVDQU64 Z3, (DX)
+
+	ADDQ $64, SI
+	ADDQ $64, DI
+	ADDQ $64, DX
+	DECQ CX
+	JNZ  loop
+
+done:
+	VZEROUPPER
+	RET
+
+// func nttButterfliesAVX512(xin, yin, xout, yout []uint64, psi, Q, QInv uint64)
+// Computes xout, yout = butterfly(xin, yin, psi).
+TEXT ·nttButterfliesAVX512(SB), NOSPLIT, $0-120
+	MOVQ xin_base+0(FP), SI
+	MOVQ xin_len+8(FP), CX
+	MOVQ yin_base+24(FP), DI
+	MOVQ xout_base+48(FP), R9
+	MOVQ yout_base+72(FP), R10
+	MOVQ psi+96(FP), R11
+	MOVQ Q+104(FP), AX
+	MOVQ QInv+112(FP), BX
+
+	LOADCONSTANTS(AX, BX, R8)
+	LOADPSI(R11)
+
+	SHRQ $3, CX
+	JZ   done
+
+loop:
+	VMOVDQU64 (SI), Z0
+	VMOVDQU64 (DI), Z1
+	VPSUBQ    Z27, Z0, Z2
+	VPMINUQ   Z2, Z0, Z0
+	MREDCONSTANT(Z1, Z25, Z24, Z3, Z4, Z5, Z6, Z7, Z8, Z9)
+	VPADDQ    Z3, Z0, Z1
+	VPADDQ    Z26, Z0, Z2
+	VPSUBQ    Z3, Z2, Z2
+	VMOVDQU64 Z1, (R9)
+	VMOVDQU64 Z2, (R10)
+
+	ADDQ $64, SI
+	ADDQ $64, DI
+	ADDQ $64, R9
+	ADDQ $64, R10
+	DECQ CX
+	JNZ  loop
+
+done:
+	VZEROUPPER
+	RET
+
+// func nttButterfliesLazyAVX512(xin, yin, xout, yout []uint64, psi, Q, QInv uint64)
+// Computes xout, yout = butterfly(xin, yin, psi) without the reduction of xin.
+TEXT ·nttButterfliesLazyAVX512(SB), NOSPLIT, $0-120
+	MOVQ xin_base+0(FP), SI
+	MOVQ xin_len+8(FP), CX
+	MOVQ yin_base+24(FP), DI
+	MOVQ xout_base+48(FP), R9
+	MOVQ yout_base+72(FP), R10
+	MOVQ psi+96(FP), R11
+	MOVQ Q+104(FP), AX
+	MOVQ QInv+112(FP), BX
+
+	LOADCONSTANTS(AX, BX, R8)
+	LOADPSI(R11)
+
+	SHRQ $3, CX
+	JZ   done
+
+loop:
+	VMOVDQU64 (SI), Z0
+	VMOVDQU64 (DI), Z1
+	MREDCONSTANT(Z1, Z25, Z24, Z3, Z4, Z5, Z6, Z7, Z8, Z9)
+	VPADDQ    Z3, Z0, Z1
+	VPADDQ    Z26, Z0, Z2
+	VPSUBQ    Z3, Z2, Z2
+	VMOVDQU64 Z1, (R9)
+	VMOVDQU64 Z2, (R10)
+
+	ADDQ $64, SI
+	ADDQ $64, DI
+	ADDQ $64, R9
+	ADDQ $64, R10
+	DECQ CX
+	JNZ  loop
+
+done:
+	VZEROUPPER
+	RET
+
+// func invNTTButterfliesAVX512(x, y []uint64, psi, Q, QInv uint64)
+// Computes x, y = invbutterfly(x, y, psi).
+TEXT ·invNTTButterfliesAVX512(SB), NOSPLIT, $0-72
+	MOVQ x_base+0(FP), SI
+	MOVQ x_len+8(FP), CX
+	MOVQ y_base+24(FP), DI
+	MOVQ psi+48(FP), R11
+	MOVQ Q+56(FP), AX
+	MOVQ QInv+64(FP), BX
+
+	LOADCONSTANTS(AX, BX, R8)
+	LOADPSI(R11)
+
+	SHRQ $3, CX
+	JZ   done
+
+loop:
+	VMOVDQU64 (SI), Z0
+	VMOVDQU64 (DI), Z1
+	VPADDQ    Z1, Z0, Z2
+	VPSUBQ    Z26, Z2, Z3
+	VPMINUQ   Z3, Z2, Z2
+	VPADDQ    Z27, Z0, Z0
+	VPSUBQ    Z1, Z0, Z0
+	MREDCONSTANT(Z0, Z25, Z24, Z3, Z4, Z5, Z6, Z7, Z8, Z9)
+	VMOVDQU64 Z2, (SI)
+	VMOVDQU64 Z3, (DI)
+
+	ADDQ $64, SI
+	ADDQ $64, DI
+	DECQ CX
+	JNZ  loop
+
+done:
+	VZEROUPPER
+	RET
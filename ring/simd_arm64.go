@@ -0,0 +1,47 @@
+//go:build arm64 && !purego
+// +build arm64,!purego
+
+package ring
+
+import (
+	"golang.org/x/sys/cpu"
+)
+
+func init() {
+	hasNEON = cpu.ARM64.HasASIMD
+}
+
+//go:noescape
+func addVecNEON(p1, p2, p3 []uint64, qi uint64)
+
+// addVecKernel computes p3 = p1 + p2 mod qi on the largest prefix of the vectors whose length is a multiple
+// of 8 and returns its length, or returns 0 if the CPU does not support the kernel.
+func addVecKernel(p1, p2, p3 []uint64, qi uint64) (n int) {
+	n = len(p1) &^ 7
+	if n == 0 || !hasNEON {
+		return 0
+	}
+	addVecNEON(p1[:n], p2[:n], p3[:n], qi)
+	return
+}
+
+// mulCoeffsMontgomeryKernel returns 0: NEON has no 64x64 -> 128-bit multiplication, for which the scalar
+// instructions are faster.
+func mulCoeffsMontgomeryKernel(p1, p2, p3 []uint64, qi, mredParams uint64) (n int) {
+	return 0
+}
+
+// hasNTTKernels returns false: the NTT has no NEON kernel.
+func hasNTTKernels() bool {
+	return false
+}
+
+// nttLazyVectorized computes NTTLazy.
+func nttLazyVectorized(coeffsIn, coeffsOut []uint64, N int, nttPsi []uint64, Q, QInv uint64, bredParams []uint64) {
+	NTTLazy(coeffsIn, coeffsOut, N, nttPsi, Q, QInv, bredParams)
+}
+
+// invNTTCoreVectorized computes invNTTCore.
+func invNTTCoreVectorized(coeffsIn, coeffsOut []uint64, N int, nttPsiInv []uint64, Q, QInv uint64) {
+	invNTTCore(coeffsIn, coeffsOut, N, nttPsiInv, Q, QInv)
+}
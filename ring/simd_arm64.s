@@ -0,0 +1,50 @@
+//go:build arm64 && !purego
+// +build arm64,!purego
+
+#include "textflag.h"
+
+// func addVecNEON(p1, p2, p3 []uint64, qi uint64)
+TEXT ·addVecNEON(SB), NOSPLIT, $0-80
+	MOVD p1_base+0(FP), R0
+	MOVD p1_len+8(FP), R3
+	MOVD p2_base+24(FP), R1
+	MOVD p3_base+48(FP), R2
+	MOVD qi+72(FP), R4
+
+	VDUP R4, V16.D2
+
+	LSR $3, R3, R3
+	CBZ R3, done
+
+loop:
+	VLD1.P 64(R0), [V0.D2, V1.D2, V2.D2, V3.D2]
+	VLD1.P 64(R1), [V4.D2, V5.D2, V6.D2, V7.D2]
+
+	VADD V4.D2, V0.D2, V0.D2
+	VADD V5.D2, V1.D2, V1.D2
+	VADD V6.D2, V2.D2, V2.D2
+	VADD V7.D2, V3.D2, V3.D2
+
+	// a - (q & (a >= q))
+	VCMHS V16.D2, V0.D2, V4.D2
+	VCMHS V16.D2, V1.D2, V5.D2
+	VCMHS V16.D2, V2.D2, V6.D2
+	VCMHS V16.D2, V3.D2, V7.D2
+
+	VAND V16.B16, V4.B16, V4.B16
+	VAND V16.B16, V5.B16, V5.B16
+	VAND V16.B16, V6.B16, V6.B16
+	VAND V16.B16, V7.B16, V7.B16
+
+	VSUB V4.D2, V0.D2, V0.D2
+	VSUB V5.D2, V1.D2, V1.D2
+	VSUB V6.D2, V2.D2, V2.D2
+	VSUB V7.D2, V3.D2, V3.D2
+
+	VST1.P [V0.D2, V1.D2, V2.D2, V3.D2], 64(R2)
+
+	SUB  $1, R3, R3
+	CBNZ R3, loop
+
+done:
+	RET
@@ -0,0 +1,29 @@
+//go:build (!amd64 && !arm64) || purego
+// +build !amd64,!arm64 purego
+
+package ring
+
+// addVecKernel returns 0: there is no SIMD kernel for this architecture.
+func addVecKernel(p1, p2, p3 []uint64, qi uint64) (n int) {
+	return 0
+}
+
+// mulCoeffsMontgomeryKernel returns 0: there is no SIMD kernel for this architecture.
+func mulCoeffsMontgomeryKernel(p1, p2, p3 []uint64, qi, mredParams uint64) (n int) {
+	return 0
+}
+
+// hasNTTKernels returns false.
+func hasNTTKernels() bool {
+	return false
+}
+
+// nttLazyVectorized computes NTTLazy.
+func nttLazyVectorized(coeffsIn, coeffsOut []uint64, N int, nttPsi []uint64, Q, QInv uint64, bredParams []uint64) {
+	NTTLazy(coeffsIn, coeffsOut, N, nttPsi, Q, QInv, bredParams)
+}
+
+// invNTTCoreVectorized computes invNTTCore.
+func invNTTCoreVectorized(coeffsIn, coeffsOut []uint64, N int, nttPsiInv []uint64, Q, QInv uint64) {
+	invNTTCore(coeffsIn, coeffsOut, N, nttPsiInv, Q, QInv)
+}
@@ -0,0 +1,151 @@
+package ring
+
+import (
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/tuneinsight/lattigo/v3/utils"
+)
+
+// simdFeatures returns the combinations of the SIMD features of the CPU for which the kernels are tested.
+func simdFeatures() (features []struct{ avx2, avx512, neon bool }) {
+	features = append(features, struct{ avx2, avx512, neon bool }{hasAVX2, hasAVX512, hasNEON})
+	if hasAVX512 && hasAVX2 {
+		features = append(features, struct{ avx2, avx512, neon bool }{true, false, false})
+	}
+	return
+}
+
+// withSIMD runs f with the given SIMD features and restores the features of the CPU.
+func withSIMD(avx2, avx512, neon bool, f func()) {
+	hasAVX2Cpu, hasAVX512Cpu, hasNEONCpu := hasAVX2, hasAVX512, hasNEON
+	defer func() { hasAVX2, hasAVX512, hasNEON = hasAVX2Cpu, hasAVX512Cpu, hasNEONCpu }()
+	hasAVX2, hasAVX512, hasNEON = avx2, avx512, neon
+	f()
+}
+
+func TestSIMD(t *testing.T) {
+
+	prng, err := utils.NewPRNG()
+	require.NoError(t, err)
+
+	randomVec := func(n int, q uint64) (v []uint64) {
+		buf := make([]byte, 8*n)
+		prng.Clock(buf)
+		v = make([]uint64, n)
+		for i := range v {
+			v[i] = binary.BigEndian.Uint64(buf[8*i:])
+			if q != 0 {
+				v[i] %= q
+			}
+		}
+		return
+	}
+
+	for _, features := range simdFeatures() {
+
+		name := fmt.Sprintf("AVX2=%t/AVX512=%t/NEON=%t", features.avx2, features.avx512, features.neon)
+
+		for _, logQ := range []int{30, 55, 61} {
+
+			q := GenerateNTTPrimes(logQ, 1<<12, 1)[0]
+			qInv := MRedParams(q)
+
+			for _, n := range []int{8, 24, 1 << 10, 1<<10 + 5} {
+
+				t.Run(fmt.Sprintf("%s/logQ=%d/n=%d/", name, logQ, n), func(t *testing.T) {
+
+					// Coefficients in [0, q-1] and arbitrary 64-bit values
+					for _, bound := range []uint64{q, 0} {
+
+						p1, p2 := randomVec(n, bound), randomVec(n, bound)
+						p1[0], p2[0] = bound-1, bound-1
+
+						want, have := make([]uint64, n), make([]uint64, n)
+
+						withSIMD(features.avx2, features.avx512, features.neon, func() {
+
+							for i := range want {
+								want[i] = CRed(p1[i]+p2[i], q)
+							}
+
+							k := addVecKernel(p1, p2, have, q)
+							require.Equal(t, want[:k], have[:k], "addVecKernel")
+
+							if n&7 == 0 {
+								AddVec(p1, p2, have, q)
+								require.Equal(t, want, have, "AddVec")
+							}
+
+							for i := range want {
+								want[i] = MRed(p1[i], p2[i], q, qInv)
+							}
+
+							k = mulCoeffsMontgomeryKernel(p1, p2, have, q, qInv)
+							require.Equal(t, want[:k], have[:k], "mulCoeffsMontgomeryKernel")
+
+							if n&7 == 0 {
+								MulCoeffsMontgomeryVec(p1, p2, have, q, qInv)
+								require.Equal(t, want, have, "MulCoeffsMontgomeryVec")
+							}
+						})
+					}
+				})
+			}
+		}
+
+		for _, logN := range []int{4, 5, 6, 10, 12} {
+
+			N := 1 << logN
+
+			ringStandard, err := NewRingWithCustomNTT(N, GenerateNTTPrimes(61, 2*N, 2), NumberTheoreticTransformerStandard{}, 2*N)
+			require.NoError(t, err)
+
+			ringVectorized, err := NewRingWithCustomNTT(N, ringStandard.Modulus, NumberTheoreticTransformerVectorized{}, 2*N)
+			require.NoError(t, err)
+			require.Equal(t, Standard, ringVectorized.Type())
+
+			// The Standard rings use the vectorized NTT if and only if the CPU supports its kernels
+			withSIMD(features.avx2, features.avx512, features.neon, func() {
+				ringDefault, err := NewRing(N, ringStandard.Modulus)
+				require.NoError(t, err)
+				_, isVectorized := ringDefault.NumberTheoreticTransformer.(NumberTheoreticTransformerVectorized)
+				require.Equal(t, hasNTTKernels(), isVectorized)
+			})
+
+			sampler := NewUniformSampler(prng, ringStandard)
+
+			t.Run(fmt.Sprintf("%s/NTT/N=%d/", name, N), func(t *testing.T) {
+
+				p := sampler.ReadNew()
+				want, have := ringStandard.NewPoly(), ringStandard.NewPoly()
+
+				withSIMD(features.avx2, features.avx512, features.neon, func() {
+
+					ringStandard.NTT(p, want)
+					ringVectorized.NTT(p, have)
+					require.True(t, ringStandard.Equal(want, have), "Forward")
+
+					ringStandard.NTTLazy(p, want)
+					ringVectorized.NTTLazy(p, have)
+					require.Equal(t, want.Coeffs, have.Coeffs, "ForwardLazy")
+
+					ringStandard.InvNTT(p, want)
+					ringVectorized.InvNTT(p, have)
+					require.True(t, ringStandard.Equal(want, have), "Backward")
+
+					ringStandard.InvNTTLazy(p, want)
+					ringVectorized.InvNTTLazy(p, have)
+					require.Equal(t, want.Coeffs, have.Coeffs, "BackwardLazy")
+
+					ringVectorized.NTT(p, have)
+					ringVectorized.InvNTT(have, have)
+					require.True(t, ringStandard.Equal(p, have), "Backward(Forward)")
+				})
+			})
+		}
+	}
+}
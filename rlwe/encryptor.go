@@ -11,6 +11,7 @@ type Encryptor interface {
 	EncryptFromCRP(pt *Plaintext, crp *ring.Poly, ct *Ciphertext)
 	ShallowCopy() Encryptor
 	WithKey(key interface{}) Encryptor
+	WithPRNG(prng utils.PRNG) Encryptor
 }
 
 type encryptor struct {
@@ -46,7 +47,7 @@ func newEncryptor(params Parameters) encryptor {
 
 	return encryptor{
 		encryptorBase:     newEncryptorBase(params),
-		encryptorSamplers: newEncryptorSamplers(params),
+		encryptorSamplers: newEncryptorSamplers(params, nil),
 		encryptorBuffers:  newEncryptorBuffers(params),
 		basisextender:     bc,
 	}
@@ -62,21 +63,23 @@ func newEncryptorBase(params Parameters) *encryptorBase {
 }
 
 type encryptorSamplers struct {
-	gaussianSampler *ring.GaussianSampler
-	ternarySampler  *ring.TernarySampler
-	uniformSampler  *ring.UniformSampler
+	fork           *utils.PRNGFork // derives the PRNGs of the shallow copies, nil if the samplers are keyed from crypto/rand
+	xeSampler      ring.DistributionSampler
+	xuSampler      ring.DistributionSampler
+	uniformSampler *ring.UniformSampler
 }
 
-func newEncryptorSamplers(params Parameters) *encryptorSamplers {
-	prng, err := utils.NewPRNG()
+func newEncryptorSamplers(params Parameters, source utils.PRNG) *encryptorSamplers {
+	prng, err := utils.DefaultPRNG(source)
 	if err != nil {
 		panic(err)
 	}
 
 	return &encryptorSamplers{
-		gaussianSampler: ring.NewGaussianSampler(prng, params.RingQ(), params.Sigma(), int(6*params.Sigma())),
-		ternarySampler:  ring.NewTernarySamplerWithHammingWeight(prng, params.ringQ, params.h, false),
-		uniformSampler:  ring.NewUniformSampler(prng, params.RingQ()),
+		fork:           utils.NewPRNGFork(source),
+		xeSampler:      params.Xe().NewSampler(prng, params.RingQ(), params.GaussianSamplerType()),
+		xuSampler:      params.Xu().NewSampler(prng, params.RingQ(), params.GaussianSamplerType()),
+		uniformSampler: ring.NewUniformSampler(prng, params.RingQ()),
 	}
 }
 
@@ -152,10 +155,34 @@ func (enc *skEncryptor) ShallowCopy() Encryptor {
 	return &skEncryptor{*enc.encryptor.ShallowCopy(), enc.sk}
 }
 
+// WithPRNG creates a shallow copy of this pkEncryptor whose samplers (uniform, error and ephemeral secret)
+// draw their randomness from prng, making the encryptions reproducible from the seed of prng.
+// The temporary buffers are reallocated, and the ShallowCopy of the returned Encryptor draws its randomness
+// from a PRNG derived from prng (see utils.PRNGFork).
+func (enc *pkEncryptor) WithPRNG(prng utils.PRNG) Encryptor {
+	return &pkEncryptor{*enc.encryptor.withPRNG(prng), enc.pk}
+}
+
+// WithPRNG creates a shallow copy of this skEncryptor whose samplers (uniform and error) draw their
+// randomness from prng, making the encryptions reproducible from the seed of prng.
+// The temporary buffers are reallocated, and the ShallowCopy of the returned Encryptor draws its randomness
+// from a PRNG derived from prng (see utils.PRNGFork).
+func (enc *skEncryptor) WithPRNG(prng utils.PRNG) Encryptor {
+	return &skEncryptor{*enc.encryptor.withPRNG(prng), enc.sk}
+}
+
 // ShallowCopy creates a shallow copy of this encryptor in which all the read-only data-structures are
 // shared with the receiver and the temporary buffers are reallocated. The receiver and the returned
 // Encryptors can be used concurrently.
 func (enc *encryptor) ShallowCopy() *encryptor {
+	prng, err := enc.fork.Derive()
+	if err != nil {
+		panic(err)
+	}
+	return enc.withPRNG(prng)
+}
+
+func (enc *encryptor) withPRNG(prng utils.PRNG) *encryptor {
 
 	var bc *ring.BasisExtender
 	if enc.params.PCount() != 0 {
@@ -164,7 +191,7 @@ func (enc *encryptor) ShallowCopy() *encryptor {
 
 	return &encryptor{
 		encryptorBase:     enc.encryptorBase,
-		encryptorSamplers: newEncryptorSamplers(enc.params),
+		encryptorSamplers: newEncryptorSamplers(enc.params, prng),
 		encryptorBuffers:  newEncryptorBuffers(enc.params),
 		basisextender:     bc,
 	}
@@ -195,7 +222,7 @@ func (enc *pkEncryptor) encrypt(plaintext *Plaintext, ciphertext *Ciphertext) {
 
 	u := PolyQP{Q: poolQ0, P: poolP2}
 
-	enc.ternarySampler.ReadLvl(levelQ, u.Q)
+	enc.xuSampler.ReadLvl(levelQ, u.Q)
 	ringQP.ExtendBasisSmallNormAndCenter(u.Q, levelP, nil, u.P)
 
 	// (#Q + #P) NTT
@@ -216,11 +243,11 @@ func (enc *pkEncryptor) encrypt(plaintext *Plaintext, ciphertext *Ciphertext) {
 
 	e := PolyQP{Q: poolQ0, P: poolP2}
 
-	enc.gaussianSampler.ReadLvl(levelQ, e.Q)
+	enc.xeSampler.ReadLvl(levelQ, e.Q)
 	ringQP.ExtendBasisSmallNormAndCenter(e.Q, levelP, nil, e.P)
 	ringQP.AddLvl(levelQ, levelP, ct0QP, e, ct0QP)
 
-	enc.gaussianSampler.ReadLvl(levelQ, e.Q)
+	enc.xeSampler.ReadLvl(levelQ, e.Q)
 	ringQP.ExtendBasisSmallNormAndCenter(e.Q, levelP, nil, e.P)
 	ringQP.AddLvl(levelQ, levelP, ct1QP, e, ct1QP)
 
@@ -269,7 +296,7 @@ func (enc *pkEncryptor) encryptNoP(plaintext *Plaintext, ciphertext *Ciphertext)
 
 	ciphertextNTT := ciphertext.Value[0].IsNTT
 
-	enc.ternarySampler.ReadLvl(levelQ, poolQ0)
+	enc.xuSampler.ReadLvl(levelQ, poolQ0)
 	ringQ.NTTLvl(levelQ, poolQ0, poolQ0)
 	ringQ.MFormLvl(levelQ, poolQ0, poolQ0)
 
@@ -281,12 +308,12 @@ func (enc *pkEncryptor) encryptNoP(plaintext *Plaintext, ciphertext *Ciphertext)
 	if ciphertextNTT {
 
 		// ct1 = u*pk1 + e1
-		enc.gaussianSampler.ReadLvl(levelQ, poolQ0)
+		enc.xeSampler.ReadLvl(levelQ, poolQ0)
 		ringQ.NTTLvl(levelQ, poolQ0, poolQ0)
 		ringQ.AddLvl(levelQ, ciphertext.Value[1], poolQ0, ciphertext.Value[1])
 
 		// ct0 = u*pk0 + e0
-		enc.gaussianSampler.ReadLvl(levelQ, poolQ0)
+		enc.xeSampler.ReadLvl(levelQ, poolQ0)
 
 		if !plaintext.Value.IsNTT {
 			ringQ.AddLvl(levelQ, poolQ0, plaintext.Value, poolQ0)
@@ -304,10 +331,10 @@ func (enc *pkEncryptor) encryptNoP(plaintext *Plaintext, ciphertext *Ciphertext)
 		ringQ.InvNTTLvl(levelQ, ciphertext.Value[1], ciphertext.Value[1])
 
 		// ct[0] = pk[0]*u + e0
-		enc.gaussianSampler.ReadAndAddLvl(ciphertext.Level(), ciphertext.Value[0])
+		enc.xeSampler.ReadAndAddLvl(ciphertext.Level(), ciphertext.Value[0])
 
 		// ct[1] = pk[1]*u + e1
-		enc.gaussianSampler.ReadAndAddLvl(ciphertext.Level(), ciphertext.Value[1])
+		enc.xeSampler.ReadAndAddLvl(ciphertext.Level(), ciphertext.Value[1])
 
 		if !plaintext.Value.IsNTT {
 			ringQ.AddLvl(levelQ, ciphertext.Value[0], plaintext.Value, ciphertext.Value[0])
@@ -338,7 +365,7 @@ func (enc *skEncryptor) encrypt(plaintext *Plaintext, ciphertext *Ciphertext) {
 
 	if ciphertextNTT {
 
-		enc.gaussianSampler.ReadLvl(levelQ, poolQ0)
+		enc.xeSampler.ReadLvl(levelQ, poolQ0)
 
 		if plaintext.Value.IsNTT {
 			ringQ.NTTLvl(levelQ, poolQ0, poolQ0)
@@ -364,7 +391,7 @@ func (enc *skEncryptor) encrypt(plaintext *Plaintext, ciphertext *Ciphertext) {
 			ringQ.AddLvl(levelQ, ciphertext.Value[0], plaintext.Value, ciphertext.Value[0])
 		}
 
-		enc.gaussianSampler.ReadAndAddLvl(ciphertext.Level(), ciphertext.Value[0])
+		enc.xeSampler.ReadAndAddLvl(ciphertext.Level(), ciphertext.Value[0])
 
 		ringQ.InvNTTLvl(levelQ, ciphertext.Value[1], ciphertext.Value[1])
 
@@ -25,18 +25,20 @@ type KeyGenerator interface {
 	GenSwitchingKeyForRowRotation(sk *SecretKey) (swk *SwitchingKey)
 	GenRotationKeysForInnerSum(sk *SecretKey) (rks *RotationKeySet)
 	GenSwitchingKeysForRingSwap(skCKKS, skCI *SecretKey) (swkStdToConjugateInvariant, swkConjugateInvariantToStd *SwitchingKey)
+	WithPRNG(prng utils.PRNG) KeyGenerator
 }
 
 // KeyGenerator is a structure that stores the elements required to create new keys,
 // as well as a small memory pool for intermediate values.
 type keyGenerator struct {
-	params           Parameters
-	poolQ            *ring.Poly
-	poolQP           PolyQP
-	ternarySampler   *ring.TernarySampler
-	gaussianSamplerQ *ring.GaussianSampler
-	uniformSamplerQ  *ring.UniformSampler
-	uniformSamplerP  *ring.UniformSampler
+	params          Parameters
+	prng            utils.PRNG
+	poolQ           *ring.Poly
+	poolQP          PolyQP
+	xsSampler       ring.DistributionSampler
+	xeSamplerQ      ring.DistributionSampler
+	uniformSamplerQ *ring.UniformSampler
+	uniformSamplerP *ring.UniformSampler
 }
 
 // NewKeyGenerator creates a new KeyGenerator, from which the secret and public keys, as well as the evaluation,
@@ -48,6 +50,17 @@ func NewKeyGenerator(params Parameters) KeyGenerator {
 		panic(err)
 	}
 
+	return newKeyGenerator(params, prng)
+}
+
+// WithPRNG creates a new KeyGenerator with the same parameters as the receiver, whose samplers (secret, error
+// and uniform) draw their randomness from prng, making the generated keys reproducible from the seed of prng.
+func (keygen *keyGenerator) WithPRNG(prng utils.PRNG) KeyGenerator {
+	return newKeyGenerator(keygen.params, prng)
+}
+
+func newKeyGenerator(params Parameters, prng utils.PRNG) KeyGenerator {
+
 	var poolQP PolyQP
 	var uniformSamplerP *ring.UniformSampler
 	if params.PCount() > 0 {
@@ -56,43 +69,36 @@ func NewKeyGenerator(params Parameters) KeyGenerator {
 	}
 
 	return &keyGenerator{
-		params:           params,
-		poolQ:            params.RingQ().NewPoly(),
-		poolQP:           poolQP,
-		ternarySampler:   ring.NewTernarySamplerWithHammingWeight(prng, params.ringQ, params.h, false),
-		gaussianSamplerQ: ring.NewGaussianSampler(prng, params.RingQ(), params.Sigma(), int(6*params.Sigma())),
-		uniformSamplerQ:  ring.NewUniformSampler(prng, params.RingQ()),
-		uniformSamplerP:  uniformSamplerP,
+		params:          params,
+		prng:            prng,
+		poolQ:           params.RingQ().NewPoly(),
+		poolQP:          poolQP,
+		xsSampler:       params.Xs().NewSampler(prng, params.RingQ(), params.GaussianSamplerType()),
+		xeSamplerQ:      params.Xe().NewSampler(prng, params.RingQ(), params.GaussianSamplerType()),
+		uniformSamplerQ: ring.NewUniformSampler(prng, params.RingQ()),
+		uniformSamplerP: uniformSamplerP,
 	}
 }
 
-// GenSecretKey generates a new SecretKey with the distribution [1/3, 1/3, 1/3].
+// GenSecretKey generates a new SecretKey with the secret distribution of the parameters (see Parameters.Xs).
 func (keygen *keyGenerator) GenSecretKey() (sk *SecretKey) {
-	return keygen.genSecretKeyFromSampler(keygen.ternarySampler)
+	return keygen.genSecretKeyFromSampler(keygen.xsSampler)
 }
 
 // GenSecretKey generates a new SecretKey with the error distribution.
 func (keygen *keyGenerator) GenSecretKeyGaussian() (sk *SecretKey) {
-	return keygen.genSecretKeyFromSampler(keygen.gaussianSamplerQ)
+	return keygen.genSecretKeyFromSampler(keygen.xeSamplerQ)
 }
 
 // GenSecretKeyWithDistrib generates a new SecretKey with the distribution [(p-1)/2, p, (p-1)/2].
 func (keygen *keyGenerator) GenSecretKeyWithDistrib(p float64) (sk *SecretKey) {
-	prng, err := utils.NewPRNG()
-	if err != nil {
-		panic(err)
-	}
-	ternarySamplerMontgomery := ring.NewTernarySampler(prng, keygen.params.RingQ(), p, false)
+	ternarySamplerMontgomery := ring.NewTernarySampler(keygen.prng, keygen.params.RingQ(), p, false)
 	return keygen.genSecretKeyFromSampler(ternarySamplerMontgomery)
 }
 
 // GenSecretKeyWithHammingWeight generates a new SecretKey with exactly hw non-zero coefficients.
 func (keygen *keyGenerator) GenSecretKeyWithHammingWeight(hw int) (sk *SecretKey) {
-	prng, err := utils.NewPRNG()
-	if err != nil {
-		panic(err)
-	}
-	ternarySamplerMontgomery := ring.NewTernarySamplerWithHammingWeight(prng, keygen.params.RingQ(), hw, false)
+	ternarySamplerMontgomery := ring.NewTernarySamplerWithHammingWeight(keygen.prng, keygen.params.RingQ(), hw, false)
 	return keygen.genSecretKeyFromSampler(ternarySamplerMontgomery)
 }
 
@@ -132,7 +138,7 @@ func (keygen *keyGenerator) GenPublicKey(sk *SecretKey) (pk *PublicKey) {
 		//pk[0] = [-as + e]
 		//pk[1] = [a]
 		pk = NewPublicKey(keygen.params)
-		keygen.gaussianSamplerQ.Read(pk.Value[0].Q)
+		keygen.xeSamplerQ.Read(pk.Value[0].Q)
 		ringQP.ExtendBasisSmallNormAndCenter(pk.Value[0].Q, levelP, nil, pk.Value[0].P)
 		ringQP.NTTLvl(levelQ, levelP, pk.Value[0], pk.Value[0])
 
@@ -146,7 +152,7 @@ func (keygen *keyGenerator) GenPublicKey(sk *SecretKey) (pk *PublicKey) {
 		//pk[0] = [-as + e]
 		//pk[1] = [a]
 		pk = NewPublicKey(keygen.params)
-		keygen.gaussianSamplerQ.Read(pk.Value[0].Q)
+		keygen.xeSamplerQ.Read(pk.Value[0].Q)
 
 		ringQ.NTT(pk.Value[0].Q, pk.Value[0].Q)
 
@@ -355,7 +361,7 @@ func (keygen *keyGenerator) genSwitchingKey(skIn *ring.Poly, skOut PolyQP, swk *
 	for i := 0; i < beta; i++ {
 
 		// e
-		keygen.gaussianSamplerQ.ReadLvl(levelQ, swk.Value[i][0].Q)
+		keygen.xeSamplerQ.ReadLvl(levelQ, swk.Value[i][0].Q)
 		ringQP.ExtendBasisSmallNormAndCenter(swk.Value[i][0].Q, levelP, nil, swk.Value[i][0].P)
 		ringQP.NTTLazyLvl(levelQ, levelP, swk.Value[i][0], swk.Value[i][0])
 		ringQP.MFormLvl(levelQ, levelP, swk.Value[i][0], swk.Value[i][0])
@@ -42,8 +42,27 @@ const GaloisGen uint64 = 5
 // Optionally, users may specify the error variance (Sigma) and secrets' density (H) and the ring
 // type (RingType). If left unset, standard default values for these field are substituted at
 // parameter creation (see NewParametersFromLiteral).
+//
+// For the ring.Cyclotomic ring type, users must set the cyclotomic order (M) instead of LogN: the
+// ring is then Z[X]/(Phi_M(X)) of degree phi(M).
+//
+// Users may also set the size of the RNS limbs (LimbSize) to 32 to restrict the moduli to 32-bit primes, so that
+// the polynomials can be encoded with ring.Poly.WriteTo32, and the type of
+// the discrete Gaussian samplers (GaussianSamplerType), which are used for the errors and for the smudging
+// noise of the multiparty protocols.
+//
+// Finally, users may set the distributions of the secrets (Xs), of the encryption and key errors (Xe) and
+// of the ephemeral secrets u of the public-key encryption (Xu). If left unset, they default to the ternary
+// distribution of Hamming weight H for Xs and Xu, and to the discrete Gaussian distribution of standard
+// deviation Sigma truncated at 6*Sigma for Xe.
+//
+// Note that a LimbSize of 32 does not instantiate a 32-bit ring: the polynomials are still stored on 64-bit words in
+// memory, the keys and ciphertexts are still serialized on 64 bits, and the modular arithmetic, the NTT tables and
+// the samplers are the 64-bit ones of the ring package. There is no 32-bit limb storage or arithmetic, and the
+// moduli remain limited to MaxModuliSize bits for both limb sizes.
 type ParametersLiteral struct {
 	LogN     int
+	M        int `json:",omitempty"`
 	Q        []uint64
 	P        []uint64
 	LogQ     []int `json:",omitempty"`
@@ -51,12 +70,20 @@ type ParametersLiteral struct {
 	Sigma    float64
 	H        int
 	RingType ring.Type
+	LimbSize int `json:",omitempty"`
+
+	GaussianSamplerType ring.GaussianSamplerType `json:",omitempty"`
+
+	Xs *ring.Distribution `json:",omitempty"`
+	Xe *ring.Distribution `json:",omitempty"`
+	Xu *ring.Distribution `json:",omitempty"`
 }
 
 // Parameters represents a set of generic RLWE parameters. Its fields are private and
 // immutable. See ParametersLiteral for user-specified parameters.
 type Parameters struct {
 	logN     int
+	m        int
 	qi       []uint64
 	pi       []uint64
 	sigma    float64
@@ -64,6 +91,11 @@ type Parameters struct {
 	ringQ    *ring.Ring
 	ringP    *ring.Ring
 	ringType ring.Type
+	limbSize int
+
+	gaussianSamplerType ring.GaussianSamplerType
+
+	xs, xe, xu ring.Distribution
 }
 
 // NewParameters returns a new set of generic RLWE parameters from the given ring degree logn, moduli q and p, and
@@ -82,6 +114,10 @@ func NewParameters(logn int, q, p []uint64, h int, sigma float64, ringType ring.
 		h:        h,
 		sigma:    sigma,
 		ringType: ringType,
+		limbSize: 64,
+		xs:       defaultSecretDistribution(h),
+		xe:       defaultErrorDistribution(sigma),
+		xu:       defaultSecretDistribution(h),
 	}
 
 	// pre-check that moduli chain is of valid size and that all factors are prime.
@@ -96,6 +132,44 @@ func NewParameters(logn int, q, p []uint64, h int, sigma float64, ringType ring.
 	return params, params.initRings()
 }
 
+// NewParametersCyclotomic returns a new set of generic RLWE parameters over the ring Z[X]/(Phi_m(X)) of degree phi(m),
+// for the cyclotomic order m, with moduli q and p and error distribution parameter sigma. It returns the empty parameters
+// Parameters{} and a non-nil error if the specified parameters are invalid.
+func NewParametersCyclotomic(m int, q, p []uint64, h int, sigma float64) (Parameters, error) {
+
+	if m < 3 {
+		return Parameters{}, fmt.Errorf("invalid cyclotomic order m=%d", m)
+	}
+
+	var err error
+	if err = checkSizeParams(logCyclotomicDegree(m), len(q), len(p)); err != nil {
+		return Parameters{}, err
+	}
+
+	if err = CheckModuli(q, p); err != nil {
+		return Parameters{}, err
+	}
+
+	params := Parameters{
+		logN:     logCyclotomicDegree(m),
+		m:        m,
+		pi:       make([]uint64, len(p)),
+		qi:       make([]uint64, len(q)),
+		h:        h,
+		sigma:    sigma,
+		ringType: ring.Cyclotomic,
+		limbSize: 64,
+		xs:       defaultSecretDistribution(h),
+		xe:       defaultErrorDistribution(sigma),
+		xu:       defaultSecretDistribution(h),
+	}
+
+	copy(params.qi, q)
+	copy(params.pi, p)
+
+	return params, params.initRings()
+}
+
 // NewParametersFromLiteral instantiate a set of generic RLWE parameters from a ParametersLiteral specification.
 // It returns the empty parameters Parameters{} and a non-nil error if the specified parameters are invalid.
 //
@@ -108,7 +182,43 @@ func NewParameters(logn int, q, p []uint64, h int, sigma float64, ringType ring.
 // If the error variance is left unset, its value is set to `DefaultSigma`.
 //
 // If the RingType is left unset, the default value is ring.Standard.
-func NewParametersFromLiteral(paramDef ParametersLiteral) (Parameters, error) {
+//
+// If the LimbSize is left unset, the default value is 64. If it is set to 32, the moduli must be smaller than 2^32
+// and the sizes specified through the LogQ and LogP fields must be at most 31.
+//
+// If the GaussianSamplerType is left unset, the default value is ring.GaussianFloat.
+//
+// If the distributions Xs, Xe or Xu are left unset, they are derived from H and Sigma (see ParametersLiteral).
+func NewParametersFromLiteral(paramDef ParametersLiteral) (params Parameters, err error) {
+
+	if paramDef.LimbSize == 32 {
+		if err = checkModuliLogSize32(paramDef.LogQ, paramDef.LogP); err != nil {
+			return Parameters{}, err
+		}
+	}
+
+	if paramDef.RingType == ring.Cyclotomic {
+		params, err = newParametersCyclotomicFromLiteral(paramDef)
+	} else {
+		params, err = newParametersStandardFromLiteral(paramDef)
+	}
+
+	if err != nil {
+		return Parameters{}, err
+	}
+
+	if params, err = params.withLimbSize(paramDef.LimbSize); err != nil {
+		return Parameters{}, err
+	}
+
+	if params, err = params.withGaussianSamplerType(paramDef.GaussianSamplerType); err != nil {
+		return Parameters{}, err
+	}
+
+	return params.withDistributions(paramDef.Xs, paramDef.Xe, paramDef.Xu)
+}
+
+func newParametersStandardFromLiteral(paramDef ParametersLiteral) (Parameters, error) {
 
 	if paramDef.H == 0 {
 		paramDef.H = 1 << (paramDef.LogN - 1)
@@ -143,6 +253,96 @@ func NewParametersFromLiteral(paramDef ParametersLiteral) (Parameters, error) {
 	}
 }
 
+func newParametersCyclotomicFromLiteral(paramDef ParametersLiteral) (Parameters, error) {
+
+	if paramDef.M < 3 {
+		return Parameters{}, fmt.Errorf("invalid cyclotomic order M=%d", paramDef.M)
+	}
+
+	if paramDef.H == 0 {
+		paramDef.H = int(ring.EulerTotient(paramDef.M)) >> 1
+	}
+
+	if paramDef.Sigma == 0 {
+		paramDef.Sigma = DefaultSigma
+	}
+
+	switch {
+	case paramDef.Q != nil && paramDef.LogQ == nil && paramDef.P != nil && paramDef.LogP == nil:
+		return NewParametersCyclotomic(paramDef.M, paramDef.Q, paramDef.P, paramDef.H, paramDef.Sigma)
+	case paramDef.LogQ != nil && paramDef.Q == nil && paramDef.LogP != nil && paramDef.P == nil:
+		q, p, err := GenModuliCyclotomic(paramDef.M, paramDef.LogQ, paramDef.LogP)
+		if err != nil {
+			return Parameters{}, err
+		}
+		return NewParametersCyclotomic(paramDef.M, q, p, paramDef.H, paramDef.Sigma)
+	default:
+		return Parameters{}, fmt.Errorf("invalid parameter literal")
+	}
+}
+
+// withLimbSize returns a copy of the receiver with RNS limbs of limbSize bits.
+// It returns an error if limbSize is not 32 or 64 or if a modulus does not fit on limbSize bits.
+func (p Parameters) withLimbSize(limbSize int) (Parameters, error) {
+	switch limbSize {
+	case 0, 64:
+		p.limbSize = 64
+	case 32:
+		for i, qi := range p.qi {
+			if bits.Len64(qi) > ring.MaxModulusSize32 {
+				return Parameters{}, fmt.Errorf("Qi (i=%d) does not fit on 32-bit limbs", i)
+			}
+		}
+		for i, pi := range p.pi {
+			if bits.Len64(pi) > ring.MaxModulusSize32 {
+				return Parameters{}, fmt.Errorf("Pi (i=%d) does not fit on 32-bit limbs", i)
+			}
+		}
+		p.limbSize = 32
+	default:
+		return Parameters{}, fmt.Errorf("invalid limb size %d: must be 32 or 64", limbSize)
+	}
+	return p, nil
+}
+
+// withGaussianSamplerType returns a copy of the receiver with discrete Gaussian samplers of the given type.
+func (p Parameters) withGaussianSamplerType(samplerType ring.GaussianSamplerType) (Parameters, error) {
+	switch samplerType {
+	case ring.GaussianFloat, ring.GaussianCDT, ring.GaussianConvolution:
+		p.gaussianSamplerType = samplerType
+		return p, nil
+	default:
+		return Parameters{}, fmt.Errorf("invalid Gaussian sampler type %d", samplerType)
+	}
+}
+
+// withDistributions returns a copy of the receiver with the given distributions of the secrets, of the errors and
+// of the ephemeral secrets. The nil distributions are left unchanged.
+func (p Parameters) withDistributions(xs, xe, xu *ring.Distribution) (Parameters, error) {
+	for _, d := range []struct {
+		name string
+		dist *ring.Distribution
+		rec  *ring.Distribution
+	}{{"Xs", xs, &p.xs}, {"Xe", xe, &p.xe}, {"Xu", xu, &p.xu}} {
+		if d.dist == nil {
+			continue
+		}
+		if err := d.dist.Validate(p.N()); err != nil {
+			return Parameters{}, fmt.Errorf("invalid %s: %w", d.name, err)
+		}
+		*d.rec = *d.dist
+	}
+	return p, nil
+}
+
+func defaultSecretDistribution(h int) ring.Distribution {
+	return ring.Distribution{Type: ring.TernaryHammingWeight, H: h}
+}
+
+func defaultErrorDistribution(sigma float64) ring.Distribution {
+	return ring.Distribution{Type: ring.DiscreteGaussian, Sigma: sigma, Bound: 6 * sigma}
+}
+
 // StandardParameters returns a RLWE parameter set that corresponds to the
 // standard dual of a conjugate invariant parameter set. If the receiver is already
 // a standard set, then the method returns the receiver.
@@ -165,14 +365,30 @@ func (p Parameters) StandardParameters() (pci Parameters, err error) {
 
 // N returns the ring degree
 func (p Parameters) N() int {
+	if p.ringType == ring.Cyclotomic {
+		return p.ringQ.N
+	}
 	return 1 << p.logN
 }
 
-// LogN returns the log of the degree of the polynomial ring
+// LogN returns the log of the degree of the polynomial ring, rounded up for the Cyclotomic ring type.
 func (p Parameters) LogN() int {
 	return p.logN
 }
 
+// M returns the order of the cyclotomic polynomial defining the ring, i.e. 2N for the Standard ring type, 4N for the
+// ConjugateInvariant ring type and the cyclotomic order for the Cyclotomic ring type.
+func (p Parameters) M() int {
+	switch p.ringType {
+	case ring.ConjugateInvariant:
+		return 4 << p.logN
+	case ring.Cyclotomic:
+		return p.m
+	default:
+		return 2 << p.logN
+	}
+}
+
 // RingQ returns a pointer to ringQ
 func (p Parameters) RingQ() *ring.Ring {
 	return p.ringQ
@@ -198,11 +414,38 @@ func (p Parameters) Sigma() float64 {
 	return p.sigma
 }
 
+// Xs returns the distribution of the secrets.
+func (p Parameters) Xs() ring.Distribution {
+	return p.xs
+}
+
+// Xe returns the distribution of the encryption and key errors.
+func (p Parameters) Xe() ring.Distribution {
+	return p.xe
+}
+
+// Xu returns the distribution of the ephemeral secrets of the public-key encryption.
+func (p Parameters) Xu() ring.Distribution {
+	return p.xu
+}
+
 // RingType returns the type of the underlying ring.
 func (p Parameters) RingType() ring.Type {
 	return p.ringType
 }
 
+// GaussianSamplerType returns the type of the discrete Gaussian samplers of the errors and of the smudging noise.
+func (p Parameters) GaussianSamplerType() ring.GaussianSamplerType {
+	return p.gaussianSamplerType
+}
+
+// LimbSize returns the bit-size of the RNS limbs, i.e. 32 if all the moduli are restricted to 32 bits and 64 otherwise.
+// The limb size only restricts the moduli: the polynomials are stored on 64-bit words and computed with the 64-bit
+// arithmetic regardless of it.
+func (p Parameters) LimbSize() int {
+	return p.limbSize
+}
+
 // MaxLevel returns the maximum level of a ciphertext
 func (p Parameters) MaxLevel() int {
 	return p.QCount() - 1
@@ -335,7 +578,19 @@ func (p *Parameters) PiOverflowMargin(level int) int {
 // column rotations by k position to the left. Providing a negative k is
 // equivalent to a right rotation.
 func (p Parameters) GaloisElementForColumnRotationBy(k int) uint64 {
-	return ring.ModExp(GaloisGen, uint64(k&int(p.ringQ.NthRoot-1)), p.ringQ.NthRoot)
+	if p.ringType == ring.Cyclotomic {
+		panic("Cannot generate GaloisElementForColumnRotationBy if ringType is Cyclotomic")
+	}
+	// NthRoot is a power of two, hence the exponentiation is computed modulo 2^64 and then masked.
+	mask := p.ringQ.NthRoot - 1
+	galEl, x := uint64(1), uint64(GaloisGen)
+	for e := uint64(k) & mask; e > 0; e >>= 1 {
+		if e&1 == 1 {
+			galEl *= x
+		}
+		x *= x
+	}
+	return galEl & mask
 }
 
 // GaloisElementForRowRotation returns the galois element for generating the row
@@ -371,17 +626,62 @@ func (p Parameters) GaloisElementsForRowInnerSum() (galEls []uint64) {
 // InverseGaloisElement takes a galois element and returns the galois element
 //  corresponding to the inverse automorphism
 func (p Parameters) InverseGaloisElement(galEl uint64) uint64 {
+	if p.ringType == ring.Cyclotomic {
+		// galEl^(phi(m)-1) = galEl^-1 mod m
+		return ring.ModExp(galEl, uint64(p.N()-1), p.ringQ.NthRoot)
+	}
 	return ring.ModExp(galEl, p.ringQ.NthRoot-1, p.ringQ.NthRoot)
 }
 
+// RotationForGaloisElement takes a galois element and returns the k in [0, NthRoot/4) and the
+// flag rowRotation such that galEl = (-1)^rowRotation * GaloisGen^k mod NthRoot, i.e. the
+// column rotation by k positions to the left, followed by a row rotation if rowRotation is true.
+// It is the inverse of GaloisElementForColumnRotationBy.
+func (p Parameters) RotationForGaloisElement(galEl uint64) (k int, rowRotation bool) {
+
+	if p.ringType == ring.Cyclotomic {
+		panic("Cannot compute RotationForGaloisElement if ringType is Cyclotomic")
+	}
+
+	mask := p.ringQ.NthRoot - 1
+	galEl &= mask
+
+	if galEl&1 == 0 {
+		panic("invalid galois element: must be odd")
+	}
+
+	if galEl&3 == 3 {
+		galEl = (p.ringQ.NthRoot - galEl) & mask
+		rowRotation = true
+	}
+
+	// GaloisGen^(2^i) = 1 + 2^(i+2) mod 2^(i+3), hence the bits of k can be recovered
+	// one by one, from the least significant to the most significant.
+	logNthRoot := bits.Len64(p.ringQ.NthRoot) - 1
+	x, pow := uint64(1), uint64(GaloisGen)
+	for i := 0; i < logNthRoot-2; i++ {
+		if (x^galEl)&((1<<(i+3))-1) != 0 {
+			k |= 1 << i
+			x *= pow
+		}
+		pow *= pow
+	}
+
+	return
+}
+
 // Equals checks two Parameter structs for equality.
 func (p Parameters) Equals(other Parameters) bool {
 	res := p.logN == other.logN
+	res = res && (p.m == other.m)
 	res = res && utils.EqualSliceUint64(p.qi, other.qi)
 	res = res && utils.EqualSliceUint64(p.pi, other.pi)
 	res = res && (p.h == other.h)
 	res = res && (p.sigma == other.sigma)
 	res = res && (p.ringType == other.ringType)
+	res = res && (p.limbSize == other.limbSize)
+	res = res && (p.gaussianSamplerType == other.gaussianSamplerType)
+	res = res && (p.xs == other.xs) && (p.xe == other.xe) && (p.xu == other.xu)
 	return res
 }
 
@@ -394,11 +694,8 @@ func (p Parameters) CopyNew() Parameters {
 	qi, pi := p.qi, p.pi
 	p.qi, p.pi = make([]uint64, len(p.qi)), make([]uint64, len(p.pi))
 	copy(p.qi, qi)
-	p.ringQ, _ = ring.NewRingFromType(1<<p.logN, p.qi, p.ringType)
-	if len(p.pi) > 0 {
-		copy(p.pi, pi)
-		p.ringP, _ = ring.NewRingFromType(1<<p.logN, p.pi, p.ringType)
-	}
+	copy(p.pi, pi)
+	_ = p.initRings()
 	return p
 }
 
@@ -413,18 +710,34 @@ func (p Parameters) MarshalBinary() ([]byte, error) {
 	// 1 byte : #P
 	// 8 byte : H
 	// 8 byte : sigma
-	// 1 byte : ringType
+	// 1 byte : ringType (bits 0 to 3), GaussianSamplerType (bits 4 to 6) and 32-bit limbs (bit 7)
+	// 8 byte : m (only for ring.Cyclotomic)
 	// 8 * (#Q) : Q
 	// 8 * (#P) : P
+	// 17 * 3 : Xs, Xe and Xu
 	b := utils.NewBuffer(make([]byte, 0, p.MarshalBinarySize()))
 	b.WriteUint8(uint8(p.logN))
 	b.WriteUint8(uint8(len(p.qi)))
 	b.WriteUint8(uint8(len(p.pi)))
 	b.WriteUint64(uint64(p.h))
 	b.WriteUint64(math.Float64bits(p.sigma))
-	b.WriteUint8(uint8(p.ringType))
+	flags := uint8(p.ringType) | uint8(p.gaussianSamplerType)<<4
+	if p.limbSize == 32 {
+		flags |= 0x80
+	}
+	b.WriteUint8(flags)
+	if p.ringType == ring.Cyclotomic {
+		b.WriteUint64(uint64(p.m))
+	}
 	b.WriteUint64Slice(p.qi)
 	b.WriteUint64Slice(p.pi)
+	for _, d := range []ring.Distribution{p.xs, p.xe, p.xu} {
+		data, err := d.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		b.WriteUint8Slice(data)
+	}
 	return b.Bytes(), nil
 }
 
@@ -439,7 +752,19 @@ func (p *Parameters) UnmarshalBinary(data []byte) error {
 	lenP := int(b.ReadUint8())
 	h := int(b.ReadUint64())
 	sigma := math.Float64frombits(b.ReadUint64())
-	ringType := ring.Type(b.ReadUint8())
+	flags := b.ReadUint8()
+	ringType := ring.Type(flags & 0x0F)
+	samplerType := ring.GaussianSamplerType((flags >> 4) & 0x07)
+
+	limbSize := 64
+	if flags&0x80 != 0 {
+		limbSize = 32
+	}
+
+	var m int
+	if ringType == ring.Cyclotomic {
+		m = int(b.ReadUint64())
+	}
 
 	if err := checkSizeParams(logN, lenQ, lenP); err != nil {
 		return err
@@ -450,19 +775,61 @@ func (p *Parameters) UnmarshalBinary(data []byte) error {
 	b.ReadUint64Slice(qi)
 	b.ReadUint64Slice(pi)
 
+	var xs, xe, xu ring.Distribution
+	for _, d := range []*ring.Distribution{&xs, &xe, &xu} {
+		dataDist := make([]byte, d.MarshalBinarySize())
+		b.ReadUint8Slice(dataDist)
+		if err := d.UnmarshalBinary(dataDist); err != nil {
+			return err
+		}
+	}
+
+	var params Parameters
 	var err error
-	*p, err = NewParameters(logN, qi, pi, h, sigma, ringType)
+	if ringType == ring.Cyclotomic {
+		params, err = NewParametersCyclotomic(m, qi, pi, h, sigma)
+	} else {
+		params, err = NewParameters(logN, qi, pi, h, sigma, ringType)
+	}
+
+	if err != nil {
+		*p = Parameters{}
+		return err
+	}
+
+	if params, err = params.withLimbSize(limbSize); err != nil {
+		*p = Parameters{}
+		return err
+	}
+
+	if params, err = params.withGaussianSamplerType(samplerType); err != nil {
+		*p = Parameters{}
+		return err
+	}
+
+	*p, err = params.withDistributions(&xs, &xe, &xu)
 	return err
 }
 
 // MarshalBinarySize returns the length of the []byte encoding of the reciever.
 func (p Parameters) MarshalBinarySize() int {
-	return 20 + (len(p.qi)+len(p.pi))<<3
+	if p.ringType == ring.Cyclotomic {
+		return 28 + (len(p.qi)+len(p.pi))<<3 + 3*p.xs.MarshalBinarySize()
+	}
+	return 20 + (len(p.qi)+len(p.pi))<<3 + 3*p.xs.MarshalBinarySize()
 }
 
 // MarshalJSON returns a JSON representation of this parameter set. See `Marshal` from the `encoding/json` package.
 func (p Parameters) MarshalJSON() ([]byte, error) {
-	return json.Marshal(&ParametersLiteral{LogN: p.logN, Q: p.qi, P: p.pi, H: p.h, Sigma: p.sigma})
+	var limbSize int
+	if p.limbSize == 32 {
+		limbSize = 32
+	}
+	xs, xe, xu := p.xs, p.xe, p.xu
+	if p.ringType == ring.Cyclotomic {
+		return json.Marshal(&ParametersLiteral{M: p.m, Q: p.qi, P: p.pi, H: p.h, Sigma: p.sigma, RingType: p.ringType, LimbSize: limbSize, GaussianSamplerType: p.gaussianSamplerType, Xs: &xs, Xe: &xe, Xu: &xu})
+	}
+	return json.Marshal(&ParametersLiteral{LogN: p.logN, Q: p.qi, P: p.pi, H: p.h, Sigma: p.sigma, LimbSize: limbSize, GaussianSamplerType: p.gaussianSamplerType, Xs: &xs, Xe: &xe, Xu: &xu})
 }
 
 // UnmarshalJSON reads a JSON representation of a parameter set into the receiver Parameter. See `Unmarshal` from the `encoding/json` package.
@@ -513,6 +880,23 @@ func CheckModuli(q, p []uint64) error {
 	return nil
 }
 
+func checkModuliLogSize32(logQ, logP []int) error {
+
+	for i, qi := range logQ {
+		if qi > ring.MaxModulusSize32-1 {
+			return fmt.Errorf("logQ[%d]=%d is not in ]0, %d] for 32-bit limbs", i, qi, ring.MaxModulusSize32-1)
+		}
+	}
+
+	for i, pi := range logP {
+		if pi > ring.MaxModulusSize32-1 {
+			return fmt.Errorf("logP[%d]=%d is not in ]0, %d] for 32-bit limbs", i, pi, ring.MaxModulusSize32-1)
+		}
+	}
+
+	return nil
+}
+
 func checkSizeParams(logN int, lenQ, lenP int) error {
 	if logN > MaxLogN {
 		return fmt.Errorf("logN=%d is larger than MaxLogN=%d", logN, MaxLogN)
@@ -553,6 +937,26 @@ func GenModuli(logN int, logQ, logP []int) (q, p []uint64, err error) {
 		return
 	}
 
+	return genModuli(2<<logN, logQ, logP)
+}
+
+// GenModuliCyclotomic generates a valid moduli chain for the ring Z[X]/(Phi_m(X)) from the provided moduli sizes.
+func GenModuliCyclotomic(m int, logQ, logP []int) (q, p []uint64, err error) {
+
+	if err = checkSizeParams(logCyclotomicDegree(m), len(logQ), len(logP)); err != nil {
+		return
+	}
+
+	return genModuli(int(ring.CyclotomicNTTRoot(m)), logQ, logP)
+}
+
+// logCyclotomicDegree returns ceil(log2(phi(m))).
+func logCyclotomicDegree(m int) int {
+	return bits.Len64(ring.EulerTotient(m) - 1)
+}
+
+func genModuli(NthRoot int, logQ, logP []int) (q, p []uint64, err error) {
+
 	if err = checkModuliLogSize(logQ, logP); err != nil {
 		return
 	}
@@ -570,7 +974,7 @@ func GenModuli(logN int, logQ, logP []int) (q, p []uint64, err error) {
 	// For each bit-size, finds that many primes
 	primes := make(map[int][]uint64)
 	for key, value := range primesbitlen {
-		primes[key] = ring.GenerateNTTPrimes(int(key), 2<<logN, int(value))
+		primes[key] = ring.GenerateNTTPrimes(int(key), NthRoot, int(value))
 	}
 
 	// Assigns the primes to the moduli chain
@@ -589,6 +993,15 @@ func GenModuli(logN int, logQ, logP []int) (q, p []uint64, err error) {
 }
 
 func (p *Parameters) initRings() (err error) {
+	if p.ringType == ring.Cyclotomic {
+		if p.ringQ, err = ring.NewRingCyclotomic(p.m, p.qi); err != nil {
+			return err
+		}
+		if len(p.pi) != 0 {
+			p.ringP, err = ring.NewRingCyclotomic(p.m, p.pi)
+		}
+		return err
+	}
 	if p.ringQ, err = ring.NewRingFromType(1<<p.logN, p.qi, p.ringType); err != nil {
 		return err
 	}
@@ -0,0 +1,229 @@
+package rlwe
+
+import (
+	"fmt"
+	"math/bits"
+	"sort"
+
+	"github.com/tuneinsight/lattigo/v3/ring"
+)
+
+// PermutationNetwork is a decomposition of an arbitrary permutation of the plaintext slots into a
+// sequence of layers, each layer being a sum of masked automorphisms:
+//
+// out = sum_{galEl} Layers[i][galEl] * phi_{galEl}(in),
+//
+// where phi_{galEl} is the automorphism X -> X^galEl and Layers[i][galEl] is a 0/1 mask on the slots.
+// The galois element 1 stands for the identity.
+//
+// The decomposition follows a Benes network: a permutation of 2^k slots is decomposed into at most
+// 2k-1 layers, each using at most three distinct galois elements (the identity, and the rotations
+// by +2^j and -2^j, or the row rotation), hence the evaluation requires at most 2 log2(Slots) rotation keys.
+// Layers that do not move any slot are omitted.
+type PermutationNetwork struct {
+	Slots  int
+	Layers []map[uint64][]bool
+}
+
+// NewPermutationNetwork decomposes the permutation of the plaintext slots given by
+// out[i] = in[permutation[i]] into a PermutationNetwork.
+//
+// The slots are arranged as the rotation group of the ring: if len(permutation) = N and the ring is
+// ring.Standard, the slots are seen as a 2 x N/2 matrix (as in the BFV scheme), whose columns are rotated
+// by the column rotations and whose rows are swapped by the row rotation. Otherwise, len(permutation) must
+// be a power of two smaller or equal to the number of columns (N/2 for ring.Standard, N for
+// ring.ConjugateInvariant) and the slots are seen as a single row, rotated cyclically by the column
+// rotations (as in the CKKS scheme with sparse packing).
+//
+// The method will panic if permutation is not a permutation of [0, len(permutation)).
+func (p Parameters) NewPermutationNetwork(permutation []int) (pn *PermutationNetwork) {
+
+	if p.ringType == ring.Cyclotomic {
+		panic("cannot NewPermutationNetwork: ringType is Cyclotomic")
+	}
+
+	slots := len(permutation)
+
+	if slots == 0 || slots&(slots-1) != 0 {
+		panic("cannot NewPermutationNetwork: len(permutation) must be a power of two")
+	}
+
+	columns := int(p.ringQ.NthRoot >> 2)
+
+	var rowRotation bool
+	if p.ringType == ring.Standard && slots == p.N() {
+		rowRotation = true
+		columns = p.N() >> 1
+	} else if slots > columns {
+		panic(fmt.Sprintf("cannot NewPermutationNetwork: len(permutation)=%d is larger than the number of slots", slots))
+	} else {
+		columns = slots
+	}
+
+	// dst[j] is the output position of the slot at position j
+	dst := make([]int, slots)
+	for i := range dst {
+		dst[i] = -1
+	}
+
+	for i, j := range permutation {
+		if j < 0 || j >= slots || dst[j] != -1 {
+			panic("cannot NewPermutationNetwork: input is not a permutation")
+		}
+		dst[j] = i
+	}
+
+	swaps := benesNetwork(dst)
+
+	pn = &PermutationNetwork{Slots: slots, Layers: []map[uint64][]bool{}}
+
+	logSlots := bits.Len64(uint64(slots)) - 1
+
+	for i := range swaps {
+
+		// Layer i acts on the bit t of the slot indexes: a swapped slot j is exchanged with the slot j ^ 2^t
+		t := logSlots - 1 - i
+		if i >= logSlots {
+			t = i - logSlots + 1
+		}
+
+		b := 1 << t
+
+		var galEls [3]uint64
+		galEls[0] = 1
+		if rowRotation && b == columns {
+			galEls[1] = p.GaloisElementForRowRotation()
+			galEls[2] = galEls[1]
+		} else {
+			galEls[1] = p.GaloisElementForColumnRotationBy(b)
+			galEls[2] = p.GaloisElementForColumnRotationBy(columns - b)
+		}
+
+		layer := make(map[uint64][]bool)
+
+		var moved bool
+		for j, swap := range swaps[i] {
+
+			galEl := galEls[0]
+			if swap {
+				moved = true
+				if j&b == 0 {
+					galEl = galEls[1]
+				} else {
+					galEl = galEls[2]
+				}
+			}
+
+			if _, ok := layer[galEl]; !ok {
+				layer[galEl] = make([]bool, slots)
+			}
+
+			layer[galEl][j] = true
+		}
+
+		if moved {
+			pn.Layers = append(pn.Layers, layer)
+		}
+	}
+
+	return
+}
+
+// Depth returns the number of layers of the network, i.e. the number of
+// plaintext-ciphertext multiplications needed for its evaluation.
+func (pn *PermutationNetwork) Depth() int {
+	return len(pn.Layers)
+}
+
+// GaloisElements returns the sorted list of the galois elements needed for the evaluation of the network.
+func (pn *PermutationNetwork) GaloisElements() (galEls []uint64) {
+
+	set := make(map[uint64]bool)
+	for _, layer := range pn.Layers {
+		for galEl := range layer {
+			if galEl != 1 {
+				set[galEl] = true
+			}
+		}
+	}
+
+	galEls = make([]uint64, 0, len(set))
+	for galEl := range set {
+		galEls = append(galEls, galEl)
+	}
+
+	sort.Slice(galEls, func(i, j int) bool { return galEls[i] < galEls[j] })
+
+	return
+}
+
+// benesNetwork routes the permutation given by dst (the slot at position j is sent to position dst[j])
+// through a Benes network on len(dst) = 2^k positions, using the looping algorithm. It returns the 2k-1
+// layers of switches: the layer i < k exchanges the positions that differ in the bit k-1-i, and the layer
+// i >= k exchanges the positions that differ in the bit i-k+1, and swaps[i][j] is true if the position j
+// is exchanged.
+func benesNetwork(dst []int) (swaps [][]bool) {
+
+	n := len(dst)
+	k := bits.Len64(uint64(n)) - 1
+
+	if k == 0 {
+		return [][]bool{}
+	}
+
+	swaps = make([][]bool, 2*k-1)
+	for i := range swaps {
+		swaps[i] = make([]bool, n)
+	}
+
+	cur := make([]int, n)
+	copy(cur, dst)
+
+	inv := make([]int, n)
+	side := make([]int, n)
+	next := make([]int, n)
+
+	for r := 0; r < k-1; r++ {
+
+		// The bits above t are already routed: cur maps each position to a position
+		// that agrees with it on these bits, and the sub-networks are processed together.
+		t := k - 1 - r
+		b := 1 << t
+
+		for j := range cur {
+			inv[cur[j]] = j
+			side[j] = -1
+		}
+
+		// Assigns to each slot the sub-network (the value of bit t after the first layer) such that
+		// two slots at paired inputs or sent to paired outputs go through different sub-networks.
+		for i := 0; i < n; i++ {
+			if i&b != 0 || side[i] != -1 {
+				continue
+			}
+
+			for j := i; side[j] == -1; j = inv[cur[j^b]^b] {
+				side[j] = 0
+				side[j^b] = 1
+			}
+		}
+
+		for j := range cur {
+
+			swaps[r][j] = side[j] != (j>>t)&1
+
+			o := cur[j]
+			swaps[2*k-2-r][o] = side[j] != (o>>t)&1
+
+			next[(j&^b)|(side[j]<<t)] = (o &^ b) | (side[j] << t)
+		}
+
+		cur, next = next, cur
+	}
+
+	for j := range cur {
+		swaps[k-1][j] = cur[j] != j
+	}
+
+	return
+}
@@ -0,0 +1,75 @@
+package rlwe
+
+import (
+	"github.com/tuneinsight/lattigo/v3/ring"
+)
+
+// Pool is a memory pool of polynomials of the rings R_Q and R_P, from which the
+// evaluators draw their temporary polynomials. See ring.PolyPool.
+// A Pool is not safe for concurrent use.
+type Pool struct {
+	Q, P *ring.PolyPool
+}
+
+// NewPool creates a new Pool for the rings of params, which allocates new polynomials on demand.
+func NewPool(params Parameters) *Pool {
+	pool := &Pool{Q: ring.NewPolyPool(params.RingQ())}
+	if params.RingP() != nil {
+		pool.P = ring.NewPolyPool(params.RingP())
+	}
+	return pool
+}
+
+// NewArena creates a new Pool for the rings of params, pre-allocating sizeQ polynomials of R_Q and
+// sizeP polynomials of R_P at the maximum level. The returned Pool never allocates and panics when exhausted.
+// The sizes needed by a circuit are given by MaxInUse after its evaluation with a Pool created with NewPool.
+func NewArena(params Parameters, sizeQ, sizeP int) *Pool {
+	pool := &Pool{Q: ring.NewPolyArena(params.RingQ(), sizeQ)}
+	if params.RingP() != nil {
+		pool.P = ring.NewPolyArena(params.RingP(), sizeP)
+	}
+	return pool
+}
+
+// MaxInUse returns the peak numbers of polynomials of R_Q and R_P in use since the creation of the pool.
+func (pool *Pool) MaxInUse() (sizeQ, sizeP int) {
+	sizeQ = pool.Q.MaxInUse()
+	if pool.P != nil {
+		sizeP = pool.P.MaxInUse()
+	}
+	return
+}
+
+// GetPolyQP returns a PolyQP at levels levelQ and levelP from the pool.
+// The P part is nil if the pool has no ring R_P.
+func (pool *Pool) GetPolyQP(levelQ, levelP int) (p PolyQP) {
+	p.Q = pool.Q.GetPolyLvl(levelQ)
+	if pool.P != nil {
+		p.P = pool.P.GetPolyLvl(levelP)
+	}
+	return
+}
+
+// ReleasePolyQP returns the PolyQP, which must have been obtained with GetPolyQP, to the pool.
+func (pool *Pool) ReleasePolyQP(p PolyQP) {
+	pool.Q.Release(p.Q)
+	if pool.P != nil {
+		pool.P.Release(p.P)
+	}
+}
+
+// GetCiphertext returns a Ciphertext of the given degree and level whose polynomials are drawn from the pool.
+// The coefficients of the returned Ciphertext are not specified.
+func (pool *Pool) GetCiphertext(degree, level int) (ct *Ciphertext) {
+	ct = &Ciphertext{Value: make([]*ring.Poly, degree+1)}
+	for i := range ct.Value {
+		ct.Value[i] = pool.Q.GetPolyLvl(level)
+	}
+	return
+}
+
+// ReleaseCiphertext returns the polynomials of the Ciphertext, which must have been obtained with GetCiphertext, to the pool.
+func (pool *Pool) ReleaseCiphertext(ct *Ciphertext) {
+	pool.Q.Release(ct.Value...)
+	ct.Value = nil
+}
@@ -7,6 +7,7 @@ import (
 	"math"
 	"math/big"
 	"math/bits"
+	"math/rand"
 	"runtime"
 	"testing"
 
@@ -56,9 +57,11 @@ func TestRLWE(t *testing.T) {
 			testGenKeyPair,
 			testSwitchKeyGen,
 			testEncryptor,
+			testWithPRNG,
 			testDecryptor,
 			testKeySwitcher,
 			testKeySwitchDimension,
+			testPermutationNetwork,
 			testMarshaller,
 		} {
 			testSet(kgen, t)
@@ -67,6 +70,224 @@ func TestRLWE(t *testing.T) {
 	}
 }
 
+func TestRLWECyclotomic(t *testing.T) {
+
+	// 3*2^9 and a prime cyclotomic order
+	for _, paramsLit := range []ParametersLiteral{
+		{M: 1536, LogQ: []int{50, 50}, LogP: []int{55}, RingType: ring.Cyclotomic},
+		{M: 1009, LogQ: []int{50, 50}, LogP: []int{55}, RingType: ring.Cyclotomic},
+	} {
+		params, err := NewParametersFromLiteral(paramsLit)
+		if err != nil {
+			panic(err)
+		}
+
+		kgen := NewKeyGenerator(params)
+
+		for _, testSet := range []func(kgen KeyGenerator, t *testing.T){
+			testGenKeyPair,
+			testSwitchKeyGen,
+			testEncryptor,
+			testDecryptor,
+			testKeySwitcher,
+			testCyclotomic,
+		} {
+			testSet(kgen, t)
+			runtime.GC()
+		}
+	}
+}
+
+func TestRLWELimbSize32(t *testing.T) {
+
+	paramsLit := ParametersLiteral{LogN: 10, LogQ: []int{30, 30}, LogP: []int{31}, LimbSize: 32}
+
+	params, err := NewParametersFromLiteral(paramsLit)
+	if err != nil {
+		panic(err)
+	}
+
+	kgen := NewKeyGenerator(params)
+
+	for _, testSet := range []func(kgen KeyGenerator, t *testing.T){
+		testGenKeyPair,
+		testSwitchKeyGen,
+		testEncryptor,
+		testDecryptor,
+		testKeySwitcher,
+	} {
+		testSet(kgen, t)
+		runtime.GC()
+	}
+
+	t.Run(testString(params, "LimbSize32/Parameters/"), func(t *testing.T) {
+
+		require.Equal(t, 32, params.LimbSize())
+		for _, qi := range append(params.Q(), params.P()...) {
+			require.Less(t, qi, uint64(1<<32))
+		}
+
+		data, err := params.MarshalBinary()
+		require.NoError(t, err)
+		require.Equal(t, params.MarshalBinarySize(), len(data))
+		var paramsTest Parameters
+		require.NoError(t, paramsTest.UnmarshalBinary(data))
+		require.True(t, params.Equals(paramsTest))
+		require.Equal(t, 32, paramsTest.LimbSize())
+
+		data, err = json.Marshal(params)
+		require.NoError(t, err)
+		paramsTest = Parameters{}
+		require.NoError(t, json.Unmarshal(data, &paramsTest))
+		require.True(t, params.Equals(paramsTest))
+
+		paramsTest, err = NewParametersFromLiteral(ParametersLiteral{LogN: 10, LogQ: []int{30, 30}, LogP: []int{31}})
+		require.NoError(t, err)
+		require.Equal(t, 64, paramsTest.LimbSize())
+		require.False(t, params.Equals(paramsTest))
+
+		_, err = NewParametersFromLiteral(ParametersLiteral{LogN: 10, LogQ: []int{40, 30}, LogP: []int{31}, LimbSize: 32})
+		require.Error(t, err)
+
+		_, err = NewParametersFromLiteral(ParametersLiteral{LogN: 10, Q: []uint64{0x1fffffffffe00001}, P: []uint64{0x7fffd801}, LimbSize: 32})
+		require.Error(t, err)
+
+		_, err = NewParametersFromLiteral(ParametersLiteral{LogN: 10, LogQ: []int{30, 30}, LogP: []int{31}, LimbSize: 16})
+		require.Error(t, err)
+	})
+
+	t.Run(testString(params, "LimbSize32/Marshaller/Ciphertext/"), func(t *testing.T) {
+
+		prng, _ := utils.NewPRNG()
+		ciphertext := NewCiphertextRandom(prng, params, 1, params.MaxLevel())
+
+		data := make([]byte, 1)
+		data[0] = uint8(ciphertext.Degree() + 1)
+		for _, el := range ciphertext.Value {
+			buff := make([]byte, el.GetDataLen32(true))
+			_, err := el.WriteTo32(buff)
+			require.NoError(t, err)
+			data = append(data, buff...)
+		}
+
+		require.Less(t, len(data), ciphertext.GetDataLen(true))
+
+		ciphertextTest := new(Ciphertext)
+		require.NoError(t, ciphertextTest.UnmarshalBinary(data))
+		for i := range ciphertext.Value {
+			require.True(t, params.RingQ().Equal(ciphertext.Value[i], ciphertextTest.Value[i]))
+		}
+	})
+}
+
+func TestRLWEGaussianSamplers(t *testing.T) {
+
+	for _, samplerType := range []ring.GaussianSamplerType{ring.GaussianCDT, ring.GaussianConvolution} {
+
+		paramsLit := TestPN12QP109
+		paramsLit.GaussianSamplerType = samplerType
+
+		params, err := NewParametersFromLiteral(paramsLit)
+		if err != nil {
+			panic(err)
+		}
+
+		kgen := NewKeyGenerator(params)
+
+		for _, testSet := range []func(kgen KeyGenerator, t *testing.T){
+			testGenKeyPair,
+			testEncryptor,
+			testDecryptor,
+			testKeySwitcher,
+		} {
+			testSet(kgen, t)
+			runtime.GC()
+		}
+
+		t.Run(testString(params, "GaussianSamplers/"+samplerType.String()+"/Parameters/"), func(t *testing.T) {
+
+			require.Equal(t, samplerType, params.GaussianSamplerType())
+
+			data, err := params.MarshalBinary()
+			require.NoError(t, err)
+			var paramsTest Parameters
+			require.NoError(t, paramsTest.UnmarshalBinary(data))
+			require.True(t, params.Equals(paramsTest))
+
+			data, err = json.Marshal(params)
+			require.NoError(t, err)
+			paramsTest = Parameters{}
+			require.NoError(t, json.Unmarshal(data, &paramsTest))
+			require.True(t, params.Equals(paramsTest))
+
+			paramsLit.GaussianSamplerType = ring.GaussianSamplerType(5)
+			_, err = NewParametersFromLiteral(paramsLit)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestRLWEDistributions(t *testing.T) {
+
+	paramsLit := TestPN12QP109
+	paramsLit.Xs = &ring.Distribution{Type: ring.TernaryProbability, P: 0.5}
+	paramsLit.Xe = &ring.Distribution{Type: ring.UniformRange, Min: -4, Max: 4}
+	paramsLit.Xu = &ring.Distribution{Type: ring.Binary}
+
+	params, err := NewParametersFromLiteral(paramsLit)
+	if err != nil {
+		panic(err)
+	}
+
+	kgen := NewKeyGenerator(params)
+
+	for _, testSet := range []func(kgen KeyGenerator, t *testing.T){
+		testGenKeyPair,
+		testEncryptor,
+		testDecryptor,
+		testKeySwitcher,
+	} {
+		testSet(kgen, t)
+		runtime.GC()
+	}
+
+	t.Run(testString(params, "Distributions/Parameters/"), func(t *testing.T) {
+
+		require.Equal(t, *paramsLit.Xs, params.Xs())
+		require.Equal(t, *paramsLit.Xe, params.Xe())
+		require.Equal(t, *paramsLit.Xu, params.Xu())
+
+		paramsDefault, err := NewParametersFromLiteral(TestPN12QP109)
+		require.NoError(t, err)
+		require.Equal(t, ring.Distribution{Type: ring.TernaryHammingWeight, H: paramsDefault.HammingWeight()}, paramsDefault.Xs())
+		sigma := paramsDefault.Sigma()
+		require.Equal(t, ring.Distribution{Type: ring.DiscreteGaussian, Sigma: sigma, Bound: 6 * sigma}, paramsDefault.Xe())
+		require.Equal(t, paramsDefault.Xs(), paramsDefault.Xu())
+		require.False(t, params.Equals(paramsDefault))
+
+		data, err := params.MarshalBinary()
+		require.NoError(t, err)
+		require.Equal(t, params.MarshalBinarySize(), len(data))
+		var paramsTest Parameters
+		require.NoError(t, paramsTest.UnmarshalBinary(data))
+		require.True(t, params.Equals(paramsTest))
+
+		data, err = json.Marshal(params)
+		require.NoError(t, err)
+		paramsTest = Parameters{}
+		require.NoError(t, json.Unmarshal(data, &paramsTest))
+		require.True(t, params.Equals(paramsTest))
+
+		paramsLit.Xe = &ring.Distribution{Type: ring.UniformRange, Min: 4, Max: -4}
+		_, err = NewParametersFromLiteral(paramsLit)
+		require.Error(t, err)
+
+		paramsLit.Xe = &ring.Distribution{}
+		_, err = NewParametersFromLiteral(paramsLit)
+		require.Error(t, err)
+	})
+}
+
 // Returns the ceil(log2) of the sum of the absolute value of all the coefficients
 func log2OfInnerSum(level int, ringQ *ring.Ring, poly *ring.Poly) (logSum int) {
 	sumRNS := make([]uint64, level+1)
@@ -141,6 +362,10 @@ func testGenKeyPair(kgen KeyGenerator, t *testing.T) {
 	// Checks that the secret-key has exactly params.h non-zero coefficients
 	t.Run(testString(params, "SK"), func(t *testing.T) {
 
+		if params.Xs().Type != ring.TernaryHammingWeight {
+			t.Skip("secret distribution does not have a fixed Hamming weight")
+		}
+
 		skInvNTT := NewSecretKey(params)
 
 		if params.PCount() > 0 {
@@ -152,7 +377,7 @@ func testGenKeyPair(kgen KeyGenerator, t *testing.T) {
 						zeros++
 					}
 				}
-				require.Equal(t, params.ringP.N, zeros+params.h)
+				require.Equal(t, params.ringP.N, zeros+params.Xs().H)
 			}
 		}
 
@@ -164,12 +389,12 @@ func testGenKeyPair(kgen KeyGenerator, t *testing.T) {
 					zeros++
 				}
 			}
-			require.Equal(t, params.ringQ.N, zeros+params.h)
+			require.Equal(t, params.ringQ.N, zeros+params.Xs().H)
 		}
 
 	})
 
-	// Checks that sum([-as + e, a] + [as])) <= N * 6 * sigma
+	// Checks that sum([-as + e, a] + [as])) <= N * ||e||
 	t.Run(testString(params, "PK"), func(t *testing.T) {
 
 		if params.PCount() > 0 {
@@ -177,14 +402,14 @@ func testGenKeyPair(kgen KeyGenerator, t *testing.T) {
 			params.RingQP().MulCoeffsMontgomeryAndAddLvl(sk.Value.Q.Level(), sk.Value.P.Level(), sk.Value, pk.Value[1], pk.Value[0])
 			params.RingQP().InvNTTLvl(sk.Value.Q.Level(), sk.Value.P.Level(), pk.Value[0], pk.Value[0])
 
-			log2Bound := bits.Len64(uint64(math.Floor(DefaultSigma*6)) * uint64(params.N()))
+			log2Bound := bits.Len64(uint64(math.Floor(params.Xe().NormBound())) * uint64(params.N()))
 			require.GreaterOrEqual(t, log2Bound, log2OfInnerSum(pk.Value[0].Q.Level(), params.RingQ(), pk.Value[0].Q))
 			require.GreaterOrEqual(t, log2Bound, log2OfInnerSum(pk.Value[0].P.Level(), params.RingP(), pk.Value[0].P))
 		} else {
 			params.RingQ().MulCoeffsMontgomeryAndAdd(sk.Value.Q, pk.Value[1].Q, pk.Value[0].Q)
 			params.RingQ().InvNTT(pk.Value[0].Q, pk.Value[0].Q)
 
-			log2Bound := bits.Len64(uint64(math.Floor(DefaultSigma*6)) * uint64(params.N()))
+			log2Bound := bits.Len64(uint64(math.Floor(params.Xe().NormBound())) * uint64(params.N()))
 			require.GreaterOrEqual(t, log2Bound, log2OfInnerSum(pk.Value[0].Q.Level(), params.RingQ(), pk.Value[0].Q))
 		}
 
@@ -239,12 +464,12 @@ func testSwitchKeyGen(kgen KeyGenerator, t *testing.T) {
 		ringQ.Sub(swk.Value[0][0].Q, skIn.Value.Q, swk.Value[0][0].Q)
 
 		// Checks that the error is below the bound
-		// Worst error bound is N * floor(6*sigma) * #Keys
+		// Worst error bound is N * ||e|| * #Keys
 
 		ringQP.InvNTTLvl(levelQ, levelP, swk.Value[0][0], swk.Value[0][0])
 		ringQP.InvMFormLvl(levelQ, levelP, swk.Value[0][0], swk.Value[0][0])
 
-		log2Bound := bits.Len64(uint64(math.Floor(DefaultSigma*6)) * uint64(params.N()*len(swk.Value)))
+		log2Bound := bits.Len64(uint64(math.Floor(params.Xe().NormBound())) * uint64(params.N()*len(swk.Value)))
 		require.GreaterOrEqual(t, log2Bound, log2OfInnerSum(len(ringQ.Modulus)-1, ringQ, swk.Value[0][0].Q))
 		require.GreaterOrEqual(t, log2Bound, log2OfInnerSum(len(ringP.Modulus)-1, ringP, swk.Value[0][0].P))
 
@@ -353,6 +578,75 @@ func testEncryptor(kgen KeyGenerator, t *testing.T) {
 	})
 }
 
+func testWithPRNG(kgen KeyGenerator, t *testing.T) {
+
+	params := kgen.(*keyGenerator).params
+
+	newPRNG := func() utils.PRNG {
+		prng, err := utils.NewKeyedPRNG([]byte{'l', 'a', 't', 't', 'i', 'g', 'o'})
+		if err != nil {
+			panic(err)
+		}
+		return prng
+	}
+
+	marshal := func(obj interface{ MarshalBinary() ([]byte, error) }) []byte {
+		data, err := obj.MarshalBinary()
+		require.NoError(t, err)
+		return data
+	}
+
+	t.Run(testString(params, "WithPRNG/KeyGenerator/"), func(t *testing.T) {
+		kgen1, kgen2 := kgen.WithPRNG(newPRNG()), kgen.WithPRNG(newPRNG())
+
+		sk1, pk1 := kgen1.GenKeyPair()
+		sk2, pk2 := kgen2.GenKeyPair()
+		require.Equal(t, marshal(sk1), marshal(sk2))
+		require.Equal(t, marshal(pk1), marshal(pk2))
+
+		if params.PCount() != 0 {
+			require.Equal(t, marshal(kgen1.GenRelinearizationKey(sk1, 1)), marshal(kgen2.GenRelinearizationKey(sk2, 1)))
+		}
+
+		require.Equal(t, marshal(kgen1.GenSecretKeyWithHammingWeight(16)), marshal(kgen2.GenSecretKeyWithHammingWeight(16)))
+	})
+
+	t.Run(testString(params, "WithPRNG/Encryptor/"), func(t *testing.T) {
+
+		sk, pk := kgen.WithPRNG(newPRNG()).GenKeyPair()
+
+		plaintext := NewPlaintext(params, params.MaxLevel())
+		plaintext.Value.IsNTT = true
+
+		for _, key := range []interface{}{sk, pk} {
+
+			enc1, enc2 := NewEncryptor(params, key).WithPRNG(newPRNG()), NewEncryptor(params, key).WithPRNG(newPRNG())
+
+			ct1, ct2 := NewCiphertextNTT(params, 1, plaintext.Level()), NewCiphertextNTT(params, 1, plaintext.Level())
+			enc1.Encrypt(plaintext, ct1)
+			enc2.Encrypt(plaintext, ct2)
+			require.Equal(t, marshal(ct1), marshal(ct2))
+
+			// The copies are keyed from the PRNG of their parent, hence are reproducible but independent of it
+			enc1.ShallowCopy().Encrypt(plaintext, ct1)
+			enc2.ShallowCopy().Encrypt(plaintext, ct2)
+			require.Equal(t, marshal(ct1), marshal(ct2))
+
+			enc2.Encrypt(plaintext, ct2)
+			require.NotEqual(t, marshal(ct1), marshal(ct2))
+
+			// The copies do not depend on the use of their parent, whose PRNG they do not read
+			ct3 := NewCiphertextNTT(params, 1, plaintext.Level())
+			enc1.ShallowCopy().Encrypt(plaintext, ct1)
+			enc2.ShallowCopy().Encrypt(plaintext, ct3)
+			require.Equal(t, marshal(ct1), marshal(ct3))
+
+			enc1.Encrypt(plaintext, ct1)
+			require.Equal(t, marshal(ct1), marshal(ct2))
+		}
+	})
+}
+
 func testDecryptor(kgen KeyGenerator, t *testing.T) {
 	params := kgen.(*keyGenerator).params
 	sk := kgen.GenSecretKey()
@@ -587,6 +881,108 @@ func testKeySwitchDimension(kgen KeyGenerator, t *testing.T) {
 	})
 }
 
+func testPermutationNetwork(kgen KeyGenerator, t *testing.T) {
+
+	params := kgen.(*keyGenerator).params
+
+	columns := int(params.RingQ().NthRoot >> 2)
+
+	t.Run(testString(params, "PermutationNetwork/RotationForGaloisElement/"), func(t *testing.T) {
+		for _, k := range []int{0, 1, 5, columns/2 + 3, columns - 1} {
+
+			rowRotations := []bool{false}
+			if params.RingType() == ring.Standard {
+				rowRotations = append(rowRotations, true)
+			}
+
+			for _, rowRotation := range rowRotations {
+
+				galEl := params.GaloisElementForColumnRotationBy(k)
+				if rowRotation {
+					galEl = (galEl * params.GaloisElementForRowRotation()) & (params.RingQ().NthRoot - 1)
+				}
+
+				kHave, rowRotationHave := params.RotationForGaloisElement(galEl)
+				require.Equal(t, k, kHave)
+				require.Equal(t, rowRotation, rowRotationHave)
+			}
+		}
+	})
+
+	// applyAutomorphism returns the slots of phi_{galEl}(values), where the slots are arranged
+	// as one or two rows of cyclically rotated columns.
+	applyAutomorphism := func(values []int, galEl uint64, rows int) (out []int) {
+		k, rowRotation := params.RotationForGaloisElement(galEl)
+		n := len(values) / rows
+		out = make([]int, len(values))
+		for i := 0; i < rows; i++ {
+			row := i
+			if rowRotation {
+				row = rows - 1 - i
+			}
+			for j := 0; j < n; j++ {
+				out[i*n+j] = values[row*n+(j+k)%n]
+			}
+		}
+		return
+	}
+
+	for _, slots := range []int{1, 2, columns >> 3, columns, params.N()} {
+
+		rows := 1
+		if slots > columns {
+			if params.RingType() != ring.Standard {
+				continue
+			}
+			rows = 2
+		}
+
+		t.Run(testString(params, fmt.Sprintf("PermutationNetwork/Slots=%d/", slots)), func(t *testing.T) {
+
+			logSlots := bits.Len64(uint64(slots)) - 1
+
+			identity := make([]int, slots)
+			for i := range identity {
+				identity[i] = i
+			}
+
+			require.Equal(t, 0, params.NewPermutationNetwork(identity).Depth())
+
+			permutation := rand.Perm(slots)
+
+			pn := params.NewPermutationNetwork(permutation)
+
+			require.LessOrEqual(t, pn.Depth(), utils.MaxInt(2*logSlots-1, 0))
+			require.LessOrEqual(t, len(pn.GaloisElements()), 2*logSlots)
+
+			values := make([]int, slots)
+			copy(values, identity)
+
+			for _, layer := range pn.Layers {
+				out := make([]int, slots)
+				for galEl, mask := range layer {
+					rotated := applyAutomorphism(values, galEl, rows)
+					for j := range mask {
+						if mask[j] {
+							out[j] += rotated[j] + 1
+						}
+					}
+				}
+
+				for j := range out {
+					// Each slot is selected by exactly one mask
+					require.Greater(t, out[j], 0)
+					out[j]--
+				}
+
+				values = out
+			}
+
+			require.Equal(t, permutation, values)
+		})
+	}
+}
+
 func testMarshaller(kgen KeyGenerator, t *testing.T) {
 
 	params := kgen.(*keyGenerator).params
@@ -728,3 +1124,89 @@ func testMarshaller(kgen KeyGenerator, t *testing.T) {
 		rotationKey.Equals(resRotationKey)
 	})
 }
+
+func testCyclotomic(kgen KeyGenerator, t *testing.T) {
+
+	params := kgen.(*keyGenerator).params
+
+	t.Run(testString(params, "Cyclotomic/Parameters/"), func(t *testing.T) {
+		require.Equal(t, int(ring.EulerTotient(params.M())), params.N())
+		require.Equal(t, params.N(), params.RingQ().N)
+		require.Equal(t, params.N()>>1, params.HammingWeight())
+
+		data, err := params.MarshalBinary()
+		require.NoError(t, err)
+		require.Equal(t, params.MarshalBinarySize(), len(data))
+		var paramsTest Parameters
+		require.NoError(t, paramsTest.UnmarshalBinary(data))
+		require.True(t, params.Equals(paramsTest))
+
+		data, err = json.Marshal(params)
+		require.NoError(t, err)
+		paramsTest = Parameters{}
+		require.NoError(t, json.Unmarshal(data, &paramsTest))
+		require.True(t, params.Equals(paramsTest))
+
+		require.Panics(t, func() { params.GaloisElementForColumnRotationBy(1) })
+	})
+
+	t.Run(testString(params, "Cyclotomic/Automorphism/"), func(t *testing.T) {
+
+		ringQ := params.RingQ()
+		levelQ := params.MaxLevel()
+
+		sk := kgen.GenSecretKey()
+		ks := NewKeySwitcher(params)
+
+		prng, _ := utils.NewPRNG()
+		plaintext := NewPlaintext(params, levelQ)
+		ring.NewUniformSampler(prng, ringQ).Read(plaintext.Value)
+		plaintext.Value.IsNTT = true
+
+		ciphertext := NewCiphertextNTT(params, 1, levelQ)
+		NewEncryptor(params, sk).Encrypt(plaintext, ciphertext)
+
+		for _, galEl := range []uint64{uint64(params.M() - 1), 5} {
+
+			galEl = galEl % uint64(params.M())
+			require.Equal(t, uint64(1), (galEl*params.InverseGaloisElement(galEl))%uint64(params.M()))
+
+			swk := kgen.GenSwitchingKeyForGalois(galEl, sk)
+			index := ringQ.PermuteNTTIndex(galEl)
+
+			// Key-switches to sigma_{galEl^-1}(sk) and applies sigma_{galEl}
+			ks.SwitchKeysInPlace(levelQ, ciphertext.Value[1], swk, ks.Pool[1].Q, ks.Pool[2].Q)
+			ringQ.AddLvl(levelQ, ks.Pool[1].Q, ciphertext.Value[0], ks.Pool[1].Q)
+
+			ctOut := NewCiphertextNTT(params, 1, levelQ)
+			ringQ.PermuteNTTWithIndexLvl(levelQ, ks.Pool[1].Q, index, ctOut.Value[0])
+			ringQ.PermuteNTTWithIndexLvl(levelQ, ks.Pool[2].Q, index, ctOut.Value[1])
+
+			ptOut := NewPlaintext(params, levelQ)
+			NewDecryptor(params, sk).Decrypt(ctOut, ptOut)
+
+			// sigma_{galEl}(pt) is the decryption of the output ciphertext up to a small error
+			ptWant := NewPlaintext(params, levelQ)
+			ringQ.PermuteNTTWithIndexLvl(levelQ, plaintext.Value, index, ptWant.Value)
+			ringQ.InvNTTLvl(levelQ, ptWant.Value, ptWant.Value)
+
+			ringQ.SubLvl(levelQ, ptOut.Value, ptWant.Value, ptOut.Value)
+			require.GreaterOrEqual(t, 11+params.LogN(), log2OfInnerSum(levelQ, ringQ, ptOut.Value))
+		}
+	})
+	t.Run(testString(params, "Cyclotomic/Marshaller/Ciphertext/"), func(t *testing.T) {
+
+		prng, _ := utils.NewPRNG()
+		ciphertext := NewCiphertextRandom(prng, params, 1, params.MaxLevel())
+
+		data, err := ciphertext.MarshalBinary()
+		require.NoError(t, err)
+
+		ciphertextTest := new(Ciphertext)
+		require.NoError(t, ciphertextTest.UnmarshalBinary(data))
+		require.Equal(t, ciphertext.Degree(), ciphertextTest.Degree())
+		for i := range ciphertext.Value {
+			require.True(t, params.RingQ().Equal(ciphertext.Value[i], ciphertextTest.Value[i]))
+		}
+	})
+}
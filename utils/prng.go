@@ -2,7 +2,9 @@ package utils
 
 import (
 	"crypto/rand"
+	"encoding/binary"
 	"errors"
+	"sync/atomic"
 
 	"golang.org/x/crypto/blake2b"
 )
@@ -75,3 +77,43 @@ func (prng *KeyedPRNG) SetClock(sum []byte, n uint64) error {
 	}
 	return nil
 }
+
+// DefaultPRNG returns prng if it is not nil, and a new KeyedPRNG keyed from crypto/rand (see NewPRNG) otherwise.
+func DefaultPRNG(prng PRNG) (PRNG, error) {
+	if prng != nil {
+		return prng, nil
+	}
+	return NewPRNG()
+}
+
+// PRNGFork derives independent PRNGs from a fork key read once from a parent PRNG. It is used by the ShallowCopy
+// methods of the objects whose randomness is drawn from a user-provided PRNG, so that the copies draw independent,
+// but reproducible, sequences of random bytes without reading from the PRNG of the receiver.
+// A PRNGFork can be used concurrently.
+type PRNGFork struct {
+	key     []byte
+	counter uint64
+}
+
+// NewPRNGFork creates a new PRNGFork whose fork key is read from prng, or returns nil if prng is nil.
+// Reading the fork key advances the clock of prng.
+func NewPRNGFork(prng PRNG) *PRNGFork {
+	if prng == nil {
+		return nil
+	}
+	key := make([]byte, 32)
+	prng.Clock(key)
+	return &PRNGFork{key: key}
+}
+
+// Derive returns a new KeyedPRNG keyed with the fork key and the number of PRNGs previously derived from the
+// PRNGFork, or nil if the receiver is nil. The i-th derived PRNG only depends on the fork key and on i.
+func (fork *PRNGFork) Derive() (PRNG, error) {
+	if fork == nil {
+		return nil, nil
+	}
+	key := make([]byte, len(fork.key)+8)
+	copy(key, fork.key)
+	binary.LittleEndian.PutUint64(key[len(fork.key):], atomic.AddUint64(&fork.counter, 1)-1)
+	return NewKeyedPRNG(key)
+}
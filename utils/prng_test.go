@@ -32,4 +32,45 @@ func Test_PRNG(t *testing.T) {
 		require.Equal(t, sum0, sum1)
 	})
 
+	t.Run("PRNGFork", func(t *testing.T) {
+
+		fork := NewPRNGFork(nil)
+		require.Nil(t, fork)
+		prng, err := fork.Derive()
+		require.NoError(t, err)
+		require.Nil(t, prng)
+
+		Ha, _ := NewKeyedPRNG([]byte{0x01})
+		Hb, _ := NewKeyedPRNG([]byte{0x01})
+
+		Fa, Fb := NewPRNGFork(Ha), NewPRNGFork(Hb)
+
+		// The derived PRNGs do not depend on the use of the parent PRNG after the fork
+		Hb.Clock(make([]byte, 64))
+
+		sum0 := make([]byte, 64)
+		sum1 := make([]byte, 64)
+		sum2 := make([]byte, 64)
+		sum3 := make([]byte, 64)
+
+		Da0, err := Fa.Derive()
+		require.NoError(t, err)
+		Da1, err := Fa.Derive()
+		require.NoError(t, err)
+		Db0, err := Fb.Derive()
+		require.NoError(t, err)
+
+		Da0.Clock(sum0)
+		Db0.Clock(sum1)
+		Da1.Clock(sum2)
+		Ha.Clock(sum3)
+
+		require.Equal(t, sum0, sum1)
+		require.NotEqual(t, sum0, sum2)
+		require.NotEqual(t, sum0, sum3)
+		require.NotEqual(t, sum2, sum3)
+
+		// Deriving does not advance the clock of the parent PRNG
+		require.Equal(t, uint64(2), Ha.GetClock())
+	})
 }